@@ -17,19 +17,43 @@ func main() {
 	// Define subcommands
 	exportCmd := flag.NewFlagSet("export", flag.ExitOnError)
 	importCmd := flag.NewFlagSet("import", flag.ExitOnError)
+	pruneCmd := flag.NewFlagSet("prune", flag.ExitOnError)
 
 	// Export flags
-	exportOutput := exportCmd.String("output", "", "Output file path (default: backup_YYYYMMDD_HHMMSS.json)")
+	exportOutput := exportCmd.String("output", "", "Output file path (default: <dir>/backup_YYYYMMDD_HHMMSS.jsonl.gz)")
+	exportDir := exportCmd.String("dir", ".", "Directory for generated backup file names")
+	exportFormat := exportCmd.String("format", "jsonl", "Backup format: jsonl or json")
+	exportCompress := exportCmd.String("compress", "gzip", "Compression: gzip, zstd or none")
+	exportSince := exportCmd.String("since", "", "Only export rows changed since this time (RFC3339 or YYYY-MM-DD)")
+	exportIncremental := exportCmd.Bool("incremental", false, "Only export rows changed since the latest backup in -dir")
 
 	// Import flags
 	importInput := importCmd.String("input", "", "Input file path (required)")
 	importClear := importCmd.Bool("clear", false, "Clear existing data before import (WARNING: destructive)")
 
+	// Prune flags
+	pruneDir := pruneCmd.String("dir", "", "Backup directory (required)")
+	pruneDaily := pruneCmd.Int("daily", 7, "Number of daily backups to keep")
+	pruneWeekly := pruneCmd.Int("weekly", 4, "Number of weekly backups to keep")
+	pruneDryRun := pruneCmd.Bool("dry-run", false, "List backups that would be removed without deleting them")
+
 	if len(os.Args) < 2 {
 		printUsage()
 		os.Exit(1)
 	}
 
+	// Pruning only touches files, so it doesn't need a database connection
+	if os.Args[1] == "prune" {
+		pruneCmd.Parse(os.Args[2:])
+		if *pruneDir == "" {
+			fmt.Println("Error: -dir flag is required")
+			pruneCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		handlePrune(*pruneDir, service.RetentionPolicy{Daily: *pruneDaily, Weekly: *pruneWeekly}, *pruneDryRun)
+		return
+	}
+
 	// Load configuration
 	cfg := config.Load()
 
@@ -51,7 +75,13 @@ func main() {
 	switch os.Args[1] {
 	case "export":
 		exportCmd.Parse(os.Args[2:])
-		handleExport(backupService, *exportOutput)
+		opts, err := exportOptions(*exportFormat, *exportCompress, *exportSince, *exportIncremental, *exportDir)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			exportCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		handleExport(backupService, *exportOutput, *exportDir, opts)
 
 	case "import":
 		importCmd.Parse(os.Args[2:])
@@ -68,23 +98,74 @@ func main() {
 	}
 }
 
-func handleExport(backupService *service.BackupService, outputPath string) {
+// exportOptions builds export options from the export flags
+func exportOptions(format, compression, since string, incremental bool, dir string) (service.ExportOptions, error) {
+	opts := service.ExportOptions{
+		Format:      service.BackupFormat(format),
+		Compression: service.BackupCompression(compression),
+	}
+
+	switch opts.Format {
+	case service.BackupFormatJSON, service.BackupFormatJSONL:
+	default:
+		return opts, fmt.Errorf("unknown format %q", format)
+	}
+	switch opts.Compression {
+	case service.BackupCompressionNone, service.BackupCompressionGzip, service.BackupCompressionZstd:
+	default:
+		return opts, fmt.Errorf("unknown compression %q", compression)
+	}
+
+	if since != "" && incremental {
+		return opts, fmt.Errorf("-since and -incremental cannot be used together")
+	}
+
+	if since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			t, err = time.ParseInLocation("2006-01-02", since, time.Local)
+		}
+		if err != nil {
+			return opts, fmt.Errorf("invalid -since time %q", since)
+		}
+		opts.Since = &t
+	}
+
+	if incremental {
+		files, err := service.ListBackupFiles(dir)
+		if err != nil {
+			return opts, err
+		}
+		if len(files) == 0 {
+			return opts, fmt.Errorf("no backups found in %s to export changes since", dir)
+		}
+		opts.Since = &files[0].CreatedAt
+	}
+
+	return opts, nil
+}
+
+func handleExport(backupService *service.BackupService, outputPath, dir string, opts service.ExportOptions) {
 	// Generate default filename if not provided
 	if outputPath == "" {
-		timestamp := time.Now().Format("20060102_150405")
-		outputPath = fmt.Sprintf("backup_%s.json", timestamp)
+		name := service.BackupFileName(time.Now(), opts.Since != nil, opts.Format, opts.Compression)
+		outputPath = filepath.Join(dir, name)
 	}
 
 	// Ensure directory exists
-	dir := filepath.Dir(outputPath)
+	dir = filepath.Dir(outputPath)
 	if dir != "." && dir != "" {
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			log.Fatalf("Failed to create output directory: %v", err)
 		}
 	}
 
-	log.Printf("Exporting database to: %s", outputPath)
-	if err := backupService.Export(outputPath); err != nil {
+	if opts.Since != nil {
+		log.Printf("Exporting changes since %s to: %s", opts.Since.Format(time.RFC3339), outputPath)
+	} else {
+		log.Printf("Exporting database to: %s", outputPath)
+	}
+	if err := backupService.ExportFile(outputPath, opts); err != nil {
 		log.Fatalf("Export failed: %v", err)
 	}
 
@@ -93,6 +174,31 @@ func handleExport(backupService *service.BackupService, outputPath string) {
 	log.Printf("Export complete! File size: %.2f MB", float64(fileInfo.Size())/1024/1024)
 }
 
+func handlePrune(dir string, policy service.RetentionPolicy, dryRun bool) {
+	if policy.Daily <= 0 && policy.Weekly <= 0 {
+		log.Fatal("Refusing to prune: -daily or -weekly must keep at least one backup")
+	}
+
+	files, err := service.ListBackupFiles(dir)
+	if err != nil {
+		log.Fatalf("Failed to list backups: %v", err)
+	}
+
+	keep, remove := policy.Prune(files)
+	log.Printf("Keeping %d backups, removing %d", len(keep), len(remove))
+
+	for _, file := range remove {
+		if dryRun {
+			log.Printf("Would remove: %s", file.Path)
+			continue
+		}
+		if err := os.Remove(file.Path); err != nil {
+			log.Fatalf("Failed to remove %s: %v", file.Path, err)
+		}
+		log.Printf("Removed: %s", file.Path)
+	}
+}
+
 func handleImport(backupService *service.BackupService, db *database.DB, inputPath string, clearData bool) {
 	// Check if file exists
 	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
@@ -125,33 +231,101 @@ func handleImport(backupService *service.BackupService, db *database.DB, inputPa
 func clearDatabase(db *database.DB) error {
 	// Delete in reverse order of dependencies
 	tables := []string{
+		"spelling_test_answers",
+		"spelling_test_attempts",
+		"spelling_tests",
+		"word_attempts",
+		"practice_word_timing",
+		"practice_state",
+		"hangman_state",
+		"hangman_games",
+		"hangman_sessions",
+		"missing_letter_state",
+		"missing_letter_games",
+		"missing_letter_sessions",
+		"spelling_bee_turns",
+		"spelling_bee_participants",
+		"spelling_bees",
+		"clash_matches",
+		"leaderboard_settings",
+		"achievements",
+		"word_schedules",
 		"practice_results",
 		"practice_sessions",
 		"list_assignments",
+		"teacher_class_lists",
+		"teacher_class_members",
+		"teacher_classes",
 		"words",
 		"spelling_lists",
+		"teacher_kid_relationships",
 		"kid_sessions",
 		"kids",
+		"streak_freezes",
+		"streak_settings",
 		"family_members",
 		"families",
+		"email_delivery_attempts",
+		"email_outbox",
+		"invitations",
+		"api_tokens",
 		"password_reset_tokens",
 		"sessions",
+		"digest_subscriptions",
+		"login_challenges",
+		"two_factor_recovery_codes",
+		"user_two_factor",
+		"user_identities",
 		"users",
 	}
 
 	allowedTables := map[string]struct{}{
-		"practice_results":      {},
-		"practice_sessions":     {},
-		"list_assignments":      {},
-		"words":                 {},
-		"spelling_lists":        {},
-		"kid_sessions":          {},
-		"kids":                  {},
-		"family_members":        {},
-		"families":              {},
-		"password_reset_tokens": {},
-		"sessions":              {},
-		"users":                 {},
+		"spelling_test_answers":     {},
+		"spelling_test_attempts":    {},
+		"spelling_tests":            {},
+		"word_attempts":             {},
+		"practice_word_timing":      {},
+		"practice_state":            {},
+		"hangman_state":             {},
+		"hangman_games":             {},
+		"hangman_sessions":          {},
+		"missing_letter_state":      {},
+		"missing_letter_games":      {},
+		"missing_letter_sessions":   {},
+		"spelling_bee_turns":        {},
+		"spelling_bee_participants": {},
+		"spelling_bees":             {},
+		"clash_matches":             {},
+		"leaderboard_settings":      {},
+		"achievements":              {},
+		"word_schedules":            {},
+		"practice_results":          {},
+		"practice_sessions":         {},
+		"list_assignments":          {},
+		"teacher_class_lists":       {},
+		"teacher_class_members":     {},
+		"teacher_classes":           {},
+		"words":                     {},
+		"spelling_lists":            {},
+		"teacher_kid_relationships": {},
+		"kid_sessions":              {},
+		"kids":                      {},
+		"streak_freezes":            {},
+		"streak_settings":           {},
+		"family_members":            {},
+		"families":                  {},
+		"email_delivery_attempts":   {},
+		"email_outbox":              {},
+		"invitations":               {},
+		"api_tokens":                {},
+		"password_reset_tokens":     {},
+		"sessions":                  {},
+		"digest_subscriptions":      {},
+		"login_challenges":          {},
+		"two_factor_recovery_codes": {},
+		"user_two_factor":           {},
+		"user_identities":           {},
+		"users":                     {},
 	}
 
 	for _, table := range tables {
@@ -172,26 +346,45 @@ func printUsage() {
 	fmt.Println("SpellingClash Database Backup Tool")
 	fmt.Println()
 	fmt.Println("Usage:")
-	fmt.Println("  backup export [options]    Export database to JSON file")
-	fmt.Println("  backup import [options]    Import database from JSON file")
+	fmt.Println("  backup export [options]    Export database to a backup file")
+	fmt.Println("  backup import [options]    Import database from a backup file")
+	fmt.Println("  backup prune [options]     Remove old backups from a directory")
 	fmt.Println()
 	fmt.Println("Export Options:")
-	fmt.Println("  -output <file>    Output file path (default: backup_YYYYMMDD_HHMMSS.json)")
+	fmt.Println("  -output <file>      Output file path (default: <dir>/backup_YYYYMMDD_HHMMSS.jsonl.gz)")
+	fmt.Println("  -dir <dir>          Directory for generated file names (default: .)")
+	fmt.Println("  -format <format>    jsonl (streamed, one row per line) or json (default: jsonl)")
+	fmt.Println("  -compress <type>    gzip, zstd or none (default: gzip)")
+	fmt.Println("  -since <time>       Only export rows changed since a time (RFC3339 or YYYY-MM-DD)")
+	fmt.Println("  -incremental        Only export rows changed since the latest backup in -dir")
 	fmt.Println()
 	fmt.Println("Import Options:")
-	fmt.Println("  -input <file>     Input file path (required)")
-	fmt.Println("  -clear            Clear existing data before import (WARNING: destructive)")
+	fmt.Println("  -input <file>       Input file path (required); format and compression are detected")
+	fmt.Println("  -clear              Clear existing data before import (WARNING: destructive)")
+	fmt.Println()
+	fmt.Println("Prune Options:")
+	fmt.Println("  -dir <dir>          Backup directory (required)")
+	fmt.Println("  -daily <n>          Keep the newest backup of each of the last n days (default: 7)")
+	fmt.Println("  -weekly <n>         Keep the newest backup of each of the last n weeks (default: 4)")
+	fmt.Println("  -dry-run            Show what would be removed without deleting anything")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  # Export database")
-	fmt.Println("  backup export")
-	fmt.Println("  backup export -output mybackup.json")
+	fmt.Println("  backup export -dir backups")
+	fmt.Println("  backup export -output mybackup.json -format json -compress none")
+	fmt.Println()
+	fmt.Println("  # Export changes since the last backup")
+	fmt.Println("  backup export -dir backups -incremental")
 	fmt.Println()
 	fmt.Println("  # Import database (merge with existing data)")
-	fmt.Println("  backup import -input backup.json")
+	fmt.Println("  backup import -input backup.jsonl.gz")
+	fmt.Println()
+	fmt.Println("  # Import database (replace all data), then apply an incremental backup")
+	fmt.Println("  backup import -input backup_20240101_020000.jsonl.gz -clear")
+	fmt.Println("  backup import -input backup_20240101_140000_incremental.jsonl.gz")
 	fmt.Println()
-	fmt.Println("  # Import database (replace all data)")
-	fmt.Println("  backup import -input backup.json -clear")
+	fmt.Println("  # Keep a week of daily and a month of weekly backups")
+	fmt.Println("  backup prune -dir backups -daily 7 -weekly 4")
 	fmt.Println()
 	fmt.Println("Environment Variables:")
 	fmt.Println("  DATABASE_TYPE    Database type: sqlite, postgres, or mysql (default: sqlite)")
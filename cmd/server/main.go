@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"html/template"
 	"log"
@@ -8,14 +9,18 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"spellingclash/internal/audio"
 	"spellingclash/internal/config"
 	"spellingclash/internal/database"
+	"spellingclash/internal/dictionary"
+	"spellingclash/internal/email"
 	"spellingclash/internal/handlers"
 	"spellingclash/internal/repository"
+	"spellingclash/internal/security"
 	"spellingclash/internal/service"
 
 	"github.com/joho/godotenv"
@@ -102,16 +107,63 @@ func main() {
 		log.Println("Templates loaded successfully")
 		handlers.CompleteStep("Loading templates")
 
+		handlers.SetCurrentStep("Loading dictionary...")
+		// Load the embedded word lists, plus any extra lists in data/dictionary
+		dict, err := dictionary.Load()
+		if err != nil {
+			log.Printf("Warning: Failed to load dictionary, spelling checks disabled: %v", err)
+		} else if err := dict.LoadDir(filepath.Join("data", "dictionary")); err != nil {
+			log.Printf("Warning: Failed to load extra dictionary word lists: %v", err)
+		}
+		handlers.CompleteStep("Loading dictionary")
+
 		handlers.SetCurrentStep("Initializing services...")
 		// Initialize repositories
 		userRepo := repository.NewUserRepository(db)
 		familyRepo := repository.NewFamilyRepository(db)
 		kidRepo := repository.NewKidRepository(db)
 		teacherKidRepo := repository.NewTeacherKidRepository(db)
+		teacherClassRepo := repository.NewTeacherClassRepository(db)
 		listRepo := repository.NewListRepository(db)
+		listRepo.SetDictionary(dict)
 		practiceRepo := repository.NewPracticeRepository(db)
 		settingsRepo := repository.NewSettingsRepository(db)
 		invitationRepo := repository.NewInvitationRepository(db)
+		wordScheduleRepo := repository.NewWordScheduleRepository(db)
+		hangmanRepo := repository.NewHangmanRepository(db)
+		missingLetterRepo := repository.NewMissingLetterRepository(db)
+		apiTokenRepo := repository.NewAPITokenRepository(db)
+		spellingTestRepo := repository.NewSpellingTestRepository(db)
+		emailOutboxRepo := repository.NewEmailOutboxRepository(db)
+		digestRepo := repository.NewDigestRepository(db)
+		identityRepo := repository.NewIdentityRepository(db)
+		twoFactorRepo := repository.NewTwoFactorRepository(db)
+		streakRepo := repository.NewStreakRepository(db)
+		achievementRepo := repository.NewAchievementRepository(db)
+		leaderboardRepo := repository.NewLeaderboardRepository(db)
+		clashRepo := repository.NewClashRepository(db)
+		spellingBeeRepo := repository.NewSpellingBeeRepository(db)
+
+		// Client addresses, which rate limits are counted against, are only taken
+		// from forwarding headers set by our own proxies
+		if err := security.SetTrustedProxies(strings.Split(cfg.TrustedProxies, ",")); err != nil {
+			log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+		}
+
+		// Rate limits are kept in the database so they survive restarts and hold
+		// across replicas, unless configured to stay in memory
+		var rateLimitRepo *repository.RateLimitRepository
+		var limiter security.Limiter
+		switch cfg.RateLimitBackend {
+		case "memory":
+			limiter = security.NewMemoryLimiter()
+		default:
+			if cfg.RateLimitBackend != "database" {
+				log.Printf("Warning: Unknown RATE_LIMIT_BACKEND %q, using the database", cfg.RateLimitBackend)
+			}
+			rateLimitRepo = repository.NewRateLimitRepository(db)
+			limiter = security.NewStoreLimiter(rateLimitRepo)
+		}
 
 		// Apply invite-only mode from environment if explicitly configured.
 		if cfg.InviteOnlyModeConfigured {
@@ -123,12 +175,38 @@ func main() {
 		}
 
 		// Initialize services
-		authService := service.NewAuthService(userRepo, familyRepo, cfg.SessionDuration)
+		authService := service.NewAuthService(userRepo, familyRepo, identityRepo, twoFactorRepo, apiTokenRepo, settingsRepo, limiter, cfg.SessionDuration, cfg.CSRFSecret)
 		familyService := service.NewFamilyService(familyRepo, kidRepo)
-		teacherService := service.NewTeacherService(userRepo, familyRepo, kidRepo, teacherKidRepo)
-
-		// Initialize email service (Amazon SES)
-		emailService, err := service.NewEmailService(cfg.AWSRegion, cfg.SESFromEmail, cfg.SESFromName, cfg.AppBaseURL, cfg.DebugLogging)
+		teacherService := service.NewTeacherService(userRepo, familyRepo, kidRepo, teacherKidRepo, teacherClassRepo, listRepo)
+		apiTokenService := service.NewAPITokenService(apiTokenRepo, userRepo)
+
+		// Initialize email service with the configured backend
+		mailer, err := email.NewMailer(email.Config{
+			Backend:      cfg.EmailBackend,
+			AWSRegion:    cfg.AWSRegion,
+			SMTPHost:     cfg.SMTPHost,
+			SMTPPort:     cfg.SMTPPort,
+			SMTPUsername: cfg.SMTPUsername,
+			SMTPPassword: cfg.SMTPPassword,
+			SMTPTLS:      cfg.SMTPTLS,
+			FileDir:      cfg.EmailFileDir,
+		})
+		if err != nil {
+			log.Printf("Warning: Invalid email configuration, email disabled: %v", err)
+			mailer = email.NewNoneMailer()
+		}
+		emailTemplates, err := email.LoadTemplates(filepath.Join(cfg.TemplatesPath, "email"), email.Branding{
+			AppName:         cfg.BrandName,
+			PrimaryColor:    cfg.BrandPrimaryColor,
+			BackgroundColor: cfg.BrandBackgroundColor,
+			LogoURL:         cfg.BrandLogoURL,
+			BaseURL:         cfg.AppBaseURL,
+		}, cfg.EmailLocale)
+		if err != nil {
+			log.Printf("Warning: Failed to load email templates, email disabled: %v", err)
+			mailer = email.NewNoneMailer()
+		}
+		emailService, err := service.NewEmailService(mailer, emailTemplates, emailOutboxRepo, invitationRepo, cfg.EmailFrom, cfg.EmailFromName, cfg.AppBaseURL, cfg.DebugLogging)
 		if err != nil {
 			log.Printf("Warning: Email service initialization failed: %v", err)
 			log.Println("Continuing without email notifications")
@@ -174,11 +252,68 @@ func main() {
 				},
 			},
 		}
+		if cfg.OIDCDiscoveryURL != "" {
+			oauthProviders["oidc"] = handlers.OAuthProvider{
+				Name:  "oidc",
+				Label: cfg.OIDCLabel,
+				Config: &oauth2.Config{
+					ClientID:     cfg.OIDCClientID,
+					ClientSecret: cfg.OIDCClientSecret,
+					Scopes:       cfg.OIDCScopes,
+				},
+				OIDC: handlers.NewOIDCProvider(handlers.OIDCConfig{
+					DiscoveryURL:  cfg.OIDCDiscoveryURL,
+					ClientID:      cfg.OIDCClientID,
+					EmailClaim:    cfg.OIDCEmailClaim,
+					NameClaim:     cfg.OIDCNameClaim,
+					TeacherClaim:  cfg.OIDCTeacherClaim,
+					TeacherValues: cfg.OIDCTeacherValues,
+				}),
+			}
+		}
 
-		// Initialize TTS service with audio directory
-		ttsService := audio.NewTTSService(filepath.Join(cfg.StaticFilesPath, "audio"))
-		listService := service.NewListService(listRepo, familyRepo, userRepo, teacherKidRepo, ttsService)
-		practiceService := service.NewPracticeService(practiceRepo, listRepo)
+		// Initialize TTS service with audio directory and the configured provider
+		ttsProvider, err := audio.NewProvider(audio.ProviderConfig{
+			Provider: cfg.TTSProvider,
+			Command:  cfg.TTSCommand,
+			Format:   cfg.TTSFormat,
+		})
+		if err != nil {
+			log.Printf("Warning: Invalid TTS configuration, audio generation disabled: %v", err)
+			ttsProvider = audio.NewNoneProvider()
+		}
+		log.Printf("Using TTS provider: %s", ttsProvider.Name())
+		ttsService := audio.NewTTSService(filepath.Join(cfg.StaticFilesPath, "audio"), ttsProvider)
+		listService := service.NewListService(listRepo, familyRepo, userRepo, teacherKidRepo, teacherClassRepo, ttsService)
+		streakLocation := time.Local
+		if cfg.StreakTimezone != "" {
+			if loc, err := time.LoadLocation(cfg.StreakTimezone); err != nil {
+				log.Printf("Warning: Invalid streak time zone, using the server's: %v", err)
+			} else {
+				streakLocation = loc
+			}
+		}
+		streakService := service.NewStreakService(streakRepo, streakLocation)
+		achievementService := service.NewAchievementService(achievementRepo, kidRepo, streakService)
+		leaderboardService := service.NewLeaderboardService(leaderboardRepo, kidRepo, teacherKidRepo, userRepo, listRepo, streakService)
+		practiceService := service.NewPracticeService(practiceRepo, listRepo, wordScheduleRepo, achievementService)
+		gameService := service.NewGameService(hangmanRepo, missingLetterRepo, listRepo, dict, achievementService)
+		spellingTestService := service.NewSpellingTestService(spellingTestRepo, teacherClassRepo, kidRepo, listRepo, familyRepo, ttsService)
+		spellingBeeService := service.NewSpellingBeeService(spellingBeeRepo, listRepo, teacherKidRepo, familyRepo, ttsService)
+
+		digestSchedule, err := service.ParseDigestSchedule(cfg.DigestDay, cfg.DigestHour, cfg.DigestTimezone)
+		if err != nil {
+			log.Printf("Warning: Invalid weekly digest schedule, using Sunday at 18:00: %v", err)
+			digestSchedule, _ = service.ParseDigestSchedule("sunday", 18, "")
+		}
+		kidLoginService := service.NewKidLoginService(familyRepo, limiter, emailService, cfg.CSRFSecret)
+		clashService := service.NewClashService(clashRepo, listRepo, kidRepo, teacherKidRepo, leaderboardService, ttsService)
+		if cancelled, err := clashService.CancelUnfinishedMatches(); err != nil {
+			log.Printf("Warning: Failed to cancel unfinished clash matches: %v", err)
+		} else if cancelled > 0 {
+			log.Printf("Cancelled %d clash matches left unfinished by a restart", cancelled)
+		}
+		digestService := service.NewDigestService(digestRepo, userRepo, familyRepo, kidRepo, teacherKidRepo, listRepo, practiceService, streakService, emailService, digestSchedule)
 
 		handlers.CompleteStep("Initializing services")
 
@@ -203,16 +338,26 @@ func main() {
 
 		handlers.SetCurrentStep("Setting up routes...")
 		// Initialize handlers
-		middleware := handlers.NewMiddleware(authService, familyService, cfg.CSRFSecret)
+		middleware := handlers.NewMiddleware(authService, familyService, apiTokenService, cfg.CSRFSecret, limiter, cfg.APIRateLimit)
 		backupService := service.NewBackupService(db)
 		authHandler := handlers.NewAuthHandler(authService, emailService, templates, oauthProviders, cfg.OAuthRedirectBaseURL, settingsRepo, invitationRepo)
-		parentHandler := handlers.NewParentHandler(familyService, listService, middleware, templates)
-		teacherHandler := handlers.NewTeacherHandler(teacherService, listService, middleware, templates)
-		kidHandler := handlers.NewKidHandler(familyService, teacherService, listService, practiceService, middleware, templates)
+		parentHandler := handlers.NewParentHandler(familyService, listService, practiceService, streakService, middleware, templates)
+		teacherHandler := handlers.NewTeacherHandler(teacherService, listService, practiceService, spellingTestService, spellingBeeService, middleware, templates)
+		kidHandler := handlers.NewKidHandler(familyService, kidLoginService, teacherService, listService, practiceService, streakService, achievementService, spellingTestService, spellingBeeService, middleware, templates)
 		listHandler := handlers.NewListHandler(listService, familyService, teacherService, middleware, templates)
-		practiceHandler := handlers.NewPracticeHandler(practiceService, listService, templates)
-		hangmanHandler := handlers.NewHangmanHandler(db, listService, templates)
-		missingLetterHandler := handlers.NewMissingLetterHandler(db, listService, templates)
+		practiceHandler := handlers.NewPracticeHandler(practiceService, listService, achievementService, templates)
+		hangmanHandler := handlers.NewHangmanHandler(gameService, listService, achievementService, templates)
+		missingLetterHandler := handlers.NewMissingLetterHandler(gameService, listService, achievementService, templates)
+		spellingTestHandler := handlers.NewSpellingTestHandler(spellingTestService, templates)
+		spellingBeeHandler := handlers.NewSpellingBeeHandler(spellingBeeService, templates)
+		apiHandler := handlers.NewAPIHandler(listService, familyService, teacherService, practiceService)
+		apiTokenHandler := handlers.NewAPITokenHandler(apiTokenService, middleware, templates)
+		notificationHandler := handlers.NewNotificationHandler(digestService, middleware, templates)
+		signInMethodsHandler := handlers.NewSignInMethodsHandler(authService, middleware, templates, oauthProviders)
+		twoFactorHandler := handlers.NewTwoFactorHandler(authService, middleware, templates, cfg.BrandName)
+		sessionsHandler := handlers.NewSessionsHandler(authService, familyService, middleware, templates)
+		leaderboardHandler := handlers.NewLeaderboardHandler(leaderboardService, familyService, teacherService, middleware, templates)
+		clashHandler := handlers.NewClashHandler(clashService, listService, templates)
 		adminHandler := handlers.NewAdminHandler(templates, authService, emailService, listService, backupService, listRepo, userRepo, familyRepo, kidRepo, settingsRepo, invitationRepo, middleware, cfg.Version, cfg.AppBaseURL, cfg.DatabaseType, cfg.DatabasePath, cfg.DatabaseURL)
 
 		// Setup new routes
@@ -224,16 +369,18 @@ func main() {
 		// Public routes
 		newMux.HandleFunc("GET /", handlers.RequireReady(authHandler.Home))
 		newMux.HandleFunc("GET /login", handlers.RequireReady(authHandler.ShowLogin))
-		newMux.HandleFunc("POST /login", handlers.RequireReady(middleware.RateLimit(authHandler.Login)))
+		newMux.HandleFunc("POST /login", handlers.RequireReady(middleware.RateLimit(handlers.LoginRateLimit, authHandler.Login)))
 		newMux.HandleFunc("GET /register", handlers.RequireReady(authHandler.ShowRegister))
-		newMux.HandleFunc("POST /register", handlers.RequireReady(middleware.RateLimit(authHandler.Register)))
+		newMux.HandleFunc("POST /register", handlers.RequireReady(middleware.RateLimit(handlers.RegisterRateLimit, authHandler.Register)))
+		newMux.HandleFunc("GET /login/two-factor", handlers.RequireReady(authHandler.ShowTwoFactorLogin))
+		newMux.HandleFunc("POST /login/two-factor", handlers.RequireReady(middleware.RateLimit(handlers.TwoFactorLoginRateLimit, authHandler.TwoFactorLogin)))
 		newMux.HandleFunc("POST /logout", handlers.RequireReady(authHandler.Logout))
 		newMux.HandleFunc("GET /auth/{provider}/start", handlers.RequireReady(authHandler.StartOAuth))
 		newMux.HandleFunc("GET /auth/{provider}/callback", handlers.RequireReady(authHandler.OAuthCallback))
 		newMux.HandleFunc("GET /auth/forgot-password", handlers.RequireReady(authHandler.ShowForgotPassword))
-		newMux.HandleFunc("POST /auth/forgot-password", handlers.RequireReady(middleware.RateLimit(authHandler.ForgotPassword)))
+		newMux.HandleFunc("POST /auth/forgot-password", handlers.RequireReady(middleware.RateLimit(handlers.ForgotPasswordRateLimit, authHandler.ForgotPassword)))
 		newMux.HandleFunc("GET /auth/reset-password", handlers.RequireReady(authHandler.ShowResetPassword))
-		newMux.HandleFunc("POST /auth/reset-password", handlers.RequireReady(middleware.RateLimit(authHandler.ResetPassword)))
+		newMux.HandleFunc("POST /auth/reset-password", handlers.RequireReady(middleware.RateLimit(handlers.ResetPasswordRateLimit, authHandler.ResetPassword)))
 
 		// Protected parent routes
 		newMux.HandleFunc("GET /parent/dashboard", handlers.RequireReady(middleware.RequireAuth(parentHandler.Dashboard)))
@@ -241,6 +388,9 @@ func main() {
 		newMux.HandleFunc("POST /parent/family/create", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(parentHandler.CreateFamily))))
 		newMux.HandleFunc("POST /parent/family/join", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(parentHandler.JoinFamily))))
 		newMux.HandleFunc("POST /parent/family/{familyCode}/leave", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(parentHandler.LeaveFamily))))
+		newMux.HandleFunc("POST /parent/family/{familyCode}/streaks", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(parentHandler.UpdateStreakSettings))))
+		newMux.HandleFunc("POST /parent/family/{familyCode}/days-off", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(parentHandler.AddStreakFreeze))))
+		newMux.HandleFunc("POST /parent/family/{familyCode}/days-off/{id}/delete", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(parentHandler.DeleteStreakFreeze))))
 		newMux.HandleFunc("GET /parent/children", handlers.RequireReady(middleware.RequireAuth(parentHandler.ShowKids)))
 		newMux.HandleFunc("POST /parent/children/create", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(parentHandler.CreateKid))))
 		newMux.HandleFunc("POST /parent/children/{id}/update", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(parentHandler.UpdateKid))))
@@ -248,6 +398,8 @@ func main() {
 		newMux.HandleFunc("POST /parent/children/{id}/delete", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(parentHandler.DeleteKid))))
 		newMux.HandleFunc("GET /parent/children/{id}", handlers.RequireReady(middleware.RequireAuth(kidHandler.GetKidDetails)))
 		newMux.HandleFunc("GET /parent/children/{childId}/struggling-words", handlers.RequireReady(middleware.RequireAuth(kidHandler.GetKidStrugglingWords)))
+		newMux.HandleFunc("POST /parent/children/{id}/leaderboard", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(leaderboardHandler.UpdateLeaderboardPrivacy))))
+		newMux.HandleFunc("GET /parent/leaderboards", handlers.RequireReady(middleware.RequireAuth(leaderboardHandler.ShowLeaderboards)))
 
 		// Protected teacher routes
 		newMux.HandleFunc("GET /teacher/dashboard", handlers.RequireReady(middleware.RequireAuth(teacherHandler.Dashboard)))
@@ -255,13 +407,37 @@ func main() {
 		newMux.HandleFunc("POST /teacher/children/bulk-create", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.BulkCreateKids))))
 		newMux.HandleFunc("POST /teacher/children/link-existing", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.LinkExistingKid))))
 		newMux.HandleFunc("POST /teacher/class/assign-list", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.AssignListToClass))))
+		newMux.HandleFunc("GET /teacher/classes", handlers.RequireReady(middleware.RequireAuth(teacherHandler.ShowClasses)))
+		newMux.HandleFunc("POST /teacher/classes/create", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.CreateClass))))
+		newMux.HandleFunc("GET /teacher/classes/{id}", handlers.RequireReady(middleware.RequireAuth(teacherHandler.ViewClass)))
+		newMux.HandleFunc("POST /teacher/classes/{id}/rename", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.RenameClass))))
+		newMux.HandleFunc("POST /teacher/classes/{id}/archive", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.ArchiveClass))))
+		newMux.HandleFunc("POST /teacher/classes/{id}/restore", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.RestoreClass))))
+		newMux.HandleFunc("POST /teacher/classes/{id}/members/add", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.AddClassMember))))
+		newMux.HandleFunc("POST /teacher/classes/{id}/members/{kidId}/remove", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.RemoveClassMember))))
+		newMux.HandleFunc("POST /teacher/classes/{id}/members/{kidId}/move", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.MoveClassMember))))
+		newMux.HandleFunc("POST /teacher/classes/{id}/assign-list", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.AssignClassList))))
+		newMux.HandleFunc("POST /teacher/classes/{id}/lists/{listId}/unassign", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.UnassignClassList))))
+		newMux.HandleFunc("POST /teacher/classes/{id}/tests/schedule", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.ScheduleSpellingTest))))
+		newMux.HandleFunc("GET /teacher/classes/{id}/tests/{testId}", handlers.RequireReady(middleware.RequireAuth(teacherHandler.ViewSpellingTest)))
+		newMux.HandleFunc("POST /teacher/classes/{id}/tests/{testId}/cancel", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.CancelSpellingTest))))
 		newMux.HandleFunc("POST /teacher/children/{id}/update", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.UpdateKid))))
 		newMux.HandleFunc("POST /teacher/children/{id}/delete", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.DeleteKid))))
 		newMux.HandleFunc("GET /teacher/children/{id}", handlers.RequireReady(middleware.RequireAuth(kidHandler.GetKidDetails)))
+		newMux.HandleFunc("POST /teacher/children/{id}/leaderboard", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(leaderboardHandler.UpdateLeaderboardPrivacy))))
+		newMux.HandleFunc("GET /teacher/leaderboards", handlers.RequireReady(middleware.RequireAuth(leaderboardHandler.ShowLeaderboards)))
+		newMux.HandleFunc("GET /teacher/bees", handlers.RequireReady(middleware.RequireAuth(teacherHandler.ShowSpellingBees)))
+		newMux.HandleFunc("POST /teacher/bees", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.CreateSpellingBee))))
+		newMux.HandleFunc("GET /teacher/bees/{id}", handlers.RequireReady(middleware.RequireAuth(teacherHandler.ViewSpellingBee)))
+		newMux.HandleFunc("GET /teacher/bees/{id}/poll", handlers.RequireReady(middleware.RequireAuth(teacherHandler.PollSpellingBee)))
+		newMux.HandleFunc("POST /teacher/bees/{id}/next", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.NextSpellingBeeWord))))
+		newMux.HandleFunc("POST /teacher/bees/{id}/mark", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.MarkSpellingBeeWord))))
+		newMux.HandleFunc("POST /teacher/bees/{id}/end", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(teacherHandler.EndSpellingBee))))
 		newMux.HandleFunc("GET /teacher/lists", handlers.RequireReady(middleware.RequireAuth(listHandler.ShowLists)))
 		newMux.HandleFunc("POST /teacher/lists/create", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.CreateList))))
 		newMux.HandleFunc("GET /teacher/lists/{id}", handlers.RequireReady(middleware.RequireAuth(listHandler.ViewList)))
 		newMux.HandleFunc("PUT /teacher/lists/{id}", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.UpdateList))))
+		newMux.HandleFunc("POST /teacher/lists/{id}/update", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.UpdateList))))
 		newMux.HandleFunc("POST /teacher/lists/{id}/delete", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.DeleteList))))
 		newMux.HandleFunc("POST /teacher/lists/{id}/words/add", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.AddWord))))
 		newMux.HandleFunc("POST /teacher/lists/{id}/words/bulk-add", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.BulkAddWords))))
@@ -272,11 +448,33 @@ func main() {
 		newMux.HandleFunc("POST /teacher/lists/{listId}/unassign/{childId}", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.UnassignList))))
 		newMux.HandleFunc("POST /teacher/lists/assign-to-child", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.AssignListToKid))))
 
+		// API token management
+		newMux.HandleFunc("GET /account/api-tokens", handlers.RequireReady(middleware.RequireAuth(apiTokenHandler.ShowTokens)))
+		newMux.HandleFunc("POST /account/api-tokens/create", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(apiTokenHandler.CreateToken))))
+		newMux.HandleFunc("POST /account/api-tokens/{id}/revoke", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(apiTokenHandler.RevokeToken))))
+		newMux.HandleFunc("GET /account/notifications", handlers.RequireReady(middleware.RequireAuth(notificationHandler.ShowNotifications)))
+		newMux.HandleFunc("POST /account/notifications", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(notificationHandler.UpdateNotifications))))
+		newMux.HandleFunc("GET /account/sign-in", handlers.RequireReady(middleware.RequireAuth(signInMethodsHandler.ShowSignInMethods)))
+		newMux.HandleFunc("POST /account/sign-in/password", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(signInMethodsHandler.SetPassword))))
+		newMux.HandleFunc("POST /account/sign-in/{provider}/link", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(authHandler.StartOAuthLink))))
+		newMux.HandleFunc("POST /account/sign-in/{provider}/unlink", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(signInMethodsHandler.UnlinkProvider))))
+		newMux.HandleFunc("GET /account/sessions", handlers.RequireReady(middleware.RequireAuth(sessionsHandler.ShowSessions)))
+		newMux.HandleFunc("POST /account/sessions/revoke-others", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(sessionsHandler.RevokeOtherSessions))))
+		newMux.HandleFunc("POST /account/sessions/{handle}/revoke", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(sessionsHandler.RevokeSession))))
+		newMux.HandleFunc("POST /account/sessions/kids/{kidId}/revoke-all", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(sessionsHandler.RevokeKidSessions))))
+		newMux.HandleFunc("POST /account/sessions/kids/{kidId}/{handle}/revoke", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(sessionsHandler.RevokeKidSession))))
+		newMux.HandleFunc("GET /account/two-factor", handlers.RequireReady(middleware.RequireAuth(twoFactorHandler.ShowTwoFactor)))
+		newMux.HandleFunc("POST /account/two-factor/setup", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(twoFactorHandler.StartSetup))))
+		newMux.HandleFunc("POST /account/two-factor/confirm", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(twoFactorHandler.ConfirmSetup))))
+		newMux.HandleFunc("POST /account/two-factor/recovery-codes", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(twoFactorHandler.RegenerateRecoveryCodes))))
+		newMux.HandleFunc("POST /account/two-factor/disable", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(twoFactorHandler.Disable))))
+
 		// Spelling list routes
 		newMux.HandleFunc("GET /parent/lists", handlers.RequireReady(middleware.RequireAuth(listHandler.ShowLists)))
 		newMux.HandleFunc("POST /parent/lists/create", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.CreateList))))
 		newMux.HandleFunc("GET /parent/lists/{id}", handlers.RequireReady(middleware.RequireAuth(listHandler.ViewList)))
 		newMux.HandleFunc("PUT /parent/lists/{id}", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.UpdateList))))
+		newMux.HandleFunc("POST /parent/lists/{id}/update", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.UpdateList))))
 		newMux.HandleFunc("POST /parent/lists/{id}/delete", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.DeleteList))))
 		newMux.HandleFunc("POST /parent/lists/{id}/words/add", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.AddWord))))
 		newMux.HandleFunc("POST /parent/lists/{id}/words/bulk-add", handlers.RequireReady(middleware.RequireAuth(middleware.CSRFProtect(listHandler.BulkAddWords))))
@@ -289,10 +487,11 @@ func main() {
 
 		// Child routes
 		newMux.HandleFunc("GET /child/select", handlers.RequireReady(kidHandler.ShowKidSelect))
-		newMux.HandleFunc("POST /child/login", handlers.RequireReady(kidHandler.KidLogin))
+		newMux.HandleFunc("POST /child/login", handlers.RequireReady(middleware.RateLimit(handlers.KidLoginRateLimit, kidHandler.KidLogin)))
 		newMux.HandleFunc("GET /child/login/{id}", handlers.RequireReady(kidHandler.KidLogin))
-		newMux.HandleFunc("POST /child/login/{id}", handlers.RequireReady(kidHandler.KidLogin))
+		newMux.HandleFunc("POST /child/login/{id}", handlers.RequireReady(middleware.RateLimit(handlers.KidLoginRateLimit, kidHandler.KidLogin)))
 		newMux.HandleFunc("GET /child/dashboard", handlers.RequireReady(middleware.RequireKidAuth(kidHandler.KidDashboard)))
+		newMux.HandleFunc("GET /child/leaderboards", handlers.RequireReady(middleware.RequireKidAuth(leaderboardHandler.ShowKidLeaderboards)))
 		newMux.HandleFunc("POST /child/logout", handlers.RequireReady(kidHandler.KidLogout))
 
 		// Practice routes
@@ -302,6 +501,30 @@ func main() {
 		newMux.HandleFunc("POST /child/practice/exit", handlers.RequireReady(middleware.RequireKidAuth(practiceHandler.ExitPractice)))
 		newMux.HandleFunc("GET /child/practice/results", handlers.RequireReady(middleware.RequireKidAuth(practiceHandler.ShowResults)))
 
+		// Spelling test routes
+		newMux.HandleFunc("POST /child/tests/{id}/start", handlers.RequireReady(middleware.RequireKidAuth(spellingTestHandler.StartTest)))
+		newMux.HandleFunc("GET /child/tests/{id}", handlers.RequireReady(middleware.RequireKidAuth(spellingTestHandler.ShowTest)))
+		newMux.HandleFunc("GET /child/tests/{id}/audio/{n}", handlers.RequireReady(middleware.RequireKidAuth(spellingTestHandler.WordAudio)))
+		newMux.HandleFunc("POST /child/tests/{id}/answer", handlers.RequireReady(middleware.RequireKidAuth(spellingTestHandler.SubmitAnswer)))
+
+		// Spelling bee routes
+		newMux.HandleFunc("GET /child/bees/{id}", handlers.RequireReady(middleware.RequireKidAuth(spellingBeeHandler.ShowBee)))
+		newMux.HandleFunc("GET /child/bees/{id}/poll", handlers.RequireReady(middleware.RequireKidAuth(spellingBeeHandler.Poll)))
+		newMux.HandleFunc("GET /child/bees/{id}/audio", handlers.RequireReady(middleware.RequireKidAuth(spellingBeeHandler.WordAudio)))
+		newMux.HandleFunc("GET /child/bees/{id}/audio/definition", handlers.RequireReady(middleware.RequireKidAuth(spellingBeeHandler.DefinitionAudio)))
+		newMux.HandleFunc("POST /child/bees/{id}/answer", handlers.RequireReady(middleware.RequireKidAuth(spellingBeeHandler.Answer)))
+
+		// Clash routes
+		newMux.HandleFunc("GET /child/clash", handlers.RequireReady(middleware.RequireKidAuth(clashHandler.ShowLobby)))
+		newMux.HandleFunc("GET /child/clash/invites", handlers.RequireReady(middleware.RequireKidAuth(clashHandler.ShowInvites)))
+		newMux.HandleFunc("POST /child/clash/challenge", handlers.RequireReady(middleware.RequireKidAuth(clashHandler.Challenge)))
+		newMux.HandleFunc("GET /child/clash/{id}", handlers.RequireReady(middleware.RequireKidAuth(clashHandler.ShowMatch)))
+		newMux.HandleFunc("GET /child/clash/{id}/events", handlers.RequireReady(middleware.RequireKidAuth(clashHandler.MatchEvents)))
+		newMux.HandleFunc("GET /child/clash/{id}/audio/{round}", handlers.RequireReady(middleware.RequireKidAuth(clashHandler.RoundAudio)))
+		newMux.HandleFunc("POST /child/clash/{id}/accept", handlers.RequireReady(middleware.RequireKidAuth(clashHandler.Accept)))
+		newMux.HandleFunc("POST /child/clash/{id}/cancel", handlers.RequireReady(middleware.RequireKidAuth(clashHandler.Cancel)))
+		newMux.HandleFunc("POST /child/clash/{id}/answer", handlers.RequireReady(middleware.RequireKidAuth(clashHandler.Answer)))
+
 		// Hangman routes
 		newMux.HandleFunc("POST /child/hangman/start/{listId}", handlers.RequireReady(middleware.RequireKidAuth(hangmanHandler.StartHangman)))
 		newMux.HandleFunc("GET /child/hangman/play", handlers.RequireReady(middleware.RequireKidAuth(hangmanHandler.PlayHangman)))
@@ -318,6 +541,26 @@ func main() {
 		newMux.HandleFunc("POST /child/missing-letter/exit", handlers.RequireReady(middleware.RequireKidAuth(missingLetterHandler.ExitGame)))
 		newMux.HandleFunc("GET /child/missing-letter/results", handlers.RequireReady(middleware.RequireKidAuth(missingLetterHandler.ShowResults)))
 
+		// JSON API (token authenticated, no cookies so no CSRF)
+		newMux.HandleFunc("GET /api/v1/me", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.GetMe)))
+		newMux.HandleFunc("GET /api/v1/lists", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.ListLists)))
+		newMux.HandleFunc("POST /api/v1/lists", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.CreateList)))
+		newMux.HandleFunc("GET /api/v1/lists/{id}", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.GetList)))
+		newMux.HandleFunc("PUT /api/v1/lists/{id}", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.UpdateList)))
+		newMux.HandleFunc("DELETE /api/v1/lists/{id}", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.DeleteList)))
+		newMux.HandleFunc("GET /api/v1/lists/{id}/words", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.ListWords)))
+		newMux.HandleFunc("POST /api/v1/lists/{id}/words", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.AddWord)))
+		newMux.HandleFunc("PUT /api/v1/lists/{id}/words/{wordId}", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.UpdateWord)))
+		newMux.HandleFunc("DELETE /api/v1/lists/{id}/words/{wordId}", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.DeleteWord)))
+		newMux.HandleFunc("GET /api/v1/lists/{id}/assignments", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.ListAssignments)))
+		newMux.HandleFunc("PUT /api/v1/lists/{id}/assignments/{kidId}", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.AssignList)))
+		newMux.HandleFunc("DELETE /api/v1/lists/{id}/assignments/{kidId}", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.UnassignList)))
+		newMux.HandleFunc("GET /api/v1/kids", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.ListKids)))
+		newMux.HandleFunc("GET /api/v1/kids/{id}", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.GetKid)))
+		newMux.HandleFunc("GET /api/v1/kids/{id}/sessions", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.ListKidSessions)))
+		newMux.HandleFunc("GET /api/v1/kids/{id}/sessions/{sessionId}", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.GetKidSession)))
+		newMux.HandleFunc("GET /api/v1/kids/{id}/stats", handlers.RequireReady(middleware.RequireAPIToken(apiHandler.GetKidStats)))
+
 		// Admin routes
 		newMux.HandleFunc("GET /admin/dashboard", handlers.RequireReady(middleware.RequireAdmin(adminHandler.ShowAdminDashboard)))
 		newMux.HandleFunc("POST /admin/regenerate-lists", handlers.RequireReady(middleware.RequireAdmin(middleware.CSRFProtect(adminHandler.RegeneratePublicLists))))
@@ -325,6 +568,8 @@ func main() {
 		newMux.HandleFunc("POST /admin/users/create", handlers.RequireReady(middleware.RequireAdmin(middleware.CSRFProtect(adminHandler.CreateUser))))
 		newMux.HandleFunc("POST /admin/users/{id}/update", handlers.RequireReady(middleware.RequireAdmin(middleware.CSRFProtect(adminHandler.UpdateUser))))
 		newMux.HandleFunc("POST /admin/users/{id}/delete", handlers.RequireReady(middleware.RequireAdmin(middleware.CSRFProtect(adminHandler.DeleteUser))))
+		newMux.HandleFunc("POST /admin/users/{id}/reset-two-factor", handlers.RequireReady(middleware.RequireAdmin(middleware.CSRFProtect(adminHandler.ResetTwoFactor))))
+		newMux.HandleFunc("POST /admin/two-factor-policy", handlers.RequireReady(middleware.RequireAdmin(middleware.CSRFProtect(adminHandler.UpdateTwoFactorPolicy))))
 		newMux.HandleFunc("GET /admin/parents", handlers.RequireReady(middleware.RequireAdmin(adminHandler.ShowManageUsers)))
 		newMux.HandleFunc("POST /admin/parents/create", handlers.RequireReady(middleware.RequireAdmin(middleware.CSRFProtect(adminHandler.CreateUser))))
 		newMux.HandleFunc("POST /admin/parents/{id}/update", handlers.RequireReady(middleware.RequireAdmin(middleware.CSRFProtect(adminHandler.UpdateUser))))
@@ -332,6 +577,7 @@ func main() {
 		newMux.HandleFunc("GET /admin/children", handlers.RequireReady(middleware.RequireAdmin(adminHandler.ShowManageKids)))
 		newMux.HandleFunc("POST /admin/children/{id}/update", handlers.RequireReady(middleware.RequireAdmin(middleware.CSRFProtect(adminHandler.UpdateKid))))
 		newMux.HandleFunc("POST /admin/children/{id}/delete", handlers.RequireReady(middleware.RequireAdmin(middleware.CSRFProtect(adminHandler.DeleteKid))))
+		newMux.HandleFunc("POST /admin/email-queue/{id}/retry", handlers.RequireReady(middleware.RequireAdmin(middleware.CSRFProtect(adminHandler.RetryEmail))))
 		newMux.HandleFunc("GET /admin/database", handlers.RequireReady(middleware.RequireAdmin(adminHandler.ShowDatabaseManagement)))
 		newMux.HandleFunc("GET /admin/export", handlers.RequireReady(middleware.RequireAdmin(adminHandler.ExportDatabase)))
 		newMux.HandleFunc("POST /admin/import", handlers.RequireReady(middleware.RequireAdmin(middleware.CSRFProtect(adminHandler.ImportDatabase))))
@@ -347,7 +593,15 @@ func main() {
 		server.Handler = handlers.Logging(newMux)
 
 		// Start background session cleanup
-		go cleanupExpiredSessions(authService, familyService)
+		go cleanupExpiredSessions(authService, familyService, emailService, rateLimitRepo)
+
+		// Start delivering queued emails
+		if emailService != nil {
+			go emailService.RunOutboxWorker(context.Background(), 30*time.Second)
+		}
+
+		// Start sending weekly digests
+		go digestService.RunScheduler(context.Background(), 15*time.Minute)
 
 		// Mark as ready
 		handlers.MarkReady()
@@ -448,6 +702,20 @@ func loadTemplates(templatesPath string) (*template.Template, error) {
 			}
 			return *b
 		},
+		"ordinal": func(n int) string {
+			suffix := "th"
+			if n%100 < 11 || n%100 > 13 {
+				switch n % 10 {
+				case 1:
+					suffix = "st"
+				case 2:
+					suffix = "nd"
+				case 3:
+					suffix = "rd"
+				}
+			}
+			return fmt.Sprintf("%d%s", n, suffix)
+		},
 	}
 
 	// Parse all templates with functions
@@ -459,8 +727,9 @@ func loadTemplates(templatesPath string) (*template.Template, error) {
 	return tmpl, nil
 }
 
-// cleanupExpiredSessions periodically removes expired sessions
-func cleanupExpiredSessions(authService *service.AuthService, familyService *service.FamilyService) {
+// cleanupExpiredSessions periodically removes expired sessions, tokens, rate limits
+// and old emails
+func cleanupExpiredSessions(authService *service.AuthService, familyService *service.FamilyService, emailService *service.EmailService, rateLimitRepo *repository.RateLimitRepository) {
 	ticker := time.NewTicker(1 * time.Hour)
 	defer ticker.Stop()
 
@@ -485,5 +754,26 @@ func cleanupExpiredSessions(authService *service.AuthService, familyService *ser
 		} else {
 			log.Println("Expired password reset tokens cleaned up")
 		}
+
+		// Cleanup two-factor logins that were never finished
+		if err := authService.CleanupExpiredLoginChallenges(); err != nil {
+			log.Printf("Error cleaning up expired login challenges: %v", err)
+		}
+
+		// Cleanup rate limit counters from past windows
+		if rateLimitRepo != nil {
+			if err := rateLimitRepo.DeleteExpiredRateLimits(); err != nil {
+				log.Printf("Error cleaning up expired rate limits: %v", err)
+			}
+		}
+
+		// Cleanup sent and failed emails past their retention period
+		if emailService != nil {
+			if deleted, err := emailService.CleanupOldEmails(); err != nil {
+				log.Printf("Error cleaning up old emails: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Cleaned up %d old emails", deleted)
+			}
+		}
 	}
 }
@@ -0,0 +1,122 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const commandTimeout = 30 * time.Second
+
+// CommandProvider runs a local speech engine such as espeak-ng or piper as a subprocess.
+// The command line may contain {text}, {output}, {locale} (e.g. "en-gb") and {lang}
+// (e.g. "en") placeholders; if {text} is not present the text is written to the
+// command's stdin instead. Put "--" before {text} so a word can't be read as an option.
+//
+// Examples:
+//
+//	espeak-ng -v {locale} -w {output} -- {text}
+//	piper --model /opt/piper/{locale}.onnx --output_file {output}
+type CommandProvider struct {
+	name   string
+	args   []string
+	format string
+}
+
+// NewCommandProvider creates a provider that shells out to a local TTS engine
+func NewCommandProvider(command, format string) (*CommandProvider, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, errors.New("TTS command is required for the command provider")
+	}
+
+	format = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(format), "."))
+	if format == "" {
+		format = "wav"
+	}
+	if format != "wav" && format != "mp3" {
+		return nil, fmt.Errorf("unsupported TTS output format: %s", format)
+	}
+
+	return &CommandProvider{
+		name:   fields[0],
+		args:   fields[1:],
+		format: format,
+	}, nil
+}
+
+func (p *CommandProvider) Name() string {
+	return "command"
+}
+
+func (p *CommandProvider) Extension() string {
+	return p.format
+}
+
+// Synthesize runs the configured command to write speech for text to outputPath
+func (p *CommandProvider) Synthesize(text, locale, outputPath string) error {
+	args, usesText, err := expandCommandArgs(p.args, text, locale, outputPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.name, args...)
+	if !usesText {
+		cmd.Stdin = strings.NewReader(text)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// Don't leave a partial file behind to be served from the cache
+		os.Remove(outputPath)
+		return fmt.Errorf("failed to run %s: %w: %s", p.name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	info, err := os.Stat(outputPath)
+	if err != nil {
+		return fmt.Errorf("%s did not write an audio file: %w", p.name, err)
+	}
+	if info.Size() == 0 {
+		os.Remove(outputPath)
+		return fmt.Errorf("%s wrote an empty audio file", p.name)
+	}
+
+	return nil
+}
+
+// expandCommandArgs substitutes the {text}, {output}, {locale} and {lang} placeholders in each argument.
+// Substitution happens per argument so text containing spaces stays a single argument.
+// It reports whether the text was passed as an argument. Text starting with "-"
+// is refused where the engine would parse it as an option, i.e. at the start of
+// an argument that doesn't follow "--".
+func expandCommandArgs(args []string, text, locale, outputPath string) ([]string, bool, error) {
+	expanded := make([]string, len(args))
+	usesText := false
+	endOfOptions := false
+	for i, arg := range args {
+		if strings.Contains(arg, "{text}") {
+			usesText = true
+			if !endOfOptions && strings.HasPrefix(arg, "{text}") && strings.HasPrefix(text, "-") {
+				return nil, false, fmt.Errorf("refusing to pass %q to the TTS command as it would be read as an option; put -- before {text}", text)
+			}
+		}
+		if arg == "--" {
+			endOfOptions = true
+		}
+		arg = strings.ReplaceAll(arg, "{text}", text)
+		arg = strings.ReplaceAll(arg, "{output}", outputPath)
+		arg = strings.ReplaceAll(arg, "{locale}", localeKey(locale))
+		arg = strings.ReplaceAll(arg, "{lang}", localeLanguage(locale))
+		expanded[i] = arg
+	}
+	return expanded, usesText, nil
+}
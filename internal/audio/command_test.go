@@ -0,0 +1,151 @@
+package audio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandCommandArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		expected     []string
+		expectedText bool
+		text         string
+		wantErr      bool
+	}{
+		{
+			name:         "text and output placeholders",
+			args:         []string{"-v", "{locale}", "-w", "{output}", "{text}"},
+			expected:     []string{"-v", "en-gb", "-w", "/tmp/word_ice_cream_en-gb.wav", "ice cream"},
+			expectedText: true,
+		},
+		{
+			name:         "text via stdin",
+			args:         []string{"--model", "{lang}.onnx", "--output_file", "{output}"},
+			expected:     []string{"--model", "en.onnx", "--output_file", "/tmp/word_ice_cream_en-gb.wav"},
+			expectedText: false,
+		},
+		{
+			name:         "placeholder inside argument",
+			args:         []string{"--out={output}"},
+			expected:     []string{"--out=/tmp/word_ice_cream_en-gb.wav"},
+			expectedText: false,
+		},
+		{
+			name:    "text that looks like an option",
+			args:    []string{"-w", "{output}", "{text}"},
+			text:    "-w/etc/passwd",
+			wantErr: true,
+		},
+		{
+			name:         "text that looks like an option after --",
+			args:         []string{"-w", "{output}", "--", "{text}"},
+			text:         "-w/etc/passwd",
+			expected:     []string{"-w", "/tmp/word_ice_cream_en-gb.wav", "--", "-w/etc/passwd"},
+			expectedText: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := tt.text
+			if text == "" {
+				text = "ice cream"
+			}
+			got, usesText, err := expandCommandArgs(tt.args, text, "en-GB", "/tmp/word_ice_cream_en-gb.wav")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expandCommandArgs() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandCommandArgs() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expandCommandArgs() = %q, want %q", got, tt.expected)
+			}
+			if usesText != tt.expectedText {
+				t.Errorf("usesText = %v, want %v", usesText, tt.expectedText)
+			}
+		})
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		cfg       ProviderConfig
+		name      string
+		extension string
+		wantErr   bool
+	}{
+		{cfg: ProviderConfig{}, name: "google", extension: "mp3"},
+		{cfg: ProviderConfig{Provider: "none"}, name: "none", extension: "mp3"},
+		{cfg: ProviderConfig{Provider: "command", Command: "espeak-ng -w {output} {text}"}, name: "command", extension: "wav"},
+		{cfg: ProviderConfig{Provider: "command", Command: "piper", Format: "MP3"}, name: "command", extension: "mp3"},
+		{cfg: ProviderConfig{Provider: "command"}, wantErr: true},
+		{cfg: ProviderConfig{Provider: "command", Command: "espeak-ng", Format: "ogg"}, wantErr: true},
+		{cfg: ProviderConfig{Provider: "polly"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		provider, err := NewProvider(tt.cfg)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NewProvider(%+v) expected error", tt.cfg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewProvider(%+v) unexpected error: %v", tt.cfg, err)
+			continue
+		}
+		if provider.Name() != tt.name || provider.Extension() != tt.extension {
+			t.Errorf("NewProvider(%+v) = %s/%s, want %s/%s", tt.cfg, provider.Name(), provider.Extension(), tt.name, tt.extension)
+		}
+	}
+}
+
+func TestNoneProviderServesCachedAudio(t *testing.T) {
+	dir := t.TempDir()
+	service := NewTTSService(dir, NewNoneProvider())
+
+	if service.Enabled() {
+		t.Error("none provider should report TTS as disabled")
+	}
+	if _, err := service.GenerateAudioFile("cat", "en-GB"); !errors.Is(err, ErrTTSDisabled) {
+		t.Errorf("GenerateAudioFile() error = %v, want ErrTTSDisabled", err)
+	}
+
+	// Audio generated earlier by another provider is still returned
+	if err := os.WriteFile(filepath.Join(dir, "word_ice_cream_en-gb.wav"), []byte("RIFF"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	filename, err := service.GenerateAudioFile("Ice Cream", "en-GB")
+	if err != nil || filename != "word_ice_cream_en-gb.wav" {
+		t.Errorf("GenerateAudioFile() = %q, %v, want cached word_ice_cream_en-gb.wav", filename, err)
+	}
+
+	// The same word in another accent is cached separately
+	if _, err := service.GenerateAudioFile("Ice Cream", "en-US"); !errors.Is(err, ErrTTSDisabled) {
+		t.Errorf("GenerateAudioFile() for en-US error = %v, want ErrTTSDisabled", err)
+	}
+}
+
+func TestGoogleLanguage(t *testing.T) {
+	tests := map[string]string{
+		"en-GB": "en-gb",
+		"en_AU": "en-au",
+		"fr-FR": "fr",
+		"":      "en",
+	}
+	for locale, expected := range tests {
+		if got := googleLanguage(locale); got != expected {
+			t.Errorf("googleLanguage(%q) = %q, want %q", locale, got, expected)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const ttsRequestTimeout = 10 * time.Second
+
+// GoogleProvider uses Google Translate's text-to-speech API
+// This is a simple, free option that doesn't require API keys
+type GoogleProvider struct {
+	client *http.Client
+}
+
+// NewGoogleProvider creates a provider backed by Google Translate TTS
+func NewGoogleProvider() *GoogleProvider {
+	return &GoogleProvider{
+		client: &http.Client{Timeout: ttsRequestTimeout},
+	}
+}
+
+func (p *GoogleProvider) Name() string {
+	return "google"
+}
+
+func (p *GoogleProvider) Extension() string {
+	return "mp3"
+}
+
+// Synthesize downloads speech for text from Google Translate and saves it as MP3
+func (p *GoogleProvider) Synthesize(text, locale, outputPath string) error {
+	// Google Translate TTS endpoint
+	baseURL := "https://translate.google.com/translate_tts"
+
+	// Build URL with parameters
+	params := url.Values{}
+	params.Set("ie", "UTF-8")
+	params.Set("q", text)
+	params.Set("tl", googleLanguage(locale))
+	params.Set("client", "tw-ob")
+	params.Set("textlen", fmt.Sprintf("%d", len(text)))
+
+	fullURL := baseURL + "?" + params.Encode()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ttsRequestTimeout)
+	defer cancel()
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set user agent (required by Google)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+
+	// Make request
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch audio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// Create output file
+	outFile, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer outFile.Close()
+
+	// Copy audio data to file
+	_, err = io.Copy(outFile, resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to write audio file: %w", err)
+	}
+
+	return nil
+}
+
+// googleLanguage maps a locale to the language code Google Translate expects.
+// English accents are selected with regional codes (en-gb, en-au); other
+// languages only take the base language.
+func googleLanguage(locale string) string {
+	if localeLanguage(locale) == "en" {
+		return localeKey(locale)
+	}
+	return localeLanguage(locale)
+}
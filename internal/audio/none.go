@@ -0,0 +1,22 @@
+package audio
+
+// NoneProvider is used when audio generation is switched off.
+// Existing audio files are still served, but nothing new is generated.
+type NoneProvider struct{}
+
+// NewNoneProvider creates a provider that never generates audio
+func NewNoneProvider() *NoneProvider {
+	return &NoneProvider{}
+}
+
+func (p *NoneProvider) Name() string {
+	return "none"
+}
+
+func (p *NoneProvider) Extension() string {
+	return "mp3"
+}
+
+func (p *NoneProvider) Synthesize(text, locale, outputPath string) error {
+	return ErrTTSDisabled
+}
@@ -0,0 +1,63 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTTSDisabled is returned when audio is requested but no TTS engine is configured
+var ErrTTSDisabled = errors.New("text-to-speech is disabled")
+
+// TTSProvider synthesises speech for a piece of text
+type TTSProvider interface {
+	// Name identifies the provider in logs and configuration
+	Name() string
+
+	// Extension returns the file extension (without dot) of the audio the provider writes
+	Extension() string
+
+	// Synthesize converts text to speech in the given locale (e.g. "en-GB", "fr-FR")
+	// and writes the audio to outputPath
+	Synthesize(text, locale, outputPath string) error
+}
+
+// ProviderConfig holds the settings used to construct a TTS provider
+type ProviderConfig struct {
+	Provider string // "google", "command" or "none"
+	Command  string // Command line for the command provider, e.g. "espeak-ng -w {output} -- {text}"
+	Format   string // Audio format written by the command provider ("wav" or "mp3")
+}
+
+// fallbackLocale is used when no locale is given
+const fallbackLocale = "en"
+
+// localeKey normalises a locale for use in filenames and engine voice names (e.g. "en-gb")
+func localeKey(locale string) string {
+	key := strings.ToLower(strings.TrimSpace(locale))
+	key = strings.ReplaceAll(key, "_", "-")
+	if key == "" {
+		return fallbackLocale
+	}
+	return key
+}
+
+// localeLanguage returns the language part of a locale (e.g. "fr" for "fr-FR")
+func localeLanguage(locale string) string {
+	lang, _, _ := strings.Cut(localeKey(locale), "-")
+	return lang
+}
+
+// NewProvider creates the TTS provider selected by the configuration
+func NewProvider(cfg ProviderConfig) (TTSProvider, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Provider)) {
+	case "google", "":
+		return NewGoogleProvider(), nil
+	case "command", "local":
+		return NewCommandProvider(cfg.Command, cfg.Format)
+	case "none", "off", "disabled":
+		return NewNoneProvider(), nil
+	default:
+		return nil, fmt.Errorf("unsupported TTS provider: %s", cfg.Provider)
+	}
+}
@@ -1,143 +1,94 @@
 package audio
 
 import (
-	"context"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 )
 
+// audioExtensions lists the audio formats that may be found in the audio directory
+var audioExtensions = []string{"mp3", "wav"}
+
 // TTSService provides text-to-speech functionality
 type TTSService struct {
 	audioDir string
+	provider TTSProvider
 }
 
-const ttsRequestTimeout = 10 * time.Second
-
-// NewTTSService creates a new TTS service
-func NewTTSService(audioDir string) *TTSService {
+// NewTTSService creates a new TTS service that generates audio with the given provider
+func NewTTSService(audioDir string, provider TTSProvider) *TTSService {
+	if provider == nil {
+		provider = NewNoneProvider()
+	}
 	return &TTSService{
 		audioDir: audioDir,
+		provider: provider,
 	}
 }
 
-// GenerateAudioFile converts text to speech and saves as MP3
-// Returns the filename (not full path) on success
-func (s *TTSService) GenerateAudioFile(text string) (string, error) {
-	// Sanitize text for filename
-	sanitized := strings.ToLower(strings.TrimSpace(text))
-	sanitized = strings.ReplaceAll(sanitized, " ", "_")
-
-	// Create filename
-	filename := fmt.Sprintf("word_%s.mp3", sanitized)
-	filepath := filepath.Join(s.audioDir, filename)
-
-	// Check if file already exists
-	if _, err := os.Stat(filepath); err == nil {
-		// File already exists, return existing filename
-		return filename, nil
-	}
+// ProviderName returns the name of the configured TTS provider
+func (s *TTSService) ProviderName() string {
+	return s.provider.Name()
+}
 
-	// Generate audio using Google Translate TTS (free, no API key needed)
-	if err := s.generateUsingGoogleTTS(text, filepath); err != nil {
-		return "", fmt.Errorf("failed to generate audio: %w", err)
-	}
+// Enabled reports whether the service can generate new audio files
+func (s *TTSService) Enabled() bool {
+	_, disabled := s.provider.(*NoneProvider)
+	return !disabled
+}
 
-	return filename, nil
+// AudioPath returns the full path of a file in the audio directory
+func (s *TTSService) AudioPath(filename string) string {
+	return filepath.Join(s.audioDir, filepath.Base(filename))
 }
 
-// GenerateAudioFileWithPrefix converts text to speech and saves as MP3 with a custom filename prefix
+// GenerateAudioFile converts text to speech in the given locale and saves it in the provider's format
 // Returns the filename (not full path) on success
-func (s *TTSService) GenerateAudioFileWithPrefix(text, prefix string) (string, error) {
-	// Sanitize prefix for filename
-	sanitized := strings.ToLower(strings.TrimSpace(prefix))
-	sanitized = strings.ReplaceAll(sanitized, " ", "_")
+func (s *TTSService) GenerateAudioFile(text, locale string) (string, error) {
+	return s.GenerateAudioFileWithPrefix(text, "word_"+text, locale)
+}
 
-	// Create filename with custom prefix
-	filename := fmt.Sprintf("%s.mp3", sanitized)
-	filepath := filepath.Join(s.audioDir, filename)
+// GenerateAudioFileWithPrefix converts text to speech in the given locale and saves it with a custom filename prefix
+// The locale is part of the filename so the same text in different accents is cached separately
+// Returns the filename (not full path) on success
+func (s *TTSService) GenerateAudioFileWithPrefix(text, prefix, locale string) (string, error) {
+	base := sanitizeFilename(prefix) + "_" + localeKey(locale)
+	return s.generate(text, locale, base)
+}
 
-	// Check if file already exists
-	if _, err := os.Stat(filepath); err == nil {
-		// File already exists, return existing filename
-		return filename, nil
+// generate returns the cached audio file for base if one exists, otherwise
+// asks the provider to create it
+func (s *TTSService) generate(text, locale, base string) (string, error) {
+	// Check if file already exists in any supported format, so audio generated
+	// by a previous provider keeps being served
+	for _, ext := range audioExtensions {
+		filename := base + "." + ext
+		if _, err := os.Stat(filepath.Join(s.audioDir, filename)); err == nil {
+			return filename, nil
+		}
 	}
 
-	// Generate audio using Google Translate TTS (free, no API key needed)
-	if err := s.generateUsingGoogleTTS(text, filepath); err != nil {
+	filename := base + "." + s.provider.Extension()
+	if err := s.provider.Synthesize(text, locale, filepath.Join(s.audioDir, filename)); err != nil {
 		return "", fmt.Errorf("failed to generate audio: %w", err)
 	}
 
 	return filename, nil
 }
 
-// generateUsingGoogleTTS uses Google Translate's text-to-speech API
-// This is a simple, free option that doesn't require API keys
-func (s *TTSService) generateUsingGoogleTTS(text, outputPath string) error {
-	// Google Translate TTS endpoint
-	baseURL := "https://translate.google.com/translate_tts"
-
-	// Build URL with parameters
-	params := url.Values{}
-	params.Set("ie", "UTF-8")
-	params.Set("q", text)
-	params.Set("tl", "en")
-	params.Set("client", "tw-ob")
-	params.Set("textlen", fmt.Sprintf("%d", len(text)))
-
-	fullURL := baseURL + "?" + params.Encode()
-
-	ctx, cancel := context.WithTimeout(context.Background(), ttsRequestTimeout)
-	defer cancel()
-
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set user agent (required by Google)
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-
-	// Make request
-	client := &http.Client{Timeout: ttsRequestTimeout}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to fetch audio: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	// Create output file
-	outFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
-	}
-	defer outFile.Close()
-
-	// Copy audio data to file
-	_, err = io.Copy(outFile, resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to write audio file: %w", err)
-	}
-
-	return nil
+// sanitizeFilename lowercases text and replaces spaces so it can be used in a filename
+func sanitizeFilename(text string) string {
+	sanitized := strings.ToLower(strings.TrimSpace(text))
+	return strings.ReplaceAll(sanitized, " ", "_")
 }
 
-// BatchGenerateAudio generates audio files for multiple words
-func (s *TTSService) BatchGenerateAudio(words []string) (map[string]string, error) {
+// BatchGenerateAudio generates audio files for multiple words in the same locale
+func (s *TTSService) BatchGenerateAudio(words []string, locale string) (map[string]string, error) {
 	results := make(map[string]string)
 
 	for _, word := range words {
-		filename, err := s.GenerateAudioFile(word)
+		filename, err := s.GenerateAudioFile(word, locale)
 		if err != nil {
 			return results, fmt.Errorf("failed to generate audio for '%s': %w", word, err)
 		}
@@ -159,7 +110,7 @@ func (s *TTSService) DeleteAudioFile(filename string) error {
 	return os.Remove(filepath)
 }
 
-// GetAllAudioFiles returns a list of all MP3 files in the audio directory
+// GetAllAudioFiles returns a list of all audio files in the audio directory
 func (s *TTSService) GetAllAudioFiles() ([]string, error) {
 	files, err := os.ReadDir(s.audioDir)
 	if err != nil {
@@ -168,10 +119,21 @@ func (s *TTSService) GetAllAudioFiles() ([]string, error) {
 
 	var audioFiles []string
 	for _, file := range files {
-		if !file.IsDir() && filepath.Ext(file.Name()) == ".mp3" {
+		if !file.IsDir() && isAudioFile(file.Name()) {
 			audioFiles = append(audioFiles, file.Name())
 		}
 	}
 
 	return audioFiles, nil
 }
+
+// isAudioFile reports whether filename has one of the supported audio extensions
+func isAudioFile(filename string) bool {
+	ext := strings.TrimPrefix(filepath.Ext(filename), ".")
+	for _, supported := range audioExtensions {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
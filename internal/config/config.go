@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -24,14 +25,51 @@ type Config struct {
 	FacebookClientSecret string
 	AppleClientID        string
 	AppleClientSecret    string
-	// Email settings (Amazon SES)
-	AWSRegion    string
-	SESFromEmail string
-	SESFromName  string
+	// Generic OpenID Connect provider, e.g. Microsoft Entra ID, Keycloak or Authentik
+	OIDCDiscoveryURL  string // Issuer URL, optionally ending in /.well-known/openid-configuration
+	OIDCClientID      string
+	OIDCClientSecret  string
+	OIDCLabel         string // Button text on the login page
+	OIDCScopes        []string
+	OIDCEmailClaim    string   // Claim holding the user's email address
+	OIDCNameClaim     string   // Claim holding the user's display name
+	OIDCTeacherClaim  string   // Claim holding roles or groups, e.g. "groups" or "realm_access.roles"
+	OIDCTeacherValues []string // Values of OIDCTeacherClaim that make the user a teacher
+	// Email settings
+	EmailBackend  string // "ses", "smtp", "file" or "none"
+	EmailFrom     string // Sender address for outgoing emails
+	EmailFromName string // Display name for outgoing emails
+	AWSRegion     string // Region used by the SES backend
+	SMTPHost      string
+	SMTPPort      int    // Defaults to 587, 465 or 25 depending on SMTPTLS
+	SMTPUsername  string
+	SMTPPassword  string
+	SMTPTLS       string // "starttls", "tls" or "none"
+	EmailFileDir  string // Maildir the file backend writes messages to
+	EmailLocale   string // Locale used for emails when the recipient's language isn't known
+	// Branding used in email templates
+	BrandName            string
+	BrandPrimaryColor    string
+	BrandBackgroundColor string
+	BrandLogoURL         string // Absolute URL, as email clients can't load relative images
+	// Weekly progress digest schedule
+	DigestDay      string // Day of the week digests are sent, e.g. "sunday"
+	DigestHour     int    // Hour of the day (0-23) digests are sent
+	DigestTimezone string // IANA time zone for the schedule; empty for the server's zone
+	// Default IANA time zone daily streaks are counted in, for families that haven't
+	// chosen one; empty for the server's zone
+	StreakTimezone string
+	// Text-to-speech settings
+	TTSProvider string // "google", "command" or "none"
+	TTSCommand  string // Command line for the command provider, e.g. "espeak-ng -w {output} -- {text}"
+	TTSFormat   string // Audio format written by the command provider ("wav" or "mp3")
 	AppBaseURL   string // Base URL for email links (e.g., https://spellingclash.com)
 	Version      string // Application version
 	DebugLogging bool   // Enable debug logging
 	CSRFSecret   string // Secret key for HMAC CSRF token generation
+	APIRateLimit int    // Requests per minute allowed for each API token
+	RateLimitBackend string // "database" to share rate limits between replicas, or "memory"
+	TrustedProxies   string // Comma-separated proxy addresses or CIDR ranges whose X-Forwarded-For is believed
 	InviteOnlyMode            bool // Invite-only mode value from env
 	InviteOnlyModeConfigured  bool // Whether invite-only mode was explicitly set via env
 }
@@ -39,6 +77,13 @@ type Config struct {
 // Load reads configuration from environment variables with sensible defaults
 func Load() *Config {
 	inviteOnlyMode, inviteOnlyModeConfigured := parseOptionalBoolEnv("WORDCLASH_INVITE_ONLY")
+	// SES_FROM_EMAIL is still read so existing deployments keep working
+	emailFrom := getEnv("EMAIL_FROM", getEnv("SES_FROM_EMAIL", ""))
+	// Hour 0 is valid, so this can't use getEnvInt; an invalid value is reported at startup
+	digestHour, err := strconv.Atoi(getEnv("DIGEST_HOUR", "18"))
+	if err != nil {
+		digestHour = -1
+	}
 
 	return &Config{
 		ServerPort:           getEnv("PORT", "8080"),
@@ -57,17 +102,57 @@ func Load() *Config {
 		FacebookClientSecret: getEnv("FACEBOOK_CLIENT_SECRET", ""),
 		AppleClientID:        getEnv("APPLE_CLIENT_ID", ""),
 		AppleClientSecret:    getEnv("APPLE_CLIENT_SECRET", ""),
+		OIDCDiscoveryURL:     getEnv("OIDC_DISCOVERY_URL", ""),
+		OIDCClientID:         getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:     getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCLabel:            getEnv("OIDC_LABEL", "Single Sign-On"),
+		OIDCScopes:           strings.Fields(getEnv("OIDC_SCOPES", "openid email profile")),
+		OIDCEmailClaim:       getEnv("OIDC_EMAIL_CLAIM", "email"),
+		OIDCNameClaim:        getEnv("OIDC_NAME_CLAIM", "name"),
+		OIDCTeacherClaim:     getEnv("OIDC_TEACHER_CLAIM", ""),
+		OIDCTeacherValues:    splitList(getEnv("OIDC_TEACHER_VALUES", "teacher")),
+		EmailBackend:         getEnv("EMAIL_BACKEND", defaultEmailBackend(emailFrom)),
+		EmailFrom:            emailFrom,
+		EmailFromName:        getEnv("EMAIL_FROM_NAME", getEnv("SES_FROM_NAME", "WordClash")),
 		AWSRegion:            getEnv("AWS_REGION", "us-east-1"),
-		SESFromEmail:         getEnv("SES_FROM_EMAIL", ""),
-		SESFromName:          getEnv("SES_FROM_NAME", "WordClash"),
+		SMTPHost:             getEnv("SMTP_HOST", ""),
+		SMTPPort:             getEnvInt("SMTP_PORT", 0),
+		SMTPUsername:         getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:         getEnv("SMTP_PASSWORD", ""),
+		SMTPTLS:              getEnv("SMTP_TLS", "starttls"),
+		EmailFileDir:         getEnv("EMAIL_FILE_DIR", "./mail"),
+		EmailLocale:          getEnv("EMAIL_LOCALE", "en-GB"),
+		BrandName:            getEnv("BRAND_NAME", "WordClash"),
+		BrandPrimaryColor:    getEnv("BRAND_PRIMARY_COLOR", "#4a90e2"),
+		BrandBackgroundColor: getEnv("BRAND_BACKGROUND_COLOR", "#f9f9f9"),
+		BrandLogoURL:         getEnv("BRAND_LOGO_URL", ""),
+		DigestDay:            getEnv("DIGEST_DAY", "sunday"),
+		DigestHour:           digestHour,
+		DigestTimezone:       getEnv("DIGEST_TIMEZONE", ""),
+		StreakTimezone:       getEnv("STREAK_TIMEZONE", ""),
+		TTSProvider:          getEnv("TTS_PROVIDER", "google"),
+		TTSCommand:           getEnv("TTS_COMMAND", ""),
+		TTSFormat:            getEnv("TTS_FORMAT", "wav"),
 		AppBaseURL:           getEnv("APP_BASE_URL", "http://localhost:8080"),
 		DebugLogging:         getEnv("DEBUG_LOGGING", "false") == "true",
 		CSRFSecret:           getEnv("CSRF_SECRET", "change-me-in-production"),
+		APIRateLimit:         getEnvInt("API_RATE_LIMIT", 60),
+		RateLimitBackend:     getEnv("RATE_LIMIT_BACKEND", "database"),
+		TrustedProxies:       getEnv("TRUSTED_PROXIES", ""),
 		InviteOnlyMode:       inviteOnlyMode,
 		InviteOnlyModeConfigured: inviteOnlyModeConfigured,
 	}
 }
 
+// defaultEmailBackend keeps the old behaviour when EMAIL_BACKEND is not set:
+// SES if a sender address is configured, otherwise no email
+func defaultEmailBackend(emailFrom string) string {
+	if emailFrom != "" {
+		return "ses"
+	}
+	return "none"
+}
+
 // getEnv reads an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -76,6 +161,15 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt reads a positive integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
 // parseOptionalBoolEnv returns (value, configured).
 // Configured is false when the variable is not set or unrecognized.
 func parseOptionalBoolEnv(key string) (bool, bool) {
@@ -93,3 +187,14 @@ func parseOptionalBoolEnv(key string) (bool, bool) {
 		return false, false
 	}
 }
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -34,6 +34,23 @@ type Dialect interface {
 
 	// UpsertSettings returns the SQL query for upserting a setting (key-value pair)
 	UpsertSettings() string
+
+	// ResetSequenceQuery returns the SQL to move a table's ID sequence past its
+	// largest ID after rows were inserted with explicit IDs, or "" if the
+	// database does this itself
+	ResetSequenceQuery(table, column string) string
+
+	// TimestampAtOrAfter returns a condition that is true when a timestamp
+	// column is at or after the time bound to a ? placeholder
+	TimestampAtOrAfter(column string) string
+
+	// IncrementRateLimit returns the SQL that adds a hit to a rate limit bucket,
+	// creating the bucket with the expiry bound to the second placeholder
+	IncrementRateLimit() string
+
+	// UpsertWordSchedule returns the SQL that stores a kid's schedule for a word,
+	// replacing the one already there
+	UpsertWordSchedule() string
 }
 
 // DialectConfig holds configuration for database connection
@@ -72,3 +72,26 @@ func (d *MySQLDialect) UpsertSettings() string {
 	return "INSERT INTO settings (`key`, `value`) VALUES (?, ?) " +
 		"ON DUPLICATE KEY UPDATE `value` = VALUES(`value`), updated_at = CURRENT_TIMESTAMP"
 }
+
+func (d *MySQLDialect) ResetSequenceQuery(table, column string) string {
+	// AUTO_INCREMENT always continues from the largest ID
+	return ""
+}
+
+func (d *MySQLDialect) TimestampAtOrAfter(column string) string {
+	return column + " >= ?"
+}
+
+func (d *MySQLDialect) IncrementRateLimit() string {
+	return "INSERT INTO rate_limits (bucket, hits, expires_at) VALUES (?, 1, ?) " +
+		"ON DUPLICATE KEY UPDATE hits = hits + 1"
+}
+
+func (d *MySQLDialect) UpsertWordSchedule() string {
+	return "INSERT INTO word_schedules " +
+		"(kid_id, word_id, repetitions, interval_days, ease_factor, lapses, due_at, last_reviewed_at, updated_at) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP) " +
+		"ON DUPLICATE KEY UPDATE repetitions = VALUES(repetitions), interval_days = VALUES(interval_days), " +
+		"ease_factor = VALUES(ease_factor), lapses = VALUES(lapses), due_at = VALUES(due_at), " +
+		"last_reviewed_at = VALUES(last_reviewed_at), updated_at = CURRENT_TIMESTAMP"
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -69,3 +70,27 @@ func (d *PostgresDialect) UpsertSettings() string {
 	return `INSERT INTO settings (key, value) VALUES ($1, $2) 
 	        ON CONFLICT(key) DO UPDATE SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP`
 }
+
+func (d *PostgresDialect) ResetSequenceQuery(table, column string) string {
+	return fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE(MAX(%s), 0) + 1, false) FROM %s",
+		table, column, column, table)
+}
+
+func (d *PostgresDialect) TimestampAtOrAfter(column string) string {
+	return column + " >= ?"
+}
+
+func (d *PostgresDialect) IncrementRateLimit() string {
+	return `INSERT INTO rate_limits (bucket, hits, expires_at) VALUES ($1, 1, $2)
+	        ON CONFLICT(bucket) DO UPDATE SET hits = rate_limits.hits + 1`
+}
+
+func (d *PostgresDialect) UpsertWordSchedule() string {
+	return `INSERT INTO word_schedules
+	        (kid_id, word_id, repetitions, interval_days, ease_factor, lapses, due_at, last_reviewed_at, updated_at)
+	        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP)
+	        ON CONFLICT(kid_id, word_id) DO UPDATE SET
+	        repetitions = EXCLUDED.repetitions, interval_days = EXCLUDED.interval_days,
+	        ease_factor = EXCLUDED.ease_factor, lapses = EXCLUDED.lapses, due_at = EXCLUDED.due_at,
+	        last_reviewed_at = EXCLUDED.last_reviewed_at, updated_at = CURRENT_TIMESTAMP`
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -77,3 +78,29 @@ func (d *SQLiteDialect) UpsertSettings() string {
 	return `INSERT INTO settings (key, value) VALUES (?, ?) 
 	        ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP`
 }
+
+func (d *SQLiteDialect) ResetSequenceQuery(table, column string) string {
+	// AUTOINCREMENT always continues from the largest ID
+	return ""
+}
+
+func (d *SQLiteDialect) TimestampAtOrAfter(column string) string {
+	// Timestamps are stored as text in several layouts (CURRENT_TIMESTAMP has no
+	// time zone, bound times have an offset), so compare them normalized to UTC
+	return fmt.Sprintf("datetime(%s) >= datetime(?)", column)
+}
+
+func (d *SQLiteDialect) IncrementRateLimit() string {
+	return `INSERT INTO rate_limits (bucket, hits, expires_at) VALUES (?, 1, ?)
+	        ON CONFLICT(bucket) DO UPDATE SET hits = rate_limits.hits + 1`
+}
+
+func (d *SQLiteDialect) UpsertWordSchedule() string {
+	return `INSERT INTO word_schedules
+	        (kid_id, word_id, repetitions, interval_days, ease_factor, lapses, due_at, last_reviewed_at, updated_at)
+	        VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	        ON CONFLICT(kid_id, word_id) DO UPDATE SET
+	        repetitions = excluded.repetitions, interval_days = excluded.interval_days,
+	        ease_factor = excluded.ease_factor, lapses = excluded.lapses, due_at = excluded.due_at,
+	        last_reviewed_at = excluded.last_reviewed_at, updated_at = CURRENT_TIMESTAMP`
+}
@@ -56,6 +56,14 @@ func TestDialectPostgreSQL(t *testing.T) {
 			t.Errorf("MigrationsSubdir() = %v, want %v", result, expected)
 		}
 	})
+
+	t.Run("ResetSequenceQuery", func(t *testing.T) {
+		result := dialect.ResetSequenceQuery("users", "id")
+		expected := "SELECT setval(pg_get_serial_sequence('users', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM users"
+		if result != expected {
+			t.Errorf("ResetSequenceQuery() = %v, want %v", result, expected)
+		}
+	})
 }
 
 func TestDialectMySQL(t *testing.T) {
@@ -0,0 +1,333 @@
+// Package dictionary provides offline word lookups used to check spellings.
+//
+// Word lists are embedded in the binary, one lowercase word per line, gzipped.
+// words/<lang>.txt.gz holds the words shared by every region of a language, and
+// words/<lang>-<region>.txt.gz holds the extra spellings for that region
+// (e.g. "colour" in en-gb, "color" in en-us). The English lists were generated
+// from the English spell files shipped with Vim, keeping purely alphabetic words.
+//
+// Additional lists can be loaded at startup from a directory with LoadDir, using
+// the same naming scheme with plain .txt files (e.g. data/dictionary/fr.txt).
+package dictionary
+
+import (
+	"bufio"
+	"compress/gzip"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:embed words/*.txt.gz
+var embeddedWords embed.FS
+
+// regionAliases maps locales without their own word list to the closest list
+var regionAliases = map[string]string{
+	"en-ie": "en-gb",
+	"en-za": "en-gb",
+	"en-in": "en-gb",
+}
+
+// wordSet is a set of words, indexed by length for suggestions
+type wordSet struct {
+	words    map[string]struct{}
+	byLength map[int][]string
+}
+
+func newWordSet() *wordSet {
+	return &wordSet{
+		words:    make(map[string]struct{}),
+		byLength: make(map[int][]string),
+	}
+}
+
+func (ws *wordSet) add(word string) {
+	if _, exists := ws.words[word]; exists {
+		return
+	}
+	ws.words[word] = struct{}{}
+	n := len([]rune(word))
+	ws.byLength[n] = append(ws.byLength[n], word)
+}
+
+func (ws *wordSet) contains(word string) bool {
+	_, ok := ws.words[word]
+	return ok
+}
+
+// Dictionary holds word lists keyed by language ("en") and locale ("en-gb")
+type Dictionary struct {
+	sets map[string]*wordSet
+}
+
+// Load creates a dictionary from the embedded word lists
+func Load() (*Dictionary, error) {
+	d := &Dictionary{sets: make(map[string]*wordSet)}
+
+	files, err := embeddedWords.ReadDir("words")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded word lists: %w", err)
+	}
+
+	for _, file := range files {
+		f, err := embeddedWords.Open("words/" + file.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to open word list %s: %w", file.Name(), err)
+		}
+		err = d.readList(strings.TrimSuffix(file.Name(), ".txt.gz"), f, true)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d.sortSets()
+	return d, nil
+}
+
+// LoadDir adds the plain-text word lists (<locale>.txt) found in dir.
+// A missing directory is not an error.
+func (d *Dictionary) LoadDir(dir string) error {
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read dictionary directory %s: %w", dir, err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".txt" {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to open word list %s: %w", file.Name(), err)
+		}
+		err = d.readList(strings.TrimSuffix(file.Name(), ".txt"), f, false)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	d.sortSets()
+	return nil
+}
+
+// readList adds the words in r to the set for key
+func (d *Dictionary) readList(key string, r io.Reader, compressed bool) error {
+	if compressed {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("failed to decompress word list %s: %w", key, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	key = normalizeLocale(key)
+	set, ok := d.sets[key]
+	if !ok {
+		set = newWordSet()
+		d.sets[key] = set
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		set.add(word)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read word list %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// sortSets keeps suggestion candidates in a stable order
+func (d *Dictionary) sortSets() {
+	for _, set := range d.sets {
+		for _, words := range set.byLength {
+			sort.Strings(words)
+		}
+	}
+}
+
+// setsFor returns the word sets that apply to a locale: the language-wide list
+// followed by the regional list, when they exist
+func (d *Dictionary) setsFor(locale string) []*wordSet {
+	locale = normalizeLocale(locale)
+	lang, _, _ := strings.Cut(locale, "-")
+
+	var sets []*wordSet
+	if set, ok := d.sets[lang]; ok {
+		sets = append(sets, set)
+	}
+	region := locale
+	if alias, ok := regionAliases[region]; ok {
+		region = alias
+	}
+	if set, ok := d.sets[region]; ok && region != lang {
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// Supports reports whether the dictionary has a word list for a locale's language
+func (d *Dictionary) Supports(locale string) bool {
+	return len(d.setsFor(locale)) > 0
+}
+
+// Contains reports whether text is spelled correctly for the locale.
+// Phrases are accepted when every word in them is known.
+func (d *Dictionary) Contains(text, locale string) bool {
+	sets := d.setsFor(locale)
+	if len(sets) == 0 {
+		return false
+	}
+
+	words := splitWords(text)
+	if len(words) == 0 {
+		return false
+	}
+
+	for _, word := range words {
+		if !containsAny(sets, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// Suggest returns the closest known spelling for text, or "" if nothing is close.
+// For phrases, each unknown word is replaced by its suggestion.
+func (d *Dictionary) Suggest(text, locale string) string {
+	sets := d.setsFor(locale)
+	words := splitWords(text)
+	if len(sets) == 0 || len(words) == 0 {
+		return ""
+	}
+
+	suggested := make([]string, len(words))
+	changed := false
+	for i, word := range words {
+		if containsAny(sets, word) {
+			suggested[i] = word
+			continue
+		}
+		suggestion := closestWord(sets, word)
+		if suggestion == "" {
+			return ""
+		}
+		suggested[i] = suggestion
+		changed = true
+	}
+
+	if !changed {
+		return ""
+	}
+	return strings.Join(suggested, " ")
+}
+
+// closestWord finds the known word with the smallest edit distance to word
+func closestWord(sets []*wordSet, word string) string {
+	length := len([]rune(word))
+	maxDistance := 2
+	if length <= 4 {
+		maxDistance = 1
+	}
+
+	best := ""
+	bestDistance := maxDistance + 1
+	for l := length - maxDistance; l <= length+maxDistance; l++ {
+		for _, set := range sets {
+			for _, candidate := range set.byLength[l] {
+				distance := editDistance(word, candidate, bestDistance)
+				if distance < bestDistance || (distance == bestDistance && distance <= maxDistance && candidate < best) {
+					best = candidate
+					bestDistance = distance
+				}
+			}
+		}
+	}
+
+	if bestDistance > maxDistance {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b, counting an
+// adjacent transposition as one edit. It stops early once the distance is
+// known to exceed limit, returning limit+1.
+func editDistance(a, b string, limit int) int {
+	ar, br := []rune(a), []rune(b)
+	if abs(len(ar)-len(br)) > limit {
+		return limit + 1
+	}
+
+	prev2 := make([]int, len(br)+1)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(br)]
+}
+
+func containsAny(sets []*wordSet, word string) bool {
+	for _, set := range sets {
+		if set.contains(word) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitWords lowercases text and splits it into words on spaces and hyphens
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return r == ' ' || r == '-'
+	})
+}
+
+// normalizeLocale lowercases a locale and uses "-" as the separator (e.g. "en-gb")
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,120 @@
+package dictionary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadTestDictionary(t *testing.T) *Dictionary {
+	t.Helper()
+	d, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	return d
+}
+
+func TestContains(t *testing.T) {
+	d := loadTestDictionary(t)
+
+	tests := []struct {
+		text   string
+		locale string
+		want   bool
+	}{
+		{text: "garden", locale: "en-GB", want: true},
+		{text: "Garden", locale: "en-US", want: true},
+		{text: "colour", locale: "en-GB", want: true},
+		{text: "colour", locale: "en-US", want: false},
+		{text: "color", locale: "en-US", want: true},
+		{text: "colour", locale: "en-IE", want: true}, // falls back to en-gb spellings
+		{text: "ice cream", locale: "en-GB", want: true},
+		{text: "gorden", locale: "en-GB", want: false},
+		{text: "zzzz", locale: "en-GB", want: false},
+		{text: "", locale: "en-GB", want: false},
+		{text: "garden", locale: "fr-FR", want: false}, // no French list embedded
+	}
+
+	for _, tt := range tests {
+		if got := d.Contains(tt.text, tt.locale); got != tt.want {
+			t.Errorf("Contains(%q, %q) = %v, want %v", tt.text, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestSupports(t *testing.T) {
+	d := loadTestDictionary(t)
+
+	if !d.Supports("en-GB") || !d.Supports("en-AU") {
+		t.Error("expected English locales to be supported")
+	}
+	if d.Supports("fr-FR") {
+		t.Error("expected French to be unsupported without a word list")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	d := loadTestDictionary(t)
+
+	tests := []struct {
+		text   string
+		locale string
+		want   string
+	}{
+		{text: "becuase", locale: "en-GB", want: "because"},
+		{text: "freind", locale: "en-GB", want: "friend"},
+		{text: "necesary", locale: "en-GB", want: "necessary"},
+		{text: "ice creem", locale: "en-GB", want: "ice cream"},
+		{text: "garden", locale: "en-GB", want: ""}, // already correct
+		{text: "xqzvbn", locale: "en-GB", want: ""}, // nothing close
+	}
+
+	for _, tt := range tests {
+		if got := d.Suggest(tt.text, tt.locale); got != tt.want {
+			t.Errorf("Suggest(%q, %q) = %q, want %q", tt.text, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "cat", b: "cat", want: 0},
+		{a: "cat", b: "cut", want: 1},
+		{a: "freind", b: "friend", want: 1}, // transposition
+		{a: "garden", b: "gardens", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b, 5); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	if got := editDistance("kitten", "sitting", 1); got != 2 {
+		t.Errorf("editDistance() with limit 1 = %d, want 2", got)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	d := loadTestDictionary(t)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fr.txt"), []byte("# French words\njardin\nChat\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir() error: %v", err)
+	}
+
+	if !d.Supports("fr-FR") || !d.Contains("jardin", "fr-FR") || !d.Contains("chat", "fr-FR") {
+		t.Error("expected words loaded from directory to be found")
+	}
+	if err := d.LoadDir(filepath.Join(dir, "missing")); err != nil {
+		t.Errorf("LoadDir() on missing directory error: %v", err)
+	}
+}
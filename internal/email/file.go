@@ -0,0 +1,65 @@
+package email
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer drops messages into a maildir instead of sending them, for development
+// and testing. Each message is written to tmp/ and then moved into new/, so the
+// directory can be opened with any maildir-aware client (e.g. mutt -f <dir>) or
+// the .eml files read directly.
+type FileMailer struct {
+	dir string
+}
+
+// NewFileMailer creates a mailer that writes messages to the given maildir,
+// creating it if necessary
+func NewFileMailer(dir string) (*FileMailer, error) {
+	if dir == "" {
+		return nil, errors.New("email directory is required for the file backend")
+	}
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create maildir: %w", err)
+		}
+	}
+	return &FileMailer{dir: dir}, nil
+}
+
+func (m *FileMailer) Name() string {
+	return "file"
+}
+
+// Dir returns the maildir messages are written to
+func (m *FileMailer) Dir() string {
+	return m.dir
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	now := time.Now()
+	data, err := msg.Bytes(now)
+	if err != nil {
+		return err
+	}
+
+	b := make([]byte, 8)
+	rand.Read(b)
+	name := fmt.Sprintf("%d.%s.wordclash.eml", now.UnixNano(), hex.EncodeToString(b))
+
+	tmpPath := filepath.Join(m.dir, "tmp", name)
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(m.dir, "new", name)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to deliver message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileMailerWritesMaildir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer, err := NewMailer(Config{Backend: "file", FileDir: dir})
+	if err != nil {
+		t.Fatalf("NewMailer() error: %v", err)
+	}
+	if mailer.Name() != "file" {
+		t.Fatalf("NewMailer() = %s, want the file backend", mailer.Name())
+	}
+
+	msg := testMessage()
+	msg.Subject = "Bienvenue à WordClash"
+	if err := mailer.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+
+	if tmp, _ := os.ReadDir(filepath.Join(dir, "tmp")); len(tmp) != 0 {
+		t.Errorf("tmp/ still holds %d files after delivery", len(tmp))
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "new", "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("new/ holds %v, %v, want one message", files, err)
+	}
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatalf("failed to open message: %v", err)
+	}
+	defer f.Close()
+	parsed, err := mail.ReadMessage(f)
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("Subject = %q, %v, want %q", subject, err, msg.Subject)
+	}
+	if parsed.Header.Get("Message-ID") == "" {
+		t.Error("message has no Message-ID")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, %v, want multipart/alternative", mediaType, err)
+	}
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read part: %v", err)
+		}
+		body, _ := io.ReadAll(part) // multipart decodes quoted-printable
+		bodies = append(bodies, part.Header.Get("Content-Type")+": "+string(body))
+	}
+	want := []string{
+		"text/plain; charset=UTF-8: Hi Ada,\r\nClick the link below.",
+		"text/html; charset=UTF-8: <p>Hi Ada,</p>",
+	}
+	if strings.Join(bodies, "|") != strings.Join(want, "|") {
+		t.Errorf("parts = %q, want %q", bodies, want)
+	}
+}
+
+func TestMessageRejectsHeaderInjection(t *testing.T) {
+	msg := testMessage()
+	msg.To = []string{"parent@example.com\r\nBcc: everyone@example.com"}
+	if _, err := msg.Bytes(time.Now()); err == nil {
+		t.Error("Bytes() accepted a recipient containing a header, want an error")
+	}
+
+	msg = testMessage()
+	msg.Subject = "Hello\r\nBcc: everyone@example.com"
+	data, err := msg.Bytes(time.Now())
+	if err != nil {
+		t.Fatalf("Bytes() error: %v", err)
+	}
+	parsed, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(string(data))))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+	if bcc := parsed.Header.Get("Bcc"); bcc != "" {
+		t.Errorf("subject injected a Bcc header: %q", bcc)
+	}
+}
+
+func TestNoneMailer(t *testing.T) {
+	mailer, err := NewMailer(Config{Backend: ""})
+	if err != nil {
+		t.Fatalf("NewMailer() error: %v", err)
+	}
+	if err := mailer.Send(context.Background(), testMessage()); !errors.Is(err, ErrMailDisabled) {
+		t.Errorf("Send() error = %v, want ErrMailDisabled", err)
+	}
+	if _, err := NewMailer(Config{Backend: "pigeon"}); err == nil {
+		t.Error("NewMailer() with an unknown backend succeeded, want an error")
+	}
+}
@@ -0,0 +1,54 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMailDisabled is returned when a message is sent but no mail backend is configured
+var ErrMailDisabled = errors.New("email is disabled")
+
+// Mailer delivers email messages
+type Mailer interface {
+	// Name identifies the backend in logs and configuration
+	Name() string
+
+	// Send delivers a message
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Config holds the settings used to construct a mailer
+type Config struct {
+	Backend      string // "ses", "smtp", "file" or "none"
+	AWSRegion    string // Region used by the SES backend
+	SMTPHost     string
+	SMTPPort     int    // Defaults to the usual port for the TLS mode
+	SMTPUsername string // Leave empty for relays that don't require authentication
+	SMTPPassword string
+	SMTPTLS      string // "starttls", "tls" (implicit TLS) or "none"
+	FileDir      string // Maildir the file backend writes messages to
+}
+
+// NewMailer creates the mailer selected by the configuration
+func NewMailer(cfg Config) (Mailer, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Backend)) {
+	case "ses":
+		return NewSESMailer(cfg.AWSRegion)
+	case "smtp":
+		return NewSMTPMailer(SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			TLS:      cfg.SMTPTLS,
+		})
+	case "file", "maildir":
+		return NewFileMailer(cfg.FileDir)
+	case "none", "off", "disabled", "":
+		return NewNoneMailer(), nil
+	default:
+		return nil, fmt.Errorf("unsupported email backend: %s", cfg.Backend)
+	}
+}
@@ -0,0 +1,133 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Message is an email with a plain text body and an optional HTML alternative
+type Message struct {
+	FromEmail string
+	FromName  string
+	To        []string
+	Subject   string
+	HTMLBody  string
+	TextBody  string
+}
+
+// From returns the formatted sender address, e.g. "WordClash <noreply@example.com>"
+func (m *Message) From() string {
+	return (&mail.Address{Name: m.FromName, Address: m.FromEmail}).String()
+}
+
+// ErrInvalidMessage is returned for messages that can never be delivered, such as
+// ones with a malformed recipient address
+var ErrInvalidMessage = errors.New("invalid email message")
+
+// validate checks the sender and recipients are usable addresses
+func (m *Message) validate() error {
+	if _, err := mail.ParseAddress(m.FromEmail); err != nil {
+		return fmt.Errorf("%w: bad sender address %q: %v", ErrInvalidMessage, m.FromEmail, err)
+	}
+	if len(m.To) == 0 {
+		return fmt.Errorf("%w: no recipients", ErrInvalidMessage)
+	}
+	for _, to := range m.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("%w: bad recipient address %q: %v", ErrInvalidMessage, to, err)
+		}
+	}
+	return nil
+}
+
+// Bytes renders the message as RFC 5322 text with CRLF line endings, as sent over
+// SMTP or written to a maildir
+func (m *Message) Bytes(date time.Time) ([]byte, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+
+	header("From", m.From())
+	header("To", strings.Join(m.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", newMessageID(m.FromEmail))
+	header("MIME-Version", "1.0")
+
+	if m.HTMLBody == "" {
+		header("Content-Type", "text/plain; charset=UTF-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, m.TextBody); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary()))
+	buf.WriteString("\r\n")
+
+	// Clients show the last part they understand, so the HTML goes last
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", m.TextBody},
+		{"text/html; charset=UTF-8", m.HTMLBody},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create message part: %w", err)
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish message: %w", err)
+	}
+
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+// writeQuotedPrintable encodes a body, converting its line breaks to CRLF
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return fmt.Errorf("failed to encode message body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("failed to encode message body: %w", err)
+	}
+	return nil
+}
+
+// newMessageID generates a unique Message-ID in the sender's domain
+func newMessageID(fromEmail string) string {
+	domain := "localhost"
+	if _, d, ok := strings.Cut(fromEmail, "@"); ok && d != "" {
+		domain = d
+	}
+	b := make([]byte, 12)
+	rand.Read(b)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b), domain)
+}
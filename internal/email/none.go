@@ -0,0 +1,20 @@
+package email
+
+import "context"
+
+// NoneMailer is used when email is switched off. Every send fails with ErrMailDisabled
+// so callers can decide whether that matters.
+type NoneMailer struct{}
+
+// NewNoneMailer creates a mailer that never sends anything
+func NewNoneMailer() *NoneMailer {
+	return &NoneMailer{}
+}
+
+func (m *NoneMailer) Name() string {
+	return "none"
+}
+
+func (m *NoneMailer) Send(ctx context.Context, msg *Message) error {
+	return ErrMailDisabled
+}
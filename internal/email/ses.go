@@ -0,0 +1,82 @@
+package email
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// SESMailer sends email through Amazon SES. Credentials come from the usual AWS
+// sources: environment variables, ~/.aws/credentials or an IAM role.
+type SESMailer struct {
+	client *sesv2.Client
+	region string
+}
+
+// NewSESMailer creates a mailer that sends through SES in the given region
+func NewSESMailer(region string) (*SESMailer, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO(),
+		config.WithRegion(region),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &SESMailer{
+		client: sesv2.NewFromConfig(cfg),
+		region: region,
+	}, nil
+}
+
+func (m *SESMailer) Name() string {
+	return "ses"
+}
+
+// Region returns the AWS region messages are sent from
+func (m *SESMailer) Region() string {
+	return m.region
+}
+
+func (m *SESMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	body := &types.Body{
+		Text: &types.Content{
+			Data:    aws.String(msg.TextBody),
+			Charset: aws.String("UTF-8"),
+		},
+	}
+	if msg.HTMLBody != "" {
+		body.Html = &types.Content{
+			Data:    aws.String(msg.HTMLBody),
+			Charset: aws.String("UTF-8"),
+		}
+	}
+
+	input := &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(msg.From()),
+		Destination: &types.Destination{
+			ToAddresses: msg.To,
+		},
+		Content: &types.EmailContent{
+			Simple: &types.Message{
+				Subject: &types.Content{
+					Data:    aws.String(msg.Subject),
+					Charset: aws.String("UTF-8"),
+				},
+				Body: body,
+			},
+		},
+	}
+
+	if _, err := m.client.SendEmail(ctx, input); err != nil {
+		return fmt.Errorf("SES SendEmail failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TLS modes for the SMTP backend
+const (
+	SMTPTLSStartTLS = "starttls" // Upgrade a plain connection; the server must offer STARTTLS
+	SMTPTLSImplicit = "tls"      // Connect over TLS from the start (SMTPS, usually port 465)
+	SMTPTLSNone     = "none"     // No encryption, for relays on a trusted network
+)
+
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig holds the settings for an SMTP server or relay
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	TLS      string
+}
+
+// SMTPMailer sends email through an SMTP server, such as an on-premises relay
+type SMTPMailer struct {
+	host      string
+	port      int
+	username  string
+	password  string
+	tlsMode   string
+	tlsConfig *tls.Config
+}
+
+// NewSMTPMailer creates a mailer that delivers to the configured SMTP server
+func NewSMTPMailer(cfg SMTPConfig) (*SMTPMailer, error) {
+	host := strings.TrimSpace(cfg.Host)
+	if host == "" {
+		return nil, errors.New("SMTP host is required for the smtp backend")
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(cfg.TLS))
+	port := cfg.Port
+	switch mode {
+	case SMTPTLSStartTLS, "":
+		mode = SMTPTLSStartTLS
+		if port == 0 {
+			port = 587
+		}
+	case SMTPTLSImplicit, "ssl", "smtps":
+		mode = SMTPTLSImplicit
+		if port == 0 {
+			port = 465
+		}
+	case SMTPTLSNone, "off", "plain":
+		mode = SMTPTLSNone
+		if port == 0 {
+			port = 25
+		}
+	default:
+		return nil, fmt.Errorf("unsupported SMTP TLS mode: %s", cfg.TLS)
+	}
+
+	return &SMTPMailer{
+		host:      host,
+		port:      port,
+		username:  cfg.Username,
+		password:  cfg.Password,
+		tlsMode:   mode,
+		tlsConfig: &tls.Config{ServerName: host},
+	}, nil
+}
+
+func (m *SMTPMailer) Name() string {
+	return "smtp"
+}
+
+// Addr returns the host:port of the SMTP server
+func (m *SMTPMailer) Addr() string {
+	return net.JoinHostPort(m.host, strconv.Itoa(m.port))
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	data, err := msg.Bytes(time.Now())
+	if err != nil {
+		return err
+	}
+
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", m.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server %s: %w", m.Addr(), err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	if m.tlsMode == SMTPTLSImplicit {
+		conn = tls.Client(conn, m.tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if m.tlsMode == SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP server %s does not support STARTTLS", m.Addr())
+		}
+		if err := client.StartTLS(m.tlsConfig); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if m.username != "" {
+		// PlainAuth refuses to send the password over an unencrypted
+		// connection unless the server is on localhost
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(msg.FromEmail); err != nil {
+		return fmt.Errorf("SMTP server rejected sender: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP server rejected recipient %s: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start SMTP message: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write SMTP message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected message: %w", err)
+	}
+
+	return client.Quit()
+}
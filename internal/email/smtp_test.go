@@ -0,0 +1,185 @@
+package email
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer accepts a single SMTP session and records what it was sent
+type fakeSMTPServer struct {
+	listener   net.Listener
+	extensions []string
+	commands   []string
+	data       string
+	done       chan struct{}
+}
+
+func newFakeSMTPServer(t *testing.T, extensions ...string) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeSMTPServer{listener: listener, extensions: extensions, done: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ESMTP fake")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		s.commands = append(s.commands, line)
+
+		verb, _, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			lines := append([]string{"localhost"}, s.extensions...)
+			for i, ext := range lines {
+				sep := "-"
+				if i == len(lines)-1 {
+					sep = " "
+				}
+				tp.PrintfLine("250%s%s", sep, ext)
+			}
+		case "AUTH":
+			tp.PrintfLine("235 2.7.0 Authentication successful")
+		case "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.data = string(data)
+			tp.PrintfLine("250 2.0.0 Ok: queued")
+		case "QUIT":
+			tp.PrintfLine("221 2.0.0 Bye")
+			return
+		default:
+			tp.PrintfLine("250 2.0.0 Ok")
+		}
+	}
+}
+
+func testMessage() *Message {
+	return &Message{
+		FromEmail: "noreply@school.example",
+		FromName:  "WordClash",
+		To:        []string{"parent@example.com"},
+		Subject:   "Reset Your WordClash Password",
+		HTMLBody:  "<p>Hi Ada,</p>",
+		TextBody:  "Hi Ada,\nClick the link below.",
+	}
+}
+
+func TestSMTPMailerSendsThroughRelay(t *testing.T) {
+	server := newFakeSMTPServer(t, "AUTH PLAIN")
+	mailer, err := NewSMTPMailer(SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Username: "wordclash",
+		Password: "secret",
+		TLS:      SMTPTLSNone,
+	})
+	if err != nil {
+		t.Fatalf("NewSMTPMailer() error: %v", err)
+	}
+
+	if err := mailer.Send(context.Background(), testMessage()); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	<-server.done
+
+	commands := strings.Join(server.commands, "\n")
+	credentials := base64.StdEncoding.EncodeToString([]byte("\x00wordclash\x00secret"))
+	for _, want := range []string{
+		"AUTH PLAIN " + credentials,
+		"MAIL FROM:<noreply@school.example>",
+		"RCPT TO:<parent@example.com>",
+		"QUIT",
+	} {
+		if !strings.Contains(commands, want) {
+			t.Errorf("SMTP session missing %q:\n%s", want, commands)
+		}
+	}
+
+	for _, want := range []string{
+		"From: \"WordClash\" <noreply@school.example>",
+		"To: parent@example.com",
+		"Subject: Reset Your WordClash Password",
+		"Content-Type: multipart/alternative",
+		"Hi Ada,\nClick the link below.", // ReadDotBytes turns CRLF into LF
+	} {
+		if !strings.Contains(server.data, want) {
+			t.Errorf("message missing %q:\n%s", want, server.data)
+		}
+	}
+}
+
+func TestSMTPMailerRequiresStartTLS(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	mailer, err := NewSMTPMailer(SMTPConfig{Host: "127.0.0.1", Port: server.port()})
+	if err != nil {
+		t.Fatalf("NewSMTPMailer() error: %v", err)
+	}
+
+	err = mailer.Send(context.Background(), testMessage())
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("Send() error = %v, want STARTTLS to be required", err)
+	}
+	<-server.done
+	for _, command := range server.commands {
+		if strings.HasPrefix(command, "MAIL") {
+			t.Errorf("message was sent without TLS: %v", server.commands)
+		}
+	}
+}
+
+func TestNewSMTPMailerDefaults(t *testing.T) {
+	for _, tc := range []struct {
+		tls      string
+		wantPort int
+		wantMode string
+	}{
+		{"", 587, SMTPTLSStartTLS},
+		{"STARTTLS", 587, SMTPTLSStartTLS},
+		{"tls", 465, SMTPTLSImplicit},
+		{"none", 25, SMTPTLSNone},
+	} {
+		mailer, err := NewSMTPMailer(SMTPConfig{Host: "relay.school.local", TLS: tc.tls})
+		if err != nil {
+			t.Fatalf("NewSMTPMailer(%q) error: %v", tc.tls, err)
+		}
+		if mailer.tlsMode != tc.wantMode || mailer.Addr() != "relay.school.local:"+strconv.Itoa(tc.wantPort) {
+			t.Errorf("NewSMTPMailer(%q) = %s on %s, want %s on port %d", tc.tls, mailer.tlsMode, mailer.Addr(), tc.wantMode, tc.wantPort)
+		}
+	}
+
+	if _, err := NewSMTPMailer(SMTPConfig{}); err == nil {
+		t.Error("NewSMTPMailer() without a host succeeded, want an error")
+	}
+	if _, err := NewSMTPMailer(SMTPConfig{Host: "relay", TLS: "sometimes"}); err == nil {
+		t.Error("NewSMTPMailer() with an unknown TLS mode succeeded, want an error")
+	}
+}
@@ -0,0 +1,210 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Branding holds the values email templates use to look like the rest of the site
+type Branding struct {
+	AppName         string
+	PrimaryColor    string // Header and button colour, e.g. "#4a90e2"
+	BackgroundColor string // Body background colour, e.g. "#f9f9f9"
+	LogoURL         string // Absolute URL of a logo shown in the header; empty for none
+	BaseURL         string // Base URL for links back to the site
+}
+
+// Templates renders emails from HTML and plain text template pairs loaded from a
+// directory laid out as:
+//
+//	layout.html.tmpl     HTML layout shared by every email
+//	<name>.html.tmpl     HTML body, defines "heading" and "content" (and optionally "footer")
+//	<name>.txt.tmpl      plain text body, defines "subject"
+//	<locale>/...         translations of any of the above, e.g. fr/ or fr-CA/
+//
+// A translation only needs the files that differ. Anything missing falls back to
+// the language (e.g. "fr" for "fr-CA"), then the default locale, then the base files.
+// Templates execute against .Brand (the Branding) and .Data (the email's values).
+type Templates struct {
+	branding      Branding
+	defaultLocale string
+	layouts       map[string]*htmltemplate.Template // All maps are keyed by "<locale>/<name>"; "" is the base locale
+	html          map[string]*htmltemplate.Template
+	text          map[string]*texttemplate.Template
+}
+
+// templateData is what every email template executes against
+type templateData struct {
+	Brand Branding
+	Data  any
+}
+
+const (
+	htmlSuffix = ".html.tmpl"
+	textSuffix = ".txt.tmpl"
+	layoutName = "layout"
+)
+
+// LoadTemplates parses every email template in dir
+func LoadTemplates(dir string, branding Branding, defaultLocale string) (*Templates, error) {
+	t := &Templates{
+		branding:      branding,
+		defaultLocale: localeKey(defaultLocale),
+		layouts:       make(map[string]*htmltemplate.Template),
+		html:          make(map[string]*htmltemplate.Template),
+		text:          make(map[string]*texttemplate.Template),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read email templates: %w", err)
+	}
+	locales := []string{""}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			locales = append(locales, entry.Name())
+		}
+	}
+
+	// Layouts are parsed first so a translation can use another locale's layout
+	for _, locale := range locales {
+		layout := filepath.Join(dir, locale, layoutName+htmlSuffix)
+		if _, err := os.Stat(layout); err != nil {
+			continue
+		}
+		tmpl, err := htmltemplate.ParseFiles(layout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse email layout %s: %w", layout, err)
+		}
+		t.layouts[localeKey(locale)+"/"+layoutName] = tmpl
+	}
+
+	for _, locale := range locales {
+		if err := t.loadLocale(filepath.Join(dir, locale), localeKey(locale)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(t.text) == 0 {
+		return nil, fmt.Errorf("no email templates found in %s", dir)
+	}
+	return t, nil
+}
+
+// loadLocale parses the email bodies in one locale directory
+func (t *Templates) loadLocale(dir, locale string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return fmt.Errorf("failed to list email templates: %w", err)
+	}
+
+	for _, file := range files {
+		base := filepath.Base(file)
+		switch {
+		case strings.HasSuffix(base, textSuffix):
+			tmpl, err := texttemplate.ParseFiles(file)
+			if err != nil {
+				return fmt.Errorf("failed to parse email template %s: %w", file, err)
+			}
+			if tmpl.Lookup("subject") == nil {
+				return fmt.Errorf("email template %s does not define a subject", file)
+			}
+			t.text[locale+"/"+strings.TrimSuffix(base, textSuffix)] = tmpl
+
+		case strings.HasSuffix(base, htmlSuffix) && base != layoutName+htmlSuffix:
+			layout, ok := findTemplate(t.layouts, t.candidates(locale), layoutName)
+			if !ok {
+				return fmt.Errorf("no email layout found for %s", file)
+			}
+			tmpl, err := layout.Clone()
+			if err != nil {
+				return fmt.Errorf("failed to copy email layout: %w", err)
+			}
+			if _, err := tmpl.ParseFiles(file); err != nil {
+				return fmt.Errorf("failed to parse email template %s: %w", file, err)
+			}
+			t.html[locale+"/"+strings.TrimSuffix(base, htmlSuffix)] = tmpl
+		}
+	}
+	return nil
+}
+
+// candidates lists the locales to try for a requested locale, most specific first
+func (t *Templates) candidates(locale string) []string {
+	var candidates []string
+	add := func(key string) {
+		for _, c := range candidates {
+			if c == key {
+				return
+			}
+		}
+		candidates = append(candidates, key)
+	}
+
+	for _, l := range []string{localeKey(locale), t.defaultLocale} {
+		if l == "" {
+			continue
+		}
+		add(l)
+		if lang, _, ok := strings.Cut(l, "-"); ok {
+			add(lang)
+		}
+	}
+	add("")
+	return candidates
+}
+
+// findTemplate returns the named template for the first candidate locale that has one
+func findTemplate[T any](templates map[string]T, candidates []string, name string) (T, bool) {
+	for _, locale := range candidates {
+		if tmpl, ok := templates[locale+"/"+name]; ok {
+			return tmpl, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// Render executes the named email in the closest available locale, returning its
+// subject and bodies. The HTML body is empty if the email has no HTML template.
+func (t *Templates) Render(name, locale string, data any) (subject, htmlBody, textBody string, err error) {
+	td := templateData{Brand: t.branding, Data: data}
+	candidates := t.candidates(locale)
+
+	textTmpl, ok := findTemplate(t.text, candidates, name)
+	if !ok {
+		return "", "", "", fmt.Errorf("email template %q not found", name)
+	}
+
+	var buf bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&buf, "subject", td); err != nil {
+		return "", "", "", fmt.Errorf("failed to render %s subject: %w", name, err)
+	}
+	subject = strings.Join(strings.Fields(buf.String()), " ")
+
+	buf.Reset()
+	if err := textTmpl.Execute(&buf, td); err != nil {
+		return "", "", "", fmt.Errorf("failed to render %s text body: %w", name, err)
+	}
+	textBody = strings.TrimSpace(buf.String()) + "\n"
+
+	if htmlTmpl, ok := findTemplate(t.html, candidates, name); ok {
+		buf.Reset()
+		if err := htmlTmpl.Execute(&buf, td); err != nil {
+			return "", "", "", fmt.Errorf("failed to render %s HTML body: %w", name, err)
+		}
+		htmlBody = buf.String()
+	}
+
+	return subject, htmlBody, textBody, nil
+}
+
+// localeKey normalises a locale for lookups, e.g. "fr_CA" becomes "fr-ca"
+func localeKey(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
@@ -0,0 +1,121 @@
+package email
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testBranding = Branding{
+	AppName:         "Oak Primary Spelling",
+	PrimaryColor:    "#2e7d32",
+	BackgroundColor: "#ffffff",
+	LogoURL:         "https://oak.example/logo.png",
+	BaseURL:         "https://oak.example",
+}
+
+type resetData struct {
+	Name      string
+	ResetLink string
+}
+
+func TestTemplatesRenderShippedEmails(t *testing.T) {
+	templates, err := LoadTemplates(filepath.Join("..", "templates", "email"), testBranding, "en-GB")
+	if err != nil {
+		t.Fatalf("LoadTemplates() error: %v", err)
+	}
+
+	data := resetData{Name: "<Ada>", ResetLink: "https://oak.example/auth/reset-password?token=abc&x=1"}
+	subject, html, text, err := templates.Render("password_reset", "", data)
+	if err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	if subject != "Reset Your Oak Primary Spelling Password" {
+		t.Errorf("subject = %q", subject)
+	}
+	for _, want := range []string{"background-color: #2e7d32", `src="https://oak.example/logo.png"`, "Hi &lt;Ada&gt;,", "token=abc&amp;x=1", "Please do not reply"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML body missing %q", want)
+		}
+	}
+	if !strings.Contains(text, "Hi <Ada>,") || !strings.Contains(text, "token=abc&x=1") {
+		t.Errorf("text body was HTML-escaped:\n%s", text)
+	}
+	if strings.Contains(text, "Reset Your") {
+		t.Errorf("text body includes the subject:\n%s", text)
+	}
+
+	// Regional variants fall back to the language, and unknown languages to the default
+	for locale, want := range map[string]string{
+		"fr-CA": "Réinitialisez votre mot de passe Oak Primary Spelling",
+		"FR":    "Réinitialisez votre mot de passe Oak Primary Spelling",
+		"de-DE": "Reset Your Oak Primary Spelling Password",
+	} {
+		if subject, _, _, err := templates.Render("password_reset", locale, data); err != nil || subject != want {
+			t.Errorf("Render(%q) subject = %q, %v, want %q", locale, subject, err, want)
+		}
+	}
+
+	// Every shipped email renders in every shipped locale
+	type allData struct {
+		Name, ResetLink, LoginLink, InviterName, RegisterLink string
+		KidName, ChildrenLink, SessionsLink                   string
+		ExpiresInDays                                         int
+	}
+	for _, name := range []string{"password_reset", "welcome", "invitation", "kid_login_alert"} {
+		for _, locale := range []string{"", "fr"} {
+			if _, html, text, err := templates.Render(name, locale, allData{}); err != nil || html == "" || text == "" {
+				t.Errorf("Render(%q, %q) error = %v", name, locale, err)
+			}
+		}
+	}
+}
+
+func TestTemplatesPartialTranslation(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"layout.html.tmpl":       `<html>{{template "heading" .}}|{{template "content" .}}|{{block "footer" .}}base footer{{end}}</html>`,
+		"notice.html.tmpl":       `{{define "heading"}}Notice{{end}}{{define "content"}}Hello {{.Data}}{{end}}`,
+		"notice.txt.tmpl":        `{{define "subject"}}Notice from {{.Brand.AppName}}{{end}}Hello {{.Data}}`,
+		"text_only.txt.tmpl":     `{{define "subject"}}Plain{{end}}Just text`,
+		"cy/notice.txt.tmpl":     `{{define "subject"}}Hysbysiad{{end}}Helo {{.Data}}`,
+		"cy/layout.html.tmpl":    `<html lang="cy">{{template "content" .}}</html>`,
+		"en-us/notice.html.tmpl": `{{define "heading"}}Notice{{end}}{{define "content"}}Howdy {{.Data}}{{end}}{{define "footer"}}us footer{{end}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	templates, err := LoadTemplates(dir, testBranding, "")
+	if err != nil {
+		t.Fatalf("LoadTemplates() error: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name, locale          string
+		wantSubject, wantHTML string
+	}{
+		// Welsh has its own text and layout but no HTML body, so the base body is used in the base layout
+		{"notice", "cy-GB", "Hysbysiad", "<html>Notice|Hello Ada|base footer</html>"},
+		// US English overrides the HTML body only and keeps the base layout
+		{"notice", "en-US", "Notice from Oak Primary Spelling", "<html>Notice|Howdy Ada|us footer</html>"},
+		{"text_only", "cy", "Plain", ""},
+	} {
+		subject, html, _, err := templates.Render(tc.name, tc.locale, "Ada")
+		if err != nil {
+			t.Fatalf("Render(%q, %q) error: %v", tc.name, tc.locale, err)
+		}
+		if subject != tc.wantSubject || html != tc.wantHTML {
+			t.Errorf("Render(%q, %q) = %q, %q, want %q, %q", tc.name, tc.locale, subject, html, tc.wantSubject, tc.wantHTML)
+		}
+	}
+
+	if _, _, _, err := templates.Render("missing", "", nil); err == nil {
+		t.Error("Render() of a missing template succeeded, want an error")
+	}
+}
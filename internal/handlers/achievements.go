@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"log"
+	"spellingclash/internal/models"
+	"spellingclash/internal/service"
+)
+
+// takeNewAchievements returns the achievements to announce to a kid on a results
+// page. Failures are logged and announce nothing, as the results matter more.
+func takeNewAchievements(achievementService *service.AchievementService, kidID int64) []models.Achievement {
+	achievements, err := achievementService.TakeNewAchievements(kidID)
+	if err != nil {
+		log.Printf("Error getting new achievements for kid %d: %v", kidID, err)
+		return nil
+	}
+	return achievements
+}
@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"log"
@@ -18,6 +18,9 @@ import (
 	"spellingclash/internal/service"
 )
 
+// emailQueueDisplayLimit is how many recent emails the admin dashboard lists
+const emailQueueDisplayLimit = 25
+
 // AdminHandler handles admin-specific routes
 type AdminHandler struct {
 	templates      *template.Template
@@ -76,12 +79,21 @@ func (h *AdminHandler) ShowAdminDashboard(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	var emailQueue *service.EmailQueueStatus
+	if h.emailService != nil {
+		emailQueue, err = h.emailService.GetQueueStatus(emailQueueDisplayLimit)
+		if err != nil {
+			log.Printf("Error loading email queue status: %v", err)
+		}
+	}
+
 	csrfToken := h.getCSRFToken(r)
 
 	data := AdminDashboardViewData{
 		Title:       "Admin Dashboard",
 		User:        user,
 		PublicLists: publicLists,
+		EmailQueue:  emailQueue,
 		CSRFToken:   csrfToken,
 		Version:     h.version,
 	}
@@ -91,6 +103,39 @@ func (h *AdminHandler) ShowAdminDashboard(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// RetryEmail puts a failed email back in the outbox queue
+func (h *AdminHandler) RetryEmail(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	emailID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid email ID", http.StatusBadRequest)
+		return
+	}
+
+	if h.emailService == nil {
+		http.Error(w, service.ErrEmailDisabled.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err := h.emailService.RetryEmail(emailID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrEmailDisabled):
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		case errors.Is(err, service.ErrEmailNotFailed):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			respondWithError(w, http.StatusInternalServerError, "Failed to retry email", "Error retrying email", err)
+		}
+		return
+	}
+
+	http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+}
+
 // RegeneratePublicLists regenerates all public lists from the data files
 func (h *AdminHandler) RegeneratePublicLists(w http.ResponseWriter, r *http.Request) {
 	user := GetUserFromContext(r.Context())
@@ -155,10 +200,16 @@ func (h *AdminHandler) ShowManageUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	twoFactorUsers, err := h.authService.TwoFactorUserIDs()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to load users", "Error fetching two-factor users", err)
+		return
+	}
+
 	// Create a slice with user and family code combined
 	usersWithFamily := make([]AdminUserWithFamily, 0, len(users))
 	for _, u := range users {
-		uwf := AdminUserWithFamily{User: u}
+		uwf := AdminUserWithFamily{User: u, TwoFactor: twoFactorUsers[u.ID]}
 		families, err := h.familyRepo.GetUserFamilies(u.ID)
 		if err != nil {
 			log.Printf("Error fetching families for user %d: %v", u.ID, err)
@@ -171,10 +222,11 @@ func (h *AdminHandler) ShowManageUsers(w http.ResponseWriter, r *http.Request) {
 	csrfToken := h.getCSRFToken(r)
 
 	data := AdminUsersViewData{
-		Title:     "Manage Users",
-		User:      user,
-		Users:     usersWithFamily,
-		CSRFToken: csrfToken,
+		Title:           "Manage Users",
+		User:            user,
+		Users:           usersWithFamily,
+		TwoFactorPolicy: h.authService.TwoFactorPolicy(),
+		CSRFToken:       csrfToken,
 	}
 
 	if err := h.templates.ExecuteTemplate(w, "admin_users.tmpl", data); err != nil {
@@ -295,6 +347,58 @@ func (h *AdminHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
 }
 
+// UpdateTwoFactorPolicy changes who must use two-factor authentication
+func (h *AdminHandler) UpdateTwoFactorPolicy(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return
+	}
+
+	policy := models.TwoFactorPolicy(r.FormValue("policy"))
+	if !policy.IsValid() {
+		http.Error(w, "Invalid two-factor policy", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authService.SetTwoFactorPolicy(policy); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update setting", "Error updating two-factor policy", err)
+		return
+	}
+
+	log.Printf("Admin %d set the two-factor policy to %s", user.ID, policy)
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
+// ResetTwoFactor turns off a user's two-factor authentication so they can log in
+// after losing their authenticator and recovery codes
+func (h *AdminHandler) ResetTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authService.ResetTwoFactor(userID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to reset two-factor authentication", "Error resetting two-factor authentication", err)
+		return
+	}
+
+	log.Printf("Admin %d reset two-factor authentication for user %d", user.ID, userID)
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
 // ShowManageFamilies shows the family management page
 func (h *AdminHandler) ShowManageFamilies(w http.ResponseWriter, r *http.Request) {
 	user := GetUserFromContext(r.Context())
@@ -523,33 +627,85 @@ func (h *AdminHandler) clearDatabase() error {
 
 	// Delete in reverse order of dependencies
 	tables := []string{
+		"spelling_test_answers",
+		"spelling_test_attempts",
+		"spelling_tests",
+		"word_attempts",
+		"practice_word_timing",
+		"practice_state",
+		"hangman_state",
+		"hangman_games",
+		"hangman_sessions",
+		"missing_letter_state",
+		"missing_letter_games",
+		"missing_letter_sessions",
+		"word_schedules",
 		"practice_results", // May not exist in current schema, but try to clear it anyway
 		"practice_sessions",
 		"list_assignments",
+		"teacher_class_lists",
+		"teacher_class_members",
+		"teacher_classes",
 		"words",
 		"spelling_lists",
+		"teacher_kid_relationships",
 		"kid_sessions",
 		"kids",
 		"family_members",
 		"families",
+		"email_delivery_attempts",
+		"email_outbox",
+		"invitations",
+		"api_tokens",
 		"password_reset_tokens",
 		"sessions",
+		"digest_subscriptions",
+		"login_challenges",
+		"two_factor_recovery_codes",
+		"user_two_factor",
+		"user_identities",
 		"users",
 	}
 
 	allowedTables := map[string]struct{}{
-		"practice_results":      {},
-		"practice_sessions":     {},
-		"list_assignments":      {},
-		"words":                 {},
-		"spelling_lists":        {},
-		"kid_sessions":          {},
-		"kids":                  {},
-		"family_members":        {},
-		"families":              {},
-		"password_reset_tokens": {},
-		"sessions":              {},
-		"users":                 {},
+		"spelling_test_answers":     {},
+		"spelling_test_attempts":    {},
+		"spelling_tests":            {},
+		"word_attempts":             {},
+		"practice_word_timing":      {},
+		"practice_state":            {},
+		"hangman_state":             {},
+		"hangman_games":             {},
+		"hangman_sessions":          {},
+		"missing_letter_state":      {},
+		"missing_letter_games":      {},
+		"missing_letter_sessions":   {},
+		"word_schedules":            {},
+		"practice_results":          {},
+		"practice_sessions":         {},
+		"list_assignments":          {},
+		"teacher_class_lists":       {},
+		"teacher_class_members":     {},
+		"teacher_classes":           {},
+		"words":                     {},
+		"spelling_lists":            {},
+		"teacher_kid_relationships": {},
+		"kid_sessions":              {},
+		"kids":                      {},
+		"family_members":            {},
+		"families":                  {},
+		"email_delivery_attempts":   {},
+		"email_outbox":              {},
+		"invitations":               {},
+		"api_tokens":                {},
+		"password_reset_tokens":     {},
+		"sessions":                  {},
+		"digest_subscriptions":      {},
+		"login_challenges":          {},
+		"two_factor_recovery_codes": {},
+		"user_two_factor":           {},
+		"user_identities":           {},
+		"users":                     {},
 	}
 
 	for _, table := range tables {
@@ -984,26 +1140,7 @@ func (h *AdminHandler) SendInvitation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Send invitation email
-	var emailError *string
-	emailSent := true
-	if h.emailService != nil && h.emailService.IsEnabled() {
-		if err := h.sendInvitationEmail(r.Context(), email, invitation.Code, user.Name); err != nil {
-			log.Printf("Failed to send invitation email: %v", err)
-			emailSent = false
-			errMsg := err.Error()
-			emailError = &errMsg
-		}
-	} else {
-		emailSent = false
-		noServiceMsg := "Email service not configured"
-		emailError = &noServiceMsg
-	}
-
-	// Update email status
-	if err := h.invitationRepo.UpdateEmailStatus(invitation.ID, emailSent, emailError); err != nil {
-		log.Printf("Failed to update email status: %v", err)
-	}
+	h.queueInvitationEmail(r, invitation.ID, invitation.Email, user.Name, invitation.Code, invitation.ExpiresAt)
 
 	http.Redirect(w, r, "/admin/invitations", http.StatusSeeOther)
 }
@@ -1053,93 +1190,35 @@ func (h *AdminHandler) ResendInvitation(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Send invitation email
+	h.queueInvitationEmail(r, invitation.ID, invitation.Email, user.Name, invitation.Code, invitation.ExpiresAt)
+
+	http.Redirect(w, r, "/admin/invitations", http.StatusSeeOther)
+}
+
+// queueInvitationEmail queues an invitation email and records its status. The
+// status shows as pending until the outbox worker has tried to deliver it.
+func (h *AdminHandler) queueInvitationEmail(r *http.Request, invitationID int64, toEmail, inviterName, code string, expiresAt time.Time) {
 	var emailError *string
-	emailSent := true
-	if h.emailService != nil && h.emailService.IsEnabled() {
-		if err := h.sendInvitationEmail(r.Context(), invitation.Email, invitation.Code, user.Name); err != nil {
-			log.Printf("Failed to resend invitation email: %v", err)
-			emailSent = false
-			errMsg := err.Error()
-			emailError = &errMsg
-		}
-	} else {
-		emailSent = false
+	if h.emailService == nil || !h.emailService.IsEnabled() {
 		noServiceMsg := "Email service not configured"
 		emailError = &noServiceMsg
 	}
 
-	// Update email status
-	if err := h.invitationRepo.UpdateEmailStatus(invitation.ID, emailSent, emailError); err != nil {
+	// Mark it pending before queueing so this can't overwrite the worker's result
+	if err := h.invitationRepo.UpdateEmailStatus(invitationID, false, emailError); err != nil {
 		log.Printf("Failed to update email status: %v", err)
 	}
+	if emailError != nil {
+		return
+	}
 
-	http.Redirect(w, r, "/admin/invitations", http.StatusSeeOther)
-}
-
-// sendInvitationEmail sends an invitation email with a registration link
-func (h *AdminHandler) sendInvitationEmail(ctx context.Context, toEmail, invitationCode, inviterName string) error {
-	// Generate registration link
-	registerURL := fmt.Sprintf("%s/register?invite=%s", strings.TrimSuffix(h.appBaseURL, "/"), invitationCode)
-
-	subject := "You're invited to join SpellingClash!"
-
-	htmlBody := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background-color: #4A90E2; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
-        .content { background-color: #f9f9f9; padding: 30px; border-radius: 0 0 5px 5px; }
-        .button { display: inline-block; background-color: #4A90E2; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; margin: 20px 0; }
-        .code { background-color: #e8e8e8; padding: 10px; font-family: monospace; font-size: 16px; text-align: center; border-radius: 3px; }
-        .footer { text-align: center; margin-top: 20px; color: #666; font-size: 12px; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>🎯 SpellingClash Invitation</h1>
-        </div>
-        <div class="content">
-            <p>Hi there!</p>
-            <p><strong>%s</strong> has invited you to join SpellingClash, a fun spelling practice app for kids!</p>
-            <p>Click the button below to create your account:</p>
-            <p style="text-align: center;">
-                <a href="%s" class="button">Accept Invitation</a>
-            </p>
-            <p>Or copy and paste this link into your browser:</p>
-            <div class="code">%s</div>
-            <p style="margin-top: 30px; color: #666; font-size: 14px;">This invitation will expire in 7 days.</p>
-        </div>
-        <div class="footer">
-            <p>This email was sent because someone invited you to SpellingClash.</p>
-            <p>If you weren't expecting this invitation, you can safely ignore this email.</p>
-        </div>
-    </div>
-</body>
-</html>
-`, inviterName, registerURL, registerURL)
-
-	textBody := fmt.Sprintf(`You're invited to join SpellingClash!
-
-%s has invited you to join SpellingClash, a fun spelling practice app for kids!
-
-To create your account, visit:
-%s
-
-This invitation will expire in 7 days.
-
----
-This email was sent because someone invited you to SpellingClash.
-If you weren't expecting this invitation, you can safely ignore this email.
-`, inviterName, registerURL)
-
-	// Use the sendEmail private method from EmailService via the public wrapper
-	return h.emailService.SendInvitationEmail(ctx, toEmail, subject, htmlBody, textBody)
+	if err := h.emailService.SendInvitationEmail(r.Context(), invitationID, toEmail, inviterName, code, expiresAt, ""); err != nil {
+		log.Printf("Failed to queue invitation email: %v", err)
+		errMsg := err.Error()
+		if err := h.invitationRepo.UpdateEmailStatus(invitationID, false, &errMsg); err != nil {
+			log.Printf("Failed to update email status: %v", err)
+		}
+	}
 }
 
 // renderInvitationsPageWithError renders the invitations page with an error message
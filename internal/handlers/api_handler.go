@@ -0,0 +1,756 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"spellingclash/internal/service"
+	"strconv"
+	"time"
+)
+
+const (
+	// apiMaxBodySize limits JSON request bodies
+	apiMaxBodySize = 1 << 20
+	// apiDefaultSessionLimit and apiMaxSessionLimit bound the practice sessions returned per request
+	apiDefaultSessionLimit = 20
+	apiMaxSessionLimit     = 100
+)
+
+// APIHandler serves the versioned JSON API under /api/v1. Requests are
+// authenticated with API tokens by Middleware.RequireAPIToken and use the
+// same services, and so the same access rules, as the web pages.
+type APIHandler struct {
+	listService     *service.ListService
+	familyService   *service.FamilyService
+	teacherService  *service.TeacherService
+	practiceService *service.PracticeService
+}
+
+// NewAPIHandler creates a new API handler
+func NewAPIHandler(listService *service.ListService, familyService *service.FamilyService, teacherService *service.TeacherService, practiceService *service.PracticeService) *APIHandler {
+	return &APIHandler{
+		listService:     listService,
+		familyService:   familyService,
+		teacherService:  teacherService,
+		practiceService: practiceService,
+	}
+}
+
+type apiUser struct {
+	ID        int64  `json:"id"`
+	Email     string `json:"email"`
+	Name      string `json:"name"`
+	IsTeacher bool   `json:"is_teacher"`
+	IsAdmin   bool   `json:"is_admin"`
+}
+
+type apiList struct {
+	ID               int64     `json:"id"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	Locale           string    `json:"locale"`
+	FamilyCode       *string   `json:"family_code"`
+	IsPublic         bool      `json:"is_public"`
+	CreatedBy        *int64    `json:"created_by"`
+	WordCount        *int      `json:"word_count,omitempty"`
+	AssignedKidCount *int      `json:"assigned_kid_count,omitempty"`
+	Words            []apiWord `json:"words,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type apiAssignedList struct {
+	apiList
+	ManagedByTeacher bool       `json:"managed_by_teacher"`
+	DueDate          *time.Time `json:"due_date"`
+}
+
+type apiWord struct {
+	ID         int64     `json:"id"`
+	ListID     int64     `json:"list_id"`
+	Word       string    `json:"word"`
+	Difficulty int       `json:"difficulty"`
+	Definition string    `json:"definition"`
+	Position   int       `json:"position"`
+	AudioURL   string    `json:"audio_url,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type apiKid struct {
+	ID            int64             `json:"id"`
+	Name          string            `json:"name"`
+	Username      string            `json:"username"`
+	AvatarColor   string            `json:"avatar_color"`
+	FamilyCode    string            `json:"family_code"`
+	AssignedLists []apiAssignedList `json:"assigned_lists,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+type apiSession struct {
+	ID           int64        `json:"id"`
+	KidID        int64        `json:"kid_id"`
+	ListID       int64        `json:"list_id"`
+	StartedAt    time.Time    `json:"started_at"`
+	CompletedAt  *time.Time   `json:"completed_at"`
+	TotalWords   int          `json:"total_words"`
+	CorrectWords int          `json:"correct_words"`
+	PointsEarned int          `json:"points_earned"`
+	Attempts     []apiAttempt `json:"attempts,omitempty"`
+}
+
+type apiAttempt struct {
+	WordID       int64     `json:"word_id"`
+	Answer       string    `json:"answer"`
+	IsCorrect    bool      `json:"is_correct"`
+	TimeTakenMs  int       `json:"time_taken_ms"`
+	PointsEarned int       `json:"points_earned"`
+	AttemptedAt  time.Time `json:"attempted_at"`
+}
+
+type apiStats struct {
+	TotalSessions        int                 `json:"total_sessions"`
+	TotalWordsPracticed  int                 `json:"total_words_practiced"`
+	TotalCorrect         int                 `json:"total_correct"`
+	TotalPoints          int                 `json:"total_points"`
+	UniqueWordsAttempted int                 `json:"unique_words_attempted"`
+	OverallAccuracy      float64             `json:"overall_accuracy"`
+	StrugglingWords      []apiStrugglingWord `json:"struggling_words"`
+}
+
+type apiStrugglingWord struct {
+	WordID          int64     `json:"word_id"`
+	Word            string    `json:"word"`
+	TotalAttempts   int       `json:"total_attempts"`
+	CorrectAttempts int       `json:"correct_attempts"`
+	SuccessRate     float64   `json:"success_rate"`
+	LastAttempted   time.Time `json:"last_attempted"`
+}
+
+type apiListRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Locale      string `json:"locale"`
+	FamilyCode  string `json:"family_code"`
+}
+
+type apiWordRequest struct {
+	Word       string `json:"word"`
+	Difficulty int    `json:"difficulty"`
+	Definition string `json:"definition"`
+}
+
+type apiAssignmentRequest struct {
+	DueDate string `json:"due_date"` // YYYY-MM-DD, optional
+}
+
+// GetMe returns the user the API token belongs to
+func (h *APIHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	respondWithJSON(w, http.StatusOK, apiUser{
+		ID:        user.ID,
+		Email:     user.Email,
+		Name:      user.Name,
+		IsTeacher: user.IsTeacher,
+		IsAdmin:   user.IsAdmin,
+	})
+}
+
+// ListLists returns every list the user can see, including public lists
+func (h *APIHandler) ListLists(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+
+	lists, err := h.listService.GetAllUserListsWithAssignments(user.ID)
+	if err != nil {
+		respondWithJSONError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting user lists", err)
+		return
+	}
+
+	result := make([]apiList, 0, len(lists))
+	for _, summary := range lists {
+		list := toAPIList(summary.SpellingList)
+		wordCount, kidCount := summary.WordCount, summary.AssignedKidCount
+		list.WordCount = &wordCount
+		list.AssignedKidCount = &kidCount
+		result = append(result, list)
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// CreateList creates a list. Parents may give a family_code, defaulting to their first family.
+func (h *APIHandler) CreateList(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+
+	var req apiListRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	familyCode := ""
+	if !user.IsTeacher {
+		familyCode = req.FamilyCode
+		if familyCode == "" {
+			families, err := h.familyService.GetUserFamilies(user.ID)
+			if err != nil {
+				respondWithJSONError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting user families", err)
+				return
+			}
+			if len(families) == 0 {
+				respondWithJSONError(w, http.StatusBadRequest, "No family found", "", nil)
+				return
+			}
+			familyCode = families[0].FamilyCode
+		}
+	}
+
+	list, err := h.listService.CreateList(familyCode, user.ID, req.Name, req.Description, req.Locale)
+	if err != nil {
+		respondWithServiceError(w, err, "Error creating list", http.StatusBadRequest)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toAPIList(*list))
+}
+
+// GetList returns a list with its words
+func (h *APIHandler) GetList(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	listID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	list, err := h.listService.GetList(listID)
+	if err != nil {
+		respondWithServiceError(w, err, "Error getting list", http.StatusInternalServerError)
+		return
+	}
+
+	words, err := h.listService.GetListWords(listID, user.ID)
+	if err != nil {
+		respondWithServiceError(w, err, "Error getting list words", http.StatusInternalServerError)
+		return
+	}
+
+	result := toAPIList(*list)
+	result.Words = toAPIWords(words)
+	wordCount := len(words)
+	result.WordCount = &wordCount
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// UpdateList updates a list's name, description and locale
+func (h *APIHandler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	listID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req apiListRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if err := h.listService.UpdateList(listID, user.ID, req.Name, req.Description, req.Locale); err != nil {
+		respondWithServiceError(w, err, "Error updating list", http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.listService.GetList(listID)
+	if err != nil {
+		respondWithServiceError(w, err, "Error getting list", http.StatusInternalServerError)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toAPIList(*list))
+}
+
+// DeleteList deletes a list
+func (h *APIHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	listID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.listService.DeleteList(listID, user.ID); err != nil {
+		respondWithServiceError(w, err, "Error deleting list", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWords returns the words in a list
+func (h *APIHandler) ListWords(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	listID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	words, err := h.listService.GetListWords(listID, user.ID)
+	if err != nil {
+		respondWithServiceError(w, err, "Error getting list words", http.StatusInternalServerError)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toAPIWords(words))
+}
+
+// AddWord adds a word to a list
+func (h *APIHandler) AddWord(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	listID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req apiWordRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	word, err := h.listService.AddWord(listID, user.ID, req.Word, req.Difficulty, req.Definition)
+	if err != nil {
+		respondWithServiceError(w, err, "Error adding word", http.StatusBadRequest)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, toAPIWord(*word))
+}
+
+// UpdateWord updates a word in a list
+func (h *APIHandler) UpdateWord(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	listID, wordID, ok := h.listWordIDs(w, r, user)
+	if !ok {
+		return
+	}
+
+	var req apiWordRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if err := h.listService.UpdateWord(wordID, user.ID, req.Word, req.Difficulty, req.Definition); err != nil {
+		respondWithServiceError(w, err, "Error updating word", http.StatusBadRequest)
+		return
+	}
+
+	words, err := h.listService.GetListWords(listID, user.ID)
+	if err != nil {
+		respondWithServiceError(w, err, "Error getting list words", http.StatusInternalServerError)
+		return
+	}
+	for _, word := range words {
+		if word.ID == wordID {
+			respondWithJSON(w, http.StatusOK, toAPIWord(word))
+			return
+		}
+	}
+	respondWithJSONError(w, http.StatusNotFound, service.ErrWordNotFound.Error(), "", nil)
+}
+
+// DeleteWord removes a word from a list
+func (h *APIHandler) DeleteWord(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	_, wordID, ok := h.listWordIDs(w, r, user)
+	if !ok {
+		return
+	}
+
+	if err := h.listService.DeleteWord(wordID, user.ID); err != nil {
+		respondWithServiceError(w, err, "Error deleting word", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAssignments returns the children a list is assigned to
+func (h *APIHandler) ListAssignments(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	listID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	kids, err := h.listService.GetListAssignedKids(listID, user.ID)
+	if err != nil {
+		respondWithServiceError(w, err, "Error getting assigned kids", http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]apiKid, 0, len(kids))
+	for _, kid := range kids {
+		result = append(result, toAPIKid(kid))
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// AssignList assigns a list to a child, with an optional due date
+func (h *APIHandler) AssignList(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	listID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	kid, ok := h.accessibleKid(w, r, user, "kidId")
+	if !ok {
+		return
+	}
+
+	var req apiAssignmentRequest
+	if r.ContentLength != 0 && !decodeJSONBody(w, r, &req) {
+		return
+	}
+	dueDate, err := parseOptionalDueDate(req.DueDate)
+	if err != nil {
+		respondWithJSONError(w, http.StatusBadRequest, "Invalid due date, expected YYYY-MM-DD", "", nil)
+		return
+	}
+
+	if err := h.listService.AssignListToKidWithDueDate(listID, kid.ID, user.ID, dueDate); err != nil {
+		respondWithServiceError(w, err, "Error assigning list", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnassignList removes a list assignment from a child
+func (h *APIHandler) UnassignList(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	listID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	kid, ok := h.accessibleKid(w, r, user, "kidId")
+	if !ok {
+		return
+	}
+
+	if err := h.listService.UnassignListFromKid(listID, kid.ID, user.ID); err != nil {
+		respondWithServiceError(w, err, "Error unassigning list", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListKids returns the user's children, or a teacher's class
+func (h *APIHandler) ListKids(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+
+	var kids []models.Kid
+	var err error
+	if user.IsTeacher {
+		kids, err = h.teacherService.GetTeacherKids(user.ID)
+	} else {
+		kids, err = h.familyService.GetAllUserKids(user.ID)
+	}
+	if err != nil {
+		respondWithJSONError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting kids", err)
+		return
+	}
+
+	result := make([]apiKid, 0, len(kids))
+	for _, kid := range kids {
+		result = append(result, toAPIKid(kid))
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// GetKid returns a child with their assigned lists
+func (h *APIHandler) GetKid(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	kid, ok := h.accessibleKid(w, r, user, "id")
+	if !ok {
+		return
+	}
+
+	lists, err := h.listService.GetKidAssignedLists(kid.ID)
+	if err != nil {
+		respondWithJSONError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting assigned lists", err)
+		return
+	}
+
+	result := toAPIKid(*kid)
+	result.AssignedLists = make([]apiAssignedList, 0, len(lists))
+	for _, list := range lists {
+		result.AssignedLists = append(result.AssignedLists, apiAssignedList{
+			apiList:          toAPIList(list),
+			ManagedByTeacher: list.AssignmentManagedByTeacher,
+			DueDate:          list.AssignmentDueDate,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// ListKidSessions returns a child's most recent practice sessions. The number
+// returned is set with ?limit= (default 20, at most 100).
+func (h *APIHandler) ListKidSessions(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	kid, ok := h.accessibleKid(w, r, user, "id")
+	if !ok {
+		return
+	}
+
+	limit := apiDefaultSessionLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			respondWithJSONError(w, http.StatusBadRequest, "Invalid limit", "", nil)
+			return
+		}
+		limit = min(n, apiMaxSessionLimit)
+	}
+
+	sessions, err := h.practiceService.GetKidRecentSessions(kid.ID, limit)
+	if err != nil {
+		respondWithJSONError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting practice sessions", err)
+		return
+	}
+
+	result := make([]apiSession, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, toAPISession(session))
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// GetKidSession returns a practice session with each word attempt
+func (h *APIHandler) GetKidSession(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	kid, ok := h.accessibleKid(w, r, user, "id")
+	if !ok {
+		return
+	}
+	sessionID, ok := pathID(w, r, "sessionId")
+	if !ok {
+		return
+	}
+
+	session, attempts, err := h.practiceService.GetSessionResults(sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithJSONError(w, http.StatusNotFound, "Practice session not found", "", nil)
+		return
+	}
+	if err != nil {
+		respondWithJSONError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting practice session", err)
+		return
+	}
+	if session == nil || session.KidID != kid.ID {
+		respondWithJSONError(w, http.StatusNotFound, "Practice session not found", "", nil)
+		return
+	}
+
+	result := toAPISession(*session)
+	result.Attempts = make([]apiAttempt, 0, len(attempts))
+	for _, attempt := range attempts {
+		result.Attempts = append(result.Attempts, apiAttempt{
+			WordID:       attempt.WordID,
+			Answer:       attempt.AttemptText,
+			IsCorrect:    attempt.IsCorrect,
+			TimeTakenMs:  attempt.TimeTakenMs,
+			PointsEarned: attempt.PointsEarned,
+			AttemptedAt:  attempt.AttemptedAt,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// GetKidStats returns a child's overall practice statistics and struggling words
+func (h *APIHandler) GetKidStats(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	kid, ok := h.accessibleKid(w, r, user, "id")
+	if !ok {
+		return
+	}
+
+	stats, err := h.practiceService.GetKidStats(kid.ID)
+	if err != nil {
+		respondWithJSONError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting kid stats", err)
+		return
+	}
+	struggling, err := h.practiceService.GetStrugglingWords(kid.ID)
+	if err != nil {
+		respondWithJSONError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting struggling words", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toAPIStats(stats, struggling))
+}
+
+// accessibleKid loads the child named by a path parameter, checking that the
+// user is in the child's family or, for teachers, linked to the child
+func (h *APIHandler) accessibleKid(w http.ResponseWriter, r *http.Request, user *models.User, param string) (*models.Kid, bool) {
+	kidID, ok := pathID(w, r, param)
+	if !ok {
+		return nil, false
+	}
+
+	kid, err := h.familyService.GetKid(kidID)
+	if err != nil {
+		respondWithServiceError(w, err, "Error getting kid", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if user.IsTeacher {
+		err = h.teacherService.VerifyTeacherKidAccess(user.ID, kid.ID)
+	} else {
+		err = h.familyService.VerifyFamilyAccess(user.ID, kid.FamilyCode)
+	}
+	if err != nil {
+		// Don't reveal whether children outside the user's family exist
+		respondWithJSONError(w, http.StatusNotFound, service.ErrKidNotFound.Error(), "", nil)
+		return nil, false
+	}
+	return kid, true
+}
+
+// listWordIDs parses the list and word IDs from the path and checks that the
+// word belongs to the list
+func (h *APIHandler) listWordIDs(w http.ResponseWriter, r *http.Request, user *models.User) (int64, int64, bool) {
+	listID, ok := pathID(w, r, "id")
+	if !ok {
+		return 0, 0, false
+	}
+	wordID, ok := pathID(w, r, "wordId")
+	if !ok {
+		return 0, 0, false
+	}
+
+	words, err := h.listService.GetListWords(listID, user.ID)
+	if err != nil {
+		respondWithServiceError(w, err, "Error getting list words", http.StatusInternalServerError)
+		return 0, 0, false
+	}
+	for _, word := range words {
+		if word.ID == wordID {
+			return listID, wordID, true
+		}
+	}
+	respondWithJSONError(w, http.StatusNotFound, service.ErrWordNotFound.Error(), "", nil)
+	return 0, 0, false
+}
+
+// pathID parses a numeric path parameter, writing a 400 response if it is invalid
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil || id <= 0 {
+		respondWithJSONError(w, http.StatusBadRequest, "Invalid "+name, "", nil)
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeJSONBody decodes a JSON request body into v, writing a 400 response if it is invalid
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		respondWithJSONError(w, http.StatusBadRequest, "Invalid JSON body: "+err.Error(), "", nil)
+		return false
+	}
+	return true
+}
+
+// respondWithServiceError maps service errors to API responses. Errors the API
+// doesn't recognise are reported with fallbackStatus; for 4xx statuses the
+// service's message is returned, as the HTML handlers do.
+func respondWithServiceError(w http.ResponseWriter, err error, logMsg string, fallbackStatus int) {
+	switch {
+	case errors.Is(err, service.ErrListNotFound), errors.Is(err, service.ErrWordNotFound), errors.Is(err, service.ErrKidNotFound):
+		respondWithJSONError(w, http.StatusNotFound, err.Error(), "", nil)
+	case errors.Is(err, service.ErrNotFamilyMember):
+		respondWithJSONError(w, http.StatusForbidden, err.Error(), "", nil)
+	case fallbackStatus >= http.StatusInternalServerError:
+		respondWithJSONError(w, fallbackStatus, ErrInternalServerError, logMsg, err)
+	default:
+		respondWithJSONError(w, fallbackStatus, err.Error(), logMsg, err)
+	}
+}
+
+func toAPIList(list models.SpellingList) apiList {
+	return apiList{
+		ID:          list.ID,
+		Name:        list.Name,
+		Description: list.Description,
+		Locale:      list.Locale,
+		FamilyCode:  list.FamilyCode,
+		IsPublic:    list.IsPublic,
+		CreatedBy:   list.CreatedBy,
+		CreatedAt:   list.CreatedAt,
+		UpdatedAt:   list.UpdatedAt,
+	}
+}
+
+func toAPIWord(word models.Word) apiWord {
+	result := apiWord{
+		ID:         word.ID,
+		ListID:     word.SpellingListID,
+		Word:       word.WordText,
+		Difficulty: word.DifficultyLevel,
+		Definition: word.Definition,
+		Position:   word.Position,
+		CreatedAt:  word.CreatedAt,
+	}
+	if word.AudioFilename != "" {
+		result.AudioURL = "/static/audio/" + word.AudioFilename
+	}
+	return result
+}
+
+func toAPIWords(words []models.Word) []apiWord {
+	result := make([]apiWord, 0, len(words))
+	for _, word := range words {
+		result = append(result, toAPIWord(word))
+	}
+	return result
+}
+
+func toAPIKid(kid models.Kid) apiKid {
+	return apiKid{
+		ID:          kid.ID,
+		Name:        kid.Name,
+		Username:    kid.Username,
+		AvatarColor: kid.AvatarColor,
+		FamilyCode:  kid.FamilyCode,
+		CreatedAt:   kid.CreatedAt,
+	}
+}
+
+func toAPISession(session models.PracticeSession) apiSession {
+	return apiSession{
+		ID:           session.ID,
+		KidID:        session.KidID,
+		ListID:       session.SpellingListID,
+		StartedAt:    session.StartedAt,
+		CompletedAt:  session.CompletedAt,
+		TotalWords:   session.TotalWords,
+		CorrectWords: session.CorrectWords,
+		PointsEarned: session.PointsEarned,
+	}
+}
+
+func toAPIStats(stats *models.KidStats, struggling []repository.StrugglingWord) apiStats {
+	result := apiStats{
+		TotalSessions:        stats.TotalSessions,
+		TotalWordsPracticed:  stats.TotalWordsPracticed,
+		TotalCorrect:         stats.TotalCorrect,
+		TotalPoints:          stats.TotalPoints,
+		UniqueWordsAttempted: stats.UniqueWordsAttempted,
+		OverallAccuracy:      stats.OverallAccuracy,
+		StrugglingWords:      make([]apiStrugglingWord, 0, len(struggling)),
+	}
+	for _, word := range struggling {
+		result.StrugglingWords = append(result.StrugglingWords, apiStrugglingWord{
+			WordID:          word.WordID,
+			Word:            word.WordText,
+			TotalAttempts:   word.TotalAttempts,
+			CorrectAttempts: word.CorrectAttempts,
+			SuccessRate:     word.SuccessRate,
+			LastAttempted:   word.LastAttempted,
+		})
+	}
+	return result
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"spellingclash/internal/audio"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"spellingclash/internal/security"
+	"spellingclash/internal/service"
+	"strings"
+	"testing"
+	"time"
+)
+
+type apiTestServer struct {
+	mux   *http.ServeMux
+	token string
+	auth  *service.AuthService
+}
+
+// newAPITestServer creates a database with two families, each with a parent and a
+// child, and returns the API routes with a token for the first parent
+func newAPITestServer(t *testing.T, rateLimit int) *apiTestServer {
+	t.Helper()
+
+	db, err := database.Initialize(filepath.Join(t.TempDir(), "api.db"))
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.RunMigrations("../../migrations"); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	seed := []string{
+		"INSERT INTO users (id, email, password_hash, name) VALUES (1, 'parent@example.com', 'x', 'Parent'), (2, 'other@example.com', 'x', 'Other')",
+		"INSERT INTO families (family_code) VALUES ('FAM1'), ('FAM2')",
+		"INSERT INTO family_members (family_code, user_id, role) VALUES ('FAM1', 1, 'parent'), ('FAM2', 2, 'parent')",
+		"INSERT INTO kids (id, family_code, name, username, password) VALUES (1, 'FAM1', 'Ada', 'ada1', 'pw'), (2, 'FAM2', 'Bob', 'bob2', 'pw')",
+	}
+	for _, query := range seed {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("failed to seed %q: %v", query, err)
+		}
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	familyRepo := repository.NewFamilyRepository(db)
+	kidRepo := repository.NewKidRepository(db)
+	teacherKidRepo := repository.NewTeacherKidRepository(db)
+	listRepo := repository.NewListRepository(db)
+	classRepo := repository.NewTeacherClassRepository(db)
+
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+
+	limiter := security.NewMemoryLimiter()
+	authService := service.NewAuthService(userRepo, familyRepo, repository.NewIdentityRepository(db), repository.NewTwoFactorRepository(db), apiTokenRepo, repository.NewSettingsRepository(db), limiter, time.Hour, "secret")
+	familyService := service.NewFamilyService(familyRepo, kidRepo)
+	teacherService := service.NewTeacherService(userRepo, familyRepo, kidRepo, teacherKidRepo, classRepo, listRepo)
+	tokenService := service.NewAPITokenService(apiTokenRepo, userRepo)
+	tts := audio.NewTTSService(t.TempDir(), audio.NewNoneProvider())
+	listService := service.NewListService(listRepo, familyRepo, userRepo, teacherKidRepo, classRepo, tts)
+	practiceService := service.NewPracticeService(repository.NewPracticeRepository(db), listRepo, repository.NewWordScheduleRepository(db), nil)
+
+	token, _, err := tokenService.CreateToken(1, "test")
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+
+	middleware := NewMiddleware(authService, familyService, tokenService, "secret", limiter, rateLimit)
+	api := NewAPIHandler(listService, familyService, teacherService, practiceService)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/lists", middleware.RequireAPIToken(api.ListLists))
+	mux.HandleFunc("POST /api/v1/lists", middleware.RequireAPIToken(api.CreateList))
+	mux.HandleFunc("GET /api/v1/lists/{id}", middleware.RequireAPIToken(api.GetList))
+	mux.HandleFunc("POST /api/v1/lists/{id}/words", middleware.RequireAPIToken(api.AddWord))
+	mux.HandleFunc("PUT /api/v1/lists/{id}/assignments/{kidId}", middleware.RequireAPIToken(api.AssignList))
+	mux.HandleFunc("GET /api/v1/kids", middleware.RequireAPIToken(api.ListKids))
+	mux.HandleFunc("GET /api/v1/kids/{id}", middleware.RequireAPIToken(api.GetKid))
+
+	return &apiTestServer{mux: mux, token: token, auth: authService}
+}
+
+func (s *apiTestServer) do(t *testing.T, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	recorder := httptest.NewRecorder()
+	s.mux.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestAPIRequiresToken(t *testing.T) {
+	server := newAPITestServer(t, 100)
+
+	for _, token := range []string{"", "sc_not-a-real-token"} {
+		recorder := server.do(t, "GET", "/api/v1/kids", token, "")
+		if recorder.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, recorder.Code)
+		}
+		if ct := recorder.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("token %q: Content-Type = %q, want application/json", token, ct)
+		}
+	}
+}
+
+func TestAPIRequiresTwoFactorSetup(t *testing.T) {
+	server := newAPITestServer(t, 100)
+
+	if err := server.auth.SetTwoFactorPolicy(models.TwoFactorEveryone); err != nil {
+		t.Fatalf("SetTwoFactorPolicy() error: %v", err)
+	}
+	if recorder := server.do(t, "GET", "/api/v1/kids", server.token, ""); recorder.Code != http.StatusForbidden {
+		t.Errorf("status without two-factor set up = %d, want 403", recorder.Code)
+	}
+
+	if err := server.auth.SetTwoFactorPolicy(models.TwoFactorOptional); err != nil {
+		t.Fatalf("SetTwoFactorPolicy() error: %v", err)
+	}
+	if recorder := server.do(t, "GET", "/api/v1/kids", server.token, ""); recorder.Code != http.StatusOK {
+		t.Errorf("status once two-factor is optional = %d, want 200", recorder.Code)
+	}
+}
+
+func TestAPIKidsAreScopedToFamily(t *testing.T) {
+	server := newAPITestServer(t, 100)
+
+	recorder := server.do(t, "GET", "/api/v1/kids", server.token, "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", recorder.Code, recorder.Body)
+	}
+	var kids []apiKid
+	if err := json.Unmarshal(recorder.Body.Bytes(), &kids); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(kids) != 1 || kids[0].Name != "Ada" {
+		t.Errorf("kids = %+v, want only Ada", kids)
+	}
+
+	if recorder := server.do(t, "GET", "/api/v1/kids/2", server.token, ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("other family's kid: status = %d, want 404", recorder.Code)
+	}
+}
+
+func TestAPICreateListAddWordAndAssign(t *testing.T) {
+	server := newAPITestServer(t, 100)
+
+	recorder := server.do(t, "POST", "/api/v1/lists", server.token, `{"name": "Week 1", "locale": "en-US"}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("create list: status = %d, want 201: %s", recorder.Code, recorder.Body)
+	}
+	var list apiList
+	if err := json.Unmarshal(recorder.Body.Bytes(), &list); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if list.Name != "Week 1" || list.Locale != "en-US" || list.FamilyCode == nil || *list.FamilyCode != "FAM1" {
+		t.Errorf("unexpected list %+v", list)
+	}
+	listPath := "/api/v1/lists/" + jsonNumber(list.ID)
+
+	recorder = server.do(t, "POST", listPath+"/words", server.token, `{"word": "because", "difficulty": 2}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("add word: status = %d, want 201: %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = server.do(t, "PUT", listPath+"/assignments/1", server.token, `{"due_date": "2030-01-31"}`)
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("assign list: status = %d, want 204: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := server.do(t, "PUT", listPath+"/assignments/2", server.token, ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("assign to other family's kid: status = %d, want 404", recorder.Code)
+	}
+
+	recorder = server.do(t, "GET", listPath, server.token, "")
+	if err := json.Unmarshal(recorder.Body.Bytes(), &list); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(list.Words) != 1 || list.Words[0].Word != "because" || list.Words[0].Difficulty != 2 {
+		t.Errorf("words = %+v, want because", list.Words)
+	}
+
+	recorder = server.do(t, "GET", "/api/v1/kids/1", server.token, "")
+	var kid apiKid
+	if err := json.Unmarshal(recorder.Body.Bytes(), &kid); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(kid.AssignedLists) != 1 || kid.AssignedLists[0].DueDate == nil {
+		t.Errorf("assigned lists = %+v, want Week 1 with a due date", kid.AssignedLists)
+	}
+
+	if recorder := server.do(t, "POST", "/api/v1/lists", server.token, `{"title": "x"}`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("unknown field: status = %d, want 400", recorder.Code)
+	}
+}
+
+func TestAPIRateLimitsPerToken(t *testing.T) {
+	server := newAPITestServer(t, 2)
+
+	for i := 0; i < 2; i++ {
+		if recorder := server.do(t, "GET", "/api/v1/lists", server.token, ""); recorder.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i, recorder.Code)
+		}
+	}
+	recorder := server.do(t, "GET", "/api/v1/lists", server.token, "")
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", recorder.Code)
+	}
+	if recorder.Header().Get("Retry-After") == "" {
+		t.Error("expected a Retry-After header")
+	}
+}
+
+func jsonNumber(id int64) string {
+	data, _ := json.Marshal(id)
+	return string(data)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"spellingclash/internal/service"
+	"strconv"
+)
+
+// APITokenHandler handles the page where parents and teachers manage their API tokens
+type APITokenHandler struct {
+	tokenService *service.APITokenService
+	middleware   *Middleware
+	templates    *template.Template
+}
+
+// NewAPITokenHandler creates a new API token handler
+func NewAPITokenHandler(tokenService *service.APITokenService, middleware *Middleware, templates *template.Template) *APITokenHandler {
+	return &APITokenHandler{
+		tokenService: tokenService,
+		middleware:   middleware,
+		templates:    templates,
+	}
+}
+
+// ShowTokens displays the user's API tokens
+func (h *APITokenHandler) ShowTokens(w http.ResponseWriter, r *http.Request) {
+	h.renderTokens(w, r, APITokensViewData{
+		Success: r.URL.Query().Get("success"),
+	})
+}
+
+// CreateToken creates an API token and shows its value once
+func (h *APITokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return
+	}
+
+	value, token, err := h.tokenService.CreateToken(user.ID, r.FormValue("name"))
+	if err != nil {
+		if !errors.Is(err, service.ErrAPITokenNameInvalid) && !errors.Is(err, service.ErrTooManyAPITokens) {
+			log.Printf("Error creating API token: %v", err)
+			err = errors.New("failed to create token")
+		}
+		h.renderTokens(w, r, APITokensViewData{Error: err.Error()})
+		return
+	}
+
+	log.Printf("User %d created API token %d (%s)", user.ID, token.ID, token.TokenPrefix)
+	h.renderTokens(w, r, APITokensViewData{NewToken: value})
+}
+
+// RevokeToken deletes one of the user's API tokens
+func (h *APITokenHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	tokenID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid token ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.tokenService.RevokeToken(user.ID, tokenID); err != nil {
+		if errors.Is(err, service.ErrAPITokenNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error revoking API token", err)
+		return
+	}
+
+	log.Printf("User %d revoked API token %d", user.ID, tokenID)
+	http.Redirect(w, r, "/account/api-tokens?success=Token+revoked", http.StatusSeeOther)
+}
+
+func (h *APITokenHandler) renderTokens(w http.ResponseWriter, r *http.Request, data APITokensViewData) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	tokens, err := h.tokenService.GetUserTokens(user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting API tokens", err)
+		return
+	}
+
+	data.Title = "API Tokens - WordClash"
+	data.User = user
+	data.Tokens = tokens
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		data.CSRFToken, _ = h.middleware.GetCSRFToken(cookie.Value)
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "api_tokens.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering API tokens template", err)
+	}
+}
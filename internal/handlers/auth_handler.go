@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"html/template"
 	"log"
 	"net/http"
 	"spellingclash/internal/repository"
 	"spellingclash/internal/security"
 	"spellingclash/internal/service"
+	"strings"
 )
 
 // AuthHandler handles authentication-related HTTP requests
@@ -69,7 +71,12 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	password := r.FormValue("password")
 
 	// Attempt login
-	session, loggedInUser, err := h.authService.Login(email, password)
+	session, loggedInUser, err := h.authService.Login(email, password, loginClient(r))
+	var twoFactorErr *service.TwoFactorRequiredError
+	if errors.As(err, &twoFactorErr) {
+		h.startTwoFactorLogin(w, r, twoFactorErr.Challenge)
+		return
+	}
 	if err != nil {
 		// Re-render login with error
 		data := LoginViewData{
@@ -78,6 +85,12 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 			Email:          email,
 			OAuthProviders: h.oauthProviderViews(r),
 		}
+		var lockedErr *service.AccountLockedError
+		if errors.As(err, &lockedErr) {
+			data.Error = "Too many failed logins for this account. Please try again later or reset your password."
+			w.Header().Set("Retry-After", security.RetryAfterSeconds(lockedErr.RetryAfter))
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
 		if err := h.templates.ExecuteTemplate(w, "login.tmpl", data); err != nil {
 			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering login template", err)
 		}
@@ -86,6 +99,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	// Set session cookie
 	http.SetCookie(w, security.CreateSessionCookie(r, SessionCookieName, session.ID, session.ExpiresAt))
+	h.rememberLoginDevice(w, r, loggedInUser)
 
 	// Redirect to the appropriate dashboard
 	if loggedInUser != nil && loggedInUser.IsTeacher {
@@ -267,7 +281,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Auto-login after registration
-	session, _, err := h.authService.Login(email, password)
+	session, signedIn, err := h.authService.Login(email, password, loginClient(r))
 	if err != nil {
 		// Registration succeeded but login failed - redirect to login
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
@@ -276,6 +290,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	// Set session cookie
 	http.SetCookie(w, security.CreateSessionCookie(r, SessionCookieName, session.ID, session.ExpiresAt))
+	h.rememberLoginDevice(w, r, signedIn)
 
 	// Redirect to the appropriate dashboard
 	if user.IsTeacher {
@@ -340,7 +355,7 @@ func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 	email := r.FormValue("email")
 
 	// Request password reset
-	err := h.authService.RequestPasswordReset(r.Context(), h.emailService, email)
+	err := h.authService.RequestPasswordReset(r.Context(), h.emailService, email, preferredLocale(r))
 
 	// Always show success message (even if email doesn't exist - security best practice)
 	data := ForgotPasswordViewData{
@@ -400,7 +415,7 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	password := r.FormValue("password")
 
 	// Attempt password reset
-	err := h.authService.ResetPassword(token, password)
+	err := h.authService.ResetPassword(token, password, security.GetClientIP(r))
 	if err != nil {
 		data := ResetPasswordViewData{
 			Title: "Reset Password - WordClash",
@@ -424,3 +439,15 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering login template", err)
 	}
 }
+
+// preferredLocale returns the first language in the request's Accept-Language
+// header (e.g. "fr-CA" from "fr-CA,fr;q=0.9,en;q=0.8"), or "" if there isn't one
+func preferredLocale(r *http.Request) string {
+	for _, tag := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, _, _ = strings.Cut(tag, ";")
+		if tag = strings.TrimSpace(tag); tag != "" && tag != "*" {
+			return tag
+		}
+	}
+	return ""
+}
@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"spellingclash/internal/models"
+	"spellingclash/internal/service"
+	"strconv"
+	"time"
+)
+
+// clashHeartbeat is how often an idle event stream is written to, so proxies
+// don't close it
+const clashHeartbeat = 15 * time.Second
+
+// ClashHandler handles live head-to-head clash matches between kids
+type ClashHandler struct {
+	clashService *service.ClashService
+	listService  *service.ListService
+	templates    *template.Template
+}
+
+// NewClashHandler creates a new clash handler
+func NewClashHandler(clashService *service.ClashService, listService *service.ListService, templates *template.Template) *ClashHandler {
+	return &ClashHandler{
+		clashService: clashService,
+		listService:  listService,
+		templates:    templates,
+	}
+}
+
+// clashLobbyRedirect sends a kid back to the clash lobby with a message
+func clashLobbyRedirect(w http.ResponseWriter, r *http.Request, key, message string) {
+	http.Redirect(w, r, "/child/clash?"+url.Values{key: {message}}.Encode(), http.StatusSeeOther)
+}
+
+// ShowLobby shows a kid who they can challenge, their open challenges and the
+// matches they've played
+func (h *ClashHandler) ShowLobby(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Redirect(w, r, "/child/select", http.StatusSeeOther)
+		return
+	}
+
+	if matchID := h.clashService.ActiveMatch(kid.ID); matchID != 0 {
+		http.Redirect(w, r, fmt.Sprintf("/child/clash/%d", matchID), http.StatusSeeOther)
+		return
+	}
+
+	rivals, err := h.clashService.GetRivals(kid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting clash rivals", err)
+		return
+	}
+	lists, err := h.listService.GetKidAssignedLists(kid.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting assigned lists", err)
+		return
+	}
+	history, err := h.clashService.GetHistory(kid)
+	if err != nil {
+		log.Printf("Error getting clash history: %v", err)
+	}
+
+	data := KidClashLobbyViewData{
+		Title:   "Clash - WordClash",
+		Kid:     kid,
+		Rivals:  rivals,
+		Lists:   lists,
+		Invites: h.clashService.GetInvites(kid.ID),
+		History: history,
+		Error:   r.URL.Query().Get("error"),
+		Success: r.URL.Query().Get("success"),
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "clash_lobby.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering clash lobby template", err)
+	}
+}
+
+// ShowInvites renders the lobby's open challenges, polled by htmx, and sends
+// both kids to the match as soon as it is accepted
+func (h *ClashHandler) ShowInvites(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	if matchID := h.clashService.ActiveMatch(kid.ID); matchID != 0 {
+		w.Header().Set("HX-Redirect", fmt.Sprintf("/child/clash/%d", matchID))
+		return
+	}
+
+	data := KidClashLobbyViewData{Kid: kid, Invites: h.clashService.GetInvites(kid.ID)}
+	if err := h.templates.ExecuteTemplate(w, "clash_invites.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering clash invites template", err)
+	}
+}
+
+// Challenge invites a rival to a match on a list assigned to both of them
+func (h *ClashHandler) Challenge(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return
+	}
+	rivalID, err := strconv.ParseInt(r.FormValue("rival_id"), 10, 64)
+	if err != nil {
+		clashLobbyRedirect(w, r, "error", "Pick someone to challenge.")
+		return
+	}
+	listID, err := strconv.ParseInt(r.FormValue("list_id"), 10, 64)
+	if err != nil {
+		clashLobbyRedirect(w, r, "error", "Pick a list to play.")
+		return
+	}
+
+	_, err = h.clashService.Challenge(kid, rivalID, listID)
+	switch {
+	case errors.Is(err, service.ErrClashNotRival):
+		clashLobbyRedirect(w, r, "error", "You can only challenge kids in your family or class.")
+	case errors.Is(err, service.ErrClashListShared):
+		clashLobbyRedirect(w, r, "error", "Pick a list that you've both been given.")
+	case errors.Is(err, service.ErrClashNoAudio):
+		clashLobbyRedirect(w, r, "error", "That list's words can't be read out yet. Try again soon or pick another list.")
+	case errors.Is(err, service.ErrClashBusy):
+		clashLobbyRedirect(w, r, "error", "One of you is already in a clash.")
+	case err != nil:
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error creating clash match", err)
+	default:
+		clashLobbyRedirect(w, r, "success", "Challenge sent! The match starts as soon as it's accepted.")
+	}
+}
+
+// clashMatchID reads the match ID from the request path
+func clashMatchID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	matchID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return matchID, true
+}
+
+// Accept starts a match the kid was challenged to and takes them to it
+func (h *ClashHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+	matchID, ok := clashMatchID(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.clashService.Accept(kid.ID, matchID)
+	if errors.Is(err, service.ErrClashNotFound) || errors.Is(err, service.ErrClashNotOpen) {
+		clashLobbyRedirect(w, r, "error", "That challenge isn't open any more.")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error accepting clash match", err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/child/clash/%d", matchID), http.StatusSeeOther)
+}
+
+// Cancel declines a challenge the kid received or withdraws one they sent
+func (h *ClashHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+	matchID, ok := clashMatchID(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.clashService.Cancel(kid.ID, matchID)
+	if err != nil && !errors.Is(err, service.ErrClashNotFound) && !errors.Is(err, service.ErrClashNotOpen) {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error cancelling clash match", err)
+		return
+	}
+
+	http.Redirect(w, r, "/child/clash", http.StatusSeeOther)
+}
+
+// ShowMatch shows the match page, which follows the match over its event stream
+func (h *ClashHandler) ShowMatch(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Redirect(w, r, "/child/select", http.StatusSeeOther)
+		return
+	}
+	matchID, ok := clashMatchID(w, r)
+	if !ok {
+		return
+	}
+
+	state, err := h.clashService.State(kid, matchID)
+	if errors.Is(err, service.ErrClashNotFound) {
+		clashLobbyRedirect(w, r, "error", "That match couldn't be found.")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting clash match", err)
+		return
+	}
+
+	data := KidClashViewData{
+		Title: "Clash - WordClash",
+		Kid:   kid,
+		State: state,
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "clash.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering clash template", err)
+	}
+}
+
+// writeClashState sends the match as the kid sees it as a server-sent event
+func writeClashState(w http.ResponseWriter, rc *http.ResponseController, state *models.ClashState) error {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: state\ndata: %s\n\n", payload); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
+
+// MatchEvents streams a match to a kid's browser as server-sent events, sending
+// the match as they see it whenever it changes. Having the stream open marks the
+// kid as ready to play.
+func (h *ClashHandler) MatchEvents(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+	matchID, ok := clashMatchID(w, r)
+	if !ok {
+		return
+	}
+
+	// A match that is no longer live still sends how it ended, once
+	updates, unsubscribe, err := h.clashService.Subscribe(kid.ID, matchID)
+	if err != nil && !errors.Is(err, service.ErrClashNotFound) {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error subscribing to clash match", err)
+		return
+	}
+	if err == nil {
+		defer unsubscribe()
+	}
+
+	state, err := h.clashService.State(kid, matchID)
+	if errors.Is(err, service.ErrClashNotFound) {
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting clash match", err)
+		return
+	}
+
+	// The stream stays open for the whole match, well past the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Warning: Failed to clear write deadline for clash events: %v", err)
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(clashHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		if err := writeClashState(w, rc, state); err != nil || updates == nil {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			continue
+		case <-updates:
+		}
+
+		if state, err = h.clashService.State(kid, matchID); err != nil {
+			log.Printf("Error getting clash match %d: %v", matchID, err)
+			return
+		}
+	}
+}
+
+// RoundAudio plays the word being read out in a round
+func (h *ClashHandler) RoundAudio(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+	matchID, ok := clashMatchID(w, r)
+	if !ok {
+		return
+	}
+	round, err := strconv.Atoi(r.PathValue("round"))
+	if err != nil {
+		http.Error(w, "Invalid round", http.StatusBadRequest)
+		return
+	}
+
+	path, err := h.clashService.RoundAudio(kid.ID, matchID, round)
+	if errors.Is(err, service.ErrClashNotFound) {
+		http.Error(w, "Round not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting clash round audio", err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeFile(w, r, path)
+}
+
+// Answer records the kid's spelling of the word being read out
+func (h *ClashHandler) Answer(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+	matchID, ok := clashMatchID(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return
+	}
+
+	err := h.clashService.Answer(kid.ID, matchID, r.FormValue("answer"))
+	if errors.Is(err, service.ErrClashNotFound) {
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error answering clash round", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
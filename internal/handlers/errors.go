@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"log"
 	"net/http"
 )
@@ -15,3 +16,24 @@ func respondWithError(w http.ResponseWriter, status int, userMsg, logMsg string,
 
 	http.Error(w, userMsg, status)
 }
+
+// respondWithJSON writes v as a JSON response body
+func respondWithJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error encoding JSON response: %v", err)
+	}
+}
+
+// respondWithJSONError is respondWithError for the JSON API, writing {"error": userMsg}
+func respondWithJSONError(w http.ResponseWriter, status int, userMsg, logMsg string, err error) {
+	if err != nil {
+		if logMsg == "" {
+			logMsg = userMsg
+		}
+		log.Printf("%s: %v", logMsg, err)
+	}
+
+	respondWithJSON(w, status, map[string]string{"error": userMsg})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"html/template"
 	"log"
 	"net/http"
@@ -9,27 +10,38 @@ import (
 	"spellingclash/internal/security"
 	"spellingclash/internal/service"
 	"strconv"
+	"time"
 )
 
 // KidHandler handles kid-related HTTP requests
 type KidHandler struct {
-	familyService   *service.FamilyService
-	teacherService  *service.TeacherService
-	listService     *service.ListService
-	practiceService *service.PracticeService
-	middleware      *Middleware
-	templates       *template.Template
+	familyService       *service.FamilyService
+	kidLoginService     *service.KidLoginService
+	teacherService      *service.TeacherService
+	listService         *service.ListService
+	practiceService     *service.PracticeService
+	streakService       *service.StreakService
+	achievementService  *service.AchievementService
+	spellingTestService *service.SpellingTestService
+	spellingBeeService  *service.SpellingBeeService
+	middleware          *Middleware
+	templates           *template.Template
 }
 
 // NewKidHandler creates a new kid handler
-func NewKidHandler(familyService *service.FamilyService, teacherService *service.TeacherService, listService *service.ListService, practiceService *service.PracticeService, middleware *Middleware, templates *template.Template) *KidHandler {
+func NewKidHandler(familyService *service.FamilyService, kidLoginService *service.KidLoginService, teacherService *service.TeacherService, listService *service.ListService, practiceService *service.PracticeService, streakService *service.StreakService, achievementService *service.AchievementService, spellingTestService *service.SpellingTestService, spellingBeeService *service.SpellingBeeService, middleware *Middleware, templates *template.Template) *KidHandler {
 	return &KidHandler{
-		familyService:   familyService,
-		teacherService:  teacherService,
-		listService:     listService,
-		practiceService: practiceService,
-		middleware:      middleware,
-		templates:       templates,
+		familyService:       familyService,
+		kidLoginService:     kidLoginService,
+		teacherService:      teacherService,
+		listService:         listService,
+		practiceService:     practiceService,
+		streakService:       streakService,
+		achievementService:  achievementService,
+		spellingTestService: spellingTestService,
+		spellingBeeService:  spellingBeeService,
+		middleware:          middleware,
+		templates:           templates,
 	}
 }
 
@@ -41,6 +53,9 @@ func (h *KidHandler) ShowKidSelect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Give the device its cookie before any password is tried
+	h.kidDevice(w, r)
+
 	// Check for error parameter
 	hasError := r.URL.Query().Get("error") == "invalid"
 
@@ -77,6 +92,8 @@ func (h *KidHandler) KidLogin(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		h.kidDevice(w, r)
+
 		// Check for error parameter
 		hasError := r.URL.Query().Get("error") == "invalid"
 
@@ -115,11 +132,24 @@ func (h *KidHandler) KidLogin(w http.ResponseWriter, r *http.Request) {
 
 	// If password is provided, verify it
 	if password != "" {
-		if kid.Password != password {
+		device := h.kidDevice(w, r)
+		token, err := h.kidLoginService.CheckPassword(r.Context(), kid, password, device)
+		var waitErr *service.KidLoginWaitError
+		switch {
+		case err == nil:
+		case errors.As(err, &waitErr):
+			log.Printf("Kid %d login paused for %s after too many wrong passwords", kid.ID, waitErr.RetryAfter.Round(time.Second))
+			h.renderKidLoginWait(w, kid, waitErr.RetryAfter)
+			return
+		case errors.Is(err, service.ErrWrongKidPassword):
 			// Redirect back to password page with error
 			http.Redirect(w, r, "/child/login/"+strconv.FormatInt(kid.ID, 10)+"?error=invalid", http.StatusSeeOther)
 			return
+		default:
+			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error checking kid password", err)
+			return
 		}
+		rememberKidDevice(w, r, device, token)
 
 		// Password correct - create session
 		sessionID, expiresAt, err := h.familyService.CreateKidSession(kid.ID)
@@ -176,6 +206,35 @@ func (h *KidHandler) KidDashboard(w http.ResponseWriter, r *http.Request) {
 		recentSessions = []models.PracticeSession{}
 	}
 
+	// Scheduled tests only show while their window is open
+	openTests, err := h.spellingTestService.GetOpenTestsForKid(kid.ID, time.Now())
+	if err != nil {
+		log.Printf("Error getting open spelling tests: %v", err)
+	}
+
+	runningBees, err := h.spellingBeeService.GetKidRunningBees(kid.ID)
+	if err != nil {
+		log.Printf("Error getting running spelling bees: %v", err)
+	}
+	beeHistory, err := h.spellingBeeService.GetKidHistory(kid.ID)
+	if err != nil {
+		log.Printf("Error getting spelling bee history: %v", err)
+	}
+
+	streaks, err := h.streakService.GetKidStreaks(kid, time.Now())
+	if err != nil {
+		log.Printf("Error getting streaks: %v", err)
+		streaks = &models.KidStreaks{}
+	}
+
+	// Unlocks are flagged as new the first time the trophy cabinet shows them
+	trophies, err := h.achievementService.GetTrophies(kid.ID)
+	if err != nil {
+		log.Printf("Error getting trophies: %v", err)
+	} else if err := h.achievementService.MarkSeen(kid.ID); err != nil {
+		log.Printf("Error marking achievements seen: %v", err)
+	}
+
 	data := KidDashboardViewData{
 		Title:          "My Dashboard - WordClash",
 		Kid:            kid,
@@ -183,6 +242,11 @@ func (h *KidHandler) KidDashboard(w http.ResponseWriter, r *http.Request) {
 		TotalPoints:    totalPoints,
 		TotalSessions:  totalSessions,
 		RecentSessions: recentSessions,
+		OpenTests:      openTests,
+		RunningBees:    runningBees,
+		BeeHistory:     beeHistory,
+		Streaks:        streaks,
+		Trophies:       trophies,
 	}
 
 	if err := h.templates.ExecuteTemplate(w, "kid_dashboard.tmpl", data); err != nil {
@@ -334,6 +398,12 @@ func (h *KidHandler) GetKidDetails(w http.ResponseWriter, r *http.Request) {
 		stats = &models.KidStats{}
 	}
 
+	streaks, err := h.streakService.GetKidStreaks(kid, time.Now())
+	if err != nil {
+		log.Printf("Error getting streaks: %v", err)
+		streaks = &models.KidStreaks{}
+	}
+
 	// Get CSRF token
 	csrfToken := ""
 	if cookie, err := r.Cookie(SessionCookieName); err == nil {
@@ -348,6 +418,7 @@ func (h *KidHandler) GetKidDetails(w http.ResponseWriter, r *http.Request) {
 		AllLists:        allLists,
 		StrugglingWords: strugglingWords,
 		Stats:           stats,
+		Streaks:         streaks,
 		CSRFToken:       csrfToken,
 	}
 
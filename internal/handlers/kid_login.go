@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"spellingclash/internal/models"
+	"spellingclash/internal/security"
+	"spellingclash/internal/service"
+	"strings"
+	"time"
+)
+
+const (
+	// kidDeviceCookieName identifies a device children log in on, so wrong
+	// passwords can be counted per device
+	kidDeviceCookieName = "kid_device"
+
+	// kidTrustCookieName holds tokens for the children who have logged in on the
+	// device before, who skip the per-child guessing limits there
+	kidTrustCookieName = "kid_trusted"
+
+	kidDeviceCookieDuration = 365 * 24 * time.Hour
+)
+
+// kidDevice identifies the device a kid is logging in from. A device without a
+// cookie the server issued is given a new one, and identified by its IP address
+// until it sends it back.
+func (h *KidHandler) kidDevice(w http.ResponseWriter, r *http.Request) service.KidDevice {
+	device := service.KidDevice{IP: security.GetClientIP(r)}
+	if cookie, err := r.Cookie(kidDeviceCookieName); err == nil {
+		device.ID, device.HasCookie = h.kidLoginService.DeviceID(cookie.Value)
+	}
+	if !device.HasCookie {
+		device.ID = "ip:" + device.IP
+		http.SetCookie(w, security.CreateSessionCookie(r, kidDeviceCookieName, h.kidLoginService.NewDeviceCookie(), time.Now().Add(kidDeviceCookieDuration)))
+	}
+	if cookie, err := r.Cookie(kidTrustCookieName); err == nil && cookie.Value != "" {
+		device.TrustTokens = strings.Split(cookie.Value, ".")
+	}
+	return device
+}
+
+// rememberKidDevice stores the token from a successful login on the device
+func rememberKidDevice(w http.ResponseWriter, r *http.Request, device service.KidDevice, token string) {
+	if token == "" {
+		return
+	}
+	tokens := service.TrustTokens(device.TrustTokens, token)
+	http.SetCookie(w, security.CreateSessionCookie(r, kidTrustCookieName, strings.Join(tokens, "."), time.Now().Add(kidDeviceCookieDuration)))
+}
+
+// renderKidLoginWait shows the login page asking the child to wait before trying
+// again
+func (h *KidHandler) renderKidLoginWait(w http.ResponseWriter, kid *models.Kid, retryAfter time.Duration) {
+	data := KidLoginViewData{
+		Title:       "Login - WordClash",
+		Kid:         kid,
+		WaitSeconds: int((retryAfter + time.Second - 1) / time.Second),
+		WaitMinutes: int((retryAfter + time.Minute - 1) / time.Minute),
+		Locked:      retryAfter > time.Minute,
+	}
+
+	w.Header().Set("Retry-After", security.RetryAfterSeconds(retryAfter))
+	w.WriteHeader(http.StatusTooManyRequests)
+	if err := h.templates.ExecuteTemplate(w, "kid_login.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering kid login template", err)
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"spellingclash/internal/models"
+	"spellingclash/internal/service"
+	"strconv"
+)
+
+// LeaderboardHandler handles the leaderboard pages for kids, parents and teachers
+type LeaderboardHandler struct {
+	leaderboardService *service.LeaderboardService
+	familyService      *service.FamilyService
+	teacherService     *service.TeacherService
+	middleware         *Middleware
+	templates          *template.Template
+}
+
+// NewLeaderboardHandler creates a new leaderboard handler
+func NewLeaderboardHandler(leaderboardService *service.LeaderboardService, familyService *service.FamilyService, teacherService *service.TeacherService, middleware *Middleware, templates *template.Template) *LeaderboardHandler {
+	return &LeaderboardHandler{
+		leaderboardService: leaderboardService,
+		familyService:      familyService,
+		teacherService:     teacherService,
+		middleware:         middleware,
+		templates:          templates,
+	}
+}
+
+// leaderboardsPath is the leaderboards page for the user's role
+func leaderboardsPath(user *models.User) string {
+	if user.IsTeacher {
+		return "/teacher/leaderboards"
+	}
+	return "/parent/leaderboards"
+}
+
+// ShowKidLeaderboards shows a kid the boards they are on
+func (h *LeaderboardHandler) ShowKidLeaderboards(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Redirect(w, r, "/child/select", http.StatusSeeOther)
+		return
+	}
+
+	period := service.LeaderboardPeriod(r.URL.Query().Get("period"))
+	boards, hidden, err := h.leaderboardService.GetKidBoards(kid, period)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting kid leaderboards", err)
+		return
+	}
+
+	data := KidLeaderboardsViewData{
+		Title:  "Leaderboards - WordClash",
+		Kid:    kid,
+		Period: period,
+		Boards: boards,
+		Hidden: hidden,
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "kid_leaderboards.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering kid leaderboards template", err)
+	}
+}
+
+// ShowLeaderboards shows a parent their families' boards, or a teacher their
+// roster's and assignments' boards, with each kid's leaderboard settings
+func (h *LeaderboardHandler) ShowLeaderboards(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	period := service.LeaderboardPeriod(r.URL.Query().Get("period"))
+	var boards []models.Leaderboard
+	var kids []models.Kid
+	var err error
+	if user.IsTeacher {
+		if boards, err = h.leaderboardService.GetTeacherBoards(user.ID, period); err != nil {
+			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting teacher leaderboards", err)
+			return
+		}
+		if kids, err = h.teacherService.GetTeacherKids(user.ID); err != nil {
+			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting teacher kids", err)
+			return
+		}
+	} else {
+		families, err := h.familyService.GetUserFamilies(user.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting families", err)
+			return
+		}
+		for _, family := range families {
+			board, err := h.leaderboardService.FamilyBoard(family.FamilyCode, period, 0)
+			if err != nil {
+				respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting family leaderboard", err)
+				return
+			}
+			board.Title = "Family " + family.FamilyCode
+			boards = append(boards, *board)
+		}
+		if kids, err = h.familyService.GetAllUserKids(user.ID); err != nil {
+			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting kids", err)
+			return
+		}
+	}
+
+	privacy, err := h.leaderboardService.GetPrivacySettings(kids)
+	if err != nil {
+		log.Printf("Error getting leaderboard settings: %v", err)
+	}
+
+	data := LeaderboardsViewData{
+		Title:   "Leaderboards - WordClash",
+		User:    user,
+		Period:  period,
+		Boards:  boards,
+		Privacy: privacy,
+		Error:   r.URL.Query().Get("error"),
+		Success: r.URL.Query().Get("success"),
+	}
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		data.CSRFToken, _ = h.middleware.GetCSRFToken(cookie.Value)
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "leaderboards.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering leaderboards template", err)
+	}
+}
+
+// UpdateLeaderboardPrivacy saves a kid's nickname and whether the parent or
+// teacher has taken them off leaderboards. Parents and teachers each have their
+// own switch, so one can't put back a kid the other has taken off.
+func (h *LeaderboardHandler) UpdateLeaderboardPrivacy(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return
+	}
+
+	kidID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid kid ID", http.StatusBadRequest)
+		return
+	}
+
+	kid, err := h.familyService.GetKid(kidID)
+	if err != nil {
+		http.Error(w, "Kid not found", http.StatusNotFound)
+		return
+	}
+
+	nickname := r.FormValue("nickname")
+	hidden := r.FormValue("hidden") == "on"
+	if user.IsTeacher {
+		if err := h.teacherService.VerifyTeacherKidAccess(user.ID, kid.ID); err != nil {
+			http.Error(w, ErrUnauthorized, http.StatusForbidden)
+			return
+		}
+		err = h.leaderboardService.SetTeacherPrivacy(kid.ID, nickname, hidden)
+	} else {
+		if err := h.familyService.VerifyFamilyAccess(user.ID, kid.FamilyCode); err != nil {
+			http.Error(w, ErrUnauthorized, http.StatusForbidden)
+			return
+		}
+		err = h.leaderboardService.SetParentPrivacy(kid.ID, nickname, hidden)
+	}
+
+	if errors.Is(err, service.ErrInvalidNickname) {
+		http.Redirect(w, r, leaderboardsPath(user)+"?"+url.Values{"error": {"Nicknames can be up to 30 characters and can't contain bad words."}}.Encode(), http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error updating leaderboard settings", err)
+		return
+	}
+
+	http.Redirect(w, r, leaderboardsPath(user)+"?"+url.Values{"success": {"Leaderboard settings saved for " + kid.Name + "."}}.Encode(), http.StatusSeeOther)
+}
@@ -97,6 +97,7 @@ func (h *ListHandler) ShowLists(w http.ResponseWriter, r *http.Request) {
 		User:      user,
 		Lists:     lists,
 		Families:  families,
+		Locales:   models.SupportedLocales,
 		CSRFToken: csrfToken,
 	}
 
@@ -120,6 +121,7 @@ func (h *ListHandler) CreateList(w http.ResponseWriter, r *http.Request) {
 
 	name := r.FormValue("name")
 	description := r.FormValue("description")
+	locale := r.FormValue("locale")
 
 	familyCode := ""
 	if !user.IsTeacher {
@@ -133,7 +135,7 @@ func (h *ListHandler) CreateList(w http.ResponseWriter, r *http.Request) {
 		familyCode = families[0].FamilyCode
 	}
 
-	list, err := h.listService.CreateList(familyCode, user.ID, name, description)
+	list, err := h.listService.CreateList(familyCode, user.ID, name, description, locale)
 	if err != nil {
 		log.Printf("Error creating list: %v", err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -217,17 +219,25 @@ func (h *ListHandler) ViewList(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Flag likely typos on lists the user can edit
+	var spellingWarnings map[string]*models.SpellingWarning
+	if !list.IsPublic {
+		spellingWarnings = h.listService.GetSpellingWarnings(list, words)
+	}
+
 	// Get CSRF token
 	csrfToken := h.getCSRFToken(r)
 
 	data := ListDetailViewData{
-		Title:        list.Name + " - WordClash",
-		User:         user,
-		List:         list,
-		Words:        words,
-		AssignedKids: assignedKids,
-		FamilyKids:   familyKids,
-		CSRFToken:    csrfToken,
+		Title:            list.Name + " - WordClash",
+		User:             user,
+		List:             list,
+		Words:            words,
+		AssignedKids:     assignedKids,
+		FamilyKids:       familyKids,
+		Locales:          models.SupportedLocales,
+		SpellingWarnings: spellingWarnings,
+		CSRFToken:        csrfToken,
 	}
 
 	if err := h.templates.ExecuteTemplate(w, "list_detail.tmpl", data); err != nil {
@@ -257,8 +267,9 @@ func (h *ListHandler) UpdateList(w http.ResponseWriter, r *http.Request) {
 
 	name := r.FormValue("name")
 	description := r.FormValue("description")
+	locale := r.FormValue("locale")
 
-	if err := h.listService.UpdateList(listID, user.ID, name, description); err != nil {
+	if err := h.listService.UpdateList(listID, user.ID, name, description, locale); err != nil {
 		log.Printf("Error updating list: %v", err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+	"spellingclash/internal/models"
+	"spellingclash/internal/security"
+	"spellingclash/internal/service"
+	"strings"
+	"time"
+)
+
+const (
+	// knownDeviceCookieName holds tokens for the accounts that have signed in on
+	// the browser before, which skip the account lockout there
+	knownDeviceCookieName = "known_device"
+
+	knownDeviceCookieDuration = 365 * 24 * time.Hour
+)
+
+// loginClient identifies where a password sign-in comes from
+func loginClient(r *http.Request) service.LoginClient {
+	client := service.LoginClient{IP: security.GetClientIP(r)}
+	if cookie, err := r.Cookie(knownDeviceCookieName); err == nil && cookie.Value != "" {
+		client.DeviceTokens = strings.Split(cookie.Value, ".")
+	}
+	return client
+}
+
+// rememberLoginDevice stores a token on the browser the user has just signed in on
+func (h *AuthHandler) rememberLoginDevice(w http.ResponseWriter, r *http.Request, user *models.User) {
+	if user == nil {
+		return
+	}
+	tokens := service.KnownDeviceTokens(loginClient(r).DeviceTokens, h.authService.DeviceToken(user))
+	http.SetCookie(w, security.CreateSessionCookie(r, knownDeviceCookieName, strings.Join(tokens, "."), time.Now().Add(knownDeviceCookieDuration)))
+}
@@ -2,11 +2,14 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"spellingclash/internal/models"
 	"spellingclash/internal/security"
 	"spellingclash/internal/service"
+	"strings"
 	"time"
 )
 
@@ -18,23 +21,45 @@ const (
 	KidSessionContextKey ContextKey = "kid"
 )
 
+// apiRateWindow is the window for the per-token API rate limit
+const apiRateWindow = time.Minute
+
+// Rate limits for the public routes that check passwords or send email, per
+// client IP address. A school puts a whole class behind one address, so the kid
+// login limit leaves room for everyone logging in at once.
+var (
+	LoginRateLimit          = security.RateLimitPolicy{Name: "login", Limit: 20, Window: time.Minute}
+	TwoFactorLoginRateLimit = security.RateLimitPolicy{Name: "two-factor-login", Limit: 20, Window: time.Minute}
+	RegisterRateLimit       = security.RateLimitPolicy{Name: "register", Limit: 10, Window: time.Hour}
+	ForgotPasswordRateLimit = security.RateLimitPolicy{Name: "forgot-password", Limit: 5, Window: 15 * time.Minute}
+	ResetPasswordRateLimit  = security.RateLimitPolicy{Name: "reset-password", Limit: 10, Window: 15 * time.Minute}
+	KidLoginRateLimit       = security.RateLimitPolicy{Name: "kid-login", Limit: 60, Window: time.Minute}
+)
+
 // Middleware holds dependencies for middleware functions
 type Middleware struct {
-	authService   *service.AuthService
-	familyService *service.FamilyService
-	csrfGen       *security.CSRFGenerator
-	rateLimiter   *security.RateLimiter
+	authService     *service.AuthService
+	familyService   *service.FamilyService
+	apiTokenService *service.APITokenService
+	csrfGen         *security.CSRFGenerator
+	limiter         security.Limiter
+	apiRateLimit    security.RateLimitPolicy
 }
 
 // NewMiddleware creates a new middleware instance.
 // csrfSecret must be a stable per-deployment secret (e.g. from the CSRF_SECRET env var);
 // using a stateless HMAC approach means tokens survive pod restarts and work across replicas.
-func NewMiddleware(authService *service.AuthService, familyService *service.FamilyService, csrfSecret string) *Middleware {
+// limiter counts requests for the rate limits; use a database-backed one when running
+// more than one replica. apiRateLimit is the number of requests per minute allowed for
+// each API token.
+func NewMiddleware(authService *service.AuthService, familyService *service.FamilyService, apiTokenService *service.APITokenService, csrfSecret string, limiter security.Limiter, apiRateLimit int) *Middleware {
 	return &Middleware{
-		authService:   authService,
-		familyService: familyService,
-		csrfGen:       security.NewCSRFGenerator(csrfSecret),
-		rateLimiter:   security.NewRateLimiter(100, 1*time.Minute), // 100 requests per minute
+		authService:     authService,
+		familyService:   familyService,
+		apiTokenService: apiTokenService,
+		csrfGen:         security.NewCSRFGenerator(csrfSecret),
+		limiter:         limiter,
+		apiRateLimit:    security.RateLimitPolicy{Name: "api", Limit: apiRateLimit, Window: apiRateWindow},
 	}
 }
 
@@ -68,6 +93,11 @@ func (m *Middleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		m.touchSession(cookie.Value, r)
+		if m.redirectToTwoFactorSetup(w, r, user) {
+			return
+		}
+
 		// Add user to context
 		ctx := context.WithValue(r.Context(), UserContextKey, user)
 		next(w, r.WithContext(ctx))
@@ -102,6 +132,10 @@ func (m *Middleware) RequireKidAuth(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		if err := m.familyService.TouchKidSession(cookie.Value, r.UserAgent(), security.GetClientIP(r)); err != nil {
+			log.Printf("Error updating kid session: %v", err)
+		}
+
 		// Add kid to context
 		ctx := context.WithValue(r.Context(), KidSessionContextKey, kid)
 		next(w, r.WithContext(ctx))
@@ -133,12 +167,113 @@ func (m *Middleware) RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		m.touchSession(cookie.Value, r)
+		if m.redirectToTwoFactorSetup(w, r, user) {
+			return
+		}
+
 		// Add user to context
 		ctx := context.WithValue(r.Context(), UserContextKey, user)
 		next(w, r.WithContext(ctx))
 	}
 }
 
+// touchSession records when and where a session was last used for the session
+// management page. Failing to do so shouldn't stop the request.
+func (m *Middleware) touchSession(sessionID string, r *http.Request) {
+	if err := m.authService.TouchSession(sessionID, r.UserAgent(), security.GetClientIP(r)); err != nil {
+		log.Printf("Error updating session: %v", err)
+	}
+}
+
+// redirectToTwoFactorSetup sends users the admin requires to use two-factor
+// authentication to set it up before they can go anywhere else, reporting whether
+// the request was handled
+func (m *Middleware) redirectToTwoFactorSetup(w http.ResponseWriter, r *http.Request, user *models.User) bool {
+	if strings.HasPrefix(r.URL.Path, TwoFactorSetupPath) {
+		return false
+	}
+
+	needsSetup, err := m.authService.NeedsTwoFactorSetup(user)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error checking two-factor authentication", err)
+		return true
+	}
+	if needsSetup {
+		http.Redirect(w, r, TwoFactorSetupPath, http.StatusSeeOther)
+		return true
+	}
+	return false
+}
+
+// RequireAPIToken is middleware that requires a valid API token in the
+// Authorization header. Requests are rate limited per token, and failed
+// authentication attempts per client IP.
+func (m *Middleware) RequireAPIToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		value, ok := bearerToken(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			respondWithJSONError(w, http.StatusUnauthorized, "API token required", "", nil)
+			return
+		}
+
+		user, token, err := m.apiTokenService.Authenticate(value)
+		if err != nil {
+			if !errors.Is(err, service.ErrInvalidAPIToken) {
+				respondWithJSONError(w, http.StatusInternalServerError, ErrInternalServerError, "Error authenticating API token", err)
+				return
+			}
+			ip := security.GetClientIP(r)
+			if allowed, retryAfter := m.allow(m.apiRateLimit, "ip:"+ip); !allowed {
+				m.respondRateLimited(w, retryAfter)
+				log.Printf("API authentication rate limit exceeded for IP: %s", ip)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+			respondWithJSONError(w, http.StatusUnauthorized, "Invalid API token", "", nil)
+			return
+		}
+
+		if allowed, retryAfter := m.allow(m.apiRateLimit, fmt.Sprintf("token:%d", token.ID)); !allowed {
+			m.respondRateLimited(w, retryAfter)
+			log.Printf("API rate limit exceeded for token %d (user %d)", token.ID, user.ID)
+			return
+		}
+
+		// Tokens stop working while the admin requires two-factor authentication
+		// the user hasn't set up, as their sessions do
+		needsSetup, err := m.authService.NeedsTwoFactorSetup(user)
+		if err != nil {
+			respondWithJSONError(w, http.StatusInternalServerError, ErrInternalServerError, "Error checking two-factor authentication", err)
+			return
+		}
+		if needsSetup {
+			respondWithJSONError(w, http.StatusForbidden, "Two-factor authentication must be set up before this account can use the API", "", nil)
+			return
+		}
+
+		// Add user to context
+		ctx := context.WithValue(r.Context(), UserContextKey, user)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+func (m *Middleware) respondRateLimited(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", security.RetryAfterSeconds(retryAfter))
+	respondWithJSONError(w, http.StatusTooManyRequests, "Too many requests. Please try again later.", "", nil)
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	value = strings.TrimSpace(value)
+	return value, value != ""
+}
+
 // Logging middleware logs HTTP requests
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -170,14 +305,15 @@ func GetKidFromContext(ctx context.Context) *models.Kid {
 	return kid
 }
 
-// RateLimit middleware limits requests per IP address
-func (m *Middleware) RateLimit(next http.HandlerFunc) http.HandlerFunc {
+// RateLimit middleware limits requests per IP address under policy
+func (m *Middleware) RateLimit(policy security.RateLimitPolicy, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ip := security.GetClientIP(r)
 
-		if !m.rateLimiter.Allow(ip) {
+		if allowed, retryAfter := m.allow(policy, ip); !allowed {
+			w.Header().Set("Retry-After", security.RetryAfterSeconds(retryAfter))
 			http.Error(w, "Too many requests. Please try again later.", http.StatusTooManyRequests)
-			log.Printf("Rate limit exceeded for IP: %s", ip)
+			log.Printf("Rate limit %q exceeded for IP: %s", policy.Name, ip)
 			return
 		}
 
@@ -185,6 +321,17 @@ func (m *Middleware) RateLimit(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// allow records a request against a rate limit. If the limiter fails, e.g. because
+// the database is down, the request is let through rather than locking everyone out.
+func (m *Middleware) allow(policy security.RateLimitPolicy, key string) (bool, time.Duration) {
+	allowed, retryAfter, err := m.limiter.Allow(policy, key)
+	if err != nil {
+		log.Printf("Error checking rate limit %q: %v", policy.Name, err)
+		return true, 0
+	}
+	return allowed, retryAfter
+}
+
 // CSRFProtect middleware validates CSRF tokens on state-changing requests
 func (m *Middleware) CSRFProtect(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"spellingclash/internal/service"
+	"time"
+)
+
+// NotificationHandler handles the page where parents and teachers choose which emails they get
+type NotificationHandler struct {
+	digestService *service.DigestService
+	middleware    *Middleware
+	templates     *template.Template
+}
+
+// NewNotificationHandler creates a new notification settings handler
+func NewNotificationHandler(digestService *service.DigestService, middleware *Middleware, templates *template.Template) *NotificationHandler {
+	return &NotificationHandler{
+		digestService: digestService,
+		middleware:    middleware,
+		templates:     templates,
+	}
+}
+
+// ShowNotifications displays the user's email notification settings
+func (h *NotificationHandler) ShowNotifications(w http.ResponseWriter, r *http.Request) {
+	h.renderNotifications(w, r, NotificationsViewData{
+		Success: r.URL.Query().Get("success"),
+	})
+}
+
+// UpdateNotifications saves the user's email notification settings
+func (h *NotificationHandler) UpdateNotifications(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return
+	}
+
+	subscribed := r.FormValue("weekly_digest") == "on"
+	if err := h.digestService.SetSubscribed(user.ID, subscribed); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error saving digest subscription", err)
+		return
+	}
+
+	log.Printf("User %d set weekly digest to %t", user.ID, subscribed)
+	http.Redirect(w, r, "/account/notifications?success=Settings+saved", http.StatusSeeOther)
+}
+
+func (h *NotificationHandler) renderNotifications(w http.ResponseWriter, r *http.Request, data NotificationsViewData) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	subscribed, err := h.digestService.IsSubscribed(user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting digest subscription", err)
+		return
+	}
+
+	data.Title = "Email Notifications - WordClash"
+	data.User = user
+	data.WeeklyDigest = subscribed
+	data.EmailAvailable = h.digestService.IsAvailable()
+	data.NextDigest = h.digestService.NextDigest(time.Now())
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		data.CSRFToken, _ = h.middleware.GetCSRFToken(cookie.Value)
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "notifications.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering notifications template", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"math/big"
 	"net/http"
 	"net/url"
@@ -18,6 +19,7 @@ import (
 	"golang.org/x/oauth2"
 
 	"spellingclash/internal/security"
+	"spellingclash/internal/service"
 )
 
 // OAuthProvider defines provider configuration and metadata
@@ -27,6 +29,7 @@ type OAuthProvider struct {
 	Config      *oauth2.Config
 	UserInfoURL string
 	AuthParams  map[string]string
+	OIDC        *OIDCProvider // Set for OpenID Connect providers, whose endpoints are discovered
 }
 
 type OAuthProviderView struct {
@@ -37,9 +40,11 @@ type OAuthProviderView struct {
 }
 
 type oauthUserInfo struct {
-	Subject string
-	Email   string
-	Name    string
+	Subject       string
+	Email         string
+	EmailVerified bool // The provider vouches that the user controls Email
+	Name          string
+	IsTeacher     bool // The provider says the user is a teacher
 }
 
 func (h *AuthHandler) oauthProviderViews(r *http.Request) []OAuthProviderView {
@@ -67,6 +72,15 @@ func (h *AuthHandler) oauthProviderViews(r *http.Request) []OAuthProviderView {
 
 // StartOAuth initiates the OAuth flow for a provider
 func (h *AuthHandler) StartOAuth(w http.ResponseWriter, r *http.Request) {
+	h.startOAuth(w, r, false)
+}
+
+// StartOAuthLink initiates the OAuth flow to link a provider to the signed-in user
+func (h *AuthHandler) StartOAuthLink(w http.ResponseWriter, r *http.Request) {
+	h.startOAuth(w, r, true)
+}
+
+func (h *AuthHandler) startOAuth(w http.ResponseWriter, r *http.Request, link bool) {
 	providerKey := r.PathValue("provider")
 	provider, ok := h.oauthProviders[providerKey]
 	if !ok || provider.Config == nil || provider.Config.ClientID == "" || provider.Config.ClientSecret == "" {
@@ -81,19 +95,27 @@ func (h *AuthHandler) StartOAuth(w http.ResponseWriter, r *http.Request) {
 	h.setTempCookie(w, r, "oauth_provider", providerKey, 10*time.Minute)
 	h.setTempCookie(w, r, "oauth_nonce", nonce, 10*time.Minute)
 
-	if familyCode := r.URL.Query().Get("family_code"); familyCode != "" {
-		h.setTempCookie(w, r, "oauth_family_code", familyCode, 10*time.Minute)
+	if link {
+		h.setTempCookie(w, r, "oauth_link", "1", 10*time.Minute)
+	} else {
+		h.clearTempCookie(w, r, "oauth_link")
+		if familyCode := r.URL.Query().Get("family_code"); familyCode != "" {
+			h.setTempCookie(w, r, "oauth_family_code", familyCode, 10*time.Minute)
+		}
 	}
 
 	redirectURL := h.oauthRedirectURL(r, providerKey)
 	config := *provider.Config
 	config.RedirectURL = redirectURL
+	if !h.resolveOAuthEndpoint(w, r, provider, &config) {
+		return
+	}
 
 	options := []oauth2.AuthCodeOption{oauth2.AccessTypeOnline}
 	for key, value := range provider.AuthParams {
 		options = append(options, oauth2.SetAuthURLParam(key, value))
 	}
-	if providerKey == "apple" {
+	if providerKey == "apple" || providerKey == "oidc" {
 		options = append(options, oauth2.SetAuthURLParam("nonce", nonce))
 	}
 
@@ -135,6 +157,9 @@ func (h *AuthHandler) OAuthCallback(w http.ResponseWriter, r *http.Request) {
 	redirectURL := h.oauthRedirectURL(r, providerKey)
 	config := *provider.Config
 	config.RedirectURL = redirectURL
+	if !h.resolveOAuthEndpoint(w, r, provider, &config) {
+		return
+	}
 
 	token, err := config.Exchange(ctx, code)
 	if err != nil {
@@ -152,14 +177,39 @@ func (h *AuthHandler) OAuthCallback(w http.ResponseWriter, r *http.Request) {
 	if cookie, err := r.Cookie("oauth_family_code"); err == nil {
 		familyCode = cookie.Value
 	}
+	linkCookie, linkErr := r.Cookie("oauth_link")
+	link := linkErr == nil && linkCookie.Value == "1"
 
 	// Clear temporary OAuth cookies
 	h.clearTempCookie(w, r, "oauth_state")
 	h.clearTempCookie(w, r, "oauth_provider")
 	h.clearTempCookie(w, r, "oauth_nonce")
 	h.clearTempCookie(w, r, "oauth_family_code")
+	h.clearTempCookie(w, r, "oauth_link")
+
+	identity := service.OAuthIdentity{
+		Provider:      providerKey,
+		Subject:       userInfo.Subject,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.EmailVerified,
+		Name:          userInfo.Name,
+		IsTeacher:     userInfo.IsTeacher,
+	}
+	if link {
+		h.finishOAuthLink(w, r, identity)
+		return
+	}
 
-	session, user, err := h.authService.OAuthLogin(providerKey, userInfo.Subject, userInfo.Email, userInfo.Name, familyCode)
+	session, user, err := h.authService.OAuthLogin(identity, familyCode)
+	var twoFactorErr *service.TwoFactorRequiredError
+	if errors.As(err, &twoFactorErr) {
+		h.startTwoFactorLogin(w, r, twoFactorErr.Challenge)
+		return
+	}
+	if errors.Is(err, service.ErrAccountExists) {
+		h.httpError(w, r, fmt.Sprintf("An account with %s already exists. Log in to it, then link %s from Sign-in Methods in your account settings.", userInfo.Email, provider.Label), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		h.httpError(w, r, err.Error(), http.StatusBadRequest)
 		return
@@ -173,6 +223,33 @@ func (h *AuthHandler) OAuthCallback(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/parent/dashboard", http.StatusSeeOther)
 }
 
+// finishOAuthLink links the provider account to the signed-in user and returns them
+// to their sign-in settings
+func (h *AuthHandler) finishOAuthLink(w http.ResponseWriter, r *http.Request, identity service.OAuthIdentity) {
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	user, err := h.authService.ValidateSession(cookie.Value)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	query := url.Values{}
+	switch err := h.authService.LinkIdentity(user.ID, identity); {
+	case err == nil:
+		query.Set("success", "linked")
+	case errors.Is(err, service.ErrIdentityInUse), errors.Is(err, service.ErrProviderAlreadyLinked):
+		query.Set("error", err.Error())
+	default:
+		log.Printf("Error linking %s account for user %d: %v", identity.Provider, user.ID, err)
+		query.Set("error", "failed to link account")
+	}
+	http.Redirect(w, r, "/account/sign-in?"+query.Encode(), http.StatusSeeOther)
+}
+
 func (h *AuthHandler) fetchOAuthUserInfo(ctx context.Context, providerKey string, provider OAuthProvider, token *oauth2.Token, r *http.Request) (oauthUserInfo, error) {
 	switch providerKey {
 	case "google":
@@ -181,6 +258,8 @@ func (h *AuthHandler) fetchOAuthUserInfo(ctx context.Context, providerKey string
 		return h.fetchFacebookUser(ctx, provider, token)
 	case "apple":
 		return h.fetchAppleUser(ctx, provider, token, r)
+	case "oidc":
+		return h.fetchOIDCUser(ctx, provider, token, r)
 	default:
 		return oauthUserInfo{}, errors.New("unsupported OAuth provider")
 	}
@@ -199,15 +278,16 @@ func (h *AuthHandler) fetchGoogleUser(ctx context.Context, provider OAuthProvide
 	}
 
 	var payload struct {
-		ID    string `json:"id"`
-		Email string `json:"email"`
-		Name  string `json:"name"`
+		ID            string `json:"id"`
+		Email         string `json:"email"`
+		VerifiedEmail bool   `json:"verified_email"`
+		Name          string `json:"name"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
 		return oauthUserInfo{}, fmt.Errorf("failed to parse Google user info")
 	}
 
-	return oauthUserInfo{Subject: payload.ID, Email: payload.Email, Name: payload.Name}, nil
+	return oauthUserInfo{Subject: payload.ID, Email: payload.Email, EmailVerified: payload.VerifiedEmail, Name: payload.Name}, nil
 }
 
 func (h *AuthHandler) fetchFacebookUser(ctx context.Context, provider OAuthProvider, token *oauth2.Token) (oauthUserInfo, error) {
@@ -250,7 +330,36 @@ func (h *AuthHandler) fetchAppleUser(ctx context.Context, provider OAuthProvider
 		return oauthUserInfo{}, err
 	}
 
-	return oauthUserInfo{Subject: claims.Subject, Email: claims.Email, Name: claims.Name}, nil
+	return oauthUserInfo{Subject: claims.Subject, Email: claims.Email, EmailVerified: claims.EmailVerified, Name: claims.Name}, nil
+}
+
+func (h *AuthHandler) fetchOIDCUser(ctx context.Context, provider OAuthProvider, token *oauth2.Token, r *http.Request) (oauthUserInfo, error) {
+	if provider.OIDC == nil {
+		return oauthUserInfo{}, errors.New("OpenID Connect provider not configured")
+	}
+
+	nonce := ""
+	if cookie, err := r.Cookie("oauth_nonce"); err == nil {
+		nonce = cookie.Value
+	}
+
+	return provider.OIDC.Identify(ctx, token, nonce)
+}
+
+// resolveOAuthEndpoint fills in the endpoint of an OpenID Connect provider from its
+// discovery document, rendering an error and returning false if the issuer is unreachable
+func (h *AuthHandler) resolveOAuthEndpoint(w http.ResponseWriter, r *http.Request, provider OAuthProvider, config *oauth2.Config) bool {
+	if provider.OIDC == nil {
+		return true
+	}
+	endpoint, err := provider.OIDC.Endpoint(r.Context())
+	if err != nil {
+		log.Printf("Error discovering OpenID Connect endpoints: %v", err)
+		h.httpError(w, r, "Single sign-on is unavailable right now, please try again later", http.StatusBadGateway)
+		return false
+	}
+	config.Endpoint = endpoint
+	return true
 }
 
 func (h *AuthHandler) oauthRedirectURL(r *http.Request, providerKey string) string {
@@ -321,9 +430,10 @@ type appleJWKKey struct {
 }
 
 type appleParsedClaims struct {
-	Subject string
-	Email   string
-	Name    string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
 }
 
 func parseAppleIDToken(ctx context.Context, idToken, clientID, nonce string) (appleParsedClaims, error) {
@@ -355,9 +465,10 @@ func parseAppleIDToken(ctx context.Context, idToken, clientID, nonce string) (ap
 	}
 
 	return appleParsedClaims{
-		Subject: claims.Subject,
-		Email:   claims.Email,
-		Name:    "",
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified == "true",
+		Name:          "",
 	}, nil
 }
 
@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	oidcWellKnownPath = "/.well-known/openid-configuration"
+
+	// oidcMetadataTTL is how long discovered endpoints are cached before being fetched again
+	oidcMetadataTTL = 24 * time.Hour
+
+	// oidcKeyRefreshInterval limits how often signing keys are refetched when a token
+	// is signed with a key we haven't seen, e.g. after the issuer rotates its keys
+	oidcKeyRefreshInterval = time.Minute
+)
+
+// oidcSigningMethods are the ID token algorithms accepted from the issuer
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// OIDCConfig configures a generic OpenID Connect provider such as Microsoft Entra ID,
+// Keycloak or Authentik
+type OIDCConfig struct {
+	DiscoveryURL  string // Issuer URL, optionally ending in /.well-known/openid-configuration
+	ClientID      string
+	EmailClaim    string   // Claim holding the user's email address
+	NameClaim     string   // Claim holding the user's display name
+	TeacherClaim  string   // Claim holding roles or groups; dots reach into nested claims
+	TeacherValues []string // Values of TeacherClaim that make the user a teacher
+}
+
+// OIDCProvider discovers an OpenID Connect issuer's endpoints and verifies its ID tokens.
+// Endpoints and signing keys are fetched on first use and cached, so the server still
+// starts when the issuer is unreachable.
+type OIDCProvider struct {
+	config OIDCConfig
+	client *http.Client
+
+	mu              sync.Mutex
+	metadata        *oidcMetadata
+	metadataFetched time.Time
+	keys            map[string]crypto.PublicKey
+	keysFetched     time.Time
+}
+
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcJWKSet struct {
+	Keys []oidcJWK `json:"keys"`
+}
+
+type oidcJWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewOIDCProvider creates an OpenID Connect provider. Nothing is fetched until it is used.
+func NewOIDCProvider(config OIDCConfig) *OIDCProvider {
+	if config.EmailClaim == "" {
+		config.EmailClaim = "email"
+	}
+	if config.NameClaim == "" {
+		config.NameClaim = "name"
+	}
+	return &OIDCProvider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Endpoint returns the issuer's authorization and token endpoints
+func (p *OIDCProvider) Endpoint(ctx context.Context) (oauth2.Endpoint, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return oauth2.Endpoint{}, err
+	}
+	return oauth2.Endpoint{
+		AuthURL:  metadata.AuthorizationEndpoint,
+		TokenURL: metadata.TokenEndpoint,
+	}, nil
+}
+
+// Identify verifies the ID token returned with token and maps its claims to a user.
+// Claims missing from the ID token are looked up at the userinfo endpoint, since
+// some issuers only put the subject in the ID token.
+func (p *OIDCProvider) Identify(ctx context.Context, token *oauth2.Token, nonce string) (oauthUserInfo, error) {
+	idToken, _ := token.Extra("id_token").(string)
+	if idToken == "" {
+		return oauthUserInfo{}, errors.New("missing OpenID Connect id_token")
+	}
+
+	claims, err := p.verifyIDToken(ctx, idToken, nonce)
+	if err != nil {
+		log.Printf("OpenID Connect token rejected: %v", err)
+		return oauthUserInfo{}, errors.New("invalid sign-in token")
+	}
+
+	if p.needsUserInfo(claims) {
+		userInfo, err := p.fetchUserInfo(ctx, token)
+		if err != nil {
+			log.Printf("Error fetching OpenID Connect user info: %v", err)
+			return oauthUserInfo{}, errors.New("failed to fetch user info")
+		}
+		// The userinfo response must be about the same user as the ID token
+		if sub, _ := userInfo["sub"].(string); sub != "" && sub == claims["sub"] {
+			for key, value := range userInfo {
+				if _, ok := claims[key]; !ok {
+					claims[key] = value
+				}
+			}
+		}
+	}
+
+	return p.mapClaims(claims)
+}
+
+// mapClaims picks the user's details out of verified claims
+func (p *OIDCProvider) mapClaims(claims jwt.MapClaims) (oauthUserInfo, error) {
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return oauthUserInfo{}, errors.New("missing subject")
+	}
+
+	email, _ := claimValue(claims, p.config.EmailClaim).(string)
+	if email == "" {
+		return oauthUserInfo{}, errors.New("email address not available")
+	}
+	// Accounts are matched by email, so an address the issuer hasn't verified can't be trusted
+	verified, ok := claims["email_verified"]
+	if ok && !claimIsTrue(verified) {
+		return oauthUserInfo{}, errors.New("email address not verified")
+	}
+
+	name, _ := claimValue(claims, p.config.NameClaim).(string)
+
+	info := oauthUserInfo{Subject: subject, Email: email, EmailVerified: ok, Name: name}
+	if p.config.TeacherClaim != "" {
+		for _, value := range claimStrings(claimValue(claims, p.config.TeacherClaim)) {
+			for _, teacherValue := range p.config.TeacherValues {
+				if value == teacherValue {
+					info.IsTeacher = true
+				}
+			}
+		}
+	}
+	return info, nil
+}
+
+// needsUserInfo reports whether the ID token is missing a claim we map
+func (p *OIDCProvider) needsUserInfo(claims jwt.MapClaims) bool {
+	for _, name := range []string{p.config.EmailClaim, p.config.NameClaim, p.config.TeacherClaim} {
+		if name != "" && claimValue(claims, name) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyIDToken checks the ID token's signature, issuer, audience, expiry and nonce
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, idToken, nonce string) (jwt.MapClaims, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	claims := jwt.MapClaims{}
+	if _, err := parser.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.signingKey(ctx, metadata, kid)
+	}); err != nil {
+		return nil, err
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); nonce == "" || tokenNonce != nonce {
+		return nil, errors.New("nonce mismatch")
+	}
+	return claims, nil
+}
+
+// discover fetches and caches the issuer's metadata
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil && time.Since(p.metadataFetched) < oidcMetadataTTL {
+		return p.metadata, nil
+	}
+
+	issuer := strings.TrimRight(strings.TrimSuffix(p.config.DiscoveryURL, oidcWellKnownPath), "/")
+	var metadata oidcMetadata
+	if err := p.getJSON(ctx, issuer+oidcWellKnownPath, "", &metadata); err != nil {
+		if p.metadata != nil {
+			// Keep using what we had rather than locking everyone out
+			log.Printf("Warning: failed to refresh OpenID Connect discovery, using cached endpoints: %v", err)
+			return p.metadata, nil
+		}
+		return nil, fmt.Errorf("OpenID Connect discovery failed: %w", err)
+	}
+
+	if strings.TrimRight(metadata.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("OpenID Connect issuer %q does not match discovery URL %q", metadata.Issuer, issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("OpenID Connect discovery is missing required endpoints")
+	}
+
+	p.metadata = &metadata
+	p.metadataFetched = time.Now()
+	return p.metadata, nil
+}
+
+// signingKey returns the issuer's public key with the given ID, refetching the key
+// set if it isn't known
+func (p *OIDCProvider) signingKey(ctx context.Context, metadata *oidcMetadata, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key := p.findKey(kid); key != nil {
+		return key, nil
+	}
+	if time.Since(p.keysFetched) < oidcKeyRefreshInterval {
+		return nil, errors.New("unknown signing key")
+	}
+
+	var set oidcJWKSet
+	if err := p.getJSON(ctx, metadata.JWKSURI, "", &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	p.keysFetched = time.Now()
+
+	p.keys = make(map[string]crypto.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.Printf("Skipping OpenID Connect signing key %q: %v", jwk.Kid, err)
+			continue
+		}
+		p.keys[jwk.Kid] = key
+	}
+
+	if key := p.findKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, errors.New("unknown signing key")
+}
+
+// findKey looks up a cached key. Tokens without a key ID are accepted when the
+// issuer only has one key.
+func (p *OIDCProvider) findKey(kid string) crypto.PublicKey {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return p.keys[kid]
+}
+
+// fetchUserInfo retrieves the user's claims from the userinfo endpoint
+func (p *OIDCProvider) fetchUserInfo(ctx context.Context, token *oauth2.Token) (map[string]interface{}, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.UserInfoEndpoint == "" {
+		return map[string]interface{}{}, nil
+	}
+
+	var claims map[string]interface{}
+	if err := p.getJSON(ctx, metadata.UserInfoEndpoint, token.AccessToken, &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// getJSON fetches a URL and decodes the JSON response, sending accessToken as a
+// bearer token if it is set
+func (p *OIDCProvider) getJSON(ctx context.Context, url, accessToken string, v interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// publicKey converts an RSA or elliptic curve JSON Web Key
+func (k oidcJWK) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeJWKInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+// claimValue looks up a claim by name, following dots into nested objects
+// (e.g. "realm_access.roles" for Keycloak realm roles)
+func claimValue(claims map[string]interface{}, name string) interface{} {
+	if value, ok := claims[name]; ok {
+		return value
+	}
+	current := claims
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		value, ok := current[part]
+		if !ok {
+			return nil
+		}
+		if i == len(parts)-1 {
+			return value
+		}
+		if current, ok = value.(map[string]interface{}); !ok {
+			return nil
+		}
+	}
+	return nil
+}
+
+// claimStrings flattens a string, number, boolean or list claim to strings
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			values = append(values, claimStrings(item)...)
+		}
+		return values
+	case string:
+		return []string{v}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// claimIsTrue reports whether a boolean claim is true; some issuers send "true" as a string
+func claimIsTrue(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	default:
+		return false
+	}
+}
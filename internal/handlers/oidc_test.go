@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"spellingclash/internal/database"
+	"spellingclash/internal/repository"
+	"spellingclash/internal/security"
+	"spellingclash/internal/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const oidcTestClientID = "spellingclash"
+
+// mockIssuer is a minimal OpenID Connect issuer that signs whatever claims a test sets
+type mockIssuer struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	kid      string
+	claims   jwt.MapClaims
+	userInfo map[string]interface{}
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	issuer := &mockIssuer{key: key, kid: "key-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"userinfo_endpoint":      issuer.server.URL + "/userinfo",
+			"jwks_uri":               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kid": "key-1",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "good-code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, issuer.claims)
+		token.Header["kid"] = issuer.kid
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			t.Errorf("failed to sign token: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(issuer.userInfo)
+	})
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// newOIDCTestHandler returns the OAuth routes with the mock issuer as the oidc provider
+func newOIDCTestHandler(t *testing.T, issuer *mockIssuer) (*http.ServeMux, *database.DB) {
+	t.Helper()
+
+	db, err := database.Initialize(filepath.Join(t.TempDir(), "oidc.db"))
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.RunMigrations("../../migrations"); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO users (id, email, password_hash, name) VALUES (1, 'existing@school.example', 'x', 'Existing')"); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+
+	providers := map[string]OAuthProvider{
+		"oidc": {
+			Name:  "oidc",
+			Label: "School Account",
+			Config: &oauth2.Config{
+				ClientID:     oidcTestClientID,
+				ClientSecret: "secret",
+				Scopes:       []string{"openid", "email", "profile"},
+			},
+			OIDC: NewOIDCProvider(OIDCConfig{
+				DiscoveryURL:  issuer.server.URL + "/.well-known/openid-configuration",
+				ClientID:      oidcTestClientID,
+				TeacherClaim:  "realm_access.roles",
+				TeacherValues: []string{"staff", "teacher"},
+			}),
+		},
+	}
+
+	authService := service.NewAuthService(repository.NewUserRepository(db), repository.NewFamilyRepository(db), repository.NewIdentityRepository(db), repository.NewTwoFactorRepository(db), repository.NewAPITokenRepository(db), repository.NewSettingsRepository(db), security.NewMemoryLimiter(), time.Hour, "secret")
+	templates := template.Must(template.New("login.tmpl").Parse("{{.Error}}"))
+	auth := NewAuthHandler(authService, nil, templates, providers, "https://app.example", repository.NewSettingsRepository(db), repository.NewInvitationRepository(db))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /auth/{provider}/start", auth.StartOAuth)
+	mux.HandleFunc("GET /auth/{provider}/callback", auth.OAuthCallback)
+	return mux, db
+}
+
+// oidcSignIn starts a sign-in, has the issuer return claims (with the nonce filled
+// in when it isn't set) and returns the response to the callback
+func oidcSignIn(t *testing.T, mux *http.ServeMux, issuer *mockIssuer, claims jwt.MapClaims) *httptest.ResponseRecorder {
+	t.Helper()
+
+	start := httptest.NewRecorder()
+	mux.ServeHTTP(start, httptest.NewRequest(http.MethodGet, "/auth/oidc/start", nil))
+	if start.Code != http.StatusFound {
+		t.Fatalf("start returned %d: %s", start.Code, start.Body.String())
+	}
+	location, err := url.Parse(start.Header().Get("Location"))
+	if err != nil || location.Path != "/authorize" {
+		t.Fatalf("start redirected to %q, want the issuer's authorization endpoint", start.Header().Get("Location"))
+	}
+	params := location.Query()
+	if params.Get("client_id") != oidcTestClientID || params.Get("redirect_uri") != "https://app.example/auth/oidc/callback" || params.Get("nonce") == "" {
+		t.Fatalf("authorization request %v is missing parameters", params)
+	}
+
+	if _, ok := claims["nonce"]; !ok {
+		claims["nonce"] = params.Get("nonce")
+	}
+	issuer.claims = claims
+
+	callback := httptest.NewRequest(http.MethodGet, "/auth/oidc/callback?"+url.Values{
+		"code":  {"good-code"},
+		"state": {params.Get("state")},
+	}.Encode(), nil)
+	for _, cookie := range start.Result().Cookies() {
+		callback.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, callback)
+	return rec
+}
+
+func oidcTestClaims(issuer *mockIssuer, subject, email string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":   issuer.server.URL,
+		"aud":   oidcTestClientID,
+		"sub":   subject,
+		"email": email,
+		"name":  "Ms Smith",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(5 * time.Minute).Unix(),
+	}
+}
+
+func hasSessionCookie(rec *httptest.ResponseRecorder) bool {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == SessionCookieName && cookie.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOIDCSignIn(t *testing.T) {
+	issuer := newMockIssuer(t)
+	mux, db := newOIDCTestHandler(t, issuer)
+
+	// A new user in the teacher role gets a teacher account
+	claims := oidcTestClaims(issuer, "staff-1", "smith@school.example")
+	claims["realm_access"] = map[string]interface{}{"roles": []string{"offline_access", "staff"}}
+	rec := oidcSignIn(t, mux, issuer, claims)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/teacher/dashboard" || !hasSessionCookie(rec) {
+		t.Fatalf("teacher sign-in returned %d to %q: %s", rec.Code, rec.Header().Get("Location"), rec.Body.String())
+	}
+	var name string
+	var isTeacher bool
+	if err := db.QueryRow("SELECT u.name, u.is_teacher FROM users u JOIN user_identities i ON i.user_id = u.id WHERE u.email = 'smith@school.example' AND i.provider = 'oidc' AND i.subject = 'staff-1'").Scan(&name, &isTeacher); err != nil {
+		t.Fatalf("failed to load the new user: %v", err)
+	}
+	if name != "Ms Smith" || !isTeacher {
+		t.Errorf("new user = %q, teacher %t, want Ms Smith as a teacher", name, isTeacher)
+	}
+
+	// An existing parent isn't linked by an email the issuer hasn't verified
+	claims = oidcTestClaims(issuer, "parent-1", "existing@school.example")
+	rec = oidcSignIn(t, mux, issuer, claims)
+	if rec.Code == http.StatusSeeOther || hasSessionCookie(rec) || !strings.Contains(rec.Body.String(), "already exists") {
+		t.Fatalf("unverified sign-in to an existing account returned %d to %q, want it refused", rec.Code, rec.Header().Get("Location"))
+	}
+
+	// but is with a verified one, with the email taken from userinfo because it
+	// isn't in the ID token
+	claims = oidcTestClaims(issuer, "parent-1", "")
+	delete(claims, "email")
+	issuer.userInfo = map[string]interface{}{"sub": "parent-1", "email": "existing@school.example", "email_verified": true}
+	rec = oidcSignIn(t, mux, issuer, claims)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/parent/dashboard" {
+		t.Fatalf("parent sign-in returned %d to %q: %s", rec.Code, rec.Header().Get("Location"), rec.Body.String())
+	}
+	var subject string
+	if err := db.QueryRow("SELECT i.subject, u.is_teacher FROM users u JOIN user_identities i ON i.user_id = u.id WHERE u.id = 1 AND i.provider = 'oidc'").Scan(&subject, &isTeacher); err != nil {
+		t.Fatalf("failed to load the existing user: %v", err)
+	}
+	if subject != "parent-1" || isTeacher {
+		t.Errorf("existing user subject = %q, teacher %t, want linked as a parent", subject, isTeacher)
+	}
+}
+
+func TestOIDCRejectsBadTokens(t *testing.T) {
+	issuer := newMockIssuer(t)
+	mux, _ := newOIDCTestHandler(t, issuer)
+
+	for name, modify := range map[string]func(jwt.MapClaims){
+		"wrong nonce":         func(c jwt.MapClaims) { c["nonce"] = "replayed" },
+		"wrong audience":      func(c jwt.MapClaims) { c["aud"] = "another-app" },
+		"wrong issuer":        func(c jwt.MapClaims) { c["iss"] = "https://evil.example" },
+		"expired":             func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+		"unverified email":    func(c jwt.MapClaims) { c["email_verified"] = false },
+		"missing email":       func(c jwt.MapClaims) { delete(c, "email") },
+		"unknown signing key": func(c jwt.MapClaims) { issuer.kid = "key-2" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			issuer.kid = "key-1"
+			issuer.userInfo = map[string]interface{}{"sub": "someone"}
+			claims := oidcTestClaims(issuer, "someone", "someone@school.example")
+			modify(claims)
+
+			rec := oidcSignIn(t, mux, issuer, claims)
+			if rec.Code == http.StatusSeeOther || hasSessionCookie(rec) {
+				t.Errorf("sign-in succeeded with %d to %q, want it refused", rec.Code, rec.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestOIDCDiscoveryIssuerMismatch(t *testing.T) {
+	issuer := newMockIssuer(t)
+	provider := NewOIDCProvider(OIDCConfig{DiscoveryURL: issuer.server.URL + "/other", ClientID: oidcTestClientID})
+	if _, err := provider.Endpoint(t.Context()); err == nil || !strings.Contains(err.Error(), "discovery") {
+		t.Errorf("Endpoint() error = %v, want a discovery failure", err)
+	}
+}
+
+func TestClaimValue(t *testing.T) {
+	claims := map[string]interface{}{
+		"email":        "a@example.com",
+		"realm_access": map[string]interface{}{"roles": []interface{}{"teacher"}},
+		"dotted.name":  "literal",
+	}
+	if got := claimValue(claims, "email"); got != "a@example.com" {
+		t.Errorf("claimValue(email) = %v", got)
+	}
+	if got := claimStrings(claimValue(claims, "realm_access.roles")); len(got) != 1 || got[0] != "teacher" {
+		t.Errorf("claimValue(realm_access.roles) = %v, want [teacher]", got)
+	}
+	if got := claimValue(claims, "dotted.name"); got != "literal" {
+		t.Errorf("claimValue(dotted.name) = %v, want the literal claim", got)
+	}
+	if got := claimValue(claims, "email.domain"); got != nil {
+		t.Errorf("claimValue(email.domain) = %v, want nil", got)
+	}
+}
@@ -11,19 +11,23 @@ import (
 
 // ParentHandler handles parent-related HTTP requests
 type ParentHandler struct {
-	familyService *service.FamilyService
-	listService   *service.ListService
-	middleware    *Middleware
-	templates     *template.Template
+	familyService   *service.FamilyService
+	listService     *service.ListService
+	practiceService *service.PracticeService
+	streakService   *service.StreakService
+	middleware      *Middleware
+	templates       *template.Template
 }
 
 // NewParentHandler creates a new parent handler
-func NewParentHandler(familyService *service.FamilyService, listService *service.ListService, middleware *Middleware, templates *template.Template) *ParentHandler {
+func NewParentHandler(familyService *service.FamilyService, listService *service.ListService, practiceService *service.PracticeService, streakService *service.StreakService, middleware *Middleware, templates *template.Template) *ParentHandler {
 	return &ParentHandler{
-		familyService: familyService,
-		listService:   listService,
-		middleware:    middleware,
-		templates:     templates,
+		familyService:   familyService,
+		listService:     listService,
+		practiceService: practiceService,
+		streakService:   streakService,
+		middleware:      middleware,
+		templates:       templates,
 	}
 }
 
@@ -67,7 +71,7 @@ func (h *ParentHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
 		Title:         "Dashboard - WordClash",
 		User:          user,
 		Families:      families,
-		Kids:          allKids,
+		Kids:          h.kidsWithStats(allKids),
 		FamilyMembers: familyMembers,
 		ParentUsers:   parentUsers,
 		CSRFToken:     csrfToken,
@@ -92,6 +96,21 @@ func (h *ParentHandler) ShowFamily(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	streaks := make([]FamilyStreakSettings, 0, len(families))
+	for _, family := range families {
+		settings, err := h.streakService.GetSettings(family.FamilyCode)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting streak settings", err)
+			return
+		}
+		freezes, err := h.streakService.GetFreezes(family.FamilyCode)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting streak days off", err)
+			return
+		}
+		streaks = append(streaks, FamilyStreakSettings{FamilyCode: family.FamilyCode, Settings: settings, Freezes: freezes})
+	}
+
 	// Get CSRF token
 	csrfToken := h.getCSRFToken(r)
 
@@ -99,7 +118,11 @@ func (h *ParentHandler) ShowFamily(w http.ResponseWriter, r *http.Request) {
 		Title:     "Manage Families - WordClash",
 		User:      user,
 		Families:  families,
+		Streaks:   streaks,
+		Timezones: commonTimezones,
 		CSRFToken: csrfToken,
+		Error:     r.URL.Query().Get("error"),
+		Success:   r.URL.Query().Get("success"),
 	}
 
 	if err := h.templates.ExecuteTemplate(w, "family.tmpl", data); err != nil {
@@ -12,17 +12,19 @@ import (
 
 // PracticeHandler handles practice game HTTP requests
 type PracticeHandler struct {
-	practiceService *service.PracticeService
-	listService     *service.ListService
-	templates       *template.Template
+	practiceService    *service.PracticeService
+	listService        *service.ListService
+	achievementService *service.AchievementService
+	templates          *template.Template
 }
 
 // NewPracticeHandler creates a new practice handler
-func NewPracticeHandler(practiceService *service.PracticeService, listService *service.ListService, templates *template.Template) *PracticeHandler {
+func NewPracticeHandler(practiceService *service.PracticeService, listService *service.ListService, achievementService *service.AchievementService, templates *template.Template) *PracticeHandler {
 	return &PracticeHandler{
-		practiceService: practiceService,
-		listService:     listService,
-		templates:       templates,
+		practiceService:    practiceService,
+		listService:        listService,
+		achievementService: achievementService,
+		templates:          templates,
 	}
 }
 
@@ -267,12 +269,13 @@ func (h *PracticeHandler) ShowResults(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := PracticeResultsViewData{
-		Title:       "Results - SpellingClash",
-		Kid:         kid,
-		Session:     session,
-		Attempts:    attempts,
-		Accuracy:    accuracy,
-		TotalPoints: totalPoints,
+		Title:           "Results - SpellingClash",
+		Kid:             kid,
+		Session:         session,
+		Attempts:        attempts,
+		Accuracy:        accuracy,
+		TotalPoints:     totalPoints,
+		NewAchievements: takeNewAchievements(h.achievementService, kid.ID),
 	}
 
 	// Clean up practice state from database
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"spellingclash/internal/models"
+	"spellingclash/internal/security"
+	"spellingclash/internal/service"
+	"strconv"
+	"strings"
+)
+
+// SessionsHandler handles the page where users see and sign out their own and
+// their children's sessions
+type SessionsHandler struct {
+	authService   *service.AuthService
+	familyService *service.FamilyService
+	middleware    *Middleware
+	templates     *template.Template
+}
+
+// NewSessionsHandler creates a new sessions handler
+func NewSessionsHandler(authService *service.AuthService, familyService *service.FamilyService, middleware *Middleware, templates *template.Template) *SessionsHandler {
+	return &SessionsHandler{
+		authService:   authService,
+		familyService: familyService,
+		middleware:    middleware,
+		templates:     templates,
+	}
+}
+
+// ShowSessions lists the user's active sessions and those of their children
+func (h *SessionsHandler) ShowSessions(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	data := SessionsViewData{
+		Title: "Sessions - WordClash",
+		User:  user,
+		Error: r.URL.Query().Get("error"),
+	}
+	switch r.URL.Query().Get("success") {
+	case "revoked":
+		data.Success = "Session signed out."
+	case "others":
+		data.Success = "Signed out everywhere else."
+	case "kid":
+		data.Success = "Your child has been signed out."
+	}
+
+	var currentHandle string
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		currentHandle = models.SessionHandle(cookie.Value)
+		data.CSRFToken, _ = h.middleware.GetCSRFToken(cookie.Value)
+	}
+
+	sessions, err := h.authService.GetSessions(user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting sessions", err)
+		return
+	}
+	for _, session := range sessions {
+		data.Sessions = append(data.Sessions, SessionView{
+			Handle:     session.Handle(),
+			Device:     describeUserAgent(session.UserAgent),
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.Handle() == currentHandle,
+			RevokeURL:  "/account/sessions/" + session.Handle() + "/revoke",
+		})
+	}
+
+	kids, err := h.familyService.GetAllUserKids(user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting kids", err)
+		return
+	}
+	for _, kid := range kids {
+		kidSessions, err := h.familyService.GetKidSessions(kid.ID, user.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting kid sessions", err)
+			return
+		}
+		if len(kidSessions) == 0 {
+			continue
+		}
+		view := KidSessionsView{Kid: kid}
+		for _, session := range kidSessions {
+			view.Sessions = append(view.Sessions, SessionView{
+				Handle:     session.Handle(),
+				Device:     describeUserAgent(session.UserAgent),
+				IPAddress:  session.IPAddress,
+				CreatedAt:  session.CreatedAt,
+				LastSeenAt: session.LastSeenAt,
+				RevokeURL:  fmt.Sprintf("/account/sessions/kids/%d/%s/revoke", kid.ID, session.Handle()),
+			})
+		}
+		data.Kids = append(data.Kids, view)
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "sessions.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering sessions template", err)
+	}
+}
+
+// RevokeSession signs out one of the user's sessions. Signing out the current one
+// logs the user out.
+func (h *SessionsHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	handle := r.PathValue("handle")
+	err := h.authService.RevokeSession(user.ID, handle)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrSessionNotFound):
+		http.Redirect(w, r, "/account/sessions?"+url.Values{"error": {"That session has already ended."}}.Encode(), http.StatusSeeOther)
+		return
+	default:
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error revoking session", err)
+		return
+	}
+
+	log.Printf("User %d signed out session %s", user.ID, handle)
+	if cookie, err := r.Cookie(SessionCookieName); err == nil && models.SessionHandle(cookie.Value) == handle {
+		http.SetCookie(w, security.CreateDeleteCookie(r, SessionCookieName))
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/account/sessions?success=revoked", http.StatusSeeOther)
+}
+
+// RevokeOtherSessions signs out every session except the one making the request
+func (h *SessionsHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	revoked, err := h.authService.RevokeOtherSessions(user.ID, cookie.Value)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error revoking sessions", err)
+		return
+	}
+
+	log.Printf("User %d signed out %d other sessions", user.ID, revoked)
+	http.Redirect(w, r, "/account/sessions?success=others", http.StatusSeeOther)
+}
+
+// RevokeKidSession signs out one of a child's sessions
+func (h *SessionsHandler) RevokeKidSession(w http.ResponseWriter, r *http.Request) {
+	h.revokeKidSessions(w, r, r.PathValue("handle"))
+}
+
+// RevokeKidSessions signs a child out on every device
+func (h *SessionsHandler) RevokeKidSessions(w http.ResponseWriter, r *http.Request) {
+	h.revokeKidSessions(w, r, "")
+}
+
+// revokeKidSessions signs out the child's session with handle, or all of them if
+// handle is empty
+func (h *SessionsHandler) revokeKidSessions(w http.ResponseWriter, r *http.Request, handle string) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	kidID, err := strconv.ParseInt(r.PathValue("kidId"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid kid ID", http.StatusBadRequest)
+		return
+	}
+
+	if handle == "" {
+		err = h.familyService.RevokeKidSessions(kidID, user.ID)
+	} else {
+		err = h.familyService.RevokeKidSession(kidID, user.ID, handle)
+	}
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrKidNotFound), errors.Is(err, service.ErrNotFamilyMember):
+		http.Error(w, ErrUnauthorized, http.StatusForbidden)
+		return
+	case errors.Is(err, service.ErrSessionNotFound):
+		http.Redirect(w, r, "/account/sessions?"+url.Values{"error": {"That session has already ended."}}.Encode(), http.StatusSeeOther)
+		return
+	default:
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error revoking kid sessions", err)
+		return
+	}
+
+	log.Printf("User %d signed out sessions for kid %d", user.ID, kidID)
+	http.Redirect(w, r, "/account/sessions?success=kid", http.StatusSeeOther)
+}
+
+// describeUserAgent turns a user agent string into a short description such as
+// "Firefox on Windows"
+func describeUserAgent(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	for _, b := range []struct{ token, name string }{
+		// Order matters: Edge and Opera also claim to be Chrome, and Chrome claims to be Safari
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"CriOS/", "Chrome"},
+		{"Safari/", "Safari"},
+	} {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	system := ""
+	for _, s := range []struct{ token, name string }{
+		// iPadOS and Android user agents also mention macOS and Linux
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Android", "Android"},
+		{"CrOS", "ChromeOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+
+	if system == "" {
+		return browser
+	}
+	return browser + " on " + system
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"spellingclash/internal/models"
+	"spellingclash/internal/service"
+	"spellingclash/internal/validation"
+)
+
+// SignInMethodsHandler handles the page where users link sign-in providers and set a password
+type SignInMethodsHandler struct {
+	authService    *service.AuthService
+	middleware     *Middleware
+	templates      *template.Template
+	oauthProviders map[string]OAuthProvider
+}
+
+// NewSignInMethodsHandler creates a new sign-in methods handler
+func NewSignInMethodsHandler(authService *service.AuthService, middleware *Middleware, templates *template.Template, oauthProviders map[string]OAuthProvider) *SignInMethodsHandler {
+	return &SignInMethodsHandler{
+		authService:    authService,
+		middleware:     middleware,
+		templates:      templates,
+		oauthProviders: oauthProviders,
+	}
+}
+
+// ShowSignInMethods displays the user's password and linked providers
+func (h *SignInMethodsHandler) ShowSignInMethods(w http.ResponseWriter, r *http.Request) {
+	data := SignInMethodsViewData{Error: r.URL.Query().Get("error")}
+	switch r.URL.Query().Get("success") {
+	case "linked":
+		data.Success = "Account linked. You can now sign in with it."
+	case "unlinked":
+		data.Success = "Account unlinked."
+	case "password":
+		data.Success = "Password saved."
+	}
+	h.renderSignInMethods(w, r, data)
+}
+
+// SetPassword sets or changes the user's password
+func (h *SignInMethodsHandler) SetPassword(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return
+	}
+
+	newPassword := r.FormValue("new_password")
+	if newPassword != r.FormValue("confirm_password") {
+		h.renderSignInMethods(w, r, SignInMethodsViewData{Error: "Passwords do not match"})
+		return
+	}
+
+	var sessionID string
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		sessionID = cookie.Value
+	}
+	err := h.authService.SetPassword(user.ID, r.FormValue("current_password"), newPassword, sessionID)
+	var validationErr validation.ValidationError
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrWrongPassword), errors.As(err, &validationErr):
+		h.renderSignInMethods(w, r, SignInMethodsViewData{Error: err.Error()})
+		return
+	default:
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error setting password", err)
+		return
+	}
+
+	log.Printf("User %d set a new password", user.ID)
+	http.Redirect(w, r, "/account/sign-in?success=password", http.StatusSeeOther)
+}
+
+// UnlinkProvider removes a linked sign-in provider from the user's account
+func (h *SignInMethodsHandler) UnlinkProvider(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	provider := r.PathValue("provider")
+	err := h.authService.UnlinkIdentity(user.ID, provider)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrIdentityNotFound), errors.Is(err, service.ErrLastSignInMethod):
+		http.Redirect(w, r, "/account/sign-in?"+url.Values{"error": {err.Error()}}.Encode(), http.StatusSeeOther)
+		return
+	default:
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error unlinking sign-in provider", err)
+		return
+	}
+
+	log.Printf("User %d unlinked their %s account", user.ID, provider)
+	http.Redirect(w, r, "/account/sign-in?success=unlinked", http.StatusSeeOther)
+}
+
+func (h *SignInMethodsHandler) renderSignInMethods(w http.ResponseWriter, r *http.Request, data SignInMethodsViewData) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	identities, err := h.authService.GetIdentities(user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting linked identities", err)
+		return
+	}
+
+	data.Title = "Sign-in Methods - WordClash"
+	data.User = user
+	data.HasPassword = user.HasPassword()
+	data.Providers = h.providerViews(identities)
+	data.CanUnlink = data.HasPassword || len(identities) > 1
+	tf, err := h.authService.GetTwoFactor(user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting two-factor settings", err)
+		return
+	}
+	data.TwoFactor = tf.IsEnabled()
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		data.CSRFToken, _ = h.middleware.GetCSRFToken(cookie.Value)
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "signin_methods.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering sign-in methods template", err)
+	}
+}
+
+// providerViews lists the configured providers, plus any linked ones that have since
+// been switched off so they can still be unlinked
+func (h *SignInMethodsHandler) providerViews(identities []models.UserIdentity) []SignInProviderView {
+	linked := make(map[string]*models.UserIdentity)
+	for i := range identities {
+		linked[identities[i].Provider] = &identities[i]
+	}
+
+	var views []SignInProviderView
+	for key, provider := range h.oauthProviders {
+		if provider.Config == nil || provider.Config.ClientID == "" || provider.Config.ClientSecret == "" {
+			continue
+		}
+		views = append(views, SignInProviderView{Name: key, Label: provider.Label, CSSClass: "btn-" + key, Identity: linked[key]})
+		delete(linked, key)
+	}
+	for key, identity := range linked {
+		views = append(views, SignInProviderView{Name: key, Label: key, Identity: identity})
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].Label < views[j].Label
+	})
+	return views
+}
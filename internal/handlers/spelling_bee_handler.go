@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"spellingclash/internal/models"
+	"spellingclash/internal/service"
+	"time"
+)
+
+// SpellingBeeHandler handles the page children follow a classroom spelling bee
+// on, and type their answers into when the teacher has them answer on devices
+type SpellingBeeHandler struct {
+	spellingBeeService *service.SpellingBeeService
+	templates          *template.Template
+}
+
+// NewSpellingBeeHandler creates a new spelling bee handler
+func NewSpellingBeeHandler(spellingBeeService *service.SpellingBeeService, templates *template.Template) *SpellingBeeHandler {
+	return &SpellingBeeHandler{
+		spellingBeeService: spellingBeeService,
+		templates:          templates,
+	}
+}
+
+// ShowBee shows the child whose turn it is and how everyone is doing, with the
+// word and an answer box when it's the child's turn to type
+func (h *SpellingBeeHandler) ShowBee(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Redirect(w, r, "/child/select", http.StatusSeeOther)
+		return
+	}
+	beeID, ok := spellingBeeID(w, r)
+	if !ok {
+		return
+	}
+
+	state, err := h.spellingBeeService.GetKidState(kid.ID, beeID)
+	if errors.Is(err, service.ErrBeeNotFound) {
+		http.Redirect(w, r, "/child/dashboard", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting spelling bee", err)
+		return
+	}
+
+	data := KidSpellingBeeViewData{
+		Title: "Spelling Bee - WordClash",
+		Kid:   kid,
+		State: state,
+	}
+	// Classmates are only counted, not named, as they may be from other families
+	for i := range state.Participants {
+		if state.Participants[i].KidID == kid.ID {
+			data.You = &state.Participants[i]
+		}
+		if !state.Participants[i].IsOut() {
+			data.StillIn++
+		}
+	}
+	if state.Bee.IsRunning() && state.Bee.AnswerMode == models.BeeKidsType && state.Turn != nil && state.Turn.IsOpen() {
+		data.YourTurn = state.Turn.KidID == kid.ID
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "spelling_bee.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering spelling bee template", err)
+	}
+}
+
+// Answer records the spelling the child typed for their word
+func (h *SpellingBeeHandler) Answer(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+	beeID, ok := spellingBeeID(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return
+	}
+
+	// A late or repeated answer just shows the child where the bee is now
+	err := h.spellingBeeService.Answer(kid.ID, beeID, r.FormValue("answer"), time.Now())
+	if err != nil && !errors.Is(err, service.ErrBeeNoTurn) && !errors.Is(err, service.ErrBeeNotYourTurn) && !errors.Is(err, service.ErrBeeFinished) {
+		if errors.Is(err, service.ErrBeeNotFound) {
+			http.Redirect(w, r, "/child/dashboard", http.StatusSeeOther)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to save answer", "Error saving spelling bee answer", err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/child/bees/%d", beeID), http.StatusSeeOther)
+}
+
+// WordAudio plays the word it's the child's turn to type
+func (h *SpellingBeeHandler) WordAudio(w http.ResponseWriter, r *http.Request) {
+	h.serveTurnAudio(w, r, false)
+}
+
+// DefinitionAudio plays the definition of the word it's the child's turn to type
+func (h *SpellingBeeHandler) DefinitionAudio(w http.ResponseWriter, r *http.Request) {
+	h.serveTurnAudio(w, r, true)
+}
+
+// serveTurnAudio serves the audio for the child's open turn
+func (h *SpellingBeeHandler) serveTurnAudio(w http.ResponseWriter, r *http.Request, definition bool) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+	beeID, ok := spellingBeeID(w, r)
+	if !ok {
+		return
+	}
+
+	path, err := h.spellingBeeService.TurnAudio(kid.ID, beeID, definition)
+	if errors.Is(err, service.ErrBeeNotFound) || errors.Is(err, service.ErrBeeNotYourTurn) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting spelling bee audio", err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeFile(w, r, path)
+}
+
+// Poll reloads the child's page once the bee has moved on
+func (h *SpellingBeeHandler) Poll(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+	beeID, ok := spellingBeeID(w, r)
+	if !ok {
+		return
+	}
+
+	bee, err := h.spellingBeeService.GetKidBee(kid.ID, beeID)
+	if errors.Is(err, service.ErrBeeNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting spelling bee", err)
+		return
+	}
+	refreshIfBeeChanged(w, r, bee)
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"spellingclash/internal/service"
+	"strconv"
+	"time"
+)
+
+// SpellingTestHandler handles the locked-down test mode children use to sit
+// scheduled spelling tests
+type SpellingTestHandler struct {
+	spellingTestService *service.SpellingTestService
+	templates           *template.Template
+}
+
+// NewSpellingTestHandler creates a new spelling test handler
+func NewSpellingTestHandler(spellingTestService *service.SpellingTestService, templates *template.Template) *SpellingTestHandler {
+	return &SpellingTestHandler{
+		spellingTestService: spellingTestService,
+		templates:           templates,
+	}
+}
+
+// StartTest starts the child's attempt at an open test
+func (h *SpellingTestHandler) StartTest(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	testID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid test ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.spellingTestService.StartTest(kid.ID, testID, time.Now()); err != nil {
+		if !errors.Is(err, service.ErrTestNotFound) && !errors.Is(err, service.ErrTestNotOpen) {
+			log.Printf("Error starting spelling test %d for kid %d: %v", testID, kid.ID, err)
+		}
+		http.Redirect(w, r, "/child/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/child/tests/%d", testID), http.StatusSeeOther)
+}
+
+// ShowTest dictates the next word of the child's test, or confirms they have finished
+func (h *SpellingTestHandler) ShowTest(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Redirect(w, r, "/child/select", http.StatusSeeOther)
+		return
+	}
+
+	testID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid test ID", http.StatusBadRequest)
+		return
+	}
+
+	data := SpellingTestViewData{
+		Title: "Spelling Test - WordClash",
+		Kid:   kid,
+	}
+
+	progress, err := h.spellingTestService.GetTestProgress(kid.ID, testID, time.Now())
+	switch {
+	case errors.Is(err, service.ErrTestNotOpen):
+		data.Closed = true
+	case errors.Is(err, service.ErrTestNotFound), errors.Is(err, service.ErrTestNotStarted):
+		http.Redirect(w, r, "/child/dashboard", http.StatusSeeOther)
+		return
+	case err != nil:
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting spelling test progress", err)
+		return
+	default:
+		data.Progress = progress
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "spelling_test.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering spelling test template", err)
+	}
+}
+
+// WordAudio plays the word the child is on
+func (h *SpellingTestHandler) WordAudio(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	testID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid test ID", http.StatusBadRequest)
+		return
+	}
+	number, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil {
+		http.Error(w, "Invalid word number", http.StatusBadRequest)
+		return
+	}
+
+	path, err := h.spellingTestService.WordAudio(kid.ID, testID, number, time.Now())
+	if errors.Is(err, service.ErrTestNotFound) || errors.Is(err, service.ErrTestNotStarted) || errors.Is(err, service.ErrTestNotOpen) {
+		http.Error(w, "Word not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting spelling test audio", err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeFile(w, r, path)
+}
+
+// SubmitAnswer records the child's answer and moves on to the next word.
+// Nothing tells the child whether they were right.
+func (h *SpellingTestHandler) SubmitAnswer(w http.ResponseWriter, r *http.Request) {
+	kid := GetKidFromContext(r.Context())
+	if kid == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	testID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid test ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return
+	}
+	wordID, err := strconv.ParseInt(r.FormValue("word_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid word ID", http.StatusBadRequest)
+		return
+	}
+
+	// A stale or repeated submission just shows the child where they are now
+	_, err = h.spellingTestService.SubmitAnswer(kid.ID, testID, wordID, r.FormValue("answer"), time.Now())
+	if err != nil && !errors.Is(err, service.ErrTestWordAnswered) && !errors.Is(err, service.ErrTestNotOpen) {
+		if errors.Is(err, service.ErrTestNotFound) || errors.Is(err, service.ErrTestNotStarted) {
+			http.Redirect(w, r, "/child/dashboard", http.StatusSeeOther)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to save answer", "Error saving spelling test answer", err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/child/tests/%d", testID), http.StatusSeeOther)
+}
@@ -28,6 +28,7 @@ var startupStatus = &StartupStatus{
 		{Name: "Database connection", Completed: false},
 		{Name: "Running migrations", Completed: false},
 		{Name: "Loading templates", Completed: false},
+		{Name: "Loading dictionary", Completed: false},
 		{Name: "Initializing services", Completed: false},
 		{Name: "Seeding default lists", Completed: false},
 		{Name: "Generating audio files", Completed: false},
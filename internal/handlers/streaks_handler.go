@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"spellingclash/internal/models"
+	"spellingclash/internal/service"
+	"strconv"
+	"time"
+)
+
+// commonTimezones are suggested in the family streak settings. Any IANA zone name
+// is accepted.
+var commonTimezones = []string{
+	"Europe/London",
+	"Europe/Dublin",
+	"Europe/Paris",
+	"Europe/Berlin",
+	"America/New_York",
+	"America/Chicago",
+	"America/Denver",
+	"America/Los_Angeles",
+	"America/Toronto",
+	"Asia/Kolkata",
+	"Asia/Singapore",
+	"Asia/Tokyo",
+	"Australia/Sydney",
+	"Australia/Perth",
+	"Pacific/Auckland",
+	"UTC",
+}
+
+// kidsWithStats adds practice totals and streaks to kids for the parent dashboard.
+// Stats that can't be loaded are left at zero rather than failing the page.
+func (h *ParentHandler) kidsWithStats(kids []models.Kid) []models.KidWithStats {
+	result := make([]models.KidWithStats, 0, len(kids))
+	for i := range kids {
+		kid := &kids[i]
+		stats := models.KidWithStats{Kid: *kid}
+
+		var err error
+		if stats.TotalPractices, err = h.practiceService.GetKidTotalSessionsCount(kid.ID); err != nil {
+			log.Printf("Error getting practice count for kid %d: %v", kid.ID, err)
+		}
+		if stats.TotalPoints, err = h.practiceService.GetKidTotalPoints(kid.ID); err != nil {
+			log.Printf("Error getting points for kid %d: %v", kid.ID, err)
+		}
+		if lists, err := h.listService.GetKidAssignedLists(kid.ID); err != nil {
+			log.Printf("Error getting assigned lists for kid %d: %v", kid.ID, err)
+		} else {
+			stats.AssignedListsCount = len(lists)
+		}
+		if streaks, err := h.streakService.GetKidStreaks(kid, time.Now()); err != nil {
+			log.Printf("Error getting streaks for kid %d: %v", kid.ID, err)
+		} else {
+			stats.CurrentDailyStreak = streaks.CurrentDaily
+			stats.LongestDailyStreak = streaks.LongestDaily
+			stats.CurrentCorrectStreak = streaks.CurrentCorrect
+		}
+
+		result = append(result, stats)
+	}
+	return result
+}
+
+// verifyStreakFamily parses the form and checks the user belongs to the family in
+// the path, returning the family code or "" after responding
+func (h *ParentHandler) verifyStreakFamily(w http.ResponseWriter, r *http.Request) string {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return ""
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return ""
+	}
+
+	familyCode := r.PathValue("familyCode")
+	if err := h.familyService.VerifyFamilyAccess(user.ID, familyCode); err != nil {
+		http.Error(w, ErrUnauthorized, http.StatusForbidden)
+		return ""
+	}
+	return familyCode
+}
+
+// redirectToFamilies returns to the family page with a message
+func redirectToFamilies(w http.ResponseWriter, r *http.Request, key, message string) {
+	http.Redirect(w, r, "/parent/family?"+url.Values{key: {message}}.Encode(), http.StatusSeeOther)
+}
+
+// UpdateStreakSettings saves a family's time zone and whether weekends are days off
+func (h *ParentHandler) UpdateStreakSettings(w http.ResponseWriter, r *http.Request) {
+	familyCode := h.verifyStreakFamily(w, r)
+	if familyCode == "" {
+		return
+	}
+
+	err := h.streakService.UpdateSettings(familyCode, r.FormValue("timezone"), r.FormValue("weekends_off") == "on")
+	if errors.Is(err, service.ErrInvalidTimezone) {
+		redirectToFamilies(w, r, "error", "Unknown time zone. Use a name like Europe/London.")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error updating streak settings", err)
+		return
+	}
+
+	redirectToFamilies(w, r, "success", "Streak settings saved.")
+}
+
+// AddStreakFreeze adds days off, such as a school holiday, that don't break streaks
+func (h *ParentHandler) AddStreakFreeze(w http.ResponseWriter, r *http.Request) {
+	familyCode := h.verifyStreakFamily(w, r)
+	if familyCode == "" {
+		return
+	}
+
+	_, err := h.streakService.AddFreeze(familyCode, r.FormValue("name"), r.FormValue("start_date"), r.FormValue("end_date"))
+	if errors.Is(err, service.ErrInvalidStreakFreeze) {
+		redirectToFamilies(w, r, "error", "Days off need a name, and an end date on or after the start date within a year.")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error adding streak days off", err)
+		return
+	}
+
+	redirectToFamilies(w, r, "success", "Days off added.")
+}
+
+// DeleteStreakFreeze removes a family's days off
+func (h *ParentHandler) DeleteStreakFreeze(w http.ResponseWriter, r *http.Request) {
+	familyCode := h.verifyStreakFamily(w, r)
+	if familyCode == "" {
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid days off ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.streakService.DeleteFreeze(familyCode, id)
+	if errors.Is(err, service.ErrStreakFreezeNotFound) {
+		redirectToFamilies(w, r, "error", "Those days off have already been removed.")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error removing streak days off", err)
+		return
+	}
+
+	redirectToFamilies(w, r, "success", "Days off removed.")
+}
@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"spellingclash/internal/models"
+	"spellingclash/internal/service"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ShowClasses renders the teacher's classes, including archived ones.
+func (h *TeacherHandler) ShowClasses(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.requireTeacherPage(w, r)
+	if !ok {
+		return
+	}
+
+	classes, err := h.teacherService.GetTeacherClasses(user.ID, true)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting classes", err)
+		return
+	}
+
+	data := TeacherClassesViewData{
+		Title:     "Classes - WordClash",
+		User:      user,
+		Classes:   classes,
+		Success:   strings.TrimSpace(r.URL.Query().Get("success")),
+		Error:     strings.TrimSpace(r.URL.Query().Get("error")),
+		CSRFToken: h.getCSRFToken(r),
+	}
+	if err := h.templates.ExecuteTemplate(w, "teacher_classes.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering classes", err)
+	}
+}
+
+// CreateClass creates a new class and opens it.
+func (h *TeacherHandler) CreateClass(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.requireTeacherAction(w, r)
+	if !ok {
+		return
+	}
+
+	class, err := h.teacherService.CreateClass(user.ID, r.FormValue("name"))
+	if err != nil {
+		http.Redirect(w, r, "/teacher/classes?error="+url.QueryEscape(classErrorMessage(err)), http.StatusSeeOther)
+		return
+	}
+
+	redirectToClass(w, r, class.ID, "success", "Class created")
+}
+
+// ViewClass renders the dashboard for one class.
+func (h *TeacherHandler) ViewClass(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.requireTeacherPage(w, r)
+	if !ok {
+		return
+	}
+
+	classID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+
+	class, err := h.teacherService.GetClass(user.ID, classID)
+	if errors.Is(err, service.ErrClassNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting class", err)
+		return
+	}
+
+	kids, err := h.teacherService.GetClassMembers(user.ID, classID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting class members", err)
+		return
+	}
+	members := make([]ClassMember, 0, len(kids))
+	inClass := make(map[int64]bool, len(kids))
+	for _, kid := range kids {
+		stats, err := h.practiceService.GetKidStats(kid.ID)
+		if err != nil {
+			log.Printf("Error getting stats for kid %d: %v", kid.ID, err)
+		}
+		members = append(members, ClassMember{Kid: kid, Stats: stats})
+		inClass[kid.ID] = true
+	}
+
+	lists, err := h.teacherService.GetClassLists(user.ID, classID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting class lists", err)
+		return
+	}
+
+	tests, err := h.spellingTestService.GetClassTests(user.ID, classID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting class spelling tests", err)
+		return
+	}
+
+	data := TeacherClassViewData{
+		Title:     class.Name + " - WordClash",
+		User:      user,
+		Class:     class,
+		Members:   members,
+		Lists:     lists,
+		Tests:     tests,
+		Now:       time.Now(),
+		Success:   strings.TrimSpace(r.URL.Query().Get("success")),
+		Error:     strings.TrimSpace(r.URL.Query().Get("error")),
+		CSRFToken: h.getCSRFToken(r),
+	}
+
+	// The forms for changing the class are only shown while it is active
+	if !class.IsArchived() {
+		roster, err := h.teacherService.GetTeacherKids(user.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting class children", err)
+			return
+		}
+		for _, kid := range roster {
+			if !inClass[kid.ID] {
+				data.OtherKids = append(data.OtherKids, kid)
+			}
+		}
+
+		classes, err := h.teacherService.GetTeacherClasses(user.ID, false)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting classes", err)
+			return
+		}
+		for _, other := range classes {
+			if other.ID != class.ID {
+				data.OtherClasses = append(data.OtherClasses, other)
+			}
+		}
+
+		data.AllLists, err = h.listService.GetAllUserListsWithAssignments(user.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting spelling lists", err)
+			return
+		}
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "teacher_class.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering class", err)
+	}
+}
+
+// RenameClass renames a class.
+func (h *TeacherHandler) RenameClass(w http.ResponseWriter, r *http.Request) {
+	user, classID, ok := h.classAction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.teacherService.RenameClass(user.ID, classID, r.FormValue("name")); err != nil {
+		redirectToClass(w, r, classID, "error", classErrorMessage(err))
+		return
+	}
+	redirectToClass(w, r, classID, "success", "Class renamed")
+}
+
+// ArchiveClass archives a class at the end of the year.
+func (h *TeacherHandler) ArchiveClass(w http.ResponseWriter, r *http.Request) {
+	user, classID, ok := h.classAction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.teacherService.ArchiveClass(user.ID, classID); err != nil {
+		redirectToClass(w, r, classID, "error", classErrorMessage(err))
+		return
+	}
+	http.Redirect(w, r, "/teacher/classes?success=Class+archived", http.StatusSeeOther)
+}
+
+// RestoreClass makes an archived class active again.
+func (h *TeacherHandler) RestoreClass(w http.ResponseWriter, r *http.Request) {
+	user, classID, ok := h.classAction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.teacherService.RestoreClass(user.ID, classID); err != nil {
+		redirectToClass(w, r, classID, "error", classErrorMessage(err))
+		return
+	}
+	redirectToClass(w, r, classID, "success", "Class restored")
+}
+
+// AddClassMember adds one of the teacher's children to a class.
+func (h *TeacherHandler) AddClassMember(w http.ResponseWriter, r *http.Request) {
+	user, classID, ok := h.classAction(w, r)
+	if !ok {
+		return
+	}
+
+	kidID, err := strconv.ParseInt(r.FormValue("kid_id"), 10, 64)
+	if err != nil || kidID <= 0 {
+		redirectToClass(w, r, classID, "error", "Please select a child")
+		return
+	}
+
+	if err := h.teacherService.AddKidToClass(user.ID, classID, kidID); err != nil {
+		redirectToClass(w, r, classID, "error", classErrorMessage(err))
+		return
+	}
+	redirectToClass(w, r, classID, "success", "Child added to class")
+}
+
+// RemoveClassMember removes a child from a class.
+func (h *TeacherHandler) RemoveClassMember(w http.ResponseWriter, r *http.Request) {
+	user, classID, ok := h.classAction(w, r)
+	if !ok {
+		return
+	}
+
+	kidID, err := strconv.ParseInt(r.PathValue("kidId"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid child ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.teacherService.RemoveKidFromClass(user.ID, classID, kidID); err != nil {
+		redirectToClass(w, r, classID, "error", classErrorMessage(err))
+		return
+	}
+	redirectToClass(w, r, classID, "success", "Child removed from class")
+}
+
+// MoveClassMember moves a child from this class to another.
+func (h *TeacherHandler) MoveClassMember(w http.ResponseWriter, r *http.Request) {
+	user, classID, ok := h.classAction(w, r)
+	if !ok {
+		return
+	}
+
+	kidID, err := strconv.ParseInt(r.PathValue("kidId"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid child ID", http.StatusBadRequest)
+		return
+	}
+	toClassID, err := strconv.ParseInt(r.FormValue("to_class_id"), 10, 64)
+	if err != nil || toClassID <= 0 {
+		redirectToClass(w, r, classID, "error", "Please select a class")
+		return
+	}
+
+	if err := h.teacherService.MoveKidToClass(user.ID, kidID, classID, toClassID); err != nil {
+		redirectToClass(w, r, classID, "error", classErrorMessage(err))
+		return
+	}
+	redirectToClass(w, r, classID, "success", "Child moved to another class")
+}
+
+// AssignClassList assigns a spelling list to everyone in a class.
+func (h *TeacherHandler) AssignClassList(w http.ResponseWriter, r *http.Request) {
+	user, classID, ok := h.classAction(w, r)
+	if !ok {
+		return
+	}
+
+	listID, err := strconv.ParseInt(r.FormValue("list_id"), 10, 64)
+	if err != nil || listID <= 0 {
+		redirectToClass(w, r, classID, "error", "Please select a valid list")
+		return
+	}
+	dueDate, err := parseOptionalDate(r.FormValue("due_date"))
+	if err != nil {
+		redirectToClass(w, r, classID, "error", "Due date must be in YYYY-MM-DD format")
+		return
+	}
+
+	assigned, err := h.listService.AssignListToClass(listID, classID, user.ID, dueDate)
+	if err != nil {
+		redirectToClass(w, r, classID, "error", classErrorMessage(err))
+		return
+	}
+	redirectToClass(w, r, classID, "success", fmt.Sprintf("Assigned list to %d students", assigned))
+}
+
+// UnassignClassList removes a spelling list from a class.
+func (h *TeacherHandler) UnassignClassList(w http.ResponseWriter, r *http.Request) {
+	user, classID, ok := h.classAction(w, r)
+	if !ok {
+		return
+	}
+
+	listID, err := strconv.ParseInt(r.PathValue("listId"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid list ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.listService.UnassignListFromClass(listID, classID, user.ID); err != nil {
+		redirectToClass(w, r, classID, "error", classErrorMessage(err))
+		return
+	}
+	redirectToClass(w, r, classID, "success", "List removed from class")
+}
+
+// requireTeacherPage returns the logged in teacher for a page request
+func (h *TeacherHandler) requireTeacherPage(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, false
+	}
+	if !user.IsTeacher {
+		http.Error(w, "Forbidden: Teacher access required", http.StatusForbidden)
+		return nil, false
+	}
+	return user, true
+}
+
+// requireTeacherAction returns the logged in teacher for a form submission
+func (h *TeacherHandler) requireTeacherAction(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return nil, false
+	}
+	if !user.IsTeacher {
+		http.Error(w, "Forbidden: Teacher access required", http.StatusForbidden)
+		return nil, false
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return nil, false
+	}
+	return user, true
+}
+
+// classAction returns the teacher and class ID for a form submission on a class
+func (h *TeacherHandler) classAction(w http.ResponseWriter, r *http.Request) (*models.User, int64, bool) {
+	user, ok := h.requireTeacherAction(w, r)
+	if !ok {
+		return nil, 0, false
+	}
+	classID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return nil, 0, false
+	}
+	return user, classID, true
+}
+
+// formClassID reads the optional class_id used to put new children straight into
+// a class, checking the class can take new members
+func (h *TeacherHandler) formClassID(r *http.Request, teacherUserID int64) (int64, error) {
+	raw := strings.TrimSpace(r.FormValue("class_id"))
+	if raw == "" {
+		return 0, nil
+	}
+	classID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, service.ErrClassNotFound
+	}
+	class, err := h.teacherService.GetClass(teacherUserID, classID)
+	if err != nil {
+		return 0, err
+	}
+	if class.IsArchived() {
+		return 0, service.ErrClassArchived
+	}
+	return classID, nil
+}
+
+func redirectToClass(w http.ResponseWriter, r *http.Request, classID int64, key, message string) {
+	target := fmt.Sprintf("/teacher/classes/%d?%s=%s", classID, key, url.QueryEscape(message))
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// classErrorMessage returns a message for a class error that is safe to show
+func classErrorMessage(err error) string {
+	for _, known := range []error{
+		service.ErrClassNotFound,
+		service.ErrClassNameInvalid,
+		service.ErrClassNameTaken,
+		service.ErrClassArchived,
+		service.ErrKidNotInClass,
+		service.ErrTeacherKidLink,
+		service.ErrListNotFound,
+		service.ErrNotFamilyMember,
+		service.ErrTestNotFound,
+		service.ErrTestWindowInvalid,
+		service.ErrTestListEmpty,
+		service.ErrTestNeedsAudio,
+	} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	log.Printf("Error updating class: %v", err)
+	return "Something went wrong, please try again"
+}
@@ -16,19 +16,25 @@ import (
 
 // TeacherHandler handles teacher-facing class management routes.
 type TeacherHandler struct {
-	teacherService *service.TeacherService
-	listService    *service.ListService
-	middleware     *Middleware
-	templates      *template.Template
+	teacherService      *service.TeacherService
+	listService         *service.ListService
+	practiceService     *service.PracticeService
+	spellingTestService *service.SpellingTestService
+	spellingBeeService  *service.SpellingBeeService
+	middleware          *Middleware
+	templates           *template.Template
 }
 
 // NewTeacherHandler creates a new teacher handler.
-func NewTeacherHandler(teacherService *service.TeacherService, listService *service.ListService, middleware *Middleware, templates *template.Template) *TeacherHandler {
+func NewTeacherHandler(teacherService *service.TeacherService, listService *service.ListService, practiceService *service.PracticeService, spellingTestService *service.SpellingTestService, spellingBeeService *service.SpellingBeeService, middleware *Middleware, templates *template.Template) *TeacherHandler {
 	return &TeacherHandler{
-		teacherService: teacherService,
-		listService:    listService,
-		middleware:     middleware,
-		templates:      templates,
+		teacherService:      teacherService,
+		listService:         listService,
+		practiceService:     practiceService,
+		spellingTestService: spellingTestService,
+		spellingBeeService:  spellingBeeService,
+		middleware:          middleware,
+		templates:           templates,
 	}
 }
 
@@ -50,6 +56,12 @@ func (h *TeacherHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	classes, err := h.teacherService.GetTeacherClasses(user.ID, false)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting classes", err)
+		return
+	}
+
 	allLists, err := h.listService.GetAllUserListsWithAssignments(user.ID)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting spelling lists", err)
@@ -60,6 +72,7 @@ func (h *TeacherHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
 		Title:     "Teacher Dashboard - WordClash",
 		User:      user,
 		Kids:      kids,
+		Classes:   classes,
 		AllLists:  allLists,
 		Success:   strings.TrimSpace(r.URL.Query().Get("success")),
 		Error:     strings.TrimSpace(r.URL.Query().Get("error")),
@@ -89,6 +102,11 @@ func (h *TeacherHandler) CreateKid(w http.ResponseWriter, r *http.Request) {
 
 	name := strings.TrimSpace(r.FormValue("name"))
 	avatarColor := strings.TrimSpace(r.FormValue("avatar_color"))
+	classID, err := h.formClassID(r, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	kid, err := h.teacherService.CreateTeacherKid(user.ID, name, avatarColor)
 	if err != nil {
@@ -96,6 +114,13 @@ func (h *TeacherHandler) CreateKid(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if classID > 0 {
+		if err := h.teacherService.AddKidToClass(user.ID, classID, kid.ID); err != nil {
+			log.Printf("Error adding child to class: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("Content-Type", "text/html")
@@ -174,6 +199,11 @@ func (h *TeacherHandler) BulkCreateKids(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Please provide at least one child name", http.StatusBadRequest)
 		return
 	}
+	classID, err := h.formClassID(r, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	kids, err := h.teacherService.BulkCreateTeacherKids(user.ID, names)
 	if err != nil {
@@ -181,6 +211,15 @@ func (h *TeacherHandler) BulkCreateKids(w http.ResponseWriter, r *http.Request)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if classID > 0 {
+		for _, kid := range kids {
+			if err := h.teacherService.AddKidToClass(user.ID, classID, kid.ID); err != nil {
+				log.Printf("Error adding child to class: %v", err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	}
 
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("Content-Type", "text/html")
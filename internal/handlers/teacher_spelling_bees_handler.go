@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"spellingclash/internal/models"
+	"spellingclash/internal/service"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ShowSpellingBees lists the teacher's bees and the form to start a new one.
+func (h *TeacherHandler) ShowSpellingBees(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.requireTeacherPage(w, r)
+	if !ok {
+		return
+	}
+
+	bees, err := h.spellingBeeService.GetTeacherBees(user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting spelling bees", err)
+		return
+	}
+	kids, err := h.teacherService.GetTeacherKids(user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting class children", err)
+		return
+	}
+	allLists, err := h.listService.GetAllUserListsWithAssignments(user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting spelling lists", err)
+		return
+	}
+
+	data := TeacherSpellingBeesViewData{
+		Title:     "Spelling Bees - WordClash",
+		User:      user,
+		Bees:      bees,
+		Kids:      kids,
+		AllLists:  allLists,
+		Success:   strings.TrimSpace(r.URL.Query().Get("success")),
+		Error:     strings.TrimSpace(r.URL.Query().Get("error")),
+		CSRFToken: h.getCSRFToken(r),
+	}
+	if err := h.templates.ExecuteTemplate(w, "teacher_spelling_bees.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering spelling bees", err)
+	}
+}
+
+// CreateSpellingBee starts a bee on a list for the children ticked on the form.
+func (h *TeacherHandler) CreateSpellingBee(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.requireTeacherAction(w, r)
+	if !ok {
+		return
+	}
+
+	listID, err := strconv.ParseInt(r.FormValue("list_id"), 10, 64)
+	if err != nil || listID <= 0 {
+		redirectToBees(w, r, "error", "Please select a valid list")
+		return
+	}
+	var kidIDs []int64
+	for _, raw := range r.Form["kid_ids"] {
+		kidID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid child ID", http.StatusBadRequest)
+			return
+		}
+		kidIDs = append(kidIDs, kidID)
+	}
+	maxMisses, err := strconv.Atoi(r.FormValue("max_misses"))
+	if err != nil {
+		redirectToBees(w, r, "error", service.ErrBeeMissesInvalid.Error())
+		return
+	}
+
+	bee, err := h.spellingBeeService.CreateBee(user.ID, listID, kidIDs, maxMisses, r.FormValue("answer_mode"), time.Now())
+	if err != nil {
+		redirectToBees(w, r, "error", beeErrorMessage(err))
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/teacher/bees/%d", bee.ID), http.StatusSeeOther)
+}
+
+// ViewSpellingBee renders the page a teacher hosts a bee from, usually shown on
+// the classroom board. It dictates the current word and shows the standings.
+func (h *TeacherHandler) ViewSpellingBee(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.requireTeacherPage(w, r)
+	if !ok {
+		return
+	}
+	beeID, ok := spellingBeeID(w, r)
+	if !ok {
+		return
+	}
+
+	state, err := h.spellingBeeService.GetTeacherState(user.ID, beeID)
+	if errors.Is(err, service.ErrBeeNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting spelling bee", err)
+		return
+	}
+
+	data := TeacherSpellingBeeViewData{
+		Title:     state.Bee.ListName + " Spelling Bee - WordClash",
+		User:      user,
+		State:     state,
+		Success:   strings.TrimSpace(r.URL.Query().Get("success")),
+		Error:     strings.TrimSpace(r.URL.Query().Get("error")),
+		CSRFToken: h.getCSRFToken(r),
+	}
+	if err := h.templates.ExecuteTemplate(w, "teacher_spelling_bee.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering spelling bee", err)
+	}
+}
+
+// NextSpellingBeeWord dictates a new word to the next child still in the bee.
+func (h *TeacherHandler) NextSpellingBeeWord(w http.ResponseWriter, r *http.Request) {
+	user, beeID, ok := h.beeAction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.spellingBeeService.NextTurn(user.ID, beeID, time.Now()); err != nil {
+		redirectToBee(w, r, beeID, "error", beeErrorMessage(err))
+		return
+	}
+	redirectToBee(w, r, beeID, "", "")
+}
+
+// MarkSpellingBeeWord records whether the child spelled the current word right.
+func (h *TeacherHandler) MarkSpellingBeeWord(w http.ResponseWriter, r *http.Request) {
+	user, beeID, ok := h.beeAction(w, r)
+	if !ok {
+		return
+	}
+
+	correct := r.FormValue("correct") == "true"
+	if err := h.spellingBeeService.MarkTurn(user.ID, beeID, correct, time.Now()); err != nil {
+		redirectToBee(w, r, beeID, "error", beeErrorMessage(err))
+		return
+	}
+	redirectToBee(w, r, beeID, "", "")
+}
+
+// EndSpellingBee finishes a bee early and saves the standings as they are.
+func (h *TeacherHandler) EndSpellingBee(w http.ResponseWriter, r *http.Request) {
+	user, beeID, ok := h.beeAction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.spellingBeeService.EndBee(user.ID, beeID, time.Now()); err != nil {
+		redirectToBee(w, r, beeID, "error", beeErrorMessage(err))
+		return
+	}
+	redirectToBee(w, r, beeID, "success", "Spelling bee finished")
+}
+
+// PollSpellingBee reloads the teacher's page once a child's typed answer has
+// changed the bee.
+func (h *TeacherHandler) PollSpellingBee(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil || !user.IsTeacher {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+	beeID, ok := spellingBeeID(w, r)
+	if !ok {
+		return
+	}
+
+	bee, err := h.spellingBeeService.GetTeacherBee(user.ID, beeID)
+	if errors.Is(err, service.ErrBeeNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting spelling bee", err)
+		return
+	}
+	refreshIfBeeChanged(w, r, bee)
+}
+
+// beeAction returns the teacher and bee ID for a form submission on a bee
+func (h *TeacherHandler) beeAction(w http.ResponseWriter, r *http.Request) (*models.User, int64, bool) {
+	user, ok := h.requireTeacherAction(w, r)
+	if !ok {
+		return nil, 0, false
+	}
+	beeID, ok := spellingBeeID(w, r)
+	if !ok {
+		return nil, 0, false
+	}
+	return user, beeID, true
+}
+
+// spellingBeeID reads the bee ID from the request path
+func spellingBeeID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	beeID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid spelling bee ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return beeID, true
+}
+
+// refreshIfBeeChanged answers an htmx poll carrying the version the page was
+// rendered at, telling htmx to reload the page if the bee has moved on since
+func refreshIfBeeChanged(w http.ResponseWriter, r *http.Request, bee *models.SpellingBee) {
+	if r.URL.Query().Get("v") != strconv.Itoa(bee.Version) {
+		w.Header().Set("HX-Refresh", "true")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func redirectToBees(w http.ResponseWriter, r *http.Request, key, message string) {
+	target := fmt.Sprintf("/teacher/bees?%s=%s", key, url.QueryEscape(message))
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+func redirectToBee(w http.ResponseWriter, r *http.Request, beeID int64, key, message string) {
+	target := fmt.Sprintf("/teacher/bees/%d", beeID)
+	if key != "" {
+		target += fmt.Sprintf("?%s=%s", key, url.QueryEscape(message))
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// beeErrorMessage turns a spelling bee error into a message for the teacher
+func beeErrorMessage(err error) string {
+	for _, known := range []error{
+		service.ErrBeeNotFound,
+		service.ErrBeeNeedsKids,
+		service.ErrBeeMissesInvalid,
+		service.ErrBeeModeInvalid,
+		service.ErrBeeListEmpty,
+		service.ErrBeeFinished,
+		service.ErrBeeTurnOpen,
+		service.ErrBeeNoTurn,
+		service.ErrTeacherKidLink,
+		service.ErrListNotFound,
+	} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	log.Printf("Error updating spelling bee: %v", err)
+	return "Something went wrong, please try again"
+}
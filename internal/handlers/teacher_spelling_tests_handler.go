@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"spellingclash/internal/service"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// testWindowLayout is the format of a datetime-local form field
+const testWindowLayout = "2006-01-02T15:04"
+
+// ScheduleSpellingTest schedules a test window on a list for a class.
+func (h *TeacherHandler) ScheduleSpellingTest(w http.ResponseWriter, r *http.Request) {
+	user, classID, ok := h.classAction(w, r)
+	if !ok {
+		return
+	}
+
+	listID, err := strconv.ParseInt(r.FormValue("list_id"), 10, 64)
+	if err != nil || listID <= 0 {
+		redirectToClass(w, r, classID, "error", "Please select a valid list")
+		return
+	}
+	opensAt, err := time.ParseInLocation(testWindowLayout, strings.TrimSpace(r.FormValue("opens_at")), time.Local)
+	if err != nil {
+		redirectToClass(w, r, classID, "error", "Please choose when the test opens")
+		return
+	}
+	closesAt, err := time.ParseInLocation(testWindowLayout, strings.TrimSpace(r.FormValue("closes_at")), time.Local)
+	if err != nil {
+		redirectToClass(w, r, classID, "error", "Please choose when the test closes")
+		return
+	}
+
+	if _, err := h.spellingTestService.ScheduleTest(user.ID, classID, listID, opensAt, closesAt); err != nil {
+		redirectToClass(w, r, classID, "error", classErrorMessage(err))
+		return
+	}
+	redirectToClass(w, r, classID, "success", "Spelling test scheduled")
+}
+
+// CancelSpellingTest deletes a scheduled test and any results.
+func (h *TeacherHandler) CancelSpellingTest(w http.ResponseWriter, r *http.Request) {
+	user, classID, ok := h.classAction(w, r)
+	if !ok {
+		return
+	}
+
+	testID, err := strconv.ParseInt(r.PathValue("testId"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid test ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.spellingTestService.CancelTest(user.ID, classID, testID); err != nil {
+		redirectToClass(w, r, classID, "error", classErrorMessage(err))
+		return
+	}
+	redirectToClass(w, r, classID, "success", "Spelling test cancelled")
+}
+
+// ViewSpellingTest renders each child's score and answers for a test.
+func (h *TeacherHandler) ViewSpellingTest(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.requireTeacherPage(w, r)
+	if !ok {
+		return
+	}
+
+	classID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		return
+	}
+	testID, err := strconv.ParseInt(r.PathValue("testId"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid test ID", http.StatusBadRequest)
+		return
+	}
+
+	class, err := h.teacherService.GetClass(user.ID, classID)
+	if errors.Is(err, service.ErrClassNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting class", err)
+		return
+	}
+
+	test, results, err := h.spellingTestService.GetTestResults(user.ID, classID, testID)
+	if errors.Is(err, service.ErrTestNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting spelling test results", err)
+		return
+	}
+
+	data := TeacherSpellingTestViewData{
+		Title:     test.ListName + " Test - WordClash",
+		User:      user,
+		Class:     class,
+		Test:      test,
+		Results:   results,
+		Now:       time.Now(),
+		CSRFToken: h.getCSRFToken(r),
+	}
+	if err := h.templates.ExecuteTemplate(w, "teacher_spelling_test.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering spelling test results", err)
+	}
+}
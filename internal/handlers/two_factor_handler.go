@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"spellingclash/internal/models"
+	"spellingclash/internal/security"
+	"spellingclash/internal/service"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// TwoFactorSetupPath is where users manage two-factor authentication. Users the
+	// admin requires to use it are sent here until they have.
+	TwoFactorSetupPath = "/account/two-factor"
+
+	loginChallengeCookieName = "login_challenge"
+)
+
+// startTwoFactorLogin remembers a sign-in that passed its first step and asks the
+// user for their authentication code
+func (h *AuthHandler) startTwoFactorLogin(w http.ResponseWriter, r *http.Request, challenge *models.LoginChallenge) {
+	h.setTempCookie(w, r, loginChallengeCookieName, challenge.ID, time.Until(challenge.ExpiresAt))
+	http.Redirect(w, r, "/login/two-factor", http.StatusSeeOther)
+}
+
+// ShowTwoFactorLogin renders the second step of the login form
+func (h *AuthHandler) ShowTwoFactorLogin(w http.ResponseWriter, r *http.Request) {
+	if _, err := r.Cookie(loginChallengeCookieName); err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	h.renderTwoFactorLogin(w, "")
+}
+
+// TwoFactorLogin checks the authentication code and finishes the sign-in
+func (h *AuthHandler) TwoFactorLogin(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(loginChallengeCookieName)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return
+	}
+
+	session, user, err := h.authService.CompleteTwoFactorLogin(cookie.Value, r.FormValue("code"))
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		h.renderTwoFactorLogin(w, "That code didn't work. Check your authenticator app and try again.")
+		return
+	case errors.Is(err, service.ErrLoginChallengeExpired):
+		h.clearTempCookie(w, r, loginChallengeCookieName)
+		h.httpError(w, r, "Your sign-in timed out or had too many wrong codes. Please log in again.", http.StatusUnauthorized)
+		return
+	default:
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error completing two-factor login", err)
+		return
+	}
+
+	h.clearTempCookie(w, r, loginChallengeCookieName)
+	http.SetCookie(w, security.CreateSessionCookie(r, SessionCookieName, session.ID, session.ExpiresAt))
+	h.rememberLoginDevice(w, r, user)
+	if user.IsTeacher {
+		http.Redirect(w, r, "/teacher/dashboard", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/parent/dashboard", http.StatusSeeOther)
+}
+
+func (h *AuthHandler) renderTwoFactorLogin(w http.ResponseWriter, message string) {
+	data := TwoFactorLoginViewData{
+		Title: "Two-factor Authentication - WordClash",
+		Error: message,
+	}
+	if err := h.templates.ExecuteTemplate(w, "login_two_factor.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering two-factor login template", err)
+	}
+}
+
+// TwoFactorHandler handles the page where users set up two-factor authentication
+type TwoFactorHandler struct {
+	authService *service.AuthService
+	middleware  *Middleware
+	templates   *template.Template
+	issuer      string
+}
+
+// NewTwoFactorHandler creates a new two-factor handler. The issuer names the
+// account in the user's authenticator app.
+func NewTwoFactorHandler(authService *service.AuthService, middleware *Middleware, templates *template.Template, issuer string) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		authService: authService,
+		middleware:  middleware,
+		templates:   templates,
+		issuer:      issuer,
+	}
+}
+
+// ShowTwoFactor displays the user's two-factor status, or the QR code to scan while
+// they are setting it up
+func (h *TwoFactorHandler) ShowTwoFactor(w http.ResponseWriter, r *http.Request) {
+	data := TwoFactorViewData{Error: r.URL.Query().Get("error")}
+	if r.URL.Query().Get("success") == "disabled" {
+		data.Success = "Two-factor authentication is off."
+	}
+	h.renderTwoFactor(w, r, data)
+}
+
+// StartSetup creates a new secret for the user to scan
+func (h *TwoFactorHandler) StartSetup(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	_, err := h.authService.BeginTwoFactorEnrolment(user.ID)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled):
+		h.redirectWithError(w, r, err)
+		return
+	default:
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error starting two-factor setup", err)
+		return
+	}
+	http.Redirect(w, r, TwoFactorSetupPath, http.StatusSeeOther)
+}
+
+// ConfirmSetup turns on two-factor authentication once the user enters a code from
+// their app, and shows their recovery codes
+func (h *TwoFactorHandler) ConfirmSetup(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.authService.ConfirmTwoFactorEnrolment(user.ID, r.FormValue("code"))
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		h.renderTwoFactor(w, r, TwoFactorViewData{Error: "That code didn't work. Check the time on your phone is right and try again."})
+		return
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled), errors.Is(err, service.ErrTwoFactorNotEnabled):
+		h.redirectWithError(w, r, err)
+		return
+	default:
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error confirming two-factor setup", err)
+		return
+	}
+
+	log.Printf("User %d turned on two-factor authentication", user.ID)
+	h.renderTwoFactor(w, r, TwoFactorViewData{
+		Success:       "Two-factor authentication is on. You'll be asked for a code each time you log in.",
+		RecoveryCodes: codes,
+	})
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(user.ID, r.FormValue("code"))
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrInvalidTwoFactorCode), errors.Is(err, service.ErrTwoFactorNotEnabled):
+		h.redirectWithError(w, r, err)
+		return
+	default:
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error regenerating recovery codes", err)
+		return
+	}
+
+	log.Printf("User %d regenerated their recovery codes", user.ID)
+	h.renderTwoFactor(w, r, TwoFactorViewData{
+		Success:       "New recovery codes created. Your old codes no longer work.",
+		RecoveryCodes: codes,
+	})
+}
+
+// Disable turns off two-factor authentication
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, ErrInvalidFormData, http.StatusBadRequest)
+		return
+	}
+
+	err := h.authService.DisableTwoFactor(user, r.FormValue("code"))
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrInvalidTwoFactorCode), errors.Is(err, service.ErrTwoFactorNotEnabled), errors.Is(err, service.ErrTwoFactorEnforced):
+		h.redirectWithError(w, r, err)
+		return
+	default:
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error disabling two-factor authentication", err)
+		return
+	}
+
+	log.Printf("User %d turned off two-factor authentication", user.ID)
+	http.Redirect(w, r, TwoFactorSetupPath+"?success=disabled", http.StatusSeeOther)
+}
+
+func (h *TwoFactorHandler) redirectWithError(w http.ResponseWriter, r *http.Request, err error) {
+	http.Redirect(w, r, TwoFactorSetupPath+"?"+url.Values{"error": {err.Error()}}.Encode(), http.StatusSeeOther)
+}
+
+func (h *TwoFactorHandler) renderTwoFactor(w http.ResponseWriter, r *http.Request, data TwoFactorViewData) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	tf, err := h.authService.GetTwoFactor(user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error getting two-factor settings", err)
+		return
+	}
+
+	data.Title = "Two-factor Authentication - WordClash"
+	data.User = user
+	data.Enabled = tf.IsEnabled()
+	data.Required = h.authService.TwoFactorPolicy().Requires(user)
+	if data.Enabled {
+		if data.RecoveryCodesLeft, err = h.authService.CountRecoveryCodes(user.ID); err != nil {
+			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error counting recovery codes", err)
+			return
+		}
+	} else if tf != nil {
+		// Setup has started, so show the secret to add to an authenticator app
+		data.Pending = true
+		data.Secret = tf.Secret
+		png, err := qrcode.Encode(security.TOTPKeyURI(h.issuer, user.Email, tf.Secret), qrcode.Medium, 256)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error creating QR code", err)
+			return
+		}
+		data.QRCode = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png))
+	}
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		data.CSRFToken, _ = h.middleware.GetCSRFToken(cookie.Value)
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "two_factor.tmpl", data); err != nil {
+		respondWithError(w, http.StatusInternalServerError, ErrInternalServerError, "Error rendering two-factor template", err)
+	}
+}
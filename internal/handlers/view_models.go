@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"html/template"
 	"time"
 
 	"spellingclash/internal/models"
 	"spellingclash/internal/repository"
+	"spellingclash/internal/service"
 )
 
 type LoginViewData struct {
@@ -44,6 +46,7 @@ type AdminDashboardViewData struct {
 	Title       string
 	User        *models.User
 	PublicLists []models.SpellingList
+	EmailQueue  *service.EmailQueueStatus
 	CSRFToken   string
 	Version     string
 }
@@ -51,13 +54,15 @@ type AdminDashboardViewData struct {
 type AdminUserWithFamily struct {
 	models.User
 	FamilyCode string
+	TwoFactor  bool
 }
 
 type AdminUsersViewData struct {
-	Title     string
-	User      *models.User
-	Users     []AdminUserWithFamily
-	CSRFToken string
+	Title           string
+	User            *models.User
+	Users           []AdminUserWithFamily
+	TwoFactorPolicy models.TwoFactorPolicy
+	CSRFToken       string
 }
 
 type AdminFamiliesViewData struct {
@@ -95,7 +100,7 @@ type ParentDashboardViewData struct {
 	Title         string
 	User          *models.User
 	Families      []models.Family
-	Kids          []models.Kid
+	Kids          []models.KidWithStats
 	FamilyMembers []models.FamilyMember
 	ParentUsers   []models.User
 	CSRFToken     string
@@ -105,7 +110,18 @@ type ParentFamilyViewData struct {
 	Title     string
 	User      *models.User
 	Families  []models.Family
+	Streaks   []FamilyStreakSettings
+	Timezones []string
 	CSRFToken string
+	Error     string
+	Success   string
+}
+
+// FamilyStreakSettings is one family's streak settings on the family page
+type FamilyStreakSettings struct {
+	FamilyCode string
+	Settings   *models.StreakSettings
+	Freezes    []models.StreakFreeze
 }
 
 type ParentKidsViewData struct {
@@ -121,17 +137,187 @@ type TeacherDashboardViewData struct {
 	Title     string
 	User      *models.User
 	Kids      []models.Kid
+	Classes   []models.TeacherClass
 	AllLists  []models.ListSummary
 	Success   string
 	Error     string
 	CSRFToken string
 }
 
+type TeacherClassesViewData struct {
+	Title     string
+	User      *models.User
+	Classes   []models.TeacherClass
+	Success   string
+	Error     string
+	CSRFToken string
+}
+
+// ClassMember is a child in a class with their practice totals
+type ClassMember struct {
+	Kid   models.Kid
+	Stats *models.KidStats
+}
+
+type TeacherClassViewData struct {
+	Title        string
+	User         *models.User
+	Class        *models.TeacherClass
+	Members      []ClassMember
+	Lists        []models.TeacherClassList
+	AllLists     []models.ListSummary
+	OtherKids    []models.Kid          // Teacher's children not in this class
+	OtherClasses []models.TeacherClass // Active classes members can move to
+	Tests        []models.SpellingTest
+	Now          time.Time // Used to show each test's window status
+	Success      string
+	Error        string
+	CSRFToken    string
+}
+
+type TeacherSpellingTestViewData struct {
+	Title     string
+	User      *models.User
+	Class     *models.TeacherClass
+	Test      *models.SpellingTest
+	Results   []models.SpellingTestResult
+	Now       time.Time
+	CSRFToken string
+}
+
+// TeacherSpellingBeesViewData is the page a teacher starts spelling bees from
+type TeacherSpellingBeesViewData struct {
+	Title     string
+	User      *models.User
+	Bees      []models.SpellingBee
+	Kids      []models.Kid
+	AllLists  []models.ListSummary
+	Success   string
+	Error     string
+	CSRFToken string
+}
+
+// TeacherSpellingBeeViewData is the page a teacher hosts a spelling bee from
+type TeacherSpellingBeeViewData struct {
+	Title     string
+	User      *models.User
+	State     *models.SpellingBeeState
+	Success   string
+	Error     string
+	CSRFToken string
+}
+
+type APITokensViewData struct {
+	Title     string
+	User      *models.User
+	Tokens    []models.APIToken
+	NewToken  string // Shown once, straight after the token is created
+	Success   string
+	Error     string
+	CSRFToken string
+}
+
+type NotificationsViewData struct {
+	Title          string
+	User           *models.User
+	WeeklyDigest   bool
+	EmailAvailable bool // False when no email backend is configured
+	NextDigest     time.Time
+	Success        string
+	Error          string
+	CSRFToken      string
+}
+
+// LeaderboardsViewData is the page where parents and teachers see their kids'
+// leaderboards and choose how each kid appears on them
+type LeaderboardsViewData struct {
+	Title     string
+	User      *models.User
+	Period    string
+	Boards    []models.Leaderboard
+	Privacy   []models.KidLeaderboardSettings
+	Success   string
+	Error     string
+	CSRFToken string
+}
+
+// SignInMethodsViewData is the page where users manage how they sign in
+type SignInMethodsViewData struct {
+	Title       string
+	User        *models.User
+	HasPassword bool
+	Providers   []SignInProviderView
+	CanUnlink   bool // False when unlinking would leave the user no way to sign in
+	TwoFactor   bool // Two-factor authentication is on
+	Success     string
+	Error       string
+	CSRFToken   string
+}
+
+// SignInProviderView is a configured sign-in provider and whether the user has linked it
+type SignInProviderView struct {
+	Name     string
+	Label    string
+	CSSClass string
+	Identity *models.UserIdentity // Nil if not linked
+}
+
+// TwoFactorViewData is the page where users set up two-factor authentication
+type TwoFactorViewData struct {
+	Title             string
+	User              *models.User
+	Enabled           bool
+	Pending           bool         // Setup has started but not been confirmed with a code
+	Secret            string       // For typing into an authenticator app that can't scan QRCode
+	QRCode            template.URL // The otpauth:// key URI as a PNG data URL
+	Required          bool         // The admin requires this user to use two-factor authentication
+	RecoveryCodes     []string     // Only set straight after they are generated
+	RecoveryCodesLeft int
+	Success           string
+	Error             string
+	CSRFToken         string
+}
+
+// TwoFactorLoginViewData is the login step asking for an authentication code
+type TwoFactorLoginViewData struct {
+	Title string
+	Error string
+}
+
+// SessionsViewData is the page where users see where they and their children are signed in
+type SessionsViewData struct {
+	Title     string
+	User      *models.User
+	Sessions  []SessionView
+	Kids      []KidSessionsView // Only children with active sessions
+	Success   string
+	Error     string
+	CSRFToken string
+}
+
+// SessionView is a signed-in device
+type SessionView struct {
+	Handle     string
+	Device     string
+	IPAddress  string
+	CreatedAt  time.Time
+	LastSeenAt *time.Time
+	Current    bool // The session viewing the page
+	RevokeURL  string
+}
+
+// KidSessionsView is a child and the devices they are signed in on
+type KidSessionsView struct {
+	Kid      models.Kid
+	Sessions []SessionView
+}
+
 type ParentListsViewData struct {
 	Title     string
 	User      *models.User
 	Lists     []models.ListSummary
 	Families  []models.Family
+	Locales   []models.Locale
 	CSRFToken string
 }
 
@@ -142,7 +328,10 @@ type ListDetailViewData struct {
 	Words        []models.Word
 	AssignedKids []models.Kid
 	FamilyKids   []models.Kid
-	CSRFToken    string
+	Locales      []models.Locale
+	// Likely typos keyed by word text, only shown to users who can edit the list
+	SpellingWarnings map[string]*models.SpellingWarning
+	CSRFToken        string
 }
 
 type KidSelectViewData struct {
@@ -151,9 +340,12 @@ type KidSelectViewData struct {
 }
 
 type KidLoginViewData struct {
-	Title    string
-	Kid      *models.Kid
-	HasError bool
+	Title       string
+	Kid         *models.Kid
+	HasError    bool
+	WaitSeconds int // How long to wait after too many wrong passwords
+	WaitMinutes int
+	Locked      bool // Whether the wait is long enough to need a grown-up's help
 }
 
 type KidDashboardViewData struct {
@@ -163,6 +355,49 @@ type KidDashboardViewData struct {
 	TotalPoints    int
 	TotalSessions  int
 	RecentSessions []models.PracticeSession
+	OpenTests      []models.KidSpellingTest
+	RunningBees    []models.KidSpellingBee
+	BeeHistory     []models.KidSpellingBee
+	Streaks        *models.KidStreaks
+	Trophies       []models.Trophy
+}
+
+// KidLeaderboardsViewData is the page where a kid sees the boards they are on
+type KidLeaderboardsViewData struct {
+	Title  string
+	Kid    *models.Kid
+	Period string
+	Boards []models.Leaderboard
+	Hidden bool // The kid has been taken off leaderboards
+}
+
+// KidClashLobbyViewData is the page where a kid challenges a rival to a clash
+type KidClashLobbyViewData struct {
+	Title   string
+	Kid     *models.Kid
+	Rivals  []models.ClashRival
+	Lists   []models.SpellingList
+	Invites []models.ClashInvite
+	History []models.ClashHistoryEntry
+	Success string
+	Error   string
+}
+
+// KidClashViewData is the page a clash is played on
+type KidClashViewData struct {
+	Title string
+	Kid   *models.Kid
+	State *models.ClashState
+}
+
+// KidSpellingBeeViewData is the page a kid follows a classroom spelling bee on
+type KidSpellingBeeViewData struct {
+	Title    string
+	Kid      *models.Kid
+	State    *models.SpellingBeeState
+	You      *models.SpellingBeeParticipant
+	YourTurn bool // The kid has a word to type an answer for
+	StillIn  int  // Children not yet out, including the kid
 }
 
 type KidDetailsViewData struct {
@@ -173,6 +408,7 @@ type KidDetailsViewData struct {
 	AllLists        []models.ListSummary
 	StrugglingWords []repository.StrugglingWord
 	Stats           *models.KidStats
+	Streaks         *models.KidStreaks
 	CSRFToken       string
 }
 
@@ -194,13 +430,21 @@ type PracticeViewData struct {
 	ProgressPercentage int
 }
 
+type SpellingTestViewData struct {
+	Title    string
+	Kid      *models.Kid
+	Progress *models.SpellingTestProgress
+	Closed   bool // The test closed before the child finished
+}
+
 type PracticeResultsViewData struct {
-	Title       string
-	Kid         *models.Kid
-	Session     *models.PracticeSession
-	Attempts    []models.WordAttempt
-	Accuracy    float64
-	TotalPoints int
+	Title           string
+	Kid             *models.Kid
+	Session         *models.PracticeSession
+	Attempts        []models.WordAttempt
+	Accuracy        float64
+	TotalPoints     int
+	NewAchievements []models.Achievement
 }
 
 type MissingLetterViewData struct {
@@ -210,9 +454,10 @@ type MissingLetterViewData struct {
 }
 
 type MissingLetterResultsViewData struct {
-	Title   string
-	Kid     *models.Kid
-	Results *models.MissingLetterSession
+	Title           string
+	Kid             *models.Kid
+	Results         *models.MissingLetterSession
+	NewAchievements []models.Achievement
 }
 
 type MissingLetterGameStateViewData struct {
@@ -227,9 +472,10 @@ type HangmanViewData struct {
 }
 
 type HangmanResultsViewData struct {
-	Title   string
-	Kid     *models.Kid
-	Results *models.HangmanSession
+	Title           string
+	Kid             *models.Kid
+	Results         *models.HangmanSession
+	NewAchievements []models.Achievement
 }
 
 type HangmanGameStateViewData struct {
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Achievement is something a kid can unlock, such as a week-long streak
+type Achievement struct {
+	Key         string
+	Name        string
+	Description string
+	Icon        string
+}
+
+// KidAchievement records a kid unlocking an achievement
+type KidAchievement struct {
+	ID             int64
+	KidID          int64
+	AchievementKey string
+	UnlockedAt     time.Time
+	SeenAt         *time.Time // When the kid was shown the unlock; nil until then
+}
+
+// Trophy is an achievement in a kid's trophy cabinet, unlocked or not
+type Trophy struct {
+	Achievement
+	UnlockedAt *time.Time // nil while still locked
+	IsNew      bool       // Unlocked since the kid last looked
+}
@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// Clash match statuses
+const (
+	ClashWaiting   = "waiting"   // Challenged, waiting for the opponent to accept
+	ClashPlaying   = "playing"   // Accepted and being played
+	ClashFinished  = "finished"  // Every round played
+	ClashCancelled = "cancelled" // Declined, withdrawn, expired or cut short by a restart
+)
+
+// Clash match results, from one kid's side
+const (
+	ClashWon  = "won"
+	ClashLost = "lost"
+	ClashDraw = "draw"
+)
+
+// ClashMatch is a head-to-head match between two kids on a list assigned to both
+type ClashMatch struct {
+	ID              int64
+	ListID          int64
+	ChallengerID    int64
+	OpponentID      int64
+	Status          string
+	TotalRounds     int
+	ChallengerScore int // Rounds won
+	OpponentScore   int
+	WinnerID        *int64 // nil for a draw or a match that didn't finish
+	CreatedAt       time.Time
+	StartedAt       *time.Time
+	FinishedAt      *time.Time
+}
+
+// ClashRival is a kid who can be challenged, named as the challenger may see them
+type ClashRival struct {
+	KidID       int64
+	DisplayName string
+	AvatarColor string
+}
+
+// ClashInvite is a challenge waiting to be accepted, sent or received by a kid
+type ClashInvite struct {
+	MatchID   int64
+	ListName  string
+	Rival     ClashRival
+	Incoming  bool // The kid was challenged, rather than sent the challenge
+	CreatedAt time.Time
+}
+
+// ClashHistoryEntry is a match a kid played, from their side
+type ClashHistoryEntry struct {
+	Match      ClashMatch
+	ListName   string
+	Rival      ClashRival
+	YourScore  int
+	TheirScore int
+	Result     string // ClashWon, ClashLost or ClashDraw; empty if cancelled
+}
+
+// ClashPlayer is one side of a live match
+type ClashPlayer struct {
+	Name        string `json:"name"`
+	AvatarColor string `json:"avatarColor"`
+	Score       int    `json:"score"`
+	Ready       bool   `json:"ready"` // Has the match page open
+}
+
+// ClashRoundResult is how the last round went
+type ClashRoundResult struct {
+	Word       string `json:"word"`
+	WinnerName string `json:"winnerName,omitempty"` // Empty when nobody spelt it right in time
+	TimeMs     int    `json:"timeMs,omitempty"`
+	YouWon     bool   `json:"youWon"`
+	YourAnswer string `json:"yourAnswer"`
+}
+
+// ClashState is a live match as one kid sees it, sent to their browser on every change
+type ClashState struct {
+	MatchID     int64             `json:"matchId"`
+	Status      string            `json:"status"`
+	Round       int               `json:"round"` // 0 until the first word is read out
+	TotalRounds int               `json:"totalRounds"`
+	RoundOpen   bool              `json:"roundOpen"`          // Answers are being taken
+	AudioURL    string            `json:"audioUrl,omitempty"` // Reads out the word to spell while the round is open
+	Answered    bool              `json:"answered"`           // This kid has answered the open round
+	You         ClashPlayer       `json:"you"`
+	Rival       ClashPlayer       `json:"rival"`
+	LastRound   *ClashRoundResult `json:"lastRound,omitempty"`
+	Result      string            `json:"result,omitempty"` // Set once finished
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// DigestSubscription records whether a user wants the weekly progress digest
+type DigestSubscription struct {
+	UserID     int64
+	Enabled    bool
+	LastSentAt *time.Time
+	UpdatedAt  time.Time
+}
+
+// KidActivity totals a kid's completed sessions across every game mode over a period
+type KidActivity struct {
+	Sessions       int
+	WordsPracticed int
+	Correct        int
+	Points         int
+}
+
+// Accuracy returns the percentage of words answered correctly, or 0 if none were practised
+func (a *KidActivity) Accuracy() int {
+	if a.WordsPracticed == 0 {
+		return 0
+	}
+	return a.Correct * 100 / a.WordsPracticed
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Outbox email statuses
+const (
+	EmailStatusPending = "pending" // Waiting to be sent, possibly after a failed attempt
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed" // Gave up after too many attempts
+)
+
+// OutboxEmail is a queued outbound email
+type OutboxEmail struct {
+	ID            int64
+	ToEmail       string
+	Subject       string
+	HTMLBody      string
+	TextBody      string
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     *string
+	InvitationID  *int64 // Set for invitation emails so their status can be updated
+	CreatedAt     time.Time
+	SentAt        *time.Time
+}
+
+// IsFailed reports whether delivery was given up on
+func (e *OutboxEmail) IsFailed() bool {
+	return e.Status == EmailStatusFailed
+}
+
+// EmailDeliveryAttempt records one attempt to deliver an outbox email
+type EmailDeliveryAttempt struct {
+	ID          int64
+	OutboxID    int64
+	Backend     string
+	Success     bool
+	Error       *string
+	AttemptedAt time.Time
+}
+
+// EmailQueueStats summarises the outbox for the admin dashboard
+type EmailQueueStats struct {
+	Pending       int
+	Retrying      int // Pending emails that have already failed at least once
+	Sent          int
+	Failed        int
+	OldestPending *time.Time
+}
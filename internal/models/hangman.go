@@ -46,3 +46,12 @@ type HangmanSession struct {
 	GamesWon       int
 	TotalPoints    int
 }
+
+// HangmanState represents persisted progress through a hangman session
+type HangmanState struct {
+	KidID          int64
+	SessionID      int64
+	CurrentWordIdx int
+	Words          []Word // Words for the session in play order
+	PointsSoFar    int
+}
@@ -14,6 +14,22 @@ type Kid struct {
 	UpdatedAt   time.Time
 }
 
+// KidSession is a child's login on a device
+type KidSession struct {
+	ID         string
+	KidID      int64
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	LastSeenAt *time.Time
+	UserAgent  string
+	IPAddress  string
+}
+
+// Handle identifies the session on the session management page without revealing its ID
+func (s *KidSession) Handle() string {
+	return SessionHandle(s.ID)
+}
+
 // KidWithStats combines a kid with their statistics
 type KidWithStats struct {
 	Kid                  Kid
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Leaderboard scopes
+const (
+	LeaderboardFamily     = "family"     // The kids in one family
+	LeaderboardRoster     = "roster"     // The kids linked to one teacher
+	LeaderboardAssignment = "assignment" // The kids one parent or teacher assigned a list to
+)
+
+// Leaderboard periods
+const (
+	LeaderboardWeek    = "week" // Since Monday
+	LeaderboardAllTime = "all"
+)
+
+// LeaderboardSettings is how a kid appears on leaderboards
+type LeaderboardSettings struct {
+	KidID           int64
+	Nickname        string // Shown on boards beyond the kid's family; empty for a generated one
+	HiddenByParent  bool
+	HiddenByTeacher bool
+	UpdatedAt       time.Time
+}
+
+// Hidden reports whether the kid has been taken off leaderboards
+func (s *LeaderboardSettings) Hidden() bool {
+	return s.HiddenByParent || s.HiddenByTeacher
+}
+
+// LeaderboardEntry is a kid's place on a leaderboard
+type LeaderboardEntry struct {
+	Rank        int // Kids on the same points share a rank
+	KidID       int64
+	DisplayName string // The kid's name on family boards and their nickname elsewhere
+	AvatarColor string
+	Points      int
+	IsViewer    bool // The kid looking at the board
+}
+
+// Leaderboard ranks kids in a scope by the points they scored in a period
+type Leaderboard struct {
+	Title   string
+	Scope   string
+	Period  string
+	Entries []LeaderboardEntry
+}
+
+// KidLeaderboardSettings is a kid with their leaderboard settings, for the
+// privacy controls parents and teachers see
+type KidLeaderboardSettings struct {
+	Kid             Kid
+	Settings        LeaderboardSettings
+	DefaultNickname string // Shown while Settings.Nickname is empty
+}
@@ -52,3 +52,12 @@ type MissingLetterSession struct {
 	GamesWon       int
 	TotalPoints    int
 }
+
+// MissingLetterState represents persisted progress through a missing letter session
+type MissingLetterState struct {
+	KidID          int64
+	SessionID      int64
+	CurrentWordIdx int
+	Words          []Word // Words for the session in play order
+	PointsSoFar    int
+}
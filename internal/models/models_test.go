@@ -188,3 +188,24 @@ func TestKidStatsCalculation(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		code   string
+		want   string
+		wantOK bool
+	}{
+		{code: "", want: DefaultLocale, wantOK: true},
+		{code: "en-GB", want: "en-GB", wantOK: true},
+		{code: "en_us", want: "en-US", wantOK: true},
+		{code: " FR-fr ", want: "fr-FR", wantOK: true},
+		{code: "xx-XX", want: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizeLocale(tt.code)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("NormalizeLocale(%q) = %q, %v, want %q, %v", tt.code, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -56,3 +56,20 @@ type PracticeState struct {
 	WordOrder    string // Comma-separated word IDs in randomized order
 }
 
+
+// WordSchedule tracks a kid's spaced-repetition (SM-2) progress for a single word
+type WordSchedule struct {
+	KidID          int64
+	WordID         int64
+	Repetitions    int     // Consecutive successful reviews
+	IntervalDays   int     // Days until the next review
+	EaseFactor     float64 // SM-2 easiness factor (minimum 1.3)
+	Lapses         int     // Number of times the word was forgotten
+	DueAt          time.Time
+	LastReviewedAt *time.Time
+}
+
+// IsDue reports whether the word should be practised at the given time
+func (s *WordSchedule) IsDue(now time.Time) bool {
+	return !s.DueAt.After(now)
+}
@@ -0,0 +1,104 @@
+package models
+
+import "time"
+
+// Spelling bee statuses
+const (
+	BeeRunning  = "running"
+	BeeFinished = "finished"
+)
+
+// Spelling bee answer modes
+const (
+	BeeTeacherMarks = "teacher" // Children spell out loud and the teacher marks them
+	BeeKidsType     = "devices" // Children type their answers on their own devices
+)
+
+// SpellingBee is a live bee a teacher hosts for some of their children on one list
+type SpellingBee struct {
+	ID             int64
+	TeacherUserID  int64
+	SpellingListID int64
+	ListName       string
+	MaxMisses      int // Wrong answers before a child is out
+	AnswerMode     string
+	Status         string
+	Version        int // Goes up on every change
+	CreatedAt      time.Time
+	FinishedAt     *time.Time
+}
+
+// IsRunning reports whether words are still being dictated
+func (b *SpellingBee) IsRunning() bool {
+	return b.Status == BeeRunning
+}
+
+// SpellingBeeParticipant is a child taking part in a bee
+type SpellingBeeParticipant struct {
+	ID             int64
+	BeeID          int64
+	KidID          int64
+	KidName        string
+	AvatarColor    string
+	TurnOrder      int
+	WordsCorrect   int
+	Misses         int
+	EliminatedTurn *int // The turn the child went out on, nil while they are still in
+	FinalPlace     *int // Set once the bee finishes
+}
+
+// IsOut reports whether the child has been eliminated
+func (p *SpellingBeeParticipant) IsOut() bool {
+	return p.EliminatedTurn != nil
+}
+
+// MissesLeft returns how many more words the child can get wrong and stay in
+func (p *SpellingBeeParticipant) MissesLeft(maxMisses int) int {
+	return max(maxMisses-p.Misses-1, 0)
+}
+
+// Place returns the child's final place, or 0 while the bee is running
+func (p *SpellingBeeParticipant) Place() int {
+	if p.FinalPlace == nil {
+		return 0
+	}
+	return *p.FinalPlace
+}
+
+// SpellingBeeTurn is one word dictated to one child in a bee
+type SpellingBeeTurn struct {
+	ID         int64
+	BeeID      int64
+	TurnNumber int
+	KidID      int64
+	WordID     *int64 // Nil if the word has since been deleted from the list
+	WordText   string
+	Answer     string
+	IsCorrect  *bool // Nil while the child is answering
+	AskedAt    time.Time
+	AnsweredAt *time.Time
+}
+
+// IsOpen reports whether the turn is waiting for an answer
+func (t *SpellingBeeTurn) IsOpen() bool {
+	return t.IsCorrect == nil
+}
+
+// SpellingBeeState is a bee with everything needed to host or follow it
+type SpellingBeeState struct {
+	Bee                SpellingBee
+	Participants       []SpellingBeeParticipant // In turn order, or by final place once finished
+	Turn               *SpellingBeeTurn         // The open turn, or the last one answered
+	Speller            *SpellingBeeParticipant  // The child the turn belongs to
+	Word               *Word                    // The turn's word with its audio, nil if deleted or for children
+	HasAudio           bool                     // The turn's word can be played, for children
+	HasDefinitionAudio bool                     // The turn's word's definition can be played, for children
+	WordsLeft          int                      // Words in the list not yet dictated
+}
+
+// KidSpellingBee is a bee a child took part in, from their side
+type KidSpellingBee struct {
+	Bee         SpellingBee
+	Participant SpellingBeeParticipant
+	TotalKids   int
+}
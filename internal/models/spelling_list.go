@@ -1,6 +1,47 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+// DefaultLocale is the locale used for lists that don't specify one
+const DefaultLocale = "en-GB"
+
+// Locale is a language and accent a spelling list can be spoken in
+type Locale struct {
+	Code string // BCP 47 tag, e.g. "en-GB"
+	Name string // Display name, e.g. "English (UK)"
+}
+
+// SupportedLocales lists the locales that can be chosen for a spelling list
+var SupportedLocales = []Locale{
+	{Code: "en-GB", Name: "English (UK)"},
+	{Code: "en-US", Name: "English (US)"},
+	{Code: "en-AU", Name: "English (Australia)"},
+	{Code: "en-CA", Name: "English (Canada)"},
+	{Code: "en-IE", Name: "English (Ireland)"},
+	{Code: "en-NZ", Name: "English (New Zealand)"},
+	{Code: "fr-FR", Name: "French"},
+	{Code: "de-DE", Name: "German"},
+	{Code: "es-ES", Name: "Spanish"},
+	{Code: "it-IT", Name: "Italian"},
+}
+
+// NormalizeLocale returns the canonical form of a supported locale code.
+// An empty code maps to DefaultLocale; unsupported codes return false.
+func NormalizeLocale(code string) (string, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), "_", "-")
+	if code == "" {
+		return DefaultLocale, true
+	}
+	for _, locale := range SupportedLocales {
+		if strings.EqualFold(locale.Code, code) {
+			return locale.Code, true
+		}
+	}
+	return "", false
+}
 
 // SpellingList represents a custom list of words to practice
 type SpellingList struct {
@@ -8,6 +49,7 @@ type SpellingList struct {
 	FamilyCode                 *string // Nullable for public lists
 	Name                       string
 	Description                string
+	Locale                     string // Language and accent for audio, e.g. "en-GB"
 	CreatedBy                  *int64 // Nullable for system-created lists
 	CreatedAt                  time.Time
 	UpdatedAt                  time.Time
@@ -29,6 +71,18 @@ type Word struct {
 	CreatedAt               time.Time
 }
 
+// SpellingWarning flags a word that isn't in the dictionary for its list's locale
+type SpellingWarning struct {
+	Word       string
+	Suggestion string // Closest dictionary spelling, empty if none was found
+}
+
+// WordValidation is the result of checking words before they are added to a list
+type WordValidation struct {
+	BadWords []string          // Words rejected by the bad words filter
+	Warnings []SpellingWarning // Words that may be misspelled
+}
+
 // ListAssignment represents the assignment of a list to a kid
 type ListAssignment struct {
 	ID               int64
@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// SpellingTest is a scheduled test on one list for a teacher's class.
+// Children can only sit the test while its window is open.
+type SpellingTest struct {
+	ID             int64
+	ClassID        int64
+	SpellingListID int64
+	ListName       string
+	CreatedBy      int64
+	OpensAt        time.Time
+	ClosesAt       time.Time
+	CreatedAt      time.Time
+	CompletedCount int // Only populated by class queries
+}
+
+// IsOpen reports whether children can sit the test at the given time
+func (t *SpellingTest) IsOpen(now time.Time) bool {
+	return !now.Before(t.OpensAt) && now.Before(t.ClosesAt)
+}
+
+// Status describes the test window at the given time: "scheduled", "open" or "closed"
+func (t *SpellingTest) Status(now time.Time) string {
+	switch {
+	case now.Before(t.OpensAt):
+		return "scheduled"
+	case t.IsOpen(now):
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// SpellingTestAttempt is a child's single attempt at a spelling test
+type SpellingTestAttempt struct {
+	ID           int64
+	TestID       int64
+	KidID        int64
+	TotalWords   int
+	CorrectWords int
+	StartedAt    time.Time
+	CompletedAt  *time.Time
+}
+
+// IsComplete reports whether the child has answered every word
+func (a *SpellingTestAttempt) IsComplete() bool {
+	return a.CompletedAt != nil
+}
+
+// Score returns the percentage of words spelled correctly, counting
+// unanswered words as wrong
+func (a *SpellingTestAttempt) Score() float64 {
+	if a.TotalWords == 0 {
+		return 0
+	}
+	return float64(a.CorrectWords) / float64(a.TotalWords) * 100
+}
+
+// SpellingTestAnswer is a child's one answer to a word in a test
+type SpellingTestAnswer struct {
+	ID         int64
+	AttemptID  int64
+	WordID     int64
+	WordText   string
+	Answer     string
+	IsCorrect  bool
+	AnsweredAt time.Time
+}
+
+// SpellingTestResult is one child's result for a test, shown to their teacher
+type SpellingTestResult struct {
+	Kid     Kid
+	Attempt *SpellingTestAttempt // Nil if the child has not started the test
+	Answers []SpellingTestAnswer
+}
+
+// KidSpellingTest is an open test shown on a child's dashboard
+type KidSpellingTest struct {
+	Test    SpellingTest
+	Attempt *SpellingTestAttempt // Nil if the child has not started the test
+}
+
+// SpellingTestProgress is where a child is in a test
+type SpellingTestProgress struct {
+	Test       *SpellingTest
+	Attempt    *SpellingTestAttempt
+	Word       *Word // The next word to dictate, nil once the attempt is complete
+	Number     int   // 1-based number of the next word
+	TotalWords int
+}
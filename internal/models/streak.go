@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// StreakSettings are a family's choices for how daily streaks are counted
+type StreakSettings struct {
+	FamilyCode  string
+	Timezone    string // IANA time zone name; empty for the server default
+	WeekendsOff bool   // Saturdays and Sundays without practice don't break a streak
+	UpdatedAt   time.Time
+}
+
+// StreakFreeze is a run of days off, such as a school holiday, that doesn't break
+// a streak
+type StreakFreeze struct {
+	ID         int64
+	FamilyCode string
+	Name       string
+	StartDate  string // First day off, as YYYY-MM-DD
+	EndDate    string // Last day off, as YYYY-MM-DD
+	CreatedAt  time.Time
+}
+
+// KidStreaks are a kid's daily and correct-answer streaks
+type KidStreaks struct {
+	CurrentDaily   int  // Consecutive days with a completed session, up to today
+	LongestDaily   int  // Best run of days ever
+	CurrentCorrect int  // Correct answers in a row since the last wrong one
+	ActiveToday    bool // Whether today already counts towards the streak
+	DayOff         bool // Whether today is a day off that can't break the streak
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// TeacherClass is a named group of a teacher's children, such as a class or reading group
+type TeacherClass struct {
+	ID            int64
+	TeacherUserID int64
+	Name          string
+	ArchivedAt    *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	MemberCount   int // Only populated by list queries
+	ListCount     int // Only populated by list queries
+}
+
+// IsArchived reports whether the class has been archived
+func (c *TeacherClass) IsArchived() bool {
+	return c.ArchivedAt != nil
+}
+
+// TeacherClassList is a spelling list assigned to a whole class
+type TeacherClassList struct {
+	ClassID    int64
+	ListID     int64
+	ListName   string
+	DueDate    *time.Time
+	AssignedAt time.Time
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// TwoFactor is a user's TOTP authenticator
+type TwoFactor struct {
+	UserID       int64
+	Secret       string
+	EnabledAt    *time.Time // Nil until enrolment is confirmed with a code
+	LastUsedStep int64      // The newest time step accepted, so codes can't be reused
+	CreatedAt    time.Time
+}
+
+// IsEnabled reports whether sign-ins need a code from the authenticator
+func (t *TwoFactor) IsEnabled() bool {
+	return t != nil && t.EnabledAt != nil
+}
+
+// LoginChallenge is a sign-in that has passed its first step and is waiting for a
+// two-factor code before a session is issued
+type LoginChallenge struct {
+	ID        string
+	UserID    int64
+	Attempts  int
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// IsExpired checks if the challenge has expired
+func (c *LoginChallenge) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
+
+// TwoFactorPolicy is who an admin requires to use two-factor authentication
+type TwoFactorPolicy string
+
+const (
+	TwoFactorOptional TwoFactorPolicy = "optional"
+	TwoFactorAdmins   TwoFactorPolicy = "admins"
+	TwoFactorEveryone TwoFactorPolicy = "everyone"
+)
+
+// IsValid reports whether the policy is one of the known values
+func (p TwoFactorPolicy) IsValid() bool {
+	return p == TwoFactorOptional || p == TwoFactorAdmins || p == TwoFactorEveryone
+}
+
+// Requires reports whether the policy makes a user set up two-factor authentication
+func (p TwoFactorPolicy) Requires(user *User) bool {
+	switch p {
+	case TwoFactorEveryone:
+		return true
+	case TwoFactorAdmins:
+		return user.IsAdmin
+	default:
+		return false
+	}
+}
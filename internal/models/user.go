@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
 
 // User represents a parent account in the system
 type User struct {
@@ -8,20 +12,38 @@ type User struct {
 	Email        string
 	PasswordHash string
 	Name         string
-	OAuthProvider string
-	OAuthSubject  string
 	IsAdmin      bool
 	IsTeacher    bool
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
 
+// HasPassword reports whether the user can sign in with a password. Accounts created
+// through a sign-in provider have no password until they set one.
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// UserIdentity links a user to their account with an OAuth or OpenID Connect provider
+type UserIdentity struct {
+	ID         int64
+	UserID     int64
+	Provider   string
+	Subject    string
+	Email      string // The provider's email address for the account when it was last used
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
 // Session represents an authenticated session
 type Session struct {
-	ID        string
-	UserID    int64
-	ExpiresAt time.Time
-	CreatedAt time.Time
+	ID         string
+	UserID     int64
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	LastSeenAt *time.Time
+	UserAgent  string
+	IPAddress  string
 }
 
 // IsExpired checks if the session has expired
@@ -29,6 +51,18 @@ func (s *Session) IsExpired() bool {
 	return time.Now().After(s.ExpiresAt)
 }
 
+// Handle identifies the session on the session management page without revealing its ID
+func (s *Session) Handle() string {
+	return SessionHandle(s.ID)
+}
+
+// SessionHandle returns a stable identifier for a session ID that can be shown in
+// pages and URLs. The ID itself is a bearer credential.
+func SessionHandle(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:8])
+}
+
 // PasswordResetToken represents a token for password reset
 type PasswordResetToken struct {
 	Token     string
@@ -42,3 +76,15 @@ type PasswordResetToken struct {
 func (t *PasswordResetToken) IsExpired() bool {
 	return time.Now().After(t.ExpiresAt)
 }
+
+// APIToken is a personal access token for the JSON API. The token itself is
+// only shown once when it is created; TokenPrefix identifies it afterwards.
+type APIToken struct {
+	ID          int64
+	UserID      int64
+	Name        string
+	TokenHash   string
+	TokenPrefix string
+	CreatedAt   time.Time
+	LastUsedAt  *time.Time
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// AssignmentCompletion is a finished session on a list assigned to a kid with a due date
+type AssignmentCompletion struct {
+	ListID      int64
+	AssignedAt  time.Time
+	DueDate     time.Time
+	CompletedAt time.Time
+}
+
+// AchievementRepository handles kids' unlocked achievements and the history they are earned from
+type AchievementRepository struct {
+	db *database.DB
+}
+
+// NewAchievementRepository creates a new achievement repository
+func NewAchievementRepository(db *database.DB) *AchievementRepository {
+	return &AchievementRepository{db: db}
+}
+
+// GetKidAchievements lists the achievements a kid has unlocked, oldest first
+func (r *AchievementRepository) GetKidAchievements(kidID int64) ([]models.KidAchievement, error) {
+	query := `
+		SELECT id, kid_id, achievement_key, unlocked_at, seen_at
+		FROM achievements
+		WHERE kid_id = ?
+		ORDER BY unlocked_at, id
+	`
+
+	rows, err := r.db.Query(query, kidID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get achievements: %w", err)
+	}
+	defer rows.Close()
+
+	var achievements []models.KidAchievement
+	for rows.Next() {
+		var achievement models.KidAchievement
+		if err := rows.Scan(&achievement.ID, &achievement.KidID, &achievement.AchievementKey, &achievement.UnlockedAt, &achievement.SeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan achievement: %w", err)
+		}
+		achievements = append(achievements, achievement)
+	}
+	return achievements, rows.Err()
+}
+
+// Unlock records a kid unlocking an achievement, reporting false if they already had it
+func (r *AchievementRepository) Unlock(kidID int64, key string, now time.Time) (bool, error) {
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM achievements WHERE kid_id = ? AND achievement_key = ?", kidID, key).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check achievement: %w", err)
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	if _, err := r.db.Exec(
+		"INSERT INTO achievements (kid_id, achievement_key, unlocked_at) VALUES (?, ?, ?)",
+		kidID, key, now,
+	); err != nil {
+		// Another request may have unlocked it first
+		if err := r.db.QueryRow("SELECT COUNT(*) FROM achievements WHERE kid_id = ? AND achievement_key = ?", kidID, key).Scan(&count); err == nil && count > 0 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to unlock achievement: %w", err)
+	}
+	return true, nil
+}
+
+// MarkSeen records that a kid has been shown all their unlocked achievements
+func (r *AchievementRepository) MarkSeen(kidID int64, now time.Time) error {
+	if _, err := r.db.Exec("UPDATE achievements SET seen_at = ? WHERE kid_id = ? AND seen_at IS NULL", now, kidID); err != nil {
+		return fmt.Errorf("failed to mark achievements seen: %w", err)
+	}
+	return nil
+}
+
+// finishedSession returns a condition on sessions aliased s that is true when
+// every word in the session was played, rather than the kid leaving early
+func finishedSession(src activitySource) string {
+	return fmt.Sprintf(
+		"s.completed_at IS NOT NULL AND s.%[1]s > 0 AND (SELECT COUNT(*) FROM %[2]s i WHERE i.%[3]s = s.id AND i.%[4]s IS NOT NULL) >= s.%[1]s",
+		src.total, src.items, src.sessionFK, src.answeredAt,
+	)
+}
+
+// CountPerfectSessions counts the sessions in any game mode a kid finished
+// without getting a word wrong
+func (r *AchievementRepository) CountPerfectSessions(kidID int64) (int, error) {
+	total := 0
+	for _, src := range activitySources {
+		query := fmt.Sprintf(`
+			SELECT COUNT(*)
+			FROM %[1]s s
+			WHERE s.kid_id = ? AND %[2]s
+			AND (SELECT COUNT(*) FROM %[3]s i WHERE i.%[4]s = s.id AND i.%[5]s = TRUE) >= s.%[6]s
+		`, src.sessions, finishedSession(src), src.items, src.sessionFK, src.correct, src.total)
+
+		var count int
+		if err := r.db.QueryRow(query, kidID).Scan(&count); err != nil {
+			return 0, fmt.Errorf("failed to count perfect %s: %w", src.sessions, err)
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// CountMasteredWords counts the words a kid has reviewed correctly at least
+// minRepetitions times in a row
+func (r *AchievementRepository) CountMasteredWords(kidID int64, minRepetitions int) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM word_schedules WHERE kid_id = ? AND repetitions >= ?", kidID, minRepetitions).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count mastered words: %w", err)
+	}
+	return count, nil
+}
+
+// CountFlawlessHangmanGames counts the hangman games a kid won without a wrong guess
+func (r *AchievementRepository) CountFlawlessHangmanGames(kidID int64) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM hangman_games WHERE kid_id = ? AND is_won = TRUE AND wrong_guesses = 0", kidID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count flawless hangman games: %w", err)
+	}
+	return count, nil
+}
+
+// GetAssignmentCompletions lists the sessions in any game mode a kid finished on
+// lists assigned to them with a due date
+func (r *AchievementRepository) GetAssignmentCompletions(kidID int64) ([]AssignmentCompletion, error) {
+	var completions []AssignmentCompletion
+	for _, src := range activitySources {
+		query := fmt.Sprintf(`
+			SELECT la.spelling_list_id, la.assigned_at, la.due_date, s.completed_at
+			FROM list_assignments la
+			JOIN %[1]s s ON s.kid_id = la.kid_id AND s.spelling_list_id = la.spelling_list_id
+			WHERE la.kid_id = ? AND la.due_date IS NOT NULL AND %[2]s
+		`, src.sessions, finishedSession(src))
+
+		rows, err := r.db.Query(query, kidID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query assignment completions in %s: %w", src.sessions, err)
+		}
+		for rows.Next() {
+			var completion AssignmentCompletion
+			if err := rows.Scan(&completion.ListID, &completion.AssignedAt, &completion.DueDate, &completion.CompletedAt); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan assignment completion: %w", err)
+			}
+			completions = append(completions, completion)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return completions, nil
+}
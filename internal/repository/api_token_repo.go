@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// APITokenRepository handles API token data operations
+type APITokenRepository struct {
+	db *database.DB
+}
+
+// NewAPITokenRepository creates a new API token repository
+func NewAPITokenRepository(db *database.DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+// CreateToken stores a new API token by its hash
+func (r *APITokenRepository) CreateToken(userID int64, name, tokenHash, tokenPrefix string) (*models.APIToken, error) {
+	query := `
+		INSERT INTO api_tokens (user_id, name, token_hash, token_prefix)
+		VALUES (?, ?, ?, ?)
+	`
+	id, err := r.db.ExecReturningID(query, userID, name, tokenHash, tokenPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API token: %w", err)
+	}
+
+	return &models.APIToken{
+		ID:          id,
+		UserID:      userID,
+		Name:        name,
+		TokenHash:   tokenHash,
+		TokenPrefix: tokenPrefix,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// GetTokenByHash retrieves an API token by the hash of its value
+func (r *APITokenRepository) GetTokenByHash(tokenHash string) (*models.APIToken, error) {
+	query := `
+		SELECT id, user_id, name, token_hash, token_prefix, created_at, last_used_at
+		FROM api_tokens
+		WHERE token_hash = ?
+	`
+	token, err := scanAPIToken(r.db.QueryRow(query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+	return token, nil
+}
+
+// GetUserTokens retrieves all API tokens belonging to a user, newest first
+func (r *APITokenRepository) GetUserTokens(userID int64) ([]models.APIToken, error) {
+	query := `
+		SELECT id, user_id, name, token_hash, token_prefix, created_at, last_used_at
+		FROM api_tokens
+		WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []models.APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+// CountUserTokens returns the number of API tokens a user has
+func (r *APITokenRepository) CountUserTokens(userID int64) (int, error) {
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM api_tokens WHERE user_id = ?", userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count API tokens: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteUserToken deletes one of a user's API tokens, reporting whether it existed
+func (r *APITokenRepository) DeleteUserToken(tokenID, userID int64) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM api_tokens WHERE id = ? AND user_id = ?", tokenID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete API token: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete API token: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// DeleteUserTokens deletes all of a user's API tokens, returning how many there were
+func (r *APITokenRepository) DeleteUserTokens(userID int64) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM api_tokens WHERE user_id = ?", userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete API tokens: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// UpdateLastUsed records when an API token was last used
+func (r *APITokenRepository) UpdateLastUsed(tokenID int64, usedAt time.Time) error {
+	if _, err := r.db.Exec("UPDATE api_tokens SET last_used_at = ? WHERE id = ?", usedAt, tokenID); err != nil {
+		return fmt.Errorf("failed to update API token last used time: %w", err)
+	}
+	return nil
+}
+
+// scanAPIToken scans an api_tokens row into a model
+func scanAPIToken(row rowScanner) (*models.APIToken, error) {
+	var token models.APIToken
+	var lastUsedAt sql.NullTime
+	if err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenHash,
+		&token.TokenPrefix,
+		&token.CreatedAt,
+		&lastUsedAt,
+	); err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	return &token, nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// clashMatchColumns are selected from clash_matches aliased cm
+const clashMatchColumns = `cm.id, cm.spelling_list_id, cm.challenger_kid_id, cm.opponent_kid_id, cm.status, cm.total_rounds,
+	cm.challenger_score, cm.opponent_score, cm.winner_kid_id, cm.created_at, cm.started_at, cm.finished_at`
+
+// ClashMatchRow is a clash match with the name of the list it was played on
+type ClashMatchRow struct {
+	models.ClashMatch
+	ListName string
+}
+
+// ClashRepository handles the history of head-to-head clash matches
+type ClashRepository struct {
+	db *database.DB
+}
+
+// NewClashRepository creates a new clash repository
+func NewClashRepository(db *database.DB) *ClashRepository {
+	return &ClashRepository{db: db}
+}
+
+// CreateMatch records a new challenge, waiting for the opponent to accept
+func (r *ClashRepository) CreateMatch(listID, challengerID, opponentID int64, now time.Time) (*models.ClashMatch, error) {
+	id, err := r.db.ExecReturningID(
+		"INSERT INTO clash_matches (spelling_list_id, challenger_kid_id, opponent_kid_id, status, created_at) VALUES (?, ?, ?, ?, ?)",
+		listID, challengerID, opponentID, models.ClashWaiting, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clash match: %w", err)
+	}
+	return &models.ClashMatch{
+		ID:           id,
+		ListID:       listID,
+		ChallengerID: challengerID,
+		OpponentID:   opponentID,
+		Status:       models.ClashWaiting,
+		CreatedAt:    now,
+	}, nil
+}
+
+func scanClashMatch(scan func(dest ...interface{}) error, extra ...interface{}) (models.ClashMatch, error) {
+	var match models.ClashMatch
+	var winnerID sql.NullInt64
+	var startedAt, finishedAt sql.NullTime
+	dest := []interface{}{
+		&match.ID, &match.ListID, &match.ChallengerID, &match.OpponentID, &match.Status, &match.TotalRounds,
+		&match.ChallengerScore, &match.OpponentScore, &winnerID, &match.CreatedAt, &startedAt, &finishedAt,
+	}
+	if err := scan(append(dest, extra...)...); err != nil {
+		return match, err
+	}
+	if winnerID.Valid {
+		match.WinnerID = &winnerID.Int64
+	}
+	if startedAt.Valid {
+		match.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		match.FinishedAt = &finishedAt.Time
+	}
+	return match, nil
+}
+
+// GetMatch returns a clash match, or nil if it doesn't exist
+func (r *ClashRepository) GetMatch(matchID int64) (*models.ClashMatch, error) {
+	row := r.db.QueryRow("SELECT "+clashMatchColumns+" FROM clash_matches cm WHERE cm.id = ?", matchID)
+	match, err := scanClashMatch(row.Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clash match: %w", err)
+	}
+	return &match, nil
+}
+
+// StartMatch records an accepted challenge starting with the given number of rounds
+func (r *ClashRepository) StartMatch(matchID int64, totalRounds int, now time.Time) error {
+	if _, err := r.db.Exec(
+		"UPDATE clash_matches SET status = ?, total_rounds = ?, started_at = ? WHERE id = ?",
+		models.ClashPlaying, totalRounds, now, matchID,
+	); err != nil {
+		return fmt.Errorf("failed to start clash match: %w", err)
+	}
+	return nil
+}
+
+// FinishMatch records the final scores of a match
+func (r *ClashRepository) FinishMatch(matchID int64, challengerScore, opponentScore int, winnerID *int64, now time.Time) error {
+	var winner interface{}
+	if winnerID != nil {
+		winner = *winnerID
+	}
+	if _, err := r.db.Exec(
+		"UPDATE clash_matches SET status = ?, challenger_score = ?, opponent_score = ?, winner_kid_id = ?, finished_at = ? WHERE id = ?",
+		models.ClashFinished, challengerScore, opponentScore, winner, now, matchID,
+	); err != nil {
+		return fmt.Errorf("failed to finish clash match: %w", err)
+	}
+	return nil
+}
+
+// CancelMatch records a match ending without a result
+func (r *ClashRepository) CancelMatch(matchID int64, now time.Time) error {
+	if _, err := r.db.Exec(
+		"UPDATE clash_matches SET status = ?, finished_at = ? WHERE id = ?",
+		models.ClashCancelled, now, matchID,
+	); err != nil {
+		return fmt.Errorf("failed to cancel clash match: %w", err)
+	}
+	return nil
+}
+
+// CancelUnfinished cancels every match left waiting or playing, such as those
+// cut short by the server restarting
+func (r *ClashRepository) CancelUnfinished(now time.Time) (int64, error) {
+	result, err := r.db.Exec(
+		"UPDATE clash_matches SET status = ?, finished_at = ? WHERE status IN (?, ?)",
+		models.ClashCancelled, now, models.ClashWaiting, models.ClashPlaying,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel unfinished clash matches: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetKidMatches lists the matches a kid challenged or was challenged to, newest
+// first, optionally only those with the given statuses
+func (r *ClashRepository) GetKidMatches(kidID int64, limit int, statuses ...string) ([]ClashMatchRow, error) {
+	query := `
+		SELECT ` + clashMatchColumns + `, sl.name
+		FROM clash_matches cm
+		JOIN spelling_lists sl ON sl.id = cm.spelling_list_id
+		WHERE (cm.challenger_kid_id = ? OR cm.opponent_kid_id = ?)`
+	args := []interface{}{kidID, kidID}
+	if len(statuses) > 0 {
+		query += " AND cm.status IN (" + generatePlaceholders(len(statuses)) + ")"
+		for _, status := range statuses {
+			args = append(args, status)
+		}
+	}
+	query += " ORDER BY cm.created_at DESC, cm.id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clash matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []ClashMatchRow
+	for rows.Next() {
+		var row ClashMatchRow
+		match, err := scanClashMatch(rows.Scan, &row.ListName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan clash match: %w", err)
+		}
+		row.ClashMatch = match
+		matches = append(matches, row)
+	}
+	return matches, rows.Err()
+}
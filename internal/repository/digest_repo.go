@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// DigestRepository handles weekly digest subscriptions and the activity totals they report
+type DigestRepository struct {
+	db *database.DB
+}
+
+// NewDigestRepository creates a new digest repository
+func NewDigestRepository(db *database.DB) *DigestRepository {
+	return &DigestRepository{db: db}
+}
+
+// GetSubscription retrieves a user's digest subscription, or nil if they have never set one
+func (r *DigestRepository) GetSubscription(userID int64) (*models.DigestSubscription, error) {
+	query := `SELECT user_id, enabled, last_sent_at, updated_at FROM digest_subscriptions WHERE user_id = ?`
+
+	var sub models.DigestSubscription
+	var lastSentAt sql.NullTime
+	err := r.db.QueryRow(query, userID).Scan(&sub.UserID, &sub.Enabled, &lastSentAt, &sub.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest subscription: %w", err)
+	}
+	if lastSentAt.Valid {
+		sub.LastSentAt = &lastSentAt.Time
+	}
+	return &sub, nil
+}
+
+// SetSubscription turns a user's digest on or off. Subscribing counts as having
+// just been sent a digest, so the first one arrives at the next scheduled time
+// rather than straight away.
+func (r *DigestRepository) SetSubscription(userID int64, enabled bool, now time.Time) error {
+	query := "UPDATE digest_subscriptions SET enabled = ?, updated_at = ? WHERE user_id = ?"
+	args := []interface{}{enabled, now, userID}
+	if enabled {
+		query = "UPDATE digest_subscriptions SET enabled = ?, updated_at = ?, last_sent_at = ? WHERE user_id = ?"
+		args = []interface{}{enabled, now, now, userID}
+	}
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update digest subscription: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+		return nil
+	}
+
+	if _, err := r.db.Exec(
+		"INSERT INTO digest_subscriptions (user_id, enabled, last_sent_at, updated_at) VALUES (?, ?, ?, ?)",
+		userID, enabled, now, now,
+	); err != nil {
+		return fmt.Errorf("failed to create digest subscription: %w", err)
+	}
+	return nil
+}
+
+// GetDueSubscriptions lists the enabled subscriptions that haven't had a digest since due
+func (r *DigestRepository) GetDueSubscriptions(due time.Time) ([]models.DigestSubscription, error) {
+	query := `
+		SELECT user_id, enabled, last_sent_at, updated_at
+		FROM digest_subscriptions
+		WHERE enabled = TRUE AND (last_sent_at IS NULL OR NOT ` + r.db.Dialect.TimestampAtOrAfter("last_sent_at") + `)
+		ORDER BY user_id
+	`
+	rows, err := r.db.Query(query, due)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due digests: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []models.DigestSubscription
+	for rows.Next() {
+		var sub models.DigestSubscription
+		var lastSentAt sql.NullTime
+		if err := rows.Scan(&sub.UserID, &sub.Enabled, &lastSentAt, &sub.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan digest subscription: %w", err)
+		}
+		if lastSentAt.Valid {
+			sub.LastSentAt = &lastSentAt.Time
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// ClaimDigest marks a user's digest as sent at now, reporting false if another
+// server has already sent it since due
+func (r *DigestRepository) ClaimDigest(userID int64, due, now time.Time) (bool, error) {
+	query := `
+		UPDATE digest_subscriptions SET last_sent_at = ?
+		WHERE user_id = ? AND enabled = TRUE AND (last_sent_at IS NULL OR NOT ` + r.db.Dialect.TimestampAtOrAfter("last_sent_at") + `)
+	`
+	result, err := r.db.Exec(query, now, userID, due)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim digest: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim digest: %w", err)
+	}
+	return affected == 1, nil
+}
+
+// activitySource describes a game mode's session and per-word tables
+type activitySource struct {
+	sessions   string
+	items      string
+	sessionFK  string
+	correct    string
+	answeredAt string
+	total      string // Session column holding the number of words in the session
+}
+
+var activitySources = []activitySource{
+	{"practice_sessions", "word_attempts", "practice_session_id", "is_correct", "attempted_at", "total_words"},
+	{"hangman_sessions", "hangman_games", "session_id", "is_won", "completed_at", "total_games"},
+	{"missing_letter_sessions", "missing_letter_games", "session_id", "is_won", "completed_at", "total_games"},
+}
+
+// GetKidActivity totals the sessions a kid completed in [from, to) across practice,
+// hangman and missing letter
+func (r *DigestRepository) GetKidActivity(kidID int64, from, to time.Time) (*models.KidActivity, error) {
+	var activity models.KidActivity
+	for _, src := range activitySources {
+		query := fmt.Sprintf(`
+			SELECT
+				COUNT(DISTINCT s.id),
+				COUNT(i.id),
+				COALESCE(SUM(CASE WHEN i.%s = TRUE THEN 1 ELSE 0 END), 0),
+				COALESCE(SUM(i.points_earned), 0)
+			FROM %s s
+			LEFT JOIN %s i ON s.id = i.%s
+			WHERE s.kid_id = ? AND s.completed_at IS NOT NULL AND %s AND NOT %s
+		`, src.correct, src.sessions, src.items, src.sessionFK,
+			r.db.Dialect.TimestampAtOrAfter("s.completed_at"), r.db.Dialect.TimestampAtOrAfter("s.completed_at"))
+
+		var sessions, words, correct, points int
+		if err := r.db.QueryRow(query, kidID, from, to).Scan(&sessions, &words, &correct, &points); err != nil {
+			return nil, fmt.Errorf("failed to total %s: %w", src.sessions, err)
+		}
+		activity.Sessions += sessions
+		activity.WordsPracticed += words
+		activity.Correct += correct
+		activity.Points += points
+	}
+	return &activity, nil
+}
@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// EmailOutboxRepository handles the queue of outbound emails and their delivery attempts
+type EmailOutboxRepository struct {
+	db *database.DB
+}
+
+// NewEmailOutboxRepository creates a new email outbox repository
+func NewEmailOutboxRepository(db *database.DB) *EmailOutboxRepository {
+	return &EmailOutboxRepository{db: db}
+}
+
+const outboxEmailColumns = `id, to_email, subject, html_body, text_body, status, attempts, next_attempt_at,
+	last_error, invitation_id, created_at, sent_at`
+
+// EnqueueEmail queues an email to be sent as soon as possible
+func (r *EmailOutboxRepository) EnqueueEmail(toEmail, subject, htmlBody, textBody string, invitationID *int64) (*models.OutboxEmail, error) {
+	now := time.Now()
+	query := `
+		INSERT INTO email_outbox (to_email, subject, html_body, text_body, status, next_attempt_at, invitation_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	id, err := r.db.ExecReturningID(query, toEmail, subject, htmlBody, textBody, models.EmailStatusPending, now, invitationID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to queue email: %w", err)
+	}
+
+	return &models.OutboxEmail{
+		ID:            id,
+		ToEmail:       toEmail,
+		Subject:       subject,
+		HTMLBody:      htmlBody,
+		TextBody:      textBody,
+		Status:        models.EmailStatusPending,
+		NextAttemptAt: now,
+		InvitationID:  invitationID,
+		CreatedAt:     now,
+	}, nil
+}
+
+// ClaimDueEmails leases up to limit pending emails that are due by now, pushing
+// their next attempt back to leaseUntil so another worker won't pick them up
+// while they are being sent. If the worker dies mid-send the email becomes due
+// again once the lease runs out.
+func (r *EmailOutboxRepository) ClaimDueEmails(now, leaseUntil time.Time, limit int) ([]models.OutboxEmail, error) {
+	query := `SELECT ` + outboxEmailColumns + `
+		FROM email_outbox
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at ASC, id ASC
+		LIMIT ?
+	`
+	rows, err := r.db.Query(query, models.EmailStatusPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due emails: %w", err)
+	}
+	var due []models.OutboxEmail
+	for rows.Next() {
+		email, err := scanOutboxEmail(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan queued email: %w", err)
+		}
+		due = append(due, *email)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var claimed []models.OutboxEmail
+	for _, email := range due {
+		result, err := r.db.Exec(
+			"UPDATE email_outbox SET next_attempt_at = ? WHERE id = ? AND status = ? AND next_attempt_at <= ?",
+			leaseUntil, email.ID, models.EmailStatusPending, now,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim queued email: %w", err)
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+			continue // Claimed by another worker
+		}
+		email.NextAttemptAt = leaseUntil
+		claimed = append(claimed, email)
+	}
+
+	return claimed, nil
+}
+
+// RecordAttempt logs a delivery attempt and updates the email's status.
+// For a failed attempt, status is pending (with nextAttemptAt set) or failed.
+func (r *EmailOutboxRepository) RecordAttempt(emailID int64, backend string, attemptErr error, status string, nextAttemptAt, attemptedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin email attempt transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var errorMsg *string
+	if attemptErr != nil {
+		msg := attemptErr.Error()
+		errorMsg = &msg
+	}
+
+	if _, err := tx.Exec(
+		"INSERT INTO email_delivery_attempts (outbox_id, backend, success, error, attempted_at) VALUES (?, ?, ?, ?, ?)",
+		emailID, backend, attemptErr == nil, errorMsg, attemptedAt,
+	); err != nil {
+		return fmt.Errorf("failed to record email attempt: %w", err)
+	}
+
+	var sentAt *time.Time
+	if attemptErr == nil {
+		sentAt = &attemptedAt
+	}
+	query := `
+		UPDATE email_outbox
+		SET status = ?, attempts = attempts + 1, next_attempt_at = ?, last_error = COALESCE(?, last_error), sent_at = ?
+		WHERE id = ?
+	`
+	if _, err := tx.Exec(query, status, nextAttemptAt, errorMsg, sentAt, emailID); err != nil {
+		return fmt.Errorf("failed to update queued email: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit email attempt: %w", err)
+	}
+	return nil
+}
+
+// RetryEmail puts a failed email back in the queue to be sent straight away.
+// It reports whether the email was found and had failed.
+func (r *EmailOutboxRepository) RetryEmail(emailID int64, now time.Time) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE email_outbox SET status = ?, next_attempt_at = ? WHERE id = ? AND status = ?",
+		models.EmailStatusPending, now, emailID, models.EmailStatusFailed,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to retry email: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to retry email: %w", err)
+	}
+	return affected == 1, nil
+}
+
+// GetQueueStats counts the emails in each state
+func (r *EmailOutboxRepository) GetQueueStats() (*models.EmailQueueStats, error) {
+	query := `
+		SELECT
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = ? AND attempts > 0 THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0)
+		FROM email_outbox
+	`
+	var stats models.EmailQueueStats
+	if err := r.db.QueryRow(query,
+		models.EmailStatusPending, models.EmailStatusPending, models.EmailStatusSent, models.EmailStatusFailed,
+	).Scan(&stats.Pending, &stats.Retrying, &stats.Sent, &stats.Failed); err != nil {
+		return nil, fmt.Errorf("failed to count queued emails: %w", err)
+	}
+
+	var oldest sql.NullTime
+	err := r.db.QueryRow(
+		"SELECT created_at FROM email_outbox WHERE status = ? ORDER BY created_at ASC, id ASC LIMIT 1",
+		models.EmailStatusPending,
+	).Scan(&oldest)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get oldest queued email: %w", err)
+	}
+	if oldest.Valid {
+		stats.OldestPending = &oldest.Time
+	}
+
+	return &stats, nil
+}
+
+// GetRecentEmails retrieves the most recently queued emails that haven't been sent,
+// along with the most recent sent ones, newest first
+func (r *EmailOutboxRepository) GetRecentEmails(limit int) ([]models.OutboxEmail, error) {
+	query := `SELECT ` + outboxEmailColumns + `
+		FROM email_outbox
+		ORDER BY CASE WHEN status = ? THEN 1 ELSE 0 END ASC, id DESC
+		LIMIT ?
+	`
+	rows, err := r.db.Query(query, models.EmailStatusSent, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent emails: %w", err)
+	}
+	defer rows.Close()
+
+	var emails []models.OutboxEmail
+	for rows.Next() {
+		email, err := scanOutboxEmail(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan queued email: %w", err)
+		}
+		emails = append(emails, *email)
+	}
+
+	return emails, rows.Err()
+}
+
+// GetEmailAttempts retrieves the delivery attempts for an email, oldest first
+func (r *EmailOutboxRepository) GetEmailAttempts(emailID int64) ([]models.EmailDeliveryAttempt, error) {
+	query := `
+		SELECT id, outbox_id, backend, success, error, attempted_at
+		FROM email_delivery_attempts
+		WHERE outbox_id = ?
+		ORDER BY id ASC
+	`
+	rows, err := r.db.Query(query, emailID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query email attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []models.EmailDeliveryAttempt
+	for rows.Next() {
+		var attempt models.EmailDeliveryAttempt
+		var errorMsg sql.NullString
+		if err := rows.Scan(&attempt.ID, &attempt.OutboxID, &attempt.Backend, &attempt.Success, &errorMsg, &attempt.AttemptedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan email attempt: %w", err)
+		}
+		if errorMsg.Valid {
+			attempt.Error = &errorMsg.String
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, rows.Err()
+}
+
+// DeleteFinishedEmails removes sent and failed emails queued before the cutoff,
+// along with their attempts, so old message bodies and links aren't kept forever
+func (r *EmailOutboxRepository) DeleteFinishedEmails(before time.Time) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin email cleanup transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"DELETE FROM email_delivery_attempts WHERE outbox_id IN (SELECT id FROM email_outbox WHERE status <> ? AND created_at < ?)",
+		models.EmailStatusPending, before,
+	); err != nil {
+		return 0, fmt.Errorf("failed to delete old email attempts: %w", err)
+	}
+	result, err := tx.Exec("DELETE FROM email_outbox WHERE status <> ? AND created_at < ?", models.EmailStatusPending, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old emails: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old emails: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit email cleanup: %w", err)
+	}
+	return deleted, nil
+}
+
+func scanOutboxEmail(row rowScanner) (*models.OutboxEmail, error) {
+	var email models.OutboxEmail
+	var lastError sql.NullString
+	var invitationID sql.NullInt64
+	var sentAt sql.NullTime
+	if err := row.Scan(
+		&email.ID,
+		&email.ToEmail,
+		&email.Subject,
+		&email.HTMLBody,
+		&email.TextBody,
+		&email.Status,
+		&email.Attempts,
+		&email.NextAttemptAt,
+		&lastError,
+		&invitationID,
+		&email.CreatedAt,
+		&sentAt,
+	); err != nil {
+		return nil, err
+	}
+	if lastError.Valid {
+		email.LastError = &lastError.String
+	}
+	if invitationID.Valid {
+		email.InvitationID = &invitationID.Int64
+	}
+	if sentAt.Valid {
+		email.SentAt = &sentAt.Time
+	}
+	return &email, nil
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// HangmanRepository handles hangman session, game and state database operations
+type HangmanRepository struct {
+	db *database.DB
+}
+
+// NewHangmanRepository creates a new hangman repository
+func NewHangmanRepository(db *database.DB) *HangmanRepository {
+	return &HangmanRepository{db: db}
+}
+
+// CreateSession creates a new hangman session and returns its ID
+func (r *HangmanRepository) CreateSession(kidID, listID int64, totalWords int) (int64, error) {
+	query := `INSERT INTO hangman_sessions (kid_id, spelling_list_id, started_at, total_games, games_won, total_points)
+			  VALUES (?, ?, ?, ?, 0, 0)`
+	return r.db.ExecReturningID(query, kidID, listID, time.Now(), totalWords)
+}
+
+// CreateGame creates a new hangman game for a word and returns its ID
+func (r *HangmanRepository) CreateGame(sessionID, kidID, wordID int64, word string, maxWrongGuesses int) (int64, error) {
+	query := `INSERT INTO hangman_games (session_id, kid_id, word_id, word, guessed_letters, wrong_guesses, max_wrong_guesses, started_at)
+			  VALUES (?, ?, ?, ?, ?, 0, ?, ?)`
+	return r.db.ExecReturningID(query, sessionID, kidID, wordID, word, "[]", maxWrongGuesses, time.Now())
+}
+
+// SaveGameProgress stores the guesses made so far in a game
+func (r *HangmanRepository) SaveGameProgress(gameID int64, guessedLetters []string, wrongGuesses int, isWon, isLost bool) error {
+	lettersJSON, err := json.Marshal(guessedLetters)
+	if err != nil {
+		return fmt.Errorf("failed to encode guessed letters: %w", err)
+	}
+
+	query := `UPDATE hangman_games SET guessed_letters = ?, wrong_guesses = ?, is_won = ?, is_lost = ?
+			  WHERE id = ?`
+	_, err = r.db.Exec(query, string(lettersJSON), wrongGuesses, isWon, isLost, gameID)
+	return err
+}
+
+// CompleteGame records the final result of a game
+func (r *HangmanRepository) CompleteGame(gameID int64, isWon bool, points int) error {
+	query := `UPDATE hangman_games SET completed_at = ?, is_won = ?, points_earned = ?
+			  WHERE id = ?`
+	_, err := r.db.Exec(query, time.Now(), isWon, points, gameID)
+	return err
+}
+
+// GetActiveGame retrieves the kid's unfinished game in their current session, or nil if there is none
+func (r *HangmanRepository) GetActiveGame(kidID int64) (*models.HangmanGame, error) {
+	query := `SELECT g.id, g.kid_id, g.word, g.guessed_letters, g.wrong_guesses, g.max_wrong_guesses,
+			  g.is_won, g.is_lost, g.points_earned, g.started_at
+			  FROM hangman_games g
+			  JOIN hangman_state s ON s.session_id = g.session_id
+			  WHERE g.kid_id = ? AND g.completed_at IS NULL
+			  ORDER BY g.id DESC LIMIT 1`
+
+	game := &models.HangmanGame{}
+	var lettersJSON string
+
+	err := r.db.QueryRow(query, kidID).Scan(&game.ID, &game.KidID, &game.Word, &lettersJSON, &game.WrongGuesses,
+		&game.MaxWrongGuesses, &game.IsWon, &game.IsLost, &game.PointsEarned, &game.StartedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(lettersJSON), &game.GuessedLetters); err != nil {
+		return nil, fmt.Errorf("failed to decode guessed letters: %w", err)
+	}
+
+	return game, nil
+}
+
+// SaveState stores the kid's progress through a session, creating the state if needed
+func (r *HangmanRepository) SaveState(state *models.HangmanState) error {
+	wordsJSON, err := json.Marshal(state.Words)
+	if err != nil {
+		return fmt.Errorf("failed to encode session words: %w", err)
+	}
+
+	// First, try to update existing record
+	updateQuery := `UPDATE hangman_state
+					SET session_id = ?, current_word_idx = ?, words_json = ?, points_so_far = ?, updated_at = CURRENT_TIMESTAMP
+					WHERE kid_id = ?`
+	result, err := r.db.Exec(updateQuery, state.SessionID, state.CurrentWordIdx, string(wordsJSON), state.PointsSoFar, state.KidID)
+	if err != nil {
+		return err
+	}
+
+	// Check if any rows were updated
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	// If no rows were updated, insert a new record
+	if rowsAffected == 0 {
+		insertQuery := `INSERT INTO hangman_state (kid_id, session_id, current_word_idx, words_json, points_so_far)
+						VALUES (?, ?, ?, ?, ?)`
+		_, err = r.db.Exec(insertQuery, state.KidID, state.SessionID, state.CurrentWordIdx, string(wordsJSON), state.PointsSoFar)
+		return err
+	}
+
+	return nil
+}
+
+// GetState retrieves the kid's progress through their current session, or nil if there is none
+func (r *HangmanRepository) GetState(kidID int64) (*models.HangmanState, error) {
+	query := `SELECT kid_id, session_id, current_word_idx, words_json, points_so_far FROM hangman_state WHERE kid_id = ?`
+
+	state := &models.HangmanState{}
+	var wordsJSON string
+
+	err := r.db.QueryRow(query, kidID).Scan(&state.KidID, &state.SessionID, &state.CurrentWordIdx, &wordsJSON, &state.PointsSoFar)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(wordsJSON), &state.Words); err != nil {
+		return nil, fmt.Errorf("failed to decode session words: %w", err)
+	}
+
+	return state, nil
+}
+
+// DeleteState removes the kid's session progress
+func (r *HangmanRepository) DeleteState(kidID int64) error {
+	query := `DELETE FROM hangman_state WHERE kid_id = ?`
+	_, err := r.db.Exec(query, kidID)
+	return err
+}
+
+// AddSessionPoints adds a finished game's points to the kid's current session
+func (r *HangmanRepository) AddSessionPoints(kidID int64, points int, won bool) error {
+	// Update session state
+	query := `UPDATE hangman_state SET points_so_far = points_so_far + ? WHERE kid_id = ?`
+	if _, err := r.db.Exec(query, points, kidID); err != nil {
+		return err
+	}
+
+	// Update session totals
+	if won {
+		query = `UPDATE hangman_sessions
+				 SET games_won = games_won + 1, total_points = total_points + ?
+				 WHERE id = (SELECT session_id FROM hangman_state WHERE kid_id = ?)`
+	} else {
+		query = `UPDATE hangman_sessions
+				 SET total_points = total_points + ?
+				 WHERE id = (SELECT session_id FROM hangman_state WHERE kid_id = ?)`
+	}
+	_, err := r.db.Exec(query, points, kidID)
+	return err
+}
+
+// CompleteSession marks the kid's current session as complete
+func (r *HangmanRepository) CompleteSession(kidID int64) error {
+	query := `UPDATE hangman_sessions SET completed_at = ?
+			  WHERE id = (SELECT session_id FROM hangman_state WHERE kid_id = ?)`
+	_, err := r.db.Exec(query, time.Now(), kidID)
+	return err
+}
+
+// GetCurrentSession retrieves the session the kid is currently playing
+func (r *HangmanRepository) GetCurrentSession(kidID int64) (*models.HangmanSession, error) {
+	query := `SELECT s.id, s.kid_id, s.spelling_list_id, s.started_at, s.completed_at,
+			  s.total_games, s.games_won, s.total_points
+			  FROM hangman_sessions s
+			  JOIN hangman_state st ON st.session_id = s.id
+			  WHERE st.kid_id = ?`
+
+	var session models.HangmanSession
+	var completedAt sql.NullTime
+	err := r.db.QueryRow(query, kidID).Scan(&session.ID, &session.KidID,
+		&session.SpellingListID, &session.StartedAt, &completedAt,
+		&session.TotalGames, &session.GamesWon, &session.TotalPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	if completedAt.Valid {
+		session.CompletedAt = &completedAt.Time
+	}
+
+	return &session, nil
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// IdentityRepository handles the sign-in provider accounts linked to users
+type IdentityRepository struct {
+	db *database.DB
+}
+
+// NewIdentityRepository creates a new identity repository
+func NewIdentityRepository(db *database.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+const identityColumns = "id, user_id, provider, subject, email, created_at, last_used_at"
+
+// GetIdentity retrieves the identity for a provider account, or nil if it isn't linked
+func (r *IdentityRepository) GetIdentity(provider, subject string) (*models.UserIdentity, error) {
+	query := "SELECT " + identityColumns + " FROM user_identities WHERE provider = ? AND subject = ?"
+	identity, err := scanIdentity(r.db.QueryRow(query, provider, subject))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+	return identity, nil
+}
+
+// GetUserIdentities lists the identities linked to a user, ordered by provider
+func (r *IdentityRepository) GetUserIdentities(userID int64) ([]models.UserIdentity, error) {
+	query := "SELECT " + identityColumns + " FROM user_identities WHERE user_id = ? ORDER BY provider"
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query identities: %w", err)
+	}
+	defer rows.Close()
+
+	var identities []models.UserIdentity
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan identity: %w", err)
+		}
+		identities = append(identities, *identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// LinkIdentity links a provider account to a user. It fails if the account is
+// already linked to someone, or the user already has an account from that provider.
+func (r *IdentityRepository) LinkIdentity(userID int64, provider, subject, email string) (*models.UserIdentity, error) {
+	now := time.Now()
+	id, err := r.db.ExecReturningID(
+		"INSERT INTO user_identities (user_id, provider, subject, email, created_at, last_used_at) VALUES (?, ?, ?, ?, ?, ?)",
+		userID, provider, subject, email, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	return &models.UserIdentity{
+		ID:         id,
+		UserID:     userID,
+		Provider:   provider,
+		Subject:    subject,
+		Email:      email,
+		CreatedAt:  now,
+		LastUsedAt: &now,
+	}, nil
+}
+
+// TouchIdentity records that an identity was used to sign in, with the email the provider gave
+func (r *IdentityRepository) TouchIdentity(id int64, email string, now time.Time) error {
+	if _, err := r.db.Exec("UPDATE user_identities SET email = ?, last_used_at = ? WHERE id = ?", email, now, id); err != nil {
+		return fmt.Errorf("failed to update identity: %w", err)
+	}
+	return nil
+}
+
+// UnlinkIdentity removes a user's identity for a provider, reporting whether there was one
+func (r *IdentityRepository) UnlinkIdentity(userID int64, provider string) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM user_identities WHERE user_id = ? AND provider = ?", userID, provider)
+	if err != nil {
+		return false, fmt.Errorf("failed to unlink identity: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to unlink identity: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func scanIdentity(row rowScanner) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	var lastUsedAt sql.NullTime
+	if err := row.Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+		&lastUsedAt,
+	); err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		identity.LastUsedAt = &lastUsedAt.Time
+	}
+	return &identity, nil
+}
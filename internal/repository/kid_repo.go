@@ -228,6 +228,60 @@ func (r *KidRepository) GetKidSession(sessionID string) (int64, error) {
 	return kidID, nil
 }
 
+// GetActiveKidSessions retrieves a kid's unexpired sessions, most recently used first
+func (r *KidRepository) GetActiveKidSessions(kidID int64) ([]models.KidSession, error) {
+	query := `
+		SELECT id, kid_id, expires_at, created_at, last_seen_at, user_agent, ip_address
+		FROM kid_sessions
+		WHERE kid_id = ? AND expires_at > ?
+		ORDER BY COALESCE(last_seen_at, created_at) DESC
+	`
+	rows, err := r.db.Query(query, kidID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query kid sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.KidSession
+	for rows.Next() {
+		var session models.KidSession
+		var lastSeenAt sql.NullTime
+		var userAgent, ipAddress sql.NullString
+		if err := rows.Scan(&session.ID, &session.KidID, &session.ExpiresAt, &session.CreatedAt, &lastSeenAt, &userAgent, &ipAddress); err != nil {
+			return nil, fmt.Errorf("failed to scan kid session: %w", err)
+		}
+		if lastSeenAt.Valid {
+			session.LastSeenAt = &lastSeenAt.Time
+		}
+		session.UserAgent = userAgent.String
+		session.IPAddress = ipAddress.String
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// TouchKidSession records that a kid session was used from a device, unless it
+// was already seen since staleBefore
+func (r *KidRepository) TouchKidSession(sessionID, userAgent, ipAddress string, now, staleBefore time.Time) error {
+	query := `
+		UPDATE kid_sessions SET last_seen_at = ?, user_agent = ?, ip_address = ?
+		WHERE id = ? AND (last_seen_at IS NULL OR last_seen_at < ?)
+	`
+	if _, err := r.db.Exec(query, now, userAgent, ipAddress, sessionID, staleBefore); err != nil {
+		return fmt.Errorf("failed to update kid session: %w", err)
+	}
+	return nil
+}
+
+// DeleteKidSessions removes all of a kid's sessions, returning how many were removed
+func (r *KidRepository) DeleteKidSessions(kidID int64) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM kid_sessions WHERE kid_id = ?", kidID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete kid sessions: %w", err)
+	}
+	return result.RowsAffected()
+}
+
 // DeleteKidSession removes a kid session from the database
 func (r *KidRepository) DeleteKidSession(sessionID string) error {
 	query := "DELETE FROM kid_sessions WHERE id = ?"
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// LeaderboardAssignment is a list a parent or teacher assigned to one or more kids
+type LeaderboardAssignment struct {
+	ListID     int64
+	ListName   string
+	AssignedBy int64
+}
+
+// LeaderboardRepository handles kids' leaderboard settings and the points boards are ranked by
+type LeaderboardRepository struct {
+	db *database.DB
+}
+
+// NewLeaderboardRepository creates a new leaderboard repository
+func NewLeaderboardRepository(db *database.DB) *LeaderboardRepository {
+	return &LeaderboardRepository{db: db}
+}
+
+// GetSettings returns a kid's leaderboard settings, or nil if they have never been set
+func (r *LeaderboardRepository) GetSettings(kidID int64) (*models.LeaderboardSettings, error) {
+	var settings models.LeaderboardSettings
+	err := r.db.QueryRow(
+		"SELECT kid_id, nickname, hidden_by_parent, hidden_by_teacher, updated_at FROM leaderboard_settings WHERE kid_id = ?",
+		kidID,
+	).Scan(&settings.KidID, &settings.Nickname, &settings.HiddenByParent, &settings.HiddenByTeacher, &settings.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// GetSettingsForKids returns the leaderboard settings of those kids who have any, by kid ID
+func (r *LeaderboardRepository) GetSettingsForKids(kidIDs []int64) (map[int64]models.LeaderboardSettings, error) {
+	settings := make(map[int64]models.LeaderboardSettings, len(kidIDs))
+	if len(kidIDs) == 0 {
+		return settings, nil
+	}
+
+	query := fmt.Sprintf(
+		"SELECT kid_id, nickname, hidden_by_parent, hidden_by_teacher, updated_at FROM leaderboard_settings WHERE kid_id IN (%s)",
+		generatePlaceholders(len(kidIDs)),
+	)
+	rows, err := r.db.Query(query, kidIDArgs(kidIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard settings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.LeaderboardSettings
+		if err := rows.Scan(&s.KidID, &s.Nickname, &s.HiddenByParent, &s.HiddenByTeacher, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan leaderboard settings: %w", err)
+		}
+		settings[s.KidID] = s
+	}
+	return settings, rows.Err()
+}
+
+// SaveSettings creates or updates a kid's leaderboard settings
+func (r *LeaderboardRepository) SaveSettings(settings *models.LeaderboardSettings) error {
+	result, err := r.db.Exec(
+		"UPDATE leaderboard_settings SET nickname = ?, hidden_by_parent = ?, hidden_by_teacher = ?, updated_at = ? WHERE kid_id = ?",
+		settings.Nickname, settings.HiddenByParent, settings.HiddenByTeacher, settings.UpdatedAt, settings.KidID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update leaderboard settings: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+		return nil
+	}
+
+	if _, err := r.db.Exec(
+		"INSERT INTO leaderboard_settings (kid_id, nickname, hidden_by_parent, hidden_by_teacher, updated_at) VALUES (?, ?, ?, ?, ?)",
+		settings.KidID, settings.Nickname, settings.HiddenByParent, settings.HiddenByTeacher, settings.UpdatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to save leaderboard settings: %w", err)
+	}
+	return nil
+}
+
+// GetPoints totals the points each kid scored in sessions they completed in any
+// game mode since the given time (all time if zero), only counting sessions on
+// listID unless it is 0. Kids without points are left out.
+func (r *LeaderboardRepository) GetPoints(kidIDs []int64, since time.Time, listID int64) (map[int64]int, error) {
+	points := make(map[int64]int, len(kidIDs))
+	if len(kidIDs) == 0 {
+		return points, nil
+	}
+
+	for _, src := range activitySources {
+		query := fmt.Sprintf(`
+			SELECT s.kid_id, COALESCE(SUM(i.points_earned), 0)
+			FROM %s s
+			JOIN %s i ON s.id = i.%s
+			WHERE s.kid_id IN (%s) AND s.completed_at IS NOT NULL
+		`, src.sessions, src.items, src.sessionFK, generatePlaceholders(len(kidIDs)))
+		args := kidIDArgs(kidIDs)
+		if !since.IsZero() {
+			query += " AND " + r.db.Dialect.TimestampAtOrAfter("s.completed_at")
+			args = append(args, since)
+		}
+		if listID != 0 {
+			query += " AND s.spelling_list_id = ?"
+			args = append(args, listID)
+		}
+		query += " GROUP BY s.kid_id"
+
+		rows, err := r.db.Query(query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to total points in %s: %w", src.sessions, err)
+		}
+		for rows.Next() {
+			var kidID int64
+			var total int
+			if err := rows.Scan(&kidID, &total); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan points: %w", err)
+			}
+			if total != 0 {
+				points[kidID] += total
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return points, nil
+}
+
+// GetKidAssignments lists the lists assigned to a kid and who assigned each
+func (r *LeaderboardRepository) GetKidAssignments(kidID int64) ([]LeaderboardAssignment, error) {
+	return r.queryAssignments(`
+		SELECT la.spelling_list_id, sl.name, la.assigned_by
+		FROM list_assignments la
+		JOIN spelling_lists sl ON sl.id = la.spelling_list_id
+		WHERE la.kid_id = ?
+		ORDER BY sl.name
+	`, kidID)
+}
+
+// GetAssignmentsBy lists the lists a parent or teacher has assigned to any kid
+func (r *LeaderboardRepository) GetAssignmentsBy(userID int64) ([]LeaderboardAssignment, error) {
+	return r.queryAssignments(`
+		SELECT DISTINCT la.spelling_list_id, sl.name, la.assigned_by
+		FROM list_assignments la
+		JOIN spelling_lists sl ON sl.id = la.spelling_list_id
+		WHERE la.assigned_by = ?
+		ORDER BY sl.name
+	`, userID)
+}
+
+func (r *LeaderboardRepository) queryAssignments(query string, args ...interface{}) ([]LeaderboardAssignment, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []LeaderboardAssignment
+	for rows.Next() {
+		var assignment LeaderboardAssignment
+		if err := rows.Scan(&assignment.ListID, &assignment.ListName, &assignment.AssignedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan assignment: %w", err)
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, rows.Err()
+}
+
+// GetAssignedKids lists the kids a parent or teacher assigned a list to
+func (r *LeaderboardRepository) GetAssignedKids(listID, assignedBy int64) ([]models.Kid, error) {
+	query := `
+		SELECT k.id, k.family_code, k.name, k.username, k.password, k.avatar_color, k.created_at, k.updated_at
+		FROM list_assignments la
+		JOIN kids k ON k.id = la.kid_id
+		WHERE la.spelling_list_id = ? AND la.assigned_by = ?
+		ORDER BY k.id
+	`
+
+	rows, err := r.db.Query(query, listID, assignedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assigned kids: %w", err)
+	}
+	defer rows.Close()
+
+	var kids []models.Kid
+	for rows.Next() {
+		var kid models.Kid
+		if err := rows.Scan(&kid.ID, &kid.FamilyCode, &kid.Name, &kid.Username, &kid.Password, &kid.AvatarColor, &kid.CreatedAt, &kid.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan assigned kid: %w", err)
+		}
+		kids = append(kids, kid)
+	}
+	return kids, rows.Err()
+}
+
+// kidIDArgs converts kid IDs to query arguments for an IN clause
+func kidIDArgs(kidIDs []int64) []interface{} {
+	args := make([]interface{}, len(kidIDs))
+	for i, id := range kidIDs {
+		args[i] = id
+	}
+	return args
+}
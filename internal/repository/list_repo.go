@@ -4,13 +4,15 @@ import (
 	"database/sql"
 	"fmt"
 	"spellingclash/internal/database"
+	"spellingclash/internal/dictionary"
 	"spellingclash/internal/models"
 	"time"
 )
 
 // ListRepository handles database operations for spelling lists and words
 type ListRepository struct {
-	db *database.DB
+	db   *database.DB
+	dict *dictionary.Dictionary
 }
 
 // NewListRepository creates a new list repository
@@ -18,10 +20,15 @@ func NewListRepository(db *database.DB) *ListRepository {
 	return &ListRepository{db: db}
 }
 
+// SetDictionary sets the dictionary used to flag likely typos in ValidateWords
+func (r *ListRepository) SetDictionary(dict *dictionary.Dictionary) {
+	r.dict = dict
+}
+
 // CreateList creates a new spelling list
-func (r *ListRepository) CreateList(familyCode string, name, description string, createdBy int64) (*models.SpellingList, error) {
-	query := "INSERT INTO spelling_lists (family_code, name, description, created_by, is_public) VALUES (?, ?, ?, ?, FALSE)"
-	listID, err := r.db.ExecReturningID(query, familyCode, name, description, createdBy)
+func (r *ListRepository) CreateList(familyCode string, name, description, locale string, createdBy int64) (*models.SpellingList, error) {
+	query := "INSERT INTO spelling_lists (family_code, name, description, locale, created_by, is_public) VALUES (?, ?, ?, ?, ?, FALSE)"
+	listID, err := r.db.ExecReturningID(query, familyCode, name, description, locale, createdBy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create list: %w", err)
 	}
@@ -31,6 +38,7 @@ func (r *ListRepository) CreateList(familyCode string, name, description string,
 		FamilyCode:  &familyCode,
 		Name:        name,
 		Description: description,
+		Locale:      locale,
 		CreatedBy:   &createdBy,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
@@ -41,9 +49,9 @@ func (r *ListRepository) CreateList(familyCode string, name, description string,
 }
 
 // CreateTeacherList creates a private teacher-owned list (not tied to any family).
-func (r *ListRepository) CreateTeacherList(name, description string, createdBy int64) (*models.SpellingList, error) {
-	query := "INSERT INTO spelling_lists (family_code, name, description, created_by, is_public) VALUES (NULL, ?, ?, ?, FALSE)"
-	listID, err := r.db.ExecReturningID(query, name, description, createdBy)
+func (r *ListRepository) CreateTeacherList(name, description, locale string, createdBy int64) (*models.SpellingList, error) {
+	query := "INSERT INTO spelling_lists (family_code, name, description, locale, created_by, is_public) VALUES (NULL, ?, ?, ?, ?, FALSE)"
+	listID, err := r.db.ExecReturningID(query, name, description, locale, createdBy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create teacher list: %w", err)
 	}
@@ -53,6 +61,7 @@ func (r *ListRepository) CreateTeacherList(name, description string, createdBy i
 		FamilyCode:  nil,
 		Name:        name,
 		Description: description,
+		Locale:      locale,
 		CreatedBy:   &createdBy,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
@@ -63,9 +72,9 @@ func (r *ListRepository) CreateTeacherList(name, description string, createdBy i
 }
 
 // CreatePublicList creates a public spelling list (not tied to any family)
-func (r *ListRepository) CreatePublicList(name, description string) (*models.SpellingList, error) {
-	query := "INSERT INTO spelling_lists (family_code, name, description, created_by, is_public) VALUES (NULL, ?, ?, NULL, TRUE)"
-	listID, err := r.db.ExecReturningID(query, name, description)
+func (r *ListRepository) CreatePublicList(name, description, locale string) (*models.SpellingList, error) {
+	query := "INSERT INTO spelling_lists (family_code, name, description, locale, created_by, is_public) VALUES (NULL, ?, ?, ?, NULL, TRUE)"
+	listID, err := r.db.ExecReturningID(query, name, description, locale)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create public list: %w", err)
 	}
@@ -75,6 +84,7 @@ func (r *ListRepository) CreatePublicList(name, description string) (*models.Spe
 		FamilyCode:  nil,
 		Name:        name,
 		Description: description,
+		Locale:      locale,
 		CreatedBy:   nil, // System-created, no specific user
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
@@ -98,7 +108,7 @@ func (r *ListRepository) PublicListExists(name string) (bool, error) {
 // GetListByID retrieves a spelling list by ID
 func (r *ListRepository) GetListByID(listID int64) (*models.SpellingList, error) {
 	query := `
-		SELECT id, family_code, name, description, created_by, created_at, updated_at, is_public
+		SELECT id, family_code, name, description, created_by, created_at, updated_at, is_public, locale
 		FROM spelling_lists
 		WHERE id = ?
 	`
@@ -112,6 +122,7 @@ func (r *ListRepository) GetListByID(listID int64) (*models.SpellingList, error)
 		&list.CreatedAt,
 		&list.UpdatedAt,
 		&list.IsPublic,
+		&list.Locale,
 	)
 
 	if err == sql.ErrNoRows {
@@ -127,7 +138,7 @@ func (r *ListRepository) GetListByID(listID int64) (*models.SpellingList, error)
 // GetFamilyLists retrieves all spelling lists for a family
 func (r *ListRepository) GetFamilyLists(familyCode string) ([]models.SpellingList, error) {
 	query := `
-		SELECT id, family_code, name, description, created_by, created_at, updated_at, is_public
+		SELECT id, family_code, name, description, created_by, created_at, updated_at, is_public, locale
 		FROM spelling_lists
 		WHERE family_code = ?
 		ORDER BY created_at DESC
@@ -150,6 +161,7 @@ func (r *ListRepository) GetFamilyLists(familyCode string) ([]models.SpellingLis
 			&list.CreatedAt,
 			&list.UpdatedAt,
 			&list.IsPublic,
+			&list.Locale,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan list: %w", err)
 		}
@@ -162,7 +174,7 @@ func (r *ListRepository) GetFamilyLists(familyCode string) ([]models.SpellingLis
 // GetPublicLists retrieves all public spelling lists available to everyone
 func (r *ListRepository) GetPublicLists() ([]models.SpellingList, error) {
 	query := `
-		SELECT id, family_code, name, description, created_by, created_at, updated_at, is_public
+		SELECT id, family_code, name, description, created_by, created_at, updated_at, is_public, locale
 		FROM spelling_lists
 		WHERE is_public = TRUE
 		ORDER BY name ASC
@@ -185,6 +197,7 @@ func (r *ListRepository) GetPublicLists() ([]models.SpellingList, error) {
 			&list.CreatedAt,
 			&list.UpdatedAt,
 			&list.IsPublic,
+			&list.Locale,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan public list: %w", err)
 		}
@@ -194,10 +207,10 @@ func (r *ListRepository) GetPublicLists() ([]models.SpellingList, error) {
 	return lists, nil
 }
 
-// UpdateList updates a spelling list's name and description
-func (r *ListRepository) UpdateList(listID int64, name, description string) error {
-	query := "UPDATE spelling_lists SET name = ?, description = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
-	_, err := r.db.Exec(query, name, description, listID)
+// UpdateList updates a spelling list's name, description and locale
+func (r *ListRepository) UpdateList(listID int64, name, description, locale string) error {
+	query := "UPDATE spelling_lists SET name = ?, description = ?, locale = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	_, err := r.db.Exec(query, name, description, locale, listID)
 	if err != nil {
 		return fmt.Errorf("failed to update list: %w", err)
 	}
@@ -513,7 +526,7 @@ func (r *ListRepository) UnassignListFromKid(listID, kidID int64) error {
 // GetKidAssignedLists retrieves all lists assigned to a kid
 func (r *ListRepository) GetKidAssignedLists(kidID int64) ([]models.SpellingList, error) {
 	query := `
-		SELECT sl.id, sl.family_code, sl.name, sl.description, sl.created_by, sl.created_at, sl.updated_at, sl.is_public,
+		SELECT sl.id, sl.family_code, sl.name, sl.description, sl.created_by, sl.created_at, sl.updated_at, sl.is_public, sl.locale,
 		       COALESCE(la.managed_by_teacher, FALSE), la.due_date
 		FROM spelling_lists sl
 		INNER JOIN list_assignments la ON sl.id = la.spelling_list_id
@@ -539,6 +552,7 @@ func (r *ListRepository) GetKidAssignedLists(kidID int64) ([]models.SpellingList
 			&list.CreatedAt,
 			&list.UpdatedAt,
 			&list.IsPublic,
+			&list.Locale,
 			&list.AssignmentManagedByTeacher,
 			&dueDate,
 		); err != nil {
@@ -625,7 +639,7 @@ func (r *ListRepository) GetListAssignedKids(listID int64) ([]models.Kid, error)
 func (r *ListRepository) GetFamilyListsWithAssignmentCounts(familyCode string) ([]models.ListSummary, error) {
 	query := `
 		SELECT 
-			sl.id, sl.family_code, sl.name, sl.description, sl.created_by, sl.created_at, sl.updated_at,
+			sl.id, sl.family_code, sl.name, sl.description, sl.created_by, sl.created_at, sl.updated_at, sl.locale,
 			COUNT(DISTINCT la.kid_id) as assigned_kid_count,
 			COUNT(DISTINCT w.id) as word_count
 		FROM spelling_lists sl
@@ -652,6 +666,7 @@ func (r *ListRepository) GetFamilyListsWithAssignmentCounts(familyCode string) (
 			&list.CreatedBy,
 			&list.CreatedAt,
 			&list.UpdatedAt,
+			&list.Locale,
 			&list.AssignedKidCount,
 			&list.WordCount,
 		); err != nil {
@@ -667,7 +682,7 @@ func (r *ListRepository) GetFamilyListsWithAssignmentCounts(familyCode string) (
 func (r *ListRepository) GetAllListsWithAssignmentCounts() ([]models.ListSummary, error) {
 	query := `
 		SELECT 
-			sl.id, sl.family_code, sl.name, sl.description, sl.created_by, sl.created_at, sl.updated_at, sl.is_public,
+			sl.id, sl.family_code, sl.name, sl.description, sl.created_by, sl.created_at, sl.updated_at, sl.is_public, sl.locale,
 			COUNT(DISTINCT la.kid_id) as assigned_kid_count,
 			COUNT(DISTINCT w.id) as word_count
 		FROM spelling_lists sl
@@ -694,6 +709,7 @@ func (r *ListRepository) GetAllListsWithAssignmentCounts() ([]models.ListSummary
 			&list.CreatedAt,
 			&list.UpdatedAt,
 			&list.IsPublic,
+			&list.Locale,
 			&list.AssignedKidCount,
 			&list.WordCount,
 		); err != nil {
@@ -716,8 +732,37 @@ func (r *ListRepository) IsListAssignedToKid(listID, kidID int64) (bool, error)
 	return count > 0, nil
 }
 
-// ValidateWords checks if any words are in the bad words filter
-// Returns a slice of words that were found in the filter
-func (r *ListRepository) ValidateWords(words []string) ([]string, error) {
-	return r.db.ValidateWords(words)
+// ValidateWords checks words against the bad words filter and, when a dictionary
+// is set, flags words that look misspelled for the locale
+func (r *ListRepository) ValidateWords(words []string, locale string) (*models.WordValidation, error) {
+	badWords, err := r.db.ValidateWords(words)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WordValidation{
+		BadWords: badWords,
+		Warnings: r.CheckSpelling(words, locale),
+	}, nil
+}
+
+// CheckSpelling returns a warning for each word that isn't in the dictionary for the locale.
+// Nothing is flagged when no dictionary is set or it has no word list for the locale.
+func (r *ListRepository) CheckSpelling(words []string, locale string) []models.SpellingWarning {
+	if r.dict == nil || !r.dict.Supports(locale) {
+		return nil
+	}
+
+	var warnings []models.SpellingWarning
+	for _, word := range words {
+		if r.dict.Contains(word, locale) {
+			continue
+		}
+		warnings = append(warnings, models.SpellingWarning{
+			Word:       word,
+			Suggestion: r.dict.Suggest(word, locale),
+		})
+	}
+
+	return warnings
 }
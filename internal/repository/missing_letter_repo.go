@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// MissingLetterRepository handles missing letter session, game and state database operations
+type MissingLetterRepository struct {
+	db *database.DB
+}
+
+// NewMissingLetterRepository creates a new missing letter repository
+func NewMissingLetterRepository(db *database.DB) *MissingLetterRepository {
+	return &MissingLetterRepository{db: db}
+}
+
+// CreateSession creates a new missing letter session and returns its ID
+func (r *MissingLetterRepository) CreateSession(kidID, listID int64, totalWords int) (int64, error) {
+	query := `INSERT INTO missing_letter_sessions (kid_id, spelling_list_id, started_at, total_games, games_won, total_points)
+			  VALUES (?, ?, ?, ?, 0, 0)`
+	return r.db.ExecReturningID(query, kidID, listID, time.Now(), totalWords)
+}
+
+// CreateGame creates a new missing letter game for a word and returns its ID
+func (r *MissingLetterRepository) CreateGame(sessionID, kidID, wordID int64, word string, missingIndices []int, maxAttempts int) (int64, error) {
+	indicesJSON, err := json.Marshal(missingIndices)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode missing indices: %w", err)
+	}
+
+	query := `INSERT INTO missing_letter_games (session_id, kid_id, word_id, word, missing_indices, guessed_letters, attempts, max_attempts, started_at)
+			  VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?)`
+	return r.db.ExecReturningID(query, sessionID, kidID, wordID, word, string(indicesJSON), "[]", maxAttempts, time.Now())
+}
+
+// SaveGameProgress stores the guesses made so far in a game
+func (r *MissingLetterRepository) SaveGameProgress(gameID int64, guessedWords []string, attempts int, isWon, isLost bool) error {
+	lettersJSON, err := json.Marshal(guessedWords)
+	if err != nil {
+		return fmt.Errorf("failed to encode guesses: %w", err)
+	}
+
+	query := `UPDATE missing_letter_games SET guessed_letters = ?, attempts = ?, is_won = ?, is_lost = ?
+			  WHERE id = ?`
+	_, err = r.db.Exec(query, string(lettersJSON), attempts, isWon, isLost, gameID)
+	return err
+}
+
+// CompleteGame records the final result of a game
+func (r *MissingLetterRepository) CompleteGame(gameID int64, isWon bool, points int) error {
+	query := `UPDATE missing_letter_games SET completed_at = ?, is_won = ?, points_earned = ?
+			  WHERE id = ?`
+	_, err := r.db.Exec(query, time.Now(), isWon, points, gameID)
+	return err
+}
+
+// GetActiveGame retrieves the kid's unfinished game in their current session, or nil if there is none
+func (r *MissingLetterRepository) GetActiveGame(kidID int64) (*models.MissingLetterGame, error) {
+	query := `SELECT g.id, g.session_id, g.kid_id, g.word_id, g.word, g.missing_indices, g.guessed_letters,
+			  g.attempts, g.max_attempts, g.is_won, g.is_lost, g.points_earned, g.started_at
+			  FROM missing_letter_games g
+			  JOIN missing_letter_state s ON s.session_id = g.session_id
+			  WHERE g.kid_id = ? AND g.completed_at IS NULL
+			  ORDER BY g.id DESC LIMIT 1`
+
+	game := &models.MissingLetterGame{}
+	var indicesJSON, lettersJSON string
+
+	err := r.db.QueryRow(query, kidID).Scan(&game.ID, &game.SessionID, &game.KidID, &game.WordID, &game.Word,
+		&indicesJSON, &lettersJSON, &game.Attempts, &game.MaxAttempts, &game.IsWon, &game.IsLost,
+		&game.PointsEarned, &game.StartedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(indicesJSON), &game.MissingIndices); err != nil {
+		return nil, fmt.Errorf("failed to decode missing indices: %w", err)
+	}
+	if err := json.Unmarshal([]byte(lettersJSON), &game.GuessedLetters); err != nil {
+		return nil, fmt.Errorf("failed to decode guesses: %w", err)
+	}
+
+	return game, nil
+}
+
+// SaveState stores the kid's progress through a session, creating the state if needed
+func (r *MissingLetterRepository) SaveState(state *models.MissingLetterState) error {
+	wordsJSON, err := json.Marshal(state.Words)
+	if err != nil {
+		return fmt.Errorf("failed to encode session words: %w", err)
+	}
+
+	// First, try to update existing record
+	updateQuery := `UPDATE missing_letter_state
+					SET session_id = ?, current_word_idx = ?, words_json = ?, points_so_far = ?, updated_at = CURRENT_TIMESTAMP
+					WHERE kid_id = ?`
+	result, err := r.db.Exec(updateQuery, state.SessionID, state.CurrentWordIdx, string(wordsJSON), state.PointsSoFar, state.KidID)
+	if err != nil {
+		return err
+	}
+
+	// Check if any rows were updated
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	// If no rows were updated, insert a new record
+	if rowsAffected == 0 {
+		insertQuery := `INSERT INTO missing_letter_state (kid_id, session_id, current_word_idx, words_json, points_so_far)
+						VALUES (?, ?, ?, ?, ?)`
+		_, err = r.db.Exec(insertQuery, state.KidID, state.SessionID, state.CurrentWordIdx, string(wordsJSON), state.PointsSoFar)
+		return err
+	}
+
+	return nil
+}
+
+// GetState retrieves the kid's progress through their current session, or nil if there is none
+func (r *MissingLetterRepository) GetState(kidID int64) (*models.MissingLetterState, error) {
+	query := `SELECT kid_id, session_id, current_word_idx, words_json, points_so_far FROM missing_letter_state WHERE kid_id = ?`
+
+	state := &models.MissingLetterState{}
+	var wordsJSON string
+
+	err := r.db.QueryRow(query, kidID).Scan(&state.KidID, &state.SessionID, &state.CurrentWordIdx, &wordsJSON, &state.PointsSoFar)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(wordsJSON), &state.Words); err != nil {
+		return nil, fmt.Errorf("failed to decode session words: %w", err)
+	}
+
+	return state, nil
+}
+
+// DeleteState removes the kid's session progress
+func (r *MissingLetterRepository) DeleteState(kidID int64) error {
+	query := `DELETE FROM missing_letter_state WHERE kid_id = ?`
+	_, err := r.db.Exec(query, kidID)
+	return err
+}
+
+// AddSessionPoints adds a finished game's points to the kid's current session
+func (r *MissingLetterRepository) AddSessionPoints(kidID int64, points int, won bool) error {
+	// Update session state
+	query := `UPDATE missing_letter_state SET points_so_far = points_so_far + ? WHERE kid_id = ?`
+	if _, err := r.db.Exec(query, points, kidID); err != nil {
+		return err
+	}
+
+	// Update session totals
+	if won {
+		query = `UPDATE missing_letter_sessions
+				 SET games_won = games_won + 1, total_points = total_points + ?
+				 WHERE id = (SELECT session_id FROM missing_letter_state WHERE kid_id = ?)`
+	} else {
+		query = `UPDATE missing_letter_sessions
+				 SET total_points = total_points + ?
+				 WHERE id = (SELECT session_id FROM missing_letter_state WHERE kid_id = ?)`
+	}
+	_, err := r.db.Exec(query, points, kidID)
+	return err
+}
+
+// CompleteSession marks the kid's current session as complete
+func (r *MissingLetterRepository) CompleteSession(kidID int64) error {
+	query := `UPDATE missing_letter_sessions SET completed_at = ?
+			  WHERE id = (SELECT session_id FROM missing_letter_state WHERE kid_id = ?)`
+	_, err := r.db.Exec(query, time.Now(), kidID)
+	return err
+}
+
+// GetCurrentSession retrieves the session the kid is currently playing
+func (r *MissingLetterRepository) GetCurrentSession(kidID int64) (*models.MissingLetterSession, error) {
+	query := `SELECT s.id, s.kid_id, s.spelling_list_id, s.started_at, s.completed_at,
+			  s.total_games, s.games_won, s.total_points
+			  FROM missing_letter_sessions s
+			  JOIN missing_letter_state st ON st.session_id = s.id
+			  WHERE st.kid_id = ?`
+
+	var session models.MissingLetterSession
+	var completedAt sql.NullTime
+	err := r.db.QueryRow(query, kidID).Scan(&session.ID, &session.KidID,
+		&session.SpellingListID, &session.StartedAt, &completedAt,
+		&session.TotalGames, &session.GamesWon, &session.TotalPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	if completedAt.Valid {
+		session.CompletedAt = &completedAt.Time
+	}
+
+	return &session, nil
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
 	"spellingclash/internal/database"
 	"spellingclash/internal/models"
+	"strings"
 	"time"
 )
 
@@ -506,8 +508,12 @@ func (r *PracticeRepository) GetStrugglingWordsForKid(kidID int64, threshold flo
 	for rows.Next() {
 		var word StrugglingWord
 		var totalAttempts, correctAttempts int
+		var lastAttempted interface{}
 		
-		if err := rows.Scan(&word.WordID, &word.WordText, &totalAttempts, &correctAttempts, &word.LastAttempted); err != nil {
+		if err := rows.Scan(&word.WordID, &word.WordText, &totalAttempts, &correctAttempts, &lastAttempted); err != nil {
+			return nil, err
+		}
+		if word.LastAttempted, err = parseAggregateTime(lastAttempted); err != nil {
 			return nil, err
 		}
 		
@@ -527,6 +533,36 @@ func (r *PracticeRepository) GetStrugglingWordsForKid(kidID int64, threshold flo
 	return strugglingWords, nil
 }
 
+// aggregateTimeLayouts are the formats SQLite stores timestamps in
+var aggregateTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parseAggregateTime converts the result of MAX() or MIN() on a timestamp column.
+// SQLite returns these as text because the result has no declared column type.
+func parseAggregateTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case []byte:
+		return parseAggregateTime(string(v))
+	case string:
+		s := strings.TrimSuffix(v, "Z")
+		for _, layout := range aggregateTimeLayouts {
+			if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported timestamp %v", value)
+}
+
 // GetKidStats gets overall statistics for a kid including practice, hangman, and missing letter sessions
 func (r *PracticeRepository) GetKidStats(kidID int64) (*models.KidStats, error) {
 	// Get practice session stats
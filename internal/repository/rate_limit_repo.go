@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"spellingclash/internal/database"
+	"time"
+)
+
+// RateLimitRepository stores rate limit counters in the database so every
+// replica shares them. It implements security.RateLimitStore.
+type RateLimitRepository struct {
+	db *database.DB
+}
+
+// NewRateLimitRepository creates a new rate limit repository
+func NewRateLimitRepository(db *database.DB) *RateLimitRepository {
+	return &RateLimitRepository{db: db}
+}
+
+// IncrementRateLimit adds a hit to a bucket, creating it if needed, and returns
+// the bucket's hits
+func (r *RateLimitRepository) IncrementRateLimit(bucket string, expiresAt time.Time) (int, error) {
+	if _, err := r.db.Exec(r.db.Dialect.IncrementRateLimit(), bucket, expiresAt); err != nil {
+		return 0, fmt.Errorf("failed to increment rate limit: %w", err)
+	}
+	return r.GetRateLimitHits(bucket)
+}
+
+// GetRateLimitHits returns the hits in a bucket, or 0 if it doesn't exist
+func (r *RateLimitRepository) GetRateLimitHits(bucket string) (int, error) {
+	var hits int
+	err := r.db.QueryRow("SELECT hits FROM rate_limits WHERE bucket = ?", bucket).Scan(&hits)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rate limit: %w", err)
+	}
+	return hits, nil
+}
+
+// DeleteRateLimit removes a bucket
+func (r *RateLimitRepository) DeleteRateLimit(bucket string) error {
+	if _, err := r.db.Exec("DELETE FROM rate_limits WHERE bucket = ?", bucket); err != nil {
+		return fmt.Errorf("failed to delete rate limit: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredRateLimits removes buckets whose window has ended
+func (r *RateLimitRepository) DeleteExpiredRateLimits() error {
+	if _, err := r.db.Exec("DELETE FROM rate_limits WHERE expires_at < ?", time.Now()); err != nil {
+		return fmt.Errorf("failed to delete expired rate limits: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"spellingclash/internal/database"
+	"spellingclash/internal/models"
 )
 
 type SettingsRepository struct {
@@ -44,3 +45,17 @@ func (r *SettingsRepository) SetInviteOnlyMode(enabled bool) error {
 	}
 	return r.SetSetting("invite_only_mode", value)
 }
+
+// GetTwoFactorPolicy returns who must use two-factor authentication
+func (r *SettingsRepository) GetTwoFactorPolicy() models.TwoFactorPolicy {
+	value, err := r.GetSetting("two_factor_policy")
+	if err != nil || !models.TwoFactorPolicy(value).IsValid() {
+		return models.TwoFactorOptional
+	}
+	return models.TwoFactorPolicy(value)
+}
+
+// SetTwoFactorPolicy sets who must use two-factor authentication
+func (r *SettingsRepository) SetTwoFactorPolicy(policy models.TwoFactorPolicy) error {
+	return r.SetSetting("two_factor_policy", string(policy))
+}
@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// spellingBeeColumns are selected from spelling_bees aliased sb joined to spelling_lists aliased sl
+const spellingBeeColumns = `sb.id, sb.teacher_user_id, sb.spelling_list_id, sl.name, sb.max_misses, sb.answer_mode,
+	sb.status, sb.version, sb.created_at, sb.finished_at`
+
+// beeParticipantColumns are selected from spelling_bee_participants aliased p joined to kids aliased k
+const beeParticipantColumns = `p.id, p.bee_id, p.kid_id, k.name, k.avatar_color, p.turn_order, p.words_correct,
+	p.misses, p.eliminated_turn, p.final_place`
+
+// SpellingBeeRepository handles live classroom spelling bees and their results
+type SpellingBeeRepository struct {
+	db *database.DB
+}
+
+// NewSpellingBeeRepository creates a new spelling bee repository
+func NewSpellingBeeRepository(db *database.DB) *SpellingBeeRepository {
+	return &SpellingBeeRepository{db: db}
+}
+
+// CreateBee starts a bee with the given children, who take turns in the order given
+func (r *SpellingBeeRepository) CreateBee(teacherUserID, listID int64, maxMisses int, answerMode string, kidIDs []int64, now time.Time) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin spelling bee transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	beeID, err := tx.ExecReturningID(
+		"INSERT INTO spelling_bees (teacher_user_id, spelling_list_id, max_misses, answer_mode, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		teacherUserID, listID, maxMisses, answerMode, models.BeeRunning, now, now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create spelling bee: %w", err)
+	}
+	for i, kidID := range kidIDs {
+		if _, err := tx.Exec(
+			"INSERT INTO spelling_bee_participants (bee_id, kid_id, turn_order, updated_at) VALUES (?, ?, ?, ?)",
+			beeID, kidID, i+1, now,
+		); err != nil {
+			return 0, fmt.Errorf("failed to add spelling bee participant: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit spelling bee: %w", err)
+	}
+	return beeID, nil
+}
+
+func scanSpellingBee(row rowScanner) (*models.SpellingBee, error) {
+	var bee models.SpellingBee
+	var finishedAt sql.NullTime
+	if err := row.Scan(
+		&bee.ID, &bee.TeacherUserID, &bee.SpellingListID, &bee.ListName, &bee.MaxMisses, &bee.AnswerMode,
+		&bee.Status, &bee.Version, &bee.CreatedAt, &finishedAt,
+	); err != nil {
+		return nil, err
+	}
+	if finishedAt.Valid {
+		bee.FinishedAt = &finishedAt.Time
+	}
+	return &bee, nil
+}
+
+// GetBee retrieves a bee with its list name, or nil if it doesn't exist
+func (r *SpellingBeeRepository) GetBee(beeID int64) (*models.SpellingBee, error) {
+	query := `
+		SELECT ` + spellingBeeColumns + `
+		FROM spelling_bees sb
+		JOIN spelling_lists sl ON sl.id = sb.spelling_list_id
+		WHERE sb.id = ?
+	`
+	bee, err := scanSpellingBee(r.db.QueryRow(query, beeID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spelling bee: %w", err)
+	}
+	return bee, nil
+}
+
+// GetTeacherBees lists the bees a teacher has hosted, newest first
+func (r *SpellingBeeRepository) GetTeacherBees(teacherUserID int64, limit int) ([]models.SpellingBee, error) {
+	query := `
+		SELECT ` + spellingBeeColumns + `
+		FROM spelling_bees sb
+		JOIN spelling_lists sl ON sl.id = sb.spelling_list_id
+		WHERE sb.teacher_user_id = ?
+		ORDER BY sb.created_at DESC, sb.id DESC
+		LIMIT ?
+	`
+	rows, err := r.db.Query(query, teacherUserID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spelling bees: %w", err)
+	}
+	defer rows.Close()
+
+	var bees []models.SpellingBee
+	for rows.Next() {
+		bee, err := scanSpellingBee(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan spelling bee: %w", err)
+		}
+		bees = append(bees, *bee)
+	}
+	return bees, rows.Err()
+}
+
+// BumpVersion records that a bee has changed
+func (r *SpellingBeeRepository) BumpVersion(beeID int64, now time.Time) error {
+	if _, err := r.db.Exec("UPDATE spelling_bees SET version = version + 1, updated_at = ? WHERE id = ?", now, beeID); err != nil {
+		return fmt.Errorf("failed to update spelling bee version: %w", err)
+	}
+	return nil
+}
+
+func scanBeeParticipant(row rowScanner) (*models.SpellingBeeParticipant, error) {
+	var p models.SpellingBeeParticipant
+	var eliminatedTurn, finalPlace sql.NullInt64
+	if err := row.Scan(
+		&p.ID, &p.BeeID, &p.KidID, &p.KidName, &p.AvatarColor, &p.TurnOrder, &p.WordsCorrect,
+		&p.Misses, &eliminatedTurn, &finalPlace,
+	); err != nil {
+		return nil, err
+	}
+	if eliminatedTurn.Valid {
+		turn := int(eliminatedTurn.Int64)
+		p.EliminatedTurn = &turn
+	}
+	if finalPlace.Valid {
+		place := int(finalPlace.Int64)
+		p.FinalPlace = &place
+	}
+	return &p, nil
+}
+
+// GetParticipants retrieves the children in a bee in turn order
+func (r *SpellingBeeRepository) GetParticipants(beeID int64) ([]models.SpellingBeeParticipant, error) {
+	query := `
+		SELECT ` + beeParticipantColumns + `
+		FROM spelling_bee_participants p
+		JOIN kids k ON k.id = p.kid_id
+		WHERE p.bee_id = ?
+		ORDER BY p.turn_order ASC
+	`
+	rows, err := r.db.Query(query, beeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spelling bee participants: %w", err)
+	}
+	defer rows.Close()
+
+	var participants []models.SpellingBeeParticipant
+	for rows.Next() {
+		p, err := scanBeeParticipant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan spelling bee participant: %w", err)
+		}
+		participants = append(participants, *p)
+	}
+	return participants, rows.Err()
+}
+
+// UpdateParticipant saves a child's score in a bee
+func (r *SpellingBeeRepository) UpdateParticipant(p *models.SpellingBeeParticipant, now time.Time) error {
+	var eliminatedTurn interface{}
+	if p.EliminatedTurn != nil {
+		eliminatedTurn = *p.EliminatedTurn
+	}
+	if _, err := r.db.Exec(
+		"UPDATE spelling_bee_participants SET words_correct = ?, misses = ?, eliminated_turn = ?, updated_at = ? WHERE id = ?",
+		p.WordsCorrect, p.Misses, eliminatedTurn, now, p.ID,
+	); err != nil {
+		return fmt.Errorf("failed to update spelling bee participant: %w", err)
+	}
+	return nil
+}
+
+// FinishBee records the final place of each participant, keyed by participant ID
+func (r *SpellingBeeRepository) FinishBee(beeID int64, places map[int64]int, now time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin spelling bee transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for participantID, place := range places {
+		if _, err := tx.Exec("UPDATE spelling_bee_participants SET final_place = ?, updated_at = ? WHERE id = ?", place, now, participantID); err != nil {
+			return fmt.Errorf("failed to save spelling bee place: %w", err)
+		}
+	}
+	if _, err := tx.Exec(
+		"UPDATE spelling_bees SET status = ?, finished_at = ?, updated_at = ?, version = version + 1 WHERE id = ?",
+		models.BeeFinished, now, now, beeID,
+	); err != nil {
+		return fmt.Errorf("failed to finish spelling bee: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit spelling bee results: %w", err)
+	}
+	return nil
+}
+
+// CreateTurn records a word being dictated to a child
+func (r *SpellingBeeRepository) CreateTurn(beeID int64, turnNumber int, kidID, wordID int64, wordText string, now time.Time) (*models.SpellingBeeTurn, error) {
+	id, err := r.db.ExecReturningID(
+		"INSERT INTO spelling_bee_turns (bee_id, turn_number, kid_id, word_id, word_text, asked_at) VALUES (?, ?, ?, ?, ?, ?)",
+		beeID, turnNumber, kidID, wordID, wordText, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spelling bee turn: %w", err)
+	}
+	return &models.SpellingBeeTurn{
+		ID:         id,
+		BeeID:      beeID,
+		TurnNumber: turnNumber,
+		KidID:      kidID,
+		WordID:     &wordID,
+		WordText:   wordText,
+		AskedAt:    now,
+	}, nil
+}
+
+// AnswerTurn marks an open turn, reporting false if it had already been marked
+func (r *SpellingBeeRepository) AnswerTurn(turnID int64, answer string, isCorrect bool, now time.Time) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE spelling_bee_turns SET answer = ?, is_correct = ?, answered_at = ? WHERE id = ? AND is_correct IS NULL",
+		answer, isCorrect, now, turnID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to answer spelling bee turn: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to answer spelling bee turn: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// GetTurns retrieves every word dictated in a bee, in order
+func (r *SpellingBeeRepository) GetTurns(beeID int64) ([]models.SpellingBeeTurn, error) {
+	query := `
+		SELECT id, bee_id, turn_number, kid_id, word_id, word_text, answer, is_correct, asked_at, answered_at
+		FROM spelling_bee_turns
+		WHERE bee_id = ?
+		ORDER BY turn_number ASC
+	`
+	rows, err := r.db.Query(query, beeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spelling bee turns: %w", err)
+	}
+	defer rows.Close()
+
+	var turns []models.SpellingBeeTurn
+	for rows.Next() {
+		var turn models.SpellingBeeTurn
+		var wordID sql.NullInt64
+		var answer sql.NullString
+		var isCorrect sql.NullBool
+		var answeredAt sql.NullTime
+		if err := rows.Scan(&turn.ID, &turn.BeeID, &turn.TurnNumber, &turn.KidID, &wordID, &turn.WordText, &answer, &isCorrect, &turn.AskedAt, &answeredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan spelling bee turn: %w", err)
+		}
+		if wordID.Valid {
+			turn.WordID = &wordID.Int64
+		}
+		turn.Answer = answer.String
+		if isCorrect.Valid {
+			turn.IsCorrect = &isCorrect.Bool
+		}
+		if answeredAt.Valid {
+			turn.AnsweredAt = &answeredAt.Time
+		}
+		turns = append(turns, turn)
+	}
+	return turns, rows.Err()
+}
+
+// GetKidBees lists the bees a kid took part in, newest first, optionally only
+// those with the given status
+func (r *SpellingBeeRepository) GetKidBees(kidID int64, status string, limit int) ([]models.KidSpellingBee, error) {
+	query := `
+		SELECT ` + spellingBeeColumns + `, ` + beeParticipantColumns + `,
+			(SELECT COUNT(*) FROM spelling_bee_participants others WHERE others.bee_id = sb.id)
+		FROM spelling_bee_participants p
+		JOIN kids k ON k.id = p.kid_id
+		JOIN spelling_bees sb ON sb.id = p.bee_id
+		JOIN spelling_lists sl ON sl.id = sb.spelling_list_id
+		WHERE p.kid_id = ?`
+	args := []interface{}{kidID}
+	if status != "" {
+		query += " AND sb.status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY sb.created_at DESC, sb.id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kid spelling bees: %w", err)
+	}
+	defer rows.Close()
+
+	var bees []models.KidSpellingBee
+	for rows.Next() {
+		var entry models.KidSpellingBee
+		var finishedAt sql.NullTime
+		var eliminatedTurn, finalPlace sql.NullInt64
+		bee, p := &entry.Bee, &entry.Participant
+		if err := rows.Scan(
+			&bee.ID, &bee.TeacherUserID, &bee.SpellingListID, &bee.ListName, &bee.MaxMisses, &bee.AnswerMode,
+			&bee.Status, &bee.Version, &bee.CreatedAt, &finishedAt,
+			&p.ID, &p.BeeID, &p.KidID, &p.KidName, &p.AvatarColor, &p.TurnOrder, &p.WordsCorrect,
+			&p.Misses, &eliminatedTurn, &finalPlace,
+			&entry.TotalKids,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan kid spelling bee: %w", err)
+		}
+		if finishedAt.Valid {
+			bee.FinishedAt = &finishedAt.Time
+		}
+		if eliminatedTurn.Valid {
+			turn := int(eliminatedTurn.Int64)
+			p.EliminatedTurn = &turn
+		}
+		if finalPlace.Valid {
+			place := int(finalPlace.Int64)
+			p.FinalPlace = &place
+		}
+		bees = append(bees, entry)
+	}
+	return bees, rows.Err()
+}
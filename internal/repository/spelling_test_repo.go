@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// SpellingTestRepository handles scheduled spelling tests and children's attempts at them
+type SpellingTestRepository struct {
+	db *database.DB
+}
+
+// NewSpellingTestRepository creates a new spelling test repository
+func NewSpellingTestRepository(db *database.DB) *SpellingTestRepository {
+	return &SpellingTestRepository{db: db}
+}
+
+// CreateTest schedules a test on a list for a class
+func (r *SpellingTestRepository) CreateTest(classID, listID, createdBy int64, opensAt, closesAt time.Time) (*models.SpellingTest, error) {
+	query := `
+		INSERT INTO spelling_tests (class_id, spelling_list_id, created_by, opens_at, closes_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	id, err := r.db.ExecReturningID(query, classID, listID, createdBy, opensAt, closesAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spelling test: %w", err)
+	}
+
+	return &models.SpellingTest{
+		ID:             id,
+		ClassID:        classID,
+		SpellingListID: listID,
+		CreatedBy:      createdBy,
+		OpensAt:        opensAt,
+		ClosesAt:       closesAt,
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+// GetTestByID retrieves a test with its list name
+func (r *SpellingTestRepository) GetTestByID(testID int64) (*models.SpellingTest, error) {
+	query := `
+		SELECT st.id, st.class_id, st.spelling_list_id, sl.name, st.created_by, st.opens_at, st.closes_at, st.created_at
+		FROM spelling_tests st
+		INNER JOIN spelling_lists sl ON sl.id = st.spelling_list_id
+		WHERE st.id = ?
+	`
+	var test models.SpellingTest
+	err := r.db.QueryRow(query, testID).Scan(
+		&test.ID,
+		&test.ClassID,
+		&test.SpellingListID,
+		&test.ListName,
+		&test.CreatedBy,
+		&test.OpensAt,
+		&test.ClosesAt,
+		&test.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spelling test: %w", err)
+	}
+	return &test, nil
+}
+
+// GetClassTests retrieves a class's tests with the number of completed attempts,
+// most recent window first
+func (r *SpellingTestRepository) GetClassTests(classID int64) ([]models.SpellingTest, error) {
+	query := `
+		SELECT st.id, st.class_id, st.spelling_list_id, sl.name, st.created_by, st.opens_at, st.closes_at, st.created_at,
+		       (SELECT COUNT(*) FROM spelling_test_attempts sta WHERE sta.test_id = st.id AND sta.completed_at IS NOT NULL)
+		FROM spelling_tests st
+		INNER JOIN spelling_lists sl ON sl.id = st.spelling_list_id
+		WHERE st.class_id = ?
+		ORDER BY st.opens_at DESC, st.id DESC
+	`
+	rows, err := r.db.Query(query, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query class spelling tests: %w", err)
+	}
+	defer rows.Close()
+
+	var tests []models.SpellingTest
+	for rows.Next() {
+		var test models.SpellingTest
+		if err := rows.Scan(
+			&test.ID,
+			&test.ClassID,
+			&test.SpellingListID,
+			&test.ListName,
+			&test.CreatedBy,
+			&test.OpensAt,
+			&test.ClosesAt,
+			&test.CreatedAt,
+			&test.CompletedCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan spelling test: %w", err)
+		}
+		tests = append(tests, test)
+	}
+
+	return tests, rows.Err()
+}
+
+// GetKidTests retrieves the tests for every active class a kid belongs to,
+// soonest closing first. Callers decide which windows are open.
+func (r *SpellingTestRepository) GetKidTests(kidID int64) ([]models.SpellingTest, error) {
+	query := `
+		SELECT st.id, st.class_id, st.spelling_list_id, sl.name, st.created_by, st.opens_at, st.closes_at, st.created_at
+		FROM spelling_tests st
+		INNER JOIN spelling_lists sl ON sl.id = st.spelling_list_id
+		INNER JOIN teacher_classes tc ON tc.id = st.class_id
+		INNER JOIN teacher_class_members tcm ON tcm.class_id = st.class_id
+		WHERE tcm.kid_id = ? AND tc.archived_at IS NULL
+		ORDER BY st.closes_at ASC, st.id ASC
+	`
+	rows, err := r.db.Query(query, kidID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query kid spelling tests: %w", err)
+	}
+	defer rows.Close()
+
+	var tests []models.SpellingTest
+	for rows.Next() {
+		var test models.SpellingTest
+		if err := rows.Scan(
+			&test.ID,
+			&test.ClassID,
+			&test.SpellingListID,
+			&test.ListName,
+			&test.CreatedBy,
+			&test.OpensAt,
+			&test.ClosesAt,
+			&test.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan spelling test: %w", err)
+		}
+		tests = append(tests, test)
+	}
+
+	return tests, rows.Err()
+}
+
+// DeleteTest deletes a test along with every attempt at it
+func (r *SpellingTestRepository) DeleteTest(testID int64) error {
+	if _, err := r.db.Exec("DELETE FROM spelling_tests WHERE id = ?", testID); err != nil {
+		return fmt.Errorf("failed to delete spelling test: %w", err)
+	}
+	return nil
+}
+
+// CreateAttempt starts a kid's attempt at a test
+func (r *SpellingTestRepository) CreateAttempt(testID, kidID int64, totalWords int) (*models.SpellingTestAttempt, error) {
+	query := "INSERT INTO spelling_test_attempts (test_id, kid_id, total_words) VALUES (?, ?, ?)"
+	id, err := r.db.ExecReturningID(query, testID, kidID, totalWords)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spelling test attempt: %w", err)
+	}
+
+	return &models.SpellingTestAttempt{
+		ID:         id,
+		TestID:     testID,
+		KidID:      kidID,
+		TotalWords: totalWords,
+		StartedAt:  time.Now(),
+	}, nil
+}
+
+// GetAttempt retrieves a kid's attempt at a test
+func (r *SpellingTestRepository) GetAttempt(testID, kidID int64) (*models.SpellingTestAttempt, error) {
+	query := `
+		SELECT id, test_id, kid_id, total_words, correct_words, started_at, completed_at
+		FROM spelling_test_attempts
+		WHERE test_id = ? AND kid_id = ?
+	`
+	attempt, err := scanSpellingTestAttempt(r.db.QueryRow(query, testID, kidID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spelling test attempt: %w", err)
+	}
+	return attempt, nil
+}
+
+// GetTestAttempts retrieves every attempt at a test
+func (r *SpellingTestRepository) GetTestAttempts(testID int64) ([]models.SpellingTestAttempt, error) {
+	query := `
+		SELECT id, test_id, kid_id, total_words, correct_words, started_at, completed_at
+		FROM spelling_test_attempts
+		WHERE test_id = ?
+		ORDER BY id ASC
+	`
+	rows, err := r.db.Query(query, testID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spelling test attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []models.SpellingTestAttempt
+	for rows.Next() {
+		attempt, err := scanSpellingTestAttempt(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan spelling test attempt: %w", err)
+		}
+		attempts = append(attempts, *attempt)
+	}
+
+	return attempts, rows.Err()
+}
+
+// RecordAnswer stores a kid's answer to a word and updates the attempt's score.
+// Each word can only be answered once per attempt.
+func (r *SpellingTestRepository) RecordAnswer(attemptID, wordID int64, wordText, answer string, isCorrect bool) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin spelling test answer transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO spelling_test_answers (attempt_id, word_id, word_text, answer, is_correct)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	if _, err := tx.Exec(query, attemptID, wordID, wordText, answer, isCorrect); err != nil {
+		return fmt.Errorf("failed to record spelling test answer: %w", err)
+	}
+	if isCorrect {
+		if _, err := tx.Exec("UPDATE spelling_test_attempts SET correct_words = correct_words + 1 WHERE id = ?", attemptID); err != nil {
+			return fmt.Errorf("failed to update spelling test score: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit spelling test answer: %w", err)
+	}
+	return nil
+}
+
+// CompleteAttempt marks an attempt as finished, fixing the number of words it was marked out of
+func (r *SpellingTestRepository) CompleteAttempt(attemptID int64, totalWords int, completedAt time.Time) error {
+	query := "UPDATE spelling_test_attempts SET total_words = ?, completed_at = ? WHERE id = ?"
+	if _, err := r.db.Exec(query, totalWords, completedAt, attemptID); err != nil {
+		return fmt.Errorf("failed to complete spelling test attempt: %w", err)
+	}
+	return nil
+}
+
+// GetAttemptAnswers retrieves the answers in an attempt in the order they were given
+func (r *SpellingTestRepository) GetAttemptAnswers(attemptID int64) ([]models.SpellingTestAnswer, error) {
+	query := `
+		SELECT id, attempt_id, word_id, word_text, answer, is_correct, answered_at
+		FROM spelling_test_answers
+		WHERE attempt_id = ?
+		ORDER BY id ASC
+	`
+	rows, err := r.db.Query(query, attemptID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spelling test answers: %w", err)
+	}
+	defer rows.Close()
+
+	var answers []models.SpellingTestAnswer
+	for rows.Next() {
+		var answer models.SpellingTestAnswer
+		if err := rows.Scan(
+			&answer.ID,
+			&answer.AttemptID,
+			&answer.WordID,
+			&answer.WordText,
+			&answer.Answer,
+			&answer.IsCorrect,
+			&answer.AnsweredAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan spelling test answer: %w", err)
+		}
+		answers = append(answers, answer)
+	}
+
+	return answers, rows.Err()
+}
+
+func scanSpellingTestAttempt(row rowScanner) (*models.SpellingTestAttempt, error) {
+	var attempt models.SpellingTestAttempt
+	var completedAt sql.NullTime
+	if err := row.Scan(
+		&attempt.ID,
+		&attempt.TestID,
+		&attempt.KidID,
+		&attempt.TotalWords,
+		&attempt.CorrectWords,
+		&attempt.StartedAt,
+		&completedAt,
+	); err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		attempt.CompletedAt = &completedAt.Time
+	}
+	return &attempt, nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// StreakRepository handles families' streak settings and the activity streaks are counted from
+type StreakRepository struct {
+	db *database.DB
+}
+
+// NewStreakRepository creates a new streak repository
+func NewStreakRepository(db *database.DB) *StreakRepository {
+	return &StreakRepository{db: db}
+}
+
+// GetSettings retrieves a family's streak settings, or nil if they have never set any
+func (r *StreakRepository) GetSettings(familyCode string) (*models.StreakSettings, error) {
+	query := `SELECT family_code, timezone, weekends_off, updated_at FROM streak_settings WHERE family_code = ?`
+
+	var settings models.StreakSettings
+	err := r.db.QueryRow(query, familyCode).Scan(&settings.FamilyCode, &settings.Timezone, &settings.WeekendsOff, &settings.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streak settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// SaveSettings creates or updates a family's streak settings
+func (r *StreakRepository) SaveSettings(familyCode, timezone string, weekendsOff bool, now time.Time) error {
+	result, err := r.db.Exec(
+		"UPDATE streak_settings SET timezone = ?, weekends_off = ?, updated_at = ? WHERE family_code = ?",
+		timezone, weekendsOff, now, familyCode,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update streak settings: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+		return nil
+	}
+
+	if _, err := r.db.Exec(
+		"INSERT INTO streak_settings (family_code, timezone, weekends_off, updated_at) VALUES (?, ?, ?, ?)",
+		familyCode, timezone, weekendsOff, now,
+	); err != nil {
+		return fmt.Errorf("failed to create streak settings: %w", err)
+	}
+	return nil
+}
+
+// GetFreezes lists a family's days off, earliest first
+func (r *StreakRepository) GetFreezes(familyCode string) ([]models.StreakFreeze, error) {
+	query := `
+		SELECT id, family_code, name, start_date, end_date, created_at
+		FROM streak_freezes
+		WHERE family_code = ?
+		ORDER BY start_date, id
+	`
+
+	rows, err := r.db.Query(query, familyCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streak freezes: %w", err)
+	}
+	defer rows.Close()
+
+	var freezes []models.StreakFreeze
+	for rows.Next() {
+		var freeze models.StreakFreeze
+		if err := rows.Scan(&freeze.ID, &freeze.FamilyCode, &freeze.Name, &freeze.StartDate, &freeze.EndDate, &freeze.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan streak freeze: %w", err)
+		}
+		freezes = append(freezes, freeze)
+	}
+	return freezes, rows.Err()
+}
+
+// CreateFreeze adds days off for a family
+func (r *StreakRepository) CreateFreeze(familyCode, name, startDate, endDate string) (*models.StreakFreeze, error) {
+	freeze := &models.StreakFreeze{
+		FamilyCode: familyCode,
+		Name:       name,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		CreatedAt:  time.Now(),
+	}
+	id, err := r.db.ExecReturningID(
+		"INSERT INTO streak_freezes (family_code, name, start_date, end_date, created_at) VALUES (?, ?, ?, ?, ?)",
+		familyCode, name, startDate, endDate, freeze.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create streak freeze: %w", err)
+	}
+	freeze.ID = id
+	return freeze, nil
+}
+
+// DeleteFreeze removes a family's days off, reporting whether they existed
+func (r *StreakRepository) DeleteFreeze(familyCode string, id int64) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM streak_freezes WHERE id = ? AND family_code = ?", id, familyCode)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete streak freeze: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete streak freeze: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// GetKidCompletionTimes lists when a kid completed sessions in any game mode since a time
+func (r *StreakRepository) GetKidCompletionTimes(kidID int64, since time.Time) ([]time.Time, error) {
+	var times []time.Time
+	for _, src := range activitySources {
+		query := fmt.Sprintf(
+			"SELECT completed_at FROM %s WHERE kid_id = ? AND completed_at IS NOT NULL AND %s",
+			src.sessions, r.db.Dialect.TimestampAtOrAfter("completed_at"),
+		)
+		rows, err := r.db.Query(query, kidID, since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s: %w", src.sessions, err)
+		}
+		for rows.Next() {
+			var completedAt time.Time
+			if err := rows.Scan(&completedAt); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan %s: %w", src.sessions, err)
+			}
+			times = append(times, completedAt)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return times, nil
+}
+
+// GetKidCorrectStreak counts a kid's correct answers in any game mode since their
+// last wrong one
+func (r *StreakRepository) GetKidCorrectStreak(kidID int64) (int, error) {
+	// Find the most recent wrong answer. IDs grow with time within a table, so
+	// each table's newest is found by ID and the times compared across them.
+	var lastWrong *time.Time
+	for _, src := range activitySources {
+		query := fmt.Sprintf(`
+			SELECT i.%[1]s
+			FROM %[2]s i
+			JOIN %[3]s s ON s.id = i.%[4]s
+			WHERE s.kid_id = ? AND i.%[1]s IS NOT NULL AND i.%[5]s = FALSE
+			ORDER BY i.id DESC
+			LIMIT 1
+		`, src.answeredAt, src.items, src.sessions, src.sessionFK, src.correct)
+
+		var answeredAt time.Time
+		err := r.db.QueryRow(query, kidID).Scan(&answeredAt)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to find last wrong answer in %s: %w", src.items, err)
+		}
+		if lastWrong == nil || answeredAt.After(*lastWrong) {
+			lastWrong = &answeredAt
+		}
+	}
+
+	streak := 0
+	for _, src := range activitySources {
+		query := fmt.Sprintf(`
+			SELECT COUNT(*)
+			FROM %[2]s i
+			JOIN %[3]s s ON s.id = i.%[4]s
+			WHERE s.kid_id = ? AND i.%[1]s IS NOT NULL AND i.%[5]s = TRUE
+		`, src.answeredAt, src.items, src.sessions, src.sessionFK, src.correct)
+		args := []interface{}{kidID}
+		if lastWrong != nil {
+			query += " AND " + r.db.Dialect.TimestampAtOrAfter("i."+src.answeredAt)
+			args = append(args, *lastWrong)
+		}
+
+		var count int
+		if err := r.db.QueryRow(query, args...).Scan(&count); err != nil {
+			return 0, fmt.Errorf("failed to count correct answers in %s: %w", src.items, err)
+		}
+		streak += count
+	}
+	return streak, nil
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// TeacherClassRepository handles teacher classes, their members and class list assignments
+type TeacherClassRepository struct {
+	db *database.DB
+}
+
+// NewTeacherClassRepository creates a new teacher class repository
+func NewTeacherClassRepository(db *database.DB) *TeacherClassRepository {
+	return &TeacherClassRepository{db: db}
+}
+
+// CreateClass creates a new class for a teacher
+func (r *TeacherClassRepository) CreateClass(teacherUserID int64, name string) (*models.TeacherClass, error) {
+	query := "INSERT INTO teacher_classes (teacher_user_id, name) VALUES (?, ?)"
+	id, err := r.db.ExecReturningID(query, teacherUserID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create class: %w", err)
+	}
+
+	now := time.Now()
+	return &models.TeacherClass{
+		ID:            id,
+		TeacherUserID: teacherUserID,
+		Name:          name,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// GetClassByID retrieves a class by ID
+func (r *TeacherClassRepository) GetClassByID(classID int64) (*models.TeacherClass, error) {
+	query := `
+		SELECT id, teacher_user_id, name, archived_at, created_at, updated_at
+		FROM teacher_classes
+		WHERE id = ?
+	`
+	var class models.TeacherClass
+	var archivedAt sql.NullTime
+	err := r.db.QueryRow(query, classID).Scan(
+		&class.ID,
+		&class.TeacherUserID,
+		&class.Name,
+		&archivedAt,
+		&class.CreatedAt,
+		&class.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class: %w", err)
+	}
+	if archivedAt.Valid {
+		class.ArchivedAt = &archivedAt.Time
+	}
+	return &class, nil
+}
+
+// GetTeacherClasses retrieves a teacher's classes with member and list counts,
+// active classes first and then by name
+func (r *TeacherClassRepository) GetTeacherClasses(teacherUserID int64, includeArchived bool) ([]models.TeacherClass, error) {
+	query := `
+		SELECT tc.id, tc.teacher_user_id, tc.name, tc.archived_at, tc.created_at, tc.updated_at,
+		       (SELECT COUNT(*) FROM teacher_class_members tcm WHERE tcm.class_id = tc.id),
+		       (SELECT COUNT(*) FROM teacher_class_lists tcl WHERE tcl.class_id = tc.id)
+		FROM teacher_classes tc
+		WHERE tc.teacher_user_id = ?
+	`
+	if !includeArchived {
+		query += " AND tc.archived_at IS NULL"
+	}
+	query += " ORDER BY CASE WHEN tc.archived_at IS NULL THEN 0 ELSE 1 END, tc.name ASC, tc.id ASC"
+
+	rows, err := r.db.Query(query, teacherUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query classes: %w", err)
+	}
+	defer rows.Close()
+
+	var classes []models.TeacherClass
+	for rows.Next() {
+		var class models.TeacherClass
+		var archivedAt sql.NullTime
+		if err := rows.Scan(
+			&class.ID,
+			&class.TeacherUserID,
+			&class.Name,
+			&archivedAt,
+			&class.CreatedAt,
+			&class.UpdatedAt,
+			&class.MemberCount,
+			&class.ListCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan class: %w", err)
+		}
+		if archivedAt.Valid {
+			class.ArchivedAt = &archivedAt.Time
+		}
+		classes = append(classes, class)
+	}
+
+	return classes, rows.Err()
+}
+
+// ActiveClassNameExists checks whether a teacher already has an active class with
+// the given name, ignoring case and the class being renamed
+func (r *TeacherClassRepository) ActiveClassNameExists(teacherUserID int64, name string, excludeClassID int64) (bool, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM teacher_classes
+		WHERE teacher_user_id = ? AND LOWER(name) = LOWER(?) AND archived_at IS NULL AND id <> ?
+	`
+	var count int
+	if err := r.db.QueryRow(query, teacherUserID, name, excludeClassID).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check class name: %w", err)
+	}
+	return count > 0, nil
+}
+
+// RenameClass changes a class's name
+func (r *TeacherClassRepository) RenameClass(classID int64, name string) error {
+	query := "UPDATE teacher_classes SET name = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	if _, err := r.db.Exec(query, name, classID); err != nil {
+		return fmt.Errorf("failed to rename class: %w", err)
+	}
+	return nil
+}
+
+// SetArchivedAt archives a class, or restores it when archivedAt is nil
+func (r *TeacherClassRepository) SetArchivedAt(classID int64, archivedAt *time.Time) error {
+	query := "UPDATE teacher_classes SET archived_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+	if _, err := r.db.Exec(query, archivedAt, classID); err != nil {
+		return fmt.Errorf("failed to update class archive state: %w", err)
+	}
+	return nil
+}
+
+// IsClassMember checks if a kid belongs to a class
+func (r *TeacherClassRepository) IsClassMember(classID, kidID int64) (bool, error) {
+	query := "SELECT COUNT(*) FROM teacher_class_members WHERE class_id = ? AND kid_id = ?"
+	var count int
+	if err := r.db.QueryRow(query, classID, kidID).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check class membership: %w", err)
+	}
+	return count > 0, nil
+}
+
+// AddClassMember adds a kid to a class
+func (r *TeacherClassRepository) AddClassMember(classID, kidID int64) error {
+	query := "INSERT INTO teacher_class_members (class_id, kid_id) VALUES (?, ?)"
+	if _, err := r.db.Exec(query, classID, kidID); err != nil {
+		return fmt.Errorf("failed to add class member: %w", err)
+	}
+	return nil
+}
+
+// RemoveClassMember removes a kid from a class, reporting whether they were a member
+func (r *TeacherClassRepository) RemoveClassMember(classID, kidID int64) (bool, error) {
+	query := "DELETE FROM teacher_class_members WHERE class_id = ? AND kid_id = ?"
+	result, err := r.db.Exec(query, classID, kidID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove class member: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check removed class member: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// GetClassMembers retrieves the kids in a class ordered by name
+func (r *TeacherClassRepository) GetClassMembers(classID int64) ([]models.Kid, error) {
+	query := `
+		SELECT k.id, k.family_code, k.name, k.username, COALESCE(k.password, ''), k.avatar_color, k.created_at, k.updated_at
+		FROM teacher_class_members tcm
+		INNER JOIN kids k ON k.id = tcm.kid_id
+		WHERE tcm.class_id = ?
+		ORDER BY k.name ASC, k.id ASC
+	`
+	rows, err := r.db.Query(query, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query class members: %w", err)
+	}
+	defer rows.Close()
+
+	var kids []models.Kid
+	for rows.Next() {
+		var kid models.Kid
+		if err := rows.Scan(
+			&kid.ID,
+			&kid.FamilyCode,
+			&kid.Name,
+			&kid.Username,
+			&kid.Password,
+			&kid.AvatarColor,
+			&kid.CreatedAt,
+			&kid.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan class member: %w", err)
+		}
+		kids = append(kids, kid)
+	}
+
+	return kids, rows.Err()
+}
+
+// SetClassList assigns a list to a class, replacing the due date of an existing assignment
+func (r *TeacherClassRepository) SetClassList(classID, listID int64, dueDate *time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin class list transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM teacher_class_lists WHERE class_id = ? AND spelling_list_id = ?", classID, listID); err != nil {
+		return fmt.Errorf("failed to clear existing class list: %w", err)
+	}
+	if _, err := tx.Exec("INSERT INTO teacher_class_lists (class_id, spelling_list_id, due_date) VALUES (?, ?, ?)", classID, listID, dueDate); err != nil {
+		return fmt.Errorf("failed to assign list to class: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit class list transaction: %w", err)
+	}
+	return nil
+}
+
+// RemoveClassList removes a list from a class, reporting whether it was assigned
+func (r *TeacherClassRepository) RemoveClassList(classID, listID int64) (bool, error) {
+	query := "DELETE FROM teacher_class_lists WHERE class_id = ? AND spelling_list_id = ?"
+	result, err := r.db.Exec(query, classID, listID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove class list: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check removed class list: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// GetClassLists retrieves the lists assigned to a class, most recent first
+func (r *TeacherClassRepository) GetClassLists(classID int64) ([]models.TeacherClassList, error) {
+	query := `
+		SELECT tcl.class_id, tcl.spelling_list_id, sl.name, tcl.due_date, tcl.assigned_at
+		FROM teacher_class_lists tcl
+		INNER JOIN spelling_lists sl ON sl.id = tcl.spelling_list_id
+		WHERE tcl.class_id = ?
+		ORDER BY tcl.assigned_at DESC, tcl.id DESC
+	`
+	rows, err := r.db.Query(query, classID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query class lists: %w", err)
+	}
+	defer rows.Close()
+
+	var lists []models.TeacherClassList
+	for rows.Next() {
+		var list models.TeacherClassList
+		var dueDate sql.NullTime
+		if err := rows.Scan(&list.ClassID, &list.ListID, &list.ListName, &dueDate, &list.AssignedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan class list: %w", err)
+		}
+		if dueDate.Valid {
+			list.DueDate = &dueDate.Time
+		}
+		lists = append(lists, list)
+	}
+
+	return lists, rows.Err()
+}
+
+// IsListAssignedThroughOtherClass checks if a kid also gets a list from another
+// active class, so removing it from one class must keep the kid's assignment
+func (r *TeacherClassRepository) IsListAssignedThroughOtherClass(kidID, listID, excludeClassID int64) (bool, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM teacher_class_lists tcl
+		INNER JOIN teacher_class_members tcm ON tcm.class_id = tcl.class_id
+		INNER JOIN teacher_classes tc ON tc.id = tcl.class_id
+		WHERE tcm.kid_id = ? AND tcl.spelling_list_id = ? AND tcl.class_id <> ? AND tc.archived_at IS NULL
+	`
+	var count int
+	if err := r.db.QueryRow(query, kidID, listID, excludeClassID).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check class list assignments: %w", err)
+	}
+	return count > 0, nil
+}
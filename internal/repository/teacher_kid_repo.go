@@ -78,3 +78,30 @@ func (r *TeacherKidRepository) GetTeacherKids(teacherUserID int64) ([]models.Kid
 
 	return kids, nil
 }
+
+// GetKidTeacherIDs retrieves the IDs of all teachers linked to a kid.
+func (r *TeacherKidRepository) GetKidTeacherIDs(kidID int64) ([]int64, error) {
+	query := `
+		SELECT teacher_user_id
+		FROM teacher_kid_relationships
+		WHERE kid_id = ?
+		ORDER BY teacher_user_id
+	`
+
+	rows, err := r.db.Query(query, kidID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query kid teachers: %w", err)
+	}
+	defer rows.Close()
+
+	var teacherIDs []int64
+	for rows.Next() {
+		var teacherID int64
+		if err := rows.Scan(&teacherID); err != nil {
+			return nil, fmt.Errorf("failed to scan kid teacher: %w", err)
+		}
+		teacherIDs = append(teacherIDs, teacherID)
+	}
+
+	return teacherIDs, rows.Err()
+}
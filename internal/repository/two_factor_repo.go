@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"time"
+)
+
+// TwoFactorRepository handles TOTP authenticators, recovery codes and pending
+// two-factor sign-ins
+type TwoFactorRepository struct {
+	db *database.DB
+}
+
+// NewTwoFactorRepository creates a new two-factor repository
+func NewTwoFactorRepository(db *database.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// GetTwoFactor retrieves a user's authenticator, or nil if they haven't started enrolment
+func (r *TwoFactorRepository) GetTwoFactor(userID int64) (*models.TwoFactor, error) {
+	query := `SELECT user_id, secret, enabled_at, last_used_step, created_at FROM user_two_factor WHERE user_id = ?`
+
+	var tf models.TwoFactor
+	var enabledAt sql.NullTime
+	err := r.db.QueryRow(query, userID).Scan(&tf.UserID, &tf.Secret, &enabledAt, &tf.LastUsedStep, &tf.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get two-factor settings: %w", err)
+	}
+	if enabledAt.Valid {
+		tf.EnabledAt = &enabledAt.Time
+	}
+	return &tf, nil
+}
+
+// GetEnabledUserIDs returns the IDs of every user with two-factor authentication on
+func (r *TwoFactorRepository) GetEnabledUserIDs() (map[int64]bool, error) {
+	rows, err := r.db.Query("SELECT user_id FROM user_two_factor WHERE enabled_at IS NOT NULL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query two-factor users: %w", err)
+	}
+	defer rows.Close()
+
+	enabled := make(map[int64]bool)
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan two-factor user: %w", err)
+		}
+		enabled[userID] = true
+	}
+	return enabled, rows.Err()
+}
+
+// StartEnrolment stores a new, not yet enabled, secret for a user, replacing any
+// earlier enrolment they didn't finish
+func (r *TwoFactorRepository) StartEnrolment(userID int64, secret string, now time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM user_two_factor WHERE user_id = ? AND enabled_at IS NULL", userID); err != nil {
+		return fmt.Errorf("failed to clear unfinished enrolment: %w", err)
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO user_two_factor (user_id, secret, last_used_step, created_at) VALUES (?, ?, 0, ?)",
+		userID, secret, now,
+	); err != nil {
+		return fmt.Errorf("failed to start two-factor enrolment: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Enable turns on a user's pending authenticator after they confirmed a code from
+// step, and stores their recovery codes
+func (r *TwoFactorRepository) Enable(userID int64, step int64, recoveryCodeHashes []string, now time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"UPDATE user_two_factor SET enabled_at = ?, last_used_step = ? WHERE user_id = ? AND enabled_at IS NULL",
+		now, step, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return fmt.Errorf("failed to enable two-factor authentication: no pending enrolment")
+	}
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UseStep records that a code from step was accepted, reporting false if that
+// step or a later one was already used
+func (r *TwoFactorRepository) UseStep(userID int64, step int64) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE user_two_factor SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?",
+		step, userID, step,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record two-factor code: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record two-factor code: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ReplaceRecoveryCodes swaps a user's recovery codes for new ones
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(tx *database.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.Exec("DELETE FROM two_factor_recovery_codes WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec("INSERT INTO two_factor_recovery_codes (user_id, code_hash) VALUES (?, ?)", userID, hash); err != nil {
+			return fmt.Errorf("failed to store recovery code: %w", err)
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode marks a user's unused recovery code as used, reporting whether there was one
+func (r *TwoFactorRepository) UseRecoveryCode(userID int64, codeHash string, now time.Time) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE two_factor_recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL",
+		now, userID, codeHash,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// CountUnusedRecoveryCodes returns how many recovery codes a user has left
+func (r *TwoFactorRepository) CountUnusedRecoveryCodes(userID int64) (int, error) {
+	var count int
+	if err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM two_factor_recovery_codes WHERE user_id = ? AND used_at IS NULL", userID,
+	).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return count, nil
+}
+
+// Disable removes a user's authenticator and recovery codes
+func (r *TwoFactorRepository) Disable(userID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM two_factor_recovery_codes WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM user_two_factor WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM login_challenges WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete login challenges: %w", err)
+	}
+	return tx.Commit()
+}
+
+// CreateLoginChallenge stores a sign-in waiting for its two-factor code
+func (r *TwoFactorRepository) CreateLoginChallenge(id string, userID int64, expiresAt time.Time) (*models.LoginChallenge, error) {
+	now := time.Now()
+	if _, err := r.db.Exec(
+		"INSERT INTO login_challenges (id, user_id, attempts, expires_at, created_at) VALUES (?, ?, 0, ?, ?)",
+		id, userID, expiresAt, now,
+	); err != nil {
+		return nil, fmt.Errorf("failed to create login challenge: %w", err)
+	}
+	return &models.LoginChallenge{ID: id, UserID: userID, ExpiresAt: expiresAt, CreatedAt: now}, nil
+}
+
+// GetLoginChallenge retrieves a login challenge, or nil if there isn't one
+func (r *TwoFactorRepository) GetLoginChallenge(id string) (*models.LoginChallenge, error) {
+	var challenge models.LoginChallenge
+	err := r.db.QueryRow(
+		"SELECT id, user_id, attempts, expires_at, created_at FROM login_challenges WHERE id = ?", id,
+	).Scan(&challenge.ID, &challenge.UserID, &challenge.Attempts, &challenge.ExpiresAt, &challenge.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login challenge: %w", err)
+	}
+	return &challenge, nil
+}
+
+// RecordFailedAttempt counts a wrong code against a login challenge
+func (r *TwoFactorRepository) RecordFailedAttempt(id string) error {
+	if _, err := r.db.Exec("UPDATE login_challenges SET attempts = attempts + 1 WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to update login challenge: %w", err)
+	}
+	return nil
+}
+
+// DeleteLoginChallenge removes a login challenge
+func (r *TwoFactorRepository) DeleteLoginChallenge(id string) error {
+	if _, err := r.db.Exec("DELETE FROM login_challenges WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete login challenge: %w", err)
+	}
+	return nil
+}
+
+// DeleteUserLoginChallenges removes a user's login challenges, so a half-finished
+// sign-in can't be completed after their password changes
+func (r *TwoFactorRepository) DeleteUserLoginChallenges(userID int64) error {
+	if _, err := r.db.Exec("DELETE FROM login_challenges WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete login challenges: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredLoginChallenges removes login challenges that were never completed
+func (r *TwoFactorRepository) DeleteExpiredLoginChallenges() error {
+	if _, err := r.db.Exec("DELETE FROM login_challenges WHERE expires_at < ?", time.Now()); err != nil {
+		return fmt.Errorf("failed to delete expired login challenges: %w", err)
+	}
+	return nil
+}
@@ -50,8 +50,6 @@ func (r *UserRepository) CreateUserWithRole(email, passwordHash, name string, is
 		Email:        email,
 		PasswordHash: passwordHash,
 		Name:         name,
-		OAuthProvider: "",
-		OAuthSubject:  "",
 		IsAdmin:      isAdmin,
 		IsTeacher:    isTeacher,
 		CreatedAt:    time.Now(),
@@ -64,7 +62,7 @@ func (r *UserRepository) CreateUserWithRole(email, passwordHash, name string, is
 // GetUserByEmail retrieves a user by email address
 func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, name, COALESCE(oauth_provider, ''), COALESCE(oauth_subject, ''), is_admin, is_teacher, created_at, updated_at
+		SELECT id, email, password_hash, name, is_admin, is_teacher, created_at, updated_at
 		FROM users
 		WHERE email = ?
 	`
@@ -74,8 +72,6 @@ func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.Name,
-		&user.OAuthProvider,
-		&user.OAuthSubject,
 		&user.IsAdmin,
 		&user.IsTeacher,
 		&user.CreatedAt,
@@ -95,7 +91,7 @@ func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 // GetUserByID retrieves a user by ID
 func (r *UserRepository) GetUserByID(id int64) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, name, COALESCE(oauth_provider, ''), COALESCE(oauth_subject, ''), is_admin, is_teacher, created_at, updated_at
+		SELECT id, email, password_hash, name, is_admin, is_teacher, created_at, updated_at
 		FROM users
 		WHERE id = ?
 	`
@@ -105,8 +101,6 @@ func (r *UserRepository) GetUserByID(id int64) (*models.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.Name,
-		&user.OAuthProvider,
-		&user.OAuthSubject,
 		&user.IsAdmin,
 		&user.IsTeacher,
 		&user.CreatedAt,
@@ -147,26 +141,103 @@ func (r *UserRepository) CreateSession(sessionID string, userID int64, expiresAt
 // GetSession retrieves a session by ID
 func (r *UserRepository) GetSession(sessionID string) (*models.Session, error) {
 	query := `
-		SELECT id, user_id, expires_at, created_at
+		SELECT id, user_id, expires_at, created_at, last_seen_at, user_agent, ip_address
 		FROM sessions
 		WHERE id = ?
 	`
+	session, err := scanSession(r.db.QueryRow(query, sessionID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return session, nil
+}
+
+// GetUserSessions retrieves a user's unexpired sessions, most recently used first
+func (r *UserRepository) GetUserSessions(userID int64) ([]models.Session, error) {
+	query := `
+		SELECT id, user_id, expires_at, created_at, last_seen_at, user_agent, ip_address
+		FROM sessions
+		WHERE user_id = ? AND expires_at > ?
+		ORDER BY COALESCE(last_seen_at, created_at) DESC
+	`
+	rows, err := r.db.Query(query, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+func scanSession(row rowScanner) (*models.Session, error) {
 	session := &models.Session{}
-	err := r.db.QueryRow(query, sessionID).Scan(
+	var lastSeenAt sql.NullTime
+	var userAgent, ipAddress sql.NullString
+	if err := row.Scan(
 		&session.ID,
 		&session.UserID,
 		&session.ExpiresAt,
 		&session.CreatedAt,
-	)
+		&lastSeenAt,
+		&userAgent,
+		&ipAddress,
+	); err != nil {
+		return nil, err
+	}
+	if lastSeenAt.Valid {
+		session.LastSeenAt = &lastSeenAt.Time
+	}
+	session.UserAgent = userAgent.String
+	session.IPAddress = ipAddress.String
+	return session, nil
+}
 
-	if err == sql.ErrNoRows {
-		return nil, nil
+// TouchSession records that a session was used from a device. Sessions seen
+// since staleBefore are left alone, so not every request writes to the database.
+func (r *UserRepository) TouchSession(sessionID, userAgent, ipAddress string, now, staleBefore time.Time) error {
+	query := `
+		UPDATE sessions SET last_seen_at = ?, user_agent = ?, ip_address = ?
+		WHERE id = ? AND (last_seen_at IS NULL OR last_seen_at < ?)
+	`
+	if _, err := r.db.Exec(query, now, userAgent, ipAddress, sessionID, staleBefore); err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
 	}
+	return nil
+}
+
+// DeleteUserSession removes one of a user's sessions, reporting whether it existed
+func (r *UserRepository) DeleteUserSession(userID int64, sessionID string) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM sessions WHERE id = ? AND user_id = ?", sessionID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get session: %w", err)
+		return false, fmt.Errorf("failed to delete session: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete session: %w", err)
 	}
+	return affected > 0, nil
+}
 
-	return session, nil
+// DeleteUserSessions removes all of a user's sessions except keepSessionID, which
+// may be empty to remove them all, returning how many were removed
+func (r *UserRepository) DeleteUserSessions(userID int64, keepSessionID string) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM sessions WHERE user_id = ? AND id <> ?", userID, keepSessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	return result.RowsAffected()
 }
 
 // DeleteSession removes a session from the database
@@ -192,7 +263,7 @@ func (r *UserRepository) DeleteExpiredSessions() error {
 // GetAllUsers retrieves all users
 func (r *UserRepository) GetAllUsers() ([]models.User, error) {
 	query := `
-		SELECT id, email, password_hash, name, COALESCE(oauth_provider, ''), COALESCE(oauth_subject, ''), is_admin, is_teacher, created_at, updated_at
+		SELECT id, email, password_hash, name, is_admin, is_teacher, created_at, updated_at
 		FROM users
 		ORDER BY created_at DESC
 	`
@@ -210,8 +281,6 @@ func (r *UserRepository) GetAllUsers() ([]models.User, error) {
 			&user.Email,
 			&user.PasswordHash,
 			&user.Name,
-			&user.OAuthProvider,
-			&user.OAuthSubject,
 			&user.IsAdmin,
 			&user.IsTeacher,
 			&user.CreatedAt,
@@ -249,58 +318,12 @@ func (r *UserRepository) DeleteUser(id int64) error {
 	return nil
 }
 
-// GetUserByOAuth retrieves a user by OAuth provider and subject
-func (r *UserRepository) GetUserByOAuth(provider, subject string) (*models.User, error) {
-	query := `
-		SELECT id, email, password_hash, name, COALESCE(oauth_provider, ''), COALESCE(oauth_subject, ''), is_admin, is_teacher, created_at, updated_at
-		FROM users
-		WHERE oauth_provider = ? AND oauth_subject = ?
-	`
-	user := &models.User{}
-	err := r.db.QueryRow(query, provider, subject).Scan(
-		&user.ID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.Name,
-		&user.OAuthProvider,
-		&user.OAuthSubject,
-		&user.IsAdmin,
-		&user.IsTeacher,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user by oauth: %w", err)
-	}
-
-	return user, nil
-}
-
-// LinkOAuthProvider links an existing user to an OAuth provider
-func (r *UserRepository) LinkOAuthProvider(userID int64, provider, subject string) error {
-	query := `
-		UPDATE users
-		SET oauth_provider = ?, oauth_subject = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = ?
-		AND (oauth_provider IS NULL OR oauth_provider = '')
-	`
-	result, err := r.db.Exec(query, provider, subject, userID)
-	if err != nil {
-		return fmt.Errorf("failed to link oauth provider: %w", err)
+// SetUserTeacher grants or removes the teacher role
+func (r *UserRepository) SetUserTeacher(userID int64, isTeacher bool) error {
+	query := `UPDATE users SET is_teacher = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	if _, err := r.db.Exec(query, isTeacher, userID); err != nil {
+		return fmt.Errorf("failed to update teacher role: %w", err)
 	}
-
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to read link result: %w", err)
-	}
-	if rows == 0 {
-		return fmt.Errorf("oauth provider already linked")
-	}
-
 	return nil
 }
 
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+)
+
+// WordScheduleRepository handles spaced-repetition schedule database operations
+type WordScheduleRepository struct {
+	db *database.DB
+}
+
+// NewWordScheduleRepository creates a new word schedule repository
+func NewWordScheduleRepository(db *database.DB) *WordScheduleRepository {
+	return &WordScheduleRepository{db: db}
+}
+
+// GetSchedulesForKid retrieves the schedules of the given words for a kid, keyed by word ID.
+// Words that have never been reviewed are absent from the map.
+func (r *WordScheduleRepository) GetSchedulesForKid(kidID int64, wordIDs []int64) (map[int64]*models.WordSchedule, error) {
+	schedules := make(map[int64]*models.WordSchedule)
+	if len(wordIDs) == 0 {
+		return schedules, nil
+	}
+
+	args := make([]interface{}, len(wordIDs)+1)
+	args[0] = kidID
+	for i, id := range wordIDs {
+		args[i+1] = id
+	}
+
+	query := `
+		SELECT kid_id, word_id, repetitions, interval_days, ease_factor, lapses, due_at, last_reviewed_at
+		FROM word_schedules
+		WHERE kid_id = ?
+		AND word_id IN (` + generatePlaceholders(len(wordIDs)) + `)
+	`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query word schedules: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		schedule, err := scanWordSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules[schedule.WordID] = schedule
+	}
+
+	return schedules, rows.Err()
+}
+
+// GetSchedule retrieves a single word schedule, returning nil if the word has never been reviewed
+func (r *WordScheduleRepository) GetSchedule(kidID, wordID int64) (*models.WordSchedule, error) {
+	query := `
+		SELECT kid_id, word_id, repetitions, interval_days, ease_factor, lapses, due_at, last_reviewed_at
+		FROM word_schedules
+		WHERE kid_id = ? AND word_id = ?
+	`
+
+	schedule, err := scanWordSchedule(r.db.QueryRow(query, kidID, wordID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// SaveSchedule stores a word schedule, replacing any existing one for the same kid
+// and word in a single statement, so answers saved at the same time can't race
+func (r *WordScheduleRepository) SaveSchedule(schedule *models.WordSchedule) error {
+	_, err := r.db.Exec(r.db.Dialect.UpsertWordSchedule(),
+		schedule.KidID,
+		schedule.WordID,
+		schedule.Repetitions,
+		schedule.IntervalDays,
+		schedule.EaseFactor,
+		schedule.Lapses,
+		schedule.DueAt,
+		schedule.LastReviewedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save word schedule: %w", err)
+	}
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanWordSchedule scans a word_schedules row into a model
+func scanWordSchedule(row rowScanner) (*models.WordSchedule, error) {
+	schedule := &models.WordSchedule{}
+	var lastReviewedAt sql.NullTime
+
+	err := row.Scan(
+		&schedule.KidID,
+		&schedule.WordID,
+		&schedule.Repetitions,
+		&schedule.IntervalDays,
+		&schedule.EaseFactor,
+		&schedule.Lapses,
+		&schedule.DueAt,
+		&lastReviewedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastReviewedAt.Valid {
+		schedule.LastReviewedAt = &lastReviewedAt.Time
+	}
+
+	return schedule, nil
+}
@@ -0,0 +1,92 @@
+package security
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// trustedProxies are the networks allowed to say who the client is with the
+// X-Forwarded-For and X-Real-IP headers. It is set once at startup.
+var trustedProxies []*net.IPNet
+
+// SetTrustedProxies sets the reverse proxies whose forwarding headers are
+// believed, as IP addresses or CIDR ranges. With none, the headers are ignored
+// and the client is whoever opened the connection.
+func SetTrustedProxies(proxies []string) error {
+	var networks []*net.IPNet
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return fmt.Errorf("invalid trusted proxy address: %s", proxy)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy range: %s", proxy)
+		}
+		networks = append(networks, network)
+	}
+	trustedProxies = networks
+	return nil
+}
+
+// isTrustedProxy reports whether ip belongs to one of the trusted proxies
+func isTrustedProxy(ip net.IP) bool {
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetClientIP returns the IP address of the client making the request, without
+// a port. Forwarding headers are only believed when the connection comes from a
+// trusted proxy, and X-Forwarded-For is read from the nearest hop back, so a
+// client can't pick its own address by sending the headers itself.
+func GetClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	client := net.ParseIP(host)
+	if client == nil || !isTrustedProxy(client) {
+		return host
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	if len(hops) == 0 {
+		if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); realIP != nil {
+			return realIP.String()
+		}
+	}
+	// Each proxy appends the address it was connected from, so the client is the
+	// last hop that isn't one of ours
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		client = hop
+		if !isTrustedProxy(hop) {
+			break
+		}
+	}
+	return client.String()
+}
@@ -0,0 +1,51 @@
+package security
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetClientIP(t *testing.T) {
+	t.Cleanup(func() { trustedProxies = nil })
+
+	tests := []struct {
+		name       string
+		proxies    []string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{name: "direct connection", remoteAddr: "203.0.113.7:51234", want: "203.0.113.7"},
+		{name: "IPv6 connection", remoteAddr: "[2001:db8::1]:443", want: "2001:db8::1"},
+		{name: "headers from an untrusted client", remoteAddr: "203.0.113.7:51234", forwarded: []string{"198.51.100.1"}, realIP: "198.51.100.2", want: "203.0.113.7"},
+		{name: "trusted proxy", proxies: []string{"10.0.0.0/8"}, remoteAddr: "10.0.0.2:80", forwarded: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "spoofed hop before the proxy's", proxies: []string{"10.0.0.2"}, remoteAddr: "10.0.0.2:80", forwarded: []string{"1.2.3.4, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "chain of trusted proxies", proxies: []string{"10.0.0.0/8"}, remoteAddr: "10.0.0.2:80", forwarded: []string{"1.2.3.4, 198.51.100.1", "10.0.0.9"}, want: "198.51.100.1"},
+		{name: "garbage hop", proxies: []string{"10.0.0.0/8"}, remoteAddr: "10.0.0.2:80", forwarded: []string{"nonsense"}, want: "10.0.0.2"},
+		{name: "real IP from a trusted proxy", proxies: []string{"10.0.0.2"}, remoteAddr: "10.0.0.2:80", realIP: "198.51.100.2", want: "198.51.100.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetTrustedProxies(tt.proxies); err != nil {
+				t.Fatalf("SetTrustedProxies() error: %v", err)
+			}
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := GetClientIP(r); got != tt.want {
+				t.Errorf("GetClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if err := SetTrustedProxies([]string{"not an address"}); err == nil {
+		t.Error("SetTrustedProxies() accepted an invalid address")
+	}
+}
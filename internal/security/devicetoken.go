@@ -0,0 +1,17 @@
+package security
+
+import "strings"
+
+// AppendDeviceToken adds a token from a successful sign-in to the tokens a device
+// already holds, newest first, keeping at most limit. Tokens are "<id>-<mac>", and
+// the new one replaces any older token for the same ID.
+func AppendDeviceToken(tokens []string, token string, limit int) []string {
+	id, _, _ := strings.Cut(token, "-")
+	kept := []string{token}
+	for _, t := range tokens {
+		if tokenID, _, _ := strings.Cut(t, "-"); tokenID != id && len(kept) < limit {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
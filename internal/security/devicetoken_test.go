@@ -0,0 +1,20 @@
+package security
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestAppendDeviceToken(t *testing.T) {
+	tokens := AppendDeviceToken([]string{"1-aaa", "2-bbb"}, "1-ccc", 10)
+	if len(tokens) != 2 || tokens[0] != "1-ccc" || tokens[1] != "2-bbb" {
+		t.Errorf("AppendDeviceToken() = %v, want the new token replacing the old one for the same ID", tokens)
+	}
+
+	for i := range 15 {
+		tokens = AppendDeviceToken(tokens, strconv.Itoa(i+10)+"-x", 10)
+	}
+	if len(tokens) != 10 || tokens[0] != "24-x" {
+		t.Errorf("AppendDeviceToken() = %v, want the 10 newest tokens", tokens)
+	}
+}
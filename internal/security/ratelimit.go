@@ -1,105 +1,189 @@
 package security
 
 import (
-	"net/http"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
 	"sync"
 	"time"
 )
 
-// RateLimiter implements a simple token bucket rate limiter
-type RateLimiter struct {
-	visitors map[string]*visitor
-	mu       sync.RWMutex
-	rate     int           // requests per window
-	window   time.Duration // time window
+// RateLimitPolicy is a limit on how often something may happen, e.g. logins from
+// one IP address
+type RateLimitPolicy struct {
+	Name   string        // Keeps each policy's counters apart, e.g. "login"
+	Limit  int           // Hits allowed per window
+	Window time.Duration // Length of each fixed window
 }
 
-type visitor struct {
-	tokens     int
-	lastRefill time.Time
-	mu         sync.Mutex
+// Limiter counts hits against keys under a policy
+type Limiter interface {
+	// Allow records a hit for key and reports whether it is within the policy's
+	// limit. When it isn't, the duration is how long until the key may try again.
+	Allow(policy RateLimitPolicy, key string) (bool, time.Duration, error)
+
+	// Blocked reports how long key must wait because it has already used up the
+	// policy's limit, or 0 if it hasn't, without recording a hit
+	Blocked(policy RateLimitPolicy, key string) (time.Duration, error)
+
+	// Reset forgets the hits recorded for key in the current window
+	Reset(policy RateLimitPolicy, key string) error
+}
+
+// RateLimitStore holds the counters behind a Limiter
+type RateLimitStore interface {
+	// IncrementRateLimit adds a hit to a bucket, creating it to expire at
+	// expiresAt if needed, and returns the bucket's hits
+	IncrementRateLimit(bucket string, expiresAt time.Time) (int, error)
+
+	// GetRateLimitHits returns the hits in a bucket, or 0 if it doesn't exist
+	GetRateLimitHits(bucket string) (int, error)
+
+	// DeleteRateLimit removes a bucket
+	DeleteRateLimit(bucket string) error
+}
+
+// StoreLimiter is a fixed window Limiter keeping its counters in a RateLimitStore.
+// With a database store the limits hold across restarts and replicas.
+type StoreLimiter struct {
+	store RateLimitStore
+	now   func() time.Time
+}
+
+// NewStoreLimiter creates a limiter backed by store
+func NewStoreLimiter(store RateLimitStore) *StoreLimiter {
+	return &StoreLimiter{store: store, now: time.Now}
 }
 
-// NewRateLimiter creates a new rate limiter
-// rate: number of requests allowed per window
-// window: time window for rate limiting
-func NewRateLimiter(rate int, window time.Duration) *RateLimiter {
-	rl := &RateLimiter{
-		visitors: make(map[string]*visitor),
-		rate:     rate,
-		window:   window,
+// SetClock makes the limiter read the time from now, e.g. so tests can hold it
+// inside one window
+func (l *StoreLimiter) SetClock(now func() time.Time) {
+	l.now = now
+}
+
+// NewMemoryLimiter creates a limiter that keeps its counters in this process, so
+// they reset on restart and aren't shared between replicas
+func NewMemoryLimiter() *StoreLimiter {
+	return NewStoreLimiter(NewMemoryRateLimitStore())
+}
+
+// Allow records a hit for key and reports whether it is within the policy's limit
+func (l *StoreLimiter) Allow(policy RateLimitPolicy, key string) (bool, time.Duration, error) {
+	bucket, resetAt := l.bucket(policy, key)
+	hits, err := l.store.IncrementRateLimit(bucket, resetAt)
+	if err != nil {
+		return false, 0, err
+	}
+	if hits > policy.Limit {
+		return false, resetAt.Sub(l.now()), nil
+	}
+	return true, 0, nil
+}
+
+// Blocked reports how long key must wait before the policy allows it again
+func (l *StoreLimiter) Blocked(policy RateLimitPolicy, key string) (time.Duration, error) {
+	bucket, resetAt := l.bucket(policy, key)
+	hits, err := l.store.GetRateLimitHits(bucket)
+	if err != nil {
+		return 0, err
+	}
+	if hits >= policy.Limit {
+		return resetAt.Sub(l.now()), nil
 	}
+	return 0, nil
+}
+
+// Reset forgets the hits recorded for key in the current window
+func (l *StoreLimiter) Reset(policy RateLimitPolicy, key string) error {
+	bucket, _ := l.bucket(policy, key)
+	return l.store.DeleteRateLimit(bucket)
+}
+
+// bucket names the counter for key in the current window of policy, and returns
+// when that window ends. Keys can be long and client supplied, so the name is a
+// hash.
+func (l *StoreLimiter) bucket(policy RateLimitPolicy, key string) (string, time.Time) {
+	start := l.now().Truncate(policy.Window)
+	sum := sha256.Sum256([]byte(policy.Name + "\x00" + key + "\x00" + strconv.FormatInt(start.Unix(), 10)))
+	return hex.EncodeToString(sum[:]), start.Add(policy.Window)
+}
+
+// MemoryRateLimitStore is a RateLimitStore kept in memory
+type MemoryRateLimitStore struct {
+	buckets map[string]*memoryBucket
+	mu      sync.Mutex
+}
+
+type memoryBucket struct {
+	hits      int
+	expiresAt time.Time
+}
+
+// NewMemoryRateLimitStore creates an in-memory store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	s := &MemoryRateLimitStore{buckets: make(map[string]*memoryBucket)}
 	// Start cleanup goroutine
-	go rl.cleanupVisitors()
-	return rl
+	go s.cleanupBuckets()
+	return s
 }
 
-// Allow checks if a request from an IP should be allowed
-func (rl *RateLimiter) Allow(ip string) bool {
-	rl.mu.Lock()
-	v, exists := rl.visitors[ip]
+// IncrementRateLimit adds a hit to a bucket and returns its hits
+func (s *MemoryRateLimitStore) IncrementRateLimit(bucket string, expiresAt time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exists := s.buckets[bucket]
 	if !exists {
-		v = &visitor{
-			tokens:     rl.rate,
-			lastRefill: time.Now(),
-		}
-		rl.visitors[ip] = v
+		b = &memoryBucket{expiresAt: expiresAt}
+		s.buckets[bucket] = b
 	}
-	rl.mu.Unlock()
+	b.hits++
+	return b.hits, nil
+}
 
-	v.mu.Lock()
-	defer v.mu.Unlock()
+// GetRateLimitHits returns the hits in a bucket
+func (s *MemoryRateLimitStore) GetRateLimitHits(bucket string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	// Refill tokens based on time passed
-	now := time.Now()
-	elapsed := now.Sub(v.lastRefill)
-	if elapsed >= rl.window {
-		v.tokens = rl.rate
-		v.lastRefill = now
+	if b, exists := s.buckets[bucket]; exists {
+		return b.hits, nil
 	}
+	return 0, nil
+}
 
-	// Check if request is allowed
-	if v.tokens > 0 {
-		v.tokens--
-		return true
-	}
+// DeleteRateLimit removes a bucket
+func (s *MemoryRateLimitStore) DeleteRateLimit(bucket string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	return false
+	delete(s.buckets, bucket)
+	return nil
 }
 
-// cleanupVisitors removes old visitor entries to prevent memory leaks
-func (rl *RateLimiter) cleanupVisitors() {
+// cleanupBuckets removes expired buckets to prevent memory leaks
+func (s *MemoryRateLimitStore) cleanupBuckets() {
 	ticker := time.NewTicker(1 * time.Hour)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		rl.mu.Lock()
+		s.mu.Lock()
 		now := time.Now()
-		for ip, v := range rl.visitors {
-			v.mu.Lock()
-			if now.Sub(v.lastRefill) > rl.window*2 {
-				delete(rl.visitors, ip)
+		for name, b := range s.buckets {
+			if now.After(b.expiresAt) {
+				delete(s.buckets, name)
 			}
-			v.mu.Unlock()
 		}
-		rl.mu.Unlock()
+		s.mu.Unlock()
 	}
 }
 
-// GetClientIP extracts the client IP from the request
-func GetClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header (when behind proxy)
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		return forwarded
-	}
-
-	// Check X-Real-IP header
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
+// RetryAfterSeconds formats a wait for a Retry-After header, rounding up so
+// clients don't retry too early
+func RetryAfterSeconds(wait time.Duration) string {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
 	}
-
-	// Fall back to RemoteAddr
-	return r.RemoteAddr
+	return strconv.Itoa(seconds)
 }
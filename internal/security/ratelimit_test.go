@@ -0,0 +1,69 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStoreLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 10, 0, time.UTC)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	policy := RateLimitPolicy{Name: "login", Limit: 3, Window: time.Minute}
+
+	for i := 0; i < policy.Limit; i++ {
+		if allowed, _, err := limiter.Allow(policy, "203.0.113.7"); err != nil || !allowed {
+			t.Fatalf("Allow() hit %d = %v, %v, want allowed", i+1, allowed, err)
+		}
+	}
+	if wait, err := limiter.Blocked(policy, "203.0.113.7"); err != nil || wait != 50*time.Second {
+		t.Errorf("Blocked() at the limit = %v, %v, want 50s", wait, err)
+	}
+	allowed, retryAfter, err := limiter.Allow(policy, "203.0.113.7")
+	if err != nil || allowed || retryAfter != 50*time.Second {
+		t.Errorf("Allow() over the limit = %v, %v, %v, want refused for 50s", allowed, retryAfter, err)
+	}
+
+	// Other keys and policies have their own counters
+	if allowed, _, _ := limiter.Allow(policy, "198.51.100.1"); !allowed {
+		t.Error("Allow() refused a different key")
+	}
+	if allowed, _, _ := limiter.Allow(RateLimitPolicy{Name: "register", Limit: 1, Window: time.Minute}, "203.0.113.7"); !allowed {
+		t.Error("Allow() refused a different policy")
+	}
+
+	// The count starts again in the next window
+	now = now.Add(50 * time.Second)
+	if wait, _ := limiter.Blocked(policy, "203.0.113.7"); wait != 0 {
+		t.Errorf("Blocked() in the next window = %v, want 0", wait)
+	}
+	if allowed, _, _ := limiter.Allow(policy, "203.0.113.7"); !allowed {
+		t.Error("Allow() refused in the next window")
+	}
+
+	if err := limiter.Reset(policy, "203.0.113.7"); err != nil {
+		t.Fatalf("Reset() error: %v", err)
+	}
+	bucket, _ := limiter.bucket(policy, "203.0.113.7")
+	if hits, _ := limiter.store.GetRateLimitHits(bucket); hits != 0 {
+		t.Errorf("hits after Reset() = %d, want 0", hits)
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	tests := []struct {
+		wait time.Duration
+		want string
+	}{
+		{0, "1"},
+		{300 * time.Millisecond, "1"},
+		{time.Second, "1"},
+		{1500 * time.Millisecond, "2"},
+		{15 * time.Minute, "900"},
+	}
+	for _, tt := range tests {
+		if got := RetryAfterSeconds(tt.wait); got != tt.want {
+			t.Errorf("RetryAfterSeconds(%v) = %q, want %q", tt.wait, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package security
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 test key from RFC 6238, "12345678901234567890", in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// The RFC's 8 digit codes, truncated to our 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode() at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := TOTPStep(now)
+	previous, _ := TOTPCode(rfc6238Secret, step-1)
+	tooOld, _ := TOTPCode(rfc6238Secret, step-2)
+
+	if got, ok := VerifyTOTP(rfc6238Secret, "005 924", now, 0); !ok || got != step {
+		t.Errorf("VerifyTOTP() = %d, %v, want the current step", got, ok)
+	}
+	if _, ok := VerifyTOTP(rfc6238Secret, previous, now, 0); !ok {
+		t.Error("VerifyTOTP() rejected the previous code, want it allowed for clock drift")
+	}
+	if _, ok := VerifyTOTP(rfc6238Secret, tooOld, now, 0); ok {
+		t.Error("VerifyTOTP() accepted a code from a minute ago")
+	}
+	if _, ok := VerifyTOTP(rfc6238Secret, "005924", now, step); ok {
+		t.Error("VerifyTOTP() accepted a code that was already used")
+	}
+	if _, ok := VerifyTOTP(rfc6238Secret, "12345", now, 0); ok {
+		t.Error("VerifyTOTP() accepted a short code")
+	}
+}
+
+func TestTOTPSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	now := time.Now()
+	code, err := TOTPCode(secret, TOTPStep(now))
+	if err != nil {
+		t.Fatalf("TOTPCode() error = %v", err)
+	}
+	if _, ok := VerifyTOTP(secret, code, now, 0); !ok {
+		t.Error("VerifyTOTP() rejected a code for a generated secret")
+	}
+
+	uri := TOTPKeyURI("WordClash", "parent@example.com", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/WordClash:parent@example.com?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("TOTPKeyURI() = %q", uri)
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	code, err := GenerateRecoveryCode()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCode() error = %v", err)
+	}
+	if len(code) != 14 || strings.Count(code, "-") != 2 {
+		t.Errorf("GenerateRecoveryCode() = %q, want xxxx-xxxx-xxxx", code)
+	}
+	if HashRecoveryCode(code) != HashRecoveryCode(" "+strings.ToUpper(strings.ReplaceAll(code, "-", ""))) {
+		t.Error("HashRecoveryCode() depends on case or dashes")
+	}
+}
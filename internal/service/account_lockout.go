@@ -0,0 +1,122 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"spellingclash/internal/models"
+	"spellingclash/internal/security"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Wrong passwords lock password sign-in to an account from one address after
+// accountAddressLockout, and from every address after the higher accountLockout,
+// which someone guessing from many addresses would reach. Devices that have
+// signed in to the account before skip both, so nobody can keep a parent or
+// teacher out of their own browser.
+var (
+	accountAddressLockout = security.RateLimitPolicy{Name: "account-lockout-ip", Limit: 10, Window: 15 * time.Minute}
+	accountLockout        = security.RateLimitPolicy{Name: "account-lockout", Limit: 50, Window: 15 * time.Minute}
+)
+
+// maxKnownAccounts caps how many accounts a browser remembers signing in to
+const maxKnownAccounts = 5
+
+// LoginClient is where a password sign-in comes from
+type LoginClient struct {
+	IP           string   // Client IP address
+	DeviceTokens []string // Tokens from earlier sign-ins on the device
+}
+
+// AccountLockedError is returned by Login while an account is locked after too
+// many wrong passwords
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("too many failed logins, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+// lockoutKey identifies an account for lockout, ignoring how the email was typed
+func lockoutKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// addressLockoutKey identifies an account and the address signing in to it
+func addressLockoutKey(email, ip string) string {
+	return lockoutKey(email) + "\x00" + ip
+}
+
+// KnownDeviceTokens adds a token from a successful sign-in to a device's tokens,
+// keeping the most recent ones
+func KnownDeviceTokens(tokens []string, token string) []string {
+	return security.AppendDeviceToken(tokens, token, maxKnownAccounts)
+}
+
+// DeviceToken returns a token for a device the user has signed in on, which lets
+// it skip the account lockout. It includes the password hash, so changing the
+// password makes every device count as new again.
+func (s *AuthService) DeviceToken(user *models.User) string {
+	mac := hmac.New(sha256.New, s.deviceSecret)
+	mac.Write([]byte(strconv.FormatInt(user.ID, 10) + "\x00" + user.PasswordHash))
+	return strconv.FormatInt(user.ID, 10) + "-" + hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// isKnownDevice reports whether the user has signed in on the client's device before
+func (s *AuthService) isKnownDevice(user *models.User, client LoginClient) bool {
+	want := s.DeviceToken(user)
+	for _, token := range client.DeviceTokens {
+		if hmac.Equal([]byte(token), []byte(want)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAccountLockout returns an AccountLockedError if the account is locked for
+// the client. The limiter failing doesn't stop anyone signing in.
+func (s *AuthService) checkAccountLockout(email string, client LoginClient) error {
+	var wait time.Duration
+	for policy, key := range map[security.RateLimitPolicy]string{
+		accountAddressLockout: addressLockoutKey(email, client.IP),
+		accountLockout:        lockoutKey(email),
+	} {
+		w, err := s.limiter.Blocked(policy, key)
+		if err != nil {
+			log.Printf("Error checking account lockout: %v", err)
+			continue
+		}
+		wait = max(wait, w)
+	}
+	if wait > 0 {
+		log.Printf("Login attempt for locked account %q from %s", lockoutKey(email), client.IP)
+		return &AccountLockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// recordFailedLogin counts a wrong password towards locking the account
+func (s *AuthService) recordFailedLogin(email string, client LoginClient) {
+	if _, _, err := s.limiter.Allow(accountAddressLockout, addressLockoutKey(email, client.IP)); err != nil {
+		log.Printf("Error recording failed login: %v", err)
+	}
+	if _, _, err := s.limiter.Allow(accountLockout, lockoutKey(email)); err != nil {
+		log.Printf("Error recording failed login: %v", err)
+	}
+}
+
+// clearFailedLogins forgets wrong passwords once the account's owner has proved
+// who they are from the client's address
+func (s *AuthService) clearFailedLogins(email, ip string) {
+	if err := s.limiter.Reset(accountAddressLockout, addressLockoutKey(email, ip)); err != nil {
+		log.Printf("Error clearing failed logins: %v", err)
+	}
+	if err := s.limiter.Reset(accountLockout, lockoutKey(email)); err != nil {
+		log.Printf("Error clearing failed logins: %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"log"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"time"
+)
+
+const (
+	// masteredRepetitions is how many spaced reviews in a row a word must be
+	// spelt right on to count as mastered
+	masteredRepetitions = 3
+
+	weekStreakDays      = 7
+	wordsMasteredTarget = 100
+)
+
+// achievementRule is an achievement and how to tell whether a kid has earned it.
+// Rules look at the kid's whole history, so achievements added later are awarded
+// for what kids have already done.
+type achievementRule struct {
+	models.Achievement
+	earned func(s *AchievementService, kid *models.Kid, now time.Time) (bool, error)
+}
+
+// achievementRules are every achievement, in trophy cabinet order
+var achievementRules = []achievementRule{
+	{
+		Achievement: models.Achievement{Key: "perfect_session", Name: "Perfectionist", Description: "Finish a session without a single mistake", Icon: "💯"},
+		earned: func(s *AchievementService, kid *models.Kid, now time.Time) (bool, error) {
+			count, err := s.achievementRepo.CountPerfectSessions(kid.ID)
+			return count > 0, err
+		},
+	},
+	{
+		Achievement: models.Achievement{Key: "week_streak", Name: "On Fire", Description: "Play every day for a week", Icon: "🔥"},
+		earned: func(s *AchievementService, kid *models.Kid, now time.Time) (bool, error) {
+			streaks, err := s.streakService.GetKidStreaks(kid, now)
+			if err != nil {
+				return false, err
+			}
+			return streaks.LongestDaily >= weekStreakDays, nil
+		},
+	},
+	{
+		Achievement: models.Achievement{Key: "words_mastered_100", Name: "Word Wizard", Description: "Master 100 words by spelling each right three reviews in a row", Icon: "🧙"},
+		earned: func(s *AchievementService, kid *models.Kid, now time.Time) (bool, error) {
+			count, err := s.achievementRepo.CountMasteredWords(kid.ID, masteredRepetitions)
+			return count >= wordsMasteredTarget, err
+		},
+	},
+	{
+		Achievement: models.Achievement{Key: "flawless_hangman", Name: "Mind Reader", Description: "Win a hangman game without a wrong guess", Icon: "🎯"},
+		earned: func(s *AchievementService, kid *models.Kid, now time.Time) (bool, error) {
+			count, err := s.achievementRepo.CountFlawlessHangmanGames(kid.ID)
+			return count > 0, err
+		},
+	},
+	{
+		Achievement: models.Achievement{Key: "early_finish", Name: "Ahead of Schedule", Description: "Finish an assigned list by its due date", Icon: "⏰"},
+		earned: func(s *AchievementService, kid *models.Kid, now time.Time) (bool, error) {
+			return s.finishedAssignmentOnTime(kid)
+		},
+	},
+}
+
+// AchievementService unlocks achievements as kids play and shows them off
+type AchievementService struct {
+	achievementRepo *repository.AchievementRepository
+	kidRepo         *repository.KidRepository
+	streakService   *StreakService
+}
+
+// NewAchievementService creates a new achievement service
+func NewAchievementService(achievementRepo *repository.AchievementRepository, kidRepo *repository.KidRepository, streakService *StreakService) *AchievementService {
+	return &AchievementService{
+		achievementRepo: achievementRepo,
+		kidRepo:         kidRepo,
+		streakService:   streakService,
+	}
+}
+
+// CheckAchievements unlocks any achievements a kid has earned and doesn't have
+// yet, returning the ones newly unlocked
+func (s *AchievementService) CheckAchievements(kidID int64) ([]models.Achievement, error) {
+	kid, err := s.kidRepo.GetKidByID(kidID)
+	if err != nil {
+		return nil, err
+	}
+	if kid == nil {
+		return nil, nil
+	}
+
+	unlocked, err := s.achievementRepo.GetKidAchievements(kidID)
+	if err != nil {
+		return nil, err
+	}
+	have := make(map[string]bool, len(unlocked))
+	for _, achievement := range unlocked {
+		have[achievement.AchievementKey] = true
+	}
+
+	now := time.Now()
+	var newlyUnlocked []models.Achievement
+	for _, rule := range achievementRules {
+		if have[rule.Key] {
+			continue
+		}
+		earned, err := rule.earned(s, kid, now)
+		if err != nil {
+			return newlyUnlocked, err
+		}
+		if !earned {
+			continue
+		}
+		isNew, err := s.achievementRepo.Unlock(kidID, rule.Key, now)
+		if err != nil {
+			return newlyUnlocked, err
+		}
+		if isNew {
+			newlyUnlocked = append(newlyUnlocked, rule.Achievement)
+		}
+	}
+	return newlyUnlocked, nil
+}
+
+// afterActivity checks a kid's achievements once they finish a game or session.
+// Failures are logged rather than failing what the kid was doing, and a nil
+// service does nothing.
+func (s *AchievementService) afterActivity(kidID int64) {
+	if s == nil {
+		return
+	}
+	if _, err := s.CheckAchievements(kidID); err != nil {
+		log.Printf("Error checking achievements for kid %d: %v", kidID, err)
+	}
+}
+
+// GetTrophies returns every achievement with whether the kid has unlocked it
+func (s *AchievementService) GetTrophies(kidID int64) ([]models.Trophy, error) {
+	unlocked, err := s.achievementRepo.GetKidAchievements(kidID)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]models.KidAchievement, len(unlocked))
+	for _, achievement := range unlocked {
+		byKey[achievement.AchievementKey] = achievement
+	}
+
+	trophies := make([]models.Trophy, 0, len(achievementRules))
+	for _, rule := range achievementRules {
+		trophy := models.Trophy{Achievement: rule.Achievement}
+		if achievement, ok := byKey[rule.Key]; ok {
+			unlockedAt := achievement.UnlockedAt
+			trophy.UnlockedAt = &unlockedAt
+			trophy.IsNew = achievement.SeenAt == nil
+		}
+		trophies = append(trophies, trophy)
+	}
+	return trophies, nil
+}
+
+// TakeNewAchievements returns the achievements a kid hasn't been shown yet and
+// marks them as seen
+func (s *AchievementService) TakeNewAchievements(kidID int64) ([]models.Achievement, error) {
+	trophies, err := s.GetTrophies(kidID)
+	if err != nil {
+		return nil, err
+	}
+	var unseen []models.Achievement
+	for _, trophy := range trophies {
+		if trophy.IsNew {
+			unseen = append(unseen, trophy.Achievement)
+		}
+	}
+	if len(unseen) == 0 {
+		return nil, nil
+	}
+	if err := s.MarkSeen(kidID); err != nil {
+		return nil, err
+	}
+	return unseen, nil
+}
+
+// MarkSeen records that a kid has been shown all their achievements
+func (s *AchievementService) MarkSeen(kidID int64) error {
+	return s.achievementRepo.MarkSeen(kidID, time.Now())
+}
+
+// finishedAssignmentOnTime reports whether a kid has finished a session on an
+// assigned list between it being assigned and the end of its due date, in the
+// family's time zone
+func (s *AchievementService) finishedAssignmentOnTime(kid *models.Kid) (bool, error) {
+	completions, err := s.achievementRepo.GetAssignmentCompletions(kid.ID)
+	if err != nil || len(completions) == 0 {
+		return false, err
+	}
+	calendar, err := s.streakService.GetCalendar(kid.FamilyCode)
+	if err != nil {
+		return false, err
+	}
+
+	for _, completion := range completions {
+		// Due dates are stored as midnight UTC on the day they are due
+		dueDay := completion.DueDate.UTC().Format(time.DateOnly)
+		completedDay := completion.CompletedAt.In(calendar.Location).Format(time.DateOnly)
+		if !completion.CompletedAt.Before(completion.AssignedAt) && completedDay <= dueDay {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"fmt"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"testing"
+	"time"
+)
+
+// newAchievementTestService seeds a family with one kid and one list of one word
+func newAchievementTestService(t *testing.T) (*database.DB, *AchievementService) {
+	t.Helper()
+	db := newTestDB(t)
+	seed := []string{
+		"INSERT INTO users (id, email, password_hash, name) VALUES (1, 'parent@example.com', 'x', 'Parent')",
+		"INSERT INTO families (family_code) VALUES ('FAM1')",
+		"INSERT INTO kids (id, family_code, name, username, password) VALUES (1, 'FAM1', 'Ada', 'ada1', 'x')",
+		"INSERT INTO spelling_lists (id, name, description, family_code, is_public, locale, created_by) VALUES (1, 'Week 1', '', 'FAM1', 0, 'en-GB', 1)",
+		"INSERT INTO words (id, spelling_list_id, word_text, position) VALUES (1, 1, 'cat', 0)",
+	}
+	for _, query := range seed {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("failed to seed %q: %v", query, err)
+		}
+	}
+
+	streaks := NewStreakService(repository.NewStreakRepository(db), time.UTC)
+	return db, NewAchievementService(repository.NewAchievementRepository(db), repository.NewKidRepository(db), streaks)
+}
+
+func achievementKeys(achievements []models.Achievement) []string {
+	keys := make([]string, len(achievements))
+	for i, achievement := range achievements {
+		keys[i] = achievement.Key
+	}
+	return keys
+}
+
+func TestCheckAchievements(t *testing.T) {
+	db, achievements := newAchievementTestService(t)
+	day := func(d, hour int) time.Time {
+		return time.Date(2026, 10, d, hour, 0, 0, 0, time.UTC)
+	}
+
+	seed := []struct {
+		query string
+		args  []interface{}
+	}{
+		// Due on the 10th, assigned on the 1st
+		{"INSERT INTO list_assignments (spelling_list_id, kid_id, assigned_by, assigned_at, due_date) VALUES (1, 1, 1, ?, ?)",
+			[]interface{}{day(1, 9), time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)}},
+		// A practice session left after the first of two words, with it right
+		{"INSERT INTO practice_sessions (id, kid_id, spelling_list_id, started_at, completed_at, total_words, correct_words) VALUES (1, 1, 1, ?, ?, 2, 1)",
+			[]interface{}{day(2, 10), day(2, 11)}},
+		{"INSERT INTO word_attempts (practice_session_id, word_id, attempt_text, is_correct, time_taken_ms, points_earned, attempted_at) VALUES (1, 1, 'cat', 1, 1000, 10, ?)",
+			[]interface{}{day(2, 10)}},
+		// A hangman game won with a wrong guess, in a session finished after the due date
+		{"INSERT INTO hangman_sessions (id, kid_id, spelling_list_id, started_at, completed_at, total_games, games_won) VALUES (1, 1, 1, ?, ?, 1, 1)",
+			[]interface{}{day(11, 10), day(11, 11)}},
+		{"INSERT INTO hangman_games (session_id, kid_id, word_id, word, wrong_guesses, is_won, started_at, completed_at) VALUES (1, 1, 1, 'cat', 1, 1, ?, ?)",
+			[]interface{}{day(11, 10), day(11, 11)}},
+	}
+	for _, s := range seed {
+		if _, err := db.Exec(s.query, s.args...); err != nil {
+			t.Fatalf("failed to seed %q: %v", s.query, err)
+		}
+	}
+
+	// The hangman session was perfect, but too late for the assignment
+	unlocked, err := achievements.CheckAchievements(1)
+	if err != nil {
+		t.Fatalf("CheckAchievements() error: %v", err)
+	}
+	if keys := achievementKeys(unlocked); len(keys) != 1 || keys[0] != "perfect_session" {
+		t.Errorf("CheckAchievements() = %v, want [perfect_session]", keys)
+	}
+
+	// A flawless game, finished in a session on the due date itself
+	more := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"INSERT INTO missing_letter_sessions (id, kid_id, spelling_list_id, started_at, completed_at, total_games) VALUES (1, 1, 1, ?, ?, 1)",
+			[]interface{}{day(10, 20), day(10, 21)}},
+		{"INSERT INTO missing_letter_games (session_id, kid_id, word_id, word, missing_indices, is_won, started_at, completed_at) VALUES (1, 1, 1, 'cat', '[1]', 0, ?, ?)",
+			[]interface{}{day(10, 20), day(10, 21)}},
+		{"INSERT INTO hangman_games (session_id, kid_id, word_id, word, wrong_guesses, is_won, started_at, completed_at) VALUES (1, 1, 1, 'cat', 0, 1, ?, ?)",
+			[]interface{}{day(11, 12), day(11, 12)}},
+	}
+	for _, s := range more {
+		if _, err := db.Exec(s.query, s.args...); err != nil {
+			t.Fatalf("failed to seed %q: %v", s.query, err)
+		}
+	}
+	if unlocked, err = achievements.CheckAchievements(1); err != nil {
+		t.Fatalf("CheckAchievements() error: %v", err)
+	}
+	if keys := achievementKeys(unlocked); len(keys) != 2 || keys[0] != "flawless_hangman" || keys[1] != "early_finish" {
+		t.Errorf("CheckAchievements() = %v, want [flawless_hangman early_finish]", keys)
+	}
+
+	// Nothing is unlocked twice
+	if unlocked, err = achievements.CheckAchievements(1); err != nil || len(unlocked) != 0 {
+		t.Errorf("CheckAchievements() again = %v, %v, want nothing new", achievementKeys(unlocked), err)
+	}
+
+	// Unlocks are announced once
+	news, err := achievements.TakeNewAchievements(1)
+	if err != nil || len(news) != 3 {
+		t.Fatalf("TakeNewAchievements() = %v, %v, want 3", achievementKeys(news), err)
+	}
+	if news, err = achievements.TakeNewAchievements(1); err != nil || len(news) != 0 {
+		t.Errorf("TakeNewAchievements() again = %v, %v, want none", achievementKeys(news), err)
+	}
+
+	trophies, err := achievements.GetTrophies(1)
+	if err != nil {
+		t.Fatalf("GetTrophies() error: %v", err)
+	}
+	if len(trophies) != len(achievementRules) {
+		t.Fatalf("GetTrophies() returned %d trophies, want %d", len(trophies), len(achievementRules))
+	}
+	for _, trophy := range trophies {
+		wantUnlocked := trophy.Key == "perfect_session" || trophy.Key == "flawless_hangman" || trophy.Key == "early_finish"
+		if (trophy.UnlockedAt != nil) != wantUnlocked || trophy.IsNew {
+			t.Errorf("trophy %s unlocked = %v, new = %v, want unlocked = %v and seen", trophy.Key, trophy.UnlockedAt != nil, trophy.IsNew, wantUnlocked)
+		}
+	}
+}
+
+func TestStreakAndMasteryAchievements(t *testing.T) {
+	db, achievements := newAchievementTestService(t)
+
+	// Seven days in a row ending yesterday
+	today := time.Now().UTC()
+	for i := 1; i <= 7; i++ {
+		completedAt := today.AddDate(0, 0, -i)
+		if _, err := db.Exec(
+			"INSERT INTO practice_sessions (kid_id, spelling_list_id, started_at, completed_at, total_words, correct_words) VALUES (1, 1, ?, ?, 1, 0)",
+			completedAt, completedAt,
+		); err != nil {
+			t.Fatalf("failed to seed practice session: %v", err)
+		}
+	}
+
+	// 100 words reviewed right three times, and one that isn't there yet
+	for i := 0; i <= wordsMasteredTarget; i++ {
+		wordID := int64(100 + i)
+		repetitions := masteredRepetitions
+		if i == wordsMasteredTarget {
+			repetitions--
+		}
+		if _, err := db.Exec("INSERT INTO words (id, spelling_list_id, word_text, position) VALUES (?, 1, ?, ?)", wordID, fmt.Sprintf("word%d", i), i+1); err != nil {
+			t.Fatalf("failed to seed word: %v", err)
+		}
+		if _, err := db.Exec(
+			"INSERT INTO word_schedules (kid_id, word_id, repetitions, interval_days, ease_factor, lapses, due_at) VALUES (1, ?, ?, 15, 2.5, 0, ?)",
+			wordID, repetitions, today,
+		); err != nil {
+			t.Fatalf("failed to seed word schedule: %v", err)
+		}
+	}
+
+	unlocked, err := achievements.CheckAchievements(1)
+	if err != nil {
+		t.Fatalf("CheckAchievements() error: %v", err)
+	}
+	if keys := achievementKeys(unlocked); len(keys) != 2 || keys[0] != "week_streak" || keys[1] != "words_mastered_100" {
+		t.Errorf("CheckAchievements() = %v, want [week_streak words_mastered_100]", keys)
+	}
+}
+
+func TestCompleteSessionChecksAchievements(t *testing.T) {
+	db, achievements := newAchievementTestService(t)
+	if _, err := db.Exec("INSERT INTO practice_sessions (id, kid_id, spelling_list_id, started_at, total_words) VALUES (1, 1, 1, ?, 1)", time.Now()); err != nil {
+		t.Fatalf("failed to seed practice session: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO word_attempts (practice_session_id, word_id, attempt_text, is_correct, time_taken_ms, points_earned, attempted_at) VALUES (1, 1, 'cat', 1, 1000, 10, ?)", time.Now()); err != nil {
+		t.Fatalf("failed to seed word attempt: %v", err)
+	}
+
+	practice := NewPracticeService(repository.NewPracticeRepository(db), repository.NewListRepository(db), repository.NewWordScheduleRepository(db), achievements)
+	if _, err := practice.CompleteSession(1); err != nil {
+		t.Fatalf("CompleteSession() error: %v", err)
+	}
+
+	news, err := achievements.TakeNewAchievements(1)
+	if keys := achievementKeys(news); err != nil || len(keys) != 1 || keys[0] != "perfect_session" {
+		t.Errorf("TakeNewAchievements() after a perfect session = %v, %v, want [perfect_session]", keys, err)
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidAPIToken     = errors.New("invalid API token")
+	ErrAPITokenNotFound    = errors.New("API token not found")
+	ErrAPITokenNameInvalid = errors.New("token name is required and must be at most 100 characters")
+	ErrTooManyAPITokens    = errors.New("too many API tokens, revoke an unused token first")
+)
+
+const (
+	// apiTokenPrefix starts every API token so leaked tokens are easy to recognise
+	apiTokenPrefix = "sc_"
+	// apiTokenPrefixLength is how much of a token is kept to identify it in the UI
+	apiTokenPrefixLength = len(apiTokenPrefix) + 8
+	maxAPITokensPerUser  = 20
+	maxAPITokenNameLen   = 100
+	// apiTokenTouchInterval limits how often last_used_at is written for a busy token
+	apiTokenTouchInterval = time.Minute
+)
+
+// APITokenService manages per-user API tokens
+type APITokenService struct {
+	tokenRepo *repository.APITokenRepository
+	userRepo  *repository.UserRepository
+}
+
+// NewAPITokenService creates a new API token service
+func NewAPITokenService(tokenRepo *repository.APITokenRepository, userRepo *repository.UserRepository) *APITokenService {
+	return &APITokenService{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// CreateToken creates a named API token for a user. The returned token value
+// is not stored and cannot be retrieved again.
+func (s *APITokenService) CreateToken(userID int64, name string) (string, *models.APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxAPITokenNameLen {
+		return "", nil, ErrAPITokenNameInvalid
+	}
+
+	count, err := s.tokenRepo.CountUserTokens(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if count >= maxAPITokensPerUser {
+		return "", nil, ErrTooManyAPITokens
+	}
+
+	secret, err := generateSecureToken(32)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate API token: %w", err)
+	}
+	value := apiTokenPrefix + secret
+
+	token, err := s.tokenRepo.CreateToken(userID, name, hashAPIToken(value), value[:apiTokenPrefixLength])
+	if err != nil {
+		return "", nil, err
+	}
+	return value, token, nil
+}
+
+// GetUserTokens lists a user's API tokens
+func (s *APITokenService) GetUserTokens(userID int64) ([]models.APIToken, error) {
+	return s.tokenRepo.GetUserTokens(userID)
+}
+
+// RevokeToken deletes one of a user's API tokens
+func (s *APITokenService) RevokeToken(userID, tokenID int64) error {
+	deleted, err := s.tokenRepo.DeleteUserToken(tokenID, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAPITokenNotFound
+	}
+	return nil
+}
+
+// Authenticate returns the user and token for an API token value
+func (s *APITokenService) Authenticate(value string) (*models.User, *models.APIToken, error) {
+	if !strings.HasPrefix(value, apiTokenPrefix) {
+		return nil, nil, ErrInvalidAPIToken
+	}
+
+	token, err := s.tokenRepo.GetTokenByHash(hashAPIToken(value))
+	if err != nil {
+		return nil, nil, err
+	}
+	if token == nil {
+		return nil, nil, ErrInvalidAPIToken
+	}
+
+	user, err := s.userRepo.GetUserByID(token.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, nil, ErrInvalidAPIToken
+	}
+
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > apiTokenTouchInterval {
+		if err := s.tokenRepo.UpdateLastUsed(token.ID, now); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
+	return user, token, nil
+}
+
+// hashAPIToken returns the hex SHA-256 hash stored for a token value. API tokens
+// are long random strings, so a fast hash is enough to protect them at rest.
+func hashAPIToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
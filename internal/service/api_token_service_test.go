@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"spellingclash/internal/repository"
+	"strings"
+	"testing"
+)
+
+func TestAPITokenLifecycle(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := db.Exec("INSERT INTO users (id, email, password_hash, name) VALUES (1, 'parent@example.com', 'x', 'Parent'), (2, 'other@example.com', 'x', 'Other')"); err != nil {
+		t.Fatalf("failed to seed users: %v", err)
+	}
+	service := NewAPITokenService(repository.NewAPITokenRepository(db), repository.NewUserRepository(db))
+
+	if _, _, err := service.CreateToken(1, "   "); !errors.Is(err, ErrAPITokenNameInvalid) {
+		t.Errorf("CreateToken() with blank name error = %v, want ErrAPITokenNameInvalid", err)
+	}
+
+	value, token, err := service.CreateToken(1, " School MIS ")
+	if err != nil {
+		t.Fatalf("CreateToken() error: %v", err)
+	}
+	if !strings.HasPrefix(value, token.TokenPrefix) || token.Name != "School MIS" {
+		t.Errorf("unexpected token %+v for value %q", token, value)
+	}
+	if token.TokenHash == value || strings.Contains(token.TokenHash, value) {
+		t.Error("token value must not be stored")
+	}
+
+	user, authed, err := service.Authenticate(value)
+	if err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+	if user.ID != 1 || authed.ID != token.ID {
+		t.Errorf("Authenticate() = user %d token %d, want user 1 token %d", user.ID, authed.ID, token.ID)
+	}
+
+	tokens, err := service.GetUserTokens(1)
+	if err != nil {
+		t.Fatalf("GetUserTokens() error: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].LastUsedAt == nil {
+		t.Errorf("GetUserTokens() = %+v, want one used token", tokens)
+	}
+
+	if err := service.RevokeToken(2, token.ID); !errors.Is(err, ErrAPITokenNotFound) {
+		t.Errorf("RevokeToken() by another user error = %v, want ErrAPITokenNotFound", err)
+	}
+	if err := service.RevokeToken(1, token.ID); err != nil {
+		t.Fatalf("RevokeToken() error: %v", err)
+	}
+	if _, _, err := service.Authenticate(value); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Errorf("Authenticate() after revoke error = %v, want ErrInvalidAPIToken", err)
+	}
+	if _, _, err := service.Authenticate("not-a-token"); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Errorf("Authenticate() with malformed token error = %v, want ErrInvalidAPIToken", err)
+	}
+}
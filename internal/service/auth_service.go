@@ -19,21 +19,52 @@ var (
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrSessionNotFound    = errors.New("session not found")
 	ErrSessionExpired     = errors.New("session expired")
+
+	ErrAccountExists         = errors.New("an account with this email already exists")
+	ErrIdentityInUse         = errors.New("that account is already linked to another user")
+	ErrProviderAlreadyLinked = errors.New("an account from this provider is already linked")
+	ErrIdentityNotFound      = errors.New("sign-in method not linked")
+	ErrLastSignInMethod      = errors.New("can't remove your only way to sign in")
+	ErrWrongPassword         = errors.New("current password is incorrect")
 )
 
 // AuthService handles authentication business logic
 type AuthService struct {
 	userRepo        *repository.UserRepository
 	familyRepo      *repository.FamilyRepository
+	identityRepo    *repository.IdentityRepository
+	twoFactorRepo   *repository.TwoFactorRepository
+	apiTokenRepo    *repository.APITokenRepository
+	settingsRepo    *repository.SettingsRepository
+	limiter         security.Limiter
 	sessionDuration time.Duration
+	now             func() time.Time // Clock authenticator codes are checked against
+	deviceSecret    []byte
 }
 
 // NewAuthService creates a new auth service
-func NewAuthService(userRepo *repository.UserRepository, familyRepo *repository.FamilyRepository, sessionDuration time.Duration) *AuthService {
+func NewAuthService(
+	userRepo *repository.UserRepository,
+	familyRepo *repository.FamilyRepository,
+	identityRepo *repository.IdentityRepository,
+	twoFactorRepo *repository.TwoFactorRepository,
+	apiTokenRepo *repository.APITokenRepository,
+	settingsRepo *repository.SettingsRepository,
+	limiter security.Limiter,
+	sessionDuration time.Duration,
+	secret string,
+) *AuthService {
 	return &AuthService{
 		userRepo:        userRepo,
 		familyRepo:      familyRepo,
+		identityRepo:    identityRepo,
+		twoFactorRepo:   twoFactorRepo,
+		apiTokenRepo:    apiTokenRepo,
+		settingsRepo:    settingsRepo,
+		limiter:         limiter,
 		sessionDuration: sessionDuration,
+		now:             time.Now,
+		deviceSecret:    []byte("login-device:" + secret),
 	}
 }
 
@@ -104,32 +135,34 @@ func (s *AuthService) RegisterWithRole(email, password, name, familyCode string,
 	return user, nil
 }
 
-// Login authenticates a user and creates a session
-func (s *AuthService) Login(email, password string) (*models.Session, *models.User, error) {
+// Login authenticates a user and creates a session. Users with two-factor
+// authentication on get a *TwoFactorRequiredError instead of a session, and
+// accounts with too many recent wrong passwords an *AccountLockedError, unless
+// the client's device has signed in to the account before.
+func (s *AuthService) Login(email, password string, client LoginClient) (*models.Session, *models.User, error) {
 	// Get user by email
 	user, err := s.userRepo.GetUserByEmail(email)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get user: %w", err)
 	}
+	if user == nil || !s.isKnownDevice(user, client) {
+		if err := s.checkAccountLockout(email, client); err != nil {
+			return nil, nil, err
+		}
+	}
 	if user == nil {
+		s.recordFailedLogin(email, client)
 		return nil, nil, ErrInvalidCredentials
 	}
 
 	// Check password
 	if !security.CheckPassword(password, user.PasswordHash) {
+		s.recordFailedLogin(email, client)
 		return nil, nil, ErrInvalidCredentials
 	}
+	s.clearFailedLogins(email, client.IP)
 
-	// Create session
-	sessionID := security.GenerateSessionID()
-	expiresAt := time.Now().Add(s.sessionDuration)
-
-	session, err := s.userRepo.CreateSession(sessionID, user.ID, expiresAt)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create session: %w", err)
-	}
-
-	return session, user, nil
+	return s.startSession(user)
 }
 
 // ValidateSession checks if a session is valid and returns the associated user
@@ -178,81 +211,237 @@ func (s *AuthService) CleanupExpiredSessions() error {
 	return nil
 }
 
-// OAuthLogin authenticates or creates a user using an OAuth provider
-func (s *AuthService) OAuthLogin(provider, subject, email, name, familyCode string) (*models.Session, *models.User, error) {
-	if provider == "" || subject == "" {
+// OAuthIdentity is what a sign-in provider told us about the person signing in
+type OAuthIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool // The provider vouches that the user controls Email
+	Name          string
+	IsTeacher     bool // The provider says the user is a teacher
+}
+
+// OAuthLogin authenticates or creates a user using an OAuth provider. A provider
+// account that isn't linked yet is linked to the user with the same email, but only
+// if the provider has verified the address; otherwise the user has to sign in some
+// other way and link it from their account settings. When the provider says the user
+// is a teacher, new accounts are created as teachers and existing accounts are given
+// the teacher role; the role is never taken away. As with Login, users with two-factor
+// authentication on get a *TwoFactorRequiredError instead of a session.
+func (s *AuthService) OAuthLogin(identity OAuthIdentity, familyCode string) (*models.Session, *models.User, error) {
+	if identity.Provider == "" || identity.Subject == "" {
 		return nil, nil, errors.New("missing oauth provider information")
 	}
-	if err := validation.ValidateEmail(email); err != nil {
+	if err := validation.ValidateEmail(identity.Email); err != nil {
 		return nil, nil, err
 	}
 
-	user, err := s.userRepo.GetUserByOAuth(provider, subject)
+	linked, err := s.identityRepo.GetIdentity(identity.Provider, identity.Subject)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to lookup oauth user: %w", err)
 	}
 
-	if user == nil {
-		existingUser, err := s.userRepo.GetUserByEmail(email)
+	var user *models.User
+	if linked != nil {
+		user, err = s.userRepo.GetUserByID(linked.UserID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			return nil, nil, ErrInvalidCredentials
+		}
+		if err := s.identityRepo.TouchIdentity(linked.ID, identity.Email, time.Now()); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		existingUser, err := s.userRepo.GetUserByEmail(identity.Email)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to check existing user: %w", err)
 		}
 		if existingUser != nil {
-			if existingUser.OAuthProvider != "" && existingUser.OAuthProvider != provider {
-				return nil, nil, ErrEmailTaken
+			if !identity.EmailVerified {
+				return nil, nil, ErrAccountExists
 			}
-			if err := s.userRepo.LinkOAuthProvider(existingUser.ID, provider, subject); err != nil {
-				return nil, nil, fmt.Errorf("failed to link oauth provider: %w", err)
+			if err := s.linkIdentity(existingUser.ID, identity); err != nil {
+				if errors.Is(err, ErrProviderAlreadyLinked) {
+					return nil, nil, ErrAccountExists
+				}
+				return nil, nil, err
 			}
 			user = existingUser
 		} else {
-			if name == "" {
-				name = strings.Split(email, "@")[0]
-			}
-			randomPasswordHash, err := security.HashPassword(security.GenerateSessionID())
-			if err != nil {
-				return nil, nil, fmt.Errorf("failed to generate oauth password hash: %w", err)
-			}
-			newUser, err := s.userRepo.CreateUser(email, randomPasswordHash, name)
+			user, err = s.createOAuthUser(identity, familyCode)
 			if err != nil {
-				return nil, nil, fmt.Errorf("failed to create oauth user: %w", err)
+				return nil, nil, err
 			}
-			if err := s.userRepo.LinkOAuthProvider(newUser.ID, provider, subject); err != nil {
-				return nil, nil, fmt.Errorf("failed to link oauth provider: %w", err)
-			}
-			user = newUser
+		}
+	}
 
-			if familyCode != "" {
-				family, err := s.familyRepo.GetFamilyByCode(familyCode)
-				if err != nil {
-					return nil, nil, fmt.Errorf("failed to check family code: %w", err)
-				}
-				if family == nil {
-					return nil, nil, errors.New("invalid family code")
-				}
-				if err := s.familyRepo.AddFamilyMember(familyCode, user.ID, "parent"); err != nil {
-					return nil, nil, fmt.Errorf("failed to join family: %w", err)
-				}
-			} else {
-				if _, err := s.familyRepo.CreateFamily(user.ID); err != nil {
-					fmt.Printf("Warning: failed to create family for user %d: %v\n", user.ID, err)
-				}
-			}
+	if identity.IsTeacher && !user.IsTeacher {
+		if err := s.userRepo.SetUserTeacher(user.ID, true); err != nil {
+			return nil, nil, err
+		}
+		user.IsTeacher = true
+	}
+
+	return s.startSession(user)
+}
+
+// createOAuthUser creates a user without a password for a new provider account
+func (s *AuthService) createOAuthUser(identity OAuthIdentity, familyCode string) (*models.User, error) {
+	name := identity.Name
+	if name == "" {
+		name = strings.Split(identity.Email, "@")[0]
+	}
+
+	// Check the family code before creating anything
+	if familyCode != "" && !identity.IsTeacher {
+		family, err := s.familyRepo.GetFamilyByCode(familyCode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check family code: %w", err)
+		}
+		if family == nil {
+			return nil, errors.New("invalid family code")
 		}
 	}
 
-	sessionID := security.GenerateSessionID()
-	expiresAt := time.Now().Add(s.sessionDuration)
-	session, err := s.userRepo.CreateSession(sessionID, user.ID, expiresAt)
+	user, err := s.userRepo.CreateUserWithRole(identity.Email, "", name, identity.IsTeacher)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create session: %w", err)
+		return nil, fmt.Errorf("failed to create oauth user: %w", err)
+	}
+	if _, err := s.identityRepo.LinkIdentity(user.ID, identity.Provider, identity.Subject, identity.Email); err != nil {
+		return nil, err
 	}
 
-	return session, user, nil
+	switch {
+	case identity.IsTeacher:
+		// Teachers don't get a family of their own, as with RegisterWithRole
+	case familyCode != "":
+		if err := s.familyRepo.AddFamilyMember(familyCode, user.ID, "parent"); err != nil {
+			return nil, fmt.Errorf("failed to join family: %w", err)
+		}
+	default:
+		if _, err := s.familyRepo.CreateFamily(user.ID); err != nil {
+			fmt.Printf("Warning: failed to create family for user %d: %v\n", user.ID, err)
+		}
+	}
+
+	return user, nil
+}
+
+// GetIdentities lists the sign-in provider accounts linked to a user
+func (s *AuthService) GetIdentities(userID int64) ([]models.UserIdentity, error) {
+	return s.identityRepo.GetUserIdentities(userID)
 }
 
-// RequestPasswordReset creates a password reset token and sends an email
-func (s *AuthService) RequestPasswordReset(ctx context.Context, emailService *EmailService, email string) error {
+// LinkIdentity links a provider account to a signed-in user. Linking an account
+// the user already has is a no-op.
+func (s *AuthService) LinkIdentity(userID int64, identity OAuthIdentity) error {
+	if identity.Provider == "" || identity.Subject == "" {
+		return errors.New("missing oauth provider information")
+	}
+
+	linked, err := s.identityRepo.GetIdentity(identity.Provider, identity.Subject)
+	if err != nil {
+		return fmt.Errorf("failed to lookup oauth user: %w", err)
+	}
+	if linked != nil {
+		if linked.UserID != userID {
+			return ErrIdentityInUse
+		}
+		return s.identityRepo.TouchIdentity(linked.ID, identity.Email, time.Now())
+	}
+
+	return s.linkIdentity(userID, identity)
+}
+
+// linkIdentity links a provider account that isn't linked to anyone yet
+func (s *AuthService) linkIdentity(userID int64, identity OAuthIdentity) error {
+	identities, err := s.identityRepo.GetUserIdentities(userID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range identities {
+		if existing.Provider == identity.Provider {
+			return ErrProviderAlreadyLinked
+		}
+	}
+
+	_, err = s.identityRepo.LinkIdentity(userID, identity.Provider, identity.Subject, identity.Email)
+	return err
+}
+
+// UnlinkIdentity removes a user's provider account, as long as they can still sign in
+// with a password or another provider afterwards
+func (s *AuthService) UnlinkIdentity(userID int64, provider string) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return ErrIdentityNotFound
+	}
+
+	identities, err := s.identityRepo.GetUserIdentities(userID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			found = true
+		}
+	}
+	if !found {
+		return ErrIdentityNotFound
+	}
+	if !user.HasPassword() && len(identities) == 1 {
+		return ErrLastSignInMethod
+	}
+
+	if _, err := s.identityRepo.UnlinkIdentity(userID, provider); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetPassword sets or changes a user's password. Users who already have a password
+// must give it; users who have only ever signed in with a provider don't have one.
+// Every other session is signed out, keeping keepSessionID, the one making the change.
+func (s *AuthService) SetPassword(userID int64, currentPassword, newPassword, keepSessionID string) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return ErrInvalidCredentials
+	}
+	if user.HasPassword() && !security.CheckPassword(currentPassword, user.PasswordHash) {
+		return ErrWrongPassword
+	}
+
+	if err := validation.ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
+	passwordHash, err := security.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.userRepo.UpdatePassword(userID, passwordHash); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if _, err := s.userRepo.DeleteUserSessions(userID, keepSessionID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := s.twoFactorRepo.DeleteUserLoginChallenges(userID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RequestPasswordReset creates a password reset token and sends an email in the given locale
+func (s *AuthService) RequestPasswordReset(ctx context.Context, emailService *EmailService, email, locale string) error {
 	// Get user by email
 	user, err := s.userRepo.GetUserByEmail(email)
 	if err != nil {
@@ -264,8 +453,9 @@ func (s *AuthService) RequestPasswordReset(ctx context.Context, emailService *Em
 		return nil
 	}
 
-	// Don't allow password reset for OAuth-only accounts
-	if user.OAuthProvider != "" && user.PasswordHash == "" {
+	// Don't allow password reset for OAuth-only accounts; they can set a password
+	// from their account settings
+	if !user.HasPassword() {
 		return nil
 	}
 
@@ -286,7 +476,7 @@ func (s *AuthService) RequestPasswordReset(ctx context.Context, emailService *Em
 
 	// Send email
 	if emailService != nil && emailService.IsEnabled() {
-		if err := emailService.SendPasswordResetEmail(ctx, user.Email, user.Name, token); err != nil {
+		if err := emailService.SendPasswordResetEmail(ctx, user.Email, user.Name, token, locale); err != nil {
 			return fmt.Errorf("failed to send reset email: %w", err)
 		}
 	}
@@ -316,8 +506,9 @@ func (s *AuthService) ValidatePasswordResetToken(token string) (bool, error) {
 	return true, nil
 }
 
-// ResetPassword resets a user's password using a valid token
-func (s *AuthService) ResetPassword(token, newPassword string) error {
+// ResetPassword resets a user's password using a valid token, from the client IP
+// address ip
+func (s *AuthService) ResetPassword(token, newPassword, ip string) error {
 	// Validate token
 	resetToken, err := s.userRepo.GetPasswordResetToken(token)
 	if err != nil {
@@ -359,8 +550,27 @@ func (s *AuthService) ResetPassword(token, newPassword string) error {
 
 	// Invalidate all existing sessions for this user (force re-login)
 	// This is a security best practice after password change
-	// Note: We'd need to add a method to delete all sessions for a user
-	// For now, they'll be cleaned up on next session validation
+	if _, err := s.userRepo.DeleteUserSessions(resetToken.UserID, ""); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	// A reset is how an account is taken back, so nothing signed in with the old
+	// password survives it: half-finished two-factor sign-ins and API tokens too
+	if err := s.twoFactorRepo.DeleteUserLoginChallenges(resetToken.UserID); err != nil {
+		return err
+	}
+	if _, err := s.apiTokenRepo.DeleteUserTokens(resetToken.UserID); err != nil {
+		return err
+	}
+
+	// The user has proved they own the account, so let them log in straight away
+	// if wrong passwords had locked it
+	user, err := s.userRepo.GetUserByID(resetToken.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user != nil {
+		s.clearFailedLogins(user.Email, ip)
+	}
 
 	return nil
 }
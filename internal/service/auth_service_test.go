@@ -0,0 +1,208 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"spellingclash/internal/repository"
+	"spellingclash/internal/security"
+	"testing"
+	"time"
+)
+
+func newAuthTestService(t *testing.T) *AuthService {
+	t.Helper()
+	db := newTestDB(t)
+	return NewAuthService(
+		repository.NewUserRepository(db), repository.NewFamilyRepository(db), repository.NewIdentityRepository(db),
+		repository.NewTwoFactorRepository(db), repository.NewAPITokenRepository(db), repository.NewSettingsRepository(db),
+		security.NewStoreLimiter(repository.NewRateLimitRepository(db)), time.Hour, "secret",
+	)
+}
+
+func TestOAuthLoginMergesVerifiedEmail(t *testing.T) {
+	auth := newAuthTestService(t)
+	parent, err := auth.Register("parent@example.com", "correct horse", "Parent", "")
+	if err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+
+	google := OAuthIdentity{Provider: "google", Subject: "g-1", Email: "parent@example.com", Name: "Parent"}
+	if _, _, err := auth.OAuthLogin(google, ""); !errors.Is(err, ErrAccountExists) {
+		t.Fatalf("OAuthLogin() with an unverified email = %v, want ErrAccountExists", err)
+	}
+
+	google.EmailVerified = true
+	_, user, err := auth.OAuthLogin(google, "")
+	if err != nil {
+		t.Fatalf("OAuthLogin() error: %v", err)
+	}
+	if user.ID != parent.ID {
+		t.Errorf("OAuthLogin() signed in user %d, want the existing user %d", user.ID, parent.ID)
+	}
+
+	// Once linked, the provider account signs in even if its email changes
+	google.Email, google.EmailVerified = "renamed@example.com", false
+	if _, user, err := auth.OAuthLogin(google, ""); err != nil || user.ID != parent.ID {
+		t.Fatalf("OAuthLogin() after linking = %v, %v, want the existing user", user, err)
+	}
+
+	// A second account from the same provider can't be merged into the same user
+	other := OAuthIdentity{Provider: "google", Subject: "g-2", Email: "parent@example.com", EmailVerified: true}
+	if _, _, err := auth.OAuthLogin(other, ""); !errors.Is(err, ErrAccountExists) {
+		t.Errorf("OAuthLogin() with a second Google account = %v, want ErrAccountExists", err)
+	}
+}
+
+func TestLinkAndUnlinkIdentities(t *testing.T) {
+	auth := newAuthTestService(t)
+
+	// A user created by signing in with Apple has no password
+	apple := OAuthIdentity{Provider: "apple", Subject: "a-1", Email: "apple@example.com", EmailVerified: true}
+	_, user, err := auth.OAuthLogin(apple, "")
+	if err != nil {
+		t.Fatalf("OAuthLogin() error: %v", err)
+	}
+	if user.HasPassword() {
+		t.Fatal("new OAuth user has a password")
+	}
+	if err := auth.UnlinkIdentity(user.ID, "apple"); !errors.Is(err, ErrLastSignInMethod) {
+		t.Fatalf("UnlinkIdentity() of the only sign-in method = %v, want ErrLastSignInMethod", err)
+	}
+
+	google := OAuthIdentity{Provider: "google", Subject: "g-1", Email: "someone@gmail.example"}
+	if err := auth.LinkIdentity(user.ID, google); err != nil {
+		t.Fatalf("LinkIdentity() error: %v", err)
+	}
+	if err := auth.LinkIdentity(user.ID, google); err != nil {
+		t.Errorf("LinkIdentity() again = %v, want no error", err)
+	}
+	if err := auth.LinkIdentity(user.ID, OAuthIdentity{Provider: "google", Subject: "g-2"}); !errors.Is(err, ErrProviderAlreadyLinked) {
+		t.Errorf("LinkIdentity() of a second Google account = %v, want ErrProviderAlreadyLinked", err)
+	}
+
+	_, otherUser, err := auth.OAuthLogin(OAuthIdentity{Provider: "facebook", Subject: "f-1", Email: "other@example.com"}, "")
+	if err != nil {
+		t.Fatalf("OAuthLogin() error: %v", err)
+	}
+	if err := auth.LinkIdentity(otherUser.ID, google); !errors.Is(err, ErrIdentityInUse) {
+		t.Errorf("LinkIdentity() of someone else's account = %v, want ErrIdentityInUse", err)
+	}
+
+	if err := auth.UnlinkIdentity(user.ID, "apple"); err != nil {
+		t.Fatalf("UnlinkIdentity() error: %v", err)
+	}
+	if err := auth.UnlinkIdentity(user.ID, "apple"); !errors.Is(err, ErrIdentityNotFound) {
+		t.Errorf("UnlinkIdentity() again = %v, want ErrIdentityNotFound", err)
+	}
+	if _, signedIn, err := auth.OAuthLogin(google, ""); err != nil || signedIn.ID != user.ID {
+		t.Errorf("OAuthLogin() with the linked account = %v, %v, want user %d", signedIn, err, user.ID)
+	}
+
+	// With a password set, the last provider can go too
+	if err := auth.SetPassword(user.ID, "", "correct horse", ""); err != nil {
+		t.Fatalf("SetPassword() error: %v", err)
+	}
+	if err := auth.UnlinkIdentity(user.ID, "google"); err != nil {
+		t.Fatalf("UnlinkIdentity() with a password = %v, want no error", err)
+	}
+	if _, _, err := auth.Login("apple@example.com", "correct horse", LoginClient{IP: "203.0.113.7"}); err != nil {
+		t.Errorf("Login() with the new password error: %v", err)
+	}
+}
+
+func TestSetPasswordRequiresCurrentPassword(t *testing.T) {
+	auth := newAuthTestService(t)
+	user, err := auth.Register("parent@example.com", "correct horse", "Parent", "")
+	if err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+
+	if err := auth.SetPassword(user.ID, "wrong", "battery staple", ""); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("SetPassword() with the wrong password = %v, want ErrWrongPassword", err)
+	}
+	if err := auth.SetPassword(user.ID, "correct horse", "short", ""); err == nil {
+		t.Error("SetPassword() accepted a short password")
+	}
+	if err := auth.SetPassword(user.ID, "correct horse", "battery staple", ""); err != nil {
+		t.Fatalf("SetPassword() error: %v", err)
+	}
+	if _, _, err := auth.Login("parent@example.com", "battery staple", LoginClient{IP: "203.0.113.7"}); err != nil {
+		t.Errorf("Login() with the new password error: %v", err)
+	}
+}
+
+func TestAccountLockout(t *testing.T) {
+	auth := newAuthTestService(t)
+	parent, err := auth.Register("parent@example.com", "correct horse", "Parent", "")
+	if err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	guesser := LoginClient{IP: "203.0.113.7"}
+	home := LoginClient{IP: "198.51.100.1"}
+
+	// A correct password clears earlier failures
+	for range accountAddressLockout.Limit - 1 {
+		if _, _, err := auth.Login("parent@example.com", "wrong", guesser); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Login() with a wrong password = %v, want ErrInvalidCredentials", err)
+		}
+	}
+	if _, _, err := auth.Login("parent@example.com", "correct horse", guesser); err != nil {
+		t.Fatalf("Login() error: %v", err)
+	}
+
+	// Guessing from one address locks the account there, but not elsewhere
+	for range accountAddressLockout.Limit {
+		if _, _, err := auth.Login("Parent@Example.com ", "wrong", guesser); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Login() with a wrong password = %v, want ErrInvalidCredentials", err)
+		}
+	}
+	var locked *AccountLockedError
+	if _, _, err := auth.Login("parent@example.com", "correct horse", guesser); !errors.As(err, &locked) || locked.RetryAfter <= 0 {
+		t.Fatalf("Login() to a locked account = %v, want an AccountLockedError", err)
+	}
+	session, signedIn, err := auth.Login("parent@example.com", "correct horse", home)
+	if err != nil || session == nil {
+		t.Fatalf("Login() from another address = %v, want a session", err)
+	}
+	home.DeviceTokens = []string{auth.DeviceToken(signedIn)}
+
+	// Guessing from many addresses locks the account everywhere, except on
+	// devices that have signed in before
+	for i := range accountLockout.Limit {
+		spread := LoginClient{IP: fmt.Sprintf("192.0.2.%d", i)}
+		if _, _, err := auth.Login("parent@example.com", "wrong", spread); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Login() with a wrong password = %v, want ErrInvalidCredentials", err)
+		}
+	}
+	if _, _, err := auth.Login("parent@example.com", "correct horse", LoginClient{IP: "198.51.100.2"}); !errors.As(err, &locked) {
+		t.Fatalf("Login() from a new address = %v, want an AccountLockedError", err)
+	}
+	if _, _, err := auth.Login("parent@example.com", "correct horse", LoginClient{IP: "198.51.100.2", DeviceTokens: []string{"1-forged"}}); !errors.As(err, &locked) {
+		t.Errorf("Login() with a forged device token = %v, want an AccountLockedError", err)
+	}
+	if _, _, err := auth.Login("parent@example.com", "correct horse", home); err != nil {
+		t.Fatalf("Login() from a known device = %v, want a session", err)
+	}
+
+	// Resetting the password unlocks the account from the address it was reset at
+	for range accountAddressLockout.Limit {
+		auth.Login("parent@example.com", "wrong", guesser)
+	}
+	if err := auth.userRepo.CreatePasswordResetToken("reset-token", parent.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("CreatePasswordResetToken() error: %v", err)
+	}
+	if err := auth.ResetPassword("reset-token", "battery staple", guesser.IP); err != nil {
+		t.Fatalf("ResetPassword() error: %v", err)
+	}
+	if _, _, err := auth.Login("parent@example.com", "battery staple", guesser); err != nil {
+		t.Errorf("Login() after a password reset = %v, want a session", err)
+	}
+
+	// Other accounts aren't affected
+	if _, err := auth.Register("other@example.com", "correct horse", "Other", ""); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	if _, _, err := auth.Login("other@example.com", "correct horse", guesser); err != nil {
+		t.Errorf("Login() to another account = %v, want a session", err)
+	}
+}
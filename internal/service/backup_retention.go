@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// backupTimeLayout is the timestamp used in backup file names
+const backupTimeLayout = "20060102_150405"
+
+// backupFilePattern matches names written by BackupFileName, e.g.
+// backup_20240102_150405.jsonl.gz or backup_20240102_150405_incremental.jsonl.zst
+var backupFilePattern = regexp.MustCompile(`^backup_(\d{8}_\d{6})(_incremental)?\.jsonl?(\.gz|\.zst)?$`)
+
+// BackupFile is a backup found in a backup directory
+type BackupFile struct {
+	Path        string
+	CreatedAt   time.Time
+	Incremental bool
+}
+
+// RetentionPolicy says how many full backups to keep: the newest backup of
+// each of the last Daily days and of each of the last Weekly ISO weeks
+type RetentionPolicy struct {
+	Daily  int
+	Weekly int
+}
+
+// BackupFileName returns the file name for a backup taken at t
+func BackupFileName(t time.Time, incremental bool, format BackupFormat, compression BackupCompression) string {
+	name := "backup_" + t.Format(backupTimeLayout)
+	if incremental {
+		name += "_incremental"
+	}
+	return name + BackupFileExtension(format, compression)
+}
+
+// ListBackupFiles returns the backups in dir, newest first. Files not named
+// by BackupFileName are ignored.
+func ListBackupFiles(dir string) ([]BackupFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory %s: %w", dir, err)
+	}
+
+	var files []BackupFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := backupFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		createdAt, err := time.ParseInLocation(backupTimeLayout, match[1], time.Local)
+		if err != nil {
+			continue
+		}
+		files = append(files, BackupFile{
+			Path:        filepath.Join(dir, entry.Name()),
+			CreatedAt:   createdAt,
+			Incremental: match[2] != "",
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].CreatedAt.After(files[j].CreatedAt)
+	})
+	return files, nil
+}
+
+// Prune splits backups into those the policy keeps and those it removes.
+// Incremental backups are kept only while they are newer than the latest full
+// backup, since that full backup already holds their changes.
+func (p RetentionPolicy) Prune(files []BackupFile) (keep, remove []BackupFile) {
+	sorted := append([]BackupFile(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	var latestFull time.Time
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	for _, file := range sorted {
+		if file.Incremental {
+			continue
+		}
+		if latestFull.IsZero() {
+			latestFull = file.CreatedAt
+		}
+		day := file.CreatedAt.Format("2006-01-02")
+		year, week := file.CreatedAt.ISOWeek()
+		weekKey := fmt.Sprintf("%d-%02d", year, week)
+
+		// Files are newest first, so the first file seen for a period is its newest
+		keepFile := false
+		if !days[day] && len(days) < p.Daily {
+			days[day] = true
+			keepFile = true
+		}
+		if !weeks[weekKey] && len(weeks) < p.Weekly {
+			weeks[weekKey] = true
+			keepFile = true
+		}
+
+		if keepFile {
+			keep = append(keep, file)
+		} else {
+			remove = append(remove, file)
+		}
+	}
+
+	for _, file := range sorted {
+		if !file.Incremental {
+			continue
+		}
+		if latestFull.IsZero() || file.CreatedAt.After(latestFull) {
+			keep = append(keep, file)
+		} else {
+			remove = append(remove, file)
+		}
+	}
+
+	return keep, remove
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestListBackupFiles(t *testing.T) {
+	dir := t.TempDir()
+	created := time.Date(2024, 3, 4, 5, 6, 7, 0, time.Local)
+	names := []string{
+		BackupFileName(created, false, BackupFormatJSONL, BackupCompressionGzip),
+		BackupFileName(created.Add(time.Hour), true, BackupFormatJSONL, BackupCompressionZstd),
+		BackupFileName(created.Add(-time.Hour), false, BackupFormatJSON, BackupCompressionNone),
+		"notes.txt",
+		"backup_latest.json",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := ListBackupFiles(dir)
+	if err != nil {
+		t.Fatalf("ListBackupFiles() error: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("found %d backups, want 3: %+v", len(files), files)
+	}
+	if filepath.Base(files[0].Path) != "backup_20240304_060607_incremental.jsonl.zst" || !files[0].Incremental {
+		t.Errorf("newest backup = %+v, want the incremental one", files[0])
+	}
+	if !files[1].CreatedAt.Equal(created) || files[1].Incremental {
+		t.Errorf("second backup = %+v, want full backup at %s", files[1], created)
+	}
+}
+
+func TestRetentionPolicyPrune(t *testing.T) {
+	// Mon 2024-03-04 is the start of ISO week 10
+	day := func(d, hour int) time.Time {
+		return time.Date(2024, 3, d, hour, 0, 0, 0, time.UTC)
+	}
+	files := []BackupFile{
+		{Path: "full-0310-12", CreatedAt: day(10, 12)},
+		{Path: "full-0310-02", CreatedAt: day(10, 2)},
+		{Path: "full-0309", CreatedAt: day(9, 2)},
+		{Path: "full-0308", CreatedAt: day(8, 2)},
+		{Path: "full-0303", CreatedAt: day(3, 2)},
+		{Path: "full-0302", CreatedAt: day(2, 2)},
+		{Path: "full-0225", CreatedAt: time.Date(2024, 2, 25, 2, 0, 0, 0, time.UTC)},
+		{Path: "inc-0310-13", CreatedAt: day(10, 13), Incremental: true},
+		{Path: "inc-0310-06", CreatedAt: day(10, 6), Incremental: true},
+	}
+
+	keep, remove := RetentionPolicy{Daily: 2, Weekly: 2}.Prune(files)
+
+	paths := func(files []BackupFile) map[string]bool {
+		m := make(map[string]bool)
+		for _, f := range files {
+			m[f.Path] = true
+		}
+		return m
+	}
+	wantKeep := []string{"full-0310-12", "full-0309", "full-0303", "inc-0310-13"}
+	wantRemove := []string{"full-0310-02", "full-0308", "full-0302", "full-0225", "inc-0310-06"}
+
+	kept := paths(keep)
+	for _, p := range wantKeep {
+		if !kept[p] {
+			t.Errorf("expected %s to be kept", p)
+		}
+	}
+	removed := paths(remove)
+	for _, p := range wantRemove {
+		if !removed[p] {
+			t.Errorf("expected %s to be removed", p)
+		}
+	}
+	if len(keep)+len(remove) != len(files) {
+		t.Errorf("kept %d and removed %d of %d files", len(keep), len(remove), len(files))
+	}
+}
+
+func TestRetentionPolicyKeepsIncrementalsWithoutFull(t *testing.T) {
+	files := []BackupFile{{Path: "inc", CreatedAt: time.Now(), Incremental: true}}
+	keep, remove := RetentionPolicy{Daily: 1}.Prune(files)
+	if len(keep) != 1 || len(remove) != 0 {
+		t.Errorf("Prune() kept %v removed %v, want the incremental kept", keep, remove)
+	}
+}
@@ -1,27 +1,76 @@
 package service
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"spellingclash/internal/database"
+	"spellingclash/internal/models"
 	"time"
 )
 
+// BackupSchemaVersion is the version of the backup format written by Export.
+// Version 1 backups (no schema_version field) only held users, families, kids,
+// family lists, words, assignments and practice sessions. Before version 3 each
+// user had at most one sign-in provider, stored on the user.
+const BackupSchemaVersion = 3
+
+// backupVersion is the human-readable version written alongside the schema version
+const backupVersion = "3.0"
+
+var (
+	ErrUnsupportedBackupVersion = errors.New("backup was created by a newer version and cannot be imported")
+)
+
 // BackupData represents the complete database backup structure
 type BackupData struct {
-	Version      string          `json:"version"`
-	ExportedAt   time.Time       `json:"exported_at"`
-	DatabaseType string          `json:"database_type"`
-	Users        []UserBackup    `json:"users"`
-	Families     []FamilyBackup  `json:"families"`
-	Kids         []KidBackup     `json:"kids"`
-	Lists        []ListBackup    `json:"lists"`
-	Words        []WordBackup    `json:"words"`
-	Practices    []PracticeBackup `json:"practices"`
+	Version               string                      `json:"version"`
+	SchemaVersion         int                         `json:"schema_version"`
+	ExportedAt            time.Time                   `json:"exported_at"`
+	Since                 *time.Time                  `json:"since,omitempty"`
+	DatabaseType          string                      `json:"database_type"`
+	Users                 []UserBackup                `json:"users"`
+	UserIdentities        []UserIdentityBackup        `json:"user_identities,omitempty"`
+	TwoFactors            []TwoFactorBackup           `json:"two_factors,omitempty"`
+	RecoveryCodes         []RecoveryCodeBackup        `json:"recovery_codes,omitempty"`
+	Families              []FamilyBackup              `json:"families"`
+	FamilyMembers         []FamilyMemberBackup        `json:"family_members,omitempty"`
+	StreakSettings        []StreakSettingsBackup      `json:"streak_settings,omitempty"`
+	StreakFreezes         []StreakFreezeBackup        `json:"streak_freezes,omitempty"`
+	Kids                  []KidBackup                 `json:"kids"`
+	TeacherKids           []TeacherKidBackup          `json:"teacher_kids"`
+	Lists                 []ListBackup                `json:"lists"`
+	ListAssignments       []ListAssignmentBackup      `json:"list_assignments,omitempty"`
+	TeacherClasses        []TeacherClassBackup        `json:"teacher_classes,omitempty"`
+	TeacherClassMembers   []TeacherClassMemberBackup  `json:"teacher_class_members,omitempty"`
+	TeacherClassLists     []TeacherClassListBackup    `json:"teacher_class_lists,omitempty"`
+	Words                 []WordBackup                `json:"words"`
+	WordSchedules         []WordScheduleBackup        `json:"word_schedules"`
+	Achievements          []AchievementBackup         `json:"achievements,omitempty"`
+	LeaderboardSettings   []LeaderboardSettingsBackup `json:"leaderboard_settings,omitempty"`
+	ClashMatches          []ClashMatchBackup          `json:"clash_matches,omitempty"`
+	SpellingBees          []SpellingBeeBackup         `json:"spelling_bees,omitempty"`
+	SpellingBeeKids       []SpellingBeeKidBackup      `json:"spelling_bee_participants,omitempty"`
+	SpellingBeeTurns      []SpellingBeeTurnBackup     `json:"spelling_bee_turns,omitempty"`
+	Practices             []PracticeBackup            `json:"practices"`
+	WordAttempts          []WordAttemptBackup         `json:"word_attempts"`
+	PracticeStates        []PracticeStateBackup       `json:"practice_states"`
+	PracticeWordTimings   []PracticeWordTimingBackup  `json:"practice_word_timings"`
+	HangmanSessions       []GameSessionBackup         `json:"hangman_sessions"`
+	HangmanGames          []HangmanGameBackup         `json:"hangman_games"`
+	HangmanStates         []GameStateBackup           `json:"hangman_states"`
+	MissingLetterSessions []GameSessionBackup         `json:"missing_letter_sessions"`
+	MissingLetterGames    []MissingLetterGameBackup   `json:"missing_letter_games"`
+	MissingLetterStates   []GameStateBackup           `json:"missing_letter_states"`
+	SpellingTests         []SpellingTestBackup        `json:"spelling_tests,omitempty"`
+	SpellingTestAttempts  []SpellingTestAttemptBackup `json:"spelling_test_attempts,omitempty"`
+	SpellingTestAnswers   []SpellingTestAnswerBackup  `json:"spelling_test_answers,omitempty"`
+	Invitations           []InvitationBackup          `json:"invitations"`
+	DigestSubscriptions   []DigestSubscriptionBackup  `json:"digest_subscriptions,omitempty"`
+	Settings              []SettingBackup             `json:"settings"`
 }
 
 // UserBackup represents a user record for backup
@@ -30,25 +79,57 @@ type UserBackup struct {
 	Email         string    `json:"email"`
 	PasswordHash  string    `json:"password_hash"`
 	Name          string    `json:"name"`
-	OAuthProvider string    `json:"oauth_provider"`
-	OAuthSubject  string    `json:"oauth_subject"`
+	OAuthProvider string    `json:"oauth_provider,omitempty"` // Only in schema version 2 and older
+	OAuthSubject  string    `json:"oauth_subject,omitempty"`  // Only in schema version 2 and older
 	IsAdmin       bool      `json:"is_admin"`
+	IsTeacher     bool      `json:"is_teacher"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
+// UserIdentityBackup represents a sign-in provider account linked to a user
+type UserIdentityBackup struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Provider   string     `json:"provider"`
+	Subject    string     `json:"subject"`
+	Email      string     `json:"email"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// TwoFactorBackup represents a user's TOTP authenticator
+type TwoFactorBackup struct {
+	UserID       int64      `json:"user_id"`
+	Secret       string     `json:"secret"`
+	EnabledAt    *time.Time `json:"enabled_at"`
+	LastUsedStep int64      `json:"last_used_step"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// RecoveryCodeBackup represents a hashed two-factor recovery code
+type RecoveryCodeBackup struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	CodeHash  string     `json:"code_hash"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
 // FamilyBackup represents a family record for backup
 type FamilyBackup struct {
-	FamilyCode string    `json:"family_code"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
-	Members    []FamilyMemberBackup `json:"members"`
+	FamilyCode string               `json:"family_code"`
+	CreatedAt  time.Time            `json:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+	Members    []FamilyMemberBackup `json:"members,omitempty"`
 }
 
-// FamilyMemberBackup represents a family member record
+// FamilyMemberBackup represents a family member record. FamilyCode is omitted
+// when the member is nested under its family.
 type FamilyMemberBackup struct {
-	UserID int64  `json:"user_id"`
-	Role   string `json:"role"`
+	FamilyCode string `json:"family_code,omitempty"`
+	UserID     int64  `json:"user_id"`
+	Role       string `json:"role"`
 }
 
 // KidBackup represents a kid record for backup
@@ -65,40 +146,348 @@ type KidBackup struct {
 
 // ListBackup represents a spelling list for backup
 type ListBackup struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	FamilyCode  *string   `json:"family_code"`
-	IsPublic    bool      `json:"is_public"`
-	CreatedBy   *int64    `json:"created_by"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Assignments []int64   `json:"assigned_kids"`
+	ID          int64                  `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	FamilyCode  *string                `json:"family_code"`
+	IsPublic    bool                   `json:"is_public"`
+	Locale      string                 `json:"locale,omitempty"`
+	CreatedBy   *int64                 `json:"created_by"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	Assignments []ListAssignmentBackup `json:"assignments,omitempty"`
+	// AssignedKids is only set in version 1 backups, which stored kid IDs alone
+	AssignedKids []int64 `json:"assigned_kids,omitempty"`
+}
+
+// ListAssignmentBackup represents a list assigned to a kid. SpellingListID is
+// omitted when the assignment is nested under its list.
+type ListAssignmentBackup struct {
+	SpellingListID   int64      `json:"spelling_list_id,omitempty"`
+	KidID            int64      `json:"kid_id"`
+	AssignedAt       time.Time  `json:"assigned_at"`
+	AssignedBy       int64      `json:"assigned_by"`
+	ManagedByTeacher bool       `json:"managed_by_teacher"`
+	DueDate          *time.Time `json:"due_date"`
+}
+
+// TeacherKidBackup represents a link between a teacher and a kid
+type TeacherKidBackup struct {
+	ID            int64     `json:"id"`
+	TeacherUserID int64     `json:"teacher_user_id"`
+	KidID         int64     `json:"kid_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TeacherClassBackup represents a teacher's class
+type TeacherClassBackup struct {
+	ID            int64      `json:"id"`
+	TeacherUserID int64      `json:"teacher_user_id"`
+	Name          string     `json:"name"`
+	ArchivedAt    *time.Time `json:"archived_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TeacherClassMemberBackup represents a kid in a teacher's class
+type TeacherClassMemberBackup struct {
+	ID        int64     `json:"id"`
+	ClassID   int64     `json:"class_id"`
+	KidID     int64     `json:"kid_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TeacherClassListBackup represents a list assigned to a teacher's class
+type TeacherClassListBackup struct {
+	ID             int64      `json:"id"`
+	ClassID        int64      `json:"class_id"`
+	SpellingListID int64      `json:"spelling_list_id"`
+	DueDate        *time.Time `json:"due_date"`
+	AssignedAt     time.Time  `json:"assigned_at"`
+}
+
+// SpellingTestBackup represents a spelling test scheduled for a class
+type SpellingTestBackup struct {
+	ID             int64     `json:"id"`
+	ClassID        int64     `json:"class_id"`
+	SpellingListID int64     `json:"spelling_list_id"`
+	CreatedBy      int64     `json:"created_by"`
+	OpensAt        time.Time `json:"opens_at"`
+	ClosesAt       time.Time `json:"closes_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// SpellingTestAttemptBackup represents a kid's attempt at a spelling test
+type SpellingTestAttemptBackup struct {
+	ID           int64      `json:"id"`
+	TestID       int64      `json:"test_id"`
+	KidID        int64      `json:"kid_id"`
+	TotalWords   int        `json:"total_words"`
+	CorrectWords int        `json:"correct_words"`
+	StartedAt    time.Time  `json:"started_at"`
+	CompletedAt  *time.Time `json:"completed_at"`
+}
+
+// SpellingTestAnswerBackup represents an answer given in a spelling test
+type SpellingTestAnswerBackup struct {
+	ID         int64     `json:"id"`
+	AttemptID  int64     `json:"attempt_id"`
+	WordID     int64     `json:"word_id"`
+	WordText   string    `json:"word_text"`
+	Answer     string    `json:"answer"`
+	IsCorrect  bool      `json:"is_correct"`
+	AnsweredAt time.Time `json:"answered_at"`
 }
 
 // WordBackup represents a word for backup
 type WordBackup struct {
-	ID                      int64  `json:"id"`
-	SpellingListID          int64  `json:"spelling_list_id"`
-	WordText                string `json:"word_text"`
-	DifficultyLevel         int    `json:"difficulty_level"`
-	AudioFilename           string `json:"audio_filename"`
-	Definition              string `json:"definition"`
-	DefinitionAudioFilename string `json:"definition_audio_filename"`
-	Position                int    `json:"position"`
+	ID                      int64     `json:"id"`
+	SpellingListID          int64     `json:"spelling_list_id"`
+	WordText                string    `json:"word_text"`
+	DifficultyLevel         int       `json:"difficulty_level"`
+	AudioFilename           string    `json:"audio_filename"`
+	Definition              string    `json:"definition"`
+	DefinitionAudioFilename string    `json:"definition_audio_filename"`
+	Position                int       `json:"position"`
 	CreatedAt               time.Time `json:"created_at"`
 }
 
 // PracticeBackup represents a practice session for backup
 type PracticeBackup struct {
+	ID             int64      `json:"id"`
+	KidID          int64      `json:"kid_id"`
+	SpellingListID int64      `json:"spelling_list_id"`
+	StartedAt      time.Time  `json:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	TotalWords     int        `json:"total_words"`
+	CorrectWords   int        `json:"correct_words"`
+	PointsEarned   int        `json:"points_earned"`
+}
+
+// WordScheduleBackup represents a kid's spaced-repetition schedule for a word
+type WordScheduleBackup struct {
+	KidID          int64      `json:"kid_id"`
+	WordID         int64      `json:"word_id"`
+	Repetitions    int        `json:"repetitions"`
+	IntervalDays   int        `json:"interval_days"`
+	EaseFactor     float64    `json:"ease_factor"`
+	Lapses         int        `json:"lapses"`
+	DueAt          time.Time  `json:"due_at"`
+	LastReviewedAt *time.Time `json:"last_reviewed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// AchievementBackup represents an achievement a kid has unlocked
+type AchievementBackup struct {
+	ID             int64      `json:"id"`
+	KidID          int64      `json:"kid_id"`
+	AchievementKey string     `json:"achievement_key"`
+	UnlockedAt     time.Time  `json:"unlocked_at"`
+	SeenAt         *time.Time `json:"seen_at"`
+}
+
+// LeaderboardSettingsBackup represents how a kid appears on leaderboards
+type LeaderboardSettingsBackup struct {
+	KidID           int64     `json:"kid_id"`
+	Nickname        string    `json:"nickname"`
+	HiddenByParent  bool      `json:"hidden_by_parent"`
+	HiddenByTeacher bool      `json:"hidden_by_teacher"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ClashMatchBackup represents a head-to-head clash match between two kids
+type ClashMatchBackup struct {
+	ID              int64      `json:"id"`
+	SpellingListID  int64      `json:"spelling_list_id"`
+	ChallengerKidID int64      `json:"challenger_kid_id"`
+	OpponentKidID   int64      `json:"opponent_kid_id"`
+	Status          string     `json:"status"`
+	TotalRounds     int        `json:"total_rounds"`
+	ChallengerScore int        `json:"challenger_score"`
+	OpponentScore   int        `json:"opponent_score"`
+	WinnerKidID     *int64     `json:"winner_kid_id"`
+	CreatedAt       time.Time  `json:"created_at"`
+	StartedAt       *time.Time `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+}
+
+// SpellingBeeBackup represents a classroom spelling bee a teacher hosted
+type SpellingBeeBackup struct {
+	ID             int64      `json:"id"`
+	TeacherUserID  int64      `json:"teacher_user_id"`
+	SpellingListID int64      `json:"spelling_list_id"`
+	MaxMisses      int        `json:"max_misses"`
+	AnswerMode     string     `json:"answer_mode"`
+	Status         string     `json:"status"`
+	Version        int        `json:"version"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+}
+
+// SpellingBeeKidBackup represents a child's standing in a spelling bee
+type SpellingBeeKidBackup struct {
 	ID             int64     `json:"id"`
+	BeeID          int64     `json:"bee_id"`
 	KidID          int64     `json:"kid_id"`
-	SpellingListID int64     `json:"spelling_list_id"`
-	StartedAt      time.Time `json:"started_at"`
+	TurnOrder      int       `json:"turn_order"`
+	WordsCorrect   int       `json:"words_correct"`
+	Misses         int       `json:"misses"`
+	EliminatedTurn *int64    `json:"eliminated_turn"`
+	FinalPlace     *int64    `json:"final_place"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// SpellingBeeTurnBackup represents a word dictated to a child in a spelling bee
+type SpellingBeeTurnBackup struct {
+	ID         int64      `json:"id"`
+	BeeID      int64      `json:"bee_id"`
+	TurnNumber int        `json:"turn_number"`
+	KidID      int64      `json:"kid_id"`
+	WordID     *int64     `json:"word_id"`
+	WordText   string     `json:"word_text"`
+	Answer     *string    `json:"answer"`
+	IsCorrect  *bool      `json:"is_correct"`
+	AskedAt    time.Time  `json:"asked_at"`
+	AnsweredAt *time.Time `json:"answered_at"`
+}
+
+// WordAttemptBackup represents a single answer given during practice
+type WordAttemptBackup struct {
+	ID                int64     `json:"id"`
+	PracticeSessionID int64     `json:"practice_session_id"`
+	WordID            int64     `json:"word_id"`
+	AttemptText       string    `json:"attempt_text"`
+	IsCorrect         bool      `json:"is_correct"`
+	TimeTakenMs       int       `json:"time_taken_ms"`
+	PointsEarned      int       `json:"points_earned"`
+	AttemptedAt       time.Time `json:"attempted_at"`
+}
+
+// PracticeStateBackup represents a kid's in-progress practice
+type PracticeStateBackup struct {
+	KidID        int64     `json:"kid_id"`
+	SessionID    int64     `json:"session_id"`
+	CurrentIndex int       `json:"current_index"`
+	CorrectCount int       `json:"correct_count"`
+	TotalPoints  int       `json:"total_points"`
+	StartTime    time.Time `json:"start_time"`
+	WordOrder    string    `json:"word_order"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// PracticeWordTimingBackup represents when a kid was shown a practice word
+type PracticeWordTimingBackup struct {
+	ID        int64     `json:"id"`
+	KidID     int64     `json:"kid_id"`
+	SessionID int64     `json:"session_id"`
+	WordIndex int       `json:"word_index"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// GameSessionBackup represents a hangman or missing letter session
+type GameSessionBackup struct {
+	ID             int64      `json:"id"`
+	KidID          int64      `json:"kid_id"`
+	SpellingListID int64      `json:"spelling_list_id"`
+	StartedAt      time.Time  `json:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	TotalGames     int        `json:"total_games"`
+	GamesWon       int        `json:"games_won"`
+	TotalPoints    int        `json:"total_points"`
+}
+
+// HangmanGameBackup represents a single hangman game
+type HangmanGameBackup struct {
+	ID              int64      `json:"id"`
+	SessionID       int64      `json:"session_id"`
+	KidID           int64      `json:"kid_id"`
+	WordID          int64      `json:"word_id"`
+	Word            string     `json:"word"`
+	GuessedLetters  string     `json:"guessed_letters"`
+	WrongGuesses    int        `json:"wrong_guesses"`
+	MaxWrongGuesses int        `json:"max_wrong_guesses"`
+	IsWon           bool       `json:"is_won"`
+	IsLost          bool       `json:"is_lost"`
+	PointsEarned    int        `json:"points_earned"`
+	StartedAt       time.Time  `json:"started_at"`
+	CompletedAt     *time.Time `json:"completed_at"`
+}
+
+// MissingLetterGameBackup represents a single missing letter game
+type MissingLetterGameBackup struct {
+	ID             int64      `json:"id"`
+	SessionID      int64      `json:"session_id"`
+	KidID          int64      `json:"kid_id"`
+	WordID         int64      `json:"word_id"`
+	Word           string     `json:"word"`
+	MissingIndices string     `json:"missing_indices"`
+	GuessedLetters string     `json:"guessed_letters"`
+	Attempts       int        `json:"attempts"`
+	MaxAttempts    int        `json:"max_attempts"`
+	IsWon          bool       `json:"is_won"`
+	IsLost         bool       `json:"is_lost"`
+	PointsEarned   int        `json:"points_earned"`
+	StartedAt      time.Time  `json:"started_at"`
 	CompletedAt    *time.Time `json:"completed_at"`
-	TotalWords     int       `json:"total_words"`
-	CorrectWords   int       `json:"correct_words"`
-	PointsEarned   int       `json:"points_earned"`
+}
+
+// GameStateBackup represents a kid's in-progress hangman or missing letter game
+type GameStateBackup struct {
+	KidID          int64     `json:"kid_id"`
+	SessionID      int64     `json:"session_id"`
+	CurrentWordIdx int       `json:"current_word_idx"`
+	WordsJSON      string    `json:"words_json"`
+	PointsSoFar    int       `json:"points_so_far"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// InvitationBackup represents an invitation for backup
+type InvitationBackup struct {
+	ID         int64      `json:"id"`
+	Code       string     `json:"code"`
+	Email      string     `json:"email"`
+	InvitedBy  int64      `json:"invited_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UsedAt     *time.Time `json:"used_at"`
+	UsedBy     *int64     `json:"used_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	EmailSent  bool       `json:"email_sent"`
+	EmailError string     `json:"email_error"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+}
+
+// StreakSettingsBackup represents a family's streak settings
+type StreakSettingsBackup struct {
+	FamilyCode  string    `json:"family_code"`
+	Timezone    string    `json:"timezone"`
+	WeekendsOff bool      `json:"weekends_off"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// StreakFreezeBackup represents a family's days off from streaks
+type StreakFreezeBackup struct {
+	ID         int64     `json:"id"`
+	FamilyCode string    `json:"family_code"`
+	Name       string    `json:"name"`
+	StartDate  string    `json:"start_date"`
+	EndDate    string    `json:"end_date"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// DigestSubscriptionBackup represents a user's weekly digest subscription
+type DigestSubscriptionBackup struct {
+	UserID     int64      `json:"user_id"`
+	Enabled    bool       `json:"enabled"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// SettingBackup represents an app-wide setting
+type SettingBackup struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
 // PracticeResultBackup represents a practice result for backup
@@ -125,62 +514,58 @@ func (s *BackupService) GetDB() *database.DB {
 
 // Export creates a complete backup of the database to a file
 func (s *BackupService) Export(outputPath string) error {
+	return s.ExportFile(outputPath, ExportOptions{Format: BackupFormatJSON})
+}
+
+// ExportFile writes a backup to a file in the requested format and compression
+func (s *BackupService) ExportFile(outputPath string, opts ExportOptions) error {
 	log.Println("Starting database export...")
-	
-	backup := &BackupData{
-		Version:      "1.0",
-		ExportedAt:   time.Now(),
-		DatabaseType: "universal",
-	}
 
-	// Export users
-	if err := s.exportUsers(backup); err != nil {
-		return fmt.Errorf("failed to export users: %w", err)
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
 	}
+	defer file.Close()
 
-	// Export families
-	if err := s.exportFamilies(backup); err != nil {
-		return fmt.Errorf("failed to export families: %w", err)
+	if err := s.ExportWithOptions(file, opts); err != nil {
+		return err
 	}
-
-	// Export kids
-	if err := s.exportKids(backup); err != nil {
-		return fmt.Errorf("failed to export kids: %w", err)
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
 	}
 
-	// Export lists
-	if err := s.exportLists(backup); err != nil {
-		return fmt.Errorf("failed to export lists: %w", err)
-	}
+	log.Printf("Database exported successfully to %s", outputPath)
+	return nil
+}
 
-	// Export words
-	if err := s.exportWords(backup); err != nil {
-		return fmt.Errorf("failed to export words: %w", err)
-	}
+// ExportToWriter exports the database to an io.Writer (useful for HTTP responses)
+func (s *BackupService) ExportToWriter(w io.Writer) error {
+	return s.exportDocument(w, nil)
+}
 
-	// Export practice sessions
-	if err := s.exportPractices(backup); err != nil {
-		return fmt.Errorf("failed to export practices: %w", err)
+// exportDocument writes the backup as a single JSON document
+func (s *BackupService) exportDocument(w io.Writer, since *time.Time) error {
+	builder := newBackupBuilder(since)
+	for _, table := range backupTables {
+		err := table.export(s.db, since, func(row interface{}) error {
+			builder.add(table.tableName(), row)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", table.tableName(), err)
+		}
 	}
 
-	// Write to file
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
-	}
-	defer file.Close()
+	backup := builder.data
+	log.Printf("Exported: %d users, %d families, %d kids, %d lists, %d words, %d practices, %d hangman sessions, %d missing letter sessions, %d invitations",
+		len(backup.Users), len(backup.Families), len(backup.Kids), len(backup.Lists), len(backup.Words),
+		len(backup.Practices), len(backup.HangmanSessions), len(backup.MissingLetterSessions), len(backup.Invitations))
 
-	encoder := json.NewEncoder(file)
+	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 	if err := encoder.Encode(backup); err != nil {
 		return fmt.Errorf("failed to encode backup: %w", err)
 	}
-
-	log.Printf("Database exported successfully to %s", outputPath)
-	log.Printf("Exported: %d users, %d families, %d kids, %d lists, %d words, %d practices",
-		len(backup.Users), len(backup.Families), len(backup.Kids), 
-		len(backup.Lists), len(backup.Words), len(backup.Practices))
-
 	return nil
 }
 
@@ -194,376 +579,395 @@ func (s *BackupService) Import(inputPath string) error {
 	}
 	defer file.Close()
 
-	var backup BackupData
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&backup); err != nil {
-		return fmt.Errorf("failed to decode backup: %w", err)
-	}
-
-	log.Printf("Backup version: %s, exported at: %s", backup.Version, backup.ExportedAt)
-
-	// Import in order of dependencies
-	if err := s.importUsers(backup.Users); err != nil {
-		return fmt.Errorf("failed to import users: %w", err)
-	}
+	return s.ImportFromReader(file)
+}
 
-	if err := s.importFamilies(backup.Families); err != nil {
-		return fmt.Errorf("failed to import families: %w", err)
-	}
+// ImportFromReader restores a database from a backup reader (for file uploads).
+// It accepts JSON and JSON Lines backups, optionally gzip or zstd compressed.
+// Backups written by older versions are migrated to the current schema first.
+// Rows that already exist are updated, so incremental backups can be applied
+// on top of a restored full backup.
+func (s *BackupService) ImportFromReader(reader io.Reader) error {
+	log.Println("Starting database import from reader...")
 
-	if err := s.importKids(backup.Kids); err != nil {
-		return fmt.Errorf("failed to import kids: %w", err)
+	r, err := newDecompressedReader(reader)
+	if err != nil {
+		return err
 	}
+	defer r.Close()
 
-	if err := s.importLists(backup.Lists); err != nil {
-		return fmt.Errorf("failed to import lists: %w", err)
+	// JSON Lines backups start with a header line; anything else is a single document
+	decoder := json.NewDecoder(r)
+	var first json.RawMessage
+	if err := decoder.Decode(&first); err != nil {
+		return fmt.Errorf("failed to decode backup: %w", err)
 	}
 
-	if err := s.importWords(backup.Words); err != nil {
-		return fmt.Errorf("failed to import words: %w", err)
+	var header streamHeader
+	if err := json.Unmarshal(first, &header); err == nil && header.Format == streamFormatName {
+		log.Printf("Backup version: %s (schema %d), exported at: %s", header.Version, header.SchemaVersion, header.ExportedAt)
+		err = s.importStream(decoder, header)
+	} else {
+		err = s.importDocument(first)
 	}
-
-	if err := s.importPractices(backup.Practices); err != nil {
-		return fmt.Errorf("failed to import practices: %w", err)
+	if err != nil {
+		return err
 	}
 
 	log.Println("Database import completed successfully")
 	return nil
 }
 
-// ImportFromReader restores a database from a backup reader (for file uploads)
-func (s *BackupService) ImportFromReader(reader io.Reader) error {
-	log.Println("Starting database import from reader...")
-
+// importDocument restores a backup written as a single JSON document
+func (s *BackupService) importDocument(data json.RawMessage) error {
 	var backup BackupData
-	decoder := json.NewDecoder(reader)
-	if err := decoder.Decode(&backup); err != nil {
+	if err := json.Unmarshal(data, &backup); err != nil {
 		return fmt.Errorf("failed to decode backup: %w", err)
 	}
 
-	log.Printf("Backup version: %s, exported at: %s", backup.Version, backup.ExportedAt)
-
-	// Import in order of dependencies
-	if err := s.importUsers(backup.Users); err != nil {
-		return fmt.Errorf("failed to import users: %w", err)
-	}
+	log.Printf("Backup version: %s (schema %d), exported at: %s", backup.Version, backup.SchemaVersion, backup.ExportedAt)
 
-	if err := s.importFamilies(backup.Families); err != nil {
-		return fmt.Errorf("failed to import families: %w", err)
-	}
-
-	if err := s.importKids(backup.Kids); err != nil {
-		return fmt.Errorf("failed to import kids: %w", err)
-	}
-
-	if err := s.importLists(backup.Lists); err != nil {
-		return fmt.Errorf("failed to import lists: %w", err)
-	}
-
-	if err := s.importWords(backup.Words); err != nil {
-		return fmt.Errorf("failed to import words: %w", err)
-	}
-
-	if err := s.importPractices(backup.Practices); err != nil {
-		return fmt.Errorf("failed to import practices: %w", err)
+	if err := migrateBackup(&backup); err != nil {
+		return err
 	}
 
-	log.Println("Database import completed successfully")
-	return nil
+	return s.restoreRows(backup.eachRow)
 }
 
-func (s *BackupService) exportUsers(backup *BackupData) error {
-	query := "SELECT id, email, password_hash, name, COALESCE(oauth_provider, ''), COALESCE(oauth_subject, ''), is_admin, created_at, updated_at FROM users ORDER BY id"
-	rows, err := s.db.Query(query)
+// restoreRows writes the rows produced by each in a single transaction, then
+// moves ID sequences past the restored IDs
+func (s *BackupService) restoreRows(each func(restore func(table string, row interface{}) error) error) error {
+	tx, err := s.db.Begin()
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	for rows.Next() {
-		var u UserBackup
-		if err := rows.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.OAuthProvider, &u.OAuthSubject, &u.IsAdmin, &u.CreatedAt, &u.UpdatedAt); err != nil {
-			return err
+	counts := make(map[string]int)
+	err = each(func(table string, row interface{}) error {
+		t := findBackupTable(table)
+		if t == nil {
+			return fmt.Errorf("backup contains unknown table %q", table)
 		}
-		backup.Users = append(backup.Users, u)
-	}
-	return rows.Err()
-}
-
-func (s *BackupService) exportFamilies(backup *BackupData) error {
-	query := "SELECT family_code, created_at, updated_at FROM families ORDER BY family_code"
-	rows, err := s.db.Query(query)
+		if err := t.restore(tx, row); err != nil {
+			return fmt.Errorf("failed to import %s row: %w", table, err)
+		}
+		counts[table]++
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var f FamilyBackup
-		if err := rows.Scan(&f.FamilyCode, &f.CreatedAt, &f.UpdatedAt); err != nil {
-			return err
+	for _, table := range backupTables {
+		if n := counts[table.tableName()]; n > 0 {
+			log.Printf("Imported %d rows into %s", n, table.tableName())
 		}
-
-		// Get family members
-		memberQuery := "SELECT user_id, role FROM family_members WHERE family_code = ? ORDER BY user_id"
-		memberRows, err := s.db.Query(memberQuery, f.FamilyCode)
-		if err != nil {
-			return err
+		column := table.sequence()
+		if column == "" {
+			continue
 		}
-
-		for memberRows.Next() {
-			var m FamilyMemberBackup
-			if err := memberRows.Scan(&m.UserID, &m.Role); err != nil {
-				memberRows.Close()
-				return err
+		if query := tx.GetDialect().ResetSequenceQuery(table.tableName(), column); query != "" {
+			if _, err := tx.Exec(query); err != nil {
+				return fmt.Errorf("failed to reset %s sequence: %w", table.tableName(), err)
 			}
-			f.Members = append(f.Members, m)
 		}
-		memberRows.Close()
-
-		backup.Families = append(backup.Families, f)
 	}
-	return rows.Err()
-}
 
-func (s *BackupService) exportKids(backup *BackupData) error {
-	query := "SELECT id, family_code, name, username, COALESCE(password, ''), COALESCE(avatar_color, '#4A90E2'), created_at, updated_at FROM kids ORDER BY id"
-	rows, err := s.db.Query(query)
-	if err != nil {
-		return err
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit import: %w", err)
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var k KidBackup
-		if err := rows.Scan(&k.ID, &k.FamilyCode, &k.Name, &k.Username, &k.Password, &k.AvatarColor, &k.CreatedAt, &k.UpdatedAt); err != nil {
-			return err
-		}
-		backup.Kids = append(backup.Kids, k)
-	}
-	return rows.Err()
+	return nil
 }
 
-func (s *BackupService) exportLists(backup *BackupData) error {
-	query := "SELECT id, name, description, family_code, is_public, created_by, created_at, updated_at FROM spelling_lists WHERE is_public = 0 ORDER BY id"
-	rows, err := s.db.Query(query)
-	if err != nil {
-		return err
+// migrateBackup upgrades a decoded backup to BackupSchemaVersion
+func migrateBackup(backup *BackupData) error {
+	// Version 1 backups have no schema_version field
+	if backup.SchemaVersion == 0 {
+		backup.SchemaVersion = 1
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var l ListBackup
-		var familyCode sql.NullString
-		var createdBy sql.NullInt64
-		if err := rows.Scan(&l.ID, &l.Name, &l.Description, &familyCode, &l.IsPublic, &createdBy, &l.CreatedAt, &l.UpdatedAt); err != nil {
-			return err
-		}
-		if familyCode.Valid {
-			l.FamilyCode = &familyCode.String
-		}
-		if createdBy.Valid {
-			l.CreatedBy = &createdBy.Int64
-		}
-
-		// Get assigned kids
-		assignQuery := "SELECT kid_id FROM list_assignments WHERE spelling_list_id = ? ORDER BY kid_id"
-		assignRows, err := s.db.Query(assignQuery, l.ID)
-		if err != nil {
-			return err
-		}
-
-		for assignRows.Next() {
-			var kidID int64
-			if err := assignRows.Scan(&kidID); err != nil {
-				assignRows.Close()
-				return err
-			}
-			l.Assignments = append(l.Assignments, kidID)
-		}
-		assignRows.Close()
-
-		backup.Lists = append(backup.Lists, l)
+	if backup.SchemaVersion > BackupSchemaVersion {
+		return fmt.Errorf("%w (schema version %d, supported up to %d)", ErrUnsupportedBackupVersion, backup.SchemaVersion, BackupSchemaVersion)
 	}
-	return rows.Err()
-}
 
-func (s *BackupService) exportWords(backup *BackupData) error {
-	query := "SELECT w.id, w.spelling_list_id, w.word_text, COALESCE(w.difficulty_level, 1), COALESCE(w.audio_filename, ''), COALESCE(w.definition, ''), COALESCE(w.definition_audio_filename, ''), w.position, w.created_at FROM words w JOIN spelling_lists sl ON w.spelling_list_id = sl.id WHERE sl.is_public = 0 ORDER BY w.id"
-	rows, err := s.db.Query(query)
-	if err != nil {
-		return err
+	if backup.SchemaVersion < 2 {
+		migrateBackupV1(backup)
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var w WordBackup
-		if err := rows.Scan(&w.ID, &w.SpellingListID, &w.WordText, &w.DifficultyLevel, &w.AudioFilename, &w.Definition, &w.DefinitionAudioFilename, &w.Position, &w.CreatedAt); err != nil {
-			return err
-		}
-		backup.Words = append(backup.Words, w)
+	if backup.SchemaVersion < 3 {
+		migrateBackupV2(backup)
 	}
-	return rows.Err()
+
+	return nil
 }
 
-func (s *BackupService) exportPractices(backup *BackupData) error {
-	query := "SELECT ps.id, ps.kid_id, ps.spelling_list_id, ps.started_at, ps.completed_at, ps.total_words, ps.correct_words, ps.points_earned FROM practice_sessions ps JOIN spelling_lists sl ON ps.spelling_list_id = sl.id WHERE sl.is_public = 0 ORDER BY ps.id"
-	rows, err := s.db.Query(query)
-	if err != nil {
-		return err
-	}
-	defer rows.Close()
+// migrateBackupV1 converts a version 1 backup to version 2. Version 1 stored
+// only the assigned kid IDs of each list, and lists taken before lists had a
+// locale have none.
+func migrateBackupV1(backup *BackupData) {
+	for i := range backup.Lists {
+		l := &backup.Lists[i]
+		if l.Locale == "" {
+			l.Locale = models.DefaultLocale
+		}
 
-	for rows.Next() {
-		var p PracticeBackup
-		var completedAt sql.NullTime
-		if err := rows.Scan(&p.ID, &p.KidID, &p.SpellingListID, &p.StartedAt, &completedAt, &p.TotalWords, &p.CorrectWords, &p.PointsEarned); err != nil {
-			return err
+		assignedBy := int64(1) // Default to admin user
+		if l.CreatedBy != nil {
+			assignedBy = *l.CreatedBy
 		}
-		if completedAt.Valid {
-			p.CompletedAt = &completedAt.Time
+		for _, kidID := range l.AssignedKids {
+			l.Assignments = append(l.Assignments, ListAssignmentBackup{
+				KidID:      kidID,
+				AssignedAt: l.CreatedAt,
+				AssignedBy: assignedBy,
+			})
 		}
-		backup.Practices = append(backup.Practices, p)
+		l.AssignedKids = nil
 	}
-	return rows.Err()
+	backup.SchemaVersion = 2
 }
 
-func (s *BackupService) importUsers(users []UserBackup) error {
-	log.Printf("Importing %d users...", len(users))
-	for _, u := range users {
-		query := "INSERT INTO users (id, email, password_hash, name, oauth_provider, oauth_subject, is_admin, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
-		_, err := s.db.Exec(query, u.ID, u.Email, u.PasswordHash, u.Name, nullIfEmpty(u.OAuthProvider), nullIfEmpty(u.OAuthSubject), u.IsAdmin, u.CreatedAt, u.UpdatedAt)
-		if err != nil {
-			return fmt.Errorf("failed to import user %d: %w", u.ID, err)
+// migrateBackupV2 converts a version 2 backup to version 3, moving each user's
+// sign-in provider to a linked identity
+func migrateBackupV2(backup *BackupData) {
+	for i := range backup.Users {
+		if identity, ok := legacyUserIdentity(&backup.Users[i]); ok {
+			backup.UserIdentities = append(backup.UserIdentities, identity)
 		}
 	}
-	return nil
+	backup.SchemaVersion = 3
 }
 
-func (s *BackupService) importFamilies(families []FamilyBackup) error {
-	log.Printf("Importing %d families...", len(families))
-	for _, f := range families {
-		query := "INSERT INTO families (family_code, created_at, updated_at) VALUES (?, ?, ?)"
-		_, err := s.db.Exec(query, f.FamilyCode, f.CreatedAt, f.UpdatedAt)
-		if err != nil {
-			return fmt.Errorf("failed to import family %s: %w", f.FamilyCode, err)
-		}
+// legacyOAuthLinkWindow is how soon after an account was created its provider
+// must have been linked for it to count as created by signing in with it
+const legacyOAuthLinkWindow = time.Minute
+
+// legacyUserIdentity takes the sign-in provider off a user from a version 2 or
+// older backup. Users had at most one, so the identity borrows the user's ID.
+// Users created by signing in with a provider were given a random password
+// nobody knows, which is cleared so they count as having none, as migration 024
+// does for the database.
+func legacyUserIdentity(u *UserBackup) (UserIdentityBackup, bool) {
+	provider, subject := u.OAuthProvider, u.OAuthSubject
+	u.OAuthProvider, u.OAuthSubject = "", ""
+	if provider == "" || subject == "" {
+		return UserIdentityBackup{}, false
+	}
+	if !u.UpdatedAt.After(u.CreatedAt.Add(legacyOAuthLinkWindow)) {
+		u.PasswordHash = ""
+	}
+	return UserIdentityBackup{
+		ID:        u.ID,
+		UserID:    u.ID,
+		Provider:  provider,
+		Subject:   subject,
+		Email:     u.Email,
+		CreatedAt: u.UpdatedAt,
+	}, true
+}
 
-		// Import family members
-		for _, m := range f.Members {
-			memberQuery := "INSERT INTO family_members (family_code, user_id, role) VALUES (?, ?, ?)"
-			_, err := s.db.Exec(memberQuery, f.FamilyCode, m.UserID, m.Role)
-			if err != nil {
-				return fmt.Errorf("failed to import family member %d for family %s: %w", m.UserID, f.FamilyCode, err)
-			}
-		}
-	}
-	return nil
+// backupBuilder collects exported rows into a BackupData document, nesting
+// family members and list assignments under their family and list
+type backupBuilder struct {
+	data     *BackupData
+	families map[string]int
+	lists    map[int64]int
 }
 
-func (s *BackupService) importKids(kids []KidBackup) error {
-	log.Printf("Importing %d kids...", len(kids))
-	for _, k := range kids {
-		query := "INSERT INTO kids (id, family_code, name, username, password, avatar_color, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
-		_, err := s.db.Exec(query, k.ID, k.FamilyCode, k.Name, k.Username, nullIfEmpty(k.Password), k.AvatarColor, k.CreatedAt, k.UpdatedAt)
-		if err != nil {
-			return fmt.Errorf("failed to import kid %d: %w", k.ID, err)
-		}
+func newBackupBuilder(since *time.Time) *backupBuilder {
+	return &backupBuilder{
+		data: &BackupData{
+			Version:       backupVersion,
+			SchemaVersion: BackupSchemaVersion,
+			ExportedAt:    time.Now(),
+			DatabaseType:  "universal",
+			Since:         since,
+		},
+		families: make(map[string]int),
+		lists:    make(map[int64]int),
 	}
-	return nil
 }
 
-func (s *BackupService) importLists(lists []ListBackup) error {
-	log.Printf("Importing %d lists...", len(lists))
-	for _, l := range lists {
-		var familyCode interface{} = nil
-		if l.FamilyCode != nil {
-			familyCode = *l.FamilyCode
+func (b *backupBuilder) add(table string, row interface{}) {
+	d := b.data
+	switch r := row.(type) {
+	case UserBackup:
+		d.Users = append(d.Users, r)
+	case UserIdentityBackup:
+		d.UserIdentities = append(d.UserIdentities, r)
+	case TwoFactorBackup:
+		d.TwoFactors = append(d.TwoFactors, r)
+	case RecoveryCodeBackup:
+		d.RecoveryCodes = append(d.RecoveryCodes, r)
+	case FamilyBackup:
+		b.families[r.FamilyCode] = len(d.Families)
+		d.Families = append(d.Families, r)
+	case FamilyMemberBackup:
+		// Incremental backups can hold members of families that did not change
+		if i, ok := b.families[r.FamilyCode]; ok {
+			r.FamilyCode = ""
+			d.Families[i].Members = append(d.Families[i].Members, r)
+		} else {
+			d.FamilyMembers = append(d.FamilyMembers, r)
 		}
-		var createdBy interface{} = nil
-		if l.CreatedBy != nil {
-			createdBy = *l.CreatedBy
+	case StreakSettingsBackup:
+		d.StreakSettings = append(d.StreakSettings, r)
+	case StreakFreezeBackup:
+		d.StreakFreezes = append(d.StreakFreezes, r)
+	case KidBackup:
+		d.Kids = append(d.Kids, r)
+	case TeacherKidBackup:
+		d.TeacherKids = append(d.TeacherKids, r)
+	case ListBackup:
+		b.lists[r.ID] = len(d.Lists)
+		d.Lists = append(d.Lists, r)
+	case ListAssignmentBackup:
+		if i, ok := b.lists[r.SpellingListID]; ok {
+			r.SpellingListID = 0
+			d.Lists[i].Assignments = append(d.Lists[i].Assignments, r)
+		} else {
+			d.ListAssignments = append(d.ListAssignments, r)
 		}
-		query := "INSERT INTO spelling_lists (id, name, description, family_code, is_public, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
-		_, err := s.db.Exec(query, l.ID, l.Name, l.Description, familyCode, l.IsPublic, createdBy, l.CreatedAt, l.UpdatedAt)
-		if err != nil {
-			return fmt.Errorf("failed to import list %d: %w", l.ID, err)
+	case TeacherClassBackup:
+		d.TeacherClasses = append(d.TeacherClasses, r)
+	case TeacherClassMemberBackup:
+		d.TeacherClassMembers = append(d.TeacherClassMembers, r)
+	case TeacherClassListBackup:
+		d.TeacherClassLists = append(d.TeacherClassLists, r)
+	case WordBackup:
+		d.Words = append(d.Words, r)
+	case WordScheduleBackup:
+		d.WordSchedules = append(d.WordSchedules, r)
+	case AchievementBackup:
+		d.Achievements = append(d.Achievements, r)
+	case LeaderboardSettingsBackup:
+		d.LeaderboardSettings = append(d.LeaderboardSettings, r)
+	case ClashMatchBackup:
+		d.ClashMatches = append(d.ClashMatches, r)
+	case SpellingBeeBackup:
+		d.SpellingBees = append(d.SpellingBees, r)
+	case SpellingBeeKidBackup:
+		d.SpellingBeeKids = append(d.SpellingBeeKids, r)
+	case SpellingBeeTurnBackup:
+		d.SpellingBeeTurns = append(d.SpellingBeeTurns, r)
+	case PracticeBackup:
+		d.Practices = append(d.Practices, r)
+	case WordAttemptBackup:
+		d.WordAttempts = append(d.WordAttempts, r)
+	case PracticeStateBackup:
+		d.PracticeStates = append(d.PracticeStates, r)
+	case PracticeWordTimingBackup:
+		d.PracticeWordTimings = append(d.PracticeWordTimings, r)
+	case GameSessionBackup:
+		if table == "hangman_sessions" {
+			d.HangmanSessions = append(d.HangmanSessions, r)
+		} else {
+			d.MissingLetterSessions = append(d.MissingLetterSessions, r)
 		}
-
-		// Import assignments
-		for _, kidID := range l.Assignments {
-			assignQuery := "INSERT INTO list_assignments (spelling_list_id, kid_id, assigned_by) VALUES (?, ?, ?)"
-			assignedBy := 1 // Default to admin user
-			if l.CreatedBy != nil {
-				assignedBy = int(*l.CreatedBy)
-			}
-			_, err := s.db.Exec(assignQuery, l.ID, kidID, assignedBy)
-			if err != nil {
-				return fmt.Errorf("failed to import assignment for list %d, kid %d: %w", l.ID, kidID, err)
-			}
+	case HangmanGameBackup:
+		d.HangmanGames = append(d.HangmanGames, r)
+	case MissingLetterGameBackup:
+		d.MissingLetterGames = append(d.MissingLetterGames, r)
+	case GameStateBackup:
+		if table == "hangman_state" {
+			d.HangmanStates = append(d.HangmanStates, r)
+		} else {
+			d.MissingLetterStates = append(d.MissingLetterStates, r)
 		}
+	case SpellingTestBackup:
+		d.SpellingTests = append(d.SpellingTests, r)
+	case SpellingTestAttemptBackup:
+		d.SpellingTestAttempts = append(d.SpellingTestAttempts, r)
+	case SpellingTestAnswerBackup:
+		d.SpellingTestAnswers = append(d.SpellingTestAnswers, r)
+	case InvitationBackup:
+		d.Invitations = append(d.Invitations, r)
+	case DigestSubscriptionBackup:
+		d.DigestSubscriptions = append(d.DigestSubscriptions, r)
+	case SettingBackup:
+		d.Settings = append(d.Settings, r)
 	}
-	return nil
 }
 
-func (s *BackupService) importWords(words []WordBackup) error {
-	log.Printf("Importing %d words...", len(words))
-	for _, w := range words {
-		query := "INSERT INTO words (id, spelling_list_id, word_text, difficulty_level, audio_filename, definition, definition_audio_filename, position, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
-		_, err := s.db.Exec(query, w.ID, w.SpellingListID, w.WordText, w.DifficultyLevel, nullIfEmpty(w.AudioFilename), nullIfEmpty(w.Definition), nullIfEmpty(w.DefinitionAudioFilename), w.Position, w.CreatedAt)
-		if err != nil {
-			return fmt.Errorf("failed to import word %d: %w", w.ID, err)
+// eachRow calls restore for every row in the backup, in the order of backupTables
+func (d *BackupData) eachRow(restore func(table string, row interface{}) error) error {
+	families := make([]FamilyBackup, len(d.Families))
+	members := append([]FamilyMemberBackup(nil), d.FamilyMembers...)
+	for i, f := range d.Families {
+		for _, m := range f.Members {
+			m.FamilyCode = f.FamilyCode
+			members = append(members, m)
 		}
+		f.Members = nil
+		families[i] = f
 	}
-	return nil
-}
 
-func (s *BackupService) importPractices(practices []PracticeBackup) error {
-	log.Printf("Importing %d practice sessions...", len(practices))
-	for _, p := range practices {
-		var completedAt interface{} = nil
-		if p.CompletedAt != nil {
-			completedAt = *p.CompletedAt
+	lists := make([]ListBackup, len(d.Lists))
+	assignments := append([]ListAssignmentBackup(nil), d.ListAssignments...)
+	for i, l := range d.Lists {
+		for _, a := range l.Assignments {
+			a.SpellingListID = l.ID
+			assignments = append(assignments, a)
 		}
-		query := "INSERT INTO practice_sessions (id, kid_id, spelling_list_id, started_at, completed_at, total_words, correct_words, points_earned) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
-		_, err := s.db.Exec(query, p.ID, p.KidID, p.SpellingListID, p.StartedAt, completedAt, p.TotalWords, p.CorrectWords, p.PointsEarned)
-		if err != nil {
-			return fmt.Errorf("failed to import practice %d: %w", p.ID, err)
+		l.Assignments = nil
+		lists[i] = l
+	}
+
+	steps := []func() error{
+		func() error { return restoreEach(restore, "users", d.Users) },
+		func() error { return restoreEach(restore, "user_identities", d.UserIdentities) },
+		func() error { return restoreEach(restore, "user_two_factor", d.TwoFactors) },
+		func() error { return restoreEach(restore, "two_factor_recovery_codes", d.RecoveryCodes) },
+		func() error { return restoreEach(restore, "families", families) },
+		func() error { return restoreEach(restore, "family_members", members) },
+		func() error { return restoreEach(restore, "streak_settings", d.StreakSettings) },
+		func() error { return restoreEach(restore, "streak_freezes", d.StreakFreezes) },
+		func() error { return restoreEach(restore, "kids", d.Kids) },
+		func() error { return restoreEach(restore, "teacher_kid_relationships", d.TeacherKids) },
+		func() error { return restoreEach(restore, "spelling_lists", lists) },
+		func() error { return restoreEach(restore, "list_assignments", assignments) },
+		func() error { return restoreEach(restore, "teacher_classes", d.TeacherClasses) },
+		func() error { return restoreEach(restore, "teacher_class_members", d.TeacherClassMembers) },
+		func() error { return restoreEach(restore, "teacher_class_lists", d.TeacherClassLists) },
+		func() error { return restoreEach(restore, "words", d.Words) },
+		func() error { return restoreEach(restore, "word_schedules", d.WordSchedules) },
+		func() error { return restoreEach(restore, "achievements", d.Achievements) },
+		func() error { return restoreEach(restore, "leaderboard_settings", d.LeaderboardSettings) },
+		func() error { return restoreEach(restore, "clash_matches", d.ClashMatches) },
+		func() error { return restoreEach(restore, "spelling_bees", d.SpellingBees) },
+		func() error { return restoreEach(restore, "spelling_bee_participants", d.SpellingBeeKids) },
+		func() error { return restoreEach(restore, "spelling_bee_turns", d.SpellingBeeTurns) },
+		func() error { return restoreEach(restore, "practice_sessions", d.Practices) },
+		func() error { return restoreEach(restore, "word_attempts", d.WordAttempts) },
+		func() error { return restoreEach(restore, "practice_state", d.PracticeStates) },
+		func() error { return restoreEach(restore, "practice_word_timing", d.PracticeWordTimings) },
+		func() error { return restoreEach(restore, "hangman_sessions", d.HangmanSessions) },
+		func() error { return restoreEach(restore, "hangman_games", d.HangmanGames) },
+		func() error { return restoreEach(restore, "hangman_state", d.HangmanStates) },
+		func() error { return restoreEach(restore, "missing_letter_sessions", d.MissingLetterSessions) },
+		func() error { return restoreEach(restore, "missing_letter_games", d.MissingLetterGames) },
+		func() error { return restoreEach(restore, "missing_letter_state", d.MissingLetterStates) },
+		func() error { return restoreEach(restore, "spelling_tests", d.SpellingTests) },
+		func() error { return restoreEach(restore, "spelling_test_attempts", d.SpellingTestAttempts) },
+		func() error { return restoreEach(restore, "spelling_test_answers", d.SpellingTestAnswers) },
+		func() error { return restoreEach(restore, "invitations", d.Invitations) },
+		func() error { return restoreEach(restore, "digest_subscriptions", d.DigestSubscriptions) },
+		func() error { return restoreEach(restore, "settings", d.Settings) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
-// ExportToWriter exports the database to an io.Writer (useful for HTTP responses)
-func (s *BackupService) ExportToWriter(w io.Writer) error {
-	backup := &BackupData{
-		Version:      "1.0",
-		ExportedAt:   time.Now(),
-		DatabaseType: "universal",
-	}
-
-	if err := s.exportUsers(backup); err != nil {
-		return fmt.Errorf("failed to export users: %w", err)
-	}
-	if err := s.exportFamilies(backup); err != nil {
-		return fmt.Errorf("failed to export families: %w", err)
-	}
-	if err := s.exportKids(backup); err != nil {
-		return fmt.Errorf("failed to export kids: %w", err)
-	}
-	if err := s.exportLists(backup); err != nil {
-		return fmt.Errorf("failed to export lists: %w", err)
-	}
-	if err := s.exportWords(backup); err != nil {
-		return fmt.Errorf("failed to export words: %w", err)
-	}
-	if err := s.exportPractices(backup); err != nil {
-		return fmt.Errorf("failed to export practices: %w", err)
+func restoreEach[T any](restore func(table string, row interface{}) error, table string, rows []T) error {
+	for _, row := range rows {
+		if err := restore(table, row); err != nil {
+			return err
+		}
 	}
-
-	encoder := json.NewEncoder(w)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(backup)
+	return nil
 }
 
 func nullIfEmpty(s string) interface{} {
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"spellingclash/internal/database"
+	"spellingclash/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMigrateBackupV1(t *testing.T) {
+	createdBy := int64(7)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	backup := &BackupData{
+		Version: "1.0",
+		Lists: []ListBackup{
+			{ID: 1, CreatedBy: &createdBy, CreatedAt: created, AssignedKids: []int64{3, 4}},
+			{ID: 2, Locale: "en-US", AssignedKids: []int64{5}},
+		},
+	}
+
+	if err := migrateBackup(backup); err != nil {
+		t.Fatalf("migrateBackup() error: %v", err)
+	}
+
+	if backup.SchemaVersion != BackupSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", backup.SchemaVersion, BackupSchemaVersion)
+	}
+
+	first := backup.Lists[0]
+	if first.Locale != models.DefaultLocale {
+		t.Errorf("Locale = %q, want %q", first.Locale, models.DefaultLocale)
+	}
+	if len(first.Assignments) != 2 || first.AssignedKids != nil {
+		t.Fatalf("expected assigned kids to be converted, got %+v", first)
+	}
+	if a := first.Assignments[1]; a.KidID != 4 || a.AssignedBy != 7 || !a.AssignedAt.Equal(created) {
+		t.Errorf("unexpected assignment %+v", a)
+	}
+
+	second := backup.Lists[1]
+	if second.Locale != "en-US" {
+		t.Errorf("Locale = %q, want en-US", second.Locale)
+	}
+	if len(second.Assignments) != 1 || second.Assignments[0].AssignedBy != 1 {
+		t.Errorf("expected assignment by the admin user, got %+v", second.Assignments)
+	}
+}
+
+func TestMigrateBackupV2(t *testing.T) {
+	created := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	backup := &BackupData{
+		SchemaVersion: 2,
+		Users: []UserBackup{
+			{ID: 1, Email: "parent@example.com", PasswordHash: "hash"},
+			{ID: 2, Email: "google@example.com", PasswordHash: "random", OAuthProvider: "google", OAuthSubject: "g-1", CreatedAt: created, UpdatedAt: created},
+			{ID: 3, Email: "linked@example.com", PasswordHash: "hash", OAuthProvider: "facebook", OAuthSubject: "f-1", CreatedAt: created, UpdatedAt: created.Add(time.Hour)},
+		},
+	}
+
+	if err := migrateBackup(backup); err != nil {
+		t.Fatalf("migrateBackup() error: %v", err)
+	}
+
+	if len(backup.UserIdentities) != 2 {
+		t.Fatalf("UserIdentities = %+v, want the Google and Facebook users'", backup.UserIdentities)
+	}
+	if identity := backup.UserIdentities[0]; identity.UserID != 2 || identity.Provider != "google" || identity.Subject != "g-1" {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+	if backup.Users[1].OAuthProvider != "" || backup.Users[1].OAuthSubject != "" {
+		t.Errorf("user still has its provider: %+v", backup.Users[1])
+	}
+
+	// Only the user created by signing in with a provider loses its unknown password
+	for i, want := range []string{"hash", "", "hash"} {
+		if got := backup.Users[i].PasswordHash; got != want {
+			t.Errorf("user %d password hash = %q, want %q", backup.Users[i].ID, got, want)
+		}
+	}
+}
+
+func TestMigrateBackupRejectsNewerVersion(t *testing.T) {
+	backup := &BackupData{SchemaVersion: BackupSchemaVersion + 1}
+	if err := migrateBackup(backup); !errors.Is(err, ErrUnsupportedBackupVersion) {
+		t.Errorf("migrateBackup() error = %v, want ErrUnsupportedBackupVersion", err)
+	}
+}
+
+// seedBackupTestDB inserts a row into every backed up table, using now for timestamps
+func seedBackupTestDB(t *testing.T, db *database.DB, now time.Time) {
+	t.Helper()
+
+	seed := []string{
+		"INSERT INTO users (id, email, password_hash, name, is_teacher) VALUES (1, 'parent@example.com', 'x', 'Parent', 0), (2, 'teacher@example.com', 'x', 'Teacher', 1)",
+		"INSERT INTO user_identities (id, user_id, provider, subject, email, created_at, last_used_at) VALUES (1, 1, 'google', 'g-1', 'parent@example.com', ?, ?)",
+		"INSERT INTO user_two_factor (user_id, secret, enabled_at, last_used_step, created_at) VALUES (1, 'JBSWY3DPEHPK3PXP', ?, 100, ?)",
+		"INSERT INTO two_factor_recovery_codes (id, user_id, code_hash, used_at, created_at) VALUES (1, 1, 'hash', ?, ?), (2, 1, 'hash2', NULL, ?)",
+		"INSERT INTO families (family_code) VALUES ('FAM1')",
+		"INSERT INTO family_members (family_code, user_id, role) VALUES ('FAM1', 1, 'parent')",
+		"INSERT INTO streak_settings (family_code, timezone, weekends_off, updated_at) VALUES ('FAM1', 'Europe/London', 1, ?)",
+		"INSERT INTO streak_freezes (id, family_code, name, start_date, end_date, created_at) VALUES (1, 'FAM1', 'Half term', '2026-10-26', '2026-10-30', ?)",
+		"INSERT INTO kids (id, family_code, name, username) VALUES (1, 'FAM1', 'Kid', 'kid1'), (2, 'FAM1', 'Sibling', 'kid2')",
+		"INSERT INTO teacher_kid_relationships (id, teacher_user_id, kid_id) VALUES (1, 2, 1)",
+		"INSERT INTO spelling_lists (id, name, description, family_code, is_public, locale, created_by) VALUES (1, 'Week 1', '', 'FAM1', 0, 'en-US', 1), (2, 'Public', '', NULL, 1, 'en-GB', NULL)",
+		"INSERT INTO words (id, spelling_list_id, word_text, position) VALUES (1, 1, 'cat', 0), (2, 2, 'dog', 0)",
+		"INSERT INTO list_assignments (spelling_list_id, kid_id, assigned_by, managed_by_teacher, due_date) VALUES (1, 1, 2, 1, ?)",
+		"INSERT INTO teacher_classes (id, teacher_user_id, name, archived_at) VALUES (1, 2, 'Year 4 Blue', ?)",
+		"INSERT INTO teacher_class_members (id, class_id, kid_id) VALUES (1, 1, 1)",
+		"INSERT INTO teacher_class_lists (id, class_id, spelling_list_id, due_date) VALUES (1, 1, 1, ?)",
+		"INSERT INTO word_schedules (kid_id, word_id, repetitions, interval_days, ease_factor, lapses, due_at) VALUES (1, 1, 2, 6, 2.6, 0, ?)",
+		"INSERT INTO achievements (id, kid_id, achievement_key, unlocked_at, seen_at) VALUES (1, 1, 'perfect_session', ?, ?), (2, 1, 'week_streak', ?, NULL)",
+		"INSERT INTO leaderboard_settings (kid_id, nickname, hidden_by_parent, hidden_by_teacher, updated_at) VALUES (1, 'Speedy', 0, 1, ?)",
+		"INSERT INTO clash_matches (id, spelling_list_id, challenger_kid_id, opponent_kid_id, status, total_rounds, challenger_score, opponent_score, winner_kid_id, created_at, started_at, finished_at) VALUES (1, 1, 1, 2, 'finished', 2, 2, 1, 1, ?, ?, ?), (2, 1, 2, 1, 'cancelled', 0, 0, 0, NULL, ?, NULL, ?)",
+		"INSERT INTO spelling_bees (id, teacher_user_id, spelling_list_id, max_misses, answer_mode, status, version, created_at, updated_at, finished_at) VALUES (1, 2, 1, 1, 'devices', 'finished', 4, ?, ?, ?)",
+		"INSERT INTO spelling_bee_participants (id, bee_id, kid_id, turn_order, words_correct, misses, eliminated_turn, final_place, updated_at) VALUES (1, 1, 1, 1, 1, 0, NULL, 1, ?), (2, 1, 2, 2, 0, 1, 2, 2, ?)",
+		"INSERT INTO spelling_bee_turns (id, bee_id, turn_number, kid_id, word_id, word_text, answer, is_correct, asked_at, answered_at) VALUES (1, 1, 1, 1, 1, 'cat', 'cat', 1, ?, ?), (2, 1, 2, 2, NULL, 'dog', NULL, 0, ?, ?)",
+		"INSERT INTO practice_sessions (id, kid_id, spelling_list_id, started_at) VALUES (1, 1, 2, ?)",
+		"INSERT INTO word_attempts (id, practice_session_id, word_id, attempt_text, is_correct, time_taken_ms, points_earned, attempted_at) VALUES (1, 1, 2, 'dog', 1, 1200, 10, ?)",
+		"INSERT INTO hangman_sessions (id, kid_id, spelling_list_id, started_at, total_games) VALUES (1, 1, 1, ?, 1)",
+		"INSERT INTO hangman_games (id, session_id, kid_id, word_id, word, guessed_letters, is_won, started_at) VALUES (1, 1, 1, 1, 'cat', '[\"c\"]', 1, ?)",
+		"INSERT INTO missing_letter_sessions (id, kid_id, spelling_list_id, started_at, total_games) VALUES (1, 1, 1, ?, 1)",
+		"INSERT INTO missing_letter_games (id, session_id, kid_id, word_id, word, missing_indices, guessed_letters, started_at) VALUES (1, 1, 1, 1, 'cat', '[1]', '[]', ?)",
+		"INSERT INTO missing_letter_state (kid_id, session_id, words_json) VALUES (1, 1, '[1]')",
+		"INSERT INTO spelling_tests (id, class_id, spelling_list_id, created_by, opens_at, closes_at, created_at) VALUES (1, 1, 1, 2, ?, ?, ?)",
+		"INSERT INTO spelling_test_attempts (id, test_id, kid_id, total_words, correct_words, started_at) VALUES (1, 1, 1, 1, 1, ?)",
+		"INSERT INTO spelling_test_answers (id, attempt_id, word_id, word_text, answer, is_correct, answered_at) VALUES (1, 1, 1, 'cat', 'cat', 1, ?)",
+		"INSERT INTO invitations (id, code, email, invited_by, expires_at) VALUES (1, 'abc', 'new@example.com', 1, ?)",
+		"INSERT INTO digest_subscriptions (user_id, enabled, last_sent_at) VALUES (1, 1, ?)",
+		"UPDATE settings SET value = 'true' WHERE key = 'invite_only_mode'",
+	}
+	for _, query := range seed {
+		var args []interface{}
+		for range strings.Count(query, "?") {
+			args = append(args, now)
+		}
+		if _, err := db.Exec(query, args...); err != nil {
+			t.Fatalf("failed to seed %q: %v", query, err)
+		}
+	}
+}
+
+// backupTestTables are checked after a restore
+var backupTestTables = []string{
+	"users", "user_identities", "user_two_factor", "two_factor_recovery_codes", "family_members", "streak_settings", "streak_freezes", "kids", "teacher_kid_relationships", "spelling_lists", "words",
+	"list_assignments", "teacher_classes", "teacher_class_members", "teacher_class_lists", "word_schedules", "achievements", "leaderboard_settings", "clash_matches",
+	"spelling_bees", "spelling_bee_participants", "spelling_bee_turns", "practice_sessions", "word_attempts", "hangman_sessions",
+	"hangman_games", "missing_letter_sessions", "missing_letter_games", "missing_letter_state",
+	"spelling_tests", "spelling_test_attempts", "spelling_test_answers", "invitations",
+	"digest_subscriptions",
+}
+
+// assertSameRowCounts checks that dst has as many rows as src in every backupTestTables table
+func assertSameRowCounts(t *testing.T, src, dst *database.DB) {
+	t.Helper()
+	for _, table := range backupTestTables {
+		var want, got int
+		if err := src.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&want); err != nil {
+			t.Fatalf("failed to count %s: %v", table, err)
+		}
+		if err := dst.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&got); err != nil {
+			t.Fatalf("failed to count %s: %v", table, err)
+		}
+		if got != want || want == 0 {
+			t.Errorf("%s: imported %d rows, want %d", table, got, want)
+		}
+	}
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	src := newTestDB(t)
+	seedBackupTestDB(t, src, time.Now().UTC().Truncate(time.Second))
+
+	var buf bytes.Buffer
+	if err := NewBackupService(src).ExportToWriter(&buf); err != nil {
+		t.Fatalf("ExportToWriter() error: %v", err)
+	}
+
+	dst := newTestDB(t)
+	if err := NewBackupService(dst).ImportFromReader(&buf); err != nil {
+		t.Fatalf("ImportFromReader() error: %v", err)
+	}
+
+	assertSameRowCounts(t, src, dst)
+
+	var managed bool
+	var locale string
+	if err := dst.QueryRow("SELECT la.managed_by_teacher, sl.locale FROM list_assignments la JOIN spelling_lists sl ON sl.id = la.spelling_list_id").Scan(&managed, &locale); err != nil {
+		t.Fatalf("failed to read assignment: %v", err)
+	}
+	if !managed || locale != "en-US" {
+		t.Errorf("assignment managed=%v locale=%q, want true and en-US", managed, locale)
+	}
+
+	var inviteOnly string
+	if err := dst.QueryRow("SELECT value FROM settings WHERE key = 'invite_only_mode'").Scan(&inviteOnly); err != nil {
+		t.Fatalf("failed to read setting: %v", err)
+	}
+	if inviteOnly != "true" {
+		t.Errorf("invite_only_mode = %q, want true", inviteOnly)
+	}
+}
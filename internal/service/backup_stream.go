@@ -0,0 +1,237 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// BackupFormat is the file layout of a backup
+type BackupFormat string
+
+const (
+	// BackupFormatJSON is a single JSON document holding every table
+	BackupFormatJSON BackupFormat = "json"
+	// BackupFormatJSONL is a header line followed by one line per table row,
+	// written and read without holding the whole backup in memory
+	BackupFormatJSONL BackupFormat = "jsonl"
+)
+
+// BackupCompression is the compression applied to a backup file
+type BackupCompression string
+
+const (
+	BackupCompressionNone BackupCompression = "none"
+	BackupCompressionGzip BackupCompression = "gzip"
+	BackupCompressionZstd BackupCompression = "zstd"
+)
+
+// streamFormatName identifies the header line of a JSON Lines backup
+const streamFormatName = "spellingclash-backup"
+
+var (
+	ErrUnknownBackupFormat      = errors.New("unknown backup format")
+	ErrUnknownBackupCompression = errors.New("unknown backup compression")
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ExportOptions controls how a backup is written
+type ExportOptions struct {
+	Format      BackupFormat
+	Compression BackupCompression
+	// Since limits the backup to rows created or changed at or after this time
+	Since *time.Time
+}
+
+// streamHeader is the first line of a JSON Lines backup
+type streamHeader struct {
+	Format        string     `json:"format"`
+	Version       string     `json:"version"`
+	SchemaVersion int        `json:"schema_version"`
+	ExportedAt    time.Time  `json:"exported_at"`
+	Since         *time.Time `json:"since,omitempty"`
+}
+
+// streamRecord is one table row in a JSON Lines backup
+type streamRecord struct {
+	Table string          `json:"table"`
+	Row   json.RawMessage `json:"row"`
+}
+
+// BackupFileExtension returns the file extension for a format and compression, e.g. ".jsonl.gz"
+func BackupFileExtension(format BackupFormat, compression BackupCompression) string {
+	ext := "." + string(format)
+	switch compression {
+	case BackupCompressionGzip:
+		ext += ".gz"
+	case BackupCompressionZstd:
+		ext += ".zst"
+	}
+	return ext
+}
+
+// ExportWithOptions writes a backup to w in the requested format and compression
+func (s *BackupService) ExportWithOptions(w io.Writer, opts ExportOptions) error {
+	cw, err := newCompressedWriter(w, opts.Compression)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case BackupFormatJSON, "":
+		err = s.exportDocument(cw, opts.Since)
+	case BackupFormatJSONL:
+		err = s.exportStream(cw, opts.Since)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnknownBackupFormat, opts.Format)
+	}
+	if err != nil {
+		cw.Close()
+		return err
+	}
+
+	if err := cw.Close(); err != nil {
+		return fmt.Errorf("failed to finish compressed backup: %w", err)
+	}
+	return nil
+}
+
+// exportStream writes each table row as it is read, so memory use does not grow with the database
+func (s *BackupService) exportStream(w io.Writer, since *time.Time) error {
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+
+	header := streamHeader{
+		Format:        streamFormatName,
+		Version:       backupVersion,
+		SchemaVersion: BackupSchemaVersion,
+		ExportedAt:    time.Now(),
+		Since:         since,
+	}
+	if err := encoder.Encode(header); err != nil {
+		return fmt.Errorf("failed to write backup header: %w", err)
+	}
+
+	for _, table := range backupTables {
+		count := 0
+		err := table.export(s.db, since, func(row interface{}) error {
+			data, err := json.Marshal(row)
+			if err != nil {
+				return err
+			}
+			count++
+			return encoder.Encode(streamRecord{Table: table.tableName(), Row: data})
+		})
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", table.tableName(), err)
+		}
+		log.Printf("Exported %d rows from %s", count, table.tableName())
+	}
+
+	return bw.Flush()
+}
+
+// importStream restores the rows of a JSON Lines backup whose header has already been read
+func (s *BackupService) importStream(decoder *json.Decoder, header streamHeader) error {
+	if header.SchemaVersion > BackupSchemaVersion {
+		return fmt.Errorf("%w (schema version %d, supported up to %d)", ErrUnsupportedBackupVersion, header.SchemaVersion, BackupSchemaVersion)
+	}
+	if header.Since != nil {
+		log.Printf("Importing incremental backup of changes since %s", header.Since.Format(time.RFC3339))
+	}
+
+	return s.restoreRows(func(restore func(table string, row interface{}) error) error {
+		for {
+			var record streamRecord
+			if err := decoder.Decode(&record); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("failed to decode backup record: %w", err)
+			}
+
+			table := findBackupTable(record.Table)
+			if table == nil {
+				return fmt.Errorf("backup contains unknown table %q", record.Table)
+			}
+			row, err := table.decode(record.Row)
+			if err != nil {
+				return fmt.Errorf("failed to decode %s row: %w", record.Table, err)
+			}
+			var identity *UserIdentityBackup
+			if user, ok := row.(UserBackup); ok && header.SchemaVersion < 3 {
+				if legacy, ok := legacyUserIdentity(&user); ok {
+					identity = &legacy
+				}
+				row = user
+			}
+
+			if err := restore(record.Table, row); err != nil {
+				return err
+			}
+			if identity != nil {
+				if err := restore("user_identities", *identity); err != nil {
+					return err
+				}
+			}
+		}
+	})
+}
+
+// newCompressedWriter wraps w with the requested compression. Closing the
+// returned writer finishes the compressed stream but does not close w.
+func newCompressedWriter(w io.Writer, compression BackupCompression) (io.WriteCloser, error) {
+	switch compression {
+	case BackupCompressionNone, "":
+		return nopWriteCloser{w}, nil
+	case BackupCompressionGzip:
+		return gzip.NewWriter(w), nil
+	case BackupCompressionZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBackupCompression, compression)
+	}
+}
+
+// newDecompressedReader detects gzip or zstd compression from the first bytes
+// of r and returns a reader for the uncompressed backup
+func newDecompressedReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(zstdMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip backup: %w", err)
+		}
+		return gz, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read zstd backup: %w", err)
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestStreamBackupRoundTrip(t *testing.T) {
+	src := newTestDB(t)
+	seedBackupTestDB(t, src, time.Now().UTC().Truncate(time.Second))
+
+	tests := []struct {
+		compression BackupCompression
+		magic       []byte
+	}{
+		{compression: BackupCompressionNone, magic: []byte(`{"format"`)},
+		{compression: BackupCompressionGzip, magic: gzipMagic},
+		{compression: BackupCompressionZstd, magic: zstdMagic},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.compression), func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := ExportOptions{Format: BackupFormatJSONL, Compression: tt.compression}
+			if err := NewBackupService(src).ExportWithOptions(&buf, opts); err != nil {
+				t.Fatalf("ExportWithOptions() error: %v", err)
+			}
+			if !bytes.HasPrefix(buf.Bytes(), tt.magic) {
+				t.Errorf("backup starts with %x, want %x", buf.Bytes()[:len(tt.magic)], tt.magic)
+			}
+
+			dst := newTestDB(t)
+			if err := NewBackupService(dst).ImportFromReader(&buf); err != nil {
+				t.Fatalf("ImportFromReader() error: %v", err)
+			}
+			assertSameRowCounts(t, src, dst)
+		})
+	}
+}
+
+func TestIncrementalBackup(t *testing.T) {
+	src := newTestDB(t)
+	old := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Second)
+	seedBackupTestDB(t, src, old)
+
+	// Backdate rows that default to CURRENT_TIMESTAMP so only later changes are incremental
+	for _, query := range []string{
+		"UPDATE users SET updated_at = ?",
+		"UPDATE families SET updated_at = ?",
+		"UPDATE family_members SET joined_at = ?",
+		"UPDATE kids SET updated_at = ?",
+		"UPDATE teacher_kid_relationships SET created_at = ?",
+		"UPDATE spelling_lists SET updated_at = ?",
+		"UPDATE list_assignments SET assigned_at = ?",
+		"UPDATE teacher_classes SET updated_at = ?",
+		"UPDATE teacher_class_members SET created_at = ?",
+		"UPDATE teacher_class_lists SET assigned_at = ?",
+		"UPDATE words SET created_at = ?",
+		"UPDATE word_schedules SET updated_at = ?",
+		"UPDATE missing_letter_state SET updated_at = ?",
+		"UPDATE invitations SET created_at = ?",
+		"UPDATE digest_subscriptions SET updated_at = ?",
+		"UPDATE settings SET updated_at = ?",
+	} {
+		if _, err := src.Exec(query, old); err != nil {
+			t.Fatalf("failed to backdate %q: %v", query, err)
+		}
+	}
+
+	service := NewBackupService(src)
+	var full bytes.Buffer
+	if err := service.ExportWithOptions(&full, ExportOptions{Format: BackupFormatJSONL, Compression: BackupCompressionGzip}); err != nil {
+		t.Fatalf("full export error: %v", err)
+	}
+
+	since := time.Now().UTC().Add(-time.Hour)
+	for _, query := range []string{
+		"UPDATE kids SET name = 'Renamed', updated_at = CURRENT_TIMESTAMP WHERE id = 1",
+		"INSERT INTO practice_sessions (id, kid_id, spelling_list_id, started_at) VALUES (2, 1, 1, CURRENT_TIMESTAMP)",
+		"INSERT INTO word_attempts (id, practice_session_id, word_id, attempt_text, is_correct, time_taken_ms, points_earned) VALUES (2, 2, 1, 'kat', 0, 900, 0)",
+	} {
+		if _, err := src.Exec(query); err != nil {
+			t.Fatalf("failed to change %q: %v", query, err)
+		}
+	}
+
+	var incremental bytes.Buffer
+	if err := service.ExportWithOptions(&incremental, ExportOptions{Format: BackupFormatJSONL, Since: &since}); err != nil {
+		t.Fatalf("incremental export error: %v", err)
+	}
+
+	tables := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(incremental.Bytes()))
+	scanner.Scan() // header
+	for scanner.Scan() {
+		var record streamRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid record %q: %v", scanner.Text(), err)
+		}
+		tables[record.Table]++
+	}
+	want := map[string]int{"kids": 1, "practice_sessions": 1, "word_attempts": 1}
+	if len(tables) != len(want) {
+		t.Errorf("incremental backup has rows for %v, want %v", tables, want)
+	}
+	for table, n := range want {
+		if tables[table] != n {
+			t.Errorf("incremental backup has %d %s rows, want %d", tables[table], table, n)
+		}
+	}
+
+	dst := newTestDB(t)
+	restore := NewBackupService(dst)
+	if err := restore.ImportFromReader(&full); err != nil {
+		t.Fatalf("full import error: %v", err)
+	}
+	if err := restore.ImportFromReader(&incremental); err != nil {
+		t.Fatalf("incremental import error: %v", err)
+	}
+	assertSameRowCounts(t, src, dst)
+
+	var name string
+	if err := dst.QueryRow("SELECT name FROM kids WHERE id = 1").Scan(&name); err != nil {
+		t.Fatalf("failed to read kid: %v", err)
+	}
+	if name != "Renamed" {
+		t.Errorf("kid name = %q, want Renamed", name)
+	}
+}
@@ -0,0 +1,982 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"spellingclash/internal/database"
+	"strings"
+	"time"
+)
+
+// backupQuerier is satisfied by both *database.DB and *database.Tx
+type backupQuerier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	GetDialect() database.Dialect
+}
+
+// backupTable exports and restores the rows of one database table
+type backupTable interface {
+	// tableName is the database table, also used to tag JSON Lines records
+	tableName() string
+	// export calls emit for every row, or only rows changed since a time when since is set
+	export(q backupQuerier, since *time.Time, emit func(interface{}) error) error
+	// decode parses a row written by export
+	decode(raw json.RawMessage) (interface{}, error)
+	// restore inserts a row, or updates it if a row with the same key exists
+	restore(q backupQuerier, row interface{}) error
+	// sequence is the auto-increment ID column, if the table has one
+	sequence() string
+}
+
+// tableSpec describes how rows of type T are read from and written to a table
+type tableSpec[T any] struct {
+	name string
+	// selectQuery reads the columns in the same order as columns, without WHERE or ORDER BY
+	selectQuery string
+	orderBy     string
+	// changedSince are timestamp columns; a row is exported incrementally if any is at or after the since time
+	changedSince []string
+	// changedParent, if set, also exports rows whose parent changed; %s is
+	// replaced by a changed-since condition on the parent's updated_at
+	changedParent string
+	columns       []string
+	keys          []string
+	serial        bool
+	scan          func(rows *sql.Rows) (T, error)
+	values        func(row T) []interface{}
+	// write replaces the generic upsert when a table needs dialect-specific SQL
+	write func(q backupQuerier, row T) error
+}
+
+func (t *tableSpec[T]) tableName() string {
+	return t.name
+}
+
+func (t *tableSpec[T]) sequence() string {
+	if t.serial {
+		return "id"
+	}
+	return ""
+}
+
+func (t *tableSpec[T]) export(q backupQuerier, since *time.Time, emit func(interface{}) error) error {
+	query := t.selectQuery
+	var args []interface{}
+	if since != nil && len(t.changedSince) > 0 {
+		dialect := q.GetDialect()
+		var conds []string
+		for _, column := range t.changedSince {
+			conds = append(conds, dialect.TimestampAtOrAfter(column))
+		}
+		if t.changedParent != "" {
+			conds = append(conds, fmt.Sprintf(t.changedParent, dialect.TimestampAtOrAfter("updated_at")))
+		}
+		query += " WHERE (" + strings.Join(conds, " OR ") + ")"
+		for range conds {
+			args = append(args, *since)
+		}
+	}
+	query += " ORDER BY " + t.orderBy
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row, err := t.scan(rows)
+		if err != nil {
+			return err
+		}
+		if err := emit(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (t *tableSpec[T]) decode(raw json.RawMessage) (interface{}, error) {
+	var row T
+	if err := json.Unmarshal(raw, &row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+func (t *tableSpec[T]) restore(q backupQuerier, value interface{}) error {
+	row, ok := value.(T)
+	if !ok {
+		return fmt.Errorf("unexpected %T row for table %s", value, t.name)
+	}
+	if t.write != nil {
+		return t.write(q, row)
+	}
+
+	values := t.values(row)
+	keyArgs := make([]interface{}, len(t.keys))
+	keyConds := make([]string, len(t.keys))
+	for i, key := range t.keys {
+		keyConds[i] = key + " = ?"
+		for j, column := range t.columns {
+			if column == key {
+				keyArgs[i] = values[j]
+			}
+		}
+	}
+	where := strings.Join(keyConds, " AND ")
+
+	var count int
+	if err := q.QueryRow("SELECT COUNT(*) FROM "+t.name+" WHERE "+where, keyArgs...).Scan(&count); err != nil {
+		return err
+	}
+
+	if count > 0 {
+		assignments := make([]string, len(t.columns))
+		for i, column := range t.columns {
+			assignments[i] = column + " = ?"
+		}
+		query := "UPDATE " + t.name + " SET " + strings.Join(assignments, ", ") + " WHERE " + where
+		_, err := q.Exec(query, append(values, keyArgs...)...)
+		return err
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(t.columns)), ", ")
+	query := "INSERT INTO " + t.name + " (" + strings.Join(t.columns, ", ") + ") VALUES (" + placeholders + ")"
+	_, err := q.Exec(query, values...)
+	return err
+}
+
+// backupTables lists every backed up table in dependency order. Login sessions,
+// two-factor login challenges, password reset tokens, API tokens, queued emails and
+// the bad words filter are deliberately left out.
+var backupTables = []backupTable{
+	&tableSpec[UserBackup]{
+		name:         "users",
+		selectQuery:  "SELECT id, email, password_hash, name, is_admin, is_teacher, created_at, updated_at FROM users",
+		orderBy:      "id",
+		changedSince: []string{"updated_at"},
+		columns:      []string{"id", "email", "password_hash", "name", "is_admin", "is_teacher", "created_at", "updated_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (UserBackup, error) {
+			var u UserBackup
+			err := rows.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.IsAdmin, &u.IsTeacher, &u.CreatedAt, &u.UpdatedAt)
+			return u, err
+		},
+		values: func(u UserBackup) []interface{} {
+			return []interface{}{u.ID, u.Email, u.PasswordHash, u.Name, u.IsAdmin, u.IsTeacher, u.CreatedAt, u.UpdatedAt}
+		},
+	},
+	&tableSpec[UserIdentityBackup]{
+		name:         "user_identities",
+		selectQuery:  "SELECT id, user_id, provider, subject, email, created_at, last_used_at FROM user_identities",
+		orderBy:      "id",
+		changedSince: []string{"created_at", "last_used_at"},
+		columns:      []string{"id", "user_id", "provider", "subject", "email", "created_at", "last_used_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (UserIdentityBackup, error) {
+			var identity UserIdentityBackup
+			var lastUsedAt sql.NullTime
+			if err := rows.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt, &lastUsedAt); err != nil {
+				return identity, err
+			}
+			if lastUsedAt.Valid {
+				identity.LastUsedAt = &lastUsedAt.Time
+			}
+			return identity, nil
+		},
+		values: func(identity UserIdentityBackup) []interface{} {
+			return []interface{}{identity.ID, identity.UserID, identity.Provider, identity.Subject, identity.Email, identity.CreatedAt, nullableTime(identity.LastUsedAt)}
+		},
+	},
+	&tableSpec[TwoFactorBackup]{
+		name:         "user_two_factor",
+		selectQuery:  "SELECT user_id, secret, enabled_at, last_used_step, created_at FROM user_two_factor",
+		orderBy:      "user_id",
+		changedSince: []string{"created_at", "enabled_at"},
+		columns:      []string{"user_id", "secret", "enabled_at", "last_used_step", "created_at"},
+		keys:         []string{"user_id"},
+		scan: func(rows *sql.Rows) (TwoFactorBackup, error) {
+			var tf TwoFactorBackup
+			var enabledAt sql.NullTime
+			if err := rows.Scan(&tf.UserID, &tf.Secret, &enabledAt, &tf.LastUsedStep, &tf.CreatedAt); err != nil {
+				return tf, err
+			}
+			if enabledAt.Valid {
+				tf.EnabledAt = &enabledAt.Time
+			}
+			return tf, nil
+		},
+		values: func(tf TwoFactorBackup) []interface{} {
+			return []interface{}{tf.UserID, tf.Secret, nullableTime(tf.EnabledAt), tf.LastUsedStep, tf.CreatedAt}
+		},
+	},
+	&tableSpec[RecoveryCodeBackup]{
+		name:         "two_factor_recovery_codes",
+		selectQuery:  "SELECT id, user_id, code_hash, used_at, created_at FROM two_factor_recovery_codes",
+		orderBy:      "id",
+		changedSince: []string{"created_at", "used_at"},
+		columns:      []string{"id", "user_id", "code_hash", "used_at", "created_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (RecoveryCodeBackup, error) {
+			var code RecoveryCodeBackup
+			var usedAt sql.NullTime
+			if err := rows.Scan(&code.ID, &code.UserID, &code.CodeHash, &usedAt, &code.CreatedAt); err != nil {
+				return code, err
+			}
+			if usedAt.Valid {
+				code.UsedAt = &usedAt.Time
+			}
+			return code, nil
+		},
+		values: func(code RecoveryCodeBackup) []interface{} {
+			return []interface{}{code.ID, code.UserID, code.CodeHash, nullableTime(code.UsedAt), code.CreatedAt}
+		},
+	},
+	&tableSpec[FamilyBackup]{
+		name:         "families",
+		selectQuery:  "SELECT family_code, created_at, updated_at FROM families",
+		orderBy:      "family_code",
+		changedSince: []string{"updated_at"},
+		columns:      []string{"family_code", "created_at", "updated_at"},
+		keys:         []string{"family_code"},
+		scan: func(rows *sql.Rows) (FamilyBackup, error) {
+			var f FamilyBackup
+			err := rows.Scan(&f.FamilyCode, &f.CreatedAt, &f.UpdatedAt)
+			return f, err
+		},
+		values: func(f FamilyBackup) []interface{} {
+			return []interface{}{f.FamilyCode, f.CreatedAt, f.UpdatedAt}
+		},
+	},
+	&tableSpec[FamilyMemberBackup]{
+		name:         "family_members",
+		selectQuery:  "SELECT family_code, user_id, role FROM family_members",
+		orderBy:      "family_code, user_id",
+		changedSince: []string{"joined_at"},
+		columns:      []string{"family_code", "user_id", "role"},
+		keys:         []string{"family_code", "user_id"},
+		scan: func(rows *sql.Rows) (FamilyMemberBackup, error) {
+			var m FamilyMemberBackup
+			err := rows.Scan(&m.FamilyCode, &m.UserID, &m.Role)
+			return m, err
+		},
+		values: func(m FamilyMemberBackup) []interface{} {
+			return []interface{}{m.FamilyCode, m.UserID, m.Role}
+		},
+	},
+	&tableSpec[StreakSettingsBackup]{
+		name:         "streak_settings",
+		selectQuery:  "SELECT family_code, timezone, weekends_off, updated_at FROM streak_settings",
+		orderBy:      "family_code",
+		changedSince: []string{"updated_at"},
+		columns:      []string{"family_code", "timezone", "weekends_off", "updated_at"},
+		keys:         []string{"family_code"},
+		scan: func(rows *sql.Rows) (StreakSettingsBackup, error) {
+			var s StreakSettingsBackup
+			err := rows.Scan(&s.FamilyCode, &s.Timezone, &s.WeekendsOff, &s.UpdatedAt)
+			return s, err
+		},
+		values: func(s StreakSettingsBackup) []interface{} {
+			return []interface{}{s.FamilyCode, s.Timezone, s.WeekendsOff, s.UpdatedAt}
+		},
+	},
+	&tableSpec[StreakFreezeBackup]{
+		name:         "streak_freezes",
+		selectQuery:  "SELECT id, family_code, name, start_date, end_date, created_at FROM streak_freezes",
+		orderBy:      "id",
+		changedSince: []string{"created_at"},
+		columns:      []string{"id", "family_code", "name", "start_date", "end_date", "created_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (StreakFreezeBackup, error) {
+			var f StreakFreezeBackup
+			err := rows.Scan(&f.ID, &f.FamilyCode, &f.Name, &f.StartDate, &f.EndDate, &f.CreatedAt)
+			return f, err
+		},
+		values: func(f StreakFreezeBackup) []interface{} {
+			return []interface{}{f.ID, f.FamilyCode, f.Name, f.StartDate, f.EndDate, f.CreatedAt}
+		},
+	},
+	&tableSpec[KidBackup]{
+		name:         "kids",
+		selectQuery:  "SELECT id, family_code, name, username, COALESCE(password, ''), COALESCE(avatar_color, '#4A90E2'), created_at, updated_at FROM kids",
+		orderBy:      "id",
+		changedSince: []string{"updated_at"},
+		columns:      []string{"id", "family_code", "name", "username", "password", "avatar_color", "created_at", "updated_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (KidBackup, error) {
+			var k KidBackup
+			err := rows.Scan(&k.ID, &k.FamilyCode, &k.Name, &k.Username, &k.Password, &k.AvatarColor, &k.CreatedAt, &k.UpdatedAt)
+			return k, err
+		},
+		values: func(k KidBackup) []interface{} {
+			return []interface{}{k.ID, k.FamilyCode, k.Name, k.Username, nullIfEmpty(k.Password), k.AvatarColor, k.CreatedAt, k.UpdatedAt}
+		},
+	},
+	&tableSpec[TeacherKidBackup]{
+		name:         "teacher_kid_relationships",
+		selectQuery:  "SELECT id, teacher_user_id, kid_id, created_at FROM teacher_kid_relationships",
+		orderBy:      "id",
+		changedSince: []string{"created_at"},
+		columns:      []string{"id", "teacher_user_id", "kid_id", "created_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (TeacherKidBackup, error) {
+			var t TeacherKidBackup
+			err := rows.Scan(&t.ID, &t.TeacherUserID, &t.KidID, &t.CreatedAt)
+			return t, err
+		},
+		values: func(t TeacherKidBackup) []interface{} {
+			return []interface{}{t.ID, t.TeacherUserID, t.KidID, t.CreatedAt}
+		},
+	},
+	&tableSpec[ListBackup]{
+		name:         "spelling_lists",
+		selectQuery:  "SELECT id, name, COALESCE(description, ''), family_code, is_public, locale, created_by, created_at, updated_at FROM spelling_lists",
+		orderBy:      "id",
+		changedSince: []string{"updated_at"},
+		columns:      []string{"id", "name", "description", "family_code", "is_public", "locale", "created_by", "created_at", "updated_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (ListBackup, error) {
+			var l ListBackup
+			var familyCode sql.NullString
+			var createdBy sql.NullInt64
+			if err := rows.Scan(&l.ID, &l.Name, &l.Description, &familyCode, &l.IsPublic, &l.Locale, &createdBy, &l.CreatedAt, &l.UpdatedAt); err != nil {
+				return l, err
+			}
+			if familyCode.Valid {
+				l.FamilyCode = &familyCode.String
+			}
+			if createdBy.Valid {
+				l.CreatedBy = &createdBy.Int64
+			}
+			return l, nil
+		},
+		values: func(l ListBackup) []interface{} {
+			return []interface{}{l.ID, l.Name, l.Description, nullableString(l.FamilyCode), l.IsPublic, l.Locale, nullableInt64(l.CreatedBy), l.CreatedAt, l.UpdatedAt}
+		},
+	},
+	&tableSpec[ListAssignmentBackup]{
+		name:         "list_assignments",
+		selectQuery:  "SELECT spelling_list_id, kid_id, assigned_at, assigned_by, COALESCE(managed_by_teacher, FALSE), due_date FROM list_assignments",
+		orderBy:      "spelling_list_id, kid_id",
+		changedSince: []string{"assigned_at"},
+		columns:      []string{"spelling_list_id", "kid_id", "assigned_at", "assigned_by", "managed_by_teacher", "due_date"},
+		keys:         []string{"spelling_list_id", "kid_id"},
+		scan: func(rows *sql.Rows) (ListAssignmentBackup, error) {
+			var a ListAssignmentBackup
+			var dueDate sql.NullTime
+			if err := rows.Scan(&a.SpellingListID, &a.KidID, &a.AssignedAt, &a.AssignedBy, &a.ManagedByTeacher, &dueDate); err != nil {
+				return a, err
+			}
+			if dueDate.Valid {
+				a.DueDate = &dueDate.Time
+			}
+			return a, nil
+		},
+		values: func(a ListAssignmentBackup) []interface{} {
+			return []interface{}{a.SpellingListID, a.KidID, a.AssignedAt, a.AssignedBy, a.ManagedByTeacher, nullableTime(a.DueDate)}
+		},
+	},
+	&tableSpec[TeacherClassBackup]{
+		name:         "teacher_classes",
+		selectQuery:  "SELECT id, teacher_user_id, name, archived_at, created_at, updated_at FROM teacher_classes",
+		orderBy:      "id",
+		changedSince: []string{"updated_at"},
+		columns:      []string{"id", "teacher_user_id", "name", "archived_at", "created_at", "updated_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (TeacherClassBackup, error) {
+			var c TeacherClassBackup
+			var archivedAt sql.NullTime
+			if err := rows.Scan(&c.ID, &c.TeacherUserID, &c.Name, &archivedAt, &c.CreatedAt, &c.UpdatedAt); err != nil {
+				return c, err
+			}
+			if archivedAt.Valid {
+				c.ArchivedAt = &archivedAt.Time
+			}
+			return c, nil
+		},
+		values: func(c TeacherClassBackup) []interface{} {
+			return []interface{}{c.ID, c.TeacherUserID, c.Name, nullableTime(c.ArchivedAt), c.CreatedAt, c.UpdatedAt}
+		},
+	},
+	&tableSpec[TeacherClassMemberBackup]{
+		name:         "teacher_class_members",
+		selectQuery:  "SELECT id, class_id, kid_id, created_at FROM teacher_class_members",
+		orderBy:      "id",
+		changedSince: []string{"created_at"},
+		columns:      []string{"id", "class_id", "kid_id", "created_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (TeacherClassMemberBackup, error) {
+			var m TeacherClassMemberBackup
+			err := rows.Scan(&m.ID, &m.ClassID, &m.KidID, &m.CreatedAt)
+			return m, err
+		},
+		values: func(m TeacherClassMemberBackup) []interface{} {
+			return []interface{}{m.ID, m.ClassID, m.KidID, m.CreatedAt}
+		},
+	},
+	&tableSpec[TeacherClassListBackup]{
+		name:         "teacher_class_lists",
+		selectQuery:  "SELECT id, class_id, spelling_list_id, due_date, assigned_at FROM teacher_class_lists",
+		orderBy:      "id",
+		changedSince: []string{"assigned_at"},
+		columns:      []string{"id", "class_id", "spelling_list_id", "due_date", "assigned_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (TeacherClassListBackup, error) {
+			var l TeacherClassListBackup
+			var dueDate sql.NullTime
+			if err := rows.Scan(&l.ID, &l.ClassID, &l.SpellingListID, &dueDate, &l.AssignedAt); err != nil {
+				return l, err
+			}
+			if dueDate.Valid {
+				l.DueDate = &dueDate.Time
+			}
+			return l, nil
+		},
+		values: func(l TeacherClassListBackup) []interface{} {
+			return []interface{}{l.ID, l.ClassID, l.SpellingListID, nullableTime(l.DueDate), l.AssignedAt}
+		},
+	},
+	&tableSpec[WordBackup]{
+		name:         "words",
+		selectQuery:  "SELECT id, spelling_list_id, word_text, COALESCE(difficulty_level, 1), COALESCE(audio_filename, ''), COALESCE(definition, ''), COALESCE(definition_audio_filename, ''), position, created_at FROM words",
+		orderBy:      "id",
+		changedSince: []string{"created_at"},
+		// Words have no updated_at, so edits are picked up through their list
+		changedParent: "spelling_list_id IN (SELECT id FROM spelling_lists WHERE %s)",
+		columns:       []string{"id", "spelling_list_id", "word_text", "difficulty_level", "audio_filename", "definition", "definition_audio_filename", "position", "created_at"},
+		keys:          []string{"id"},
+		serial:        true,
+		scan: func(rows *sql.Rows) (WordBackup, error) {
+			var w WordBackup
+			err := rows.Scan(&w.ID, &w.SpellingListID, &w.WordText, &w.DifficultyLevel, &w.AudioFilename, &w.Definition, &w.DefinitionAudioFilename, &w.Position, &w.CreatedAt)
+			return w, err
+		},
+		values: func(w WordBackup) []interface{} {
+			return []interface{}{w.ID, w.SpellingListID, w.WordText, w.DifficultyLevel, nullIfEmpty(w.AudioFilename), nullIfEmpty(w.Definition), nullIfEmpty(w.DefinitionAudioFilename), w.Position, w.CreatedAt}
+		},
+	},
+	&tableSpec[WordScheduleBackup]{
+		name:         "word_schedules",
+		selectQuery:  "SELECT kid_id, word_id, repetitions, interval_days, ease_factor, lapses, due_at, last_reviewed_at, created_at, updated_at FROM word_schedules",
+		orderBy:      "kid_id, word_id",
+		changedSince: []string{"updated_at"},
+		columns:      []string{"kid_id", "word_id", "repetitions", "interval_days", "ease_factor", "lapses", "due_at", "last_reviewed_at", "created_at", "updated_at"},
+		keys:         []string{"kid_id", "word_id"},
+		scan: func(rows *sql.Rows) (WordScheduleBackup, error) {
+			var ws WordScheduleBackup
+			var lastReviewedAt sql.NullTime
+			if err := rows.Scan(&ws.KidID, &ws.WordID, &ws.Repetitions, &ws.IntervalDays, &ws.EaseFactor, &ws.Lapses, &ws.DueAt, &lastReviewedAt, &ws.CreatedAt, &ws.UpdatedAt); err != nil {
+				return ws, err
+			}
+			if lastReviewedAt.Valid {
+				ws.LastReviewedAt = &lastReviewedAt.Time
+			}
+			return ws, nil
+		},
+		values: func(ws WordScheduleBackup) []interface{} {
+			return []interface{}{ws.KidID, ws.WordID, ws.Repetitions, ws.IntervalDays, ws.EaseFactor, ws.Lapses, ws.DueAt, nullableTime(ws.LastReviewedAt), ws.CreatedAt, ws.UpdatedAt}
+		},
+	},
+	&tableSpec[AchievementBackup]{
+		name:         "achievements",
+		selectQuery:  "SELECT id, kid_id, achievement_key, unlocked_at, seen_at FROM achievements",
+		orderBy:      "id",
+		changedSince: []string{"unlocked_at", "seen_at"},
+		columns:      []string{"id", "kid_id", "achievement_key", "unlocked_at", "seen_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (AchievementBackup, error) {
+			var a AchievementBackup
+			var seenAt sql.NullTime
+			if err := rows.Scan(&a.ID, &a.KidID, &a.AchievementKey, &a.UnlockedAt, &seenAt); err != nil {
+				return a, err
+			}
+			if seenAt.Valid {
+				a.SeenAt = &seenAt.Time
+			}
+			return a, nil
+		},
+		values: func(a AchievementBackup) []interface{} {
+			return []interface{}{a.ID, a.KidID, a.AchievementKey, a.UnlockedAt, nullableTime(a.SeenAt)}
+		},
+	},
+	&tableSpec[LeaderboardSettingsBackup]{
+		name:         "leaderboard_settings",
+		selectQuery:  "SELECT kid_id, nickname, hidden_by_parent, hidden_by_teacher, updated_at FROM leaderboard_settings",
+		orderBy:      "kid_id",
+		changedSince: []string{"updated_at"},
+		columns:      []string{"kid_id", "nickname", "hidden_by_parent", "hidden_by_teacher", "updated_at"},
+		keys:         []string{"kid_id"},
+		scan: func(rows *sql.Rows) (LeaderboardSettingsBackup, error) {
+			var s LeaderboardSettingsBackup
+			err := rows.Scan(&s.KidID, &s.Nickname, &s.HiddenByParent, &s.HiddenByTeacher, &s.UpdatedAt)
+			return s, err
+		},
+		values: func(s LeaderboardSettingsBackup) []interface{} {
+			return []interface{}{s.KidID, s.Nickname, s.HiddenByParent, s.HiddenByTeacher, s.UpdatedAt}
+		},
+	},
+	&tableSpec[ClashMatchBackup]{
+		name:         "clash_matches",
+		selectQuery:  "SELECT id, spelling_list_id, challenger_kid_id, opponent_kid_id, status, total_rounds, challenger_score, opponent_score, winner_kid_id, created_at, started_at, finished_at FROM clash_matches",
+		orderBy:      "id",
+		changedSince: []string{"created_at", "started_at", "finished_at"},
+		columns:      []string{"id", "spelling_list_id", "challenger_kid_id", "opponent_kid_id", "status", "total_rounds", "challenger_score", "opponent_score", "winner_kid_id", "created_at", "started_at", "finished_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (ClashMatchBackup, error) {
+			var m ClashMatchBackup
+			var winnerID sql.NullInt64
+			var startedAt, finishedAt sql.NullTime
+			if err := rows.Scan(&m.ID, &m.SpellingListID, &m.ChallengerKidID, &m.OpponentKidID, &m.Status, &m.TotalRounds, &m.ChallengerScore, &m.OpponentScore, &winnerID, &m.CreatedAt, &startedAt, &finishedAt); err != nil {
+				return m, err
+			}
+			if winnerID.Valid {
+				m.WinnerKidID = &winnerID.Int64
+			}
+			if startedAt.Valid {
+				m.StartedAt = &startedAt.Time
+			}
+			if finishedAt.Valid {
+				m.FinishedAt = &finishedAt.Time
+			}
+			return m, nil
+		},
+		values: func(m ClashMatchBackup) []interface{} {
+			return []interface{}{m.ID, m.SpellingListID, m.ChallengerKidID, m.OpponentKidID, m.Status, m.TotalRounds, m.ChallengerScore, m.OpponentScore, nullableInt64(m.WinnerKidID), m.CreatedAt, nullableTime(m.StartedAt), nullableTime(m.FinishedAt)}
+		},
+	},
+	&tableSpec[SpellingBeeBackup]{
+		name:         "spelling_bees",
+		selectQuery:  "SELECT id, teacher_user_id, spelling_list_id, max_misses, answer_mode, status, version, created_at, updated_at, finished_at FROM spelling_bees",
+		orderBy:      "id",
+		changedSince: []string{"updated_at"},
+		columns:      []string{"id", "teacher_user_id", "spelling_list_id", "max_misses", "answer_mode", "status", "version", "created_at", "updated_at", "finished_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (SpellingBeeBackup, error) {
+			var b SpellingBeeBackup
+			var finishedAt sql.NullTime
+			if err := rows.Scan(&b.ID, &b.TeacherUserID, &b.SpellingListID, &b.MaxMisses, &b.AnswerMode, &b.Status, &b.Version, &b.CreatedAt, &b.UpdatedAt, &finishedAt); err != nil {
+				return b, err
+			}
+			if finishedAt.Valid {
+				b.FinishedAt = &finishedAt.Time
+			}
+			return b, nil
+		},
+		values: func(b SpellingBeeBackup) []interface{} {
+			return []interface{}{b.ID, b.TeacherUserID, b.SpellingListID, b.MaxMisses, b.AnswerMode, b.Status, b.Version, b.CreatedAt, b.UpdatedAt, nullableTime(b.FinishedAt)}
+		},
+	},
+	&tableSpec[SpellingBeeKidBackup]{
+		name:         "spelling_bee_participants",
+		selectQuery:  "SELECT id, bee_id, kid_id, turn_order, words_correct, misses, eliminated_turn, final_place, updated_at FROM spelling_bee_participants",
+		orderBy:      "id",
+		changedSince: []string{"updated_at"},
+		columns:      []string{"id", "bee_id", "kid_id", "turn_order", "words_correct", "misses", "eliminated_turn", "final_place", "updated_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (SpellingBeeKidBackup, error) {
+			var p SpellingBeeKidBackup
+			var eliminatedTurn, finalPlace sql.NullInt64
+			if err := rows.Scan(&p.ID, &p.BeeID, &p.KidID, &p.TurnOrder, &p.WordsCorrect, &p.Misses, &eliminatedTurn, &finalPlace, &p.UpdatedAt); err != nil {
+				return p, err
+			}
+			if eliminatedTurn.Valid {
+				p.EliminatedTurn = &eliminatedTurn.Int64
+			}
+			if finalPlace.Valid {
+				p.FinalPlace = &finalPlace.Int64
+			}
+			return p, nil
+		},
+		values: func(p SpellingBeeKidBackup) []interface{} {
+			return []interface{}{p.ID, p.BeeID, p.KidID, p.TurnOrder, p.WordsCorrect, p.Misses, nullableInt64(p.EliminatedTurn), nullableInt64(p.FinalPlace), p.UpdatedAt}
+		},
+	},
+	&tableSpec[SpellingBeeTurnBackup]{
+		name:         "spelling_bee_turns",
+		selectQuery:  "SELECT id, bee_id, turn_number, kid_id, word_id, word_text, answer, is_correct, asked_at, answered_at FROM spelling_bee_turns",
+		orderBy:      "id",
+		changedSince: []string{"asked_at", "answered_at"},
+		columns:      []string{"id", "bee_id", "turn_number", "kid_id", "word_id", "word_text", "answer", "is_correct", "asked_at", "answered_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (SpellingBeeTurnBackup, error) {
+			var t SpellingBeeTurnBackup
+			var wordID sql.NullInt64
+			var answer sql.NullString
+			var isCorrect sql.NullBool
+			var answeredAt sql.NullTime
+			if err := rows.Scan(&t.ID, &t.BeeID, &t.TurnNumber, &t.KidID, &wordID, &t.WordText, &answer, &isCorrect, &t.AskedAt, &answeredAt); err != nil {
+				return t, err
+			}
+			if wordID.Valid {
+				t.WordID = &wordID.Int64
+			}
+			if answer.Valid {
+				t.Answer = &answer.String
+			}
+			if isCorrect.Valid {
+				t.IsCorrect = &isCorrect.Bool
+			}
+			if answeredAt.Valid {
+				t.AnsweredAt = &answeredAt.Time
+			}
+			return t, nil
+		},
+		values: func(t SpellingBeeTurnBackup) []interface{} {
+			return []interface{}{t.ID, t.BeeID, t.TurnNumber, t.KidID, nullableInt64(t.WordID), t.WordText, nullableString(t.Answer), nullableBool(t.IsCorrect), t.AskedAt, nullableTime(t.AnsweredAt)}
+		},
+	},
+	&tableSpec[PracticeBackup]{
+		name:         "practice_sessions",
+		selectQuery:  "SELECT id, kid_id, spelling_list_id, started_at, completed_at, total_words, correct_words, points_earned FROM practice_sessions",
+		orderBy:      "id",
+		changedSince: []string{"started_at", "completed_at"},
+		columns:      []string{"id", "kid_id", "spelling_list_id", "started_at", "completed_at", "total_words", "correct_words", "points_earned"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (PracticeBackup, error) {
+			var p PracticeBackup
+			var completedAt sql.NullTime
+			if err := rows.Scan(&p.ID, &p.KidID, &p.SpellingListID, &p.StartedAt, &completedAt, &p.TotalWords, &p.CorrectWords, &p.PointsEarned); err != nil {
+				return p, err
+			}
+			if completedAt.Valid {
+				p.CompletedAt = &completedAt.Time
+			}
+			return p, nil
+		},
+		values: func(p PracticeBackup) []interface{} {
+			return []interface{}{p.ID, p.KidID, p.SpellingListID, p.StartedAt, nullableTime(p.CompletedAt), p.TotalWords, p.CorrectWords, p.PointsEarned}
+		},
+	},
+	&tableSpec[WordAttemptBackup]{
+		name:         "word_attempts",
+		selectQuery:  "SELECT id, practice_session_id, word_id, attempt_text, is_correct, time_taken_ms, points_earned, attempted_at FROM word_attempts",
+		orderBy:      "id",
+		changedSince: []string{"attempted_at"},
+		columns:      []string{"id", "practice_session_id", "word_id", "attempt_text", "is_correct", "time_taken_ms", "points_earned", "attempted_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (WordAttemptBackup, error) {
+			var a WordAttemptBackup
+			err := rows.Scan(&a.ID, &a.PracticeSessionID, &a.WordID, &a.AttemptText, &a.IsCorrect, &a.TimeTakenMs, &a.PointsEarned, &a.AttemptedAt)
+			return a, err
+		},
+		values: func(a WordAttemptBackup) []interface{} {
+			return []interface{}{a.ID, a.PracticeSessionID, a.WordID, a.AttemptText, a.IsCorrect, a.TimeTakenMs, a.PointsEarned, a.AttemptedAt}
+		},
+	},
+	&tableSpec[PracticeStateBackup]{
+		name:         "practice_state",
+		selectQuery:  "SELECT kid_id, session_id, current_index, correct_count, total_points, start_time, COALESCE(word_order, ''), updated_at FROM practice_state",
+		orderBy:      "kid_id",
+		changedSince: []string{"updated_at"},
+		columns:      []string{"kid_id", "session_id", "current_index", "correct_count", "total_points", "start_time", "word_order", "updated_at"},
+		keys:         []string{"kid_id"},
+		scan: func(rows *sql.Rows) (PracticeStateBackup, error) {
+			var p PracticeStateBackup
+			err := rows.Scan(&p.KidID, &p.SessionID, &p.CurrentIndex, &p.CorrectCount, &p.TotalPoints, &p.StartTime, &p.WordOrder, &p.UpdatedAt)
+			return p, err
+		},
+		values: func(p PracticeStateBackup) []interface{} {
+			return []interface{}{p.KidID, p.SessionID, p.CurrentIndex, p.CorrectCount, p.TotalPoints, p.StartTime, nullIfEmpty(p.WordOrder), p.UpdatedAt}
+		},
+	},
+	&tableSpec[PracticeWordTimingBackup]{
+		name:         "practice_word_timing",
+		selectQuery:  "SELECT id, kid_id, session_id, word_index, started_at FROM practice_word_timing",
+		orderBy:      "id",
+		changedSince: []string{"started_at"},
+		columns:      []string{"id", "kid_id", "session_id", "word_index", "started_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (PracticeWordTimingBackup, error) {
+			var t PracticeWordTimingBackup
+			err := rows.Scan(&t.ID, &t.KidID, &t.SessionID, &t.WordIndex, &t.StartedAt)
+			return t, err
+		},
+		values: func(t PracticeWordTimingBackup) []interface{} {
+			return []interface{}{t.ID, t.KidID, t.SessionID, t.WordIndex, t.StartedAt}
+		},
+	},
+	gameSessionTable("hangman_sessions"),
+	&tableSpec[HangmanGameBackup]{
+		name:         "hangman_games",
+		selectQuery:  "SELECT id, session_id, kid_id, word_id, word, guessed_letters, COALESCE(wrong_guesses, 0), COALESCE(max_wrong_guesses, 6), COALESCE(is_won, FALSE), COALESCE(is_lost, FALSE), COALESCE(points_earned, 0), started_at, completed_at FROM hangman_games",
+		orderBy:      "id",
+		changedSince: []string{"started_at", "completed_at"},
+		columns:      []string{"id", "session_id", "kid_id", "word_id", "word", "guessed_letters", "wrong_guesses", "max_wrong_guesses", "is_won", "is_lost", "points_earned", "started_at", "completed_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (HangmanGameBackup, error) {
+			var g HangmanGameBackup
+			var completedAt sql.NullTime
+			if err := rows.Scan(&g.ID, &g.SessionID, &g.KidID, &g.WordID, &g.Word, &g.GuessedLetters, &g.WrongGuesses, &g.MaxWrongGuesses, &g.IsWon, &g.IsLost, &g.PointsEarned, &g.StartedAt, &completedAt); err != nil {
+				return g, err
+			}
+			if completedAt.Valid {
+				g.CompletedAt = &completedAt.Time
+			}
+			return g, nil
+		},
+		values: func(g HangmanGameBackup) []interface{} {
+			return []interface{}{g.ID, g.SessionID, g.KidID, g.WordID, g.Word, g.GuessedLetters, g.WrongGuesses, g.MaxWrongGuesses, g.IsWon, g.IsLost, g.PointsEarned, g.StartedAt, nullableTime(g.CompletedAt)}
+		},
+	},
+	gameStateTable("hangman_state"),
+	gameSessionTable("missing_letter_sessions"),
+	&tableSpec[MissingLetterGameBackup]{
+		name:         "missing_letter_games",
+		selectQuery:  "SELECT id, session_id, kid_id, word_id, word, missing_indices, guessed_letters, COALESCE(attempts, 0), COALESCE(max_attempts, 3), COALESCE(is_won, FALSE), COALESCE(is_lost, FALSE), COALESCE(points_earned, 0), started_at, completed_at FROM missing_letter_games",
+		orderBy:      "id",
+		changedSince: []string{"started_at", "completed_at"},
+		columns:      []string{"id", "session_id", "kid_id", "word_id", "word", "missing_indices", "guessed_letters", "attempts", "max_attempts", "is_won", "is_lost", "points_earned", "started_at", "completed_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (MissingLetterGameBackup, error) {
+			var g MissingLetterGameBackup
+			var completedAt sql.NullTime
+			if err := rows.Scan(&g.ID, &g.SessionID, &g.KidID, &g.WordID, &g.Word, &g.MissingIndices, &g.GuessedLetters, &g.Attempts, &g.MaxAttempts, &g.IsWon, &g.IsLost, &g.PointsEarned, &g.StartedAt, &completedAt); err != nil {
+				return g, err
+			}
+			if completedAt.Valid {
+				g.CompletedAt = &completedAt.Time
+			}
+			return g, nil
+		},
+		values: func(g MissingLetterGameBackup) []interface{} {
+			return []interface{}{g.ID, g.SessionID, g.KidID, g.WordID, g.Word, g.MissingIndices, g.GuessedLetters, g.Attempts, g.MaxAttempts, g.IsWon, g.IsLost, g.PointsEarned, g.StartedAt, nullableTime(g.CompletedAt)}
+		},
+	},
+	gameStateTable("missing_letter_state"),
+	&tableSpec[SpellingTestBackup]{
+		name:         "spelling_tests",
+		selectQuery:  "SELECT id, class_id, spelling_list_id, created_by, opens_at, closes_at, created_at FROM spelling_tests",
+		orderBy:      "id",
+		changedSince: []string{"created_at"},
+		columns:      []string{"id", "class_id", "spelling_list_id", "created_by", "opens_at", "closes_at", "created_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (SpellingTestBackup, error) {
+			var t SpellingTestBackup
+			err := rows.Scan(&t.ID, &t.ClassID, &t.SpellingListID, &t.CreatedBy, &t.OpensAt, &t.ClosesAt, &t.CreatedAt)
+			return t, err
+		},
+		values: func(t SpellingTestBackup) []interface{} {
+			return []interface{}{t.ID, t.ClassID, t.SpellingListID, t.CreatedBy, t.OpensAt, t.ClosesAt, t.CreatedAt}
+		},
+	},
+	&tableSpec[SpellingTestAttemptBackup]{
+		name:         "spelling_test_attempts",
+		selectQuery:  "SELECT id, test_id, kid_id, total_words, correct_words, started_at, completed_at FROM spelling_test_attempts",
+		orderBy:      "id",
+		changedSince: []string{"started_at", "completed_at"},
+		columns:      []string{"id", "test_id", "kid_id", "total_words", "correct_words", "started_at", "completed_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (SpellingTestAttemptBackup, error) {
+			var a SpellingTestAttemptBackup
+			var completedAt sql.NullTime
+			if err := rows.Scan(&a.ID, &a.TestID, &a.KidID, &a.TotalWords, &a.CorrectWords, &a.StartedAt, &completedAt); err != nil {
+				return a, err
+			}
+			if completedAt.Valid {
+				a.CompletedAt = &completedAt.Time
+			}
+			return a, nil
+		},
+		values: func(a SpellingTestAttemptBackup) []interface{} {
+			return []interface{}{a.ID, a.TestID, a.KidID, a.TotalWords, a.CorrectWords, a.StartedAt, nullableTime(a.CompletedAt)}
+		},
+	},
+	&tableSpec[SpellingTestAnswerBackup]{
+		name:         "spelling_test_answers",
+		selectQuery:  "SELECT id, attempt_id, word_id, word_text, answer, is_correct, answered_at FROM spelling_test_answers",
+		orderBy:      "id",
+		changedSince: []string{"answered_at"},
+		columns:      []string{"id", "attempt_id", "word_id", "word_text", "answer", "is_correct", "answered_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (SpellingTestAnswerBackup, error) {
+			var a SpellingTestAnswerBackup
+			err := rows.Scan(&a.ID, &a.AttemptID, &a.WordID, &a.WordText, &a.Answer, &a.IsCorrect, &a.AnsweredAt)
+			return a, err
+		},
+		values: func(a SpellingTestAnswerBackup) []interface{} {
+			return []interface{}{a.ID, a.AttemptID, a.WordID, a.WordText, a.Answer, a.IsCorrect, a.AnsweredAt}
+		},
+	},
+	&tableSpec[InvitationBackup]{
+		name:         "invitations",
+		selectQuery:  "SELECT id, code, email, invited_by, created_at, used_at, used_by, expires_at, COALESCE(email_sent, TRUE), COALESCE(email_error, ''), last_sent_at FROM invitations",
+		orderBy:      "id",
+		changedSince: []string{"created_at", "used_at", "last_sent_at"},
+		columns:      []string{"id", "code", "email", "invited_by", "created_at", "used_at", "used_by", "expires_at", "email_sent", "email_error", "last_sent_at"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (InvitationBackup, error) {
+			var inv InvitationBackup
+			var usedAt, lastSentAt sql.NullTime
+			var usedBy sql.NullInt64
+			if err := rows.Scan(&inv.ID, &inv.Code, &inv.Email, &inv.InvitedBy, &inv.CreatedAt, &usedAt, &usedBy, &inv.ExpiresAt, &inv.EmailSent, &inv.EmailError, &lastSentAt); err != nil {
+				return inv, err
+			}
+			if usedAt.Valid {
+				inv.UsedAt = &usedAt.Time
+			}
+			if usedBy.Valid {
+				inv.UsedBy = &usedBy.Int64
+			}
+			if lastSentAt.Valid {
+				inv.LastSentAt = &lastSentAt.Time
+			}
+			return inv, nil
+		},
+		values: func(inv InvitationBackup) []interface{} {
+			return []interface{}{inv.ID, inv.Code, inv.Email, inv.InvitedBy, inv.CreatedAt, nullableTime(inv.UsedAt), nullableInt64(inv.UsedBy), inv.ExpiresAt, inv.EmailSent, nullIfEmpty(inv.EmailError), nullableTime(inv.LastSentAt)}
+		},
+	},
+	&tableSpec[DigestSubscriptionBackup]{
+		name:         "digest_subscriptions",
+		selectQuery:  "SELECT user_id, enabled, last_sent_at, updated_at FROM digest_subscriptions",
+		orderBy:      "user_id",
+		changedSince: []string{"updated_at", "last_sent_at"},
+		columns:      []string{"user_id", "enabled", "last_sent_at", "updated_at"},
+		keys:         []string{"user_id"},
+		scan: func(rows *sql.Rows) (DigestSubscriptionBackup, error) {
+			var sub DigestSubscriptionBackup
+			var lastSentAt sql.NullTime
+			if err := rows.Scan(&sub.UserID, &sub.Enabled, &lastSentAt, &sub.UpdatedAt); err != nil {
+				return sub, err
+			}
+			if lastSentAt.Valid {
+				sub.LastSentAt = &lastSentAt.Time
+			}
+			return sub, nil
+		},
+		values: func(sub DigestSubscriptionBackup) []interface{} {
+			return []interface{}{sub.UserID, sub.Enabled, nullableTime(sub.LastSentAt), sub.UpdatedAt}
+		},
+	},
+	&tableSpec[SettingBackup]{
+		name: "settings",
+		// "key" is reserved in MySQL, so select every column rather than naming them
+		selectQuery:  "SELECT * FROM settings",
+		orderBy:      "1",
+		changedSince: []string{"updated_at"},
+		scan: func(rows *sql.Rows) (SettingBackup, error) {
+			var setting SettingBackup
+			var updatedAt sql.NullTime
+			err := rows.Scan(&setting.Key, &setting.Value, &updatedAt)
+			return setting, err
+		},
+		// Defaults are inserted by migrations, so settings are always upserted
+		write: func(q backupQuerier, setting SettingBackup) error {
+			_, err := q.Exec(q.GetDialect().UpsertSettings(), setting.Key, setting.Value)
+			return err
+		},
+	},
+}
+
+// gameSessionTable backs up hangman_sessions or missing_letter_sessions, which share a layout
+func gameSessionTable(name string) backupTable {
+	return &tableSpec[GameSessionBackup]{
+		name:         name,
+		selectQuery:  "SELECT id, kid_id, spelling_list_id, started_at, completed_at, total_games, COALESCE(games_won, 0), COALESCE(total_points, 0) FROM " + name,
+		orderBy:      "id",
+		changedSince: []string{"started_at", "completed_at"},
+		columns:      []string{"id", "kid_id", "spelling_list_id", "started_at", "completed_at", "total_games", "games_won", "total_points"},
+		keys:         []string{"id"},
+		serial:       true,
+		scan: func(rows *sql.Rows) (GameSessionBackup, error) {
+			var g GameSessionBackup
+			var completedAt sql.NullTime
+			if err := rows.Scan(&g.ID, &g.KidID, &g.SpellingListID, &g.StartedAt, &completedAt, &g.TotalGames, &g.GamesWon, &g.TotalPoints); err != nil {
+				return g, err
+			}
+			if completedAt.Valid {
+				g.CompletedAt = &completedAt.Time
+			}
+			return g, nil
+		},
+		values: func(g GameSessionBackup) []interface{} {
+			return []interface{}{g.ID, g.KidID, g.SpellingListID, g.StartedAt, nullableTime(g.CompletedAt), g.TotalGames, g.GamesWon, g.TotalPoints}
+		},
+	}
+}
+
+// gameStateTable backs up hangman_state or missing_letter_state, which share a layout
+func gameStateTable(name string) backupTable {
+	return &tableSpec[GameStateBackup]{
+		name:         name,
+		selectQuery:  "SELECT kid_id, session_id, COALESCE(current_word_idx, 0), words_json, COALESCE(points_so_far, 0), updated_at FROM " + name,
+		orderBy:      "kid_id",
+		changedSince: []string{"updated_at"},
+		columns:      []string{"kid_id", "session_id", "current_word_idx", "words_json", "points_so_far", "updated_at"},
+		keys:         []string{"kid_id"},
+		scan: func(rows *sql.Rows) (GameStateBackup, error) {
+			var g GameStateBackup
+			err := rows.Scan(&g.KidID, &g.SessionID, &g.CurrentWordIdx, &g.WordsJSON, &g.PointsSoFar, &g.UpdatedAt)
+			return g, err
+		},
+		values: func(g GameStateBackup) []interface{} {
+			return []interface{}{g.KidID, g.SessionID, g.CurrentWordIdx, g.WordsJSON, g.PointsSoFar, g.UpdatedAt}
+		},
+	}
+}
+
+// findBackupTable returns the table with the given name, or nil
+func findBackupTable(name string) backupTable {
+	for _, table := range backupTables {
+		if table.tableName() == name {
+			return table
+		}
+	}
+	return nil
+}
+
+func nullableString(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func nullableInt64(n *int64) interface{} {
+	if n == nil {
+		return nil
+	}
+	return *n
+}
+
+func nullableBool(b *bool) interface{} {
+	if b == nil {
+		return nil
+	}
+	return *b
+}
+
+func nullableTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
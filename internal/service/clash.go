@@ -0,0 +1,735 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"spellingclash/internal/audio"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	clashMaxRounds   = 10
+	clashHistorySize = 20
+)
+
+var (
+	ErrClashNotFound   = errors.New("clash match not found")
+	ErrClashNotRival   = errors.New("kids can only challenge kids in their family or class")
+	ErrClashListShared = errors.New("clash lists must be assigned to both kids")
+	ErrClashBusy       = errors.New("kid is already in a clash")
+	ErrClashNotOpen    = errors.New("clash match can no longer be joined")
+	ErrClashNoAudio    = errors.New("clash lists need audio to read their words out")
+)
+
+// clashTimings are how long a live match waits at each step
+type clashTimings struct {
+	inviteTTL  time.Duration // Before an unanswered challenge is withdrawn
+	startGrace time.Duration // After accepting, before starting without both kids on the match page
+	countdown  time.Duration // Before each word is read out
+	round      time.Duration // To spell each word
+	linger     time.Duration // Keeping a finished match in memory for kids still watching
+}
+
+var defaultClashTimings = clashTimings{
+	inviteTTL:  10 * time.Minute,
+	startGrace: 30 * time.Second,
+	countdown:  3 * time.Second,
+	round:      20 * time.Second,
+	linger:     time.Minute,
+}
+
+// clashWord is a word in a match and the audio file it is read out from
+type clashWord struct {
+	text      string
+	audioFile string
+}
+
+// clashRound is the round being played, or the last one
+type clashRound struct {
+	word      clashWord
+	startedAt time.Time
+	open      bool
+	answers   map[int64]string
+	winnerID  int64
+	timeMs    int
+}
+
+// liveClash is a waiting or playing match held in memory. Its fields are guarded
+// by ClashService.mu.
+type liveClash struct {
+	match       models.ClashMatch
+	listName    string
+	kids        map[int64]*models.Kid
+	shownAs     map[int64]string // How each kid is named to the other
+	words       []clashWord
+	round       int
+	current     *clashRound
+	started     bool // The first countdown has begun
+	subscribers map[chan struct{}]int64
+}
+
+// rivalOf returns the other kid in the match
+func (m *liveClash) rivalOf(kidID int64) int64 {
+	if kidID == m.match.ChallengerID {
+		return m.match.OpponentID
+	}
+	return m.match.ChallengerID
+}
+
+func (m *liveClash) ready(kidID int64) bool {
+	for _, id := range m.subscribers {
+		if id == kidID {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *liveClash) score(kidID int64) int {
+	if kidID == m.match.ChallengerID {
+		return m.match.ChallengerScore
+	}
+	return m.match.OpponentScore
+}
+
+// notify wakes every open match page without waiting for slow ones, which catch
+// up on the next state they read
+func (m *liveClash) notify() {
+	for ch := range m.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// ClashService runs live head-to-head matches between two kids in a family or
+// class, who are read the same words and race to spell each one first. Matches
+// are played in memory and recorded in the database for their history.
+type ClashService struct {
+	clashRepo       *repository.ClashRepository
+	listRepo        *repository.ListRepository
+	kidRepo         *repository.KidRepository
+	teacherKidsRepo *repository.TeacherKidRepository
+	leaderboards    *LeaderboardService
+	ttsService      *audio.TTSService
+	timings         clashTimings
+
+	mu      sync.Mutex
+	live    map[int64]*liveClash
+	playing map[int64]int64 // Kid ID to the live match they are in
+}
+
+// NewClashService creates a new clash service
+func NewClashService(clashRepo *repository.ClashRepository, listRepo *repository.ListRepository, kidRepo *repository.KidRepository, teacherKidsRepo *repository.TeacherKidRepository, leaderboards *LeaderboardService, ttsService *audio.TTSService) *ClashService {
+	return &ClashService{
+		clashRepo:       clashRepo,
+		listRepo:        listRepo,
+		kidRepo:         kidRepo,
+		teacherKidsRepo: teacherKidsRepo,
+		leaderboards:    leaderboards,
+		ttsService:      ttsService,
+		timings:         defaultClashTimings,
+		live:            make(map[int64]*liveClash),
+		playing:         make(map[int64]int64),
+	}
+}
+
+// CancelUnfinishedMatches cancels matches left waiting or playing when the server
+// last stopped, since live matches aren't kept across restarts
+func (s *ClashService) CancelUnfinishedMatches() (int64, error) {
+	return s.clashRepo.CancelUnfinished(time.Now())
+}
+
+// rivalKids returns the kids a kid may challenge: their brothers and sisters and
+// the other kids on each of their teachers' rosters
+func (s *ClashService) rivalKids(kid *models.Kid) ([]models.Kid, error) {
+	family, err := s.kidRepo.GetFamilyKids(kid.FamilyCode)
+	if err != nil {
+		return nil, err
+	}
+	teacherIDs, err := s.teacherKidsRepo.GetKidTeacherIDs(kid.ID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := family
+	for _, teacherID := range teacherIDs {
+		roster, err := s.teacherKidsRepo.GetTeacherKids(teacherID)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, roster...)
+	}
+
+	seen := map[int64]bool{kid.ID: true}
+	var rivals []models.Kid
+	for _, candidate := range candidates {
+		if seen[candidate.ID] {
+			continue
+		}
+		seen[candidate.ID] = true
+		rivals = append(rivals, candidate)
+	}
+	return rivals, nil
+}
+
+// GetRivals returns the kids a kid may challenge, named as they may see them
+func (s *ClashService) GetRivals(kid *models.Kid) ([]models.ClashRival, error) {
+	kids, err := s.rivalKids(kid)
+	if err != nil {
+		return nil, err
+	}
+	rivals := make([]models.ClashRival, 0, len(kids))
+	for i := range kids {
+		rival, err := s.rival(kid, &kids[i])
+		if err != nil {
+			return nil, err
+		}
+		rivals = append(rivals, rival)
+	}
+	return rivals, nil
+}
+
+func (s *ClashService) rival(viewer, kid *models.Kid) (models.ClashRival, error) {
+	name, err := s.leaderboards.DisplayName(viewer, kid)
+	if err != nil {
+		return models.ClashRival{}, err
+	}
+	return models.ClashRival{KidID: kid.ID, DisplayName: name, AvatarColor: kid.AvatarColor}, nil
+}
+
+// matchWords picks up to clashMaxRounds words with audio from a list in a
+// random order. Words without audio are left out, since showing them would give
+// the answer away, and their audio is generated in the background for next time.
+func (s *ClashService) matchWords(list *models.SpellingList) ([]clashWord, error) {
+	words, err := s.listRepo.GetListWords(list.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var picked []clashWord
+	var missing []models.Word
+	for _, word := range words {
+		if word.AudioFilename == "" {
+			missing = append(missing, word)
+			continue
+		}
+		picked = append(picked, clashWord{text: word.WordText, audioFile: word.AudioFilename})
+	}
+	if len(missing) > 0 && s.ttsService != nil && s.ttsService.Enabled() {
+		go s.generateAudio(list, missing)
+	}
+
+	rand.Shuffle(len(picked), func(i, j int) {
+		picked[i], picked[j] = picked[j], picked[i]
+	})
+	if len(picked) > clashMaxRounds {
+		picked = picked[:clashMaxRounds]
+	}
+	return picked, nil
+}
+
+// generateAudio generates audio for words that have none, so they can be played
+// in later matches
+func (s *ClashService) generateAudio(list *models.SpellingList, words []models.Word) {
+	for _, word := range words {
+		filename, err := s.ttsService.GenerateAudioFile(word.WordText, list.Locale)
+		if err != nil {
+			log.Printf("Warning: Failed to generate clash audio for '%s': %v", word.WordText, err)
+			continue
+		}
+		if err := s.listRepo.UpdateWordAudio(word.ID, filename); err != nil {
+			log.Printf("Warning: Failed to update audio filename for word %d: %v", word.ID, err)
+		}
+	}
+}
+
+// Challenge invites a rival to a match on a list assigned to both of them
+func (s *ClashService) Challenge(kid *models.Kid, rivalID, listID int64) (*models.ClashMatch, error) {
+	kids, err := s.rivalKids(kid)
+	if err != nil {
+		return nil, err
+	}
+	var opponent *models.Kid
+	for i := range kids {
+		if kids[i].ID == rivalID {
+			opponent = &kids[i]
+		}
+	}
+	if opponent == nil {
+		return nil, ErrClashNotRival
+	}
+
+	for _, kidID := range []int64{kid.ID, opponent.ID} {
+		assigned, err := s.listRepo.IsListAssignedToKid(listID, kidID)
+		if err != nil {
+			return nil, err
+		}
+		if !assigned {
+			return nil, ErrClashListShared
+		}
+	}
+	list, err := s.listRepo.GetListByID(listID)
+	if err != nil {
+		return nil, err
+	}
+	words, err := s.matchWords(list)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, ErrClashNoAudio
+	}
+
+	challengerName, err := s.leaderboards.DisplayName(opponent, kid)
+	if err != nil {
+		return nil, err
+	}
+	opponentName, err := s.leaderboards.DisplayName(kid, opponent)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, busy := s.playing[kid.ID]; busy {
+		return nil, ErrClashBusy
+	}
+	if _, busy := s.playing[opponent.ID]; busy {
+		return nil, ErrClashBusy
+	}
+
+	match, err := s.clashRepo.CreateMatch(listID, kid.ID, opponent.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	live := &liveClash{
+		match:       *match,
+		listName:    list.Name,
+		kids:        map[int64]*models.Kid{kid.ID: kid, opponent.ID: opponent},
+		shownAs:     map[int64]string{kid.ID: challengerName, opponent.ID: opponentName},
+		words:       words,
+		subscribers: make(map[chan struct{}]int64),
+	}
+	s.live[match.ID] = live
+	s.playing[kid.ID] = match.ID
+	s.playing[opponent.ID] = match.ID
+
+	time.AfterFunc(s.timings.inviteTTL, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if live.match.Status == models.ClashWaiting {
+			s.cancel(live)
+		}
+	})
+	return match, nil
+}
+
+// liveMatch returns a live match the kid is in. The caller must hold s.mu.
+func (s *ClashService) liveMatch(matchID, kidID int64) (*liveClash, error) {
+	live, ok := s.live[matchID]
+	if !ok || live.kids[kidID] == nil {
+		return nil, ErrClashNotFound
+	}
+	return live, nil
+}
+
+// Accept starts a match the kid was challenged to. The first word is read out
+// once both kids have the match page open, or after a grace period.
+func (s *ClashService) Accept(kidID, matchID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	live, err := s.liveMatch(matchID, kidID)
+	if err != nil {
+		return err
+	}
+	if live.match.Status != models.ClashWaiting || live.match.OpponentID != kidID {
+		return ErrClashNotOpen
+	}
+
+	now := time.Now()
+	if err := s.clashRepo.StartMatch(matchID, len(live.words), now); err != nil {
+		return err
+	}
+	live.match.Status = models.ClashPlaying
+	live.match.TotalRounds = len(live.words)
+	live.match.StartedAt = &now
+	live.notify()
+
+	time.AfterFunc(s.timings.startGrace, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.begin(live)
+	})
+	return nil
+}
+
+// Cancel declines or withdraws a challenge that hasn't been accepted yet
+func (s *ClashService) Cancel(kidID, matchID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	live, err := s.liveMatch(matchID, kidID)
+	if err != nil {
+		return err
+	}
+	if live.match.Status != models.ClashWaiting {
+		return ErrClashNotOpen
+	}
+	return s.cancel(live)
+}
+
+// cancel ends a match without a result. The caller must hold s.mu.
+func (s *ClashService) cancel(live *liveClash) error {
+	now := time.Now()
+	live.match.Status = models.ClashCancelled
+	live.match.FinishedAt = &now
+	s.release(live)
+	live.notify()
+	return s.clashRepo.CancelMatch(live.match.ID, now)
+}
+
+// release frees both kids for other matches and forgets the match once kids
+// still watching have seen how it ended. The caller must hold s.mu.
+func (s *ClashService) release(live *liveClash) {
+	for kidID := range live.kids {
+		if s.playing[kidID] == live.match.ID {
+			delete(s.playing, kidID)
+		}
+	}
+	time.AfterFunc(s.timings.linger, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.live, live.match.ID)
+	})
+}
+
+// begin starts the countdown to the first word, once. The caller must hold s.mu.
+func (s *ClashService) begin(live *liveClash) {
+	if live.started || live.match.Status != models.ClashPlaying {
+		return
+	}
+	live.started = true
+	s.scheduleRound(live)
+}
+
+// scheduleRound reads out the next word after a countdown. The caller must hold s.mu.
+func (s *ClashService) scheduleRound(live *liveClash) {
+	time.AfterFunc(s.timings.countdown, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if live.match.Status != models.ClashPlaying {
+			return
+		}
+		live.round++
+		round := &clashRound{
+			word:      live.words[live.round-1],
+			startedAt: time.Now(),
+			open:      true,
+			answers:   make(map[int64]string),
+		}
+		live.current = round
+		live.notify()
+
+		time.AfterFunc(s.timings.round, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if live.current == round && round.open {
+				s.closeRound(live, 0)
+			}
+		})
+	})
+}
+
+// closeRound ends the open round, won by winnerID unless it is 0, and moves on
+// to the next word or finishes the match. The caller must hold s.mu.
+func (s *ClashService) closeRound(live *liveClash, winnerID int64) {
+	round := live.current
+	round.open = false
+	round.winnerID = winnerID
+	if winnerID != 0 {
+		round.timeMs = int(time.Since(round.startedAt).Milliseconds())
+		if winnerID == live.match.ChallengerID {
+			live.match.ChallengerScore++
+		} else {
+			live.match.OpponentScore++
+		}
+	}
+
+	if live.round < len(live.words) {
+		live.notify()
+		s.scheduleRound(live)
+		return
+	}
+
+	now := time.Now()
+	live.match.Status = models.ClashFinished
+	live.match.FinishedAt = &now
+	if live.match.ChallengerScore != live.match.OpponentScore {
+		winnerID := live.match.ChallengerID
+		if live.match.OpponentScore > live.match.ChallengerScore {
+			winnerID = live.match.OpponentID
+		}
+		live.match.WinnerID = &winnerID
+	}
+	s.release(live)
+	live.notify()
+	if err := s.clashRepo.FinishMatch(live.match.ID, live.match.ChallengerScore, live.match.OpponentScore, live.match.WinnerID, now); err != nil {
+		log.Printf("Error recording clash match %d: %v", live.match.ID, err)
+	}
+}
+
+// Answer records a kid's one attempt at the open round's word. The first kid to
+// spell it right wins the round; otherwise it ends once both have tried.
+func (s *ClashService) Answer(kidID, matchID int64, answer string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	live, err := s.liveMatch(matchID, kidID)
+	if err != nil {
+		return err
+	}
+	round := live.current
+	if live.match.Status != models.ClashPlaying || round == nil || !round.open {
+		return nil
+	}
+	if _, answered := round.answers[kidID]; answered {
+		return nil
+	}
+
+	answer = strings.TrimSpace(answer)
+	round.answers[kidID] = answer
+	switch {
+	case strings.EqualFold(answer, round.word.text):
+		s.closeRound(live, kidID)
+	case len(round.answers) == len(live.kids):
+		s.closeRound(live, 0)
+	default:
+		live.notify()
+	}
+	return nil
+}
+
+// Subscribe opens a kid's match page on a live match. The channel receives a value
+// whenever the match changes, and the match starts once both kids are subscribed.
+func (s *ClashService) Subscribe(kidID, matchID int64) (<-chan struct{}, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	live, err := s.liveMatch(matchID, kidID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan struct{}, 1)
+	live.subscribers[ch] = kidID
+	if live.match.Status == models.ClashPlaying && live.ready(live.rivalOf(kidID)) {
+		s.begin(live)
+	}
+	live.notify()
+
+	unsubscribe := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(live.subscribers, ch)
+		live.notify()
+	}
+	return ch, unsubscribe, nil
+}
+
+// State returns a match as the kid sees it, from memory while it is live and
+// from its history afterwards
+func (s *ClashService) State(kid *models.Kid, matchID int64) (*models.ClashState, error) {
+	s.mu.Lock()
+	if live, ok := s.live[matchID]; ok && live.kids[kid.ID] != nil {
+		state := live.state(kid.ID)
+		s.mu.Unlock()
+		return state, nil
+	}
+	s.mu.Unlock()
+
+	match, err := s.clashRepo.GetMatch(matchID)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil || (match.ChallengerID != kid.ID && match.OpponentID != kid.ID) {
+		return nil, ErrClashNotFound
+	}
+	entry, err := s.historyEntry(kid, repository.ClashMatchRow{ClashMatch: *match}, map[int64]*models.Kid{})
+	if err != nil {
+		return nil, err
+	}
+	return &models.ClashState{
+		MatchID:     match.ID,
+		Status:      match.Status,
+		Round:       match.TotalRounds,
+		TotalRounds: match.TotalRounds,
+		You:         models.ClashPlayer{Name: kid.Name, AvatarColor: kid.AvatarColor, Score: entry.YourScore},
+		Rival:       models.ClashPlayer{Name: entry.Rival.DisplayName, AvatarColor: entry.Rival.AvatarColor, Score: entry.TheirScore},
+		Result:      entry.Result,
+	}, nil
+}
+
+// state builds the match as a kid sees it. The caller must hold s.mu.
+func (m *liveClash) state(kidID int64) *models.ClashState {
+	rivalID := m.rivalOf(kidID)
+	you, rival := m.kids[kidID], m.kids[rivalID]
+	state := &models.ClashState{
+		MatchID:     m.match.ID,
+		Status:      m.match.Status,
+		Round:       m.round,
+		TotalRounds: m.match.TotalRounds,
+		You:         models.ClashPlayer{Name: you.Name, AvatarColor: you.AvatarColor, Score: m.score(kidID), Ready: m.ready(kidID)},
+		Rival:       models.ClashPlayer{Name: m.shownAs[rivalID], AvatarColor: rival.AvatarColor, Score: m.score(rivalID), Ready: m.ready(rivalID)},
+	}
+
+	if round := m.current; round != nil {
+		_, state.Answered = round.answers[kidID]
+		if round.open {
+			state.RoundOpen = true
+			// The audio file is named after the word, so it is served from a URL
+			// that gives nothing away
+			state.AudioURL = fmt.Sprintf("/child/clash/%d/audio/%d", m.match.ID, m.round)
+		} else {
+			state.LastRound = &models.ClashRoundResult{
+				Word:       round.word.text,
+				TimeMs:     round.timeMs,
+				YouWon:     round.winnerID == kidID,
+				YourAnswer: round.answers[kidID],
+			}
+			switch round.winnerID {
+			case kidID:
+				state.LastRound.WinnerName = you.Name
+			case rivalID:
+				state.LastRound.WinnerName = m.shownAs[rivalID]
+			}
+		}
+	}
+
+	if m.match.Status == models.ClashFinished {
+		state.Result = clashResult(m.match, kidID)
+	}
+	return state
+}
+
+// RoundAudio returns the path of the audio file for a round of a live match the
+// kid is in, once that round has started
+func (s *ClashService) RoundAudio(kidID, matchID int64, round int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	live, err := s.liveMatch(matchID, kidID)
+	if err != nil {
+		return "", err
+	}
+	if round < 1 || round > live.round || s.ttsService == nil {
+		return "", ErrClashNotFound
+	}
+	return s.ttsService.AudioPath(live.words[round-1].audioFile), nil
+}
+
+// clashResult is how a finished match went for a kid
+func clashResult(match models.ClashMatch, kidID int64) string {
+	switch {
+	case match.Status != models.ClashFinished:
+		return ""
+	case match.WinnerID == nil:
+		return models.ClashDraw
+	case *match.WinnerID == kidID:
+		return models.ClashWon
+	default:
+		return models.ClashLost
+	}
+}
+
+// ActiveMatch returns the ID of the match a kid is playing, or 0 if they aren't
+// playing one
+func (s *ClashService) ActiveMatch(kidID int64) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	matchID, ok := s.playing[kidID]
+	if !ok || s.live[matchID].match.Status != models.ClashPlaying {
+		return 0
+	}
+	return matchID
+}
+
+// GetInvites returns the challenges a kid has sent or received that are waiting
+// to be accepted
+func (s *ClashService) GetInvites(kidID int64) []models.ClashInvite {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	matchID, ok := s.playing[kidID]
+	if !ok || s.live[matchID].match.Status != models.ClashWaiting {
+		return nil
+	}
+	live := s.live[matchID]
+	rivalID := live.rivalOf(kidID)
+	return []models.ClashInvite{{
+		MatchID:  matchID,
+		ListName: live.listName,
+		Rival: models.ClashRival{
+			KidID:       rivalID,
+			DisplayName: live.shownAs[rivalID],
+			AvatarColor: live.kids[rivalID].AvatarColor,
+		},
+		Incoming:  live.match.OpponentID == kidID,
+		CreatedAt: live.match.CreatedAt,
+	}}
+}
+
+// GetHistory returns the matches a kid has finished, newest first
+func (s *ClashService) GetHistory(kid *models.Kid) ([]models.ClashHistoryEntry, error) {
+	rows, err := s.clashRepo.GetKidMatches(kid.ID, clashHistorySize, models.ClashFinished)
+	if err != nil {
+		return nil, err
+	}
+	kids := make(map[int64]*models.Kid)
+	history := make([]models.ClashHistoryEntry, 0, len(rows))
+	for _, row := range rows {
+		entry, err := s.historyEntry(kid, row, kids)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+	return history, nil
+}
+
+// historyEntry describes a recorded match from a kid's side, looking up rivals
+// not already in kids
+func (s *ClashService) historyEntry(kid *models.Kid, row repository.ClashMatchRow, kids map[int64]*models.Kid) (models.ClashHistoryEntry, error) {
+	entry := models.ClashHistoryEntry{
+		Match:      row.ClashMatch,
+		ListName:   row.ListName,
+		YourScore:  row.ChallengerScore,
+		TheirScore: row.OpponentScore,
+		Result:     clashResult(row.ClashMatch, kid.ID),
+	}
+	rivalID := row.OpponentID
+	if row.OpponentID == kid.ID {
+		rivalID = row.ChallengerID
+		entry.YourScore, entry.TheirScore = entry.TheirScore, entry.YourScore
+	}
+
+	rivalKid, ok := kids[rivalID]
+	if !ok {
+		var err error
+		if rivalKid, err = s.kidRepo.GetKidByID(rivalID); err != nil {
+			return entry, err
+		}
+		kids[rivalID] = rivalKid
+	}
+	if rivalKid == nil {
+		entry.Rival = models.ClashRival{KidID: rivalID, DisplayName: "Someone"}
+		return entry, nil
+	}
+	rival, err := s.rival(kid, rivalKid)
+	if err != nil {
+		return entry, err
+	}
+	entry.Rival = rival
+	return entry, nil
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"spellingclash/internal/audio"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"testing"
+	"time"
+)
+
+// newClashTestService seeds Ada and Ben in FAM1, Cy in FAM2 in Ada's class, and
+// Dee in FAM3, with a two-word list assigned to Ada and Ben, and a list without
+// audio assigned to them too
+func newClashTestService(t *testing.T) (*ClashService, map[string]*models.Kid) {
+	t.Helper()
+	db := newTestDB(t)
+
+	seed := []string{
+		"INSERT INTO users (id, email, password_hash, name, is_teacher) VALUES (1, 'teacher@example.com', 'x', 'Ms Reed', 1)",
+		"INSERT INTO families (family_code) VALUES ('FAM1'), ('FAM2'), ('FAM3')",
+		"INSERT INTO kids (id, family_code, name, username, password) VALUES (1, 'FAM1', 'Ada', 'ada1', 'x'), (2, 'FAM1', 'Ben', 'ben1', 'x'), (3, 'FAM2', 'Cy', 'cy1', 'x'), (4, 'FAM3', 'Dee', 'dee1', 'x')",
+		"INSERT INTO teacher_kid_relationships (teacher_user_id, kid_id) VALUES (1, 1), (1, 3)",
+		"INSERT INTO spelling_lists (id, name, description, family_code, is_public, locale, created_by) VALUES (1, 'Week 1', '', 'FAM1', 0, 'en-GB', 1), (2, 'Week 2', '', 'FAM1', 0, 'en-GB', 1)",
+		"INSERT INTO words (id, spelling_list_id, word_text, position, audio_filename) VALUES (1, 1, 'cat', 0, 'word_cat_en-gb.mp3'), (2, 1, 'dog', 1, 'word_dog_en-gb.mp3'), (3, 2, 'fox', 0, NULL)",
+		"INSERT INTO list_assignments (spelling_list_id, kid_id, assigned_by) VALUES (1, 1, 1), (1, 2, 1), (2, 1, 1), (2, 2, 1)",
+	}
+	for _, query := range seed {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("failed to seed %q: %v", query, err)
+		}
+	}
+
+	kidRepo := repository.NewKidRepository(db)
+	teacherKidsRepo := repository.NewTeacherKidRepository(db)
+	listRepo := repository.NewListRepository(db)
+	leaderboards := NewLeaderboardService(
+		repository.NewLeaderboardRepository(db),
+		kidRepo,
+		teacherKidsRepo,
+		repository.NewUserRepository(db),
+		listRepo,
+		NewStreakService(repository.NewStreakRepository(db), time.UTC),
+	)
+	clash := NewClashService(repository.NewClashRepository(db), listRepo, kidRepo, teacherKidsRepo, leaderboards, audio.NewTTSService(t.TempDir(), audio.NewNoneProvider()))
+	clash.timings = clashTimings{
+		inviteTTL:  time.Minute,
+		startGrace: time.Minute,
+		countdown:  time.Millisecond,
+		round:      time.Minute,
+		linger:     time.Minute,
+	}
+
+	kids := make(map[string]*models.Kid)
+	for id, name := range map[int64]string{1: "Ada", 2: "Ben", 3: "Cy", 4: "Dee"} {
+		kid, err := kidRepo.GetKidByID(id)
+		if err != nil || kid == nil {
+			t.Fatalf("failed to get %s: %v", name, err)
+		}
+		kids[name] = kid
+	}
+	return clash, kids
+}
+
+// waitForState waits for a match update until the kid's view of it satisfies ok
+func waitForState(t *testing.T, clash *ClashService, updates <-chan struct{}, kid *models.Kid, matchID int64, ok func(*models.ClashState) bool) *models.ClashState {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		state, err := clash.State(kid, matchID)
+		if err != nil {
+			t.Fatalf("State() error: %v", err)
+		}
+		if ok(state) {
+			return state
+		}
+		select {
+		case <-updates:
+		case <-timeout:
+			t.Fatalf("timed out waiting for match state, last %+v", state)
+		}
+	}
+}
+
+func TestClashRivals(t *testing.T) {
+	clash, kids := newClashTestService(t)
+
+	rivals, err := clash.GetRivals(kids["Ada"])
+	if err != nil {
+		t.Fatalf("GetRivals() error: %v", err)
+	}
+	want := []models.ClashRival{{KidID: 2, DisplayName: "Ben"}, {KidID: 3, DisplayName: DefaultNickname(3)}}
+	if len(rivals) != len(want) {
+		t.Fatalf("GetRivals() = %+v, want %+v", rivals, want)
+	}
+	for i := range want {
+		if rivals[i].KidID != want[i].KidID || rivals[i].DisplayName != want[i].DisplayName {
+			t.Errorf("GetRivals()[%d] = %+v, want %+v", i, rivals[i], want[i])
+		}
+	}
+
+	if _, err := clash.Challenge(kids["Ada"], 4, 1); !errors.Is(err, ErrClashNotRival) {
+		t.Errorf("Challenge() of a stranger error = %v, want ErrClashNotRival", err)
+	}
+	if _, err := clash.Challenge(kids["Ada"], 3, 1); !errors.Is(err, ErrClashListShared) {
+		t.Errorf("Challenge() on a list the rival doesn't have error = %v, want ErrClashListShared", err)
+	}
+	if _, err := clash.Challenge(kids["Ada"], 2, 2); !errors.Is(err, ErrClashNoAudio) {
+		t.Errorf("Challenge() on a list without audio error = %v, want ErrClashNoAudio", err)
+	}
+}
+
+func TestClashMatch(t *testing.T) {
+	clash, kids := newClashTestService(t)
+	ada, ben := kids["Ada"], kids["Ben"]
+
+	match, err := clash.Challenge(ada, ben.ID, 1)
+	if err != nil {
+		t.Fatalf("Challenge() error: %v", err)
+	}
+	if _, err := clash.Challenge(ben, ada.ID, 1); !errors.Is(err, ErrClashBusy) {
+		t.Errorf("Challenge() of a busy kid error = %v, want ErrClashBusy", err)
+	}
+	invites := clash.GetInvites(ben.ID)
+	if len(invites) != 1 || !invites[0].Incoming || invites[0].Rival.DisplayName != "Ada" || invites[0].ListName != "Week 1" {
+		t.Fatalf("GetInvites() = %+v, want an incoming challenge from Ada on Week 1", invites)
+	}
+	if err := clash.Accept(ada.ID, match.ID); !errors.Is(err, ErrClashNotOpen) {
+		t.Errorf("Accept() by the challenger error = %v, want ErrClashNotOpen", err)
+	}
+	if err := clash.Accept(ben.ID, match.ID); err != nil {
+		t.Fatalf("Accept() error: %v", err)
+	}
+	if clash.ActiveMatch(ada.ID) != match.ID {
+		t.Errorf("ActiveMatch() = %d, want %d", clash.ActiveMatch(ada.ID), match.ID)
+	}
+
+	adaUpdates, adaLeave, err := clash.Subscribe(ada.ID, match.ID)
+	if err != nil {
+		t.Fatalf("Subscribe() error: %v", err)
+	}
+	defer adaLeave()
+	_, benLeave, err := clash.Subscribe(ben.ID, match.ID)
+	if err != nil {
+		t.Fatalf("Subscribe() error: %v", err)
+	}
+	defer benLeave()
+
+	// Ada spells the first word right
+	state := waitForState(t, clash, adaUpdates, ada, match.ID, func(s *models.ClashState) bool { return s.RoundOpen && s.Round == 1 })
+	if state.AudioURL != "/child/clash/1/audio/1" || !state.Rival.Ready {
+		t.Fatalf("round 1 = %+v, want the round's audio and Ben ready", state)
+	}
+	if _, err := clash.RoundAudio(ada.ID, match.ID, 2); !errors.Is(err, ErrClashNotFound) {
+		t.Errorf("RoundAudio() of a round not started yet error = %v, want ErrClashNotFound", err)
+	}
+	if _, err := clash.RoundAudio(kids["Cy"].ID, match.ID, 1); !errors.Is(err, ErrClashNotFound) {
+		t.Errorf("RoundAudio() for a kid not in the match error = %v, want ErrClashNotFound", err)
+	}
+	path, err := clash.RoundAudio(ada.ID, match.ID, 1)
+	if err != nil {
+		t.Fatalf("RoundAudio() error: %v", err)
+	}
+	word := map[string]string{"word_cat_en-gb.mp3": "cat", "word_dog_en-gb.mp3": "dog"}[filepath.Base(path)]
+	if word == "" {
+		t.Fatalf("RoundAudio() = %q, want one of the list's audio files", path)
+	}
+	if err := clash.Answer(ada.ID, match.ID, " "+word+" "); err != nil {
+		t.Fatalf("Answer() error: %v", err)
+	}
+
+	// Both miss the second
+	state = waitForState(t, clash, adaUpdates, ada, match.ID, func(s *models.ClashState) bool { return s.RoundOpen && s.Round == 2 })
+	if err := clash.Answer(ben.ID, match.ID, "xyz"); err != nil {
+		t.Fatalf("Answer() error: %v", err)
+	}
+	if err := clash.Answer(ada.ID, match.ID, "xyz"); err != nil {
+		t.Fatalf("Answer() error: %v", err)
+	}
+
+	state = waitForState(t, clash, adaUpdates, ada, match.ID, func(s *models.ClashState) bool { return s.Status == models.ClashFinished })
+	if state.Result != models.ClashWon || state.You.Score != 1 || state.Rival.Score != 0 {
+		t.Errorf("finished state = %+v, want Ada to win 1-0", state)
+	}
+	if state.LastRound == nil || state.LastRound.WinnerName != "" || state.LastRound.YourAnswer != "xyz" {
+		t.Errorf("last round = %+v, want nobody winning", state.LastRound)
+	}
+
+	history, err := clash.GetHistory(ben)
+	if err != nil {
+		t.Fatalf("GetHistory() error: %v", err)
+	}
+	if len(history) != 1 || history[0].Result != models.ClashLost || history[0].YourScore != 0 || history[0].TheirScore != 1 || history[0].Rival.DisplayName != "Ada" {
+		t.Errorf("GetHistory() = %+v, want a 0-1 loss to Ada", history)
+	}
+	if clash.ActiveMatch(ada.ID) != 0 {
+		t.Errorf("ActiveMatch() after finishing = %d, want 0", clash.ActiveMatch(ada.ID))
+	}
+}
+
+func TestClashCancel(t *testing.T) {
+	clash, kids := newClashTestService(t)
+	ada, ben := kids["Ada"], kids["Ben"]
+
+	match, err := clash.Challenge(ada, ben.ID, 1)
+	if err != nil {
+		t.Fatalf("Challenge() error: %v", err)
+	}
+	if err := clash.Cancel(ben.ID, match.ID); err != nil {
+		t.Fatalf("Cancel() error: %v", err)
+	}
+	if err := clash.Accept(ben.ID, match.ID); !errors.Is(err, ErrClashNotOpen) {
+		t.Errorf("Accept() after declining error = %v, want ErrClashNotOpen", err)
+	}
+	if invites := clash.GetInvites(ada.ID); len(invites) != 0 {
+		t.Errorf("GetInvites() after declining = %+v, want none", invites)
+	}
+	if _, err := clash.Challenge(ada, ben.ID, 1); err != nil {
+		t.Errorf("Challenge() after declining error: %v", err)
+	}
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"strings"
+	"time"
+)
+
+const (
+	// digestMaxDelay is how late a digest can go out, e.g. after the server was down
+	// at the scheduled time. Later than this and it waits for the next week.
+	digestMaxDelay = 24 * time.Hour
+
+	// digestStreakLookback bounds how far back daily streaks are counted
+	digestStreakLookback = 60 * 24 * time.Hour
+
+	digestMaxStrugglingWords = 5
+)
+
+// DigestSchedule is when weekly digests go out
+type DigestSchedule struct {
+	Weekday  time.Weekday
+	Hour     int
+	Location *time.Location
+}
+
+// ParseDigestSchedule parses a day name (e.g. "sunday"), an hour from 0 to 23 and
+// an IANA time zone name such as "Europe/London" ("" or "Local" for the server's zone)
+func ParseDigestSchedule(day string, hour int, timezone string) (DigestSchedule, error) {
+	schedule := DigestSchedule{Hour: hour, Location: time.Local}
+
+	weekday, ok := map[string]time.Weekday{
+		"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+		"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	}[strings.ToLower(strings.TrimSpace(day))]
+	if !ok {
+		return schedule, fmt.Errorf("invalid digest day %q", day)
+	}
+	schedule.Weekday = weekday
+
+	if hour < 0 || hour > 23 {
+		return schedule, fmt.Errorf("invalid digest hour %d", hour)
+	}
+
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return schedule, fmt.Errorf("invalid digest time zone: %w", err)
+		}
+		schedule.Location = loc
+	}
+	return schedule, nil
+}
+
+// LastDue returns the most recent scheduled digest time at or before now
+func (s DigestSchedule) LastDue(now time.Time) time.Time {
+	local := now.In(s.Location)
+	daysSince := (int(local.Weekday()) - int(s.Weekday) + 7) % 7
+	due := time.Date(local.Year(), local.Month(), local.Day()-daysSince, s.Hour, 0, 0, 0, s.Location)
+	if due.After(now) {
+		due = due.AddDate(0, 0, -7)
+	}
+	return due
+}
+
+// Next returns the first scheduled digest time after now
+func (s DigestSchedule) Next(now time.Time) time.Time {
+	return s.LastDue(now).AddDate(0, 0, 7)
+}
+
+// WeeklyDigest summarises a week of progress for the kids a parent or teacher looks after
+type WeeklyDigest struct {
+	User      *models.User
+	WeekStart time.Time
+	WeekEnd   time.Time
+	Kids      []KidDigest
+}
+
+// KidDigest is one kid's part of a weekly digest
+type KidDigest struct {
+	Name            string
+	Activity        models.KidActivity
+	Accuracy        int // Percentage of words answered correctly this week
+	DaysActive      int // Days this week with at least one completed session
+	CurrentStreak   int // Consecutive days with a completed session, up to the end of the week, skipping days off
+	StrugglingWords []string
+	DueSoon         []AssignmentDue // Assignments due in the coming week
+}
+
+// AssignmentDue is an assigned list with a due date
+type AssignmentDue struct {
+	ListName string
+	DueDate  time.Time
+}
+
+// DigestService builds and sends the opt-in weekly progress digest emails
+type DigestService struct {
+	digestRepo      *repository.DigestRepository
+	userRepo        *repository.UserRepository
+	familyRepo      *repository.FamilyRepository
+	kidRepo         *repository.KidRepository
+	teacherKidRepo  *repository.TeacherKidRepository
+	listRepo        *repository.ListRepository
+	practiceService *PracticeService
+	streakService   *StreakService
+	emailService    *EmailService
+	schedule        DigestSchedule
+}
+
+// NewDigestService creates a new digest service
+func NewDigestService(
+	digestRepo *repository.DigestRepository,
+	userRepo *repository.UserRepository,
+	familyRepo *repository.FamilyRepository,
+	kidRepo *repository.KidRepository,
+	teacherKidRepo *repository.TeacherKidRepository,
+	listRepo *repository.ListRepository,
+	practiceService *PracticeService,
+	streakService *StreakService,
+	emailService *EmailService,
+	schedule DigestSchedule,
+) *DigestService {
+	return &DigestService{
+		digestRepo:      digestRepo,
+		userRepo:        userRepo,
+		familyRepo:      familyRepo,
+		kidRepo:         kidRepo,
+		teacherKidRepo:  teacherKidRepo,
+		listRepo:        listRepo,
+		practiceService: practiceService,
+		streakService:   streakService,
+		emailService:    emailService,
+		schedule:        schedule,
+	}
+}
+
+// IsAvailable reports whether digests can be sent, i.e. email is configured
+func (s *DigestService) IsAvailable() bool {
+	return s.emailService != nil && s.emailService.IsEnabled()
+}
+
+// IsSubscribed reports whether a user has opted in to the weekly digest
+func (s *DigestService) IsSubscribed(userID int64) (bool, error) {
+	sub, err := s.digestRepo.GetSubscription(userID)
+	if err != nil {
+		return false, err
+	}
+	return sub != nil && sub.Enabled, nil
+}
+
+// SetSubscribed opts a user in to or out of the weekly digest
+func (s *DigestService) SetSubscribed(userID int64, subscribed bool) error {
+	return s.digestRepo.SetSubscription(userID, subscribed, time.Now())
+}
+
+// NextDigest returns when the next digest is due to go out
+func (s *DigestService) NextDigest(now time.Time) time.Time {
+	return s.schedule.Next(now)
+}
+
+// RunScheduler sends digests as they fall due until ctx is cancelled
+func (s *DigestService) RunScheduler(ctx context.Context, interval time.Duration) {
+	if !s.IsAvailable() {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if sent, err := s.SendDueDigests(ctx, time.Now()); err != nil {
+			log.Printf("Error sending weekly digests: %v", err)
+		} else if sent > 0 {
+			log.Printf("Queued %d weekly digest emails", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDueDigests queues a digest for every subscriber who hasn't had one since the
+// last scheduled time, returning how many were queued
+func (s *DigestService) SendDueDigests(ctx context.Context, now time.Time) (int, error) {
+	due := s.schedule.LastDue(now)
+	if now.Sub(due) > digestMaxDelay {
+		return 0, nil
+	}
+
+	subs, err := s.digestRepo.GetDueSubscriptions(due)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, sub := range subs {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+
+		// Claim the digest first so another server doesn't send it too
+		claimed, err := s.digestRepo.ClaimDigest(sub.UserID, due, now)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+
+		queued, err := s.sendDigest(ctx, sub.UserID, due)
+		if err != nil {
+			log.Printf("Error sending weekly digest to user %d: %v", sub.UserID, err)
+			continue
+		}
+		if queued {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// sendDigest builds and queues one user's digest, skipping users with no kids
+func (s *DigestService) sendDigest(ctx context.Context, userID int64, weekEnd time.Time) (bool, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		return false, nil
+	}
+
+	digest, err := s.BuildDigest(user, weekEnd)
+	if err != nil {
+		return false, err
+	}
+	if len(digest.Kids) == 0 {
+		return false, nil
+	}
+
+	return true, s.emailService.SendWeeklyDigestEmail(ctx, digest, "")
+}
+
+// BuildDigest summarises the week up to weekEnd for every kid in the user's families
+// and, for teachers, every kid they teach
+func (s *DigestService) BuildDigest(user *models.User, weekEnd time.Time) (*WeeklyDigest, error) {
+	digest := &WeeklyDigest{
+		User:      user,
+		WeekStart: weekEnd.AddDate(0, 0, -7),
+		WeekEnd:   weekEnd,
+	}
+
+	kids, err := s.userKids(user)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, kid := range kids {
+		kidDigest, err := s.buildKidDigest(kid, digest.WeekStart, weekEnd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build digest for kid %d: %w", kid.ID, err)
+		}
+		digest.Kids = append(digest.Kids, *kidDigest)
+	}
+	return digest, nil
+}
+
+// userKids returns the kids a user looks after, sorted by name
+func (s *DigestService) userKids(user *models.User) ([]models.Kid, error) {
+	seen := make(map[int64]bool)
+	var kids []models.Kid
+	add := func(more []models.Kid) {
+		for _, kid := range more {
+			if !seen[kid.ID] {
+				seen[kid.ID] = true
+				kids = append(kids, kid)
+			}
+		}
+	}
+
+	families, err := s.familyRepo.GetUserFamilies(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, family := range families {
+		familyKids, err := s.kidRepo.GetFamilyKids(family.FamilyCode)
+		if err != nil {
+			return nil, err
+		}
+		add(familyKids)
+	}
+
+	if user.IsTeacher {
+		teacherKids, err := s.teacherKidRepo.GetTeacherKids(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		add(teacherKids)
+	}
+
+	sort.Slice(kids, func(i, j int) bool {
+		return strings.ToLower(kids[i].Name) < strings.ToLower(kids[j].Name)
+	})
+	return kids, nil
+}
+
+// buildKidDigest summarises one kid's week
+func (s *DigestService) buildKidDigest(kid models.Kid, weekStart, weekEnd time.Time) (*KidDigest, error) {
+	activity, err := s.digestRepo.GetKidActivity(kid.ID, weekStart, weekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Days are counted in the family's time zone, with their days off
+	calendar, days, err := s.streakService.GetKidActiveDays(&kid, weekEnd.Add(-digestStreakLookback), weekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	kidDigest := &KidDigest{
+		Name:     kid.Name,
+		Activity: *activity,
+		Accuracy: activity.Accuracy(),
+	}
+	kidDigest.CurrentStreak, _ = calendar.DailyStreaks(days, weekEnd)
+	for day := weekStart.In(calendar.Location); day.Before(weekEnd); day = day.AddDate(0, 0, 1) {
+		if days[day.Format(time.DateOnly)] {
+			kidDigest.DaysActive++
+		}
+	}
+
+	struggling, err := s.practiceService.GetStrugglingWords(kid.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i, word := range struggling {
+		if i == digestMaxStrugglingWords {
+			break
+		}
+		kidDigest.StrugglingWords = append(kidDigest.StrugglingWords, word.WordText)
+	}
+
+	lists, err := s.listRepo.GetKidAssignedLists(kid.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range lists {
+		if list.AssignmentDueDate == nil {
+			continue
+		}
+		due := *list.AssignmentDueDate
+		if !due.Before(weekEnd.Add(-24*time.Hour)) && due.Before(weekEnd.AddDate(0, 0, 7)) {
+			kidDigest.DueSoon = append(kidDigest.DueSoon, AssignmentDue{ListName: list.Name, DueDate: due})
+		}
+	}
+	sort.Slice(kidDigest.DueSoon, func(i, j int) bool {
+		return kidDigest.DueSoon[i].DueDate.Before(kidDigest.DueSoon[j].DueDate)
+	})
+
+	return kidDigest, nil
+}
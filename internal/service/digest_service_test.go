@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"spellingclash/internal/email"
+	"spellingclash/internal/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDigestSchedule(t *testing.T) {
+	schedule, err := ParseDigestSchedule(" Friday ", 7, "Europe/London")
+	if err != nil {
+		t.Fatalf("ParseDigestSchedule() error: %v", err)
+	}
+	if schedule.Weekday != time.Friday || schedule.Hour != 7 || schedule.Location.String() != "Europe/London" {
+		t.Errorf("ParseDigestSchedule() = %+v, want Friday at 7 in Europe/London", schedule)
+	}
+
+	for _, tc := range []struct {
+		day      string
+		hour     int
+		timezone string
+	}{
+		{"someday", 18, ""},
+		{"sunday", 24, ""},
+		{"sunday", -1, ""},
+		{"sunday", 18, "Mars/Olympus_Mons"},
+	} {
+		if _, err := ParseDigestSchedule(tc.day, tc.hour, tc.timezone); err == nil {
+			t.Errorf("ParseDigestSchedule(%q, %d, %q) succeeded, want an error", tc.day, tc.hour, tc.timezone)
+		}
+	}
+}
+
+func TestDigestScheduleLastDue(t *testing.T) {
+	schedule := DigestSchedule{Weekday: time.Sunday, Hour: 18, Location: time.UTC}
+	sunday := time.Date(2026, 10, 11, 18, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{sunday, sunday},
+		{sunday.Add(-time.Minute), sunday.AddDate(0, 0, -7)},
+		{sunday.Add(3 * 24 * time.Hour), sunday},
+		{sunday.AddDate(0, 0, 7).Add(-time.Second), sunday},
+	} {
+		if got := schedule.LastDue(tc.now); !got.Equal(tc.want) {
+			t.Errorf("LastDue(%s) = %s, want %s", tc.now, got, tc.want)
+		}
+	}
+	if got := schedule.Next(sunday.Add(time.Hour)); !got.Equal(sunday.AddDate(0, 0, 7)) {
+		t.Errorf("Next() = %s, want the following Sunday", got)
+	}
+}
+
+func TestWeeklyDigest(t *testing.T) {
+	db := newTestDB(t)
+	weekEnd := time.Date(2026, 10, 11, 18, 0, 0, 0, time.UTC)
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	seed := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"INSERT INTO users (id, email, password_hash, name) VALUES (1, 'parent@example.com', 'x', 'Parent'), (2, 'nokids@example.com', 'x', 'No Kids')", nil},
+		{"INSERT INTO families (family_code) VALUES ('FAM1')", nil},
+		{"INSERT INTO family_members (family_code, user_id, role) VALUES ('FAM1', 1, 'parent')", nil},
+		{"INSERT INTO kids (id, family_code, name, username, password) VALUES (1, 'FAM1', 'Ada', 'ada1', 'x')", nil},
+		{"INSERT INTO spelling_lists (id, name, description, family_code, is_public, locale, created_by) VALUES (1, 'Week 1', '', 'FAM1', 0, 'en-GB', 1), (2, 'Week 2', '', 'FAM1', 0, 'en-GB', 1)", nil},
+		{"INSERT INTO words (id, spelling_list_id, word_text, position) VALUES (1, 1, 'cat', 0), (2, 1, 'dog', 1)", nil},
+		{"INSERT INTO list_assignments (spelling_list_id, kid_id, assigned_by, due_date) VALUES (1, 1, 1, ?), (2, 1, 1, ?)", []interface{}{at(10, 14, 9), at(10, 30, 9)}},
+		// Friday and Saturday this week, one the week before and one after the digest
+		{"INSERT INTO practice_sessions (id, kid_id, spelling_list_id, started_at, completed_at) VALUES (1, 1, 1, ?, ?), (2, 1, 1, ?, ?), (3, 1, 1, ?, ?), (4, 1, 1, ?, ?)",
+			[]interface{}{at(10, 9, 10), at(10, 9, 10), at(10, 10, 10), at(10, 10, 10), at(10, 1, 10), at(10, 1, 10), at(10, 11, 19), at(10, 11, 19)}},
+		{"INSERT INTO word_attempts (practice_session_id, word_id, attempt_text, is_correct, time_taken_ms, points_earned, attempted_at) VALUES (1, 1, 'kat', 0, 1000, 0, ?), (1, 1, 'catt', 0, 1000, 0, ?), (2, 2, 'dog', 1, 1000, 10, ?), (4, 2, 'dog', 1, 1000, 10, ?)",
+			[]interface{}{at(10, 9, 10), at(10, 9, 10), at(10, 10, 10), at(10, 11, 19)}},
+	}
+	for _, s := range seed {
+		if _, err := db.Exec(s.query, s.args...); err != nil {
+			t.Fatalf("failed to seed %q: %v", s.query, err)
+		}
+	}
+
+	templates, err := email.LoadTemplates(filepath.Join("..", "templates", "email"), email.Branding{AppName: "WordClash"}, "en-GB")
+	if err != nil {
+		t.Fatalf("LoadTemplates() error: %v", err)
+	}
+	mailer := &flakyMailer{}
+	emails, err := NewEmailService(mailer, templates, repository.NewEmailOutboxRepository(db), repository.NewInvitationRepository(db), "noreply@example.com", "WordClash", "https://example.com", false)
+	if err != nil {
+		t.Fatalf("NewEmailService() error: %v", err)
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	listRepo := repository.NewListRepository(db)
+	digestRepo := repository.NewDigestRepository(db)
+	digests := NewDigestService(
+		digestRepo, userRepo, repository.NewFamilyRepository(db), repository.NewKidRepository(db),
+		repository.NewTeacherKidRepository(db), listRepo,
+		NewPracticeService(repository.NewPracticeRepository(db), listRepo, repository.NewWordScheduleRepository(db), nil),
+		NewStreakService(repository.NewStreakRepository(db), time.UTC),
+		emails, DigestSchedule{Weekday: time.Sunday, Hour: 18, Location: time.UTC},
+	)
+
+	user, err := userRepo.GetUserByID(1)
+	if err != nil {
+		t.Fatalf("GetUserByID() error: %v", err)
+	}
+	digest, err := digests.BuildDigest(user, weekEnd)
+	if err != nil {
+		t.Fatalf("BuildDigest() error: %v", err)
+	}
+	if len(digest.Kids) != 1 {
+		t.Fatalf("BuildDigest() kids = %+v, want Ada", digest.Kids)
+	}
+	kid := digest.Kids[0]
+	if kid.Activity.Sessions != 2 || kid.Activity.WordsPracticed != 3 || kid.Activity.Correct != 1 || kid.Activity.Points != 10 || kid.Accuracy != 33 {
+		t.Errorf("activity = %+v (%d%%), want this week's 2 sessions at 33%%", kid.Activity, kid.Accuracy)
+	}
+	if kid.DaysActive != 2 || kid.CurrentStreak != 2 {
+		t.Errorf("days active = %d, streak = %d, want 2 and 2", kid.DaysActive, kid.CurrentStreak)
+	}
+	if len(kid.StrugglingWords) != 1 || kid.StrugglingWords[0] != "cat" {
+		t.Errorf("struggling words = %v, want [cat]", kid.StrugglingWords)
+	}
+	if len(kid.DueSoon) != 1 || kid.DueSoon[0].ListName != "Week 1" {
+		t.Errorf("due soon = %+v, want Week 1", kid.DueSoon)
+	}
+
+	// Both users subscribed a while ago, but only the one with kids gets an email
+	for _, userID := range []int64{1, 2} {
+		if err := digestRepo.SetSubscription(userID, true, weekEnd.AddDate(0, 0, -10)); err != nil {
+			t.Fatalf("SetSubscription() error: %v", err)
+		}
+	}
+	ctx := context.Background()
+	if sent, err := digests.SendDueDigests(ctx, weekEnd.Add(digestMaxDelay+time.Hour)); err != nil || sent != 0 {
+		t.Errorf("SendDueDigests() long after the scheduled time = %d, %v, want 0", sent, err)
+	}
+	if sent, err := digests.SendDueDigests(ctx, weekEnd.Add(time.Hour)); err != nil || sent != 1 {
+		t.Fatalf("SendDueDigests() = %d, %v, want 1", sent, err)
+	}
+	if sent, err := digests.SendDueDigests(ctx, weekEnd.Add(2*time.Hour)); err != nil || sent != 0 {
+		t.Errorf("SendDueDigests() again = %d, %v, want 0", sent, err)
+	}
+	if due, err := digestRepo.GetDueSubscriptions(weekEnd); err != nil || len(due) != 0 {
+		t.Errorf("GetDueSubscriptions() after sending = %+v, %v, want none", due, err)
+	}
+
+	if _, err := emails.DeliverQueuedEmails(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("DeliverQueuedEmails() error: %v", err)
+	}
+	if len(mailer.sent) != 1 || mailer.sent[0].To[0] != "parent@example.com" {
+		t.Fatalf("sent %+v, want one digest to the parent", mailer.sent)
+	}
+	if body := mailer.sent[0].TextBody; !strings.Contains(body, "Ada") || !strings.Contains(body, "cat") || !strings.Contains(body, "/account/notifications") {
+		t.Errorf("digest body = %q, want Ada's progress and a settings link", body)
+	}
+
+	// Unsubscribing stops further digests
+	if err := digests.SetSubscribed(1, false); err != nil {
+		t.Fatalf("SetSubscribed() error: %v", err)
+	}
+	if subscribed, err := digests.IsSubscribed(1); err != nil || subscribed {
+		t.Errorf("IsSubscribed() after unsubscribing = %v, %v, want false", subscribed, err)
+	}
+}
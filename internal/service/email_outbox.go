@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"spellingclash/internal/email"
+	"spellingclash/internal/models"
+	"time"
+)
+
+var (
+	ErrEmailDisabled  = errors.New("email is not configured")
+	ErrEmailNotFailed = errors.New("email not found or has not failed")
+)
+
+const (
+	// maxEmailAttempts is how many times an email is tried before it is marked failed.
+	// With the backoff below the last attempt is a little over two hours after the first.
+	maxEmailAttempts = 8
+
+	emailRetryBaseDelay = time.Minute
+	emailRetryMaxDelay  = time.Hour
+
+	// emailSendTimeout bounds a single delivery attempt; claimed emails are leased
+	// for a little longer so a slow send isn't picked up twice
+	emailSendTimeout = time.Minute
+	emailClaimLease  = 5 * time.Minute
+	emailBatchSize   = 20
+
+	// emailRetention is how long sent and failed emails are kept for the admin dashboard
+	emailRetention = 30 * 24 * time.Hour
+)
+
+// EmailQueueStatus is what the admin dashboard shows about the outbox
+type EmailQueueStatus struct {
+	Backend string
+	Stats   *models.EmailQueueStats
+	Recent  []models.OutboxEmail
+}
+
+// emailRetryDelay returns how long to wait after the given number of failed attempts:
+// 1, 2, 4, 8... minutes, capped at an hour
+func emailRetryDelay(attempts int) time.Duration {
+	delay := emailRetryBaseDelay
+	for i := 1; i < attempts && delay < emailRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, emailRetryMaxDelay)
+}
+
+// RunOutboxWorker delivers queued emails until ctx is cancelled. It checks the queue
+// every interval and straight away whenever an email is queued.
+func (s *EmailService) RunOutboxWorker(ctx context.Context, interval time.Duration) {
+	if !s.enabled {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Keep going while full batches come back so a backlog drains quickly
+		for {
+			sent, err := s.DeliverQueuedEmails(ctx, time.Now())
+			if err != nil {
+				log.Printf("Error delivering queued emails: %v", err)
+				break
+			}
+			if sent < emailBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// DeliverQueuedEmails makes one attempt at each email that is due, returning how
+// many were attempted
+func (s *EmailService) DeliverQueuedEmails(ctx context.Context, now time.Time) (int, error) {
+	due, err := s.outboxRepo.ClaimDueEmails(now, now.Add(emailClaimLease), emailBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, queued := range due {
+		if ctx.Err() != nil {
+			// The lease runs out and the email is retried after a restart
+			return 0, ctx.Err()
+		}
+		s.deliver(ctx, queued)
+	}
+	return len(due), nil
+}
+
+// deliver makes one attempt to send a queued email and records the outcome
+func (s *EmailService) deliver(ctx context.Context, queued models.OutboxEmail) {
+	sendCtx, cancel := context.WithTimeout(ctx, emailSendTimeout)
+	sendErr := s.sendEmail(sendCtx, queued.ToEmail, queued.Subject, queued.HTMLBody, queued.TextBody)
+	cancel()
+
+	attemptedAt := time.Now()
+	attempts := queued.Attempts + 1
+	status := models.EmailStatusSent
+	nextAttemptAt := attemptedAt
+	switch {
+	case sendErr == nil:
+	case errors.Is(sendErr, email.ErrInvalidMessage) || attempts >= maxEmailAttempts:
+		status = models.EmailStatusFailed
+		log.Printf("Giving up on email %d to %s after %d attempts: %v", queued.ID, queued.ToEmail, attempts, sendErr)
+	default:
+		status = models.EmailStatusPending
+		nextAttemptAt = attemptedAt.Add(emailRetryDelay(attempts))
+		log.Printf("Email %d to %s failed (attempt %d of %d), retrying at %s: %v",
+			queued.ID, queued.ToEmail, attempts, maxEmailAttempts, nextAttemptAt.Format(time.RFC3339), sendErr)
+	}
+
+	if err := s.outboxRepo.RecordAttempt(queued.ID, s.mailer.Name(), sendErr, status, nextAttemptAt, attemptedAt); err != nil {
+		log.Printf("Error recording attempt for email %d: %v", queued.ID, err)
+	}
+
+	if queued.InvitationID != nil && status != models.EmailStatusPending {
+		var errorMsg *string
+		if sendErr != nil {
+			msg := sendErr.Error()
+			errorMsg = &msg
+		}
+		if err := s.invitationRepo.UpdateEmailStatus(*queued.InvitationID, sendErr == nil, errorMsg); err != nil {
+			log.Printf("Error updating invitation %d email status: %v", *queued.InvitationID, err)
+		}
+	}
+}
+
+// GetQueueStatus summarises the outbox and lists the most recent emails
+func (s *EmailService) GetQueueStatus(limit int) (*EmailQueueStatus, error) {
+	status := &EmailQueueStatus{Backend: s.mailer.Name()}
+	stats, err := s.outboxRepo.GetQueueStats()
+	if err != nil {
+		return nil, err
+	}
+	recent, err := s.outboxRepo.GetRecentEmails(limit)
+	if err != nil {
+		return nil, err
+	}
+	status.Stats = stats
+	status.Recent = recent
+	return status, nil
+}
+
+// RetryEmail puts a failed email back in the queue
+func (s *EmailService) RetryEmail(emailID int64) error {
+	if !s.enabled {
+		return ErrEmailDisabled
+	}
+	retried, err := s.outboxRepo.RetryEmail(emailID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !retried {
+		return ErrEmailNotFailed
+	}
+
+	s.wakeWorker()
+	return nil
+}
+
+// wakeWorker tells the outbox worker there is an email to send
+func (s *EmailService) wakeWorker() {
+	select {
+	case s.wake <- struct{}{}:
+	default: // The worker has already been woken
+	}
+}
+
+// CleanupOldEmails deletes sent and failed emails past the retention period
+func (s *EmailService) CleanupOldEmails() (int64, error) {
+	return s.outboxRepo.DeleteFinishedEmails(time.Now().Add(-emailRetention))
+}
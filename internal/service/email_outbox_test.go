@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"spellingclash/internal/email"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"testing"
+	"time"
+)
+
+// flakyMailer fails the first failures sends, then succeeds
+type flakyMailer struct {
+	failures int
+	sent     []*email.Message
+}
+
+func (m *flakyMailer) Name() string { return "flaky" }
+
+func (m *flakyMailer) Send(ctx context.Context, msg *email.Message) error {
+	if m.failures != 0 {
+		m.failures--
+		return errors.New("connection refused")
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestEmailRetryDelay(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		4:  8 * time.Minute,
+		7:  time.Hour,
+		20: time.Hour,
+	} {
+		if got := emailRetryDelay(attempts); got != want {
+			t.Errorf("emailRetryDelay(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestEmailOutbox(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := db.Exec("INSERT INTO users (id, email, password_hash, name) VALUES (1, 'admin@example.com', 'x', 'Admin')"); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	templates, err := email.LoadTemplates(filepath.Join("..", "templates", "email"), email.Branding{AppName: "WordClash"}, "en-GB")
+	if err != nil {
+		t.Fatalf("LoadTemplates() error: %v", err)
+	}
+
+	outboxRepo := repository.NewEmailOutboxRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	mailer := &flakyMailer{failures: 1}
+	emails, err := NewEmailService(mailer, templates, outboxRepo, invitationRepo, "noreply@example.com", "WordClash", "https://example.com", false)
+	if err != nil {
+		t.Fatalf("NewEmailService() error: %v", err)
+	}
+	ctx := context.Background()
+
+	invitation, err := invitationRepo.CreateInvitation("new@example.com", 1, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("CreateInvitation() error: %v", err)
+	}
+	if err := emails.SendInvitationEmail(ctx, invitation.ID, invitation.Email, "Admin", invitation.Code, invitation.ExpiresAt, ""); err != nil {
+		t.Fatalf("SendInvitationEmail() error: %v", err)
+	}
+
+	// The first attempt fails and the email waits for a retry
+	now := time.Now()
+	if n, err := emails.DeliverQueuedEmails(ctx, now); err != nil || n != 1 {
+		t.Fatalf("DeliverQueuedEmails() = %d, %v, want 1 attempt", n, err)
+	}
+	status, err := emails.GetQueueStatus(10)
+	if err != nil {
+		t.Fatalf("GetQueueStatus() error: %v", err)
+	}
+	if status.Stats.Pending != 1 || status.Stats.Retrying != 1 || status.Stats.OldestPending == nil {
+		t.Errorf("after a failed attempt stats = %+v, want 1 pending and retrying", status.Stats)
+	}
+	queued := status.Recent[0]
+	if queued.Attempts != 1 || queued.LastError == nil || queued.NextAttemptAt.Before(now.Add(emailRetryBaseDelay)) {
+		t.Errorf("after a failed attempt email = %+v, want a retry in a minute", queued)
+	}
+	if n, _ := emails.DeliverQueuedEmails(ctx, now); n != 0 {
+		t.Errorf("DeliverQueuedEmails() before the retry is due attempted %d emails, want 0", n)
+	}
+
+	// The retry succeeds and the invitation is marked as sent
+	if n, err := emails.DeliverQueuedEmails(ctx, now.Add(2*emailRetryBaseDelay)); err != nil || n != 1 {
+		t.Fatalf("DeliverQueuedEmails() retry = %d, %v, want 1 attempt", n, err)
+	}
+	if len(mailer.sent) != 1 || mailer.sent[0].To[0] != "new@example.com" {
+		t.Fatalf("sent %+v, want the invitation", mailer.sent)
+	}
+	attempts, err := outboxRepo.GetEmailAttempts(queued.ID)
+	if err != nil || len(attempts) != 2 || attempts[0].Success || !attempts[1].Success || attempts[1].Backend != "flaky" {
+		t.Errorf("GetEmailAttempts() = %+v, %v, want a failure then a success", attempts, err)
+	}
+	invitation, err = invitationRepo.GetInvitationByID(invitation.ID)
+	if err != nil || !invitation.EmailSent {
+		t.Errorf("invitation = %+v, %v, want its email marked sent", invitation, err)
+	}
+
+	// An email that keeps failing is given up on after maxEmailAttempts
+	mailer.failures = -1
+	if err := emails.SendPasswordResetEmail(ctx, "parent@example.com", "Parent", "token", ""); err != nil {
+		t.Fatalf("SendPasswordResetEmail() error: %v", err)
+	}
+	for i := 1; i <= maxEmailAttempts+1; i++ {
+		emails.DeliverQueuedEmails(ctx, now.Add(time.Duration(i)*24*time.Hour))
+	}
+	status, err = emails.GetQueueStatus(10)
+	if err != nil {
+		t.Fatalf("GetQueueStatus() error: %v", err)
+	}
+	failed := status.Recent[0]
+	if status.Stats.Failed != 1 || !failed.IsFailed() || failed.Attempts != maxEmailAttempts {
+		t.Fatalf("after repeated failures email = %+v, want failed after %d attempts", failed, maxEmailAttempts)
+	}
+	if err := emails.RetryEmail(queued.ID); !errors.Is(err, ErrEmailNotFailed) {
+		t.Errorf("RetryEmail() on a sent email error = %v, want ErrEmailNotFailed", err)
+	}
+
+	// An admin retry puts it back in the queue straight away
+	mailer.failures = 0
+	if err := emails.RetryEmail(failed.ID); err != nil {
+		t.Fatalf("RetryEmail() error: %v", err)
+	}
+	if n, err := emails.DeliverQueuedEmails(ctx, time.Now().Add(time.Second)); err != nil || n != 1 {
+		t.Fatalf("DeliverQueuedEmails() after retry = %d, %v, want 1 attempt", n, err)
+	}
+	if len(mailer.sent) != 2 || mailer.sent[1].Subject == "" {
+		t.Errorf("sent %d emails, want the password reset too", len(mailer.sent))
+	}
+}
+
+func TestClaimDueEmailsLeasesEmails(t *testing.T) {
+	db := newTestDB(t)
+	repo := repository.NewEmailOutboxRepository(db)
+	if _, err := repo.EnqueueEmail("parent@example.com", "Hello", "", "Hello\n", nil); err != nil {
+		t.Fatalf("EnqueueEmail() error: %v", err)
+	}
+
+	now := time.Now().Add(time.Second)
+	claimed, err := repo.ClaimDueEmails(now, now.Add(emailClaimLease), 10)
+	if err != nil || len(claimed) != 1 || claimed[0].Status != models.EmailStatusPending {
+		t.Fatalf("ClaimDueEmails() = %+v, %v, want the queued email", claimed, err)
+	}
+	if again, err := repo.ClaimDueEmails(now, now.Add(emailClaimLease), 10); err != nil || len(again) != 0 {
+		t.Errorf("ClaimDueEmails() while leased = %+v, %v, want nothing", again, err)
+	}
+	if expired, err := repo.ClaimDueEmails(now.Add(emailClaimLease), now.Add(2*emailClaimLease), 10); err != nil || len(expired) != 1 {
+		t.Errorf("ClaimDueEmails() after the lease ran out = %+v, %v, want the email again", expired, err)
+	}
+}
@@ -2,77 +2,110 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"net/url"
+	"strings"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/sesv2"
-	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"spellingclash/internal/email"
+	"spellingclash/internal/repository"
 )
 
-// EmailService handles sending emails via Amazon SES
+// EmailService renders the application's emails from templates and hands them to the configured mailer
 type EmailService struct {
-	client     *sesv2.Client
-	fromEmail  string
-	fromName   string
-	appBaseURL string
-	enabled    bool
-	debug      bool
+	mailer         email.Mailer
+	templates      *email.Templates
+	outboxRepo     *repository.EmailOutboxRepository
+	invitationRepo *repository.InvitationRepository
+	fromEmail      string
+	fromName       string
+	appBaseURL     string
+	enabled        bool
+	debug          bool
+	wake           chan struct{} // Nudges the outbox worker when an email is queued
 }
 
-// NewEmailService creates a new email service
-func NewEmailService(awsRegion, fromEmail, fromName, appBaseURL string, debug bool) (*EmailService, error) {
-	// If fromEmail is empty, create a disabled service
-	if fromEmail == "" {
-		log.Println("Email service disabled: SES_FROM_EMAIL not configured")
+// Template data for each email. Templates see these as .Data and the branding as .Brand.
+type passwordResetEmailData struct {
+	Name      string
+	ResetLink string
+}
+
+type welcomeEmailData struct {
+	Name      string
+	LoginLink string
+}
+
+type invitationEmailData struct {
+	InviterName   string
+	RegisterLink  string
+	ExpiresInDays int
+}
+
+type kidLoginAlertEmailData struct {
+	Name         string
+	KidName      string
+	ChildrenLink string
+	SessionsLink string
+}
+
+type weeklyDigestEmailData struct {
+	Name          string
+	WeekStart     time.Time
+	WeekEnd       time.Time
+	Kids          []KidDigest
+	DashboardLink string
+	SettingsLink  string
+}
+
+// NewEmailService creates a new email service that renders emails from templates and
+// queues them in the outbox for delivery through the given mailer.
+// A "none" mailer creates a disabled service.
+func NewEmailService(mailer email.Mailer, templates *email.Templates, outboxRepo *repository.EmailOutboxRepository, invitationRepo *repository.InvitationRepository, fromEmail, fromName, appBaseURL string, debug bool) (*EmailService, error) {
+	if _, disabled := mailer.(*email.NoneMailer); disabled {
+		log.Println("Email service disabled: no email backend configured, emails will not be sent")
 		if debug {
 			log.Println("[DEBUG] Email service will skip sending all emails")
 		}
 		return &EmailService{
-			enabled: false,
-			debug:   debug,
+			mailer:     mailer,
+			outboxRepo: outboxRepo,
+			enabled:    false,
+			debug:      debug,
+			wake:       make(chan struct{}, 1),
 		}, nil
 	}
 
-	if debug {
-		log.Printf("[DEBUG] Initializing email service with AWS SES")
-		log.Printf("[DEBUG] AWS Region: %s", awsRegion)
-		log.Printf("[DEBUG] From Email: %s", fromEmail)
-		log.Printf("[DEBUG] From Name: %s", fromName)
-		log.Printf("[DEBUG] App Base URL: %s", appBaseURL)
+	if fromEmail == "" {
+		return nil, fmt.Errorf("EMAIL_FROM is required for the %s email backend", mailer.Name())
 	}
-
-	// Load AWS configuration
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithRegion(awsRegion),
-	)
-	if err != nil {
-		if debug {
-			log.Printf("[DEBUG] Failed to load AWS config: %v", err)
-		}
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	if templates == nil {
+		return nil, fmt.Errorf("email templates are required for the %s email backend", mailer.Name())
 	}
 
 	if debug {
-		log.Println("[DEBUG] AWS config loaded successfully")
+		log.Printf("[DEBUG] Initializing email service with the %s backend", mailer.Name())
+		log.Printf("[DEBUG] From Email: %s", fromEmail)
+		log.Printf("[DEBUG] From Name: %s", fromName)
+		log.Printf("[DEBUG] App Base URL: %s", appBaseURL)
 	}
 
-	// Create SES client
-	client := sesv2.NewFromConfig(cfg)
-
-	log.Printf("Email service enabled: from=%s, region=%s", fromEmail, awsRegion)
-	if debug {
-		log.Println("[DEBUG] SES client created successfully")
-	}
+	log.Printf("Email service enabled: backend=%s, from=%s", mailer.Name(), fromEmail)
 
 	return &EmailService{
-		client:     client,
-		fromEmail:  fromEmail,
-		fromName:   fromName,
-		appBaseURL: appBaseURL,
-		enabled:    true,
-		debug:      debug,
+		mailer:         mailer,
+		templates:      templates,
+		outboxRepo:     outboxRepo,
+		invitationRepo: invitationRepo,
+		fromEmail:      fromEmail,
+		fromName:       fromName,
+		appBaseURL:     strings.TrimSuffix(appBaseURL, "/"),
+		enabled:        true,
+		debug:          debug,
+		wake:           make(chan struct{}, 1),
 	}, nil
 }
 
@@ -81,236 +114,154 @@ func (s *EmailService) IsEnabled() bool {
 	return s.enabled
 }
 
-// SendPasswordResetEmail sends a password reset email with a reset link
-func (s *EmailService) SendPasswordResetEmail(ctx context.Context, toEmail, toName, resetToken string) error {
+// SendPasswordResetEmail queues a password reset email with a reset link.
+// The locale picks the translation, e.g. from the requester's Accept-Language header;
+// an empty locale uses the default.
+func (s *EmailService) SendPasswordResetEmail(ctx context.Context, toEmail, toName, resetToken, locale string) error {
 	if s.debug {
-		log.Printf("[DEBUG] SendPasswordResetEmail called: to=%s, name=%s, token=%s", toEmail, toName, resetToken)
+		log.Printf("[DEBUG] SendPasswordResetEmail called: to=%s, name=%s, token=%s, locale=%s", toEmail, toName, resetToken, locale)
 	}
 
-	if !s.enabled {
-		log.Printf("Skipping email send (service disabled): password reset to %s", toEmail)
-		if s.debug {
-			log.Printf("[DEBUG] Email service is disabled, no email will be sent")
-		}
-		return nil
+	return s.queueTemplate(toEmail, "password_reset", locale, passwordResetEmailData{
+		Name:      toName,
+		ResetLink: fmt.Sprintf("%s/auth/reset-password?token=%s", s.appBaseURL, url.QueryEscape(resetToken)),
+	}, nil)
+}
+
+// SendWelcomeEmail queues a welcome email to new users
+func (s *EmailService) SendWelcomeEmail(ctx context.Context, toEmail, toName, locale string) error {
+	if s.debug {
+		log.Printf("[DEBUG] SendWelcomeEmail called: to=%s, name=%s, locale=%s", toEmail, toName, locale)
 	}
 
-	resetLink := fmt.Sprintf("%s/auth/reset-password?token=%s", s.appBaseURL, resetToken)
+	return s.queueTemplate(toEmail, "welcome", locale, welcomeEmailData{
+		Name:      toName,
+		LoginLink: s.appBaseURL + "/login",
+	}, nil)
+}
+
+// SendInvitationEmail queues an invitation email with a registration link. The
+// invitation's email status is updated once the email is delivered or given up on.
+func (s *EmailService) SendInvitationEmail(ctx context.Context, invitationID int64, toEmail, inviterName, invitationCode string, expiresAt time.Time, locale string) error {
 	if s.debug {
-		log.Printf("[DEBUG] Reset link generated: %s", resetLink)
+		log.Printf("[DEBUG] SendInvitationEmail called: to=%s, inviter=%s, locale=%s", toEmail, inviterName, locale)
 	}
 
-	subject := "Reset Your WordClash Password"
-	htmlBody := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-	<meta charset="UTF-8">
-	<style>
-		body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-		.container { max-width: 600px; margin: 0 auto; padding: 20px; }
-		.header { background-color: #4a90e2; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
-		.content { background-color: #f9f9f9; padding: 30px; border-radius: 0 0 5px 5px; }
-		.button { display: inline-block; padding: 12px 30px; background-color: #4a90e2; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
-		.footer { text-align: center; margin-top: 20px; font-size: 12px; color: #666; }
-	</style>
-</head>
-<body>
-	<div class="container">
-		<div class="header">
-			<h1>Password Reset Request</h1>
-		</div>
-		<div class="content">
-			<p>Hi %s,</p>
-			<p>We received a request to reset your password for your WordClash account.</p>
-			<p>Click the button below to reset your password:</p>
-			<p style="text-align: center;">
-				<a href="%s" class="button">Reset Password</a>
-			</p>
-			<p>Or copy and paste this link into your browser:</p>
-			<p style="word-break: break-all; font-size: 12px; color: #666;">%s</p>
-			<p><strong>This link will expire in 1 hour.</strong></p>
-			<p>If you didn't request a password reset, you can safely ignore this email.</p>
-		</div>
-		<div class="footer">
-			<p>This is an automated email from WordClash. Please do not reply.</p>
-		</div>
-	</div>
-</body>
-</html>
-`, toName, resetLink, resetLink)
-
-	textBody := fmt.Sprintf(`Hi %s,
-
-We received a request to reset your password for your WordClash account.
-
-Click the link below to reset your password:
-%s
-
-This link will expire in 1 hour.
-
-If you didn't request a password reset, you can safely ignore this email.
-
----
-This is an automated email from WordClash. Please do not reply.
-`, toName, resetLink)
+	// Round up so an invitation created just now still reads "7 days"
+	days := int(math.Ceil(time.Until(expiresAt).Hours() / 24))
+	if days < 1 {
+		days = 1
+	}
 
+	return s.queueTemplate(toEmail, "invitation", locale, invitationEmailData{
+		InviterName:   inviterName,
+		RegisterLink:  fmt.Sprintf("%s/register?invite=%s", s.appBaseURL, url.QueryEscape(invitationCode)),
+		ExpiresInDays: days,
+	}, &invitationID)
+}
+
+// SendWeeklyDigestEmail queues a weekly progress digest
+func (s *EmailService) SendWeeklyDigestEmail(ctx context.Context, digest *WeeklyDigest, locale string) error {
 	if s.debug {
-		log.Printf("[DEBUG] Sending password reset email: subject=%s, to=%s", subject, toEmail)
-		log.Printf("[DEBUG] HTML body length: %d bytes", len(htmlBody))
-		log.Printf("[DEBUG] Text body length: %d bytes", len(textBody))
+		log.Printf("[DEBUG] SendWeeklyDigestEmail called: to=%s, kids=%d, locale=%s", digest.User.Email, len(digest.Kids), locale)
 	}
 
-	return s.sendEmail(ctx, toEmail, subject, htmlBody, textBody)
+	dashboard := "/parent/dashboard"
+	if digest.User.IsTeacher {
+		dashboard = "/teacher/dashboard"
+	}
+
+	return s.queueTemplate(digest.User.Email, "weekly_digest", locale, weeklyDigestEmailData{
+		Name:          digest.User.Name,
+		WeekStart:     digest.WeekStart,
+		WeekEnd:       digest.WeekEnd,
+		Kids:          digest.Kids,
+		DashboardLink: s.appBaseURL + dashboard,
+		SettingsLink:  s.appBaseURL + "/account/notifications",
+	}, nil)
 }
 
-// SendWelcomeEmail sends a welcome email to new users
-func (s *EmailService) SendWelcomeEmail(ctx context.Context, toEmail, toName string) error {
+// SendKidLoginAlertEmail queues an email telling a parent that someone has been
+// guessing their child's password
+func (s *EmailService) SendKidLoginAlertEmail(ctx context.Context, toEmail, toName, kidName, locale string) error {
 	if s.debug {
-		log.Printf("[DEBUG] SendWelcomeEmail called: to=%s, name=%s", toEmail, toName)
+		log.Printf("[DEBUG] SendKidLoginAlertEmail called: to=%s, kid=%s, locale=%s", toEmail, kidName, locale)
 	}
 
+	return s.queueTemplate(toEmail, "kid_login_alert", locale, kidLoginAlertEmailData{
+		Name:         toName,
+		KidName:      kidName,
+		ChildrenLink: s.appBaseURL + "/parent/children",
+		SessionsLink: s.appBaseURL + "/account/sessions",
+	}, nil)
+}
+
+// queueTemplate renders the named email template and adds it to the outbox
+func (s *EmailService) queueTemplate(toEmail, name, locale string, data any, invitationID *int64) error {
 	if !s.enabled {
-		log.Printf("Skipping email send (service disabled): welcome to %s", toEmail)
+		log.Printf("Skipping email send (service disabled): %s to %s", name, toEmail)
 		if s.debug {
 			log.Printf("[DEBUG] Email service is disabled, no email will be sent")
 		}
 		return nil
 	}
 
-	subject := "Welcome to WordClash!"
-	htmlBody := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-	<meta charset="UTF-8">
-	<style>
-		body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-		.container { max-width: 600px; margin: 0 auto; padding: 20px; }
-		.header { background-color: #4a90e2; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
-		.content { background-color: #f9f9f9; padding: 30px; border-radius: 0 0 5px 5px; }
-		.button { display: inline-block; padding: 12px 30px; background-color: #4a90e2; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
-		.footer { text-align: center; margin-top: 20px; font-size: 12px; color: #666; }
-	</style>
-</head>
-<body>
-	<div class="container">
-		<div class="header">
-			<h1>Welcome to WordClash!</h1>
-		</div>
-		<div class="content">
-			<p>Hi %s,</p>
-			<p>Thank you for creating your WordClash account! We're excited to help your children improve their spelling skills through fun and engaging games.</p>
-			<p>Here's what you can do next:</p>
-			<ul>
-				<li>Add children to your family account</li>
-				<li>Create custom spelling lists</li>
-				<li>Track your children's progress</li>
-				<li>Let your children practice with interactive games</li>
-			</ul>
-			<p style="text-align: center;">
-				<a href="%s/login" class="button">Get Started</a>
-			</p>
-		</div>
-		<div class="footer">
-			<p>This is an automated email from WordClash. Please do not reply.</p>
-		</div>
-	</div>
-</body>
-</html>
-`, toName, s.appBaseURL)
-
-	textBody := fmt.Sprintf(`Hi %s,
-
-Thank you for creating your WordClash account! We're excited to help your children improve their spelling skills through fun and engaging games.
-
-Here's what you can do next:
-- Add children to your family account
-- Create custom spelling lists
-- Track your children's progress
-- Let your children practice with interactive games
-
-Get started: %s/login
-
----
-This is an automated email from WordClash. Please do not reply.
-`, toName, s.appBaseURL)
+	subject, htmlBody, textBody, err := s.templates.Render(name, locale, data)
+	if err != nil {
+		return fmt.Errorf("failed to render %s email: %w", name, err)
+	}
 
 	if s.debug {
-		log.Printf("[DEBUG] Sending welcome email: subject=%s, to=%s", subject, toEmail)
+		log.Printf("[DEBUG] Rendered %s email: subject=%s, to=%s", name, subject, toEmail)
 		log.Printf("[DEBUG] HTML body length: %d bytes", len(htmlBody))
 		log.Printf("[DEBUG] Text body length: %d bytes", len(textBody))
 	}
 
-	return s.sendEmail(ctx, toEmail, subject, htmlBody, textBody)
+	queued, err := s.outboxRepo.EnqueueEmail(toEmail, subject, htmlBody, textBody, invitationID)
+	if err != nil {
+		return err
+	}
+	if s.debug {
+		log.Printf("[DEBUG] Queued %s email %d", name, queued.ID)
+	}
+
+	s.wakeWorker()
+	return nil
 }
 
-// sendEmail sends an email using Amazon SES
+// sendEmail sends an email through the configured mailer
 func (s *EmailService) sendEmail(ctx context.Context, toEmail, subject, htmlBody, textBody string) error {
 	if s.debug {
 		log.Printf("[DEBUG] sendEmail called: to=%s, subject=%s", toEmail, subject)
 	}
 
-	fromAddress := s.fromEmail
-	if s.fromName != "" {
-		fromAddress = fmt.Sprintf("%s <%s>", s.fromName, s.fromEmail)
+	msg := &email.Message{
+		FromEmail: s.fromEmail,
+		FromName:  s.fromName,
+		To:        []string{toEmail},
+		Subject:   subject,
+		HTMLBody:  htmlBody,
+		TextBody:  textBody,
 	}
 
 	if s.debug {
-		log.Printf("[DEBUG] From address: %s", fromAddress)
+		log.Printf("[DEBUG] From address: %s", msg.From())
 		log.Printf("[DEBUG] To address: %s", toEmail)
 		log.Printf("[DEBUG] Subject: %s", subject)
+		log.Printf("[DEBUG] Sending with the %s backend...", s.mailer.Name())
 	}
 
-	input := &sesv2.SendEmailInput{
-		FromEmailAddress: aws.String(fromAddress),
-		Destination: &types.Destination{
-			ToAddresses: []string{toEmail},
-		},
-		Content: &types.EmailContent{
-			Simple: &types.Message{
-				Subject: &types.Content{
-					Data:    aws.String(subject),
-					Charset: aws.String("UTF-8"),
-				},
-				Body: &types.Body{
-					Html: &types.Content{
-						Data:    aws.String(htmlBody),
-						Charset: aws.String("UTF-8"),
-					},
-					Text: &types.Content{
-						Data:    aws.String(textBody),
-						Charset: aws.String("UTF-8"),
-					},
-				},
-			},
-		},
-	}
-
-	if s.debug {
-		log.Printf("[DEBUG] Calling SES SendEmail API...")
-	}
-
-	result, err := s.client.SendEmail(ctx, input)
-	if err != nil {
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		if errors.Is(err, email.ErrMailDisabled) {
+			log.Printf("Skipping email send (service disabled): %s to %s", subject, toEmail)
+			return nil
+		}
 		if s.debug {
-			log.Printf("[DEBUG] SES SendEmail failed: %v", err)
+			log.Printf("[DEBUG] %s send failed: %v", s.mailer.Name(), err)
 		}
 		return fmt.Errorf("failed to send email to %s: %w", toEmail, err)
 	}
 
-	if s.debug {
-		log.Printf("[DEBUG] SES SendEmail succeeded")
-		if result.MessageId != nil {
-			log.Printf("[DEBUG] Message ID: %s", *result.MessageId)
-		}
-	}
-
-	log.Printf("Email sent successfully: to=%s, subject=%s", toEmail, subject)
+	log.Printf("Email sent successfully: to=%s, subject=%s, backend=%s", toEmail, subject, s.mailer.Name())
 	return nil
 }
-
-// SendInvitationEmail sends a custom invitation email (used by admin handler)
-func (s *EmailService) SendInvitationEmail(ctx context.Context, toEmail, subject, htmlBody, textBody string) error {
-	return s.sendEmail(ctx, toEmail, subject, htmlBody, textBody)
-}
@@ -247,6 +247,11 @@ func (s *FamilyService) RegenerateKidPassword(kidID, userID int64) (string, erro
 		return "", fmt.Errorf("failed to update kid password: %w", err)
 	}
 
+	// Sign the kid out everywhere so the old password can't keep them logged in
+	if _, err := s.kidRepo.DeleteKidSessions(kidID); err != nil {
+		return "", fmt.Errorf("failed to revoke kid sessions: %w", err)
+	}
+
 	return newPassword, nil
 }
 
@@ -332,6 +337,51 @@ func (s *FamilyService) LogoutKid(sessionID string) error {
 	return nil
 }
 
+// TouchKidSession records that a kid session was just used, and from which device
+func (s *FamilyService) TouchKidSession(sessionID, userAgent, ipAddress string) error {
+	now := time.Now()
+	return s.kidRepo.TouchKidSession(sessionID, clipSessionClient(userAgent), clipSessionClient(ipAddress), now, now.Add(-sessionTouchInterval))
+}
+
+// GetKidSessions returns a kid's active sessions, if the user has access to the kid
+func (s *FamilyService) GetKidSessions(kidID, userID int64) ([]models.KidSession, error) {
+	kid, err := s.GetKid(kidID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.VerifyFamilyAccess(userID, kid.FamilyCode); err != nil {
+		return nil, err
+	}
+	return s.kidRepo.GetActiveKidSessions(kidID)
+}
+
+// RevokeKidSession signs out one of a kid's sessions, found by its handle
+func (s *FamilyService) RevokeKidSession(kidID, userID int64, handle string) error {
+	sessions, err := s.GetKidSessions(kidID, userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.Handle() == handle {
+			return s.kidRepo.DeleteKidSession(session.ID)
+		}
+	}
+	return ErrSessionNotFound
+}
+
+// RevokeKidSessions signs a kid out on every device
+func (s *FamilyService) RevokeKidSessions(kidID, userID int64) error {
+	kid, err := s.GetKid(kidID)
+	if err != nil {
+		return err
+	}
+	if err := s.VerifyFamilyAccess(userID, kid.FamilyCode); err != nil {
+		return err
+	}
+	_, err = s.kidRepo.DeleteKidSessions(kidID)
+	return err
+}
+
 // CleanupExpiredKidSessions removes expired kid sessions
 func (s *FamilyService) CleanupExpiredKidSessions() error {
 	if err := s.kidRepo.DeleteExpiredKidSessions(); err != nil {
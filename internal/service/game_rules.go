@@ -0,0 +1,279 @@
+package service
+
+import (
+	"math/rand"
+	"sort"
+	"spellingclash/internal/models"
+	"strings"
+)
+
+const (
+	// hangmanMaxWrongGuesses is the number of wrong letters allowed per hangman word
+	hangmanMaxWrongGuesses = 6
+	// missingLetterMaxAttempts is the number of guesses allowed per missing letter word
+	missingLetterMaxAttempts = 3
+	// missingLetterMaxWords caps the number of words in a missing letter session
+	missingLetterMaxWords = 20
+)
+
+// maskHangmanWord renders a word with unguessed letters replaced by underscores
+func maskHangmanWord(word string, guessedLetters []string) string {
+	masked := ""
+	wordLower := strings.ToLower(word)
+	for _, char := range wordLower {
+		if char == ' ' {
+			masked += "  "
+			continue
+		}
+		found := false
+		for _, letter := range guessedLetters {
+			if string(char) == letter {
+				found = true
+				break
+			}
+		}
+		if found {
+			masked += string(char) + " "
+		} else {
+			masked += "_ "
+		}
+	}
+	return strings.TrimSpace(masked)
+}
+
+// hangmanPoints awards 100 points for a solved word, minus 10 per wrong guess (minimum 10)
+func hangmanPoints(wrongGuesses int) int {
+	basePoints := 100
+	penalty := wrongGuesses * 10
+	points := basePoints - penalty
+	if points < 10 {
+		return 10
+	}
+	return points
+}
+
+// applyHangmanGuess applies a lowercase letter guess to a game state, updating the
+// masked word and win/loss flags. It returns false if the letter was already guessed,
+// in which case the state is unchanged.
+func applyHangmanGuess(state *models.HangmanGameState, letter string) bool {
+	for _, l := range state.GuessedLetters {
+		if l == letter {
+			return false
+		}
+	}
+
+	state.GuessedLetters = append(state.GuessedLetters, letter)
+
+	if !strings.Contains(strings.ToLower(state.Word), letter) {
+		state.WrongGuesses++
+	}
+
+	state.MaskedWord = maskHangmanWord(state.Word, state.GuessedLetters)
+
+	if !strings.Contains(state.MaskedWord, "_") {
+		state.IsWon = true
+		state.IsComplete = true
+	} else if state.WrongGuesses >= state.MaxWrongGuesses {
+		state.IsLost = true
+		state.IsComplete = true
+	}
+
+	return true
+}
+
+// missingLetterIndices chooses which letters to hide based on word length and difficulty
+func missingLetterIndices(word string, difficulty int) []int {
+	wordLen := len(word)
+
+	// Calculate number of letters to hide based on difficulty and word length
+	var numMissing int
+
+	switch difficulty {
+	case 1: // Easy
+		if wordLen <= 4 {
+			numMissing = 1
+		} else {
+			numMissing = 2
+		}
+	case 2: // Medium-Easy
+		if wordLen <= 4 {
+			numMissing = 1
+		} else if wordLen <= 6 {
+			numMissing = 2
+		} else {
+			numMissing = 3
+		}
+	case 3: // Medium
+		if wordLen <= 4 {
+			numMissing = 1
+		} else if wordLen <= 6 {
+			numMissing = 2
+		} else if wordLen <= 8 {
+			numMissing = 3
+		} else {
+			numMissing = 4
+		}
+	case 4: // Medium-Hard
+		if wordLen <= 4 {
+			numMissing = 2
+		} else if wordLen <= 6 {
+			numMissing = 3
+		} else if wordLen <= 8 {
+			numMissing = 4
+		} else {
+			numMissing = 5
+		}
+	case 5: // Hard
+		if wordLen <= 4 {
+			numMissing = 2
+		} else if wordLen <= 6 {
+			numMissing = 3
+		} else if wordLen <= 8 {
+			numMissing = 5
+		} else {
+			numMissing = 6
+		}
+	default:
+		numMissing = 2
+	}
+
+	// Don't hide more than 50% of the word
+	maxMissing := wordLen / 2
+	if maxMissing < 1 {
+		maxMissing = 1
+	}
+	if numMissing > maxMissing {
+		numMissing = maxMissing
+	}
+
+	// For difficulty 1, avoid first and last letters
+	// For difficulty 2-3, avoid just the first letter
+	// For difficulty 4-5, any letter can be hidden
+	startIdx := 0
+	endIdx := wordLen
+
+	if difficulty == 1 && wordLen > 3 {
+		startIdx = 1
+		endIdx = wordLen - 1
+	} else if difficulty <= 3 && wordLen > 2 {
+		startIdx = 1
+	}
+
+	availableIndices := make([]int, 0)
+	for i := startIdx; i < endIdx; i++ {
+		if word[i] != ' ' { // Don't hide spaces
+			availableIndices = append(availableIndices, i)
+		}
+	}
+
+	// Shuffle and pick
+	rand.Shuffle(len(availableIndices), func(i, j int) {
+		availableIndices[i], availableIndices[j] = availableIndices[j], availableIndices[i]
+	})
+
+	indices := make([]int, 0, numMissing)
+	for i := 0; i < numMissing && i < len(availableIndices); i++ {
+		indices = append(indices, availableIndices[i])
+	}
+
+	return indices
+}
+
+// missingLetterDisplayWord renders a word with blanks for its missing letters,
+// revealing the whole word once one of the guesses is correct
+func missingLetterDisplayWord(word string, missingIndices []int, guessedWords []string) string {
+	wordLower := strings.ToLower(word)
+	for _, guess := range guessedWords {
+		if guess == wordLower {
+			return word
+		}
+	}
+
+	result := ""
+	for i, char := range word {
+		isMissing := false
+		for _, idx := range missingIndices {
+			if idx == i {
+				isMissing = true
+				break
+			}
+		}
+
+		if isMissing {
+			result += "_"
+		} else {
+			result += string(char)
+		}
+	}
+
+	return result
+}
+
+// missingLetterPoints awards 50 points per missing letter, minus 15 per extra attempt (minimum 10)
+func missingLetterPoints(attempts, numMissing int) int {
+	basePoints := 50 * numMissing // More missing letters = more points potential
+	penalty := (attempts - 1) * 15
+	points := basePoints - penalty
+	if points < 10 {
+		return 10
+	}
+	return points
+}
+
+// fillMissingLetters builds the complete word by inserting the guessed letters,
+// left to right, into the missing positions
+func fillMissingLetters(word string, missingIndices []int, guess string) string {
+	result := []rune(strings.ToLower(word))
+	guessRunes := []rune(guess)
+
+	// Sort missing indices to ensure we map left-to-right
+	sortedIndices := make([]int, len(missingIndices))
+	copy(sortedIndices, missingIndices)
+	sort.Ints(sortedIndices)
+
+	for i, idx := range sortedIndices {
+		if i < len(guessRunes) && idx < len(result) {
+			result[idx] = guessRunes[i]
+		}
+	}
+
+	return string(result)
+}
+
+// applyMissingLetterGuess applies a guess for the missing letters to a game state,
+// updating attempts, feedback flags, the display word and win/loss flags.
+// isWord reports whether a wrong guess is still a real word, which is flagged so the
+// kid can be told; it may be nil when no dictionary is available.
+// It returns true if the guess completed the word.
+func applyMissingLetterGuess(state *models.MissingLetterGameState, guess string, isWord func(string) bool) bool {
+	wordLower := strings.ToLower(state.Word)
+	guessedWord := fillMissingLetters(state.Word, state.MissingIndices, guess)
+
+	state.Attempts++
+	state.GuessedLetters = append(state.GuessedLetters, guessedWord)
+
+	// Reset feedback flags
+	state.LastGuessCorrect = nil
+	state.LastValidWordBonus = nil
+
+	correct := guessedWord == wordLower
+	state.LastGuessCorrect = &correct
+
+	if !correct && isWord != nil {
+		validWord := isWord(guessedWord)
+		state.LastValidWordBonus = &validWord
+	}
+
+	if correct {
+		state.IsWon = true
+		state.IsComplete = true
+	} else if state.Attempts >= state.MaxAttempts {
+		state.IsLost = true
+		state.IsComplete = true
+	}
+
+	// Only reveal the word once it has been guessed
+	state.DisplayWord = missingLetterDisplayWord(state.Word, state.MissingIndices, state.GuessedLetters)
+
+	return correct
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"spellingclash/internal/models"
+	"testing"
+)
+
+func TestMaskHangmanWord(t *testing.T) {
+	tests := []struct {
+		name     string
+		word     string
+		guessed  []string
+		expected string
+	}{
+		{name: "nothing guessed", word: "cat", guessed: nil, expected: "_ _ _"},
+		{name: "some guessed", word: "Cat", guessed: []string{"c", "t"}, expected: "c _ t"},
+		{name: "repeated letter", word: "book", guessed: []string{"o"}, expected: "_ o o _"},
+		{name: "phrase keeps spaces", word: "ice cream", guessed: []string{"e"}, expected: "_ _ e   _ _ e _ _"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskHangmanWord(tt.word, tt.guessed); got != tt.expected {
+				t.Errorf("maskHangmanWord() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHangmanPoints(t *testing.T) {
+	tests := []struct {
+		wrongGuesses int
+		expected     int
+	}{
+		{0, 100},
+		{3, 70},
+		{9, 10},
+		{20, 10},
+	}
+
+	for _, tt := range tests {
+		if got := hangmanPoints(tt.wrongGuesses); got != tt.expected {
+			t.Errorf("hangmanPoints(%d) = %d, want %d", tt.wrongGuesses, got, tt.expected)
+		}
+	}
+}
+
+func TestApplyHangmanGuess(t *testing.T) {
+	state := &models.HangmanGameState{Word: "cat", MaxWrongGuesses: 2}
+
+	if !applyHangmanGuess(state, "c") {
+		t.Fatal("first guess should be applied")
+	}
+	if applyHangmanGuess(state, "c") {
+		t.Error("repeated guess should be ignored")
+	}
+	if state.WrongGuesses != 0 || state.MaskedWord != "c _ _" {
+		t.Errorf("after correct guess: wrong=%d masked=%q", state.WrongGuesses, state.MaskedWord)
+	}
+
+	applyHangmanGuess(state, "z")
+	if state.WrongGuesses != 1 || state.IsComplete {
+		t.Errorf("after wrong guess: wrong=%d complete=%v", state.WrongGuesses, state.IsComplete)
+	}
+
+	applyHangmanGuess(state, "a")
+	applyHangmanGuess(state, "t")
+	if !state.IsWon || !state.IsComplete || state.IsLost {
+		t.Errorf("expected win, got won=%v lost=%v complete=%v", state.IsWon, state.IsLost, state.IsComplete)
+	}
+
+	lost := &models.HangmanGameState{Word: "cat", MaxWrongGuesses: 2}
+	applyHangmanGuess(lost, "x")
+	applyHangmanGuess(lost, "y")
+	if !lost.IsLost || !lost.IsComplete || lost.IsWon {
+		t.Errorf("expected loss, got won=%v lost=%v complete=%v", lost.IsWon, lost.IsLost, lost.IsComplete)
+	}
+}
+
+func TestMissingLetterIndices(t *testing.T) {
+	tests := []struct {
+		word       string
+		difficulty int
+		expected   int
+	}{
+		{word: "cat", difficulty: 1, expected: 1},
+		{word: "garden", difficulty: 2, expected: 2},
+		{word: "elephant", difficulty: 3, expected: 3},
+		{word: "dinosaur", difficulty: 5, expected: 4}, // capped at half the word
+	}
+
+	for _, tt := range tests {
+		indices := missingLetterIndices(tt.word, tt.difficulty)
+		if len(indices) != tt.expected {
+			t.Errorf("missingLetterIndices(%q, %d) hid %d letters, want %d", tt.word, tt.difficulty, len(indices), tt.expected)
+		}
+		for _, idx := range indices {
+			if idx < 0 || idx >= len(tt.word) {
+				t.Errorf("index %d out of range for %q", idx, tt.word)
+			}
+			if tt.difficulty <= 3 && idx == 0 {
+				t.Errorf("difficulty %d should never hide the first letter of %q", tt.difficulty, tt.word)
+			}
+		}
+	}
+
+	// Easy words keep their last letter too
+	for i := 0; i < 20; i++ {
+		for _, idx := range missingLetterIndices("rabbit", 1) {
+			if idx == len("rabbit")-1 {
+				t.Fatal("difficulty 1 should never hide the last letter")
+			}
+		}
+	}
+
+	// Spaces are never hidden
+	for i := 0; i < 20; i++ {
+		for _, idx := range missingLetterIndices("ice cream", 5) {
+			if idx == 3 {
+				t.Fatal("spaces should never be hidden")
+			}
+		}
+	}
+}
+
+func TestMissingLetterDisplayWord(t *testing.T) {
+	if got := missingLetterDisplayWord("Garden", []int{1, 4}, nil); got != "G_rd_n" {
+		t.Errorf("display word = %q, want %q", got, "G_rd_n")
+	}
+	if got := missingLetterDisplayWord("Garden", []int{1, 4}, []string{"gordon"}); got != "G_rd_n" {
+		t.Errorf("wrong guess should keep blanks, got %q", got)
+	}
+	if got := missingLetterDisplayWord("Garden", []int{1, 4}, []string{"garden"}); got != "Garden" {
+		t.Errorf("correct guess should reveal word, got %q", got)
+	}
+}
+
+func TestMissingLetterPoints(t *testing.T) {
+	tests := []struct {
+		attempts   int
+		numMissing int
+		expected   int
+	}{
+		{attempts: 1, numMissing: 2, expected: 100},
+		{attempts: 2, numMissing: 2, expected: 85},
+		{attempts: 3, numMissing: 1, expected: 20},
+		{attempts: 5, numMissing: 1, expected: 10},
+	}
+
+	for _, tt := range tests {
+		if got := missingLetterPoints(tt.attempts, tt.numMissing); got != tt.expected {
+			t.Errorf("missingLetterPoints(%d, %d) = %d, want %d", tt.attempts, tt.numMissing, got, tt.expected)
+		}
+	}
+}
+
+func TestFillMissingLetters(t *testing.T) {
+	// Indices are filled left to right regardless of the order they are stored in
+	if got := fillMissingLetters("Garden", []int{4, 1}, "ae"); got != "garden" {
+		t.Errorf("fillMissingLetters() = %q, want %q", got, "garden")
+	}
+	// Short guesses leave the original letters in place
+	if got := fillMissingLetters("garden", []int{1, 4}, "o"); got != "gorden" {
+		t.Errorf("fillMissingLetters() = %q, want %q", got, "gorden")
+	}
+}
+
+func TestApplyMissingLetterGuess(t *testing.T) {
+	state := &models.MissingLetterGameState{Word: "garden", MissingIndices: []int{1, 4}, MaxAttempts: 2}
+
+	if applyMissingLetterGuess(state, "oo", nil) {
+		t.Fatal("wrong guess reported as correct")
+	}
+	if state.Attempts != 1 || state.IsComplete || state.LastGuessCorrect == nil || *state.LastGuessCorrect {
+		t.Errorf("after wrong guess: attempts=%d complete=%v", state.Attempts, state.IsComplete)
+	}
+	if state.DisplayWord != "g_rd_n" {
+		t.Errorf("display word = %q, want blanks kept", state.DisplayWord)
+	}
+
+	if state.LastValidWordBonus != nil {
+		t.Error("valid word bonus should not be set without a word checker")
+	}
+
+	if !applyMissingLetterGuess(state, "ae", nil) {
+		t.Fatal("correct guess reported as wrong")
+	}
+	if !state.IsWon || !state.IsComplete || state.DisplayWord != "garden" {
+		t.Errorf("after correct guess: won=%v complete=%v display=%q", state.IsWon, state.IsComplete, state.DisplayWord)
+	}
+
+	lost := &models.MissingLetterGameState{Word: "garden", MissingIndices: []int{1, 4}, MaxAttempts: 1}
+	applyMissingLetterGuess(lost, "xx", nil)
+	if !lost.IsLost || !lost.IsComplete {
+		t.Errorf("expected loss after max attempts, got lost=%v complete=%v", lost.IsLost, lost.IsComplete)
+	}
+}
+
+func TestApplyMissingLetterGuessValidWordBonus(t *testing.T) {
+	realWords := map[string]bool{"cat": true, "cot": true, "cut": true}
+	isWord := func(word string) bool { return realWords[word] }
+
+	state := &models.MissingLetterGameState{Word: "cut", MissingIndices: []int{1}, MaxAttempts: 3}
+
+	applyMissingLetterGuess(state, "a", isWord)
+	if state.LastValidWordBonus == nil || !*state.LastValidWordBonus {
+		t.Error("expected bonus for guessing a real word")
+	}
+
+	applyMissingLetterGuess(state, "x", isWord)
+	if state.LastValidWordBonus == nil || *state.LastValidWordBonus {
+		t.Error("expected no bonus for a made-up word")
+	}
+
+	applyMissingLetterGuess(state, "u", isWord)
+	if state.LastValidWordBonus != nil {
+		t.Error("correct guess should not be flagged for the bonus")
+	}
+}
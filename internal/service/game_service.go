@@ -0,0 +1,439 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"spellingclash/internal/dictionary"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"strings"
+)
+
+var (
+	ErrNoWords      = errors.New("list has no words")
+	ErrNoActiveGame = errors.New("no active game")
+)
+
+// GameService handles hangman and missing letter game business logic
+type GameService struct {
+	hangmanRepo       *repository.HangmanRepository
+	missingLetterRepo *repository.MissingLetterRepository
+	listRepo          *repository.ListRepository
+	dict              *dictionary.Dictionary
+	achievements      *AchievementService
+}
+
+// NewGameService creates a new game service. The dictionary is used for the
+// missing letter "real word" feedback and may be nil to disable it. Achievements
+// are checked after each game and session, unless achievements is nil.
+func NewGameService(hangmanRepo *repository.HangmanRepository, missingLetterRepo *repository.MissingLetterRepository, listRepo *repository.ListRepository, dict *dictionary.Dictionary, achievements *AchievementService) *GameService {
+	return &GameService{
+		hangmanRepo:       hangmanRepo,
+		missingLetterRepo: missingLetterRepo,
+		listRepo:          listRepo,
+		dict:              dict,
+		achievements:      achievements,
+	}
+}
+
+// shuffledWords returns a shuffled copy of the given words
+func shuffledWords(words []models.Word) []models.Word {
+	shuffled := make([]models.Word, len(words))
+	copy(shuffled, words)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
+
+// StartHangmanSession starts a new hangman session for a kid over the given words in random order
+func (s *GameService) StartHangmanSession(kidID, listID int64, words []models.Word) error {
+	if len(words) == 0 {
+		return ErrNoWords
+	}
+
+	words = shuffledWords(words)
+
+	sessionID, err := s.hangmanRepo.CreateSession(kidID, listID, len(words))
+	if err != nil {
+		return fmt.Errorf("failed to create hangman session: %w", err)
+	}
+
+	state := &models.HangmanState{
+		KidID:     kidID,
+		SessionID: sessionID,
+		Words:     words,
+	}
+	if err := s.hangmanRepo.SaveState(state); err != nil {
+		return fmt.Errorf("failed to save hangman state: %w", err)
+	}
+
+	return nil
+}
+
+// GetHangmanGame retrieves the kid's game in progress, or nil if there is none
+func (s *GameService) GetHangmanGame(kidID int64) (*models.HangmanGameState, error) {
+	game, err := s.hangmanRepo.GetActiveGame(kidID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hangman game: %w", err)
+	}
+	if game == nil {
+		return nil, nil
+	}
+
+	state, err := s.hangmanRepo.GetState(kidID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hangman state: %w", err)
+	}
+	if state == nil {
+		return nil, nil
+	}
+
+	return &models.HangmanGameState{
+		GameID:          game.ID,
+		Word:            game.Word,
+		MaskedWord:      maskHangmanWord(game.Word, game.GuessedLetters),
+		GuessedLetters:  game.GuessedLetters,
+		WrongGuesses:    game.WrongGuesses,
+		MaxWrongGuesses: game.MaxWrongGuesses,
+		IsWon:           game.IsWon,
+		IsLost:          game.IsLost,
+		IsComplete:      game.IsWon || game.IsLost,
+		RemainingWords:  len(state.Words) - state.CurrentWordIdx - 1,
+		CurrentWordIdx:  state.CurrentWordIdx,
+		TotalWords:      len(state.Words),
+		PointsSoFar:     state.PointsSoFar,
+	}, nil
+}
+
+// StartNextHangmanGame starts a game for the kid's current word.
+// It returns a nil state once every word has been played, after marking the session complete.
+func (s *GameService) StartNextHangmanGame(kidID int64) (*models.HangmanGameState, error) {
+	state, err := s.hangmanRepo.GetState(kidID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hangman state: %w", err)
+	}
+	if state == nil || len(state.Words) == 0 {
+		return nil, ErrNoActiveGame
+	}
+
+	if state.CurrentWordIdx >= len(state.Words) {
+		if err := s.hangmanRepo.CompleteSession(kidID); err != nil {
+			return nil, fmt.Errorf("failed to complete hangman session: %w", err)
+		}
+		s.achievements.afterActivity(kidID)
+		return nil, nil
+	}
+
+	word := state.Words[state.CurrentWordIdx]
+	gameID, err := s.hangmanRepo.CreateGame(state.SessionID, kidID, word.ID, word.WordText, hangmanMaxWrongGuesses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hangman game: %w", err)
+	}
+
+	return &models.HangmanGameState{
+		GameID:          gameID,
+		Word:            word.WordText,
+		MaskedWord:      maskHangmanWord(word.WordText, nil),
+		GuessedLetters:  []string{},
+		MaxWrongGuesses: hangmanMaxWrongGuesses,
+		RemainingWords:  len(state.Words) - state.CurrentWordIdx - 1,
+		CurrentWordIdx:  state.CurrentWordIdx,
+		TotalWords:      len(state.Words),
+		PointsSoFar:     state.PointsSoFar,
+	}, nil
+}
+
+// GuessHangmanLetter applies a letter guess to the kid's game in progress,
+// scoring the game and session when the word is solved or lost
+func (s *GameService) GuessHangmanLetter(kidID int64, letter string) (*models.HangmanGameState, error) {
+	state, err := s.GetHangmanGame(kidID)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil || state.IsComplete {
+		return nil, ErrNoActiveGame
+	}
+
+	if !applyHangmanGuess(state, strings.ToLower(letter)) {
+		// Already guessed, nothing to record
+		return state, nil
+	}
+
+	if state.IsComplete {
+		points := 0
+		if state.IsWon {
+			points = hangmanPoints(state.WrongGuesses)
+			state.PointsSoFar += points
+		}
+		if err := s.hangmanRepo.CompleteGame(state.GameID, state.IsWon, points); err != nil {
+			return nil, fmt.Errorf("failed to complete hangman game: %w", err)
+		}
+		if err := s.hangmanRepo.AddSessionPoints(kidID, points, state.IsWon); err != nil {
+			return nil, fmt.Errorf("failed to update hangman session: %w", err)
+		}
+	}
+
+	if err := s.hangmanRepo.SaveGameProgress(state.GameID, state.GuessedLetters, state.WrongGuesses, state.IsWon, state.IsLost); err != nil {
+		return nil, fmt.Errorf("failed to save hangman game: %w", err)
+	}
+	if state.IsComplete {
+		s.achievements.afterActivity(kidID)
+	}
+
+	return state, nil
+}
+
+// AdvanceHangman moves the kid on to the next word in their session
+func (s *GameService) AdvanceHangman(kidID int64) error {
+	state, err := s.hangmanRepo.GetState(kidID)
+	if err != nil {
+		return fmt.Errorf("failed to get hangman state: %w", err)
+	}
+	if state == nil {
+		return ErrNoActiveGame
+	}
+
+	state.CurrentWordIdx++
+	return s.hangmanRepo.SaveState(state)
+}
+
+// CompleteHangmanSession marks the kid's current session as complete so its points count
+func (s *GameService) CompleteHangmanSession(kidID int64) error {
+	if err := s.hangmanRepo.CompleteSession(kidID); err != nil {
+		return err
+	}
+	s.achievements.afterActivity(kidID)
+	return nil
+}
+
+// GetHangmanResults retrieves the kid's current session totals
+func (s *GameService) GetHangmanResults(kidID int64) (*models.HangmanSession, error) {
+	return s.hangmanRepo.GetCurrentSession(kidID)
+}
+
+// ClearHangmanState removes the kid's session progress once results have been shown
+func (s *GameService) ClearHangmanState(kidID int64) error {
+	return s.hangmanRepo.DeleteState(kidID)
+}
+
+// StartMissingLetterSession starts a new missing letter session for a kid
+// using up to 20 of the given words in random order
+func (s *GameService) StartMissingLetterSession(kidID, listID int64, words []models.Word) error {
+	if len(words) == 0 {
+		return ErrNoWords
+	}
+
+	words = shuffledWords(words)
+	if len(words) > missingLetterMaxWords {
+		words = words[:missingLetterMaxWords]
+	}
+
+	sessionID, err := s.missingLetterRepo.CreateSession(kidID, listID, len(words))
+	if err != nil {
+		return fmt.Errorf("failed to create missing letter session: %w", err)
+	}
+
+	state := &models.MissingLetterState{
+		KidID:     kidID,
+		SessionID: sessionID,
+		Words:     words,
+	}
+	if err := s.missingLetterRepo.SaveState(state); err != nil {
+		return fmt.Errorf("failed to save missing letter state: %w", err)
+	}
+
+	return nil
+}
+
+// GetMissingLetterGame retrieves the kid's game in progress, or nil if there is none
+func (s *GameService) GetMissingLetterGame(kidID int64) (*models.MissingLetterGameState, error) {
+	game, err := s.missingLetterRepo.GetActiveGame(kidID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get missing letter game: %w", err)
+	}
+	if game == nil {
+		return nil, nil
+	}
+
+	state, err := s.missingLetterRepo.GetState(kidID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get missing letter state: %w", err)
+	}
+	if state == nil {
+		return nil, nil
+	}
+
+	// Determine last guess correctness
+	var lastGuessCorrect *bool
+	if len(game.GuessedLetters) > 0 {
+		correct := game.GuessedLetters[len(game.GuessedLetters)-1] == strings.ToLower(game.Word)
+		lastGuessCorrect = &correct
+	}
+
+	gameState := &models.MissingLetterGameState{
+		GameID:           game.ID,
+		Word:             game.Word,
+		DisplayWord:      missingLetterDisplayWord(game.Word, game.MissingIndices, game.GuessedLetters),
+		MissingIndices:   game.MissingIndices,
+		GuessedLetters:   game.GuessedLetters,
+		Attempts:         game.Attempts,
+		MaxAttempts:      game.MaxAttempts,
+		IsWon:            game.IsWon,
+		IsLost:           game.IsLost,
+		IsComplete:       game.IsWon || game.IsLost,
+		RemainingWords:   len(state.Words) - state.CurrentWordIdx - 1,
+		CurrentWordIdx:   state.CurrentWordIdx,
+		TotalWords:       len(state.Words),
+		PointsSoFar:      state.PointsSoFar,
+		LastGuessCorrect: lastGuessCorrect,
+	}
+
+	if state.CurrentWordIdx >= 0 && state.CurrentWordIdx < len(state.Words) {
+		gameState.WordAudioFilename = state.Words[state.CurrentWordIdx].AudioFilename
+	}
+
+	return gameState, nil
+}
+
+// StartNextMissingLetterGame starts a game for the kid's current word.
+// It returns a nil state once every word has been played, after marking the session complete.
+func (s *GameService) StartNextMissingLetterGame(kidID int64) (*models.MissingLetterGameState, error) {
+	state, err := s.missingLetterRepo.GetState(kidID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get missing letter state: %w", err)
+	}
+	if state == nil || len(state.Words) == 0 {
+		return nil, ErrNoActiveGame
+	}
+
+	if state.CurrentWordIdx >= len(state.Words) {
+		if err := s.missingLetterRepo.CompleteSession(kidID); err != nil {
+			return nil, fmt.Errorf("failed to complete missing letter session: %w", err)
+		}
+		s.achievements.afterActivity(kidID)
+		return nil, nil
+	}
+
+	word := state.Words[state.CurrentWordIdx]
+
+	// Determine missing letter indices based on word length and difficulty
+	missingIndices := missingLetterIndices(word.WordText, word.DifficultyLevel)
+
+	gameID, err := s.missingLetterRepo.CreateGame(state.SessionID, kidID, word.ID, word.WordText, missingIndices, missingLetterMaxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create missing letter game: %w", err)
+	}
+
+	return &models.MissingLetterGameState{
+		GameID:            gameID,
+		Word:              word.WordText,
+		WordAudioFilename: word.AudioFilename,
+		DisplayWord:       missingLetterDisplayWord(word.WordText, missingIndices, nil),
+		MissingIndices:    missingIndices,
+		GuessedLetters:    []string{},
+		MaxAttempts:       missingLetterMaxAttempts,
+		RemainingWords:    len(state.Words) - state.CurrentWordIdx - 1,
+		CurrentWordIdx:    state.CurrentWordIdx,
+		TotalWords:        len(state.Words),
+		PointsSoFar:       state.PointsSoFar,
+	}, nil
+}
+
+// GuessMissingLetters applies a guess for the missing letters to the kid's game in progress,
+// scoring the game and session when the word is solved or lost
+func (s *GameService) GuessMissingLetters(kidID int64, guess string) (*models.MissingLetterGameState, error) {
+	state, err := s.GetMissingLetterGame(kidID)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil || state.IsComplete {
+		return nil, ErrNoActiveGame
+	}
+
+	applyMissingLetterGuess(state, strings.ToLower(guess), s.missingLetterWordChecker(kidID))
+
+	if state.IsComplete {
+		points := 0
+		if state.IsWon {
+			points = missingLetterPoints(state.Attempts, len(state.MissingIndices))
+			state.PointsSoFar += points
+		}
+		if err := s.missingLetterRepo.CompleteGame(state.GameID, state.IsWon, points); err != nil {
+			return nil, fmt.Errorf("failed to complete missing letter game: %w", err)
+		}
+		if err := s.missingLetterRepo.AddSessionPoints(kidID, points, state.IsWon); err != nil {
+			return nil, fmt.Errorf("failed to update missing letter session: %w", err)
+		}
+	}
+
+	if err := s.missingLetterRepo.SaveGameProgress(state.GameID, state.GuessedLetters, state.Attempts, state.IsWon, state.IsLost); err != nil {
+		return nil, fmt.Errorf("failed to save missing letter game: %w", err)
+	}
+	if state.IsComplete {
+		s.achievements.afterActivity(kidID)
+	}
+
+	return state, nil
+}
+
+// missingLetterWordChecker returns a function reporting whether a guess is a real word
+// in the locale of the kid's current list, or nil if words can't be checked
+func (s *GameService) missingLetterWordChecker(kidID int64) func(string) bool {
+	if s.dict == nil {
+		return nil
+	}
+
+	session, err := s.missingLetterRepo.GetCurrentSession(kidID)
+	if err != nil || session == nil {
+		log.Printf("Warning: Failed to get missing letter session for word check: %v", err)
+		return nil
+	}
+	list, err := s.listRepo.GetListByID(session.SpellingListID)
+	if err != nil || list == nil {
+		log.Printf("Warning: Failed to get list %d for word check: %v", session.SpellingListID, err)
+		return nil
+	}
+
+	if !s.dict.Supports(list.Locale) {
+		return nil
+	}
+	return func(word string) bool {
+		return s.dict.Contains(word, list.Locale)
+	}
+}
+
+// AdvanceMissingLetter moves the kid on to the next word in their session
+func (s *GameService) AdvanceMissingLetter(kidID int64) error {
+	state, err := s.missingLetterRepo.GetState(kidID)
+	if err != nil {
+		return fmt.Errorf("failed to get missing letter state: %w", err)
+	}
+	if state == nil {
+		return ErrNoActiveGame
+	}
+
+	state.CurrentWordIdx++
+	return s.missingLetterRepo.SaveState(state)
+}
+
+// CompleteMissingLetterSession marks the kid's current session as complete so its points count
+func (s *GameService) CompleteMissingLetterSession(kidID int64) error {
+	if err := s.missingLetterRepo.CompleteSession(kidID); err != nil {
+		return err
+	}
+	s.achievements.afterActivity(kidID)
+	return nil
+}
+
+// GetMissingLetterResults retrieves the kid's current session totals
+func (s *GameService) GetMissingLetterResults(kidID int64) (*models.MissingLetterSession, error) {
+	return s.missingLetterRepo.GetCurrentSession(kidID)
+}
+
+// ClearMissingLetterState removes the kid's session progress once results have been shown
+func (s *GameService) ClearMissingLetterState(kidID int64) error {
+	return s.missingLetterRepo.DeleteState(kidID)
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"spellingclash/internal/security"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrWrongKidPassword = errors.New("incorrect password")
+
+// Wrong kid passwords are counted per kid, per device and per address. Each limit
+// that is reached makes the next attempt wait for the rest of its window, so the
+// waits grow from a short pause to a lockout the more guesses are made. A device
+// can shed its cookie to start afresh, but not its address. The address limits
+// are higher as a whole class often shares one. Devices the kid has logged in on
+// before skip the per-kid and per-address limits, so someone guessing elsewhere
+// can't lock a child out of their own tablet.
+var (
+	kidLoginLimits = []security.RateLimitPolicy{
+		{Name: "kid-login-pause", Limit: 5, Window: time.Minute},
+		{Name: "kid-login-lockout", Limit: 10, Window: 15 * time.Minute},
+		{Name: "kid-login-lockout-hour", Limit: 20, Window: time.Hour},
+	}
+	kidDeviceLoginLimits = []security.RateLimitPolicy{
+		{Name: "kid-device-pause", Limit: 10, Window: time.Minute},
+		{Name: "kid-device-lockout", Limit: 20, Window: 15 * time.Minute},
+	}
+	kidAddressLoginLimits = []security.RateLimitPolicy{
+		{Name: "kid-address-pause", Limit: 30, Window: time.Minute},
+		{Name: "kid-address-lockout", Limit: 60, Window: 15 * time.Minute},
+	}
+
+	// kidLoginAlert limits how often parents are emailed about one child
+	kidLoginAlert = security.RateLimitPolicy{Name: "kid-login-alert", Limit: 1, Window: 24 * time.Hour}
+)
+
+// maxTrustedKids caps how many children a device remembers logging in
+const maxTrustedKids = 10
+
+// KidLoginWaitError is returned instead of checking a kid's password while too
+// many recent attempts were wrong
+type KidLoginWaitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *KidLoginWaitError) Error() string {
+	return fmt.Sprintf("too many wrong passwords, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+// KidDevice is the device a kid logs in from
+type KidDevice struct {
+	ID          string   // ID from the device cookie, or the client IP address if there isn't one
+	HasCookie   bool     // Whether ID came from a device cookie the server issued
+	IP          string   // Client IP address
+	TrustTokens []string // Tokens from earlier logins on the device
+}
+
+// KidLoginService checks kid passwords while guarding the short passwords
+// against guessing
+type KidLoginService struct {
+	familyRepo   *repository.FamilyRepository
+	limiter      security.Limiter
+	emailService *EmailService
+	secret       []byte
+}
+
+// NewKidLoginService creates a new kid login service. The secret signs the tokens
+// that remember which children have logged in on a device. emailService may be nil.
+func NewKidLoginService(familyRepo *repository.FamilyRepository, limiter security.Limiter, emailService *EmailService, secret string) *KidLoginService {
+	return &KidLoginService{
+		familyRepo:   familyRepo,
+		limiter:      limiter,
+		emailService: emailService,
+		secret:       []byte("kid-device:" + secret),
+	}
+}
+
+// CheckPassword checks a kid's password. On success it returns a token for the
+// device to remember the kid by, or "" if the device has no cookie to tie it to.
+// Returns ErrWrongKidPassword or a *KidLoginWaitError otherwise.
+func (s *KidLoginService) CheckPassword(ctx context.Context, kid *models.Kid, password string, device KidDevice) (string, error) {
+	trusted := s.isTrusted(kid, device)
+	kidKey := strconv.FormatInt(kid.ID, 10)
+
+	wait := s.blocked(kidDeviceLoginLimits, device.ID)
+	if !trusted {
+		wait = max(wait, s.blocked(kidLoginLimits, kidKey), s.blocked(kidAddressLoginLimits, device.IP))
+	}
+	if wait > 0 {
+		return "", &KidLoginWaitError{RetryAfter: wait}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(password), []byte(kid.Password)) != 1 {
+		s.recordFailure(kidDeviceLoginLimits, device.ID)
+		s.recordFailure(kidAddressLoginLimits, device.IP)
+		if !trusted {
+			s.recordFailure(kidLoginLimits, kidKey)
+			if s.blocked(kidLoginLimits[1:], kidKey) > 0 {
+				s.alertParents(ctx, kid)
+			}
+		}
+		return "", ErrWrongKidPassword
+	}
+
+	if !trusted {
+		for _, policy := range kidLoginLimits {
+			if err := s.limiter.Reset(policy, kidKey); err != nil {
+				log.Printf("Error resetting kid login limit: %v", err)
+			}
+		}
+	}
+	if !device.HasCookie {
+		return "", nil
+	}
+	return s.trustToken(kid, device.ID), nil
+}
+
+// NewDeviceCookie returns the value for a new device's cookie: a random ID,
+// signed so a device can't make up IDs of its own
+func (s *KidLoginService) NewDeviceCookie() string {
+	id := security.GenerateSessionID()
+	return id + "." + s.signDevice(id)
+}
+
+// DeviceID returns the ID in a device cookie, if the cookie was issued by the server
+func (s *KidLoginService) DeviceID(cookie string) (string, bool) {
+	id, signature, ok := strings.Cut(cookie, ".")
+	if !ok || id == "" || !hmac.Equal([]byte(signature), []byte(s.signDevice(id))) {
+		return "", false
+	}
+	return id, true
+}
+
+func (s *KidLoginService) signDevice(id string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("device\x00" + id))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// TrustTokens adds a token from a successful login to a device's tokens, keeping
+// the most recent ones
+func TrustTokens(tokens []string, token string) []string {
+	return security.AppendDeviceToken(tokens, token, maxTrustedKids)
+}
+
+// trustToken signs the kid and device together. It includes the password, so a
+// parent regenerating it makes every device ask again.
+func (s *KidLoginService) trustToken(kid *models.Kid, deviceID string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strconv.FormatInt(kid.ID, 10) + "\x00" + deviceID + "\x00" + kid.Password))
+	return strconv.FormatInt(kid.ID, 10) + "-" + hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// isTrusted reports whether the kid has logged in on the device before
+func (s *KidLoginService) isTrusted(kid *models.Kid, device KidDevice) bool {
+	if !device.HasCookie {
+		return false
+	}
+	want := s.trustToken(kid, device.ID)
+	for _, token := range device.TrustTokens {
+		if hmac.Equal([]byte(token), []byte(want)) {
+			return true
+		}
+	}
+	return false
+}
+
+// blocked returns the longest wait any of the policies imposes on key. The
+// limiter failing doesn't stop anyone logging in.
+func (s *KidLoginService) blocked(policies []security.RateLimitPolicy, key string) time.Duration {
+	var wait time.Duration
+	for _, policy := range policies {
+		w, err := s.limiter.Blocked(policy, key)
+		if err != nil {
+			log.Printf("Error checking kid login limit %q: %v", policy.Name, err)
+			continue
+		}
+		wait = max(wait, w)
+	}
+	return wait
+}
+
+func (s *KidLoginService) recordFailure(policies []security.RateLimitPolicy, key string) {
+	for _, policy := range policies {
+		if _, _, err := s.limiter.Allow(policy, key); err != nil {
+			log.Printf("Error recording kid login failure: %v", err)
+		}
+	}
+}
+
+// alertParents emails the family's parents that someone is guessing their child's
+// password, at most once a day per child
+func (s *KidLoginService) alertParents(ctx context.Context, kid *models.Kid) {
+	log.Printf("Kid %d locked out after too many wrong passwords", kid.ID)
+	if s.emailService == nil {
+		return
+	}
+	if allowed, _, err := s.limiter.Allow(kidLoginAlert, strconv.FormatInt(kid.ID, 10)); err != nil || !allowed {
+		return
+	}
+
+	_, parents, err := s.familyRepo.GetFamilyMembers(kid.FamilyCode)
+	if err != nil {
+		log.Printf("Error getting parents to alert about kid %d: %v", kid.ID, err)
+		return
+	}
+	for _, parent := range parents {
+		if err := s.emailService.SendKidLoginAlertEmail(ctx, parent.Email, parent.Name, kid.Name, ""); err != nil {
+			log.Printf("Error sending kid login alert to user %d: %v", parent.ID, err)
+		}
+	}
+}
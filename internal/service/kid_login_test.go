@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"spellingclash/internal/email"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"spellingclash/internal/security"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newKidLoginTestService(t *testing.T) (*KidLoginService, *EmailService, security.Limiter) {
+	t.Helper()
+	db := newTestDB(t)
+	seed := []string{
+		"INSERT INTO users (id, email, password_hash, name) VALUES (1, 'parent@example.com', 'x', 'Parent')",
+		"INSERT INTO families (family_code) VALUES ('FAM1')",
+		"INSERT INTO family_members (family_code, user_id, role) VALUES ('FAM1', 1, 'parent')",
+	}
+	for _, query := range seed {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("failed to seed %q: %v", query, err)
+		}
+	}
+
+	templates, err := email.LoadTemplates(filepath.Join("..", "templates", "email"), email.Branding{AppName: "WordClash"}, "en-GB")
+	if err != nil {
+		t.Fatalf("LoadTemplates() error: %v", err)
+	}
+	emails, err := NewEmailService(&flakyMailer{}, templates, repository.NewEmailOutboxRepository(db), repository.NewInvitationRepository(db), "noreply@example.com", "WordClash", "https://example.com", false)
+	if err != nil {
+		t.Fatalf("NewEmailService() error: %v", err)
+	}
+
+	// Keep every attempt in the same rate limit windows
+	now := time.Now().Truncate(time.Hour)
+	limiter := security.NewStoreLimiter(repository.NewRateLimitRepository(db))
+	limiter.SetClock(func() time.Time { return now })
+	return NewKidLoginService(repository.NewFamilyRepository(db), limiter, emails, "secret"), emails, limiter
+}
+
+func TestKidLoginLimits(t *testing.T) {
+	logins, _, _ := newKidLoginTestService(t)
+	ctx := context.Background()
+	kid := &models.Kid{ID: 1, FamilyCode: "FAM1", Name: "Ada", Password: "Ab3x"}
+
+	// The kid's own tablet, where they have logged in before
+	tablet := KidDevice{ID: "tablet", HasCookie: true, IP: "198.51.100.1"}
+	token, err := logins.CheckPassword(ctx, kid, "Ab3x", tablet)
+	if err != nil || token == "" {
+		t.Fatalf("CheckPassword() = %q, %v, want a trust token", token, err)
+	}
+	tablet.TrustTokens = TrustTokens(nil, token)
+
+	// Someone else guesses on another device until they have to pause
+	other := KidDevice{ID: "other", HasCookie: true, IP: "203.0.113.7"}
+	for range kidLoginLimits[0].Limit {
+		if _, err := logins.CheckPassword(ctx, kid, "zzzz", other); !errors.Is(err, ErrWrongKidPassword) {
+			t.Fatalf("CheckPassword() with a wrong password = %v, want ErrWrongKidPassword", err)
+		}
+	}
+	var waitErr *KidLoginWaitError
+	if _, err := logins.CheckPassword(ctx, kid, "Ab3x", other); !errors.As(err, &waitErr) || waitErr.RetryAfter <= 0 {
+		t.Fatalf("CheckPassword() after too many wrong passwords = %v, want a KidLoginWaitError", err)
+	}
+	// A fresh device can't get around the pause either
+	if _, err := logins.CheckPassword(ctx, kid, "Ab3x", KidDevice{ID: "ip:203.0.113.8", IP: "203.0.113.8"}); !errors.As(err, &waitErr) {
+		t.Errorf("CheckPassword() from a new device = %v, want a KidLoginWaitError", err)
+	}
+
+	// The kid can still log in on their tablet
+	if _, err := logins.CheckPassword(ctx, kid, "Ab3x", tablet); err != nil {
+		t.Errorf("CheckPassword() on a trusted device = %v, want success", err)
+	}
+
+	// A token stops working once the password is regenerated
+	regenerated := *kid
+	regenerated.Password = "Qq77"
+	if logins.isTrusted(&regenerated, tablet) {
+		t.Error("isTrusted() accepted a token from before the password changed")
+	}
+	// And is tied to the device it was issued to
+	if logins.isTrusted(kid, KidDevice{ID: "copy", HasCookie: true, TrustTokens: tablet.TrustTokens}) {
+		t.Error("isTrusted() accepted a token from another device")
+	}
+}
+
+func TestKidLoginAlertsParents(t *testing.T) {
+	logins, emails, limiter := newKidLoginTestService(t)
+	ctx := context.Background()
+	kid := &models.Kid{ID: 1, FamilyCode: "FAM1", Name: "Ada", Password: "Ab3x"}
+
+	// Wrong passwords spread over the last 15 minutes, one short of a lockout
+	for range kidLoginLimits[1].Limit - 1 {
+		limiter.Allow(kidLoginLimits[1], strconv.FormatInt(kid.ID, 10))
+	}
+
+	device := KidDevice{ID: "other", HasCookie: true, IP: "203.0.113.7"}
+	for range 2 {
+		if _, err := logins.CheckPassword(ctx, kid, "zzzz", device); err == nil {
+			t.Fatal("CheckPassword() with a wrong password succeeded")
+		}
+	}
+
+	status, err := emails.GetQueueStatus(10)
+	if err != nil {
+		t.Fatalf("GetQueueStatus() error: %v", err)
+	}
+	if len(status.Recent) != 1 || status.Recent[0].ToEmail != "parent@example.com" {
+		t.Fatalf("queued emails = %+v, want one alert to the parent", status.Recent)
+	}
+	if subject := status.Recent[0].Subject; subject != "Someone is trying to log in as Ada" {
+		t.Errorf("alert subject = %q", subject)
+	}
+}
+
+func TestKidLoginAddressLimits(t *testing.T) {
+	logins, _, _ := newKidLoginTestService(t)
+	ctx := context.Background()
+
+	// A new device cookie for every guess still counts against the address,
+	// whichever children are tried
+	var waitErr *KidLoginWaitError
+	for i := range kidAddressLoginLimits[0].Limit {
+		kid := &models.Kid{ID: int64(i + 1), FamilyCode: "FAM1", Password: "Ab3x"}
+		device := KidDevice{ID: logins.NewDeviceCookie(), HasCookie: true, IP: "203.0.113.7"}
+		if _, err := logins.CheckPassword(ctx, kid, "zzzz", device); !errors.Is(err, ErrWrongKidPassword) {
+			t.Fatalf("CheckPassword() guess %d = %v, want ErrWrongKidPassword", i+1, err)
+		}
+	}
+	kid := &models.Kid{ID: 100, FamilyCode: "FAM1", Password: "Ab3x"}
+	fresh := KidDevice{ID: logins.NewDeviceCookie(), HasCookie: true, IP: "203.0.113.7"}
+	if _, err := logins.CheckPassword(ctx, kid, "Ab3x", fresh); !errors.As(err, &waitErr) {
+		t.Errorf("CheckPassword() from a guessing address = %v, want a KidLoginWaitError", err)
+	}
+	if _, err := logins.CheckPassword(ctx, kid, "Ab3x", KidDevice{ID: "ip:198.51.100.1", IP: "198.51.100.1"}); err != nil {
+		t.Errorf("CheckPassword() from another address = %v, want success", err)
+	}
+}
+
+func TestKidDeviceCookie(t *testing.T) {
+	logins, _, _ := newKidLoginTestService(t)
+
+	cookie := logins.NewDeviceCookie()
+	id, ok := logins.DeviceID(cookie)
+	if !ok || id == "" || id == cookie {
+		t.Fatalf("DeviceID(%q) = %q, %v, want the issued ID", cookie, id, ok)
+	}
+	for _, forged := range []string{"", "made-up", id, id + ".0123", "other." + cookie[len(id)+1:]} {
+		if _, ok := logins.DeviceID(forged); ok {
+			t.Errorf("DeviceID(%q) accepted a cookie the server didn't issue", forged)
+		}
+	}
+}
+
+func TestTrustTokens(t *testing.T) {
+	var tokens []string
+	for i := range maxTrustedKids + 5 {
+		tokens = TrustTokens(tokens, strconv.Itoa(i+10)+"-x")
+	}
+	if len(tokens) != maxTrustedKids {
+		t.Errorf("TrustTokens() kept %d tokens, want %d", len(tokens), maxTrustedKids)
+	}
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxNicknameLength matches the nickname column
+const maxNicknameLength = 30
+
+var ErrInvalidNickname = errors.New("nickname must be at most 30 characters and not contain bad words")
+
+// Generated nicknames are a colour and an animal. The words differ from those in
+// kids' usernames so a nickname never gives away how a kid signs in.
+var (
+	nicknameColours = []string{
+		"Amber", "Azure", "Coral", "Crimson", "Emerald", "Golden", "Indigo", "Jade",
+		"Lemon", "Lilac", "Mint", "Ochre", "Plum", "Ruby", "Silver", "Teal",
+	}
+	nicknameAnimals = []string{
+		"Badger", "Beaver", "Bison", "Falcon", "Gecko", "Hedgehog", "Heron", "Koala",
+		"Lemur", "Lynx", "Meerkat", "Otter", "Owl", "Puffin", "Walrus", "Yak",
+	}
+)
+
+// DefaultNickname returns the nickname a kid is shown under until one is chosen
+func DefaultNickname(kidID int64) string {
+	h := fnv.New32a()
+	h.Write([]byte(strconv.FormatInt(kidID, 10)))
+	sum := h.Sum32()
+	colour := nicknameColours[sum%uint32(len(nicknameColours))]
+	animal := nicknameAnimals[(sum/uint32(len(nicknameColours)))%uint32(len(nicknameAnimals))]
+	return colour + " " + animal
+}
+
+// LeaderboardPeriod returns the period named in a request, defaulting to this week
+func LeaderboardPeriod(period string) string {
+	if period == models.LeaderboardAllTime {
+		return models.LeaderboardAllTime
+	}
+	return models.LeaderboardWeek
+}
+
+// weekStart returns midnight on the Monday of now's week in loc
+func weekStart(now time.Time, loc *time.Location) time.Time {
+	now = now.In(loc)
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	return time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday, 0, 0, 0, 0, loc)
+}
+
+// LeaderboardService ranks kids by points within their family, their teacher's
+// roster or an assignment, keeping real names within the family
+type LeaderboardService struct {
+	leaderboardRepo *repository.LeaderboardRepository
+	kidRepo         *repository.KidRepository
+	teacherKidsRepo *repository.TeacherKidRepository
+	userRepo        *repository.UserRepository
+	listRepo        *repository.ListRepository
+	streakService   *StreakService
+}
+
+// NewLeaderboardService creates a new leaderboard service
+func NewLeaderboardService(leaderboardRepo *repository.LeaderboardRepository, kidRepo *repository.KidRepository, teacherKidsRepo *repository.TeacherKidRepository, userRepo *repository.UserRepository, listRepo *repository.ListRepository, streakService *StreakService) *LeaderboardService {
+	return &LeaderboardService{
+		leaderboardRepo: leaderboardRepo,
+		kidRepo:         kidRepo,
+		teacherKidsRepo: teacherKidsRepo,
+		userRepo:        userRepo,
+		listRepo:        listRepo,
+		streakService:   streakService,
+	}
+}
+
+// boardRequest describes a leaderboard to build
+type boardRequest struct {
+	title     string
+	scope     string
+	period    string
+	kids      []models.Kid
+	location  *time.Location // Where weeks start
+	listID    int64          // Only count points on this list, unless 0
+	viewerID  int64          // The kid looking at the board, if any
+	realNames bool           // Only on boards within a single family
+}
+
+// buildBoard ranks the kids in a request who haven't been taken off leaderboards
+func (s *LeaderboardService) buildBoard(req boardRequest, now time.Time) (*models.Leaderboard, error) {
+	ids := make([]int64, len(req.kids))
+	for i, kid := range req.kids {
+		ids[i] = kid.ID
+	}
+	settings, err := s.leaderboardRepo.GetSettingsForKids(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var visible []models.Kid
+	var visibleIDs []int64
+	for _, kid := range req.kids {
+		if kidSettings, ok := settings[kid.ID]; ok && kidSettings.Hidden() {
+			continue
+		}
+		visible = append(visible, kid)
+		visibleIDs = append(visibleIDs, kid.ID)
+	}
+
+	var since time.Time
+	if req.period == models.LeaderboardWeek {
+		since = weekStart(now, req.location)
+	}
+	points, err := s.leaderboardRepo.GetPoints(visibleIDs, since, req.listID)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &models.Leaderboard{Title: req.title, Scope: req.scope, Period: req.period}
+	for _, kid := range visible {
+		name := kid.Name
+		if !req.realNames {
+			name = settings[kid.ID].Nickname
+			if name == "" {
+				name = DefaultNickname(kid.ID)
+			}
+		}
+		board.Entries = append(board.Entries, models.LeaderboardEntry{
+			KidID:       kid.ID,
+			DisplayName: name,
+			AvatarColor: kid.AvatarColor,
+			Points:      points[kid.ID],
+			IsViewer:    kid.ID == req.viewerID,
+		})
+	}
+
+	sort.SliceStable(board.Entries, func(i, j int) bool {
+		if board.Entries[i].Points != board.Entries[j].Points {
+			return board.Entries[i].Points > board.Entries[j].Points
+		}
+		return board.Entries[i].DisplayName < board.Entries[j].DisplayName
+	})
+	for i := range board.Entries {
+		board.Entries[i].Rank = i + 1
+		if i > 0 && board.Entries[i].Points == board.Entries[i-1].Points {
+			board.Entries[i].Rank = board.Entries[i-1].Rank
+		}
+	}
+	return board, nil
+}
+
+// FamilyBoard ranks the kids in a family, by name, with weeks starting in the
+// family's time zone
+func (s *LeaderboardService) FamilyBoard(familyCode, period string, viewerID int64) (*models.Leaderboard, error) {
+	kids, err := s.kidRepo.GetFamilyKids(familyCode)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := s.streakService.GetCalendar(familyCode)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildBoard(boardRequest{
+		title:     "Family",
+		scope:     models.LeaderboardFamily,
+		period:    LeaderboardPeriod(period),
+		kids:      kids,
+		location:  calendar.Location,
+		viewerID:  viewerID,
+		realNames: true,
+	}, time.Now())
+}
+
+// RosterBoard ranks the kids linked to a teacher, by nickname
+func (s *LeaderboardService) RosterBoard(teacherID int64, period string, viewerID int64) (*models.Leaderboard, error) {
+	kids, err := s.teacherKidsRepo.GetTeacherKids(teacherID)
+	if err != nil {
+		return nil, err
+	}
+	title := "Class"
+	teacher, err := s.userRepo.GetUserByID(teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if teacher != nil && teacher.Name != "" {
+		title = teacher.Name + "'s class"
+	}
+	return s.buildBoard(boardRequest{
+		title:    title,
+		scope:    models.LeaderboardRoster,
+		period:   LeaderboardPeriod(period),
+		kids:     kids,
+		location: s.streakService.defaultLocation,
+		viewerID: viewerID,
+	}, time.Now())
+}
+
+// assignmentBoard ranks the kids given an assignment, by nickname, on the points
+// they scored on its list
+func (s *LeaderboardService) assignmentBoard(assignment repository.LeaderboardAssignment, kids []models.Kid, period string, viewerID int64) (*models.Leaderboard, error) {
+	return s.buildBoard(boardRequest{
+		title:    assignment.ListName,
+		scope:    models.LeaderboardAssignment,
+		period:   LeaderboardPeriod(period),
+		kids:     kids,
+		location: s.streakService.defaultLocation,
+		listID:   assignment.ListID,
+		viewerID: viewerID,
+	}, time.Now())
+}
+
+// GetKidBoards returns the boards a kid is on: their family, each teacher's
+// roster, and each assignment shared with other kids. Kids taken off leaderboards
+// see none, reported by hidden.
+func (s *LeaderboardService) GetKidBoards(kid *models.Kid, period string) (boards []models.Leaderboard, hidden bool, err error) {
+	settings, err := s.leaderboardRepo.GetSettings(kid.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if settings != nil && settings.Hidden() {
+		return nil, true, nil
+	}
+
+	family, err := s.FamilyBoard(kid.FamilyCode, period, kid.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	boards = append(boards, *family)
+
+	teacherIDs, err := s.teacherKidsRepo.GetKidTeacherIDs(kid.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, teacherID := range teacherIDs {
+		roster, err := s.RosterBoard(teacherID, period, kid.ID)
+		if err != nil {
+			return nil, false, err
+		}
+		boards = append(boards, *roster)
+	}
+
+	assignments, err := s.leaderboardRepo.GetKidAssignments(kid.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, assignment := range assignments {
+		kids, err := s.leaderboardRepo.GetAssignedKids(assignment.ListID, assignment.AssignedBy)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(kids) < 2 {
+			continue
+		}
+		board, err := s.assignmentBoard(assignment, kids, period, kid.ID)
+		if err != nil {
+			return nil, false, err
+		}
+		boards = append(boards, *board)
+	}
+	return boards, false, nil
+}
+
+// GetTeacherBoards returns a teacher's roster board and a board for each list
+// they have assigned
+func (s *LeaderboardService) GetTeacherBoards(teacherID int64, period string) ([]models.Leaderboard, error) {
+	roster, err := s.RosterBoard(teacherID, period, 0)
+	if err != nil {
+		return nil, err
+	}
+	boards := []models.Leaderboard{*roster}
+
+	assignments, err := s.leaderboardRepo.GetAssignmentsBy(teacherID)
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		kids, err := s.leaderboardRepo.GetAssignedKids(assignment.ListID, teacherID)
+		if err != nil {
+			return nil, err
+		}
+		board, err := s.assignmentBoard(assignment, kids, period, 0)
+		if err != nil {
+			return nil, err
+		}
+		boards = append(boards, *board)
+	}
+	return boards, nil
+}
+
+// DisplayName returns how a kid is shown to another: by name within their family,
+// and by nickname to anyone else
+func (s *LeaderboardService) DisplayName(viewer, kid *models.Kid) (string, error) {
+	if viewer.FamilyCode == kid.FamilyCode {
+		return kid.Name, nil
+	}
+	settings, err := s.leaderboardRepo.GetSettings(kid.ID)
+	if err != nil {
+		return "", err
+	}
+	if settings != nil && settings.Nickname != "" {
+		return settings.Nickname, nil
+	}
+	return DefaultNickname(kid.ID), nil
+}
+
+// GetPrivacySettings returns the leaderboard settings of each kid, with the
+// defaults for kids who have none
+func (s *LeaderboardService) GetPrivacySettings(kids []models.Kid) ([]models.KidLeaderboardSettings, error) {
+	ids := make([]int64, len(kids))
+	for i, kid := range kids {
+		ids[i] = kid.ID
+	}
+	settings, err := s.leaderboardRepo.GetSettingsForKids(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.KidLeaderboardSettings, 0, len(kids))
+	for _, kid := range kids {
+		kidSettings, ok := settings[kid.ID]
+		if !ok {
+			kidSettings = models.LeaderboardSettings{KidID: kid.ID}
+		}
+		result = append(result, models.KidLeaderboardSettings{
+			Kid:             kid,
+			Settings:        kidSettings,
+			DefaultNickname: DefaultNickname(kid.ID),
+		})
+	}
+	return result, nil
+}
+
+// SetParentPrivacy saves a kid's nickname and whether their parents have taken
+// them off leaderboards. Access to the kid is checked by the caller.
+func (s *LeaderboardService) SetParentPrivacy(kidID int64, nickname string, hidden bool) error {
+	return s.savePrivacy(kidID, nickname, func(settings *models.LeaderboardSettings) {
+		settings.HiddenByParent = hidden
+	})
+}
+
+// SetTeacherPrivacy saves a kid's nickname and whether a teacher has taken them
+// off leaderboards. Access to the kid is checked by the caller.
+func (s *LeaderboardService) SetTeacherPrivacy(kidID int64, nickname string, hidden bool) error {
+	return s.savePrivacy(kidID, nickname, func(settings *models.LeaderboardSettings) {
+		settings.HiddenByTeacher = hidden
+	})
+}
+
+func (s *LeaderboardService) savePrivacy(kidID int64, nickname string, setHidden func(*models.LeaderboardSettings)) error {
+	nickname, err := s.validateNickname(nickname)
+	if err != nil {
+		return err
+	}
+
+	settings, err := s.leaderboardRepo.GetSettings(kidID)
+	if err != nil {
+		return err
+	}
+	if settings == nil {
+		settings = &models.LeaderboardSettings{KidID: kidID}
+	}
+	settings.Nickname = nickname
+	setHidden(settings)
+	settings.UpdatedAt = time.Now()
+	return s.leaderboardRepo.SaveSettings(settings)
+}
+
+// validateNickname tidies a nickname and checks it is short and clean. An empty
+// nickname means a generated one is used.
+func (s *LeaderboardService) validateNickname(nickname string) (string, error) {
+	nickname = strings.Join(strings.Fields(nickname), " ")
+	if nickname == "" {
+		return "", nil
+	}
+	if utf8.RuneCountInString(nickname) > maxNicknameLength {
+		return "", ErrInvalidNickname
+	}
+
+	validation, err := s.listRepo.ValidateWords(strings.Fields(nickname), "")
+	if err != nil {
+		return "", fmt.Errorf("failed to check nickname: %w", err)
+	}
+	if len(validation.BadWords) > 0 {
+		return "", ErrInvalidNickname
+	}
+	return nickname, nil
+}
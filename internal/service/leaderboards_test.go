@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"testing"
+	"time"
+)
+
+// newLeaderboardTestService seeds two families, with Ada and Ben in FAM1 and Cy in
+// FAM2, and a teacher with Ada and Cy on their roster
+func newLeaderboardTestService(t *testing.T) *LeaderboardService {
+	t.Helper()
+	db := newTestDB(t)
+	now := time.Now()
+	monthAgo := now.AddDate(0, -1, 0)
+
+	seed := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"INSERT INTO users (id, email, password_hash, name) VALUES (1, 'parent@example.com', 'x', 'Parent')", nil},
+		{"INSERT INTO users (id, email, password_hash, name, is_teacher) VALUES (2, 'teacher@example.com', 'x', 'Ms Reed', 1)", nil},
+		{"INSERT INTO families (family_code) VALUES ('FAM1'), ('FAM2')", nil},
+		{"INSERT INTO kids (id, family_code, name, username, password) VALUES (1, 'FAM1', 'Ada', 'ada1', 'x'), (2, 'FAM1', 'Ben', 'ben1', 'x'), (3, 'FAM2', 'Cy', 'cy1', 'x')", nil},
+		{"INSERT INTO teacher_kid_relationships (teacher_user_id, kid_id) VALUES (2, 1), (2, 3)", nil},
+		{"INSERT INTO spelling_lists (id, name, description, family_code, is_public, locale, created_by) VALUES (1, 'Week 1', '', 'FAM1', 0, 'en-GB', 1)", nil},
+		{"INSERT INTO words (id, spelling_list_id, word_text, position) VALUES (1, 1, 'cat', 0)", nil},
+		{"INSERT INTO list_assignments (spelling_list_id, kid_id, assigned_by) VALUES (1, 1, 2), (1, 3, 2)", nil},
+		{"INSERT INTO bad_words (word) VALUES ('poo')", nil},
+		// Ada scored 30 this week
+		{"INSERT INTO practice_sessions (id, kid_id, spelling_list_id, started_at, completed_at, total_words) VALUES (1, 1, 1, ?, ?, 1)", []interface{}{now, now}},
+		{"INSERT INTO word_attempts (practice_session_id, word_id, attempt_text, is_correct, time_taken_ms, points_earned, attempted_at) VALUES (1, 1, 'cat', 1, 1000, 30, ?)", []interface{}{now}},
+		// Ben scored 50 last month and 30 this week
+		{"INSERT INTO practice_sessions (id, kid_id, spelling_list_id, started_at, completed_at, total_words) VALUES (2, 2, 1, ?, ?, 1)", []interface{}{monthAgo, monthAgo}},
+		{"INSERT INTO word_attempts (practice_session_id, word_id, attempt_text, is_correct, time_taken_ms, points_earned, attempted_at) VALUES (2, 1, 'cat', 1, 1000, 50, ?)", []interface{}{monthAgo}},
+		{"INSERT INTO hangman_sessions (id, kid_id, spelling_list_id, started_at, completed_at, total_games) VALUES (1, 2, 1, ?, ?, 1)", []interface{}{now, now}},
+		{"INSERT INTO hangman_games (session_id, kid_id, word_id, word, is_won, points_earned, started_at, completed_at) VALUES (1, 2, 1, 'cat', 1, 30, ?, ?)", []interface{}{now, now}},
+		// Cy scored 20 this week
+		{"INSERT INTO missing_letter_sessions (id, kid_id, spelling_list_id, started_at, completed_at, total_games) VALUES (1, 3, 1, ?, ?, 2)", []interface{}{now, now}},
+		{"INSERT INTO missing_letter_games (session_id, kid_id, word_id, word, missing_indices, is_won, points_earned, started_at, completed_at) VALUES (1, 3, 1, 'cat', '[1]', 1, 20, ?, ?)", []interface{}{now, now}},
+	}
+	for _, s := range seed {
+		if _, err := db.Exec(s.query, s.args...); err != nil {
+			t.Fatalf("failed to seed %q: %v", s.query, err)
+		}
+	}
+
+	streaks := NewStreakService(repository.NewStreakRepository(db), time.UTC)
+	return NewLeaderboardService(
+		repository.NewLeaderboardRepository(db),
+		repository.NewKidRepository(db),
+		repository.NewTeacherKidRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewListRepository(db),
+		streaks,
+	)
+}
+
+type wantEntry struct {
+	rank   int
+	name   string
+	points int
+}
+
+func checkBoard(t *testing.T, board *models.Leaderboard, want []wantEntry) {
+	t.Helper()
+	if len(board.Entries) != len(want) {
+		t.Fatalf("%s board has %d entries, want %d: %+v", board.Title, len(board.Entries), len(want), board.Entries)
+	}
+	for i, entry := range board.Entries {
+		if entry.Rank != want[i].rank || entry.DisplayName != want[i].name || entry.Points != want[i].points {
+			t.Errorf("%s board entry %d = #%d %s %d, want #%d %s %d", board.Title, i, entry.Rank, entry.DisplayName, entry.Points, want[i].rank, want[i].name, want[i].points)
+		}
+	}
+}
+
+func TestFamilyBoard(t *testing.T) {
+	leaderboards := newLeaderboardTestService(t)
+
+	// Ada and Ben tie this week
+	week, err := leaderboards.FamilyBoard("FAM1", models.LeaderboardWeek, 1)
+	if err != nil {
+		t.Fatalf("FamilyBoard() error: %v", err)
+	}
+	checkBoard(t, week, []wantEntry{{1, "Ada", 30}, {1, "Ben", 30}})
+	if !week.Entries[0].IsViewer || week.Entries[1].IsViewer {
+		t.Errorf("FamilyBoard() viewer flags = %v, %v, want only Ada", week.Entries[0].IsViewer, week.Entries[1].IsViewer)
+	}
+
+	all, err := leaderboards.FamilyBoard("FAM1", models.LeaderboardAllTime, 0)
+	if err != nil {
+		t.Fatalf("FamilyBoard() error: %v", err)
+	}
+	checkBoard(t, all, []wantEntry{{1, "Ben", 80}, {2, "Ada", 30}})
+}
+
+func TestRosterBoardUsesNicknames(t *testing.T) {
+	leaderboards := newLeaderboardTestService(t)
+
+	roster, err := leaderboards.RosterBoard(2, models.LeaderboardWeek, 0)
+	if err != nil {
+		t.Fatalf("RosterBoard() error: %v", err)
+	}
+	if roster.Title != "Ms Reed's class" {
+		t.Errorf("RosterBoard() title = %q, want Ms Reed's class", roster.Title)
+	}
+	checkBoard(t, roster, []wantEntry{{1, DefaultNickname(1), 30}, {2, DefaultNickname(3), 20}})
+
+	if err := leaderboards.SetParentPrivacy(1, "  Speedy   Speller ", false); err != nil {
+		t.Fatalf("SetParentPrivacy() error: %v", err)
+	}
+	if roster, err = leaderboards.RosterBoard(2, models.LeaderboardWeek, 0); err != nil {
+		t.Fatalf("RosterBoard() error: %v", err)
+	}
+	checkBoard(t, roster, []wantEntry{{1, "Speedy Speller", 30}, {2, DefaultNickname(3), 20}})
+}
+
+func TestLeaderboardPrivacy(t *testing.T) {
+	leaderboards := newLeaderboardTestService(t)
+
+	// Cy's parents take them off leaderboards, and the teacher can't put them back
+	if err := leaderboards.SetParentPrivacy(3, "", true); err != nil {
+		t.Fatalf("SetParentPrivacy() error: %v", err)
+	}
+	if err := leaderboards.SetTeacherPrivacy(3, "", false); err != nil {
+		t.Fatalf("SetTeacherPrivacy() error: %v", err)
+	}
+	roster, err := leaderboards.RosterBoard(2, models.LeaderboardWeek, 0)
+	if err != nil {
+		t.Fatalf("RosterBoard() error: %v", err)
+	}
+	checkBoard(t, roster, []wantEntry{{1, DefaultNickname(1), 30}})
+
+	cy := &models.Kid{ID: 3, FamilyCode: "FAM2", Name: "Cy"}
+	if boards, hidden, err := leaderboards.GetKidBoards(cy, models.LeaderboardWeek); err != nil || !hidden || len(boards) != 0 {
+		t.Errorf("GetKidBoards() for a hidden kid = %d boards, hidden %v, %v, want none and hidden", len(boards), hidden, err)
+	}
+
+	// Ada sees her family, her class and the list assigned to her and Cy
+	ada := &models.Kid{ID: 1, FamilyCode: "FAM1", Name: "Ada"}
+	boards, hidden, err := leaderboards.GetKidBoards(ada, models.LeaderboardAllTime)
+	if err != nil || hidden {
+		t.Fatalf("GetKidBoards() = hidden %v, %v", hidden, err)
+	}
+	scopes := []string{models.LeaderboardFamily, models.LeaderboardRoster, models.LeaderboardAssignment}
+	if len(boards) != len(scopes) {
+		t.Fatalf("GetKidBoards() returned %d boards, want %d", len(boards), len(scopes))
+	}
+	for i, board := range boards {
+		if board.Scope != scopes[i] {
+			t.Errorf("board %d scope = %s, want %s", i, board.Scope, scopes[i])
+		}
+		for _, entry := range board.Entries {
+			if board.Scope != models.LeaderboardFamily && entry.DisplayName == "Ada" {
+				t.Errorf("%s board shows Ada's real name", board.Scope)
+			}
+		}
+	}
+}
+
+func TestNicknameValidation(t *testing.T) {
+	leaderboards := newLeaderboardTestService(t)
+
+	for _, nickname := range []string{"Poo Head", "A nickname far too long for any board"} {
+		if err := leaderboards.SetParentPrivacy(1, nickname, false); !errors.Is(err, ErrInvalidNickname) {
+			t.Errorf("SetParentPrivacy(%q) error = %v, want ErrInvalidNickname", nickname, err)
+		}
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	// Late on Sunday in UTC is already Monday in London
+	now := time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC)
+	if got, want := weekStart(now, london), time.Date(2026, 10, 19, 0, 0, 0, 0, london); !got.Equal(want) {
+		t.Errorf("weekStart(London) = %v, want %v", got, want)
+	}
+	if got, want := weekStart(now, time.UTC), time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("weekStart(UTC) = %v, want %v", got, want)
+	}
+}
@@ -15,8 +15,9 @@ import (
 )
 
 var (
-	ErrListNotFound = errors.New("list not found")
-	ErrWordNotFound = errors.New("word not found")
+	ErrListNotFound      = errors.New("list not found")
+	ErrWordNotFound      = errors.New("word not found")
+	ErrUnsupportedLocale = errors.New("unsupported list language")
 )
 
 // WordListData represents the structure of word list JSON files
@@ -24,6 +25,7 @@ type WordListData struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Difficulty  int    `json:"difficulty"`
+	Locale      string `json:"locale"` // Optional, defaults to models.DefaultLocale
 	Words       []struct {
 		Word       string `json:"word"`
 		Definition string `json:"definition"`
@@ -36,17 +38,19 @@ type ListService struct {
 	familyRepo     *repository.FamilyRepository
 	userRepo       *repository.UserRepository
 	teacherKidRepo *repository.TeacherKidRepository
+	classRepo      *repository.TeacherClassRepository
 	ttsService     *audio.TTSService
 	dataPath       string
 }
 
 // NewListService creates a new list service
-func NewListService(listRepo *repository.ListRepository, familyRepo *repository.FamilyRepository, userRepo *repository.UserRepository, teacherKidRepo *repository.TeacherKidRepository, ttsService *audio.TTSService) *ListService {
+func NewListService(listRepo *repository.ListRepository, familyRepo *repository.FamilyRepository, userRepo *repository.UserRepository, teacherKidRepo *repository.TeacherKidRepository, classRepo *repository.TeacherClassRepository, ttsService *audio.TTSService) *ListService {
 	return &ListService{
 		listRepo:       listRepo,
 		familyRepo:     familyRepo,
 		userRepo:       userRepo,
 		teacherKidRepo: teacherKidRepo,
+		classRepo:      classRepo,
 		ttsService:     ttsService,
 		dataPath:       "data", // Default data path
 	}
@@ -59,6 +63,12 @@ func (s *ListService) SetDataPath(path string) {
 
 // hasAccessToList checks if a user can access a list (either it's public or they're in the family)
 func (s *ListService) hasAccessToList(userID int64, list *models.SpellingList) (bool, error) {
+	return canAccessList(s.familyRepo, userID, list)
+}
+
+// canAccessList checks if a user can access a list: public lists, lists they
+// created and private lists belonging to one of their families
+func canAccessList(familyRepo *repository.FamilyRepository, userID int64, list *models.SpellingList) (bool, error) {
 	// Public lists are accessible to everyone
 	if list.IsPublic {
 		return true, nil
@@ -74,7 +84,7 @@ func (s *ListService) hasAccessToList(userID int64, list *models.SpellingList) (
 		return false, nil
 	}
 
-	isMember, err := s.familyRepo.IsFamilyMember(userID, *list.FamilyCode)
+	isMember, err := familyRepo.IsFamilyMember(userID, *list.FamilyCode)
 	if err != nil {
 		return false, fmt.Errorf("failed to verify family access: %w", err)
 	}
@@ -166,8 +176,13 @@ func (s *ListService) seedListFromFile(filename string) error {
 
 	log.Printf("Creating default public list '%s'...", listData.Name)
 
+	locale, ok := models.NormalizeLocale(listData.Locale)
+	if !ok {
+		return fmt.Errorf("unsupported locale %q in %s", listData.Locale, filename)
+	}
+
 	// Create the public list
-	list, err := s.listRepo.CreatePublicList(listData.Name, listData.Description)
+	list, err := s.listRepo.CreatePublicList(listData.Name, listData.Description, locale)
 	if err != nil {
 		return fmt.Errorf("failed to create %s public list: %w", listData.Name, err)
 	}
@@ -184,7 +199,7 @@ func (s *ListService) seedListFromFile(filename string) error {
 
 		// Generate audio file for the word
 		if s.ttsService != nil {
-			audioFilename, err := s.ttsService.GenerateAudioFile(wordData.Word)
+			audioFilename, err := s.ttsService.GenerateAudioFile(wordData.Word, list.Locale)
 			if err != nil {
 				log.Printf("Warning: Failed to generate audio for '%s': %v", wordData.Word, err)
 			} else {
@@ -198,7 +213,7 @@ func (s *ListService) seedListFromFile(filename string) error {
 			// Generate audio for definition if provided
 			if wordData.Definition != "" {
 				definitionPrefix := fmt.Sprintf("definition_%s", wordData.Word)
-				definitionAudioFilename, err := s.ttsService.GenerateAudioFileWithPrefix(wordData.Definition, definitionPrefix)
+				definitionAudioFilename, err := s.ttsService.GenerateAudioFileWithPrefix(wordData.Definition, definitionPrefix, list.Locale)
 				if err != nil {
 					log.Printf("Warning: Failed to generate definition audio for '%s': %v", wordData.Word, err)
 				} else {
@@ -232,7 +247,7 @@ func (s *ListService) seedCombinedList(listName, description string, difficulty
 	log.Printf("Creating default public list '%s'...", listName)
 
 	// Create the public list
-	list, err := s.listRepo.CreatePublicList(listName, description)
+	list, err := s.listRepo.CreatePublicList(listName, description, models.DefaultLocale)
 	if err != nil {
 		return fmt.Errorf("failed to create %s public list: %w", listName, err)
 	}
@@ -278,7 +293,7 @@ func (s *ListService) seedCombinedList(listName, description string, difficulty
 
 		// Generate audio file for the word
 		if s.ttsService != nil {
-			audioFilename, err := s.ttsService.GenerateAudioFile(wordData.Word)
+			audioFilename, err := s.ttsService.GenerateAudioFile(wordData.Word, list.Locale)
 			if err != nil {
 				log.Printf("Warning: Failed to generate audio for '%s': %v", wordData.Word, err)
 			} else {
@@ -292,7 +307,7 @@ func (s *ListService) seedCombinedList(listName, description string, difficulty
 			// Generate audio for definition if provided
 			if wordData.Definition != "" {
 				definitionPrefix := fmt.Sprintf("definition_%s", wordData.Word)
-				definitionAudioFilename, err := s.ttsService.GenerateAudioFileWithPrefix(wordData.Definition, definitionPrefix)
+				definitionAudioFilename, err := s.ttsService.GenerateAudioFileWithPrefix(wordData.Definition, definitionPrefix, list.Locale)
 				if err != nil {
 					log.Printf("Warning: Failed to generate definition audio for '%s': %v", wordData.Word, err)
 				} else {
@@ -310,8 +325,8 @@ func (s *ListService) seedCombinedList(listName, description string, difficulty
 	return nil
 }
 
-// CreateList creates a new spelling list
-func (s *ListService) CreateList(familyCode string, userID int64, name, description string) (*models.SpellingList, error) {
+// CreateList creates a new spelling list spoken in the given locale (empty for the default)
+func (s *ListService) CreateList(familyCode string, userID int64, name, description, locale string) (*models.SpellingList, error) {
 	user, err := s.userRepo.GetUserByID(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -325,8 +340,13 @@ func (s *ListService) CreateList(familyCode string, userID int64, name, descript
 		return nil, errors.New("list name is required")
 	}
 
+	locale, ok := models.NormalizeLocale(locale)
+	if !ok {
+		return nil, ErrUnsupportedLocale
+	}
+
 	if user.IsTeacher {
-		list, err := s.listRepo.CreateTeacherList(name, description, userID)
+		list, err := s.listRepo.CreateTeacherList(name, description, locale, userID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create teacher list: %w", err)
 		}
@@ -343,7 +363,7 @@ func (s *ListService) CreateList(familyCode string, userID int64, name, descript
 	}
 
 	// Create list
-	list, err := s.listRepo.CreateList(familyCode, name, description, userID)
+	list, err := s.listRepo.CreateList(familyCode, name, description, locale, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create list: %w", err)
 	}
@@ -463,8 +483,9 @@ func (s *ListService) GetAllUserListsWithAssignments(userID int64) ([]models.Lis
 	return allLists, nil
 }
 
-// UpdateList updates a list's name and description
-func (s *ListService) UpdateList(listID, userID int64, name, description string) error {
+// UpdateList updates a list's name, description and locale.
+// Changing the locale regenerates the audio for the list's words in the background.
+func (s *ListService) UpdateList(listID, userID int64, name, description, locale string) error {
 	// Get list to verify family access
 	list, err := s.GetList(listID)
 	if err != nil {
@@ -490,14 +511,76 @@ func (s *ListService) UpdateList(listID, userID int64, name, description string)
 		return errors.New("list name is required")
 	}
 
+	// Validate locale, keeping the current one if none was given
+	if strings.TrimSpace(locale) == "" {
+		locale = list.Locale
+	}
+	locale, ok := models.NormalizeLocale(locale)
+	if !ok {
+		return ErrUnsupportedLocale
+	}
+
 	// Update list
-	if err := s.listRepo.UpdateList(listID, name, description); err != nil {
+	if err := s.listRepo.UpdateList(listID, name, description, locale); err != nil {
 		return fmt.Errorf("failed to update list: %w", err)
 	}
 
+	if locale != list.Locale {
+		// Generating audio for a whole list can outlast the request, like a bulk import
+		go s.regenerateListAudio(listID, locale)
+	}
+
 	return nil
 }
 
+// regenerateListAudio regenerates word and definition audio for every word in a list,
+// e.g. after its locale changes. A word keeps its current audio if none could be
+// generated for the new locale, such as with TTS disabled. It stops early if the
+// locale changes again, leaving the newer run to finish the job. Audio that is no
+// longer referenced is removed by CleanupOrphanedAudioFiles on the next startup.
+func (s *ListService) regenerateListAudio(listID int64, locale string) {
+	if s.ttsService == nil {
+		return
+	}
+
+	words, err := s.listRepo.GetListWords(listID)
+	if err != nil {
+		log.Printf("Warning: Failed to get words to regenerate audio for list %d: %v", listID, err)
+		return
+	}
+
+	for _, word := range words {
+		list, err := s.listRepo.GetListByID(listID)
+		if err != nil || list == nil || list.Locale != locale {
+			return
+		}
+
+		audioFilename, err := s.ttsService.GenerateAudioFile(word.WordText, locale)
+		if err != nil && !errors.Is(err, audio.ErrTTSDisabled) {
+			log.Printf("Warning: Failed to regenerate audio for '%s': %v", word.WordText, err)
+		}
+		if err == nil && audioFilename != "" {
+			if err := s.listRepo.UpdateWordAudio(word.ID, audioFilename); err != nil {
+				log.Printf("Warning: Failed to update audio filename for word %d: %v", word.ID, err)
+			}
+		}
+
+		if word.Definition == "" {
+			continue
+		}
+		definitionPrefix := fmt.Sprintf("definition_%s", word.WordText)
+		definitionAudioFilename, err := s.ttsService.GenerateAudioFileWithPrefix(word.Definition, definitionPrefix, locale)
+		if err != nil && !errors.Is(err, audio.ErrTTSDisabled) {
+			log.Printf("Warning: Failed to regenerate definition audio for '%s': %v", word.WordText, err)
+		}
+		if err == nil && definitionAudioFilename != "" {
+			if err := s.listRepo.UpdateWordDefinitionAudio(word.ID, definitionAudioFilename); err != nil {
+				log.Printf("Warning: Failed to update definition audio filename for word %d: %v", word.ID, err)
+			}
+		}
+	}
+}
+
 // DeleteList deletes a spelling list
 func (s *ListService) DeleteList(listID, userID int64) error {
 	// Get list to verify family access
@@ -594,14 +677,15 @@ func (s *ListService) AddWord(listID, userID int64, wordText string, difficulty
 		return nil, errors.New("word text is required")
 	}
 
-	// Check against bad words filter
-	badWords, err := s.listRepo.ValidateWords([]string{wordText})
+	// Check against bad words filter and dictionary
+	validation, err := s.listRepo.ValidateWords([]string{wordText}, list.Locale)
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate word: %w", err)
 	}
-	if len(badWords) > 0 {
+	if len(validation.BadWords) > 0 {
 		return nil, fmt.Errorf("inappropriate word detected: '%s' is not allowed", wordText)
 	}
+	logSpellingWarnings(listID, validation.Warnings)
 
 	// Validate difficulty
 	if difficulty < 1 || difficulty > 5 {
@@ -625,7 +709,7 @@ func (s *ListService) AddWord(listID, userID int64, wordText string, difficulty
 
 	// Automatically generate audio file for the word
 	if s.ttsService != nil {
-		audioFilename, err := s.ttsService.GenerateAudioFile(wordText)
+		audioFilename, err := s.ttsService.GenerateAudioFile(wordText, list.Locale)
 		if err != nil {
 			log.Printf("Warning: Failed to generate audio for '%s': %v", wordText, err)
 			// Don't fail the word creation, just log the warning
@@ -642,7 +726,7 @@ func (s *ListService) AddWord(listID, userID int64, wordText string, difficulty
 		// Generate audio for definition if provided
 		if definition != "" {
 			definitionPrefix := fmt.Sprintf("definition_%s", wordText)
-			definitionAudioFilename, err := s.ttsService.GenerateAudioFileWithPrefix(definition, definitionPrefix)
+			definitionAudioFilename, err := s.ttsService.GenerateAudioFileWithPrefix(definition, definitionPrefix, list.Locale)
 			if err != nil {
 				log.Printf("Warning: Failed to generate definition audio for '%s': %v", wordText, err)
 			} else {
@@ -716,14 +800,15 @@ func (s *ListService) BulkAddWords(listID, userID int64, wordsText string, diffi
 		return errors.New("no valid words found")
 	}
 
-	// Validate words against bad words filter
-	badWords, err := s.listRepo.ValidateWords(cleanWords)
+	// Validate words against bad words filter and dictionary
+	validation, err := s.listRepo.ValidateWords(cleanWords, list.Locale)
 	if err != nil {
 		return fmt.Errorf("failed to validate words: %w", err)
 	}
-	if len(badWords) > 0 {
-		return fmt.Errorf("inappropriate words detected: %v - these words are not allowed", badWords)
+	if len(validation.BadWords) > 0 {
+		return fmt.Errorf("inappropriate words detected: %v - these words are not allowed", validation.BadWords)
 	}
+	logSpellingWarnings(listID, validation.Warnings)
 
 	// Get current word count for positioning
 	count, err := s.listRepo.GetWordCount(listID)
@@ -743,7 +828,7 @@ func (s *ListService) BulkAddWords(listID, userID int64, wordsText string, diffi
 
 		// Automatically generate audio file
 		if s.ttsService != nil {
-			audioFilename, err := s.ttsService.GenerateAudioFile(wordText)
+			audioFilename, err := s.ttsService.GenerateAudioFile(wordText, list.Locale)
 			if err != nil {
 				log.Printf("Warning: Failed to generate audio for '%s': %v", wordText, err)
 			} else {
@@ -821,14 +906,15 @@ func (s *ListService) BulkAddWordsWithProgress(listID, userID int64, wordsText s
 		return errors.New("no valid words found")
 	}
 
-	// Validate words against bad words filter
-	badWords, err := s.listRepo.ValidateWords(cleanWords)
+	// Validate words against bad words filter and dictionary
+	validation, err := s.listRepo.ValidateWords(cleanWords, list.Locale)
 	if err != nil {
 		return fmt.Errorf("failed to validate words: %w", err)
 	}
-	if len(badWords) > 0 {
-		return fmt.Errorf("inappropriate words detected: %v - these words are not allowed", badWords)
+	if len(validation.BadWords) > 0 {
+		return fmt.Errorf("inappropriate words detected: %v - these words are not allowed", validation.BadWords)
 	}
+	logSpellingWarnings(listID, validation.Warnings)
 
 	// Get current word count for positioning
 	count, err := s.listRepo.GetWordCount(listID)
@@ -860,7 +946,7 @@ func (s *ListService) BulkAddWordsWithProgress(listID, userID int64, wordsText s
 
 		// Automatically generate audio file
 		if s.ttsService != nil {
-			audioFilename, err := s.ttsService.GenerateAudioFile(wordText)
+			audioFilename, err := s.ttsService.GenerateAudioFile(wordText, list.Locale)
 			if err != nil {
 				log.Printf("Warning: Failed to generate audio for '%s': %v", wordText, err)
 			} else {
@@ -989,9 +1075,9 @@ func (s *ListService) UpdateWord(wordID, userID int64, wordText string, difficul
 		return fmt.Errorf("failed to update word: %w", err)
 	}
 
-	// Generate audio for the word
-	audioFilename, err := s.ttsService.GenerateAudioFile(wordText)
-	if err != nil {
+	// Generate audio for the word (left empty when TTS is disabled so stale audio isn't played)
+	audioFilename, err := s.ttsService.GenerateAudioFile(wordText, list.Locale)
+	if err != nil && !errors.Is(err, audio.ErrTTSDisabled) {
 		return fmt.Errorf("failed to generate word audio: %w", err)
 	}
 	if err := s.listRepo.UpdateWordAudio(wordID, audioFilename); err != nil {
@@ -1001,8 +1087,8 @@ func (s *ListService) UpdateWord(wordID, userID int64, wordText string, difficul
 	// Generate audio for definition if provided
 	if definition != "" {
 		definitionPrefix := fmt.Sprintf("definition_%s", wordText)
-		definitionAudioFilename, err := s.ttsService.GenerateAudioFileWithPrefix(definition, definitionPrefix)
-		if err != nil {
+		definitionAudioFilename, err := s.ttsService.GenerateAudioFileWithPrefix(definition, definitionPrefix, list.Locale)
+		if err != nil && !errors.Is(err, audio.ErrTTSDisabled) {
 			return fmt.Errorf("failed to generate definition audio: %w", err)
 		}
 		if err := s.listRepo.UpdateWordDefinitionAudio(wordID, definitionAudioFilename); err != nil {
@@ -1226,6 +1312,96 @@ func (s *ListService) AssignListToTeacherClass(listID, teacherUserID int64, dueD
 	return assigned, nil
 }
 
+// AssignListToClass assigns a list to one of a teacher's classes with optional due date.
+// Children who join the class later are given the list too.
+func (s *ListService) AssignListToClass(listID, classID, teacherUserID int64, dueDate *time.Time) (int, error) {
+	class, err := s.getTeacherActiveClass(teacherUserID, classID)
+	if err != nil {
+		return 0, err
+	}
+
+	list, err := s.GetList(listID)
+	if err != nil {
+		return 0, err
+	}
+	hasAccess, err := s.hasAccessToList(teacherUserID, list)
+	if err != nil {
+		return 0, fmt.Errorf("failed to verify access: %w", err)
+	}
+	if !hasAccess {
+		return 0, ErrNotFamilyMember
+	}
+
+	if err := s.classRepo.SetClassList(class.ID, listID, dueDate); err != nil {
+		return 0, err
+	}
+
+	kids, err := s.classRepo.GetClassMembers(class.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get class members: %w", err)
+	}
+
+	assigned := 0
+	for _, kid := range kids {
+		if err := s.listRepo.AssignListToKid(listID, kid.ID, teacherUserID, true, dueDate); err != nil {
+			return assigned, fmt.Errorf("failed assigning list to %s: %w", kid.Name, err)
+		}
+		assigned++
+	}
+
+	return assigned, nil
+}
+
+// UnassignListFromClass removes a list from one of a teacher's classes. Children
+// keep the list if a parent assigned it or they get it through another class.
+func (s *ListService) UnassignListFromClass(listID, classID, teacherUserID int64) (int, error) {
+	class, err := s.getTeacherActiveClass(teacherUserID, classID)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := s.classRepo.RemoveClassList(class.ID, listID); err != nil {
+		return 0, err
+	}
+
+	kids, err := s.classRepo.GetClassMembers(class.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get class members: %w", err)
+	}
+
+	unassigned := 0
+	for _, kid := range kids {
+		removed, err := removeClassListFromKid(s.classRepo, s.listRepo, class.ID, listID, kid.ID)
+		if err != nil {
+			return unassigned, fmt.Errorf("failed unassigning list from %s: %w", kid.Name, err)
+		}
+		if removed {
+			unassigned++
+		}
+	}
+
+	return unassigned, nil
+}
+
+func (s *ListService) getTeacherActiveClass(teacherUserID, classID int64) (*models.TeacherClass, error) {
+	user, err := s.userRepo.GetUserByID(teacherUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get teacher user: %w", err)
+	}
+	if user == nil || !user.IsTeacher {
+		return nil, ErrTeacherRequired
+	}
+
+	class, err := getTeacherClass(s.classRepo, teacherUserID, classID)
+	if err != nil {
+		return nil, err
+	}
+	if class.IsArchived() {
+		return nil, ErrClassArchived
+	}
+	return class, nil
+}
+
 // GetKidAssignedLists retrieves all lists assigned to a kid
 func (s *ListService) GetKidAssignedLists(kidID int64) ([]models.SpellingList, error) {
 	lists, err := s.listRepo.GetKidAssignedLists(kidID)
@@ -1260,11 +1436,42 @@ func (s *ListService) GetListAssignedKids(listID, userID int64) ([]models.Kid, e
 	return kids, nil
 }
 
+// GetSpellingWarnings returns likely typos among a list's words, keyed by word text.
+// Words that are in the dictionary for the list's locale have no entry.
+func (s *ListService) GetSpellingWarnings(list *models.SpellingList, words []models.Word) map[string]*models.SpellingWarning {
+	texts := make([]string, len(words))
+	for i, word := range words {
+		texts[i] = word.WordText
+	}
+
+	warnings := make(map[string]*models.SpellingWarning)
+	for _, warning := range s.listRepo.CheckSpelling(texts, list.Locale) {
+		warnings[warning.Word] = &warning
+	}
+	return warnings
+}
+
+// logSpellingWarnings records words that may be misspelled; they are still added,
+// since lists often contain names and specialist vocabulary
+func logSpellingWarnings(listID int64, warnings []models.SpellingWarning) {
+	for _, warning := range warnings {
+		if warning.Suggestion != "" {
+			log.Printf("Word '%s' added to list %d is not in the dictionary (did you mean '%s'?)", warning.Word, listID, warning.Suggestion)
+		} else {
+			log.Printf("Word '%s' added to list %d is not in the dictionary", warning.Word, listID)
+		}
+	}
+}
+
 // GenerateMissingAudio checks all words and generates any missing audio files
 func (s *ListService) GenerateMissingAudio() error {
 	if s.ttsService == nil {
 		return nil // TTS service not configured, skip
 	}
+	if !s.ttsService.Enabled() {
+		log.Println("Text-to-speech is disabled, skipping audio generation")
+		return nil
+	}
 
 	log.Println("Checking for missing audio files...")
 
@@ -1277,10 +1484,25 @@ func (s *ListService) GenerateMissingAudio() error {
 	wordAudioGenerated := 0
 	definitionAudioGenerated := 0
 
+	// Cache list lookups, since words are ordered by list
+	lists := make(map[int64]*models.SpellingList)
+
 	for _, word := range words {
+		list, ok := lists[word.SpellingListID]
+		if !ok {
+			list, err = s.listRepo.GetListByID(word.SpellingListID)
+			if err != nil {
+				return fmt.Errorf("failed to get list %d: %w", word.SpellingListID, err)
+			}
+			lists[word.SpellingListID] = list
+		}
+		if list == nil {
+			continue
+		}
+
 		// Check and generate word audio if missing
 		if word.AudioFilename == "" {
-			audioFilename, err := s.ttsService.GenerateAudioFile(word.WordText)
+			audioFilename, err := s.ttsService.GenerateAudioFile(word.WordText, list.Locale)
 			if err != nil {
 				log.Printf("Warning: Failed to generate audio for word '%s' (ID: %d): %v", word.WordText, word.ID, err)
 			} else {
@@ -1296,7 +1518,7 @@ func (s *ListService) GenerateMissingAudio() error {
 		// Check and generate definition audio if missing
 		if word.Definition != "" && word.DefinitionAudioFilename == "" {
 			definitionPrefix := fmt.Sprintf("definition_%s", word.WordText)
-			definitionAudioFilename, err := s.ttsService.GenerateAudioFileWithPrefix(word.Definition, definitionPrefix)
+			definitionAudioFilename, err := s.ttsService.GenerateAudioFileWithPrefix(word.Definition, definitionPrefix, list.Locale)
 			if err != nil {
 				log.Printf("Warning: Failed to generate definition audio for '%s' (ID: %d): %v", word.WordText, word.ID, err)
 			} else {
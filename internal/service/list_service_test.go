@@ -0,0 +1,33 @@
+package service
+
+import (
+	"spellingclash/internal/audio"
+	"spellingclash/internal/repository"
+	"testing"
+)
+
+func TestRegenerateListAudioKeepsAudioWithTTSDisabled(t *testing.T) {
+	db := newTestDB(t)
+	seed := []string{
+		"INSERT INTO families (family_code) VALUES ('FAM1')",
+		"INSERT INTO spelling_lists (id, name, description, family_code, is_public, locale) VALUES (1, 'Week 1', '', 'FAM1', 0, 'en-US')",
+		"INSERT INTO words (id, spelling_list_id, word_text, definition, audio_filename, definition_audio_filename, position) VALUES (1, 1, 'cat', 'a pet', 'word_cat_en-gb.mp3', 'definition_cat_en-gb.mp3', 0)",
+	}
+	for _, query := range seed {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("failed to seed %q: %v", query, err)
+		}
+	}
+
+	listRepo := repository.NewListRepository(db)
+	lists := NewListService(listRepo, nil, nil, nil, nil, audio.NewTTSService(t.TempDir(), audio.NewNoneProvider()))
+	lists.regenerateListAudio(1, "en-US")
+
+	words, err := listRepo.GetListWords(1)
+	if err != nil {
+		t.Fatalf("GetListWords() error: %v", err)
+	}
+	if words[0].AudioFilename != "word_cat_en-gb.mp3" || words[0].DefinitionAudioFilename != "definition_cat_en-gb.mp3" {
+		t.Errorf("audio = %q and %q, want the existing files kept", words[0].AudioFilename, words[0].DefinitionAudioFilename)
+	}
+}
@@ -56,13 +56,18 @@ func reorderWordsByIDs(words []models.Word, idString string) []models.Word {
 type PracticeService struct {
 	practiceRepo *repository.PracticeRepository
 	listRepo     *repository.ListRepository
+	scheduleRepo *repository.WordScheduleRepository
+	achievements *AchievementService
 }
 
-// NewPracticeService creates a new practice service
-func NewPracticeService(practiceRepo *repository.PracticeRepository, listRepo *repository.ListRepository) *PracticeService {
+// NewPracticeService creates a new practice service. Achievements are checked
+// after each session is completed, unless achievements is nil.
+func NewPracticeService(practiceRepo *repository.PracticeRepository, listRepo *repository.ListRepository, scheduleRepo *repository.WordScheduleRepository, achievements *AchievementService) *PracticeService {
 	return &PracticeService{
 		practiceRepo: practiceRepo,
 		listRepo:     listRepo,
+		scheduleRepo: scheduleRepo,
+		achievements: achievements,
 	}
 }
 
@@ -78,16 +83,10 @@ func (s *PracticeService) StartPracticeSession(kidID, listID int64) (*models.Pra
 		return nil, nil, errors.New("list has no words")
 	}
 
-	var selectedWords []models.Word
-
-	// If list has more than 20 words, select 20 with weighted randomization
-	if len(allWords) > 20 {
-		selectedWords, err = s.selectWeightedWords(kidID, allWords, 20)
-		if err != nil {
-			return nil, nil, err
-		}
-	} else {
-		selectedWords = allWords
+	// Pick due and overdue words first using the kid's spaced-repetition schedule
+	selectedWords, err := s.selectScheduledWords(kidID, allWords)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Randomize the order of selected words
@@ -104,88 +103,27 @@ func (s *PracticeService) StartPracticeSession(kidID, listID int64) (*models.Pra
 	return session, selectedWords, nil
 }
 
-// selectWeightedWords selects words based on performance history
-// Words with lower success rates have higher probability of being selected
-func (s *PracticeService) selectWeightedWords(kidID int64, words []models.Word, count int) ([]models.Word, error) {
-	if count >= len(words) {
-		return words, nil
-	}
-
+// selectScheduledWords selects the words a kid should practise now.
+// Words the kid has never been scheduled on are ordered by their historical success rate.
+func (s *PracticeService) selectScheduledWords(kidID int64, words []models.Word) ([]models.Word, error) {
 	// Get word IDs
 	wordIDs := make([]int64, len(words))
 	for i, word := range words {
 		wordIDs[i] = word.ID
 	}
 
+	schedules, err := s.scheduleRepo.GetSchedulesForKid(kidID, wordIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get word schedules: %w", err)
+	}
+
 	// Get performance statistics
 	performance, err := s.practiceRepo.GetWordPerformanceForKid(kidID, wordIDs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get word performance: %w", err)
 	}
 
-	// Calculate weights for each word
-	// Lower success rate = higher weight
-	// Words never attempted get medium-high weight
-	type weightedWord struct {
-		word   models.Word
-		weight float64
-	}
-
-	weightedWords := make([]weightedWord, len(words))
-	for i, word := range words {
-		perf, exists := performance[word.ID]
-		var weight float64
-		
-		if !exists || perf.TotalAttempts == 0 {
-			// Never attempted: medium-high weight (0.7)
-			weight = 0.7
-		} else {
-			// Attempted: inverse of success rate
-			// 100% success = 0.1 weight
-			// 0% success = 1.0 weight
-			weight = 1.0 - (perf.SuccessRate * 0.9)
-		}
-		
-		weightedWords[i] = weightedWord{
-			word:   word,
-			weight: weight,
-		}
-	}
-
-	// Use weighted random selection
-	selected := make([]models.Word, 0, count)
-	remaining := make([]weightedWord, len(weightedWords))
-	copy(remaining, weightedWords)
-
-	for i := 0; i < count && len(remaining) > 0; i++ {
-		// Calculate total weight
-		totalWeight := 0.0
-		for _, ww := range remaining {
-			totalWeight += ww.weight
-		}
-
-		// Pick a random point in the total weight
-		r := rand.Float64() * totalWeight
-
-		// Find which word corresponds to that point
-		cumWeight := 0.0
-		selectedIdx := 0
-		for idx, ww := range remaining {
-			cumWeight += ww.weight
-			if r <= cumWeight {
-				selectedIdx = idx
-				break
-			}
-		}
-
-		// Add selected word to result
-		selected = append(selected, remaining[selectedIdx].word)
-
-		// Remove selected word from remaining
-		remaining = append(remaining[:selectedIdx], remaining[selectedIdx+1:]...)
-	}
-
-	return selected, nil
+	return selectDueWords(words, schedules, performance, time.Now()), nil
 }
 
 // CheckAnswer checks if the answer is correct and calculates points
@@ -212,9 +150,29 @@ func (s *PracticeService) CheckAnswer(sessionID, wordID int64, answer string, ti
 		return false, 0, err
 	}
 
+	// Update the spaced-repetition schedule so the word's next due date reflects this answer
+	if err := s.reviewWord(sessionID, wordID, answerQuality(isCorrect, answer, timeTakenMs)); err != nil {
+		return false, 0, err
+	}
+
 	return isCorrect, points, nil
 }
 
+// reviewWord records an SM-2 review of a word for the kid who owns the session
+func (s *PracticeService) reviewWord(sessionID, wordID int64, quality int) error {
+	session, err := s.practiceRepo.GetSessionByID(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get practice session: %w", err)
+	}
+
+	current, err := s.scheduleRepo.GetSchedule(session.KidID, wordID)
+	if err != nil {
+		return fmt.Errorf("failed to get word schedule: %w", err)
+	}
+
+	return s.scheduleRepo.SaveSchedule(nextSchedule(current, session.KidID, wordID, quality, time.Now()))
+}
+
 // calculatePoints calculates points based on difficulty and speed
 // Formula: basePoints = difficulty * 10 (10-50 points)
 //          speedBonus = max(0, 50 - (timeTakenMs / 100)) up to 50 bonus points
@@ -261,7 +219,14 @@ func (s *PracticeService) CompleteSession(sessionID int64) (*models.PracticeSess
 	}
 
 	// Return updated session
-	return s.practiceRepo.GetSessionByID(sessionID)
+	session, err := s.practiceRepo.GetSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session != nil {
+		s.achievements.afterActivity(session.KidID)
+	}
+	return session, nil
 }
 
 // GetSessionResults retrieves session results with attempt details
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"spellingclash/internal/models"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// sessionTouchInterval is how often a session's last seen time and device are updated
+	sessionTouchInterval = time.Minute
+
+	// maxSessionClientLength caps the stored user agent and IP address
+	maxSessionClientLength = 255
+)
+
+// TouchSession records that a session was just used, and from which device
+func (s *AuthService) TouchSession(sessionID, userAgent, ipAddress string) error {
+	now := time.Now()
+	return s.userRepo.TouchSession(sessionID, clipSessionClient(userAgent), clipSessionClient(ipAddress), now, now.Add(-sessionTouchInterval))
+}
+
+// GetSessions returns a user's active sessions, most recently used first
+func (s *AuthService) GetSessions(userID int64) ([]models.Session, error) {
+	return s.userRepo.GetUserSessions(userID)
+}
+
+// RevokeSession signs out one of a user's sessions, found by its handle
+func (s *AuthService) RevokeSession(userID int64, handle string) error {
+	sessions, err := s.userRepo.GetUserSessions(userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.Handle() != handle {
+			continue
+		}
+		deleted, err := s.userRepo.DeleteUserSession(userID, session.ID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return ErrSessionNotFound
+		}
+		return nil
+	}
+	return ErrSessionNotFound
+}
+
+// RevokeOtherSessions signs out all of a user's sessions except the current one,
+// returning how many were signed out
+func (s *AuthService) RevokeOtherSessions(userID int64, currentSessionID string) (int64, error) {
+	return s.userRepo.DeleteUserSessions(userID, currentSessionID)
+}
+
+// clipSessionClient limits a client-supplied value to what the sessions table stores
+func clipSessionClient(value string) string {
+	if len(value) <= maxSessionClientLength {
+		return value
+	}
+	value = value[:maxSessionClientLength]
+	for !utf8.ValidString(value) {
+		value = value[:len(value)-1]
+	}
+	return value
+}
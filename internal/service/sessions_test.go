@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionManagement(t *testing.T) {
+	auth := newAuthTestService(t)
+	user, err := auth.Register("parent@example.com", "correct horse", "Parent", "")
+	if err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+
+	var sessionIDs []string
+	for range 3 {
+		session, _, err := auth.Login("parent@example.com", "correct horse", LoginClient{IP: "203.0.113.7"})
+		if err != nil {
+			t.Fatalf("Login() error: %v", err)
+		}
+		sessionIDs = append(sessionIDs, session.ID)
+	}
+	current := sessionIDs[0]
+
+	if err := auth.TouchSession(current, "Mozilla/5.0 "+strings.Repeat("x", 300), "203.0.113.7"); err != nil {
+		t.Fatalf("TouchSession() error: %v", err)
+	}
+	sessions, err := auth.GetSessions(user.ID)
+	if err != nil {
+		t.Fatalf("GetSessions() error: %v", err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("GetSessions() returned %d sessions, want 3", len(sessions))
+	}
+	touched := sessions[0]
+	if touched.ID != current || touched.LastSeenAt == nil || touched.IPAddress != "203.0.113.7" {
+		t.Errorf("GetSessions()[0] = %+v, want the touched session first", touched)
+	}
+	if len(touched.UserAgent) != maxSessionClientLength {
+		t.Errorf("stored user agent is %d bytes, want it clipped to %d", len(touched.UserAgent), maxSessionClientLength)
+	}
+
+	// Another user's session can't be revoked by handle
+	other, err := auth.Register("other@example.com", "correct horse", "Other", "")
+	if err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	if err := auth.RevokeSession(other.ID, sessions[1].Handle()); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("RevokeSession() of someone else's session = %v, want ErrSessionNotFound", err)
+	}
+	if err := auth.RevokeSession(user.ID, sessions[1].Handle()); err != nil {
+		t.Fatalf("RevokeSession() error: %v", err)
+	}
+	if _, err := auth.ValidateSession(sessions[1].ID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("ValidateSession() of a revoked session = %v, want ErrSessionNotFound", err)
+	}
+
+	if revoked, err := auth.RevokeOtherSessions(user.ID, current); err != nil || revoked != 1 {
+		t.Errorf("RevokeOtherSessions() = %d, %v, want 1", revoked, err)
+	}
+	if _, err := auth.ValidateSession(current); err != nil {
+		t.Errorf("ValidateSession() of the current session = %v, want it kept", err)
+	}
+}
+
+func TestResetPasswordRevokesSessions(t *testing.T) {
+	auth := newAuthTestService(t)
+	user, err := auth.Register("parent@example.com", "correct horse", "Parent", "")
+	if err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	session, _, err := auth.Login("parent@example.com", "correct horse", LoginClient{IP: "203.0.113.7"})
+	if err != nil {
+		t.Fatalf("Login() error: %v", err)
+	}
+
+	if _, err := auth.apiTokenRepo.CreateToken(user.ID, "School MIS", "token-hash", "sc_abc"); err != nil {
+		t.Fatalf("CreateToken() error: %v", err)
+	}
+	if _, err := auth.twoFactorRepo.CreateLoginChallenge("challenge", user.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("CreateLoginChallenge() error: %v", err)
+	}
+
+	if err := auth.userRepo.CreatePasswordResetToken("reset-token", user.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("CreatePasswordResetToken() error: %v", err)
+	}
+	if err := auth.ResetPassword("reset-token", "battery staple", "203.0.113.7"); err != nil {
+		t.Fatalf("ResetPassword() error: %v", err)
+	}
+	if _, err := auth.ValidateSession(session.ID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("ValidateSession() after a password reset = %v, want ErrSessionNotFound", err)
+	}
+	if count, err := auth.apiTokenRepo.CountUserTokens(user.ID); err != nil || count != 0 {
+		t.Errorf("CountUserTokens() after a password reset = %d, %v, want 0", count, err)
+	}
+	if challenge, err := auth.twoFactorRepo.GetLoginChallenge("challenge"); err != nil || challenge != nil {
+		t.Errorf("GetLoginChallenge() after a password reset = %+v, %v, want none", challenge, err)
+	}
+}
+
+func TestSetPasswordRevokesOtherSessions(t *testing.T) {
+	auth := newAuthTestService(t)
+	user, err := auth.Register("parent@example.com", "correct horse", "Parent", "")
+	if err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	current, _, err := auth.Login("parent@example.com", "correct horse", LoginClient{IP: "203.0.113.7"})
+	if err != nil {
+		t.Fatalf("Login() error: %v", err)
+	}
+	other, _, err := auth.Login("parent@example.com", "correct horse", LoginClient{IP: "198.51.100.1"})
+	if err != nil {
+		t.Fatalf("Login() error: %v", err)
+	}
+
+	if err := auth.SetPassword(user.ID, "correct horse", "battery staple", current.ID); err != nil {
+		t.Fatalf("SetPassword() error: %v", err)
+	}
+	if _, err := auth.ValidateSession(current.ID); err != nil {
+		t.Errorf("ValidateSession() of the session that changed the password = %v, want it kept", err)
+	}
+	if _, err := auth.ValidateSession(other.ID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("ValidateSession() of another session = %v, want ErrSessionNotFound", err)
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"strings"
+	"time"
+)
+
+const (
+	// maxSessionWords caps the number of words drilled in one practice session
+	maxSessionWords = 20
+	// minSessionWords is the smallest session we build when few words are due,
+	// topped up with the words that will fall due soonest
+	minSessionWords = 5
+
+	defaultEaseFactor = 2.5
+	minEaseFactor     = 1.3
+
+	// Answers quicker than fastAnswerMs are treated as effortless recall,
+	// answers slower than slowAnswerMs as hard-won recall
+	fastAnswerMs = 5000
+	slowAnswerMs = 15000
+)
+
+// answerQuality grades an answer on the SM-2 0-5 scale
+func answerQuality(isCorrect bool, answer string, timeTakenMs int) int {
+	if !isCorrect {
+		if strings.TrimSpace(answer) == "" {
+			return 0 // Complete blackout
+		}
+		return 1
+	}
+
+	switch {
+	case timeTakenMs <= fastAnswerMs:
+		return 5
+	case timeTakenMs >= slowAnswerMs:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// nextSchedule applies an SM-2 review of the given quality to a schedule.
+// A nil schedule is treated as a word the kid has never reviewed.
+// Failed words are due again immediately so they come back in the next session,
+// while each successful review pushes the word further into the future.
+func nextSchedule(current *models.WordSchedule, kidID, wordID int64, quality int, now time.Time) *models.WordSchedule {
+	schedule := models.WordSchedule{
+		KidID:      kidID,
+		WordID:     wordID,
+		EaseFactor: defaultEaseFactor,
+	}
+	if current != nil {
+		schedule = *current
+	}
+
+	if quality >= 3 {
+		switch schedule.Repetitions {
+		case 0:
+			schedule.IntervalDays = 1
+		case 1:
+			schedule.IntervalDays = 6
+		default:
+			schedule.IntervalDays = int(math.Round(float64(schedule.IntervalDays) * schedule.EaseFactor))
+		}
+		schedule.Repetitions++
+	} else {
+		schedule.Repetitions = 0
+		schedule.IntervalDays = 0
+		schedule.Lapses++
+	}
+
+	q := float64(5 - quality)
+	schedule.EaseFactor += 0.1 - q*(0.08+q*0.02)
+	if schedule.EaseFactor < minEaseFactor {
+		schedule.EaseFactor = minEaseFactor
+	}
+
+	reviewedAt := now
+	schedule.LastReviewedAt = &reviewedAt
+	schedule.DueAt = now.AddDate(0, 0, schedule.IntervalDays)
+
+	return &schedule
+}
+
+// selectDueWords picks the words for a practice session.
+// Due and overdue words come first (most overdue, then hardest), followed by
+// words that have never been scheduled (those with the worst practice history first).
+// Words that are not yet due are only used to top the session up to minSessionWords,
+// so mastered words fade out rather than being re-drilled every session.
+func selectDueWords(words []models.Word, schedules map[int64]*models.WordSchedule, performance map[int64]*repository.WordPerformance, now time.Time) []models.Word {
+	var due, unscheduled, notDue []models.Word
+	for _, word := range words {
+		schedule, exists := schedules[word.ID]
+		switch {
+		case !exists:
+			unscheduled = append(unscheduled, word)
+		case schedule.IsDue(now):
+			due = append(due, word)
+		default:
+			notDue = append(notDue, word)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		a, b := schedules[due[i].ID], schedules[due[j].ID]
+		if !a.DueAt.Equal(b.DueAt) {
+			return a.DueAt.Before(b.DueAt)
+		}
+		return a.EaseFactor < b.EaseFactor
+	})
+
+	sort.SliceStable(unscheduled, func(i, j int) bool {
+		return historyWeight(performance[unscheduled[i].ID]) > historyWeight(performance[unscheduled[j].ID])
+	})
+
+	sort.SliceStable(notDue, func(i, j int) bool {
+		return schedules[notDue[i].ID].DueAt.Before(schedules[notDue[j].ID].DueAt)
+	})
+
+	selected := make([]models.Word, 0, maxSessionWords)
+	selected = appendUpTo(selected, due, maxSessionWords)
+	selected = appendUpTo(selected, unscheduled, maxSessionWords)
+
+	minimum := minSessionWords
+	if len(words) < minimum {
+		minimum = len(words)
+	}
+	selected = appendUpTo(selected, notDue, minimum)
+
+	return selected
+}
+
+// historyWeight ranks unscheduled words by their legacy practice history.
+// Lower success rates get higher weight; words never attempted sit in the middle.
+func historyWeight(perf *repository.WordPerformance) float64 {
+	if perf == nil || perf.TotalAttempts == 0 {
+		return 0.7
+	}
+	return 1.0 - (perf.SuccessRate * 0.9)
+}
+
+// appendUpTo appends words to dst until it holds limit entries
+func appendUpTo(dst, words []models.Word, limit int) []models.Word {
+	for _, word := range words {
+		if len(dst) >= limit {
+			break
+		}
+		dst = append(dst, word)
+	}
+	return dst
+}
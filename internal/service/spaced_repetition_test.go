@@ -0,0 +1,154 @@
+package service
+
+import (
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestAnswerQuality(t *testing.T) {
+	tests := []struct {
+		name        string
+		isCorrect   bool
+		answer      string
+		timeTakenMs int
+		expected    int
+	}{
+		{name: "blank answer", isCorrect: false, answer: "  ", timeTakenMs: 3000, expected: 0},
+		{name: "wrong answer", isCorrect: false, answer: "cta", timeTakenMs: 3000, expected: 1},
+		{name: "fast correct", isCorrect: true, answer: "cat", timeTakenMs: 2000, expected: 5},
+		{name: "correct", isCorrect: true, answer: "cat", timeTakenMs: 8000, expected: 4},
+		{name: "slow correct", isCorrect: true, answer: "cat", timeTakenMs: 20000, expected: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := answerQuality(tt.isCorrect, tt.answer, tt.timeTakenMs); got != tt.expected {
+				t.Errorf("answerQuality() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNextSchedule(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	// First successful review is due the next day
+	first := nextSchedule(nil, 1, 10, 5, now)
+	if first.Repetitions != 1 || first.IntervalDays != 1 {
+		t.Fatalf("first review: got reps=%d interval=%d, want 1/1", first.Repetitions, first.IntervalDays)
+	}
+	if !first.DueAt.Equal(now.AddDate(0, 0, 1)) {
+		t.Errorf("first review due at %v, want %v", first.DueAt, now.AddDate(0, 0, 1))
+	}
+	if first.EaseFactor <= defaultEaseFactor {
+		t.Errorf("perfect recall should raise ease factor, got %.2f", first.EaseFactor)
+	}
+
+	// Second successful review jumps to six days
+	second := nextSchedule(first, 1, 10, 4, now)
+	if second.Repetitions != 2 || second.IntervalDays != 6 {
+		t.Fatalf("second review: got reps=%d interval=%d, want 2/6", second.Repetitions, second.IntervalDays)
+	}
+
+	// Further reviews grow by the ease factor
+	third := nextSchedule(second, 1, 10, 4, now)
+	if third.IntervalDays <= second.IntervalDays {
+		t.Errorf("third review interval %d should exceed %d", third.IntervalDays, second.IntervalDays)
+	}
+
+	// A lapse resets the word so it is due again straight away
+	lapsed := nextSchedule(third, 1, 10, 1, now)
+	if lapsed.Repetitions != 0 || lapsed.IntervalDays != 0 || lapsed.Lapses != 1 {
+		t.Fatalf("lapse: got reps=%d interval=%d lapses=%d, want 0/0/1", lapsed.Repetitions, lapsed.IntervalDays, lapsed.Lapses)
+	}
+	if !lapsed.IsDue(now) {
+		t.Error("lapsed word should be due immediately")
+	}
+	if lapsed.EaseFactor >= third.EaseFactor {
+		t.Errorf("lapse should lower ease factor: %.2f >= %.2f", lapsed.EaseFactor, third.EaseFactor)
+	}
+}
+
+func TestNextScheduleEaseFloor(t *testing.T) {
+	now := time.Now()
+	schedule := nextSchedule(nil, 1, 1, 0, now)
+	for i := 0; i < 10; i++ {
+		schedule = nextSchedule(schedule, 1, 1, 0, now)
+	}
+	if schedule.EaseFactor != minEaseFactor {
+		t.Errorf("ease factor = %.2f, want floor %.2f", schedule.EaseFactor, minEaseFactor)
+	}
+}
+
+func TestSelectDueWords(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	words := make([]models.Word, 0, 8)
+	for i := int64(1); i <= 8; i++ {
+		words = append(words, models.Word{ID: i})
+	}
+
+	schedules := map[int64]*models.WordSchedule{
+		// Overdue by two days
+		1: {WordID: 1, EaseFactor: 2.5, DueAt: now.AddDate(0, 0, -2)},
+		// Due now
+		2: {WordID: 2, EaseFactor: 2.5, DueAt: now},
+		// Mastered words, not due for a while
+		3: {WordID: 3, EaseFactor: 2.7, DueAt: now.AddDate(0, 0, 30)},
+		4: {WordID: 4, EaseFactor: 2.7, DueAt: now.AddDate(0, 0, 10)},
+		5: {WordID: 5, EaseFactor: 2.7, DueAt: now.AddDate(0, 0, 20)},
+		6: {WordID: 6, EaseFactor: 2.7, DueAt: now.AddDate(0, 0, 40)},
+	}
+	performance := map[int64]*repository.WordPerformance{
+		// Word 8 has a poor legacy history, so it outranks the never-attempted word 7
+		8: {WordID: 8, TotalAttempts: 4, CorrectAttempts: 0, SuccessRate: 0},
+	}
+
+	selected := selectDueWords(words, schedules, performance, now)
+
+	got := make([]int64, len(selected))
+	for i, word := range selected {
+		got[i] = word.ID
+	}
+
+	// Due words (most overdue first), then unscheduled words, then one soon-due word to reach the minimum
+	expected := []int64{1, 2, 8, 7, 4}
+	if len(got) != len(expected) {
+		t.Fatalf("selected %v, want %v", got, expected)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("selected %v, want %v", got, expected)
+		}
+	}
+}
+
+func TestSelectDueWordsCapsSession(t *testing.T) {
+	now := time.Now()
+	words := make([]models.Word, 0, 30)
+	for i := int64(1); i <= 30; i++ {
+		words = append(words, models.Word{ID: i})
+	}
+
+	selected := selectDueWords(words, map[int64]*models.WordSchedule{}, nil, now)
+	if len(selected) != maxSessionWords {
+		t.Errorf("selected %d words, want %d", len(selected), maxSessionWords)
+	}
+}
+
+func TestSelectDueWordsSmallList(t *testing.T) {
+	now := time.Now()
+	words := []models.Word{{ID: 1}, {ID: 2}}
+	schedules := map[int64]*models.WordSchedule{
+		1: {WordID: 1, EaseFactor: 2.5, DueAt: now.AddDate(0, 0, 5)},
+		2: {WordID: 2, EaseFactor: 2.5, DueAt: now.AddDate(0, 0, 3)},
+	}
+
+	// Nothing is due, but the kid still gets a session from the whole (small) list
+	selected := selectDueWords(words, schedules, nil, now)
+	if len(selected) != 2 || selected[0].ID != 2 {
+		t.Errorf("selected %+v, want both words with word 2 first", selected)
+	}
+}
@@ -0,0 +1,539 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"spellingclash/internal/audio"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	minBeeKids      = 2
+	maxBeeMisses    = 5
+	beeHistorySize  = 20
+	beeRunningLimit = 5
+)
+
+var (
+	ErrBeeNotFound      = errors.New("spelling bee not found")
+	ErrBeeNeedsKids     = errors.New("a spelling bee needs at least two children")
+	ErrBeeMissesInvalid = errors.New("children must be allowed between one and five misses")
+	ErrBeeModeInvalid   = errors.New("unknown spelling bee answer mode")
+	ErrBeeListEmpty     = errors.New("the list has no words to dictate")
+	ErrBeeFinished      = errors.New("this spelling bee has finished")
+	ErrBeeTurnOpen      = errors.New("the current word hasn't been answered yet")
+	ErrBeeNoTurn        = errors.New("there is no word waiting for an answer")
+	ErrBeeNotYourTurn   = errors.New("it isn't this child's turn to type an answer")
+)
+
+// SpellingBeeService handles classroom spelling bees a teacher hosts. Words are
+// dictated to each child still in the bee in turn, and a child is out once they
+// have missed as many words as the bee allows.
+type SpellingBeeService struct {
+	beeRepo         *repository.SpellingBeeRepository
+	listRepo        *repository.ListRepository
+	teacherKidsRepo *repository.TeacherKidRepository
+	familyRepo      *repository.FamilyRepository
+	ttsService      *audio.TTSService
+	mu              sync.Mutex // Serialises turns so a double click can't dictate two words
+}
+
+// NewSpellingBeeService creates a new spelling bee service
+func NewSpellingBeeService(beeRepo *repository.SpellingBeeRepository, listRepo *repository.ListRepository, teacherKidsRepo *repository.TeacherKidRepository, familyRepo *repository.FamilyRepository, ttsService *audio.TTSService) *SpellingBeeService {
+	return &SpellingBeeService{
+		beeRepo:         beeRepo,
+		listRepo:        listRepo,
+		teacherKidsRepo: teacherKidsRepo,
+		familyRepo:      familyRepo,
+		ttsService:      ttsService,
+	}
+}
+
+// CreateBee starts a bee on a list for some of a teacher's children, who take
+// turns in a random order
+func (s *SpellingBeeService) CreateBee(teacherUserID, listID int64, kidIDs []int64, maxMisses int, answerMode string, now time.Time) (*models.SpellingBee, error) {
+	if maxMisses < 1 || maxMisses > maxBeeMisses {
+		return nil, ErrBeeMissesInvalid
+	}
+	if answerMode != models.BeeTeacherMarks && answerMode != models.BeeKidsType {
+		return nil, ErrBeeModeInvalid
+	}
+
+	seen := make(map[int64]bool)
+	var order []int64
+	for _, kidID := range kidIDs {
+		if seen[kidID] {
+			continue
+		}
+		seen[kidID] = true
+		linked, err := s.teacherKidsRepo.IsTeacherLinkedToKid(teacherUserID, kidID)
+		if err != nil {
+			return nil, err
+		}
+		if !linked {
+			return nil, ErrTeacherKidLink
+		}
+		order = append(order, kidID)
+	}
+	if len(order) < minBeeKids {
+		return nil, ErrBeeNeedsKids
+	}
+
+	list, err := s.listRepo.GetListByID(listID)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, ErrListNotFound
+	}
+	hasAccess, err := canAccessList(s.familyRepo, teacherUserID, list)
+	if err != nil {
+		return nil, err
+	}
+	if !hasAccess {
+		return nil, ErrListNotFound
+	}
+	wordCount, err := s.listRepo.GetWordCount(listID)
+	if err != nil {
+		return nil, err
+	}
+	if wordCount == 0 {
+		return nil, ErrBeeListEmpty
+	}
+
+	rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	beeID, err := s.beeRepo.CreateBee(teacherUserID, listID, maxMisses, answerMode, order, now)
+	if err != nil {
+		return nil, err
+	}
+	return s.beeRepo.GetBee(beeID)
+}
+
+// GetTeacherBees retrieves the bees a teacher has hosted, newest first
+func (s *SpellingBeeService) GetTeacherBees(teacherUserID int64) ([]models.SpellingBee, error) {
+	return s.beeRepo.GetTeacherBees(teacherUserID, beeHistorySize)
+}
+
+// GetTeacherBee retrieves a bee hosted by the teacher
+func (s *SpellingBeeService) GetTeacherBee(teacherUserID, beeID int64) (*models.SpellingBee, error) {
+	bee, err := s.beeRepo.GetBee(beeID)
+	if err != nil {
+		return nil, err
+	}
+	if bee == nil || bee.TeacherUserID != teacherUserID {
+		return nil, ErrBeeNotFound
+	}
+	return bee, nil
+}
+
+// GetKidBee retrieves a bee the kid is taking part in
+func (s *SpellingBeeService) GetKidBee(kidID, beeID int64) (*models.SpellingBee, error) {
+	bee, err := s.beeRepo.GetBee(beeID)
+	if err != nil {
+		return nil, err
+	}
+	if bee == nil {
+		return nil, ErrBeeNotFound
+	}
+	participants, err := s.beeRepo.GetParticipants(beeID)
+	if err != nil {
+		return nil, err
+	}
+	if findBeeParticipant(participants, kidID) == nil {
+		return nil, ErrBeeNotFound
+	}
+	return bee, nil
+}
+
+// GetTeacherState retrieves a bee hosted by the teacher with its standings and current word
+func (s *SpellingBeeService) GetTeacherState(teacherUserID, beeID int64) (*models.SpellingBeeState, error) {
+	bee, err := s.GetTeacherBee(teacherUserID, beeID)
+	if err != nil {
+		return nil, err
+	}
+	return s.state(bee)
+}
+
+// GetKidState retrieves a bee the kid is taking part in with its standings. The
+// current word is left out, since its audio files are named after it; children
+// only learn whether it can be played and hear it through TurnAudio.
+func (s *SpellingBeeService) GetKidState(kidID, beeID int64) (*models.SpellingBeeState, error) {
+	bee, err := s.GetKidBee(kidID, beeID)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.state(bee)
+	if err != nil {
+		return nil, err
+	}
+	if state.Word != nil {
+		state.HasAudio = state.Word.AudioFilename != ""
+		state.HasDefinitionAudio = state.Word.DefinitionAudioFilename != ""
+		state.Word = nil
+	}
+	if state.Turn != nil && state.Turn.IsOpen() {
+		state.Turn.WordText = ""
+	}
+	return state, nil
+}
+
+// TurnAudio returns the path of the audio for the word, or its definition, a
+// kid has to type in a bee where children answer on their devices. It is only
+// available to the speller while their turn is open.
+func (s *SpellingBeeService) TurnAudio(kidID, beeID int64, definition bool) (string, error) {
+	bee, err := s.GetKidBee(kidID, beeID)
+	if err != nil {
+		return "", err
+	}
+	state, err := s.state(bee)
+	if err != nil {
+		return "", err
+	}
+	turn := state.Turn
+	if !bee.IsRunning() || bee.AnswerMode != models.BeeKidsType || turn == nil || !turn.IsOpen() || turn.KidID != kidID {
+		return "", ErrBeeNotYourTurn
+	}
+
+	filename := ""
+	if state.Word != nil {
+		filename = state.Word.AudioFilename
+		if definition {
+			filename = state.Word.DefinitionAudioFilename
+		}
+	}
+	if filename == "" || s.ttsService == nil {
+		return "", ErrBeeNotFound
+	}
+	return s.ttsService.AudioPath(filename), nil
+}
+
+// GetKidHistory retrieves the finished bees a kid took part in, newest first
+func (s *SpellingBeeService) GetKidHistory(kidID int64) ([]models.KidSpellingBee, error) {
+	return s.beeRepo.GetKidBees(kidID, models.BeeFinished, beeHistorySize)
+}
+
+// GetKidRunningBees retrieves the bees a kid is taking part in right now
+func (s *SpellingBeeService) GetKidRunningBees(kidID int64) ([]models.KidSpellingBee, error) {
+	return s.beeRepo.GetKidBees(kidID, models.BeeRunning, beeRunningLimit)
+}
+
+// NextTurn dictates a word nobody has had yet to the next child still in the
+// bee. The bee finishes when the list runs out of words.
+func (s *SpellingBeeService) NextTurn(teacherUserID, beeID int64, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bee, err := s.GetTeacherBee(teacherUserID, beeID)
+	if err != nil {
+		return err
+	}
+	if !bee.IsRunning() {
+		return ErrBeeFinished
+	}
+	participants, err := s.beeRepo.GetParticipants(beeID)
+	if err != nil {
+		return err
+	}
+	turns, err := s.beeRepo.GetTurns(beeID)
+	if err != nil {
+		return err
+	}
+
+	lastTurnOrder := 0
+	if len(turns) > 0 {
+		last := turns[len(turns)-1]
+		if last.IsOpen() {
+			return ErrBeeTurnOpen
+		}
+		if p := findBeeParticipant(participants, last.KidID); p != nil {
+			lastTurnOrder = p.TurnOrder
+		}
+	}
+
+	words, err := s.unusedWords(bee, turns)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return s.finish(bee, participants, now)
+	}
+
+	speller := nextBeeSpeller(participants, lastTurnOrder)
+	if speller == nil {
+		return s.finish(bee, participants, now)
+	}
+	word := words[rand.Intn(len(words))]
+	if _, err := s.beeRepo.CreateTurn(beeID, len(turns)+1, speller.KidID, word.ID, word.WordText, now); err != nil {
+		return err
+	}
+	return s.beeRepo.BumpVersion(beeID, now)
+}
+
+// MarkTurn records the teacher's verdict on the word being spelled. Teachers
+// can mark in either answer mode, for when a child can't use their device.
+func (s *SpellingBeeService) MarkTurn(teacherUserID, beeID int64, correct bool, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bee, err := s.GetTeacherBee(teacherUserID, beeID)
+	if err != nil {
+		return err
+	}
+	return s.judge(bee, 0, "", &correct, now)
+}
+
+// Answer records the spelling a child typed for their own word, in bees where
+// children answer on their devices
+func (s *SpellingBeeService) Answer(kidID, beeID int64, answer string, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bee, err := s.GetKidBee(kidID, beeID)
+	if err != nil {
+		return err
+	}
+	if bee.AnswerMode != models.BeeKidsType {
+		return ErrBeeNotYourTurn
+	}
+	answer = strings.TrimSpace(answer)
+	if utf8.RuneCountInString(answer) > maxTestAnswerLen {
+		answer = string([]rune(answer)[:maxTestAnswerLen])
+	}
+	return s.judge(bee, kidID, answer, nil, now)
+}
+
+// EndBee finishes a bee early, ranking the children on how they have done so far
+func (s *SpellingBeeService) EndBee(teacherUserID, beeID int64, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bee, err := s.GetTeacherBee(teacherUserID, beeID)
+	if err != nil {
+		return err
+	}
+	if !bee.IsRunning() {
+		return ErrBeeFinished
+	}
+	participants, err := s.beeRepo.GetParticipants(beeID)
+	if err != nil {
+		return err
+	}
+	return s.finish(bee, participants, now)
+}
+
+// judge closes the open turn and scores it. A typed answer is checked against
+// the word when correct is nil; kidID, if set, must be the child spelling it.
+// Must be called with mu held.
+func (s *SpellingBeeService) judge(bee *models.SpellingBee, kidID int64, answer string, correct *bool, now time.Time) error {
+	if !bee.IsRunning() {
+		return ErrBeeFinished
+	}
+	turns, err := s.beeRepo.GetTurns(bee.ID)
+	if err != nil {
+		return err
+	}
+	if len(turns) == 0 || !turns[len(turns)-1].IsOpen() {
+		return ErrBeeNoTurn
+	}
+	turn := turns[len(turns)-1]
+	if kidID != 0 && turn.KidID != kidID {
+		return ErrBeeNotYourTurn
+	}
+
+	isCorrect := strings.EqualFold(answer, strings.TrimSpace(turn.WordText))
+	if correct != nil {
+		isCorrect = *correct
+	}
+	answered, err := s.beeRepo.AnswerTurn(turn.ID, answer, isCorrect, now)
+	if err != nil {
+		return err
+	}
+	if !answered {
+		return ErrBeeNoTurn
+	}
+
+	participants, err := s.beeRepo.GetParticipants(bee.ID)
+	if err != nil {
+		return err
+	}
+	speller := findBeeParticipant(participants, turn.KidID)
+	if speller == nil {
+		return s.beeRepo.BumpVersion(bee.ID, now)
+	}
+	if isCorrect {
+		speller.WordsCorrect++
+	} else {
+		speller.Misses++
+		if speller.Misses >= bee.MaxMisses {
+			eliminatedTurn := turn.TurnNumber
+			speller.EliminatedTurn = &eliminatedTurn
+		}
+	}
+	if err := s.beeRepo.UpdateParticipant(speller, now); err != nil {
+		return err
+	}
+
+	remaining := 0
+	for i := range participants {
+		if !participants[i].IsOut() {
+			remaining++
+		}
+	}
+	if remaining <= 1 {
+		return s.finish(bee, participants, now)
+	}
+	return s.beeRepo.BumpVersion(bee.ID, now)
+}
+
+// finish ranks the children and saves their places: children still in come
+// first, then those who went out later, then fewer misses and more words
+// correct. Children level on all of those share a place.
+func (s *SpellingBeeService) finish(bee *models.SpellingBee, participants []models.SpellingBeeParticipant, now time.Time) error {
+	ranked := make([]models.SpellingBeeParticipant, len(participants))
+	copy(ranked, participants)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return beeRankKey(&ranked[i]).beats(beeRankKey(&ranked[j]))
+	})
+
+	places := make(map[int64]int, len(ranked))
+	place := 0
+	for i := range ranked {
+		if i == 0 || beeRankKey(&ranked[i-1]) != beeRankKey(&ranked[i]) {
+			place = i + 1
+		}
+		places[ranked[i].ID] = place
+	}
+	return s.beeRepo.FinishBee(bee.ID, places, now)
+}
+
+// beeRank is what a child is ranked on when a bee finishes
+type beeRank struct {
+	outOnTurn    int // 0 for children still in
+	misses       int
+	wordsCorrect int
+}
+
+func beeRankKey(p *models.SpellingBeeParticipant) beeRank {
+	rank := beeRank{misses: p.Misses, wordsCorrect: p.WordsCorrect}
+	if p.EliminatedTurn != nil {
+		rank.outOnTurn = *p.EliminatedTurn
+	}
+	return rank
+}
+
+// beats reports whether r ranks above other
+func (r beeRank) beats(other beeRank) bool {
+	if (r.outOnTurn == 0) != (other.outOnTurn == 0) {
+		return r.outOnTurn == 0
+	}
+	if r.outOnTurn != other.outOnTurn {
+		return r.outOnTurn > other.outOnTurn
+	}
+	if r.misses != other.misses {
+		return r.misses < other.misses
+	}
+	return r.wordsCorrect > other.wordsCorrect
+}
+
+// state gathers a bee's standings, current turn and the words left to dictate
+func (s *SpellingBeeService) state(bee *models.SpellingBee) (*models.SpellingBeeState, error) {
+	participants, err := s.beeRepo.GetParticipants(bee.ID)
+	if err != nil {
+		return nil, err
+	}
+	turns, err := s.beeRepo.GetTurns(bee.ID)
+	if err != nil {
+		return nil, err
+	}
+	words, err := s.unusedWords(bee, turns)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &models.SpellingBeeState{
+		Bee:          *bee,
+		Participants: participants,
+		WordsLeft:    len(words),
+	}
+	if !bee.IsRunning() {
+		sort.SliceStable(state.Participants, func(i, j int) bool {
+			return beePlace(&state.Participants[i]) < beePlace(&state.Participants[j])
+		})
+	}
+	if len(turns) > 0 {
+		turn := turns[len(turns)-1]
+		state.Turn = &turn
+		state.Speller = findBeeParticipant(state.Participants, turn.KidID)
+		if turn.WordID != nil {
+			if state.Word, err = s.listRepo.GetWordByID(*turn.WordID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return state, nil
+}
+
+// unusedWords returns the bee's list words that haven't been dictated yet
+func (s *SpellingBeeService) unusedWords(bee *models.SpellingBee, turns []models.SpellingBeeTurn) ([]models.Word, error) {
+	words, err := s.listRepo.GetListWords(bee.SpellingListID)
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[int64]bool, len(turns))
+	for _, turn := range turns {
+		if turn.WordID != nil {
+			used[*turn.WordID] = true
+		}
+	}
+	var unused []models.Word
+	for _, word := range words {
+		if !used[word.ID] {
+			unused = append(unused, word)
+		}
+	}
+	return unused, nil
+}
+
+// nextBeeSpeller returns the first child still in after the given turn order,
+// going back round to the start
+func nextBeeSpeller(participants []models.SpellingBeeParticipant, afterTurnOrder int) *models.SpellingBeeParticipant {
+	var first *models.SpellingBeeParticipant
+	for i := range participants {
+		p := &participants[i]
+		if p.IsOut() {
+			continue
+		}
+		if p.TurnOrder > afterTurnOrder {
+			return p
+		}
+		if first == nil {
+			first = p
+		}
+	}
+	return first
+}
+
+// findBeeParticipant returns the kid's entry in a bee, or nil
+func findBeeParticipant(participants []models.SpellingBeeParticipant, kidID int64) *models.SpellingBeeParticipant {
+	for i := range participants {
+		if participants[i].KidID == kidID {
+			return &participants[i]
+		}
+	}
+	return nil
+}
+
+// beePlace orders finished standings, with unplaced children last
+func beePlace(p *models.SpellingBeeParticipant) int {
+	if p.FinalPlace == nil {
+		return math.MaxInt
+	}
+	return *p.FinalPlace
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"spellingclash/internal/audio"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"testing"
+	"time"
+)
+
+// newBeeTestService seeds Ms Reed with Ada, Ben and Cy in her class, Dee who
+// isn't, and a three-word list with audio
+func newBeeTestService(t *testing.T) *SpellingBeeService {
+	t.Helper()
+	db := newTestDB(t)
+
+	seed := []string{
+		"INSERT INTO users (id, email, password_hash, name, is_teacher) VALUES (1, 'teacher@example.com', 'x', 'Ms Reed', 1)",
+		"INSERT INTO families (family_code) VALUES ('FAM1'), ('FAM2')",
+		"INSERT INTO kids (id, family_code, name, username, password) VALUES (1, 'FAM1', 'Ada', 'ada1', 'x'), (2, 'FAM1', 'Ben', 'ben1', 'x'), (3, 'FAM2', 'Cy', 'cy1', 'x'), (4, 'FAM2', 'Dee', 'dee1', 'x')",
+		"INSERT INTO teacher_kid_relationships (teacher_user_id, kid_id) VALUES (1, 1), (1, 2), (1, 3)",
+		"INSERT INTO spelling_lists (id, name, description, family_code, is_public, locale, created_by) VALUES (1, 'Week 1', '', 'FAM1', 0, 'en-GB', 1), (2, 'Empty', '', 'FAM1', 0, 'en-GB', 1)",
+		"INSERT INTO words (id, spelling_list_id, word_text, position, audio_filename) VALUES (1, 1, 'cat', 0, 'word_cat_en-gb.mp3'), (2, 1, 'dog', 1, 'word_dog_en-gb.mp3'), (3, 1, 'fish', 2, 'word_fish_en-gb.mp3')",
+	}
+	for _, query := range seed {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("failed to seed %q: %v", query, err)
+		}
+	}
+
+	return NewSpellingBeeService(
+		repository.NewSpellingBeeRepository(db),
+		repository.NewListRepository(db),
+		repository.NewTeacherKidRepository(db),
+		repository.NewFamilyRepository(db),
+		audio.NewTTSService(t.TempDir(), audio.NewNoneProvider()),
+	)
+}
+
+// nextBeeTurn dictates the next word and returns the bee as it then stands
+func nextBeeTurn(t *testing.T, bees *SpellingBeeService, beeID int64, now time.Time) *models.SpellingBeeState {
+	t.Helper()
+	if err := bees.NextTurn(1, beeID, now); err != nil {
+		t.Fatalf("NextTurn() error: %v", err)
+	}
+	state, err := bees.GetTeacherState(1, beeID)
+	if err != nil {
+		t.Fatalf("GetTeacherState() error: %v", err)
+	}
+	return state
+}
+
+func TestCreateBeeValidation(t *testing.T) {
+	bees := newBeeTestService(t)
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		listID    int64
+		kidIDs    []int64
+		maxMisses int
+		mode      string
+		want      error
+	}{
+		{"one child", 1, []int64{1, 1}, 1, models.BeeTeacherMarks, ErrBeeNeedsKids},
+		{"child not in class", 1, []int64{1, 4}, 1, models.BeeTeacherMarks, ErrTeacherKidLink},
+		{"too many misses", 1, []int64{1, 2}, 6, models.BeeTeacherMarks, ErrBeeMissesInvalid},
+		{"unknown mode", 1, []int64{1, 2}, 1, "shouting", ErrBeeModeInvalid},
+		{"empty list", 2, []int64{1, 2}, 1, models.BeeTeacherMarks, ErrBeeListEmpty},
+		{"missing list", 9, []int64{1, 2}, 1, models.BeeTeacherMarks, ErrListNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := bees.CreateBee(1, tt.listID, tt.kidIDs, tt.maxMisses, tt.mode, now); !errors.Is(err, tt.want) {
+				t.Errorf("CreateBee() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpellingBeeTeacherMarks(t *testing.T) {
+	bees := newBeeTestService(t)
+	now := time.Now()
+
+	bee, err := bees.CreateBee(1, 1, []int64{1, 2, 3}, 1, models.BeeTeacherMarks, now)
+	if err != nil {
+		t.Fatalf("CreateBee() error: %v", err)
+	}
+
+	// Everyone gets a turn in order, and no word comes up twice
+	state := nextBeeTurn(t, bees, bee.ID, now)
+	if err := bees.NextTurn(1, bee.ID, now); !errors.Is(err, ErrBeeTurnOpen) {
+		t.Errorf("NextTurn() with a word open error = %v, want ErrBeeTurnOpen", err)
+	}
+	first := state.Speller.KidID
+	if err := bees.MarkTurn(1, bee.ID, true, now); err != nil {
+		t.Fatalf("MarkTurn() error: %v", err)
+	}
+	words := map[string]bool{state.Turn.WordText: true}
+
+	state = nextBeeTurn(t, bees, bee.ID, now)
+	second := state.Speller.KidID
+	if second == first || words[state.Turn.WordText] {
+		t.Fatalf("second turn = %+v for kid %d, want a new word for someone else", state.Turn, second)
+	}
+	if err := bees.MarkTurn(1, bee.ID, false, now); err != nil {
+		t.Fatalf("MarkTurn() error: %v", err)
+	}
+	words[state.Turn.WordText] = true
+
+	state = nextBeeTurn(t, bees, bee.ID, now)
+	third := state.Speller.KidID
+	if third == first || third == second || words[state.Turn.WordText] || state.WordsLeft != 0 {
+		t.Fatalf("third turn = %+v for kid %d with %d words left, want the last word for the last kid", state.Turn, third, state.WordsLeft)
+	}
+	if err := bees.MarkTurn(1, bee.ID, true, now); err != nil {
+		t.Fatalf("MarkTurn() error: %v", err)
+	}
+
+	// The list has run out, so the two children still in share first place
+	state = nextBeeTurn(t, bees, bee.ID, now)
+	if state.Bee.IsRunning() {
+		t.Fatal("bee still running after the list ran out")
+	}
+	places := make(map[int64]int)
+	for _, p := range state.Participants {
+		if p.FinalPlace == nil {
+			t.Fatalf("participant %+v has no final place", p)
+		}
+		places[p.KidID] = *p.FinalPlace
+	}
+	if places[first] != 1 || places[third] != 1 || places[second] != 3 {
+		t.Errorf("places = %v, want %d and %d first and %d third", places, first, third, second)
+	}
+
+	history, err := bees.GetKidHistory(second)
+	if err != nil {
+		t.Fatalf("GetKidHistory() error: %v", err)
+	}
+	if len(history) != 1 || history[0].TotalKids != 3 || history[0].Participant.Misses != 1 || history[0].Bee.ListName != "Week 1" {
+		t.Errorf("GetKidHistory() = %+v, want one bee on Week 1 with a miss out of 3 kids", history)
+	}
+}
+
+func TestSpellingBeeKidsType(t *testing.T) {
+	bees := newBeeTestService(t)
+	now := time.Now()
+
+	bee, err := bees.CreateBee(1, 1, []int64{1, 2}, 2, models.BeeKidsType, now)
+	if err != nil {
+		t.Fatalf("CreateBee() error: %v", err)
+	}
+	running, err := bees.GetKidRunningBees(1)
+	if err != nil || len(running) != 1 || running[0].Bee.ID != bee.ID {
+		t.Fatalf("GetKidRunningBees() = %+v, %v, want the new bee", running, err)
+	}
+	if _, err := bees.GetKidState(4, bee.ID); !errors.Is(err, ErrBeeNotFound) {
+		t.Errorf("GetKidState() for a kid not in the bee error = %v, want ErrBeeNotFound", err)
+	}
+
+	// Only the speller can answer, and only once; the other child then misses one of their two lives
+	state := nextBeeTurn(t, bees, bee.ID, now)
+	speller, other := state.Speller.KidID, int64(1)
+	if speller == 1 {
+		other = 2
+	}
+	if err := bees.Answer(other, bee.ID, "cat", now); !errors.Is(err, ErrBeeNotYourTurn) {
+		t.Errorf("Answer() out of turn error = %v, want ErrBeeNotYourTurn", err)
+	}
+
+	// The speller hears their word without it being named on their page
+	kidState, err := bees.GetKidState(speller, bee.ID)
+	if err != nil {
+		t.Fatalf("GetKidState() error: %v", err)
+	}
+	if kidState.Word != nil || kidState.Turn.WordText != "" || !kidState.HasAudio || kidState.HasDefinitionAudio {
+		t.Errorf("GetKidState() = %+v, want the word hidden with audio to play", kidState)
+	}
+	if path, err := bees.TurnAudio(speller, bee.ID, false); err != nil || filepath.Base(path) != "word_"+state.Turn.WordText+"_en-gb.mp3" {
+		t.Errorf("TurnAudio() = %q, %v, want the audio for %q", path, err, state.Turn.WordText)
+	}
+	if _, err := bees.TurnAudio(speller, bee.ID, true); !errors.Is(err, ErrBeeNotFound) {
+		t.Errorf("TurnAudio() of a missing definition error = %v, want ErrBeeNotFound", err)
+	}
+	if _, err := bees.TurnAudio(other, bee.ID, false); !errors.Is(err, ErrBeeNotYourTurn) {
+		t.Errorf("TurnAudio() out of turn error = %v, want ErrBeeNotYourTurn", err)
+	}
+	if err := bees.Answer(speller, bee.ID, " "+state.Turn.WordText+" ", now); err != nil {
+		t.Fatalf("Answer() error: %v", err)
+	}
+	if err := bees.Answer(speller, bee.ID, state.Turn.WordText, now); !errors.Is(err, ErrBeeNoTurn) {
+		t.Errorf("Answer() twice error = %v, want ErrBeeNoTurn", err)
+	}
+
+	state = nextBeeTurn(t, bees, bee.ID, now)
+	if state.Speller.KidID != other {
+		t.Fatalf("second speller = %d, want %d", state.Speller.KidID, other)
+	}
+	if err := bees.Answer(other, bee.ID, "xyz", now); err != nil {
+		t.Fatalf("Answer() error: %v", err)
+	}
+	state = nextBeeTurn(t, bees, bee.ID, now)
+	if state.Speller.KidID != speller {
+		t.Fatalf("third speller = %d, want %d", state.Speller.KidID, speller)
+	}
+	if err := bees.MarkTurn(1, bee.ID, true, now); err != nil {
+		t.Fatalf("MarkTurn() error: %v", err)
+	}
+	if err := bees.EndBee(1, bee.ID, now); err != nil {
+		t.Fatalf("EndBee() error: %v", err)
+	}
+
+	state, err = bees.GetKidState(other, bee.ID)
+	if err != nil {
+		t.Fatalf("GetKidState() error: %v", err)
+	}
+	if state.Bee.IsRunning() || state.Participants[0].KidID != speller || *state.Participants[1].FinalPlace != 2 {
+		t.Errorf("standings = %+v, want %d first", state.Participants, speller)
+	}
+	if state.Participants[1].IsOut() || state.Participants[1].MissesLeft(bee.MaxMisses) != 0 {
+		t.Errorf("other = %+v, want still in on their last life", state.Participants[1])
+	}
+	if err := bees.NextTurn(1, bee.ID, now); !errors.Is(err, ErrBeeFinished) {
+		t.Errorf("NextTurn() after ending error = %v, want ErrBeeFinished", err)
+	}
+}
+
+func TestSpellingBeeElimination(t *testing.T) {
+	bees := newBeeTestService(t)
+	now := time.Now()
+
+	bee, err := bees.CreateBee(1, 1, []int64{1, 2}, 1, models.BeeTeacherMarks, now)
+	if err != nil {
+		t.Fatalf("CreateBee() error: %v", err)
+	}
+	state := nextBeeTurn(t, bees, bee.ID, now)
+	loser := state.Speller.KidID
+	if err := bees.MarkTurn(1, bee.ID, false, now); err != nil {
+		t.Fatalf("MarkTurn() error: %v", err)
+	}
+
+	state, err = bees.GetTeacherState(1, bee.ID)
+	if err != nil {
+		t.Fatalf("GetTeacherState() error: %v", err)
+	}
+	if state.Bee.IsRunning() {
+		t.Fatal("bee still running with one child left")
+	}
+	last := state.Participants[1]
+	if last.KidID != loser || !last.IsOut() || *last.EliminatedTurn != 1 || *last.FinalPlace != 2 {
+		t.Errorf("last place = %+v, want kid %d out on turn 1", last, loser)
+	}
+}
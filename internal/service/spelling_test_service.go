@@ -0,0 +1,413 @@
+package service
+
+import (
+	"errors"
+	"spellingclash/internal/audio"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrTestNotFound      = errors.New("spelling test not found")
+	ErrTestWindowInvalid = errors.New("the test must close after it opens, and in the future")
+	ErrTestListEmpty     = errors.New("the list has no words to test")
+	ErrTestNeedsAudio    = errors.New("every word in the list needs audio before it can be used for a test")
+	ErrTestNotOpen       = errors.New("this test is not open")
+	ErrTestNotStarted    = errors.New("this test has not been started")
+	ErrTestWordAnswered  = errors.New("that word has already been answered")
+)
+
+// maxTestAnswerLen caps a stored answer; no list word comes close
+const maxTestAnswerLen = 100
+
+// SpellingTestService handles scheduled spelling tests. Tests are kept apart from
+// practice: one attempt per child, one answer per word, and no feedback until the
+// teacher reviews the results.
+type SpellingTestService struct {
+	testRepo   *repository.SpellingTestRepository
+	classRepo  *repository.TeacherClassRepository
+	kidRepo    *repository.KidRepository
+	listRepo   *repository.ListRepository
+	familyRepo *repository.FamilyRepository
+	ttsService *audio.TTSService
+}
+
+// NewSpellingTestService creates a new spelling test service
+func NewSpellingTestService(testRepo *repository.SpellingTestRepository, classRepo *repository.TeacherClassRepository, kidRepo *repository.KidRepository, listRepo *repository.ListRepository, familyRepo *repository.FamilyRepository, ttsService *audio.TTSService) *SpellingTestService {
+	return &SpellingTestService{
+		testRepo:   testRepo,
+		classRepo:  classRepo,
+		kidRepo:    kidRepo,
+		listRepo:   listRepo,
+		familyRepo: familyRepo,
+		ttsService: ttsService,
+	}
+}
+
+// ScheduleTest schedules a test on a list for one of a teacher's active classes.
+// Words are dictated by audio only, so every word must have audio.
+func (s *SpellingTestService) ScheduleTest(teacherUserID, classID, listID int64, opensAt, closesAt time.Time) (*models.SpellingTest, error) {
+	class, err := getTeacherClass(s.classRepo, teacherUserID, classID)
+	if err != nil {
+		return nil, err
+	}
+	if class.IsArchived() {
+		return nil, ErrClassArchived
+	}
+	if !closesAt.After(opensAt) || !closesAt.After(time.Now()) {
+		return nil, ErrTestWindowInvalid
+	}
+
+	list, err := s.listRepo.GetListByID(listID)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, ErrListNotFound
+	}
+	hasAccess, err := canAccessList(s.familyRepo, teacherUserID, list)
+	if err != nil {
+		return nil, err
+	}
+	if !hasAccess {
+		return nil, ErrListNotFound
+	}
+
+	words, err := s.listRepo.GetListWords(listID)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, ErrTestListEmpty
+	}
+	for _, word := range words {
+		if word.AudioFilename == "" {
+			return nil, ErrTestNeedsAudio
+		}
+	}
+
+	test, err := s.testRepo.CreateTest(classID, listID, teacherUserID, opensAt, closesAt)
+	if err != nil {
+		return nil, err
+	}
+	test.ListName = list.Name
+	return test, nil
+}
+
+// GetClassTests retrieves the tests scheduled for a teacher's class
+func (s *SpellingTestService) GetClassTests(teacherUserID, classID int64) ([]models.SpellingTest, error) {
+	if _, err := getTeacherClass(s.classRepo, teacherUserID, classID); err != nil {
+		return nil, err
+	}
+	return s.testRepo.GetClassTests(classID)
+}
+
+// CancelTest deletes a test from a teacher's class, along with any results
+func (s *SpellingTestService) CancelTest(teacherUserID, classID, testID int64) error {
+	if _, err := s.getClassTest(teacherUserID, classID, testID); err != nil {
+		return err
+	}
+	return s.testRepo.DeleteTest(testID)
+}
+
+// GetTestResults retrieves a test with a result for every child in the class,
+// plus any child who sat the test before leaving the class
+func (s *SpellingTestService) GetTestResults(teacherUserID, classID, testID int64) (*models.SpellingTest, []models.SpellingTestResult, error) {
+	test, err := s.getClassTest(teacherUserID, classID, testID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	members, err := s.classRepo.GetClassMembers(classID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attempts, err := s.testRepo.GetTestAttempts(testID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attemptsByKid := make(map[int64]*models.SpellingTestAttempt, len(attempts))
+	for i := range attempts {
+		attemptsByKid[attempts[i].KidID] = &attempts[i]
+	}
+
+	results := make([]models.SpellingTestResult, 0, len(members))
+	for _, kid := range members {
+		result := models.SpellingTestResult{Kid: kid, Attempt: attemptsByKid[kid.ID]}
+		if result.Attempt != nil {
+			result.Answers, err = s.testRepo.GetAttemptAnswers(result.Attempt.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			delete(attemptsByKid, kid.ID)
+		}
+		results = append(results, result)
+	}
+	for _, attempt := range attempts {
+		if _, left := attemptsByKid[attempt.KidID]; !left {
+			continue
+		}
+		kid, err := s.kidRepo.GetKidByID(attempt.KidID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if kid == nil {
+			continue
+		}
+		answers, err := s.testRepo.GetAttemptAnswers(attempt.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		results = append(results, models.SpellingTestResult{Kid: *kid, Attempt: attemptsByKid[attempt.KidID], Answers: answers})
+	}
+
+	return test, results, nil
+}
+
+// GetOpenTestsForKid retrieves the tests a kid can see right now. Tests are
+// only shown while their window is open.
+func (s *SpellingTestService) GetOpenTestsForKid(kidID int64, now time.Time) ([]models.KidSpellingTest, error) {
+	tests, err := s.testRepo.GetKidTests(kidID)
+	if err != nil {
+		return nil, err
+	}
+
+	var open []models.KidSpellingTest
+	for _, test := range tests {
+		if !test.IsOpen(now) {
+			continue
+		}
+		attempt, err := s.testRepo.GetAttempt(test.ID, kidID)
+		if err != nil {
+			return nil, err
+		}
+		open = append(open, models.KidSpellingTest{Test: test, Attempt: attempt})
+	}
+	return open, nil
+}
+
+// StartTest starts a kid's only attempt at an open test, or returns the attempt
+// they already started
+func (s *SpellingTestService) StartTest(kidID, testID int64, now time.Time) (*models.SpellingTestAttempt, error) {
+	test, err := s.getKidTest(kidID, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt, err := s.testRepo.GetAttempt(testID, kidID)
+	if err != nil {
+		return nil, err
+	}
+	if attempt != nil {
+		return attempt, nil
+	}
+	if !test.IsOpen(now) {
+		return nil, ErrTestNotOpen
+	}
+
+	words, err := s.listRepo.GetListWords(test.SpellingListID)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, ErrTestListEmpty
+	}
+	return s.testRepo.CreateAttempt(testID, kidID, len(words))
+}
+
+// GetTestProgress returns the next word a kid should be dictated. Completed
+// attempts can always be viewed; unfinished ones only while the test is open.
+func (s *SpellingTestService) GetTestProgress(kidID, testID int64, now time.Time) (*models.SpellingTestProgress, error) {
+	test, err := s.getKidTest(kidID, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt, err := s.testRepo.GetAttempt(testID, kidID)
+	if err != nil {
+		return nil, err
+	}
+	if attempt == nil {
+		return nil, ErrTestNotStarted
+	}
+
+	progress := &models.SpellingTestProgress{Test: test, Attempt: attempt, TotalWords: attempt.TotalWords}
+	if attempt.IsComplete() {
+		return progress, nil
+	}
+	if !test.IsOpen(now) {
+		return nil, ErrTestNotOpen
+	}
+
+	words, answers, err := s.attemptWords(test, attempt)
+	if err != nil {
+		return nil, err
+	}
+	next := nextTestWord(words, answers)
+	if next == nil {
+		// The list lost its remaining words since the last answer
+		if err := s.completeAttempt(attempt, len(words), len(answers), now); err != nil {
+			return nil, err
+		}
+		progress.TotalWords = attempt.TotalWords
+		return progress, nil
+	}
+
+	progress.Word = next
+	progress.Number = len(answers) + 1
+	if progress.TotalWords < len(words) {
+		progress.TotalWords = len(words)
+	}
+	return progress, nil
+}
+
+// WordAudio returns the path of the audio file for word number of a kid's
+// attempt. Only the word the kid is on can be played, and it is served from a URL
+// that doesn't name it, since audio files are named after their words.
+func (s *SpellingTestService) WordAudio(kidID, testID int64, number int, now time.Time) (string, error) {
+	progress, err := s.GetTestProgress(kidID, testID, now)
+	if err != nil {
+		return "", err
+	}
+	if progress.Word == nil || progress.Number != number || progress.Word.AudioFilename == "" || s.ttsService == nil {
+		return "", ErrTestNotFound
+	}
+	return s.ttsService.AudioPath(progress.Word.AudioFilename), nil
+}
+
+// SubmitAnswer records a kid's one answer to the word they were dictated. The
+// word must be the next unanswered one, so answers cannot be retried or skipped.
+// It reports whether the attempt is now complete.
+func (s *SpellingTestService) SubmitAnswer(kidID, testID, wordID int64, answer string, now time.Time) (bool, error) {
+	test, err := s.getKidTest(kidID, testID)
+	if err != nil {
+		return false, err
+	}
+	if !test.IsOpen(now) {
+		return false, ErrTestNotOpen
+	}
+
+	attempt, err := s.testRepo.GetAttempt(testID, kidID)
+	if err != nil {
+		return false, err
+	}
+	if attempt == nil {
+		return false, ErrTestNotStarted
+	}
+	if attempt.IsComplete() {
+		return true, ErrTestWordAnswered
+	}
+
+	words, answers, err := s.attemptWords(test, attempt)
+	if err != nil {
+		return false, err
+	}
+	next := nextTestWord(words, answers)
+	if next == nil || next.ID != wordID {
+		return false, ErrTestWordAnswered
+	}
+
+	answer = strings.TrimSpace(answer)
+	if utf8.RuneCountInString(answer) > maxTestAnswerLen {
+		answer = string([]rune(answer)[:maxTestAnswerLen])
+	}
+	isCorrect := strings.EqualFold(answer, strings.TrimSpace(next.WordText))
+	if err := s.testRepo.RecordAnswer(attempt.ID, next.ID, next.WordText, answer, isCorrect); err != nil {
+		return false, err
+	}
+
+	answers = append(answers, models.SpellingTestAnswer{WordID: next.ID})
+	if nextTestWord(words, answers) != nil {
+		return false, nil
+	}
+	if err := s.completeAttempt(attempt, len(words), len(answers), now); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// attemptWords loads the test's words in list order alongside the attempt's answers
+func (s *SpellingTestService) attemptWords(test *models.SpellingTest, attempt *models.SpellingTestAttempt) ([]models.Word, []models.SpellingTestAnswer, error) {
+	words, err := s.listRepo.GetListWords(test.SpellingListID)
+	if err != nil {
+		return nil, nil, err
+	}
+	answers, err := s.testRepo.GetAttemptAnswers(attempt.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return words, answers, nil
+}
+
+// completeAttempt finishes an attempt, marking it out of at least as many words
+// as were in the list when it started
+func (s *SpellingTestService) completeAttempt(attempt *models.SpellingTestAttempt, listWords, answered int, now time.Time) error {
+	total := max(attempt.TotalWords, listWords, answered)
+	if err := s.testRepo.CompleteAttempt(attempt.ID, total, now); err != nil {
+		return err
+	}
+	attempt.TotalWords = total
+	attempt.CompletedAt = &now
+	return nil
+}
+
+// getClassTest retrieves a test belonging to a teacher's class
+func (s *SpellingTestService) getClassTest(teacherUserID, classID, testID int64) (*models.SpellingTest, error) {
+	if _, err := getTeacherClass(s.classRepo, teacherUserID, classID); err != nil {
+		return nil, err
+	}
+	test, err := s.testRepo.GetTestByID(testID)
+	if err != nil {
+		return nil, err
+	}
+	if test == nil || test.ClassID != classID {
+		return nil, ErrTestNotFound
+	}
+	return test, nil
+}
+
+// getKidTest retrieves a test set for an active class the kid belongs to
+func (s *SpellingTestService) getKidTest(kidID, testID int64) (*models.SpellingTest, error) {
+	test, err := s.testRepo.GetTestByID(testID)
+	if err != nil {
+		return nil, err
+	}
+	if test == nil {
+		return nil, ErrTestNotFound
+	}
+
+	class, err := s.classRepo.GetClassByID(test.ClassID)
+	if err != nil {
+		return nil, err
+	}
+	if class == nil || class.IsArchived() {
+		return nil, ErrTestNotFound
+	}
+	isMember, err := s.classRepo.IsClassMember(test.ClassID, kidID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrTestNotFound
+	}
+	return test, nil
+}
+
+// nextTestWord returns the first word in list order without an answer, or nil
+// once every word has been answered
+func nextTestWord(words []models.Word, answers []models.SpellingTestAnswer) *models.Word {
+	answered := make(map[int64]bool, len(answers))
+	for _, answer := range answers {
+		answered[answer.WordID] = true
+	}
+	for i := range words {
+		if !answered[words[i].ID] {
+			return &words[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"spellingclash/internal/audio"
+	"spellingclash/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestSpellingTests(t *testing.T) {
+	db := newTestDB(t)
+	for _, query := range []string{
+		"INSERT INTO users (id, email, password_hash, name, is_teacher) VALUES (1, 'teacher@example.com', 'x', 'Teacher', 1), (2, 'other@example.com', 'x', 'Other', 1)",
+		"INSERT INTO families (family_code) VALUES ('FAM1')",
+		"INSERT INTO kids (id, family_code, name, username, password) VALUES (1, 'FAM1', 'Ada', 'ada1', 'pw'), (2, 'FAM1', 'Bob', 'bob2', 'pw'), (3, 'FAM1', 'Cy', 'cy3', 'pw')",
+		"INSERT INTO spelling_lists (id, name, description, is_public, locale) VALUES (1, 'Week 1', '', 1, 'en-GB'), (2, 'No audio', '', 1, 'en-GB'), (3, 'Empty', '', 1, 'en-GB')",
+		"INSERT INTO words (id, spelling_list_id, word_text, audio_filename, definition, position) VALUES (1, 1, 'because', 'because.mp3', 'for the reason that', 0), (2, 1, 'friend', 'friend.mp3', '', 1), (3, 2, 'said', '', '', 0)",
+		"INSERT INTO teacher_classes (id, teacher_user_id, name) VALUES (1, 1, 'Year 4 Blue')",
+		"INSERT INTO teacher_class_members (class_id, kid_id) VALUES (1, 1), (1, 2)",
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("failed to seed %q: %v", query, err)
+		}
+	}
+
+	tests := NewSpellingTestService(
+		repository.NewSpellingTestRepository(db),
+		repository.NewTeacherClassRepository(db),
+		repository.NewKidRepository(db),
+		repository.NewListRepository(db),
+		repository.NewFamilyRepository(db),
+		audio.NewTTSService(t.TempDir(), audio.NewNoneProvider()),
+	)
+	now := time.Now()
+	opens, closes := now.Add(-time.Hour), now.Add(time.Hour)
+
+	for _, tc := range []struct {
+		name      string
+		teacherID int64
+		listID    int64
+		opens     time.Time
+		closes    time.Time
+		want      error
+	}{
+		{"another teacher's class", 2, 1, opens, closes, ErrClassNotFound},
+		{"closes before it opens", 1, 1, closes, opens, ErrTestWindowInvalid},
+		{"already closed", 1, 1, now.Add(-2 * time.Hour), opens, ErrTestWindowInvalid},
+		{"words without audio", 1, 2, opens, closes, ErrTestNeedsAudio},
+		{"empty list", 1, 3, opens, closes, ErrTestListEmpty},
+	} {
+		if _, err := tests.ScheduleTest(tc.teacherID, 1, tc.listID, tc.opens, tc.closes); !errors.Is(err, tc.want) {
+			t.Errorf("ScheduleTest() %s: error = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	test, err := tests.ScheduleTest(1, 1, 1, opens, closes)
+	if err != nil {
+		t.Fatalf("ScheduleTest() error: %v", err)
+	}
+	if _, err := tests.ScheduleTest(1, 1, 1, closes, closes.Add(time.Hour)); err != nil {
+		t.Fatalf("ScheduleTest() for next week error: %v", err)
+	}
+
+	// Children only see tests for their classes while the window is open
+	open, err := tests.GetOpenTestsForKid(1, now)
+	if err != nil || len(open) != 1 || open[0].Test.ID != test.ID || open[0].Attempt != nil {
+		t.Errorf("GetOpenTestsForKid() = %+v, %v, want only the open test", open, err)
+	}
+	if open, _ := tests.GetOpenTestsForKid(3, now); len(open) != 0 {
+		t.Errorf("GetOpenTestsForKid() for a child outside the class = %+v, want none", open)
+	}
+	if _, err := tests.StartTest(3, test.ID, now); !errors.Is(err, ErrTestNotFound) {
+		t.Errorf("StartTest() outside the class error = %v, want ErrTestNotFound", err)
+	}
+
+	if _, err := tests.StartTest(1, test.ID, now); err != nil {
+		t.Fatalf("StartTest() error: %v", err)
+	}
+	progress, err := tests.GetTestProgress(1, test.ID, now)
+	if err != nil {
+		t.Fatalf("GetTestProgress() error: %v", err)
+	}
+	if progress.Word == nil || progress.Word.ID != 1 || progress.Number != 1 || progress.TotalWords != 2 {
+		t.Fatalf("GetTestProgress() = %+v, want word 1 of 2", progress)
+	}
+
+	// Only the current word's audio can be played
+	if path, err := tests.WordAudio(1, test.ID, 1, now); err != nil || filepath.Base(path) != "because.mp3" {
+		t.Errorf("WordAudio() = %q, %v, want because.mp3", path, err)
+	}
+	if _, err := tests.WordAudio(1, test.ID, 2, now); !errors.Is(err, ErrTestNotFound) {
+		t.Errorf("WordAudio() of a later word error = %v, want ErrTestNotFound", err)
+	}
+	if _, err := tests.WordAudio(2, test.ID, 1, now); !errors.Is(err, ErrTestNotStarted) {
+		t.Errorf("WordAudio() before starting error = %v, want ErrTestNotStarted", err)
+	}
+
+	// Words are answered once each, in order
+	if _, err := tests.SubmitAnswer(1, test.ID, 2, "friend", now); !errors.Is(err, ErrTestWordAnswered) {
+		t.Errorf("SubmitAnswer() skipping ahead error = %v, want ErrTestWordAnswered", err)
+	}
+	if done, err := tests.SubmitAnswer(1, test.ID, 1, " Because ", now); err != nil || done {
+		t.Fatalf("SubmitAnswer() = %v, %v, want not done", done, err)
+	}
+	if _, err := tests.SubmitAnswer(1, test.ID, 1, "because", now); !errors.Is(err, ErrTestWordAnswered) {
+		t.Errorf("SubmitAnswer() retry error = %v, want ErrTestWordAnswered", err)
+	}
+	if done, err := tests.SubmitAnswer(1, test.ID, 2, "freind", now); err != nil || !done {
+		t.Fatalf("SubmitAnswer() = %v, %v, want done", done, err)
+	}
+
+	// Bob starts but runs out of time
+	if _, err := tests.StartTest(2, test.ID, now); err != nil {
+		t.Fatalf("StartTest() error: %v", err)
+	}
+	if _, err := tests.SubmitAnswer(2, test.ID, 1, "becuase", closes); !errors.Is(err, ErrTestNotOpen) {
+		t.Errorf("SubmitAnswer() after closing error = %v, want ErrTestNotOpen", err)
+	}
+
+	_, results, err := tests.GetTestResults(1, 1, test.ID)
+	if err != nil {
+		t.Fatalf("GetTestResults() error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("GetTestResults() returned %d results, want 2", len(results))
+	}
+	ada := results[0]
+	if ada.Kid.Name != "Ada" || ada.Attempt == nil || !ada.Attempt.IsComplete() || ada.Attempt.CorrectWords != 1 || ada.Attempt.TotalWords != 2 {
+		t.Errorf("Ada's result = %+v, want 1 of 2 complete", ada)
+	}
+	if len(ada.Answers) != 2 || ada.Answers[1].Answer != "freind" || ada.Answers[1].IsCorrect {
+		t.Errorf("Ada's answers = %+v, want the misspelling recorded", ada.Answers)
+	}
+	bob := results[1]
+	if bob.Attempt == nil || bob.Attempt.IsComplete() || bob.Attempt.Score() != 0 {
+		t.Errorf("Bob's result = %+v, want an unfinished attempt scoring 0", bob)
+	}
+
+	// Test results are kept apart from practice
+	var sessions int
+	if err := db.QueryRow("SELECT COUNT(*) FROM practice_sessions").Scan(&sessions); err != nil || sessions != 0 {
+		t.Errorf("practice sessions = %d, %v, want none", sessions, err)
+	}
+}
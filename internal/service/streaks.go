@@ -0,0 +1,215 @@
+package service
+
+import (
+	"errors"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"strings"
+	"time"
+)
+
+// maxStreakFreezeDays stops a typo in a year turning into a years-long holiday
+const maxStreakFreezeDays = 366
+
+var (
+	ErrInvalidTimezone      = errors.New("unknown time zone")
+	ErrInvalidStreakFreeze  = errors.New("days off need a name and a valid start and end date")
+	ErrStreakFreezeNotFound = errors.New("days off not found")
+)
+
+// StreakCalendar decides which days count towards a family's daily streaks
+type StreakCalendar struct {
+	Location    *time.Location
+	WeekendsOff bool
+	Freezes     []models.StreakFreeze
+}
+
+// IsDayOff reports whether a day can be missed without breaking a streak
+func (c *StreakCalendar) IsDayOff(day time.Time) bool {
+	day = day.In(c.Location)
+	if c.WeekendsOff && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+		return true
+	}
+	date := day.Format(time.DateOnly)
+	for _, freeze := range c.Freezes {
+		if date >= freeze.StartDate && date <= freeze.EndDate {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveDays returns the days, in the calendar's time zone, that any of times fall on
+func (c *StreakCalendar) ActiveDays(times []time.Time) map[string]bool {
+	days := make(map[string]bool, len(times))
+	for _, t := range times {
+		days[t.In(c.Location).Format(time.DateOnly)] = true
+	}
+	return days
+}
+
+// DailyStreaks returns the current run of active days up to now's date and the
+// longest run ever. Days off without activity neither break nor extend a run, and
+// nothing done yet today doesn't break the current one.
+func (c *StreakCalendar) DailyStreaks(days map[string]bool, now time.Time) (current, longest int) {
+	if len(days) == 0 {
+		return 0, 0
+	}
+	now = now.In(c.Location)
+
+	first := now.Format(time.DateOnly)
+	for day := range days {
+		first = min(first, day)
+	}
+	start, err := time.ParseInLocation(time.DateOnly, first, c.Location)
+	if err != nil {
+		return 0, 0
+	}
+
+	// Step through days at noon, which exists in every time zone even when the
+	// clocks change at midnight
+	day := time.Date(start.Year(), start.Month(), start.Day(), 12, 0, 0, 0, c.Location)
+	today := now.Format(time.DateOnly)
+	run := 0
+	for ; day.Format(time.DateOnly) <= today; day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		switch {
+		case days[date]:
+			run++
+			longest = max(longest, run)
+		case date == today || c.IsDayOff(day):
+		default:
+			run = 0
+		}
+	}
+	return run, longest
+}
+
+// StreakService counts kids' daily and correct-answer streaks across every game mode
+type StreakService struct {
+	streakRepo      *repository.StreakRepository
+	defaultLocation *time.Location
+}
+
+// NewStreakService creates a new streak service. Days are counted in
+// defaultLocation for families that haven't chosen a time zone.
+func NewStreakService(streakRepo *repository.StreakRepository, defaultLocation *time.Location) *StreakService {
+	return &StreakService{
+		streakRepo:      streakRepo,
+		defaultLocation: defaultLocation,
+	}
+}
+
+// GetSettings returns a family's streak settings, with the defaults if they have
+// never set any
+func (s *StreakService) GetSettings(familyCode string) (*models.StreakSettings, error) {
+	settings, err := s.streakRepo.GetSettings(familyCode)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &models.StreakSettings{FamilyCode: familyCode}
+	}
+	return settings, nil
+}
+
+// UpdateSettings saves a family's time zone (an IANA name, or "" for the server
+// default) and whether weekends are days off
+func (s *StreakService) UpdateSettings(familyCode, timezone string, weekendsOff bool) error {
+	timezone = strings.TrimSpace(timezone)
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil || timezone == "Local" {
+			return ErrInvalidTimezone
+		}
+	}
+	return s.streakRepo.SaveSettings(familyCode, timezone, weekendsOff, time.Now())
+}
+
+// GetFreezes lists a family's days off
+func (s *StreakService) GetFreezes(familyCode string) ([]models.StreakFreeze, error) {
+	return s.streakRepo.GetFreezes(familyCode)
+}
+
+// AddFreeze adds days off from startDate to endDate (YYYY-MM-DD), both included
+func (s *StreakService) AddFreeze(familyCode, name, startDate, endDate string) (*models.StreakFreeze, error) {
+	name = strings.TrimSpace(name)
+	start, startErr := time.Parse(time.DateOnly, startDate)
+	end, endErr := time.Parse(time.DateOnly, endDate)
+	if name == "" || len(name) > 100 || startErr != nil || endErr != nil || end.Before(start) || end.Sub(start) > maxStreakFreezeDays*24*time.Hour {
+		return nil, ErrInvalidStreakFreeze
+	}
+	return s.streakRepo.CreateFreeze(familyCode, name, start.Format(time.DateOnly), end.Format(time.DateOnly))
+}
+
+// DeleteFreeze removes a family's days off
+func (s *StreakService) DeleteFreeze(familyCode string, id int64) error {
+	deleted, err := s.streakRepo.DeleteFreeze(familyCode, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrStreakFreezeNotFound
+	}
+	return nil
+}
+
+// GetCalendar returns the calendar a family's streaks are counted on
+func (s *StreakService) GetCalendar(familyCode string) (*StreakCalendar, error) {
+	settings, err := s.GetSettings(familyCode)
+	if err != nil {
+		return nil, err
+	}
+	freezes, err := s.streakRepo.GetFreezes(familyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &StreakCalendar{Location: s.defaultLocation, WeekendsOff: settings.WeekendsOff, Freezes: freezes}
+	if settings.Timezone != "" {
+		// A zone that has since disappeared from the zone database falls back to the default
+		if loc, err := time.LoadLocation(settings.Timezone); err == nil {
+			calendar.Location = loc
+		}
+	}
+	return calendar, nil
+}
+
+// GetKidActiveDays returns the kid's calendar and the days they completed a session
+// in any game mode in [since, until), or from since onwards if until is zero
+func (s *StreakService) GetKidActiveDays(kid *models.Kid, since, until time.Time) (*StreakCalendar, map[string]bool, error) {
+	calendar, err := s.GetCalendar(kid.FamilyCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	completions, err := s.streakRepo.GetKidCompletionTimes(kid.ID, since)
+	if err != nil {
+		return nil, nil, err
+	}
+	var before []time.Time
+	for _, completedAt := range completions {
+		if until.IsZero() || completedAt.Before(until) {
+			before = append(before, completedAt)
+		}
+	}
+	return calendar, calendar.ActiveDays(before), nil
+}
+
+// GetKidStreaks counts a kid's streaks as of now
+func (s *StreakService) GetKidStreaks(kid *models.Kid, now time.Time) (*models.KidStreaks, error) {
+	calendar, days, err := s.GetKidActiveDays(kid, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	correct, err := s.streakRepo.GetKidCorrectStreak(kid.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	streaks := &models.KidStreaks{
+		CurrentCorrect: correct,
+		ActiveToday:    days[now.In(calendar.Location).Format(time.DateOnly)],
+		DayOff:         calendar.IsDayOff(now),
+	}
+	streaks.CurrentDaily, streaks.LongestDaily = calendar.DailyStreaks(days, now)
+	return streaks, nil
+}
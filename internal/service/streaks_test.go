@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"spellingclash/internal/models"
+	"spellingclash/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestDailyStreaks(t *testing.T) {
+	calendar := &StreakCalendar{Location: time.UTC}
+	end := time.Date(2026, 10, 11, 18, 0, 0, 0, time.UTC)
+	days := map[string]bool{"2026-10-11": true, "2026-10-10": true, "2026-10-09": true, "2026-10-07": true}
+	if current, longest := calendar.DailyStreaks(days, end); current != 3 || longest != 3 {
+		t.Errorf("DailyStreaks() = %d, %d, want 3, 3", current, longest)
+	}
+
+	// Nothing yet today doesn't break the streak
+	delete(days, "2026-10-11")
+	if current, _ := calendar.DailyStreaks(days, end); current != 2 {
+		t.Errorf("DailyStreaks() with nothing today = %d, want 2", current)
+	}
+	if current, longest := calendar.DailyStreaks(days, end.AddDate(0, 0, 2)); current != 0 || longest != 2 {
+		t.Errorf("DailyStreaks() after a missed day = %d, %d, want 0, 2", current, longest)
+	}
+
+	// Days off bridge the gaps, the 8th with a freeze and Sunday the 11th as a weekend
+	calendar.WeekendsOff = true
+	calendar.Freezes = []models.StreakFreeze{{Name: "Inset day", StartDate: "2026-10-08", EndDate: "2026-10-08"}}
+	if current, longest := calendar.DailyStreaks(days, end.AddDate(0, 0, 1)); current != 3 || longest != 3 {
+		t.Errorf("DailyStreaks() over days off = %d, %d, want 3, 3", current, longest)
+	}
+	if current, _ := calendar.DailyStreaks(days, end.AddDate(0, 0, 2)); current != 0 {
+		t.Errorf("DailyStreaks() after missing a school day = %d, want 0", current)
+	}
+
+	if current, longest := calendar.DailyStreaks(nil, end); current != 0 || longest != 0 {
+		t.Errorf("DailyStreaks() with no activity = %d, %d, want 0, 0", current, longest)
+	}
+}
+
+func TestKidStreaks(t *testing.T) {
+	db := newTestDB(t)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	seed := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"INSERT INTO users (id, email, password_hash, name) VALUES (1, 'parent@example.com', 'x', 'Parent')", nil},
+		{"INSERT INTO families (family_code) VALUES ('FAM1')", nil},
+		{"INSERT INTO kids (id, family_code, name, username, password) VALUES (1, 'FAM1', 'Ada', 'ada1', 'x')", nil},
+		{"INSERT INTO spelling_lists (id, name, description, family_code, is_public, locale, created_by) VALUES (1, 'Week 1', '', 'FAM1', 0, 'en-GB', 1)", nil},
+		{"INSERT INTO words (id, spelling_list_id, word_text, position) VALUES (1, 1, 'cat', 0)", nil},
+		// Practice on the 1st and the 9th, hangman late on Saturday the 10th in UTC
+		// (early Sunday in London) and missing letter on the 13th
+		{"INSERT INTO practice_sessions (id, kid_id, spelling_list_id, started_at, completed_at) VALUES (1, 1, 1, ?, ?), (2, 1, 1, ?, ?)",
+			[]interface{}{at(1, 10, 0), at(1, 10, 5), at(9, 10, 0), at(9, 10, 5)}},
+		{"INSERT INTO word_attempts (practice_session_id, word_id, attempt_text, is_correct, time_taken_ms, points_earned, attempted_at) VALUES (1, 1, 'cat', 1, 1000, 10, ?), (2, 1, 'kat', 0, 1000, 0, ?), (2, 1, 'cat', 1, 1000, 10, ?)",
+			[]interface{}{at(1, 10, 1), at(9, 10, 1), at(9, 10, 2)}},
+		{"INSERT INTO hangman_sessions (id, kid_id, spelling_list_id, started_at, completed_at, total_games) VALUES (1, 1, 1, ?, ?, 3)",
+			[]interface{}{at(10, 22, 50), at(10, 23, 30)}},
+		{"INSERT INTO hangman_games (session_id, kid_id, word_id, word, is_won, started_at, completed_at) VALUES (1, 1, 1, 'cat', 0, ?, ?), (1, 1, 1, 'cat', 1, ?, ?), (1, 1, 1, 'cat', 0, ?, NULL)",
+			[]interface{}{at(10, 22, 50), at(10, 23, 0), at(10, 23, 0), at(10, 23, 10), at(10, 23, 20)}},
+		{"INSERT INTO missing_letter_sessions (id, kid_id, spelling_list_id, started_at, completed_at, total_games) VALUES (1, 1, 1, ?, ?, 2)",
+			[]interface{}{at(13, 16, 0), at(13, 16, 5)}},
+		{"INSERT INTO missing_letter_games (session_id, kid_id, word_id, word, is_won, started_at, completed_at) VALUES (1, 1, 1, 'cat', 1, ?, ?), (1, 1, 1, 'cat', 1, ?, ?)",
+			[]interface{}{at(13, 16, 0), at(13, 16, 1), at(13, 16, 1), at(13, 16, 2)}},
+	}
+	for _, s := range seed {
+		if _, err := db.Exec(s.query, s.args...); err != nil {
+			t.Fatalf("failed to seed %q: %v", s.query, err)
+		}
+	}
+
+	streaks := NewStreakService(repository.NewStreakRepository(db), time.UTC)
+	kid := &models.Kid{ID: 1, FamilyCode: "FAM1", Name: "Ada"}
+	now := at(14, 12, 0)
+
+	// In UTC, the 9th and 10th make the best run, and the 11th and 12th were missed
+	got, err := streaks.GetKidStreaks(kid, now)
+	if err != nil {
+		t.Fatalf("GetKidStreaks() error: %v", err)
+	}
+	want := models.KidStreaks{CurrentDaily: 1, LongestDaily: 2, CurrentCorrect: 3}
+	if *got != want {
+		t.Errorf("GetKidStreaks() = %+v, want %+v", *got, want)
+	}
+
+	// In London the hangman session was on the 11th, and an inset day covers the 12th
+	if err := streaks.UpdateSettings("FAM1", "Europe/London", false); err != nil {
+		t.Fatalf("UpdateSettings() error: %v", err)
+	}
+	if _, err := streaks.AddFreeze("FAM1", "Inset day", "2026-10-12", "2026-10-12"); err != nil {
+		t.Fatalf("AddFreeze() error: %v", err)
+	}
+	if got, err = streaks.GetKidStreaks(kid, now); err != nil || got.CurrentDaily != 2 || got.LongestDaily != 2 {
+		t.Errorf("GetKidStreaks() in London = %+v, %v, want a current and best streak of 2", got, err)
+	}
+
+	// With weekends off, missing Saturday doesn't matter either
+	if err := streaks.UpdateSettings("FAM1", "Europe/London", true); err != nil {
+		t.Fatalf("UpdateSettings() error: %v", err)
+	}
+	if got, err = streaks.GetKidStreaks(kid, now); err != nil || got.CurrentDaily != 3 || got.LongestDaily != 3 {
+		t.Errorf("GetKidStreaks() with weekends off = %+v, %v, want a current and best streak of 3", got, err)
+	}
+	if got, err = streaks.GetKidStreaks(kid, at(13, 18, 0)); err != nil || !got.ActiveToday || got.DayOff {
+		t.Errorf("GetKidStreaks() on the 13th = %+v, %v, want active on a school day", got, err)
+	}
+	if got, err = streaks.GetKidStreaks(kid, at(12, 9, 0)); err != nil || got.ActiveToday || !got.DayOff {
+		t.Errorf("GetKidStreaks() on the inset day = %+v, %v, want a day off", got, err)
+	}
+}
+
+func TestStreakSettings(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := db.Exec("INSERT INTO families (family_code) VALUES ('FAM1'), ('FAM2')"); err != nil {
+		t.Fatalf("failed to seed families: %v", err)
+	}
+	streaks := NewStreakService(repository.NewStreakRepository(db), time.UTC)
+
+	if settings, err := streaks.GetSettings("FAM1"); err != nil || settings.Timezone != "" || settings.WeekendsOff {
+		t.Errorf("GetSettings() before saving = %+v, %v, want the defaults", settings, err)
+	}
+	for _, timezone := range []string{"Mars/Olympus_Mons", "Local"} {
+		if err := streaks.UpdateSettings("FAM1", timezone, false); !errors.Is(err, ErrInvalidTimezone) {
+			t.Errorf("UpdateSettings(%q) = %v, want ErrInvalidTimezone", timezone, err)
+		}
+	}
+
+	invalid := [][3]string{
+		{"", "2026-10-12", "2026-10-12"},
+		{"Half term", "2026-10-26", "2026-10-19"},
+		{"Half term", "26/10/2026", "2026-10-30"},
+		{"Gap year", "2026-01-01", "2027-06-01"},
+	}
+	for _, freeze := range invalid {
+		if _, err := streaks.AddFreeze("FAM1", freeze[0], freeze[1], freeze[2]); !errors.Is(err, ErrInvalidStreakFreeze) {
+			t.Errorf("AddFreeze(%q) = %v, want ErrInvalidStreakFreeze", freeze, err)
+		}
+	}
+
+	freeze, err := streaks.AddFreeze("FAM1", " Half term ", "2026-10-26", "2026-10-30")
+	if err != nil {
+		t.Fatalf("AddFreeze() error: %v", err)
+	}
+	if freeze.Name != "Half term" {
+		t.Errorf("AddFreeze() name = %q, want it trimmed", freeze.Name)
+	}
+
+	// Another family can't remove it
+	if err := streaks.DeleteFreeze("FAM2", freeze.ID); !errors.Is(err, ErrStreakFreezeNotFound) {
+		t.Errorf("DeleteFreeze() from another family = %v, want ErrStreakFreezeNotFound", err)
+	}
+	if err := streaks.DeleteFreeze("FAM1", freeze.ID); err != nil {
+		t.Errorf("DeleteFreeze() error: %v", err)
+	}
+	if freezes, err := streaks.GetFreezes("FAM1"); err != nil || len(freezes) != 0 {
+		t.Errorf("GetFreezes() after deleting = %+v, %v, want none", freezes, err)
+	}
+}
@@ -8,13 +8,24 @@ import (
 	"spellingclash/internal/credentials"
 	"spellingclash/internal/models"
 	"spellingclash/internal/repository"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 var (
-	ErrTeacherRequired = errors.New("teacher account required")
-	ErrTeacherKidLink  = errors.New("teacher is not linked to this child")
+	ErrTeacherRequired  = errors.New("teacher account required")
+	ErrTeacherKidLink   = errors.New("teacher is not linked to this child")
+	ErrClassNotFound    = errors.New("class not found")
+	ErrClassNameInvalid = errors.New("class name is required and must be at most 100 characters")
+	ErrClassNameTaken   = errors.New("you already have a class with that name")
+	ErrClassArchived    = errors.New("class is archived")
+	ErrKidNotInClass    = errors.New("child is not in this class")
 )
 
+// maxClassNameLen matches the teacher_classes.name column
+const maxClassNameLen = 100
+
 var teacherAvatarColors = []string{
 	"#4A90E2",
 	"#50C878",
@@ -25,19 +36,23 @@ var teacherAvatarColors = []string{
 
 // TeacherService handles teacher-to-child workflows.
 type TeacherService struct {
-	userRepo       *repository.UserRepository
-	familyRepo     *repository.FamilyRepository
-	kidRepo        *repository.KidRepository
+	userRepo        *repository.UserRepository
+	familyRepo      *repository.FamilyRepository
+	kidRepo         *repository.KidRepository
 	teacherKidsRepo *repository.TeacherKidRepository
+	classRepo       *repository.TeacherClassRepository
+	listRepo        *repository.ListRepository
 }
 
 // NewTeacherService creates a new teacher service.
-func NewTeacherService(userRepo *repository.UserRepository, familyRepo *repository.FamilyRepository, kidRepo *repository.KidRepository, teacherKidsRepo *repository.TeacherKidRepository) *TeacherService {
+func NewTeacherService(userRepo *repository.UserRepository, familyRepo *repository.FamilyRepository, kidRepo *repository.KidRepository, teacherKidsRepo *repository.TeacherKidRepository, classRepo *repository.TeacherClassRepository, listRepo *repository.ListRepository) *TeacherService {
 	return &TeacherService{
 		userRepo:        userRepo,
 		familyRepo:      familyRepo,
 		kidRepo:         kidRepo,
 		teacherKidsRepo: teacherKidsRepo,
+		classRepo:       classRepo,
+		listRepo:        listRepo,
 	}
 }
 
@@ -203,6 +218,238 @@ func (s *TeacherService) VerifyTeacherKidAccess(teacherUserID, kidID int64) erro
 	return nil
 }
 
+// CreateClass creates a named class for a teacher.
+func (s *TeacherService) CreateClass(teacherUserID int64, name string) (*models.TeacherClass, error) {
+	if err := s.VerifyTeacher(teacherUserID); err != nil {
+		return nil, err
+	}
+	name, err := s.validateClassName(teacherUserID, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	return s.classRepo.CreateClass(teacherUserID, name)
+}
+
+// GetTeacherClasses returns a teacher's classes, optionally including archived ones.
+func (s *TeacherService) GetTeacherClasses(teacherUserID int64, includeArchived bool) ([]models.TeacherClass, error) {
+	if err := s.VerifyTeacher(teacherUserID); err != nil {
+		return nil, err
+	}
+	classes, err := s.classRepo.GetTeacherClasses(teacherUserID, includeArchived)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get classes: %w", err)
+	}
+	return classes, nil
+}
+
+// GetClass returns one of the teacher's classes.
+func (s *TeacherService) GetClass(teacherUserID, classID int64) (*models.TeacherClass, error) {
+	if err := s.VerifyTeacher(teacherUserID); err != nil {
+		return nil, err
+	}
+	return getTeacherClass(s.classRepo, teacherUserID, classID)
+}
+
+// GetClassMembers returns the children in one of the teacher's classes.
+func (s *TeacherService) GetClassMembers(teacherUserID, classID int64) ([]models.Kid, error) {
+	if _, err := s.GetClass(teacherUserID, classID); err != nil {
+		return nil, err
+	}
+	return s.classRepo.GetClassMembers(classID)
+}
+
+// GetClassLists returns the lists assigned to one of the teacher's classes.
+func (s *TeacherService) GetClassLists(teacherUserID, classID int64) ([]models.TeacherClassList, error) {
+	if _, err := s.GetClass(teacherUserID, classID); err != nil {
+		return nil, err
+	}
+	return s.classRepo.GetClassLists(classID)
+}
+
+// RenameClass renames one of the teacher's active classes.
+func (s *TeacherService) RenameClass(teacherUserID, classID int64, name string) error {
+	if _, err := s.getActiveClass(teacherUserID, classID); err != nil {
+		return err
+	}
+	name, err := s.validateClassName(teacherUserID, name, classID)
+	if err != nil {
+		return err
+	}
+	return s.classRepo.RenameClass(classID, name)
+}
+
+// ArchiveClass archives a class, for example at the end of the school year.
+// Members and list assignments are kept, but the class can no longer be changed.
+func (s *TeacherService) ArchiveClass(teacherUserID, classID int64) error {
+	if _, err := s.getActiveClass(teacherUserID, classID); err != nil {
+		return err
+	}
+	now := time.Now()
+	return s.classRepo.SetArchivedAt(classID, &now)
+}
+
+// RestoreClass makes an archived class active again.
+func (s *TeacherService) RestoreClass(teacherUserID, classID int64) error {
+	class, err := s.GetClass(teacherUserID, classID)
+	if err != nil {
+		return err
+	}
+	if !class.IsArchived() {
+		return nil
+	}
+	if _, err := s.validateClassName(teacherUserID, class.Name, classID); err != nil {
+		return err
+	}
+	return s.classRepo.SetArchivedAt(classID, nil)
+}
+
+// AddKidToClass adds one of the teacher's children to a class and assigns them
+// the lists already assigned to that class.
+func (s *TeacherService) AddKidToClass(teacherUserID, classID, kidID int64) error {
+	if _, err := s.getActiveClass(teacherUserID, classID); err != nil {
+		return err
+	}
+	if err := s.VerifyTeacherKidAccess(teacherUserID, kidID); err != nil {
+		return err
+	}
+
+	member, err := s.classRepo.IsClassMember(classID, kidID)
+	if err != nil {
+		return err
+	}
+	if member {
+		return nil
+	}
+	if err := s.classRepo.AddClassMember(classID, kidID); err != nil {
+		return err
+	}
+
+	lists, err := s.classRepo.GetClassLists(classID)
+	if err != nil {
+		return err
+	}
+	for _, list := range lists {
+		if err := s.listRepo.AssignListToKid(list.ListID, kidID, teacherUserID, true, list.DueDate); err != nil {
+			return fmt.Errorf("failed to assign class list: %w", err)
+		}
+	}
+	return nil
+}
+
+// RemoveKidFromClass removes a child from a class. Lists the child only had
+// through this class are unassigned; the child stays linked to the teacher.
+func (s *TeacherService) RemoveKidFromClass(teacherUserID, classID, kidID int64) error {
+	if _, err := s.getActiveClass(teacherUserID, classID); err != nil {
+		return err
+	}
+
+	removed, err := s.classRepo.RemoveClassMember(classID, kidID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrKidNotInClass
+	}
+
+	lists, err := s.classRepo.GetClassLists(classID)
+	if err != nil {
+		return err
+	}
+	for _, list := range lists {
+		if _, err := removeClassListFromKid(s.classRepo, s.listRepo, classID, list.ListID, kidID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MoveKidToClass moves a child from one of the teacher's classes to another.
+// Lists assigned to both classes are kept.
+func (s *TeacherService) MoveKidToClass(teacherUserID, kidID, fromClassID, toClassID int64) error {
+	if fromClassID == toClassID {
+		return nil
+	}
+	if _, err := s.getActiveClass(teacherUserID, fromClassID); err != nil {
+		return err
+	}
+	member, err := s.classRepo.IsClassMember(fromClassID, kidID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return ErrKidNotInClass
+	}
+
+	// Join the new class first so lists shared by both classes are not unassigned
+	if err := s.AddKidToClass(teacherUserID, toClassID, kidID); err != nil {
+		return err
+	}
+	return s.RemoveKidFromClass(teacherUserID, fromClassID, kidID)
+}
+
+func (s *TeacherService) getActiveClass(teacherUserID, classID int64) (*models.TeacherClass, error) {
+	class, err := s.GetClass(teacherUserID, classID)
+	if err != nil {
+		return nil, err
+	}
+	if class.IsArchived() {
+		return nil, ErrClassArchived
+	}
+	return class, nil
+}
+
+func (s *TeacherService) validateClassName(teacherUserID int64, name string, classID int64) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxClassNameLen {
+		return "", ErrClassNameInvalid
+	}
+	taken, err := s.classRepo.ActiveClassNameExists(teacherUserID, name, classID)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		return "", ErrClassNameTaken
+	}
+	return name, nil
+}
+
+// getTeacherClass returns a class if it belongs to the teacher
+func getTeacherClass(classRepo *repository.TeacherClassRepository, teacherUserID, classID int64) (*models.TeacherClass, error) {
+	class, err := classRepo.GetClassByID(classID)
+	if err != nil {
+		return nil, err
+	}
+	if class == nil || class.TeacherUserID != teacherUserID {
+		return nil, ErrClassNotFound
+	}
+	return class, nil
+}
+
+// removeClassListFromKid unassigns a class's list from a child, unless a parent
+// assigned it or the child also gets it through another active class
+func removeClassListFromKid(classRepo *repository.TeacherClassRepository, listRepo *repository.ListRepository, classID, listID, kidID int64) (bool, error) {
+	assignment, err := listRepo.GetListAssignment(listID, kidID)
+	if err != nil {
+		return false, err
+	}
+	if assignment == nil || !assignment.ManagedByTeacher {
+		return false, nil
+	}
+
+	shared, err := classRepo.IsListAssignedThroughOtherClass(kidID, listID, classID)
+	if err != nil {
+		return false, err
+	}
+	if shared {
+		return false, nil
+	}
+
+	if err := listRepo.UnassignListFromKid(listID, kidID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func randomTeacherAvatarColor() string {
 	if len(teacherAvatarColors) == 0 {
 		return "#4A90E2"
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"spellingclash/internal/repository"
+	"testing"
+)
+
+func TestTeacherClasses(t *testing.T) {
+	db := newTestDB(t)
+	for _, query := range []string{
+		"INSERT INTO users (id, email, password_hash, name, is_teacher) VALUES (1, 'teacher@example.com', 'x', 'Teacher', 1), (2, 'other@example.com', 'x', 'Other', 1)",
+		"INSERT INTO families (family_code) VALUES ('FAM1')",
+		"INSERT INTO kids (id, family_code, name, username, password) VALUES (1, 'FAM1', 'Ada', 'ada1', 'pw'), (2, 'FAM1', 'Bob', 'bob2', 'pw'), (3, 'FAM1', 'Cy', 'cy3', 'pw')",
+		"INSERT INTO teacher_kid_relationships (teacher_user_id, kid_id) VALUES (1, 1), (1, 2)",
+		"INSERT INTO spelling_lists (id, name, description, is_public, locale) VALUES (1, 'Phonics', '', 1, 'en-GB'), (2, 'Year 4', '', 1, 'en-GB')",
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("failed to seed %q: %v", query, err)
+		}
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	familyRepo := repository.NewFamilyRepository(db)
+	teacherKidRepo := repository.NewTeacherKidRepository(db)
+	classRepo := repository.NewTeacherClassRepository(db)
+	listRepo := repository.NewListRepository(db)
+	teachers := NewTeacherService(userRepo, familyRepo, repository.NewKidRepository(db), teacherKidRepo, classRepo, listRepo)
+	lists := NewListService(listRepo, familyRepo, userRepo, teacherKidRepo, classRepo, nil)
+
+	blue, err := teachers.CreateClass(1, " Year 4 Blue ")
+	if err != nil {
+		t.Fatalf("CreateClass() error: %v", err)
+	}
+	if blue.Name != "Year 4 Blue" {
+		t.Errorf("CreateClass() name = %q, want trimmed name", blue.Name)
+	}
+	if _, err := teachers.CreateClass(1, "year 4 blue"); !errors.Is(err, ErrClassNameTaken) {
+		t.Errorf("CreateClass() with duplicate name error = %v, want ErrClassNameTaken", err)
+	}
+	phonics, err := teachers.CreateClass(1, "Phonics group 2")
+	if err != nil {
+		t.Fatalf("CreateClass() error: %v", err)
+	}
+
+	if _, err := teachers.GetClass(2, blue.ID); !errors.Is(err, ErrClassNotFound) {
+		t.Errorf("GetClass() by another teacher error = %v, want ErrClassNotFound", err)
+	}
+	if err := teachers.AddKidToClass(1, blue.ID, 3); !errors.Is(err, ErrTeacherKidLink) {
+		t.Errorf("AddKidToClass() with unlinked child error = %v, want ErrTeacherKidLink", err)
+	}
+
+	if err := teachers.AddKidToClass(1, blue.ID, 1); err != nil {
+		t.Fatalf("AddKidToClass() error: %v", err)
+	}
+	if n, err := lists.AssignListToClass(1, blue.ID, 1, nil); err != nil || n != 1 {
+		t.Fatalf("AssignListToClass() = %d, %v, want 1 child", n, err)
+	}
+	if _, err := lists.AssignListToClass(2, blue.ID, 1, nil); err != nil {
+		t.Fatalf("AssignListToClass() error: %v", err)
+	}
+	if _, err := lists.AssignListToClass(1, phonics.ID, 1, nil); err != nil {
+		t.Fatalf("AssignListToClass() error: %v", err)
+	}
+
+	// Children joining a class get its lists
+	if err := teachers.AddKidToClass(1, blue.ID, 2); err != nil {
+		t.Fatalf("AddKidToClass() error: %v", err)
+	}
+	assertAssigned(t, listRepo, 2, 1, true)
+	assertAssigned(t, listRepo, 2, 2, true)
+
+	// Moving keeps lists shared by both classes and drops the rest
+	if err := teachers.MoveKidToClass(1, 2, blue.ID, phonics.ID); err != nil {
+		t.Fatalf("MoveKidToClass() error: %v", err)
+	}
+	assertAssigned(t, listRepo, 2, 1, true)
+	assertAssigned(t, listRepo, 2, 2, false)
+	if members, _ := teachers.GetClassMembers(1, blue.ID); len(members) != 1 || members[0].ID != 1 {
+		t.Errorf("blue class members = %+v, want only Ada", members)
+	}
+
+	// A parent's own assignment survives the class list being removed
+	if err := listRepo.AssignListToKid(2, 1, 1, false, nil); err != nil {
+		t.Fatalf("failed to add parent assignment: %v", err)
+	}
+	if n, err := lists.UnassignListFromClass(2, blue.ID, 1); err != nil || n != 0 {
+		t.Errorf("UnassignListFromClass() = %d, %v, want 0 children", n, err)
+	}
+	assertAssigned(t, listRepo, 1, 2, true)
+
+	if err := teachers.ArchiveClass(1, blue.ID); err != nil {
+		t.Fatalf("ArchiveClass() error: %v", err)
+	}
+	if err := teachers.AddKidToClass(1, blue.ID, 2); !errors.Is(err, ErrClassArchived) {
+		t.Errorf("AddKidToClass() on archived class error = %v, want ErrClassArchived", err)
+	}
+	if _, err := lists.AssignListToClass(2, blue.ID, 1, nil); !errors.Is(err, ErrClassArchived) {
+		t.Errorf("AssignListToClass() on archived class error = %v, want ErrClassArchived", err)
+	}
+	active, err := teachers.GetTeacherClasses(1, false)
+	if err != nil || len(active) != 1 || active[0].ID != phonics.ID {
+		t.Errorf("GetTeacherClasses() = %+v, %v, want only the phonics group", active, err)
+	}
+
+	// The archived name can be reused, which then blocks restoring the old class
+	if _, err := teachers.CreateClass(1, "Year 4 Blue"); err != nil {
+		t.Fatalf("CreateClass() reusing archived name error: %v", err)
+	}
+	if err := teachers.RestoreClass(1, blue.ID); !errors.Is(err, ErrClassNameTaken) {
+		t.Errorf("RestoreClass() error = %v, want ErrClassNameTaken", err)
+	}
+}
+
+func assertAssigned(t *testing.T, listRepo *repository.ListRepository, kidID, listID int64, want bool) {
+	t.Helper()
+	assigned, err := listRepo.IsListAssignedToKid(listID, kidID)
+	if err != nil {
+		t.Fatalf("IsListAssignedToKid() error: %v", err)
+	}
+	if assigned != want {
+		t.Errorf("list %d assigned to kid %d = %v, want %v", listID, kidID, assigned, want)
+	}
+}
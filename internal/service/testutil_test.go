@@ -0,0 +1,21 @@
+package service
+
+import (
+	"path/filepath"
+	"spellingclash/internal/database"
+	"testing"
+)
+
+// newTestDB creates an empty, fully migrated SQLite database for a test
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.Initialize(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.RunMigrations("../../migrations"); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"spellingclash/internal/models"
+	"spellingclash/internal/security"
+	"time"
+)
+
+const (
+	// loginChallengeDuration is how long a user has to enter their code after their password
+	loginChallengeDuration = 5 * time.Minute
+
+	// maxLoginChallengeAttempts is how many wrong codes end a sign-in
+	maxLoginChallengeAttempts = 5
+
+	recoveryCodeCount = 10
+)
+
+var (
+	ErrInvalidTwoFactorCode    = errors.New("invalid authentication code")
+	ErrLoginChallengeExpired   = errors.New("sign-in timed out, please log in again")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already on")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not on")
+	ErrTwoFactorEnforced       = errors.New("two-factor authentication is required for your account")
+)
+
+// TwoFactorRequiredError is returned by the login methods instead of a session when
+// the user has two-factor authentication on. The sign-in finishes with
+// CompleteTwoFactorLogin once they enter a code.
+type TwoFactorRequiredError struct {
+	Challenge *models.LoginChallenge
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return "two-factor authentication code required"
+}
+
+// startSession issues a session for a user who has passed the first sign-in step,
+// or a login challenge if they also need to enter a code
+func (s *AuthService) startSession(user *models.User) (*models.Session, *models.User, error) {
+	tf, err := s.twoFactorRepo.GetTwoFactor(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tf.IsEnabled() {
+		challenge, err := s.twoFactorRepo.CreateLoginChallenge(security.GenerateSessionID(), user.ID, time.Now().Add(loginChallengeDuration))
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, user, &TwoFactorRequiredError{Challenge: challenge}
+	}
+
+	return s.createSession(user)
+}
+
+func (s *AuthService) createSession(user *models.User) (*models.Session, *models.User, error) {
+	sessionID := security.GenerateSessionID()
+	expiresAt := time.Now().Add(s.sessionDuration)
+	session, err := s.userRepo.CreateSession(sessionID, user.ID, expiresAt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return session, user, nil
+}
+
+// CompleteTwoFactorLogin finishes a sign-in with a code from the user's authenticator
+// app or one of their recovery codes
+func (s *AuthService) CompleteTwoFactorLogin(challengeID, code string) (*models.Session, *models.User, error) {
+	challenge, err := s.twoFactorRepo.GetLoginChallenge(challengeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if challenge == nil {
+		return nil, nil, ErrLoginChallengeExpired
+	}
+	if challenge.IsExpired() || challenge.Attempts >= maxLoginChallengeAttempts {
+		_ = s.twoFactorRepo.DeleteLoginChallenge(challenge.ID)
+		return nil, nil, ErrLoginChallengeExpired
+	}
+
+	user, err := s.userRepo.GetUserByID(challenge.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, nil, ErrLoginChallengeExpired
+	}
+
+	// Two-factor authentication may have been reset by an admin since the password was checked
+	tf, err := s.twoFactorRepo.GetTwoFactor(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tf.IsEnabled() {
+		ok, err := s.checkTwoFactorCode(tf, code, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			if err := s.twoFactorRepo.RecordFailedAttempt(challenge.ID); err != nil {
+				return nil, nil, err
+			}
+			return nil, nil, ErrInvalidTwoFactorCode
+		}
+	}
+
+	if err := s.twoFactorRepo.DeleteLoginChallenge(challenge.ID); err != nil {
+		return nil, nil, err
+	}
+	return s.createSession(user)
+}
+
+// checkTwoFactorCode checks an authenticator code, or a recovery code if allowed,
+// using it up so it can't be used again
+func (s *AuthService) checkTwoFactorCode(tf *models.TwoFactor, code string, allowRecovery bool) (bool, error) {
+	if step, ok := security.VerifyTOTP(tf.Secret, code, s.now(), tf.LastUsedStep); ok {
+		// Another request may have used the same code in the meantime
+		return s.twoFactorRepo.UseStep(tf.UserID, step)
+	}
+	if !allowRecovery || code == "" {
+		return false, nil
+	}
+	return s.twoFactorRepo.UseRecoveryCode(tf.UserID, security.HashRecoveryCode(code), time.Now())
+}
+
+// GetTwoFactor returns a user's authenticator, or nil if they have never started setting one up
+func (s *AuthService) GetTwoFactor(userID int64) (*models.TwoFactor, error) {
+	return s.twoFactorRepo.GetTwoFactor(userID)
+}
+
+// CountRecoveryCodes returns how many unused recovery codes a user has
+func (s *AuthService) CountRecoveryCodes(userID int64) (int, error) {
+	return s.twoFactorRepo.CountUnusedRecoveryCodes(userID)
+}
+
+// TwoFactorPolicy returns who an admin requires to use two-factor authentication
+func (s *AuthService) TwoFactorPolicy() models.TwoFactorPolicy {
+	return s.settingsRepo.GetTwoFactorPolicy()
+}
+
+// SetTwoFactorPolicy changes who is required to use two-factor authentication
+func (s *AuthService) SetTwoFactorPolicy(policy models.TwoFactorPolicy) error {
+	if !policy.IsValid() {
+		return fmt.Errorf("unknown two-factor policy %q", policy)
+	}
+	return s.settingsRepo.SetTwoFactorPolicy(policy)
+}
+
+// TwoFactorUserIDs returns the IDs of every user with two-factor authentication on
+func (s *AuthService) TwoFactorUserIDs() (map[int64]bool, error) {
+	return s.twoFactorRepo.GetEnabledUserIDs()
+}
+
+// ResetTwoFactor turns off a user's two-factor authentication without a code, for
+// an admin helping someone who has lost both their phone and recovery codes
+func (s *AuthService) ResetTwoFactor(userID int64) error {
+	return s.twoFactorRepo.Disable(userID)
+}
+
+// NeedsTwoFactorSetup reports whether the policy requires a user to turn on
+// two-factor authentication before they can do anything else
+func (s *AuthService) NeedsTwoFactorSetup(user *models.User) (bool, error) {
+	if !s.TwoFactorPolicy().Requires(user) {
+		return false, nil
+	}
+	tf, err := s.twoFactorRepo.GetTwoFactor(user.ID)
+	if err != nil {
+		return false, err
+	}
+	return !tf.IsEnabled(), nil
+}
+
+// BeginTwoFactorEnrolment creates a new secret for the user to add to their
+// authenticator app. It isn't used for sign-in until confirmed with a code.
+func (s *AuthService) BeginTwoFactorEnrolment(userID int64) (string, error) {
+	tf, err := s.twoFactorRepo.GetTwoFactor(userID)
+	if err != nil {
+		return "", err
+	}
+	if tf.IsEnabled() {
+		return "", ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	if err := s.twoFactorRepo.StartEnrolment(userID, secret, time.Now()); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// ConfirmTwoFactorEnrolment turns on two-factor authentication once the user enters
+// a code from their app, returning their recovery codes. These are only stored
+// hashed, so this is the one time they can be shown.
+func (s *AuthService) ConfirmTwoFactorEnrolment(userID int64, code string) ([]string, error) {
+	tf, err := s.twoFactorRepo.GetTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if tf.IsEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := security.VerifyTOTP(tf.Secret, code, s.now(), tf.LastUsedStep)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.Enable(userID, step, hashes, time.Now()); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces a user's recovery codes after checking a code
+// from their authenticator app
+func (s *AuthService) RegenerateRecoveryCodes(userID int64, code string) ([]string, error) {
+	tf, err := s.twoFactorRepo.GetTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !tf.IsEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	ok, err := s.checkTwoFactorCode(tf, code, false)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor turns off a user's two-factor authentication after checking a
+// code, unless the policy requires them to have it
+func (s *AuthService) DisableTwoFactor(user *models.User, code string) error {
+	if s.TwoFactorPolicy().Requires(user) {
+		return ErrTwoFactorEnforced
+	}
+
+	tf, err := s.twoFactorRepo.GetTwoFactor(user.ID)
+	if err != nil {
+		return err
+	}
+	if !tf.IsEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+	ok, err := s.checkTwoFactorCode(tf, code, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	return s.twoFactorRepo.Disable(user.ID)
+}
+
+// CleanupExpiredLoginChallenges removes sign-ins that were never finished
+func (s *AuthService) CleanupExpiredLoginChallenges() error {
+	return s.twoFactorRepo.DeleteExpiredLoginChallenges()
+}
+
+// generateRecoveryCodes returns a new set of recovery codes and their hashes
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := security.GenerateRecoveryCode()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		codes[i] = code
+		hashes[i] = security.HashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
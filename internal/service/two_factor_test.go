@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"spellingclash/internal/models"
+	"spellingclash/internal/security"
+	"testing"
+	"time"
+)
+
+// totpCodes pins the service's clock to the start of a time step and returns
+// codes for the previous, current and next steps
+func totpCodes(t *testing.T, auth *AuthService, secret string) (previous, current, next string) {
+	t.Helper()
+	now := time.Now().Truncate(security.TOTPPeriod)
+	auth.now = func() time.Time { return now }
+	step := security.TOTPStep(now)
+	codes := make([]string, 3)
+	for i := range codes {
+		code, err := security.TOTPCode(secret, step+int64(i)-1)
+		if err != nil {
+			t.Fatalf("TOTPCode() error: %v", err)
+		}
+		codes[i] = code
+	}
+	return codes[0], codes[1], codes[2]
+}
+
+// enrolTwoFactor registers a user with two-factor authentication on, returning
+// their unused authenticator codes and recovery codes
+func enrolTwoFactor(t *testing.T, auth *AuthService) (user *models.User, current, next string, recovery []string) {
+	t.Helper()
+	user, err := auth.Register("parent@example.com", "correct horse", "Parent", "")
+	if err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	secret, err := auth.BeginTwoFactorEnrolment(user.ID)
+	if err != nil {
+		t.Fatalf("BeginTwoFactorEnrolment() error: %v", err)
+	}
+
+	previous, current, next := totpCodes(t, auth, secret)
+	if _, err := auth.ConfirmTwoFactorEnrolment(user.ID, "not a code"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("ConfirmTwoFactorEnrolment() with a wrong code = %v, want ErrInvalidTwoFactorCode", err)
+	}
+	recovery, err = auth.ConfirmTwoFactorEnrolment(user.ID, previous)
+	if err != nil {
+		t.Fatalf("ConfirmTwoFactorEnrolment() error: %v", err)
+	}
+	if len(recovery) != recoveryCodeCount {
+		t.Fatalf("ConfirmTwoFactorEnrolment() returned %d recovery codes, want %d", len(recovery), recoveryCodeCount)
+	}
+	return user, current, next, recovery
+}
+
+// loginChallenge logs in with a password and returns the two-factor challenge
+func loginChallenge(t *testing.T, auth *AuthService) string {
+	t.Helper()
+	session, _, err := auth.Login("parent@example.com", "correct horse", LoginClient{IP: "203.0.113.7"})
+	var required *TwoFactorRequiredError
+	if !errors.As(err, &required) || session != nil {
+		t.Fatalf("Login() = %v, %v, want a TwoFactorRequiredError and no session", session, err)
+	}
+	return required.Challenge.ID
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	auth := newAuthTestService(t)
+	user, current, next, recovery := enrolTwoFactor(t, auth)
+
+	challenge := loginChallenge(t, auth)
+	if _, _, err := auth.CompleteTwoFactorLogin(challenge, "not a code"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("CompleteTwoFactorLogin() with a wrong code = %v, want ErrInvalidTwoFactorCode", err)
+	}
+	session, signedIn, err := auth.CompleteTwoFactorLogin(challenge, current)
+	if err != nil || session == nil || signedIn.ID != user.ID {
+		t.Fatalf("CompleteTwoFactorLogin() = %v, %v, %v, want a session for user %d", session, signedIn, err, user.ID)
+	}
+	if _, _, err := auth.CompleteTwoFactorLogin(challenge, next); !errors.Is(err, ErrLoginChallengeExpired) {
+		t.Errorf("CompleteTwoFactorLogin() with a used challenge = %v, want ErrLoginChallengeExpired", err)
+	}
+
+	// A code can't be replayed, even on a new sign-in
+	if _, _, err := auth.CompleteTwoFactorLogin(loginChallenge(t, auth), current); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("CompleteTwoFactorLogin() with a used code = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	// Recovery codes work once each
+	if _, _, err := auth.CompleteTwoFactorLogin(loginChallenge(t, auth), recovery[0]); err != nil {
+		t.Fatalf("CompleteTwoFactorLogin() with a recovery code error: %v", err)
+	}
+	if _, _, err := auth.CompleteTwoFactorLogin(loginChallenge(t, auth), recovery[0]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("CompleteTwoFactorLogin() with a used recovery code = %v, want ErrInvalidTwoFactorCode", err)
+	}
+	if left, err := auth.CountRecoveryCodes(user.ID); err != nil || left != recoveryCodeCount-1 {
+		t.Errorf("CountRecoveryCodes() = %d, %v, want %d", left, err, recoveryCodeCount-1)
+	}
+}
+
+func TestLoginChallengeAttemptLimit(t *testing.T) {
+	auth := newAuthTestService(t)
+	_, current, _, _ := enrolTwoFactor(t, auth)
+
+	challenge := loginChallenge(t, auth)
+	for range maxLoginChallengeAttempts {
+		if _, _, err := auth.CompleteTwoFactorLogin(challenge, "wrong"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Fatalf("CompleteTwoFactorLogin() with a wrong code = %v, want ErrInvalidTwoFactorCode", err)
+		}
+	}
+	if _, _, err := auth.CompleteTwoFactorLogin(challenge, current); !errors.Is(err, ErrLoginChallengeExpired) {
+		t.Errorf("CompleteTwoFactorLogin() after too many wrong codes = %v, want ErrLoginChallengeExpired", err)
+	}
+}
+
+func TestTwoFactorPolicy(t *testing.T) {
+	auth := newAuthTestService(t)
+	// The first user to register becomes an admin
+	admin, err := auth.Register("admin@example.com", "correct horse", "Admin", "")
+	if err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	user, err := auth.Register("parent@example.com", "correct horse", "Parent", "")
+	if err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+
+	if err := auth.SetTwoFactorPolicy("sometimes"); err == nil {
+		t.Error("SetTwoFactorPolicy() accepted an unknown policy")
+	}
+	if err := auth.SetTwoFactorPolicy(models.TwoFactorAdmins); err != nil {
+		t.Fatalf("SetTwoFactorPolicy() error: %v", err)
+	}
+	if needs, err := auth.NeedsTwoFactorSetup(admin); err != nil || !needs {
+		t.Errorf("NeedsTwoFactorSetup() for an admin with admins required = %v, %v, want true", needs, err)
+	}
+	if needs, err := auth.NeedsTwoFactorSetup(user); err != nil || needs {
+		t.Errorf("NeedsTwoFactorSetup() for a parent with admins required = %v, %v, want false", needs, err)
+	}
+
+	if err := auth.SetTwoFactorPolicy(models.TwoFactorEveryone); err != nil {
+		t.Fatalf("SetTwoFactorPolicy() error: %v", err)
+	}
+	if needs, err := auth.NeedsTwoFactorSetup(user); err != nil || !needs {
+		t.Errorf("NeedsTwoFactorSetup() with everyone required = %v, %v, want true", needs, err)
+	}
+
+	secret, err := auth.BeginTwoFactorEnrolment(user.ID)
+	if err != nil {
+		t.Fatalf("BeginTwoFactorEnrolment() error: %v", err)
+	}
+	_, current, next := totpCodes(t, auth, secret)
+	if _, err := auth.ConfirmTwoFactorEnrolment(user.ID, current); err != nil {
+		t.Fatalf("ConfirmTwoFactorEnrolment() error: %v", err)
+	}
+	if needs, err := auth.NeedsTwoFactorSetup(user); err != nil || needs {
+		t.Errorf("NeedsTwoFactorSetup() once enrolled = %v, %v, want false", needs, err)
+	}
+	if err := auth.DisableTwoFactor(user, next); !errors.Is(err, ErrTwoFactorEnforced) {
+		t.Errorf("DisableTwoFactor() while required = %v, want ErrTwoFactorEnforced", err)
+	}
+
+	if err := auth.SetTwoFactorPolicy(models.TwoFactorOptional); err != nil {
+		t.Fatalf("SetTwoFactorPolicy() error: %v", err)
+	}
+	if err := auth.DisableTwoFactor(user, next); err != nil {
+		t.Fatalf("DisableTwoFactor() error: %v", err)
+	}
+	if session, _, err := auth.Login("parent@example.com", "correct horse", LoginClient{IP: "203.0.113.7"}); err != nil || session == nil {
+		t.Errorf("Login() after disabling = %v, %v, want a session", session, err)
+	}
+}
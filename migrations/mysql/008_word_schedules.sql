@@ -0,0 +1,19 @@
+-- Spaced-repetition (SM-2) schedule per kid and word
+
+CREATE TABLE IF NOT EXISTS word_schedules (
+    kid_id BIGINT NOT NULL,
+    word_id BIGINT NOT NULL,
+    repetitions INT NOT NULL DEFAULT 0,
+    interval_days INT NOT NULL DEFAULT 0,
+    ease_factor DOUBLE NOT NULL DEFAULT 2.5,
+    lapses INT NOT NULL DEFAULT 0,
+    due_at DATETIME(6) NOT NULL,
+    last_reviewed_at DATETIME(6) NULL,
+    created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (kid_id, word_id),
+    FOREIGN KEY (kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE,
+    INDEX idx_word_schedules_kid_due (kid_id, due_at),
+    INDEX idx_word_schedules_word (word_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,4 @@
+-- Add a locale (language and accent) to spelling lists for audio generation
+
+ALTER TABLE spelling_lists
+    ADD COLUMN locale VARCHAR(16) NOT NULL DEFAULT 'en-GB';
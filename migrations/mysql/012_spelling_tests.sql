@@ -0,0 +1,42 @@
+-- Scheduled spelling tests. A teacher opens a test window for a class on one
+-- list; each child gets a single attempt, kept apart from practice sessions.
+
+CREATE TABLE IF NOT EXISTS spelling_tests (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    class_id BIGINT NOT NULL,
+    spelling_list_id BIGINT NOT NULL,
+    created_by BIGINT NOT NULL,
+    opens_at DATETIME NOT NULL,
+    closes_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (class_id) REFERENCES teacher_classes(id) ON DELETE CASCADE,
+    FOREIGN KEY (spelling_list_id) REFERENCES spelling_lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_spelling_tests_class (class_id)
+);
+
+CREATE TABLE IF NOT EXISTS spelling_test_attempts (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    test_id BIGINT NOT NULL,
+    kid_id BIGINT NOT NULL,
+    total_words INT NOT NULL DEFAULT 0,
+    correct_words INT NOT NULL DEFAULT 0,
+    started_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME NULL,
+    FOREIGN KEY (test_id) REFERENCES spelling_tests(id) ON DELETE CASCADE,
+    FOREIGN KEY (kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_spelling_test_kid (test_id, kid_id),
+    INDEX idx_spelling_test_attempts_kid (kid_id)
+);
+
+CREATE TABLE IF NOT EXISTS spelling_test_answers (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    attempt_id BIGINT NOT NULL,
+    word_id BIGINT NOT NULL,
+    word_text VARCHAR(255) NOT NULL,
+    answer VARCHAR(255) NOT NULL,
+    is_correct BOOLEAN NOT NULL,
+    answered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (attempt_id) REFERENCES spelling_test_attempts(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_spelling_test_answer_word (attempt_id, word_id)
+);
@@ -0,0 +1,30 @@
+-- Outbound email queue. Emails are queued here and delivered by a background
+-- worker that retries with exponential backoff, recording every attempt.
+
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    to_email VARCHAR(255) NOT NULL,
+    subject TEXT NOT NULL,
+    html_body MEDIUMTEXT NOT NULL,
+    text_body MEDIUMTEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_error TEXT,
+    invitation_id INTEGER,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    sent_at DATETIME,
+    FOREIGN KEY (invitation_id) REFERENCES invitations(id) ON DELETE SET NULL,
+    INDEX idx_email_outbox_due (status, next_attempt_at)
+);
+
+CREATE TABLE IF NOT EXISTS email_delivery_attempts (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    outbox_id BIGINT NOT NULL,
+    backend VARCHAR(20) NOT NULL,
+    success BOOLEAN NOT NULL,
+    error TEXT,
+    attempted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (outbox_id) REFERENCES email_outbox(id) ON DELETE CASCADE,
+    INDEX idx_email_delivery_attempts_outbox (outbox_id)
+);
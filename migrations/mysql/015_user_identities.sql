@@ -0,0 +1,23 @@
+-- Sign-in provider identities. A user can link one account from each provider
+-- alongside (or instead of) a password. This replaces users.oauth_provider and
+-- users.oauth_subject, which only allowed one.
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at DATETIME NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY idx_user_identities_subject (provider, subject),
+    UNIQUE KEY idx_user_identities_user (user_id, provider)
+);
+
+INSERT IGNORE INTO user_identities (user_id, provider, subject, email, created_at)
+SELECT id, oauth_provider, oauth_subject, email, updated_at
+FROM users
+WHERE oauth_provider IS NOT NULL AND oauth_provider <> '' AND oauth_subject IS NOT NULL AND oauth_subject <> '';
+
+UPDATE users SET oauth_provider = NULL, oauth_subject = NULL;
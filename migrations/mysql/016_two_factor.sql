@@ -0,0 +1,37 @@
+-- TOTP two-factor authentication. A secret is stored as soon as enrolment starts
+-- but only protects the account once enabled_at is set by confirming a code.
+-- last_used_step is the newest time step accepted, so a code can't be replayed.
+
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id BIGINT PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL,
+    enabled_at DATETIME NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- One-time recovery codes, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS two_factor_recovery_codes (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_recovery_codes_user (user_id)
+);
+
+-- A password or provider sign-in waiting for its second step
+CREATE TABLE IF NOT EXISTS login_challenges (
+    id VARCHAR(255) PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at DATETIME(6) NOT NULL,
+    created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_login_challenges_expires (expires_at)
+);
+
+-- Who must use two-factor authentication: optional, admins or everyone
+INSERT IGNORE INTO settings (`key`, `value`) VALUES ('two_factor_policy', 'optional');
@@ -0,0 +1,9 @@
+-- Rate limit counters shared by every server, so limits survive restarts and hold
+-- across replicas. A bucket is one fixed window of one policy for one client or
+-- account, stored as a hash.
+CREATE TABLE IF NOT EXISTS rate_limits (
+    bucket VARCHAR(64) PRIMARY KEY,
+    hits INT NOT NULL DEFAULT 0,
+    expires_at DATETIME(6) NOT NULL,
+    INDEX idx_rate_limits_expires (expires_at)
+);
@@ -0,0 +1,12 @@
+-- Achievements kids have unlocked. The achievements themselves are defined in
+-- code; achievement_key names one of them. seen_at is set once the kid has been
+-- shown the unlock.
+CREATE TABLE IF NOT EXISTS achievements (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    kid_id BIGINT NOT NULL,
+    achievement_key VARCHAR(50) NOT NULL,
+    unlocked_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seen_at DATETIME NULL,
+    FOREIGN KEY (kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    UNIQUE(kid_id, achievement_key)
+);
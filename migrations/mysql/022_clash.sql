@@ -0,0 +1,23 @@
+-- Head-to-head clash matches between two kids on a list assigned to both. Live
+-- matches are played in the server's memory; a row is kept from the challenge
+-- onwards as the match history. Scores are rounds won, and winner_kid_id is NULL
+-- for a draw or a match that never finished.
+CREATE TABLE IF NOT EXISTS clash_matches (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    spelling_list_id BIGINT NOT NULL,
+    challenger_kid_id BIGINT NOT NULL,
+    opponent_kid_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting',
+    total_rounds INT NOT NULL DEFAULT 0,
+    challenger_score INT NOT NULL DEFAULT 0,
+    opponent_score INT NOT NULL DEFAULT 0,
+    winner_kid_id BIGINT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at DATETIME NULL,
+    finished_at DATETIME NULL,
+    FOREIGN KEY (spelling_list_id) REFERENCES spelling_lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (challenger_kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    FOREIGN KEY (opponent_kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    INDEX idx_clash_matches_challenger (challenger_kid_id),
+    INDEX idx_clash_matches_opponent (opponent_kid_id)
+);
@@ -0,0 +1,54 @@
+-- Live classroom spelling bees hosted by a teacher. Words are dictated to one
+-- child at a time; a child is out after max_misses wrong answers. version goes up
+-- on every change so the whiteboard and children's devices know to refresh.
+CREATE TABLE IF NOT EXISTS spelling_bees (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    teacher_user_id BIGINT NOT NULL,
+    spelling_list_id BIGINT NOT NULL,
+    max_misses INT NOT NULL DEFAULT 1,
+    answer_mode VARCHAR(20) NOT NULL DEFAULT 'teacher',
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    version INT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at DATETIME NULL,
+    FOREIGN KEY (teacher_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (spelling_list_id) REFERENCES spelling_lists(id) ON DELETE CASCADE,
+    INDEX idx_spelling_bees_teacher (teacher_user_id)
+);
+
+-- The children in a bee, in the order they take turns. final_place is set when
+-- the bee finishes; eliminated_turn is the turn a child went out on.
+CREATE TABLE IF NOT EXISTS spelling_bee_participants (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    bee_id BIGINT NOT NULL,
+    kid_id BIGINT NOT NULL,
+    turn_order INT NOT NULL,
+    words_correct INT NOT NULL DEFAULT 0,
+    misses INT NOT NULL DEFAULT 0,
+    eliminated_turn INT NULL,
+    final_place INT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (bee_id) REFERENCES spelling_bees(id) ON DELETE CASCADE,
+    FOREIGN KEY (kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_spelling_bee_kid (bee_id, kid_id),
+    INDEX idx_spelling_bee_participants_kid (kid_id)
+);
+
+-- Each word dictated in a bee. is_correct is NULL while the child is answering.
+CREATE TABLE IF NOT EXISTS spelling_bee_turns (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    bee_id BIGINT NOT NULL,
+    turn_number INT NOT NULL,
+    kid_id BIGINT NOT NULL,
+    word_id BIGINT NULL,
+    word_text VARCHAR(255) NOT NULL,
+    answer VARCHAR(255) NULL,
+    is_correct BOOLEAN NULL,
+    asked_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    answered_at DATETIME NULL,
+    FOREIGN KEY (bee_id) REFERENCES spelling_bees(id) ON DELETE CASCADE,
+    FOREIGN KEY (kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE SET NULL,
+    UNIQUE KEY uk_spelling_bee_turn (bee_id, turn_number)
+);
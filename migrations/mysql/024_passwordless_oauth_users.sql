@@ -0,0 +1,22 @@
+-- Accounts created by signing in with a provider before passwords became
+-- optional were given a random password nobody knows. Clear it, so they count as
+-- having no password and can add one without being asked for it.
+--
+-- They are the accounts whose provider identity, moved into user_identities with
+-- the time the account was last updated, was linked as the account was created.
+-- An account given a password since would have been updated later, and one that
+-- linked a provider to its password later was created earlier. Accounts that have
+-- used a reset link since are left alone too.
+UPDATE users u
+JOIN (
+    SELECT DISTINCT i.user_id
+    FROM user_identities i
+    JOIN users linked ON linked.id = i.user_id
+    WHERE i.created_at <= DATE_ADD(linked.created_at, INTERVAL 1 MINUTE)
+) oauth_created ON oauth_created.user_id = u.id
+SET u.password_hash = ''
+WHERE u.password_hash <> ''
+AND NOT EXISTS (
+    SELECT 1 FROM password_reset_tokens t
+    WHERE t.user_id = u.id AND t.used = TRUE
+);
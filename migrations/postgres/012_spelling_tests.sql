@@ -0,0 +1,44 @@
+-- Scheduled spelling tests. A teacher opens a test window for a class on one
+-- list; each child gets a single attempt, kept apart from practice sessions.
+
+CREATE TABLE IF NOT EXISTS spelling_tests (
+    id BIGSERIAL PRIMARY KEY,
+    class_id BIGINT NOT NULL,
+    spelling_list_id BIGINT NOT NULL,
+    created_by BIGINT NOT NULL,
+    opens_at TIMESTAMPTZ NOT NULL,
+    closes_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (class_id) REFERENCES teacher_classes(id) ON DELETE CASCADE,
+    FOREIGN KEY (spelling_list_id) REFERENCES spelling_lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_spelling_tests_class ON spelling_tests(class_id);
+
+CREATE TABLE IF NOT EXISTS spelling_test_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    test_id BIGINT NOT NULL,
+    kid_id BIGINT NOT NULL,
+    total_words INTEGER NOT NULL DEFAULT 0,
+    correct_words INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ,
+    FOREIGN KEY (test_id) REFERENCES spelling_tests(id) ON DELETE CASCADE,
+    FOREIGN KEY (kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    UNIQUE(test_id, kid_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_spelling_test_attempts_kid ON spelling_test_attempts(kid_id);
+
+CREATE TABLE IF NOT EXISTS spelling_test_answers (
+    id BIGSERIAL PRIMARY KEY,
+    attempt_id BIGINT NOT NULL,
+    word_id BIGINT NOT NULL,
+    word_text TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    is_correct BOOLEAN NOT NULL,
+    answered_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (attempt_id) REFERENCES spelling_test_attempts(id) ON DELETE CASCADE,
+    UNIQUE(attempt_id, word_id)
+);
@@ -0,0 +1,39 @@
+-- TOTP two-factor authentication. A secret is stored as soon as enrolment starts
+-- but only protects the account once enabled_at is set by confirming a code.
+-- last_used_step is the newest time step accepted, so a code can't be replayed.
+
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id BIGINT PRIMARY KEY,
+    secret TEXT NOT NULL,
+    enabled_at TIMESTAMPTZ,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- One-time recovery codes, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS two_factor_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user ON two_factor_recovery_codes(user_id);
+
+-- A password or provider sign-in waiting for its second step
+CREATE TABLE IF NOT EXISTS login_challenges (
+    id TEXT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_challenges_expires ON login_challenges(expires_at);
+
+-- Who must use two-factor authentication: optional, admins or everyone
+INSERT INTO settings (key, value) VALUES ('two_factor_policy', 'optional') ON CONFLICT DO NOTHING;
@@ -0,0 +1,24 @@
+-- How a family's children's daily streaks are counted. Days are counted in the
+-- family's time zone (empty for the server's zone), and with weekends_off set,
+-- Saturdays and Sundays without practice don't break a streak.
+CREATE TABLE IF NOT EXISTS streak_settings (
+    family_code TEXT PRIMARY KEY,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    weekends_off BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (family_code) REFERENCES families(family_code) ON DELETE CASCADE
+);
+
+-- Days off, such as school holidays, that don't break a streak. Dates are
+-- calendar days (YYYY-MM-DD) in the family's time zone, both ends included.
+CREATE TABLE IF NOT EXISTS streak_freezes (
+    id BIGSERIAL PRIMARY KEY,
+    family_code TEXT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    start_date VARCHAR(10) NOT NULL,
+    end_date VARCHAR(10) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (family_code) REFERENCES families(family_code) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_streak_freezes_family ON streak_freezes(family_code);
@@ -0,0 +1,12 @@
+-- How a kid appears on leaderboards. Boards shared beyond the kid's family show
+-- the nickname instead of their name (a generated one while it is empty). Either
+-- a parent or a teacher can take a kid off leaderboards; each only changes their
+-- own flag.
+CREATE TABLE IF NOT EXISTS leaderboard_settings (
+    kid_id BIGINT PRIMARY KEY,
+    nickname VARCHAR(30) NOT NULL DEFAULT '',
+    hidden_by_parent BOOLEAN NOT NULL DEFAULT FALSE,
+    hidden_by_teacher BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (kid_id) REFERENCES kids(id) ON DELETE CASCADE
+);
@@ -0,0 +1,24 @@
+-- Head-to-head clash matches between two kids on a list assigned to both. Live
+-- matches are played in the server's memory; a row is kept from the challenge
+-- onwards as the match history. Scores are rounds won, and winner_kid_id is NULL
+-- for a draw or a match that never finished.
+CREATE TABLE IF NOT EXISTS clash_matches (
+    id BIGSERIAL PRIMARY KEY,
+    spelling_list_id BIGINT NOT NULL,
+    challenger_kid_id BIGINT NOT NULL,
+    opponent_kid_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting',
+    total_rounds INTEGER NOT NULL DEFAULT 0,
+    challenger_score INTEGER NOT NULL DEFAULT 0,
+    opponent_score INTEGER NOT NULL DEFAULT 0,
+    winner_kid_id BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    FOREIGN KEY (spelling_list_id) REFERENCES spelling_lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (challenger_kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    FOREIGN KEY (opponent_kid_id) REFERENCES kids(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_clash_matches_challenger ON clash_matches(challenger_kid_id);
+CREATE INDEX IF NOT EXISTS idx_clash_matches_opponent ON clash_matches(opponent_kid_id);
@@ -0,0 +1,21 @@
+-- Accounts created by signing in with a provider before passwords became
+-- optional were given a random password nobody knows. Clear it, so they count as
+-- having no password and can add one without being asked for it.
+--
+-- They are the accounts whose provider identity, moved into user_identities with
+-- the time the account was last updated, was linked as the account was created.
+-- An account given a password since would have been updated later, and one that
+-- linked a provider to its password later was created earlier. Accounts that have
+-- used a reset link since are left alone too.
+UPDATE users
+SET password_hash = ''
+WHERE password_hash <> ''
+AND EXISTS (
+    SELECT 1 FROM user_identities i
+    WHERE i.user_id = users.id
+    AND i.created_at <= users.created_at + INTERVAL '1 minute'
+)
+AND NOT EXISTS (
+    SELECT 1 FROM password_reset_tokens t
+    WHERE t.user_id = users.id AND t.used = TRUE
+);
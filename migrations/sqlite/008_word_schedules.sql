@@ -0,0 +1,20 @@
+-- Spaced-repetition (SM-2) schedule per kid and word
+
+CREATE TABLE IF NOT EXISTS word_schedules (
+    kid_id INTEGER NOT NULL,
+    word_id INTEGER NOT NULL,
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    interval_days INTEGER NOT NULL DEFAULT 0,
+    ease_factor REAL NOT NULL DEFAULT 2.5,
+    lapses INTEGER NOT NULL DEFAULT 0,
+    due_at DATETIME NOT NULL,
+    last_reviewed_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (kid_id, word_id),
+    FOREIGN KEY (kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_word_schedules_kid_due ON word_schedules(kid_id, due_at);
+CREATE INDEX IF NOT EXISTS idx_word_schedules_word ON word_schedules(word_id);
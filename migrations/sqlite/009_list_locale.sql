@@ -0,0 +1,3 @@
+-- Add a locale (language and accent) to spelling lists for audio generation
+
+ALTER TABLE spelling_lists ADD COLUMN locale TEXT DEFAULT 'en-GB' NOT NULL;
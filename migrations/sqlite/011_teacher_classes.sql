@@ -0,0 +1,46 @@
+-- Named teacher classes (e.g. "Year 4 Blue" or a reading group) with their
+-- members and the lists assigned to the whole class
+
+CREATE TABLE IF NOT EXISTS teacher_classes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    teacher_user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    archived_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (teacher_user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_teacher_classes_teacher ON teacher_classes(teacher_user_id);
+
+CREATE TABLE IF NOT EXISTS teacher_class_members (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    class_id INTEGER NOT NULL,
+    kid_id INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (class_id) REFERENCES teacher_classes(id) ON DELETE CASCADE,
+    FOREIGN KEY (kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    UNIQUE(class_id, kid_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_teacher_class_members_kid ON teacher_class_members(kid_id);
+
+CREATE TABLE IF NOT EXISTS teacher_class_lists (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    class_id INTEGER NOT NULL,
+    spelling_list_id INTEGER NOT NULL,
+    due_date DATETIME,
+    assigned_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (class_id) REFERENCES teacher_classes(id) ON DELETE CASCADE,
+    FOREIGN KEY (spelling_list_id) REFERENCES spelling_lists(id) ON DELETE CASCADE,
+    UNIQUE(class_id, spelling_list_id)
+);
+
+-- Existing teachers keep their roster as a single class
+INSERT INTO teacher_classes (teacher_user_id, name)
+SELECT DISTINCT teacher_user_id, 'My Class' FROM teacher_kid_relationships;
+
+INSERT INTO teacher_class_members (class_id, kid_id)
+SELECT tc.id, tkr.kid_id
+FROM teacher_kid_relationships tkr
+INNER JOIN teacher_classes tc ON tc.teacher_user_id = tkr.teacher_user_id;
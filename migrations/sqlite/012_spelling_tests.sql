@@ -0,0 +1,44 @@
+-- Scheduled spelling tests. A teacher opens a test window for a class on one
+-- list; each child gets a single attempt, kept apart from practice sessions.
+
+CREATE TABLE IF NOT EXISTS spelling_tests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    class_id INTEGER NOT NULL,
+    spelling_list_id INTEGER NOT NULL,
+    created_by INTEGER NOT NULL,
+    opens_at DATETIME NOT NULL,
+    closes_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (class_id) REFERENCES teacher_classes(id) ON DELETE CASCADE,
+    FOREIGN KEY (spelling_list_id) REFERENCES spelling_lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_spelling_tests_class ON spelling_tests(class_id);
+
+CREATE TABLE IF NOT EXISTS spelling_test_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    test_id INTEGER NOT NULL,
+    kid_id INTEGER NOT NULL,
+    total_words INTEGER NOT NULL DEFAULT 0,
+    correct_words INTEGER NOT NULL DEFAULT 0,
+    started_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME,
+    FOREIGN KEY (test_id) REFERENCES spelling_tests(id) ON DELETE CASCADE,
+    FOREIGN KEY (kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    UNIQUE(test_id, kid_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_spelling_test_attempts_kid ON spelling_test_attempts(kid_id);
+
+CREATE TABLE IF NOT EXISTS spelling_test_answers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    attempt_id INTEGER NOT NULL,
+    word_id INTEGER NOT NULL,
+    word_text TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    is_correct BOOLEAN NOT NULL,
+    answered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (attempt_id) REFERENCES spelling_test_attempts(id) ON DELETE CASCADE,
+    UNIQUE(attempt_id, word_id)
+);
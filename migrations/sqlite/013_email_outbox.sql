@@ -0,0 +1,32 @@
+-- Outbound email queue. Emails are queued here and delivered by a background
+-- worker that retries with exponential backoff, recording every attempt.
+
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    to_email TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    html_body TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_error TEXT,
+    invitation_id INTEGER,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    sent_at DATETIME,
+    FOREIGN KEY (invitation_id) REFERENCES invitations(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_outbox_due ON email_outbox(status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS email_delivery_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    outbox_id INTEGER NOT NULL,
+    backend TEXT NOT NULL,
+    success BOOLEAN NOT NULL,
+    error TEXT,
+    attempted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (outbox_id) REFERENCES email_outbox(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_delivery_attempts_outbox ON email_delivery_attempts(outbox_id);
@@ -0,0 +1,10 @@
+-- Opt-in weekly progress digest emails. last_sent_at records the last digest
+-- so each one is sent once, even after a restart.
+
+CREATE TABLE IF NOT EXISTS digest_subscriptions (
+    user_id INTEGER PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT 0,
+    last_sent_at DATETIME,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,7 @@
+-- Where and when sessions were last used, so users can recognise and revoke them
+ALTER TABLE sessions ADD COLUMN last_seen_at DATETIME NULL;
+ALTER TABLE sessions ADD COLUMN user_agent TEXT NULL;
+ALTER TABLE sessions ADD COLUMN ip_address TEXT NULL;
+ALTER TABLE kid_sessions ADD COLUMN last_seen_at DATETIME NULL;
+ALTER TABLE kid_sessions ADD COLUMN user_agent TEXT NULL;
+ALTER TABLE kid_sessions ADD COLUMN ip_address TEXT NULL;
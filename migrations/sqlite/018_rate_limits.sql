@@ -0,0 +1,10 @@
+-- Rate limit counters shared by every server, so limits survive restarts and hold
+-- across replicas. A bucket is one fixed window of one policy for one client or
+-- account, stored as a hash.
+CREATE TABLE IF NOT EXISTS rate_limits (
+    bucket TEXT PRIMARY KEY,
+    hits INTEGER NOT NULL DEFAULT 0,
+    expires_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limits_expires ON rate_limits(expires_at);
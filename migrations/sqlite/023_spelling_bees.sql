@@ -0,0 +1,56 @@
+-- Live classroom spelling bees hosted by a teacher. Words are dictated to one
+-- child at a time; a child is out after max_misses wrong answers. version goes up
+-- on every change so the whiteboard and children's devices know to refresh.
+CREATE TABLE IF NOT EXISTS spelling_bees (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    teacher_user_id INTEGER NOT NULL,
+    spelling_list_id INTEGER NOT NULL,
+    max_misses INTEGER NOT NULL DEFAULT 1,
+    answer_mode TEXT NOT NULL DEFAULT 'teacher',
+    status TEXT NOT NULL DEFAULT 'running',
+    version INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at DATETIME,
+    FOREIGN KEY (teacher_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (spelling_list_id) REFERENCES spelling_lists(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_spelling_bees_teacher ON spelling_bees(teacher_user_id);
+
+-- The children in a bee, in the order they take turns. final_place is set when
+-- the bee finishes; eliminated_turn is the turn a child went out on.
+CREATE TABLE IF NOT EXISTS spelling_bee_participants (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    bee_id INTEGER NOT NULL,
+    kid_id INTEGER NOT NULL,
+    turn_order INTEGER NOT NULL,
+    words_correct INTEGER NOT NULL DEFAULT 0,
+    misses INTEGER NOT NULL DEFAULT 0,
+    eliminated_turn INTEGER,
+    final_place INTEGER,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (bee_id) REFERENCES spelling_bees(id) ON DELETE CASCADE,
+    FOREIGN KEY (kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    UNIQUE(bee_id, kid_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_spelling_bee_participants_kid ON spelling_bee_participants(kid_id);
+
+-- Each word dictated in a bee. is_correct is NULL while the child is answering.
+CREATE TABLE IF NOT EXISTS spelling_bee_turns (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    bee_id INTEGER NOT NULL,
+    turn_number INTEGER NOT NULL,
+    kid_id INTEGER NOT NULL,
+    word_id INTEGER,
+    word_text TEXT NOT NULL,
+    answer TEXT,
+    is_correct BOOLEAN,
+    asked_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    answered_at DATETIME,
+    FOREIGN KEY (bee_id) REFERENCES spelling_bees(id) ON DELETE CASCADE,
+    FOREIGN KEY (kid_id) REFERENCES kids(id) ON DELETE CASCADE,
+    FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE SET NULL,
+    UNIQUE(bee_id, turn_number)
+);
@@ -566,6 +566,123 @@
         });
     }
 
+    function attachClash() {
+        var root = document.querySelector("[data-clash-match]");
+        if (!root) {
+            return;
+        }
+
+        var matchID = root.dataset.clashMatch;
+        var rivalName = root.dataset.clashRival;
+        var readyButton = root.querySelector("[data-clash-ready='true']");
+        var form = root.querySelector("[data-clash-form='true']");
+        var input = form.querySelector("input[name='answer']");
+        var audio = document.getElementById("clash-audio");
+        var source = null;
+        var round = 0;
+
+        function setText(name, value) {
+            root.querySelectorAll("[data-clash-text='" + name + "']").forEach(function (el) {
+                el.textContent = value;
+            });
+        }
+
+        function setShown(name, shown) {
+            root.querySelectorAll("[data-clash-show='" + name + "']").forEach(function (el) {
+                el.style.display = shown ? "" : "none";
+            });
+        }
+
+        function describeRound(last) {
+            if (!last) {
+                return "";
+            }
+            var text = "Nobody got it this time.";
+            if (last.winnerName) {
+                text = (last.youWon ? "You" : last.winnerName) + " got it in " + (last.timeMs / 1000).toFixed(1) + "s!";
+            }
+            return text + " The word was \"" + last.word + "\".";
+        }
+
+        function render(state) {
+            var finished = state.status === "finished" || state.status === "cancelled";
+            setText("you-score", state.you.score);
+            setText("rival-score", state.rival.score);
+            setText("round", state.round + " of " + state.totalRounds);
+            setText("rival-status", state.rival.ready ? rivalName + " is here." : "Waiting for " + rivalName + " to join...");
+            setText("last-round", describeRound(state.lastRound));
+
+            if (state.status === "waiting") {
+                setText("waiting", "Waiting for " + rivalName + " to accept...");
+            } else if (state.round === 0) {
+                setText("waiting", "Get ready...");
+            } else {
+                setText("waiting", "Next word coming up...");
+            }
+
+            setShown("start", false);
+            setShown("waiting", !finished && !state.roundOpen);
+            setShown("round", !finished && state.roundOpen);
+            setShown("result", finished);
+
+            if (state.roundOpen && state.round !== round) {
+                round = state.round;
+                input.value = "";
+                input.disabled = false;
+                input.focus();
+                setText("answered", "");
+                audio.src = state.audioUrl;
+                audio.play().catch(function () {});
+            }
+            if (state.roundOpen && state.answered) {
+                input.disabled = true;
+                setText("answered", "Answer sent! Waiting for the round to finish...");
+            }
+
+            if (finished) {
+                if (state.result === "won") {
+                    setText("result", "🏆 You won!");
+                } else if (state.result === "lost") {
+                    setText("result", rivalName + " won this time.");
+                } else if (state.result === "draw") {
+                    setText("result", "It's a draw!");
+                } else {
+                    setText("result", "This match was cancelled.");
+                }
+                setText("final-round", describeRound(state.lastRound));
+                source.close();
+            }
+        }
+
+        // Waiting for a click lets the browser play each word as it arrives
+        readyButton.addEventListener("click", function () {
+            audio.play().catch(function () {});
+            source = new EventSource("/child/clash/" + matchID + "/events");
+            source.addEventListener("state", function (event) {
+                render(JSON.parse(event.data));
+            });
+        });
+
+        form.addEventListener("submit", function (event) {
+            event.preventDefault();
+            var answer = input.value.trim();
+            if (!answer) {
+                return;
+            }
+            input.disabled = true;
+
+            var formData = new URLSearchParams();
+            formData.append("answer", answer);
+            fetch("/child/clash/" + matchID + "/answer", {
+                method: "POST",
+                headers: { "Content-Type": "application/x-www-form-urlencoded" },
+                body: formData
+            }).catch(function () {
+                input.disabled = false;
+            });
+        });
+    }
+
     document.addEventListener("click", function (event) {
         var target = event.target.closest("[data-modal-open], [data-modal-close], [data-show], [data-hide], [data-copy-text], [data-audio-target], [data-user-edit], [data-kid-edit], [data-remembered-username]");
         if (!target) {
@@ -630,6 +747,7 @@
         attachBulkImport();
         attachRememberUsernameForm();
         attachPasswordConfirm();
+        attachClash();
     });
 
     document.body.addEventListener("htmx:afterSwap", function (event) {